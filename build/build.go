@@ -13,7 +13,7 @@ func main() {
 
 	fs := flag.NewFlagSet("Kaiju Build Args", flag.ContinueOnError)
 	isEditor := fs.Bool("editor", false, "Builds the editor, otherwise builds the runtime")
-	renderer := fs.String("renderer", "", "vk (Vulkan default)")
+	renderer := fs.String("renderer", "", "vk (Vulkan default), sw (headless software)")
 	fs.Parse(os.Args[1:])
 	tags := []string{}       // tags
 	cgoLDFLAGS := []string{} // CGO_LDFLAGS
@@ -32,6 +32,8 @@ func main() {
 		tags = append(tags, "editor")
 	}
 	switch *renderer {
+	case "sw":
+		tags = append(tags, "software")
 	case "vk":
 		fallthrough
 	default:
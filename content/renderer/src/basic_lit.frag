@@ -0,0 +1,36 @@
+#version 460
+
+// NO_SHADOWS is for vertex shaders that don't pass along receiveShadows and
+// shader layouts that don't have the shadow map binding
+#ifndef NO_SHADOWS
+#define LIGHT_SHADOWS
+layout(binding = 2) uniform sampler2D shadowMap;
+#endif
+
+#include "inc_globals.inl"
+#include "inc_lighting.inl"
+
+layout(location = 0) in vec4 fragColor;
+layout(location = 1) in vec2 fragTexCoords;
+layout(location = 2) in vec3 fragNormal;
+layout(location = 3) in vec3 fragWorldPosition;
+#ifndef NO_SHADOWS
+layout(location = 4) in float fragReceiveShadows;
+#endif
+
+layout(binding = 1) uniform sampler2D texSampler;
+
+layout(location = 0) out vec4 outColor;
+layout(location = 1) out float reveal;
+
+void main() {
+	vec4 baseColor = texture(texSampler, fragTexCoords) * fragColor;
+#ifdef NO_SHADOWS
+	float shadowStrength = 0.0;
+#else
+	float shadowStrength = fragReceiveShadows;
+#endif
+	vec4 unWeightedColor = computeLighting(baseColor, fragWorldPosition,
+		fragNormal, shadowStrength);
+#include "inc_fragment_oit_block.inl"
+}
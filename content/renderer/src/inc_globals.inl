@@ -0,0 +1,30 @@
+#define MAX_LIGHTS			32
+#define LIGHT_TILE_COLUMNS	16
+#define LIGHT_TILE_ROWS		9
+#define LIGHT_TILE_COUNT	(LIGHT_TILE_COLUMNS * LIGHT_TILE_ROWS)
+#define MAX_SHADOW_VIEWS	8
+
+struct Light {
+	vec4 position;	// w = [0=directional, 1=point, 2=spot]
+	vec4 direction;	// w = range
+	vec4 color;		// a = intensity
+	vec4 cone;		// x = cos(inner angle), y = cos(outer angle), z = shadow slot
+};
+
+layout(set = 0, binding = 0) readonly uniform UniformBufferObject {
+	mat4 view;
+	mat4 projection;
+	mat4 uiView;
+	mat4 uiProjection;
+	vec4 cameraPosition;	// w = [0=perspective, 1=orthographic]
+	vec3 uiCameraPosition;
+	vec2 screenSize;
+	float time;
+	uint lightCount;
+	vec4 ambientLight;	// a = intensity
+	Light lights[MAX_LIGHTS];
+	uvec4 lightTiles[LIGHT_TILE_COUNT / 4];	// bit mask of lights per tile
+	mat4 shadowMatrices[MAX_SHADOW_VIEWS];
+	vec4 shadowCascadeSplits;	// view distance each cascade ends at
+	vec4 shadowParams;	// x = cascade count, y = normal bias in texels
+};
@@ -0,0 +1,154 @@
+#define LIGHT_TYPE_DIRECTIONAL	0
+#define LIGHT_TYPE_POINT		1
+#define LIGHT_TYPE_SPOT			2
+#define LIGHT_SPECULAR_POWER	32.0
+#define LIGHT_SPECULAR_STRENGTH	0.25
+
+#ifdef LIGHT_SHADOWS
+#include "inc_shadows.inl"
+
+// The radius of the percentage closer filter, the shadow is the average of
+// (radius * 2 + 1)^2 samples of the shadow map
+#ifndef SHADOW_PCF_RADIUS
+#define SHADOW_PCF_RADIUS		1
+#endif
+
+// Returns how much of the light reaches the surface, 0 is fully in shadow.
+// The shader including this must declare the shadowMap sampler.
+float lightShadow(Light light, vec3 worldPos, vec3 normal) {
+	int slot = int(light.cone.z);
+	if (slot < 0) {
+		return 1.0;
+	}
+	if (int(light.position.w) == LIGHT_TYPE_DIRECTIONAL) {
+		float depth = -(view * vec4(worldPos, 1.0)).z;
+		int count = int(shadowParams.x);
+		if (depth > shadowCascadeSplits[count - 1]) {
+			return 1.0;
+		}
+		int cascade = 0;
+		while (cascade < count - 1 && depth > shadowCascadeSplits[cascade]) {
+			cascade++;
+		}
+		slot += cascade;
+	}
+	mat4 shadowMatrix = shadowMatrices[slot];
+	vec2 atlasSize = vec2(textureSize(shadowMap, 0));
+	float slotSize = atlasSize.x / SHADOW_ATLAS_COLUMNS;
+	// Offset the surface along its normal by the world size of a texel
+	vec4 clip = shadowMatrix * vec4(worldPos, 1.0);
+	float rowLength = length(vec3(shadowMatrix[0][0], shadowMatrix[1][0], shadowMatrix[2][0]));
+	float texelWorld = 2.0 * clip.w / (rowLength * slotSize);
+	clip = shadowMatrix * vec4(worldPos + normal * texelWorld * shadowParams.y, 1.0);
+	vec3 ndc = clip.xyz / clip.w;
+	if (any(greaterThan(abs(ndc.xy), vec2(1.0))) || ndc.z > 1.0) {
+		return 1.0;
+	}
+	vec4 rect = shadowSlotRect(slot);
+	vec2 uv = (ndc.xy * 0.5 + 0.5) * rect.zw + rect.xy;
+	vec2 texel = 1.0 / atlasSize;
+	// Keep the filter from sampling the neighboring slots
+	vec2 uvMin = rect.xy + texel * 0.5;
+	vec2 uvMax = rect.xy + rect.zw - texel * 0.5;
+	float lit = 0.0;
+	for (int y = -SHADOW_PCF_RADIUS; y <= SHADOW_PCF_RADIUS; y++) {
+		for (int x = -SHADOW_PCF_RADIUS; x <= SHADOW_PCF_RADIUS; x++) {
+			vec2 tap = clamp(uv + vec2(x, y) * texel, uvMin, uvMax);
+			lit += ndc.z <= texture(shadowMap, tap).r ? 1.0 : 0.0;
+		}
+	}
+	float width = float(SHADOW_PCF_RADIUS * 2 + 1);
+	return lit / (width * width);
+}
+#else
+float lightShadow(Light light, vec3 worldPos, vec3 normal) {
+	return 1.0;
+}
+#endif
+
+uint lightTileMask() {
+	vec2 tileSize = screenSize / vec2(LIGHT_TILE_COLUMNS, LIGHT_TILE_ROWS);
+	ivec2 tile = clamp(ivec2(gl_FragCoord.xy / tileSize), ivec2(0),
+		ivec2(LIGHT_TILE_COLUMNS - 1, LIGHT_TILE_ROWS - 1));
+	int idx = tile.y * LIGHT_TILE_COLUMNS + tile.x;
+	return lightTiles[idx / 4][idx % 4];
+}
+
+// Finds the direction to the light and the light that reaches the surface
+// after attenuation and shadowing, returns false if no light reaches it. The
+// shadow strength is 0 to ignore the light's shadow and 1 to fully apply it.
+bool lightIncoming(Light light, vec3 worldPos, vec3 normal, float shadowStrength,
+	out vec3 toLight, out vec3 radiance)
+{
+	int type = int(light.position.w);
+	float attenuation = 1.0;
+	radiance = vec3(0.0);
+	if (type == LIGHT_TYPE_DIRECTIONAL) {
+		toLight = -light.direction.xyz;
+	} else {
+		vec3 delta = light.position.xyz - worldPos;
+		float dist = length(delta);
+		float range = light.direction.w;
+		toLight = delta / max(dist, 0.0001);
+		if (dist >= range) {
+			return false;
+		}
+		// Smooth window so the light reaches exactly zero at its range
+		float falloff = clamp(1.0 - pow(dist / range, 4.0), 0.0, 1.0);
+		attenuation = (falloff * falloff) / (dist * dist + 1.0);
+		if (type == LIGHT_TYPE_SPOT) {
+			float cosAngle = dot(-toLight, light.direction.xyz);
+			attenuation *= smoothstep(light.cone.y, light.cone.x, cosAngle);
+		}
+	}
+	if (dot(normal, toLight) <= 0.0 || attenuation <= 0.0) {
+		return false;
+	}
+	if (shadowStrength > 0.0) {
+		attenuation *= mix(1.0, lightShadow(light, worldPos, normal), shadowStrength);
+	}
+	radiance = light.color.rgb * light.color.a * attenuation;
+	return true;
+}
+
+// The direction from the surface to the camera
+vec3 lightViewDirection(vec3 worldPos) {
+	return cameraPosition.w > 0.5
+		? normalize(vec3(view[0][2], view[1][2], view[2][2]))
+		: normalize(cameraPosition.xyz - worldPos);
+}
+
+// Adds the diffuse and specular contribution of a single light
+void applyLight(Light light, vec3 worldPos, vec3 normal, vec3 viewDir,
+	float shadowStrength, inout vec3 diffuse, inout vec3 specular)
+{
+	vec3 toLight;
+	vec3 radiance;
+	if (!lightIncoming(light, worldPos, normal, shadowStrength, toLight, radiance)) {
+		return;
+	}
+	float nDotL = max(dot(normal, toLight), 0.0);
+	vec3 halfDir = normalize(toLight + viewDir);
+	float spec = pow(max(dot(normal, halfDir), 0.0), LIGHT_SPECULAR_POWER);
+	diffuse += radiance * nDotL;
+	specular += radiance * spec * LIGHT_SPECULAR_STRENGTH;
+}
+
+// Lights the base color with the ambient light and every light that reaches
+// the screen tile that this fragment is in
+vec4 computeLighting(vec4 baseColor, vec3 worldPos, vec3 normal, float shadowStrength) {
+	vec3 n = normalize(normal);
+	vec3 viewDir = lightViewDirection(worldPos);
+	vec3 diffuse = ambientLight.rgb * ambientLight.a;
+	vec3 specular = vec3(0.0);
+	uint mask = lightTileMask();
+	while (mask != 0u) {
+		int i = findLSB(mask);
+		mask &= mask - 1u;
+		if (uint(i) >= lightCount) {
+			break;
+		}
+		applyLight(lights[i], worldPos, n, viewDir, shadowStrength, diffuse, specular);
+	}
+	return vec4(baseColor.rgb * diffuse + specular, baseColor.a);
+}
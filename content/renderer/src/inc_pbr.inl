@@ -0,0 +1,88 @@
+#define PBR_PI					3.14159265359
+#define PBR_MIN_ROUGHNESS		0.045
+#define PBR_DIELECTRIC_F0		0.04
+
+// The inputs of the metallic-roughness model after the textures and factors
+// have been combined, colors are in linear space
+struct PBRSurface {
+	vec3 albedo;
+	float alpha;
+	float metallic;
+	float roughness;
+	float occlusion;
+	vec3 emissive;
+};
+
+vec3 pbrToLinear(vec3 srgb) {
+	return pow(srgb, vec3(2.2));
+}
+
+vec3 pbrToSRGB(vec3 linear) {
+	return pow(linear, vec3(1.0 / 2.2));
+}
+
+// Trowbridge-Reitz GGX normal distribution
+float pbrDistribution(float nDotH, float roughness) {
+	float a = roughness * roughness;
+	float a2 = a * a;
+	float d = nDotH * nDotH * (a2 - 1.0) + 1.0;
+	return a2 / (PBR_PI * d * d);
+}
+
+// Smith-GGX height correlated visibility, includes the 1 / (4 nDotL nDotV)
+float pbrVisibility(float nDotL, float nDotV, float roughness) {
+	float a = roughness * roughness;
+	float a2 = a * a;
+	float ggxV = nDotL * sqrt(nDotV * nDotV * (1.0 - a2) + a2);
+	float ggxL = nDotV * sqrt(nDotL * nDotL * (1.0 - a2) + a2);
+	return 0.5 / max(ggxV + ggxL, 0.0001);
+}
+
+vec3 pbrFresnel(float vDotH, vec3 f0) {
+	return f0 + (1.0 - f0) * pow(1.0 - vDotH, 5.0);
+}
+
+// Adds the Cook-Torrance contribution of a single light
+void applyLightPBR(Light light, PBRSurface surface, vec3 worldPos, vec3 normal,
+	vec3 viewDir, vec3 f0, float shadowStrength, inout vec3 color)
+{
+	vec3 toLight;
+	vec3 radiance;
+	if (!lightIncoming(light, worldPos, normal, shadowStrength, toLight, radiance)) {
+		return;
+	}
+	vec3 halfDir = normalize(toLight + viewDir);
+	float nDotL = max(dot(normal, toLight), 0.0);
+	float nDotV = max(dot(normal, viewDir), 0.0001);
+	float nDotH = max(dot(normal, halfDir), 0.0);
+	float vDotH = max(dot(viewDir, halfDir), 0.0);
+	vec3 f = pbrFresnel(vDotH, f0);
+	vec3 specular = f * pbrDistribution(nDotH, surface.roughness)
+		* pbrVisibility(nDotL, nDotV, surface.roughness);
+	vec3 diffuse = (1.0 - f) * (1.0 - surface.metallic) * surface.albedo / PBR_PI;
+	// Lights are authored for the Lambert model which doesn't divide by pi
+	color += (diffuse + specular) * radiance * nDotL * PBR_PI;
+}
+
+// Lights the surface with the ambient light and every light that reaches the
+// screen tile that this fragment is in, the result is in linear space
+vec4 computePBRLighting(PBRSurface surface, vec3 worldPos, vec3 normal, float shadowStrength) {
+	vec3 n = normalize(normal);
+	vec3 viewDir = lightViewDirection(worldPos);
+	vec3 f0 = mix(vec3(PBR_DIELECTRIC_F0), surface.albedo, surface.metallic);
+	surface.roughness = clamp(surface.roughness, PBR_MIN_ROUGHNESS, 1.0);
+	vec3 ambient = ambientLight.rgb * ambientLight.a;
+	vec3 color = ambient * surface.albedo * (1.0 - surface.metallic)
+		+ ambient * f0 * (1.0 - surface.roughness);
+	color *= surface.occlusion;
+	uint mask = lightTileMask();
+	while (mask != 0u) {
+		int i = findLSB(mask);
+		mask &= mask - 1u;
+		if (uint(i) >= lightCount) {
+			break;
+		}
+		applyLightPBR(lights[i], surface, worldPos, n, viewDir, f0, shadowStrength, color);
+	}
+	return vec4(color + surface.emissive, surface.alpha);
+}
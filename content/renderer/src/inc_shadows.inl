@@ -0,0 +1,9 @@
+#define SHADOW_ATLAS_COLUMNS	4
+#define SHADOW_ATLAS_ROWS		2
+
+// The offset (xy) and scale (zw) of a slot in the shadow atlas in UV space
+vec4 shadowSlotRect(int slot) {
+	vec2 scale = vec2(1.0 / SHADOW_ATLAS_COLUMNS, 1.0 / SHADOW_ATLAS_ROWS);
+	vec2 offset = vec2(slot % SHADOW_ATLAS_COLUMNS, slot / SHADOW_ATLAS_COLUMNS) * scale;
+	return vec4(offset, scale);
+}
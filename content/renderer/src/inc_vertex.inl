@@ -7,16 +7,7 @@ layout (location = 5) in ivec4 JointIds;
 layout (location = 6) in vec4 JointWeights;
 layout (location = 7) in vec3 MorphTarget;
 
-layout(set = 0, binding = 0) readonly uniform UniformBufferObject {
-	mat4 view;
-	mat4 projection;
-	mat4 uiView;
-	mat4 uiProjection;
-	vec4 cameraPosition;	// w = [0=perspective, 1=orthographic]
-	vec3 uiCameraPosition;
-	vec2 screenSize;
-	float time;
-};
+#include "inc_globals.inl"
 
 #define LOCATION_HEAD   8
 #define LOCATION_START  LOCATION_HEAD + 4
@@ -0,0 +1,25 @@
+#version 450
+
+layout(location = 0) in vec4 fragColor;
+layout(location = 1) in vec2 fragTexCoords;
+layout(location = 2) in vec2 fragQuadPosition;
+layout(location = 3) in float fragSoftness;
+layout(location = 4) in float fragViewDepth;
+
+layout(binding = 1) uniform sampler2D particleTexture;
+
+layout(location = 0) out vec4 outColor;
+layout(location = 1) out float reveal;
+
+void main(void) {
+	vec4 unWeightedColor = texture(particleTexture, fragTexCoords) * fragColor;
+	if (fragSoftness > 0.0) {
+		// Fades towards the edge of the quad and when the camera is within
+		// softness units of the particle, the scene depth is not sampled so
+		// intersections with opaque geometry are still hard
+		float edge = 1.0 - length(fragQuadPosition);
+		unWeightedColor.a *= smoothstep(0.0, fragSoftness, edge)
+			* smoothstep(0.0, fragSoftness, fragViewDepth);
+	}
+#include "inc_fragment_oit_block.inl"
+}
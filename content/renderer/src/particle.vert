@@ -0,0 +1,33 @@
+#version 460
+
+#include "inc_vertex.inl"
+
+layout(location = LOCATION_START) in vec4 particleColor;
+layout(location = 13) in vec4 uvs;
+layout(location = 14) in vec2 rotationSoftness;
+
+layout(location = 0) out vec4 fragColor;
+layout(location = 1) out vec2 fragTexCoords;
+layout(location = 2) out vec2 fragQuadPosition;
+layout(location = 3) out float fragSoftness;
+layout(location = 4) out float fragViewDepth;
+
+void main() {
+	// The model only holds the position and the uniform size of the particle,
+	// the quad is built facing the camera and rotated around the view axis
+	vec3 center = model[3].xyz;
+	float size = length(model[0].xyz);
+	float s = sin(rotationSoftness.x);
+	float c = cos(rotationSoftness.x);
+	vec2 corner = vec2(Position.x * c - Position.y * s,
+		Position.x * s + Position.y * c) * size;
+	vec3 right = vec3(view[0][0], view[1][0], view[2][0]);
+	vec3 up = vec3(view[0][1], view[1][1], view[2][1]);
+	vec4 viewPos = view * vec4(center + right * corner.x + up * corner.y, 1.0);
+	gl_Position = projection * viewPos;
+	fragColor = Color * particleColor;
+	fragTexCoords = UV0 * uvs.zw + uvs.xy;
+	fragQuadPosition = Position.xy * 2.0;
+	fragSoftness = rotationSoftness.y;
+	fragViewDepth = -viewPos.z;
+}
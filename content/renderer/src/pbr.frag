@@ -0,0 +1,87 @@
+#version 460
+
+#define LIGHT_SHADOWS
+layout(binding = 6) uniform sampler2D shadowMap;
+
+#include "inc_globals.inl"
+#include "inc_lighting.inl"
+#include "inc_pbr.inl"
+
+layout(location = 0) in vec4 fragColor;
+layout(location = 1) in vec2 fragTexCoords;
+layout(location = 2) in vec3 fragNormal;
+layout(location = 3) in vec3 fragWorldPosition;
+layout(location = 4) in float fragReceiveShadows;
+layout(location = 5) in vec4 fragTangent;
+layout(location = 6) in vec4 fragEmissive;
+// x = metallic, y = roughness, z = normal scale, w = occlusion strength
+layout(location = 7) in vec4 fragPBRFactors;
+layout(location = 8) in float fragAlphaCutoff;
+
+layout(binding = 1) uniform sampler2D baseColorMap;
+// Occlusion in r (when not using the occlusion map), roughness in g and
+// metallic in b, matching the glTF packing
+layout(binding = 2) uniform sampler2D metallicRoughnessMap;
+layout(binding = 3) uniform sampler2D normalMap;
+layout(binding = 4) uniform sampler2D occlusionMap;
+layout(binding = 5) uniform sampler2D emissiveMap;
+
+layout(location = 0) out vec4 outColor;
+layout(location = 1) out float reveal;
+
+vec3 surfaceNormal() {
+	vec3 n = normalize(fragNormal);
+	if (!gl_FrontFacing) {
+		n = -n;
+	}
+	vec3 t;
+	vec3 b;
+	if (dot(fragTangent.xyz, fragTangent.xyz) > 0.0001) {
+		t = normalize(fragTangent.xyz - n * dot(n, fragTangent.xyz));
+		b = cross(n, t) * (fragTangent.w < 0.0 ? -1.0 : 1.0);
+	} else {
+		// The mesh has no tangents, build them from the screen derivatives
+		vec3 dpx = dFdx(fragWorldPosition);
+		vec3 dpy = dFdy(fragWorldPosition);
+		vec2 duvx = dFdx(fragTexCoords);
+		vec2 duvy = dFdy(fragTexCoords);
+		vec3 dpyPerp = cross(dpy, n);
+		vec3 dpxPerp = cross(n, dpx);
+		t = dpyPerp * duvx.x + dpxPerp * duvy.x;
+		b = dpyPerp * duvx.y + dpxPerp * duvy.y;
+		float scale = inversesqrt(max(dot(t, t), dot(b, b)));
+		if (isinf(scale) || isnan(scale)) {
+			return n;
+		}
+		t *= scale;
+		b *= scale;
+	}
+	vec3 tn = texture(normalMap, fragTexCoords).xyz * 2.0 - 1.0;
+	tn.xy *= fragPBRFactors.z;
+	return normalize(mat3(t, b, n) * tn);
+}
+
+void main() {
+	vec4 base = texture(baseColorMap, fragTexCoords);
+	PBRSurface surface;
+	surface.albedo = pbrToLinear(base.rgb) * fragColor.rgb;
+	surface.alpha = base.a * fragColor.a;
+	vec4 mr = texture(metallicRoughnessMap, fragTexCoords);
+	surface.roughness = mr.g * fragPBRFactors.y;
+	surface.metallic = mr.b * fragPBRFactors.x;
+	float ao = texture(occlusionMap, fragTexCoords).r;
+	surface.occlusion = 1.0 + fragPBRFactors.w * (ao - 1.0);
+	surface.emissive = pbrToLinear(texture(emissiveMap, fragTexCoords).rgb)
+		* fragEmissive.rgb * fragEmissive.a;
+#ifndef OIT
+	// Opaque materials either ignore the alpha or cut it off (glTF MASK)
+	if (fragAlphaCutoff > 0.0 && surface.alpha < fragAlphaCutoff) {
+		discard;
+	}
+	surface.alpha = 1.0;
+#endif
+	vec4 lit = computePBRLighting(surface, fragWorldPosition, surfaceNormal(),
+		fragReceiveShadows);
+	vec4 unWeightedColor = vec4(pbrToSRGB(clamp(lit.rgb, 0.0, 1.0)), lit.a);
+#include "inc_fragment_oit_block.inl"
+}
@@ -0,0 +1,36 @@
+#version 460
+
+#include "inc_vertex.inl"
+
+layout(location = LOCATION_START) in vec4 baseColor;
+layout(location = LOCATION_START+1) in vec4 emissive;
+layout(location = LOCATION_START+2) in vec4 pbrFactors;
+// x = alpha cutoff, y = receive shadows, packed to stay within the minimum
+// number of vertex input locations
+layout(location = LOCATION_START+3) in vec2 alphaCutoffShadows;
+
+layout(location = 0) out vec4 fragColor;
+layout(location = 1) out vec2 fragTexCoords;
+layout(location = 2) out vec3 fragNormal;
+layout(location = 3) out vec3 fragWorldPosition;
+layout(location = 4) out float fragReceiveShadows;
+layout(location = 5) out vec4 fragTangent;
+layout(location = 6) out vec4 fragEmissive;
+layout(location = 7) out vec4 fragPBRFactors;
+layout(location = 8) out float fragAlphaCutoff;
+
+void main() {
+	// The vertex color isn't used, glTF base color factors are in baseColor
+	fragColor = baseColor;
+	fragTexCoords = UV0;
+	mat3 normalMatrix = mat3(transpose(inverse(model)));
+	fragNormal = normalMatrix * Normal;
+	fragTangent = vec4(mat3(model) * Tangent.xyz, Tangent.w);
+	vec4 wp = model * vec4(Position, 1.0);
+	fragWorldPosition = wp.xyz;
+	fragReceiveShadows = alphaCutoffShadows.y;
+	fragEmissive = emissive;
+	fragPBRFactors = pbrFactors;
+	fragAlphaCutoff = alphaCutoffShadows.x;
+	gl_Position = projection * view * wp;
+}
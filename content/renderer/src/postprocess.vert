@@ -0,0 +1,18 @@
+#version 460
+
+#include "inc_vertex.inl"
+
+layout(location = LOCATION_START) in vec4 params0;
+layout(location = LOCATION_START + 1) in vec4 params1;
+
+layout(location = 0) out vec2 fragTexCoords;
+layout(location = 1) flat out vec4 fragParams0;
+layout(location = 2) flat out vec4 fragParams1;
+
+void main() {
+	fragTexCoords = UV0;
+	fragParams0 = params0;
+	fragParams1 = params1;
+	vec3 pos = vec3(Position.x, -Position.y, Position.z) * 2.0;
+	gl_Position = vec4(pos, 1.0);
+}
@@ -0,0 +1,20 @@
+#version 460
+
+layout(location = 0) in vec2 fragTexCoords;
+layout(location = 1) flat in vec4 fragParams0;
+layout(location = 2) flat in vec4 fragParams1;
+
+layout(binding = 1) uniform sampler2D sceneTexture;
+layout(binding = 2) uniform sampler2D bloomTexture;
+
+layout(location = 0) out vec4 outColor;
+
+// fragParams0.z is the intensity of the glow
+void main() {
+	vec4 scene = texture(sceneTexture, fragTexCoords);
+	vec3 bloom = texture(bloomTexture, fragTexCoords).rgb * fragParams0.z;
+	// The glow is added in linear space, the result can be brighter than 1
+	// and is left for the tone mapping to bring back into range
+	vec3 color = pow(scene.rgb, vec3(2.2)) + bloom;
+	outColor = vec4(pow(color, vec3(1.0 / 2.2)), scene.a);
+}
@@ -0,0 +1,28 @@
+#version 460
+
+layout(location = 0) in vec2 fragTexCoords;
+layout(location = 1) flat in vec4 fragParams0;
+layout(location = 2) flat in vec4 fragParams1;
+
+layout(binding = 1) uniform sampler2D sceneTexture;
+
+layout(location = 0) out vec4 outColor;
+
+// fragParams0 is (threshold, knee, intensity, unused)
+void main() {
+	vec2 texel = 1.0 / vec2(textureSize(sceneTexture, 0));
+	// The target is half resolution, so average the 4 source pixels
+	vec3 color = texture(sceneTexture, fragTexCoords + texel * vec2(-0.5, -0.5)).rgb;
+	color += texture(sceneTexture, fragTexCoords + texel * vec2(0.5, -0.5)).rgb;
+	color += texture(sceneTexture, fragTexCoords + texel * vec2(-0.5, 0.5)).rgb;
+	color += texture(sceneTexture, fragTexCoords + texel * vec2(0.5, 0.5)).rgb;
+	color = pow(color * 0.25, vec3(2.2));
+	float threshold = fragParams0.x;
+	float knee = max(threshold * fragParams0.y, 0.00001);
+	float brightness = max(color.r, max(color.g, color.b));
+	// Quadratic soft knee, the glow fades in over the knee below the threshold
+	float soft = clamp(brightness - threshold + knee, 0.0, 2.0 * knee);
+	soft = (soft * soft) / (4.0 * knee);
+	float contribution = max(soft, brightness - threshold) / max(brightness, 0.00001);
+	outColor = vec4(color * contribution, 1.0);
+}
@@ -0,0 +1,22 @@
+#version 460
+
+layout(location = 0) in vec2 fragTexCoords;
+layout(location = 1) flat in vec4 fragParams0;
+layout(location = 2) flat in vec4 fragParams1;
+
+layout(binding = 1) uniform sampler2D sourceTexture;
+
+layout(location = 0) out vec4 outColor;
+
+const float weights[5] = float[](0.227027, 0.1945946, 0.1216216, 0.054054, 0.016216);
+
+// fragParams0.xy is the direction of the blur scaled by the radius in pixels
+void main() {
+	vec2 step = fragParams0.xy / vec2(textureSize(sourceTexture, 0));
+	vec3 color = texture(sourceTexture, fragTexCoords).rgb * weights[0];
+	for (int i = 1; i < 5; i++) {
+		color += texture(sourceTexture, fragTexCoords + step * float(i)).rgb * weights[i];
+		color += texture(sourceTexture, fragTexCoords - step * float(i)).rgb * weights[i];
+	}
+	outColor = vec4(color, 1.0);
+}
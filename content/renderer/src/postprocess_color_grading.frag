@@ -0,0 +1,29 @@
+#version 460
+
+layout(location = 0) in vec2 fragTexCoords;
+layout(location = 1) flat in vec4 fragParams0;
+layout(location = 2) flat in vec4 fragParams1;
+
+layout(binding = 1) uniform sampler2D sceneTexture;
+layout(binding = 2) uniform sampler2D lutTexture;
+
+layout(location = 0) out vec4 outColor;
+
+// The LUT is the blue slices laid side by side, so a size of 16 is 256x16
+vec3 sampleLUT(vec3 color) {
+	float size = float(textureSize(lutTexture, 0).y);
+	vec3 c = clamp(color, 0.0, 1.0) * (size - 1.0);
+	float slice = floor(c.b);
+	float blend = c.b - slice;
+	vec2 uv = (c.rg + 0.5) / vec2(size * size, size);
+	vec2 a = uv + vec2(slice / size, 0.0);
+	vec2 b = uv + vec2(min(slice + 1.0, size - 1.0) / size, 0.0);
+	return mix(texture(lutTexture, a).rgb, texture(lutTexture, b).rgb, blend);
+}
+
+// fragParams0.x is the contribution of the graded color
+void main() {
+	vec4 scene = texture(sceneTexture, fragTexCoords);
+	vec3 graded = sampleLUT(scene.rgb);
+	outColor = vec4(mix(scene.rgb, graded, fragParams0.x), scene.a);
+}
@@ -0,0 +1,49 @@
+#version 460
+
+layout(location = 0) in vec2 fragTexCoords;
+layout(location = 1) flat in vec4 fragParams0;
+layout(location = 2) flat in vec4 fragParams1;
+
+layout(binding = 1) uniform sampler2D sceneTexture;
+
+layout(location = 0) out vec4 outColor;
+
+#define FXAA_REDUCE_MUL	(1.0 / 8.0)
+#define FXAA_REDUCE_MIN	(1.0 / 128.0)
+
+float luma(vec3 color) {
+	return dot(color, vec3(0.299, 0.587, 0.114));
+}
+
+// fragParams0 is (edge threshold, edge threshold min, span max, unused)
+void main() {
+	vec2 texel = 1.0 / vec2(textureSize(sceneTexture, 0));
+	vec4 center = texture(sceneTexture, fragTexCoords);
+	float lumaNW = luma(texture(sceneTexture, fragTexCoords + vec2(-1.0, -1.0) * texel).rgb);
+	float lumaNE = luma(texture(sceneTexture, fragTexCoords + vec2(1.0, -1.0) * texel).rgb);
+	float lumaSW = luma(texture(sceneTexture, fragTexCoords + vec2(-1.0, 1.0) * texel).rgb);
+	float lumaSE = luma(texture(sceneTexture, fragTexCoords + vec2(1.0, 1.0) * texel).rgb);
+	float lumaM = luma(center.rgb);
+	float lumaMin = min(lumaM, min(min(lumaNW, lumaNE), min(lumaSW, lumaSE)));
+	float lumaMax = max(lumaM, max(max(lumaNW, lumaNE), max(lumaSW, lumaSE)));
+	if (lumaMax - lumaMin < max(fragParams0.y, lumaMax * fragParams0.x)) {
+		outColor = center;
+		return;
+	}
+	vec2 dir = vec2(-((lumaNW + lumaNE) - (lumaSW + lumaSE)),
+		(lumaNW + lumaSW) - (lumaNE + lumaSE));
+	float dirReduce = max((lumaNW + lumaNE + lumaSW + lumaSE) * 0.25 * FXAA_REDUCE_MUL,
+		FXAA_REDUCE_MIN);
+	float rcpDirMin = 1.0 / (min(abs(dir.x), abs(dir.y)) + dirReduce);
+	dir = clamp(dir * rcpDirMin, vec2(-fragParams0.z), vec2(fragParams0.z)) * texel;
+	vec3 rgbA = 0.5 * (texture(sceneTexture, fragTexCoords + dir * (1.0 / 3.0 - 0.5)).rgb
+		+ texture(sceneTexture, fragTexCoords + dir * (2.0 / 3.0 - 0.5)).rgb);
+	vec3 rgbB = rgbA * 0.5 + 0.25 * (texture(sceneTexture, fragTexCoords + dir * -0.5).rgb
+		+ texture(sceneTexture, fragTexCoords + dir * 0.5).rgb);
+	float lumaB = luma(rgbB);
+	if (lumaB < lumaMin || lumaB > lumaMax) {
+		outColor = vec4(rgbA, center.a);
+	} else {
+		outColor = vec4(rgbB, center.a);
+	}
+}
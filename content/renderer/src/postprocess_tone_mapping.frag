@@ -0,0 +1,50 @@
+#version 460
+
+layout(location = 0) in vec2 fragTexCoords;
+layout(location = 1) flat in vec4 fragParams0;
+layout(location = 2) flat in vec4 fragParams1;
+
+layout(binding = 1) uniform sampler2D sceneTexture;
+
+layout(location = 0) out vec4 outColor;
+
+#define TONE_MAP_ACES	0
+#define TONE_MAP_FILMIC	1
+
+// Krzysztof Narkowicz's fit of the ACES curve
+vec3 toneMapACES(vec3 x) {
+	return clamp((x * (2.51 * x + 0.03)) / (x * (2.43 * x + 0.59) + 0.14), 0.0, 1.0);
+}
+
+// John Hable's Uncharted 2 curve
+vec3 hable(vec3 x) {
+	const float a = 0.15;
+	const float b = 0.50;
+	const float c = 0.10;
+	const float d = 0.20;
+	const float e = 0.02;
+	const float f = 0.30;
+	return ((x * (a * x + c * b) + d * e) / (x * (a * x + b) + d * f)) - e / f;
+}
+
+vec3 toneMapFilmic(vec3 x) {
+	const float whitePoint = 11.2;
+	return hable(x * 2.0) / hable(vec3(whitePoint));
+}
+
+// fragParams0 is (exposure scale, operator, unused, unused)
+void main() {
+	vec4 scene = texture(sceneTexture, fragTexCoords);
+	// The scene shaders write gamma encoded colors, the scene images are float
+	// while post-processing is on so these can be above 1 before exposure
+	vec3 color = pow(max(scene.rgb, vec3(0.0)), vec3(2.2)) * fragParams0.x;
+	int operator = int(fragParams0.y + 0.5);
+	if (operator == TONE_MAP_ACES) {
+		color = toneMapACES(color);
+	} else if (operator == TONE_MAP_FILMIC) {
+		color = toneMapFilmic(color);
+	} else {
+		color = clamp(color, 0.0, 1.0);
+	}
+	outColor = vec4(pow(color, vec3(1.0 / 2.2)), scene.a);
+}
@@ -0,0 +1,22 @@
+#version 460
+
+layout(location = 0) in vec2 fragTexCoords;
+layout(location = 1) flat in vec4 fragParams0;
+layout(location = 2) flat in vec4 fragParams1;
+
+layout(binding = 1) uniform sampler2D sceneTexture;
+
+layout(location = 0) out vec4 outColor;
+
+// fragParams0 is (intensity, smoothness, roundness, unused) and fragParams1
+// is the color the edges fade to
+void main() {
+	vec4 scene = texture(sceneTexture, fragTexCoords);
+	vec2 size = vec2(textureSize(sceneTexture, 0));
+	vec2 d = abs(fragTexCoords - 0.5) * fragParams0.x;
+	// A roundness of 1 makes the vignette a circle on any screen shape
+	d.x *= mix(1.0, size.x / size.y, fragParams0.z);
+	float falloff = pow(clamp(1.0 - dot(d, d), 0.0, 1.0), fragParams0.y * 5.0);
+	vec3 color = mix(fragParams1.rgb, scene.rgb, falloff);
+	outColor = vec4(color, scene.a);
+}
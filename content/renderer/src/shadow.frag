@@ -0,0 +1,4 @@
+#version 460
+
+// Only the depth of the shadow casters is written into the shadow atlas
+void main() {}
@@ -0,0 +1,24 @@
+#version 460
+
+#include "inc_vertex.inl"
+#include "inc_shadows.inl"
+
+layout(location = LOCATION_START) in int slot;
+
+out gl_PerVertex {
+	vec4 gl_Position;
+	float gl_ClipDistance[4];
+};
+
+void main() {
+	vec4 clip = shadowMatrices[slot] * model * vec4(Position, 1.0);
+	// Clip to the view of the light so nothing spills into the other slots
+	gl_ClipDistance[0] = clip.w + clip.x;
+	gl_ClipDistance[1] = clip.w - clip.x;
+	gl_ClipDistance[2] = clip.w + clip.y;
+	gl_ClipDistance[3] = clip.w - clip.y;
+	// Move the [-1, 1] view of the light into its slot of the atlas
+	vec4 rect = shadowSlotRect(slot);
+	clip.xy = clip.xy * rect.zw + clip.w * (rect.xy * 2.0 + rect.zw - 1.0);
+	gl_Position = clip;
+}
@@ -0,0 +1,70 @@
+#version 460
+
+// SPLAT_LAYERS_8 blends eight layers using a second splat map for the layers
+// 4 to 7, otherwise only four layers are blended
+#ifdef SPLAT_LAYERS_8
+#define LAYER_BINDING 3
+#define SHADOW_BINDING 11
+#else
+#define LAYER_BINDING 2
+#define SHADOW_BINDING 6
+#endif
+
+#define LIGHT_SHADOWS
+layout(binding = SHADOW_BINDING) uniform sampler2D shadowMap;
+
+#include "inc_globals.inl"
+#include "inc_lighting.inl"
+
+layout(location = 0) in vec4 fragColor;
+layout(location = 1) in vec2 fragTexCoords;
+layout(location = 2) in vec3 fragNormal;
+layout(location = 3) in vec3 fragWorldPosition;
+layout(location = 4) in float fragReceiveShadows;
+layout(location = 5) in vec4 fragTiling;
+
+layout(binding = 1) uniform sampler2D splatMap;
+#ifdef SPLAT_LAYERS_8
+layout(binding = 2) uniform sampler2D splatMap2;
+#endif
+layout(binding = LAYER_BINDING) uniform sampler2D layer0;
+layout(binding = LAYER_BINDING+1) uniform sampler2D layer1;
+layout(binding = LAYER_BINDING+2) uniform sampler2D layer2;
+layout(binding = LAYER_BINDING+3) uniform sampler2D layer3;
+#ifdef SPLAT_LAYERS_8
+layout(binding = LAYER_BINDING+4) uniform sampler2D layer4;
+layout(binding = LAYER_BINDING+5) uniform sampler2D layer5;
+layout(binding = LAYER_BINDING+6) uniform sampler2D layer6;
+layout(binding = LAYER_BINDING+7) uniform sampler2D layer7;
+#endif
+
+layout(location = 0) out vec4 outColor;
+layout(location = 1) out float reveal;
+
+void main() {
+	// The splat weights are normalized so that painting a single channel
+	// doesn't need the others to be cleared
+	vec4 w0 = texture(splatMap, fragTexCoords);
+#ifdef SPLAT_LAYERS_8
+	vec4 w1 = texture(splatMap2, fragTexCoords);
+#else
+	vec4 w1 = vec4(0.0);
+#endif
+	float total = max(dot(w0, vec4(1.0)) + dot(w1, vec4(1.0)), 0.0001);
+	w0 /= total;
+	w1 /= total;
+	vec4 blended = texture(layer0, fragTexCoords * fragTiling.x) * w0.r
+		+ texture(layer1, fragTexCoords * fragTiling.y) * w0.g
+		+ texture(layer2, fragTexCoords * fragTiling.z) * w0.b
+		+ texture(layer3, fragTexCoords * fragTiling.w) * w0.a;
+#ifdef SPLAT_LAYERS_8
+	blended += texture(layer4, fragTexCoords * fragTiling.x) * w1.r
+		+ texture(layer5, fragTexCoords * fragTiling.y) * w1.g
+		+ texture(layer6, fragTexCoords * fragTiling.z) * w1.b
+		+ texture(layer7, fragTexCoords * fragTiling.w) * w1.a;
+#endif
+	vec4 baseColor = blended * fragColor;
+	vec4 unWeightedColor = computeLighting(baseColor, fragWorldPosition,
+		normalize(fragNormal), fragReceiveShadows);
+#include "inc_fragment_oit_block.inl"
+}
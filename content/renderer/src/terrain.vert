@@ -0,0 +1,25 @@
+#version 460
+
+#include "inc_vertex.inl"
+
+layout(location = LOCATION_START) in vec4 color;
+layout(location = LOCATION_START+1) in vec4 tiling;
+layout(location = LOCATION_START+2) in float receiveShadows;
+
+layout(location = 0) out vec4 fragColor;
+layout(location = 1) out vec2 fragTexCoords;
+layout(location = 2) out vec3 fragNormal;
+layout(location = 3) out vec3 fragWorldPosition;
+layout(location = 4) out float fragReceiveShadows;
+layout(location = 5) out vec4 fragTiling;
+
+void main() {
+	fragColor = Color * color;
+	fragTexCoords = UV0;
+	fragNormal = mat3(transpose(inverse(model))) * Normal;
+	vec4 wp = model * vec4(Position, 1.0);
+	fragWorldPosition = wp.xyz;
+	fragReceiveShadows = receiveShadows;
+	fragTiling = tiling;
+	gl_Position = projection * view * wp;
+}
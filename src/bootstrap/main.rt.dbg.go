@@ -64,6 +64,7 @@ func logOps() *slog.HandlerOptions {
 func setupDebug(host *engine.Host) error {
 	cla := buildCLA()
 	connectLoggingServer(host)
+	host.EnableHotReload()
 	if cla.stage != "" {
 		path := strings.ReplaceAll(cla.stage, "\\", "/")
 		if !strings.HasPrefix(path, "content/") {
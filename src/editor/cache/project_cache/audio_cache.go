@@ -0,0 +1,163 @@
+/******************************************************************************/
+/* audio_cache.go                                                             */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package project_cache
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"kaiju/engine/assets/asset_info"
+	"kaiju/platform/audio"
+	"os"
+	"path/filepath"
+)
+
+const cachedAudioVersion = 1
+
+var ErrInvalidCachedAudio = errors.New("the cached audio file is invalid or out of date")
+
+// cachedAudioHeader is written before the raw little endian float32 samples
+type cachedAudioHeader struct {
+	Magic      [4]byte
+	Version    uint32
+	Channels   uint32
+	SampleRate uint32
+	LoopStart  uint32
+	LoopEnd    uint32
+	Volume     float32
+	Samples    uint64
+}
+
+// isValid makes sure that the header was written by this version and that
+// the samples it describes are exactly what is left in the file
+func (h *cachedAudioHeader) isValid(fileSize int64) bool {
+	if string(h.Magic[:]) != "KAUD" || h.Version != cachedAudioVersion {
+		return false
+	}
+	if h.Channels == 0 || h.SampleRate == 0 {
+		return false
+	}
+	data := fileSize - int64(binary.Size(h))
+	if data < 0 || h.Samples != uint64(data)/4 || data%4 != 0 {
+		return false
+	}
+	if h.Samples%uint64(h.Channels) != 0 {
+		return false
+	}
+	frames := h.Samples / uint64(h.Channels)
+	return uint64(h.LoopStart) <= frames && uint64(h.LoopEnd) <= frames
+}
+
+func toCachedAudioPath(path string, adiID string) string {
+	return filepath.Join(path, adiID+".pcm")
+}
+
+// CacheAudio writes the already converted clip into the project cache for
+// the given ADI id so that it doesn't need to be decoded and resampled when
+// it is loaded at runtime
+func CacheAudio(adiID string, clip *audio.Clip) error {
+	path := cachePath(audioCache)
+	f, err := os.Create(toCachedAudioPath(path, adiID))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	w := bufio.NewWriter(f)
+	header := cachedAudioHeader{
+		Magic:      [4]byte{'K', 'A', 'U', 'D'},
+		Version:    cachedAudioVersion,
+		Channels:   uint32(clip.Channels),
+		SampleRate: uint32(clip.SampleRate),
+		LoopStart:  uint32(clip.LoopStart),
+		LoopEnd:    uint32(clip.LoopEnd),
+		Volume:     clip.Volume,
+		Samples:    uint64(len(clip.Samples)),
+	}
+	if err := binary.Write(w, binary.LittleEndian, &header); err != nil {
+		return err
+	}
+	if err := binary.Write(w, binary.LittleEndian, clip.Samples); err != nil {
+		return err
+	}
+	return w.Flush()
+}
+
+// LoadCachedAudio reads the clip that #CacheAudio wrote for the given ADI id.
+// The header is checked against the size of the file before anything is
+// allocated, so a truncated or corrupt file returns #ErrInvalidCachedAudio.
+func LoadCachedAudio(adiID string) (*audio.Clip, error) {
+	path := filepath.Join(CacheFolder, audioCache)
+	f, err := os.Open(toCachedAudioPath(path, adiID))
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	stat, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	r := bufio.NewReader(f)
+	var header cachedAudioHeader
+	if err := binary.Read(r, binary.LittleEndian, &header); err != nil {
+		return nil, err
+	}
+	if !header.isValid(stat.Size()) {
+		return nil, ErrInvalidCachedAudio
+	}
+	clip := &audio.Clip{
+		Samples:    make([]float32, header.Samples),
+		Channels:   int(header.Channels),
+		SampleRate: int(header.SampleRate),
+		Volume:     header.Volume,
+		LoopStart:  int(header.LoopStart),
+		LoopEnd:    int(header.LoopEnd),
+	}
+	if err := binary.Read(r, binary.LittleEndian, clip.Samples); err != nil {
+		return nil, err
+	}
+	return clip, nil
+}
+
+func DeleteAudio(adi asset_info.AssetDatabaseInfo) error {
+	path := cachePath(audioCache)
+	err := os.Remove(toCachedAudioPath(path, adi.ID))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
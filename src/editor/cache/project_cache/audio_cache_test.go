@@ -0,0 +1,107 @@
+/******************************************************************************/
+/* audio_cache_test.go                                                        */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package project_cache
+
+import (
+	"errors"
+	"kaiju/platform/audio"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+// chdirCache moves the test into an empty folder, the cache folders that
+// were created for an earlier test don't exist there
+func chdirCache(t *testing.T) {
+	t.Chdir(t.TempDir())
+	createdCachePathsLock.Lock()
+	clear(createdCachePaths)
+	createdCachePathsLock.Unlock()
+}
+
+func TestCachedAudioRoundTrip(t *testing.T) {
+	chdirCache(t)
+	clip := &audio.Clip{
+		Samples:    []float32{0, 0.25, -0.25, 0.5, -0.5, 1},
+		Channels:   2,
+		SampleRate: 48000,
+		Volume:     0.5,
+		LoopStart:  1,
+		LoopEnd:    3,
+	}
+	if err := CacheAudio("clip", clip); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := LoadCachedAudio("clip")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(loaded.Samples, clip.Samples) || loaded.Channels != clip.Channels ||
+		loaded.SampleRate != clip.SampleRate || loaded.Volume != clip.Volume ||
+		loaded.LoopStart != clip.LoopStart || loaded.LoopEnd != clip.LoopEnd {
+		t.Errorf("expected the loaded clip to match the cached clip, got %+v", loaded)
+	}
+}
+
+func TestCachedAudioInvalid(t *testing.T) {
+	chdirCache(t)
+	clip := &audio.Clip{Samples: make([]float32, 64), Channels: 2, SampleRate: 48000}
+	if err := CacheAudio("clip", clip); err != nil {
+		t.Fatal(err)
+	}
+	path := toCachedAudioPath(filepath.Join(CacheFolder, audioCache), "clip")
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	truncated := data[:len(data)-10]
+	huge := slices.Clone(data)
+	// Samples is the last field of the header
+	for i := 28; i < 36; i++ {
+		huge[i] = 0xFF
+	}
+	for name, corrupt := range map[string][]byte{"truncated": truncated, "huge": huge} {
+		if err := os.WriteFile(path, corrupt, os.ModePerm); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := LoadCachedAudio("clip"); !errors.Is(err, ErrInvalidCachedAudio) {
+			t.Errorf("%s: expected an invalid cached audio error, got %v", name, err)
+		}
+	}
+}
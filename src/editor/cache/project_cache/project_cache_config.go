@@ -40,21 +40,29 @@ package project_cache
 import (
 	"os"
 	"path/filepath"
+	"sync"
 )
 
 const (
 	CacheFolder = ".cache"
 	editorFile  = "editor.json"
 	meshCache   = "meshes"
+	spriteCache = "sprite_sheets"
+	audioCache  = "audio"
 )
 
-var createdCachePaths = make(map[string]bool)
+var (
+	createdCachePaths     = make(map[string]bool)
+	createdCachePathsLock sync.Mutex
+)
 
 func cachePath(category string) string {
 	// TODO:  If the developer manually deletes the .cache folder
 	// or any of the sub-folders, then we'd have a problem due to
 	// the createdCachePaths check here
 	path := filepath.Join(CacheFolder, category)
+	createdCachePathsLock.Lock()
+	defer createdCachePathsLock.Unlock()
 	if _, ok := createdCachePaths[path]; !ok {
 		os.MkdirAll(path, os.ModePerm)
 		createdCachePaths[path] = true
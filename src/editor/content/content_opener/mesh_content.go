@@ -1,6 +1,7 @@
 package content_opener
 
 import (
+	"kaiju/engine/assets"
 	"kaiju/engine/assets/asset_importer"
 	"kaiju/engine/assets/asset_info"
 	"kaiju/editor/cache/project_cache"
@@ -19,10 +20,18 @@ func loadMesh(host *engine.Host, adi asset_info.AssetDatabaseInfo, e *engine.Ent
 		if material, err = host.MaterialCache().Material(meta.Material); err != nil {
 			return err
 		}
-		// TODO:  We need to create or generate shader data given the definition
-		data = &rendering.ShaderDataBasic{
-			ShaderDataBase: rendering.NewShaderDataBase(),
-			Color:          matrix.ColorWhite(),
+		if meta.PBR != nil {
+			data = rendering.NewShaderDataPBR(*meta.PBR)
+		} else if meta.Material == assets.MaterialDefinitionBasicLit ||
+			meta.Material == assets.MaterialDefinitionBasicLitTransparent {
+			// The lit shaders read whether the instance receives shadows
+			data = rendering.NewShaderDataLit(matrix.ColorWhite())
+		} else {
+			// TODO:  We need to create or generate shader data given the definition
+			data = &rendering.ShaderDataBasic{
+				ShaderDataBase: rendering.NewShaderDataBase(),
+				Color:          matrix.ColorWhite(),
+			}
 		}
 	} else {
 		if material, err = host.MaterialCache().Material("basic"); err != nil {
@@ -0,0 +1,19 @@
+package content_opener
+
+import (
+	"kaiju/editor/editor_config"
+	"kaiju/editor/editor_interface"
+	"kaiju/editor/ui/shader_designer"
+	"kaiju/engine/assets/asset_info"
+)
+
+type ParticleEmitterOpener struct{}
+
+func (o ParticleEmitterOpener) Handles(adi asset_info.AssetDatabaseInfo) bool {
+	return adi.Type == editor_config.AssetTypeParticleEmitter
+}
+
+func (o ParticleEmitterOpener) Open(adi asset_info.AssetDatabaseInfo, ed editor_interface.Editor) error {
+	shader_designer.OpenParticleEmitter(adi.Path, ed.Host().LogStream)
+	return nil
+}
@@ -0,0 +1,19 @@
+package content_opener
+
+import (
+	"kaiju/editor/editor_config"
+	"kaiju/editor/editor_interface"
+	"kaiju/editor/ui/shader_designer"
+	"kaiju/engine/assets/asset_info"
+)
+
+type PostProcessOpener struct{}
+
+func (o PostProcessOpener) Handles(adi asset_info.AssetDatabaseInfo) bool {
+	return adi.Type == editor_config.AssetTypePostProcess
+}
+
+func (o PostProcessOpener) Open(adi asset_info.AssetDatabaseInfo, ed editor_interface.Editor) error {
+	shader_designer.OpenPostProcess(adi.Path, ed.Host().LogStream)
+	return nil
+}
@@ -41,38 +41,61 @@ type FileExtension = string
 type AssetType = string
 
 const (
-	FileExtensionH              FileExtension = ".h"
-	FileExtensionC              FileExtension = ".c"
-	FileExtensionGo             FileExtension = ".go"
-	FileExtensionMap            FileExtension = ".map"
-	FileExtensionObj            FileExtension = ".obj"
-	FileExtensionGlb            FileExtension = ".glb"
-	FileExtensionGltf           FileExtension = ".gltf"
-	FileExtensionPng            FileExtension = ".png"
-	FileExtensionMesh           FileExtension = ".msh"
-	FileExtensionStage          FileExtension = ".stg"
-	FileExtensionHTML           FileExtension = ".html"
-	FileExtensionShader         FileExtension = ".shader"
-	FileExtensionRenderPass     FileExtension = ".renderpass"
-	FileExtensionShaderPipeline FileExtension = ".shaderpipeline"
-	FileExtensionMaterial       FileExtension = ".material"
-	FileExtensionAssetDbInfo    FileExtension = ".adi"
+	FileExtensionH               FileExtension = ".h"
+	FileExtensionC               FileExtension = ".c"
+	FileExtensionGo              FileExtension = ".go"
+	FileExtensionMap             FileExtension = ".map"
+	FileExtensionObj             FileExtension = ".obj"
+	FileExtensionGlb             FileExtension = ".glb"
+	FileExtensionGltf            FileExtension = ".gltf"
+	FileExtensionPng             FileExtension = ".png"
+	FileExtensionJpg             FileExtension = ".jpg"
+	FileExtensionJpeg            FileExtension = ".jpeg"
+	FileExtensionTga             FileExtension = ".tga"
+	FileExtensionHdr             FileExtension = ".hdr"
+	FileExtensionDds             FileExtension = ".dds"
+	FileExtensionKtx             FileExtension = ".ktx"
+	FileExtensionKtx2            FileExtension = ".ktx2"
+	FileExtensionMesh            FileExtension = ".msh"
+	FileExtensionStage           FileExtension = ".stg"
+	FileExtensionHTML            FileExtension = ".html"
+	FileExtensionShader          FileExtension = ".shader"
+	FileExtensionRenderPass      FileExtension = ".renderpass"
+	FileExtensionRenderGraph     FileExtension = ".rendergraph"
+	FileExtensionShaderPipeline  FileExtension = ".shaderpipeline"
+	FileExtensionMaterial        FileExtension = ".material"
+	FileExtensionParticleEmitter FileExtension = ".particle"
+	FileExtensionPostProcess     FileExtension = ".postprocess"
+	FileExtensionJson            FileExtension = ".json"
+	FileExtensionWav             FileExtension = ".wav"
+	FileExtensionOgg             FileExtension = ".ogg"
+	FileExtensionMp3             FileExtension = ".mp3"
+	FileExtensionFlac            FileExtension = ".flac"
+	FileExtensionTtf             FileExtension = ".ttf"
+	FileExtensionOtf             FileExtension = ".otf"
+	FileExtensionAssetDbInfo     FileExtension = ".adi"
 )
 
 const (
-	AssetTypeH              AssetType = "h"
-	AssetTypeC              AssetType = "c"
-	AssetTypeGo             AssetType = "go"
-	AssetTypeMap            AssetType = "map"
-	AssetTypeObj            AssetType = "obj"
-	AssetTypeGlb            AssetType = "glb"
-	AssetTypeGltf           AssetType = "gltf"
-	AssetTypeImage          AssetType = "image"
-	AssetTypeMesh           AssetType = "mesh"
-	AssetTypeStage          AssetType = "stg"
-	AssetTypeHTML           AssetType = "html"
-	AssetTypeShader         AssetType = "shader"
-	AssetTypeRenderPass     AssetType = "renderpass"
-	AssetTypeShaderPipeline AssetType = "shaderpipeline"
-	AssetTypeMaterial       AssetType = "material"
+	AssetTypeH               AssetType = "h"
+	AssetTypeC               AssetType = "c"
+	AssetTypeGo              AssetType = "go"
+	AssetTypeMap             AssetType = "map"
+	AssetTypeObj             AssetType = "obj"
+	AssetTypeGlb             AssetType = "glb"
+	AssetTypeGltf            AssetType = "gltf"
+	AssetTypeImage           AssetType = "image"
+	AssetTypeMesh            AssetType = "mesh"
+	AssetTypeStage           AssetType = "stg"
+	AssetTypeHTML            AssetType = "html"
+	AssetTypeShader          AssetType = "shader"
+	AssetTypeRenderPass      AssetType = "renderpass"
+	AssetTypeRenderGraph     AssetType = "rendergraph"
+	AssetTypeShaderPipeline  AssetType = "shaderpipeline"
+	AssetTypeMaterial        AssetType = "material"
+	AssetTypeParticleEmitter AssetType = "particle"
+	AssetTypePostProcess     AssetType = "postprocess"
+	AssetTypeSpriteSheet     AssetType = "spritesheet"
+	AssetTypeAudio           AssetType = "audio"
+	AssetTypeFont            AssetType = "font"
 )
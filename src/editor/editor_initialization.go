@@ -78,7 +78,10 @@ func setupEditorWindow(ed *Editor, logStream *logging.LogStream) {
 	ed.container.Host.InitializeAudio()
 	editor_window.OpenWindow(ed,
 		engine.DefaultWindowWidth, engine.DefaultWindowHeight, -1, -1)
-	ed.RunOnHost(func() { addConsole(ed) })
+	ed.RunOnHost(func() {
+		addConsole(ed)
+		ed.container.Host.EnableHotReload()
+	})
 }
 
 func waitForProjectSelectWindow(ed *Editor) (string, error) {
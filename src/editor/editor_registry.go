@@ -47,12 +47,27 @@ func registerAssetImporters(ed *Editor) {
 	ed.assetImporters.Register(asset_importer.GlbImporter{})
 	ed.assetImporters.Register(asset_importer.GltfImporter{})
 	ed.assetImporters.Register(asset_importer.PngImporter{})
+	ed.assetImporters.Register(asset_importer.JpegImporter{})
+	ed.assetImporters.Register(asset_importer.TgaImporter{})
+	ed.assetImporters.Register(asset_importer.HdrImporter{})
+	ed.assetImporters.Register(asset_importer.DdsImporter{})
+	ed.assetImporters.Register(asset_importer.KtxImporter{})
 	ed.assetImporters.Register(asset_importer.StageImporter{})
 	ed.assetImporters.Register(asset_importer.HtmlImporter{})
 	ed.assetImporters.Register(asset_importer.ShaderImporter{})
 	ed.assetImporters.Register(asset_importer.RenderPassImporter{})
+	ed.assetImporters.Register(asset_importer.RenderGraphImporter{})
 	ed.assetImporters.Register(asset_importer.ShaderPipelineImporter{})
 	ed.assetImporters.Register(asset_importer.MaterialImporter{})
+	ed.assetImporters.Register(asset_importer.ParticleEmitterImporter{})
+	ed.assetImporters.Register(asset_importer.PostProcessImporter{})
+	ed.assetImporters.Register(asset_importer.AsepriteImporter{})
+	ed.assetImporters.Register(asset_importer.TexturePackerImporter{})
+	ed.assetImporters.Register(asset_importer.WavImporter{})
+	ed.assetImporters.Register(asset_importer.OggImporter{})
+	ed.assetImporters.Register(asset_importer.Mp3Importer{})
+	ed.assetImporters.Register(asset_importer.FlacImporter{})
+	ed.assetImporters.Register(asset_importer.FontImporter{})
 }
 
 func registerContentOpeners(ed *Editor) {
@@ -66,4 +81,6 @@ func registerContentOpeners(ed *Editor) {
 	ed.contentOpener.Register(content_opener.RenderPassOpener{})
 	ed.contentOpener.Register(content_opener.ShaderPipelineOpener{})
 	ed.contentOpener.Register(content_opener.MaterialOpener{})
+	ed.contentOpener.Register(content_opener.ParticleEmitterOpener{})
+	ed.contentOpener.Register(content_opener.PostProcessOpener{})
 }
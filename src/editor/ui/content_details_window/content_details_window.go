@@ -1,6 +1,7 @@
 package content_details_window
 
 import (
+	"kaiju/editor/editor_config"
 	"kaiju/editor/editor_interface"
 	"kaiju/editor/ui/details_common"
 	"kaiju/engine/assets/asset_importer"
@@ -190,6 +191,15 @@ func (d *ContentDetails) saveAdis() {
 		if err := asset_info.Write(d.adis[i]); err != nil {
 			slog.Error("failed to update the asset database info",
 				"asset", d.adis[i].Path, "error", err)
+			continue
+		}
+		// The mip levels and compression of images are generated on import,
+		// so they need to be imported again for the changes to take effect
+		if d.adis[i].Type == editor_config.AssetTypeImage {
+			if err := d.editor.ImportRegistry().Import(d.adis[i].Path); err != nil {
+				slog.Error("failed to re-import the image",
+					"asset", d.adis[i].Path, "error", err)
+			}
 		}
 	}
 }
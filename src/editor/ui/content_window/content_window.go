@@ -248,7 +248,7 @@ func (s *ContentWindow) listSearch() {
 			slog.Error(err.Error())
 			return nil
 		}
-		if filepath.Ext(info.Name()) == asset_info.InfoExtension {
+		if asset_info.IsImportFile(info.Name()) {
 			return nil
 		}
 		name := strings.ToLower(info.Name())
@@ -271,7 +271,7 @@ func (s *ContentWindow) listAll() {
 	}
 	s.Dir = make([]contentEntry, 0, len(dir))
 	for i := range dir {
-		if filepath.Ext(dir[i].Name()) != asset_info.InfoExtension {
+		if !asset_info.IsImportFile(dir[i].Name()) {
 			s.Dir = append(s.Dir, contentEntry{
 				Path:  filepath.Join(s.path, dir[i].Name()),
 				Name:  dir[i].Name(),
@@ -53,7 +53,7 @@ import (
 	"kaiju/engine/ui/markup"
 	"kaiju/engine/ui/markup/document"
 	"kaiju/klib"
-	"kaiju/platform/audio/audio_system"
+	"kaiju/platform/audio"
 	"log/slog"
 )
 
@@ -245,11 +245,11 @@ func (m *Menu) setupConsoleCommands() {
 	})
 
 	c.AddCommand("audio.test", "Tests playback of a wav", func(host *engine.Host, _ string) string {
-		wav, err := audio_system.LoadWav(host.AssetDatabase(), "editor/audio/sfx/fanfare.wav")
+		clip, err := host.LoadAudioClip("editor/audio/sfx/fanfare.wav")
 		if err != nil {
 			return err.Error()
 		}
-		host.Audio().Play(wav)
+		host.Audio().PlayClip(clip, audio.DefaultPlayOptions())
 		return "Playing fanfare.wav"
 	})
 }
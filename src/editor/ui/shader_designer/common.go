@@ -1,20 +1,34 @@
 package shader_designer
 
 import (
+	"kaiju/editor/editor_config"
 	"kaiju/engine/ui/markup/document"
 	"kaiju/engine/ui"
 )
 
 const (
-	shaderSrcFolder  = "content/renderer/src"
-	shaderSpvFolder  = "content/renderer/spv"
-	shaderFolder     = "content/renderer/shaders"
-	renderPassFolder = "content/renderer/passes"
-	pipelineFolder   = "content/renderer/pipelines"
-	materialFolder   = "content/renderer/materials"
-	texturesFolder   = "content/textures"
+	shaderSrcFolder   = "content/renderer/src"
+	shaderSpvFolder   = "content/renderer/spv"
+	shaderFolder      = "content/renderer/shaders"
+	renderPassFolder  = "content/renderer/passes"
+	pipelineFolder    = "content/renderer/pipelines"
+	materialFolder    = "content/renderer/materials"
+	texturesFolder    = "content/textures"
+	particleFolder    = "content/particles"
+	postProcessFolder = "content/postprocess"
 )
 
+var textureExtensions = []string{
+	editor_config.FileExtensionPng,
+	editor_config.FileExtensionJpg,
+	editor_config.FileExtensionJpeg,
+	editor_config.FileExtensionTga,
+	editor_config.FileExtensionHdr,
+	editor_config.FileExtensionDds,
+	editor_config.FileExtensionKtx,
+	editor_config.FileExtensionKtx2,
+}
+
 func showTooltip(options map[string]string, e *document.Element) {
 	id := e.Attribute("data-tooltip")
 	tip, ok := options[id]
@@ -97,6 +97,8 @@ func setObjectValueFromUI(obj any, e *document.Element) {
 			}
 		}
 		v.Set(reflect.ValueOf(slice))
+	} else if v.Kind() == reflect.Array {
+		setArrayValueFromString(v, e.UI.ToInput().Text())
 	} else {
 		var val reflect.Value
 		switch e.UI.Type() {
@@ -131,9 +133,6 @@ func reflectUIStructure(obj any, path string, fallbackOptions map[string][]strin
 			RootPath: path,
 			TipKey:   tag.Get("tip"),
 		}
-		if d := tag.Get("default"); d != "" {
-			field.Value = d
-		}
 		if field.TipKey == "" {
 			field.TipKey = field.Name
 		}
@@ -158,6 +157,14 @@ func reflectUIStructure(obj any, path string, fallbackOptions map[string][]strin
 					field.Type = "bitmask"
 				}
 			}
+		} else if kind == reflect.Array {
+			// Arrays like vectors and colors are edited as comma separated text
+			parts := make([]string, f.Len())
+			for j := range parts {
+				parts[j] = fmt.Sprint(f.Index(j).Interface())
+			}
+			field.Type = "array"
+			field.Value = strings.Join(parts, ", ")
 		} else if kind == reflect.Slice || kind == reflect.Struct {
 			p := field.FullPath()
 			if kind == reflect.Slice {
@@ -179,6 +186,24 @@ func reflectUIStructure(obj any, path string, fallbackOptions map[string][]strin
 	return section
 }
 
+// setArrayValueFromString sets the elements of the array from comma
+// separated text, elements missing from the text are left unchanged
+func setArrayValueFromString(v reflect.Value, text string) {
+	elem := v.Type().Elem()
+	parts := strings.Split(text, ",")
+	for i := range min(len(parts), v.Len()) {
+		str := strings.TrimSpace(parts[i])
+		if str == "" {
+			continue
+		}
+		res := klib.StringToTypeValue(elem.Kind().String(), str)
+		if res == nil {
+			continue
+		}
+		v.Index(i).Set(reflect.ValueOf(res).Convert(elem))
+	}
+}
+
 func reflectAddToSlice(obj any, e *document.Element) {
 	v := reflectObjectValueFromUI(obj, e)
 	v.Set(reflect.Append(v, reflect.Zero(v.Type().Elem())))
@@ -1,8 +1,11 @@
 package shader_designer
 
 var materialTooltips = map[string]string{
-	"Shader":         "The target shader for this material to use",
-	"RenderPass":     "The render pass that this material will use",
-	"ShaderPipeline": "The shader pipeline that this material will use",
-	"Texture":        "A texture attachment for the material",
+	"Shader":          "The target shader for this material to use",
+	"RenderPass":      "The render pass that this material will use",
+	"ShaderPipeline":  "The shader pipeline that this material will use",
+	"Texture":         "A texture attachment for the material",
+	"Parameters":      "The default values of the parameters that the shader declares in its MaterialParameters uniform block",
+	"Value":           "The value of the parameter as comma separated numbers, a float or int only uses the first number and a color is R, G, B, A",
+	"RenderPassImage": "The name of the image attachment in the texture's render pass to use as the texture, like shadow.depth. Leave blank to use the first image of the render pass",
 }
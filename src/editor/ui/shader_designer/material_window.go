@@ -12,6 +12,7 @@ import (
 	"log/slog"
 	"os"
 	"path/filepath"
+	"slices"
 )
 
 func setupMaterialDoc(win *ShaderDesigner) {
@@ -48,7 +49,12 @@ func collectShaderPipelinesOptions() []string {
 }
 
 func collectTextureOptions() []string {
-	return collectSpecificFileOptions(texturesFolder, editor_config.FileExtensionPng)
+	options := []string{}
+	for _, ext := range textureExtensions {
+		options = append(options, collectSpecificFileOptions(texturesFolder, ext)...)
+	}
+	slices.Sort(options)
+	return options
 }
 
 func (win *ShaderDesigner) reloadMaterialDoc() {
@@ -58,6 +64,7 @@ func (win *ShaderDesigner) reloadMaterialDoc() {
 		sy = content.UIPanel.ScrollY()
 		win.materialDoc.Destroy()
 	}
+	win.syncMaterialParameters()
 	listings := map[string][]string{}
 	listings["Shader"] = collectShaderOptions()
 	listings["RenderPass"] = collectRenderPassOptions()
@@ -96,6 +103,43 @@ func (win *ShaderDesigner) materialRemoveFromSlice(e *document.Element) {
 
 func (win *ShaderDesigner) materialValueChanged(e *document.Element) {
 	setObjectValueFromUI(&win.material, e)
+	// The parameters of the material come from the shader
+	if e.Attribute("data-path") == "Shader" {
+		win.reloadMaterialDoc()
+	}
+}
+
+// syncMaterialParameters lists the parameters that the shader of the material
+// declares, parameters keep the values already set and the ones the shader no
+// longer declares are removed
+func (win *ShaderDesigner) syncMaterialParameters() {
+	if win.material.Shader == "" {
+		return
+	}
+	data, err := os.ReadFile(win.material.Shader)
+	if err != nil {
+		slog.Error("failed to read the shader of the material", "file", win.material.Shader, "error", err)
+		return
+	}
+	sd := rendering.ShaderData{}
+	if err := json.Unmarshal(data, &sd); err != nil {
+		slog.Error("failed to unmarshal the shader data", "error", err)
+		return
+	}
+	compiled := sd.Compile()
+	fields := rendering.MaterialParametersLayout(&compiled)
+	params := make([]rendering.MaterialParameterData, len(fields))
+	for i := range fields {
+		params[i].Name = fields[i].Name
+		params[i].Value = fields[i].Type.Default()
+		idx := slices.IndexFunc(win.material.Parameters, func(p rendering.MaterialParameterData) bool {
+			return p.Name == fields[i].Name
+		})
+		if idx >= 0 {
+			params[i].Value = win.material.Parameters[idx].Value
+		}
+	}
+	win.material.Parameters = params
 }
 
 func loadMaterialData(path string) (rendering.MaterialData, bool) {
@@ -126,6 +170,10 @@ func (win *ShaderDesigner) materialSave(e *document.Element) {
 			"folder", materialFolder, "error", err)
 	}
 	path := filepath.Join(materialFolder, win.material.Name+editor_config.FileExtensionMaterial)
+	// Materials generated by model imports are named by their file path
+	if filepath.Ext(win.material.Name) == editor_config.FileExtensionMaterial {
+		path = win.material.Name
+	}
 	if _, err := os.Stat(path); err == nil {
 		ok := <-alert.New("Overwrite?", "You are about to overwrite a material with the same name, would you like to continue?", "Yes", "No", win.man.Host)
 		if !ok {
@@ -0,0 +1,44 @@
+package shader_designer
+
+var particleEmitterTooltips = map[string]string{
+	"Name":              "The name of the emitter, it is saved to content/particles with this name",
+	"MaxParticles":      "The most particles that can be alive at the same time",
+	"Duration":          "The length in seconds of one cycle of the emitter, bursts are timed within the cycle",
+	"Loop":              "Start the cycle over once it has finished, otherwise the emitter stops",
+	"WorldSpace":        "Particles stay where they were spawned when the emitter moves, otherwise they move with it",
+	"Shape":             "The shape that particles are spawned in",
+	"ShapeRadius":       "The radius of the sphere or the base of the cone",
+	"ShapeAngle":        "The angle in degrees between the cone's axis and its side",
+	"ShapeSize":         "The full size of the box as x, y, z",
+	"ShapeMesh":         "The model file (.gltf, .glb or .obj) that particles spawn on the surface of",
+	"EmitFromShell":     "Spawn on the surface of the shape rather than inside of it",
+	"SpawnRate":         "How many particles are spawned every second",
+	"Bursts":            "Groups of particles that are spawned at once at a time within the cycle",
+	"Time":              "The time in seconds within the cycle, or the normalized lifetime (0 to 1) for curve keys",
+	"Count":             "How many particles the burst spawns",
+	"Cycles":            "How many times the burst repeats, 0 is once",
+	"Interval":          "Seconds between each repeat of the burst",
+	"LifetimeMin":       "The shortest time in seconds that a particle lives",
+	"LifetimeMax":       "The longest time in seconds that a particle lives",
+	"SpeedMin":          "The slowest starting speed of a particle",
+	"SpeedMax":          "The fastest starting speed of a particle",
+	"SizeMin":           "The smallest starting size of a particle",
+	"SizeMax":           "The largest starting size of a particle",
+	"RotationMin":       "The smallest starting rotation of a particle in degrees",
+	"RotationMax":       "The largest starting rotation of a particle in degrees",
+	"AngularVelocity":   "How fast the particles rotate in degrees per second",
+	"StartColor":        "The starting color of the particles as r, g, b, a",
+	"Gravity":           "The acceleration applied to the particles as x, y, z",
+	"Drag":              "The fraction of the velocity that is lost every second",
+	"SizeOverLifetime":  "Scales the size of the particle over its lifetime",
+	"SpeedOverLifetime": "Scales the speed of the particle over its lifetime",
+	"ColorOverLifetime": "Multiplies the color of the particle over its lifetime",
+	"Value":             "The value of the curve at this key",
+	"Color":             "The color of the gradient at this key as r, g, b, a",
+	"Texture":           "The texture of the particles",
+	"FlipbookColumns":   "How many columns of frames are in the texture",
+	"FlipbookRows":      "How many rows of frames are in the texture",
+	"FlipbookFPS":       "Frames played per second, 0 plays all of the frames once over the particle's lifetime",
+	"Softness":          "Fades the particle out towards the edges and as the camera gets close, 0 is a hard edge",
+	"Seed":              "Spawns the same particles every time the emitter plays, 0 picks a random seed",
+}
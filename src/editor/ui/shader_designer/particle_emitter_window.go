@@ -0,0 +1,122 @@
+package shader_designer
+
+import (
+	"encoding/json"
+	"kaiju/editor/alert"
+	"kaiju/editor/editor_config"
+	"kaiju/engine/systems/logging"
+	"kaiju/engine/systems/particles"
+	"kaiju/engine/ui"
+	"kaiju/engine/ui/markup"
+	"kaiju/engine/ui/markup/document"
+	"log/slog"
+	"os"
+	"path/filepath"
+)
+
+func (win *ShaderDesigner) reloadParticleEmitterDoc() {
+	sy := float32(0)
+	if win.emitterDoc != nil {
+		content := win.emitterDoc.GetElementsByClass("topFields")[0]
+		sy = content.UIPanel.ScrollY()
+		win.emitterDoc.Destroy()
+	}
+	listings := map[string][]string{}
+	listings["Shape"] = particles.Shapes
+	listings["Texture"] = collectTextureOptions()
+	data := reflectUIStructure(&win.particleEmitter, "", listings)
+	data.Name = "Particle Emitter Editor"
+	win.emitterDoc, _ = markup.DocumentFromHTMLAssetRooted(win.man, dataInputHTML,
+		data, map[string]func(*document.Element){
+			"showTooltip":     showParticleEmitterTooltip,
+			"valueChanged":    win.particleEmitterValueChanged,
+			"returnHome":      win.returnHome,
+			"addToSlice":      win.particleEmitterAddToSlice,
+			"removeFromSlice": win.particleEmitterRemoveFromSlice,
+			"saveData":        win.particleEmitterSave,
+		}, win.root)
+	if sy != 0 {
+		content := win.emitterDoc.GetElementsByClass("topFields")[0]
+		win.man.Host.RunAfterFrames(2, func() {
+			content.UIPanel.SetScrollY(sy)
+		})
+	}
+}
+
+func showParticleEmitterTooltip(e *document.Element) {
+	showTooltip(particleEmitterTooltips, e)
+}
+
+func (win *ShaderDesigner) particleEmitterAddToSlice(e *document.Element) {
+	reflectAddToSlice(&win.particleEmitter, e)
+	win.reloadParticleEmitterDoc()
+}
+
+func (win *ShaderDesigner) particleEmitterRemoveFromSlice(e *document.Element) {
+	reflectRemoveFromSlice(&win.particleEmitter, e)
+	win.reloadParticleEmitterDoc()
+}
+
+func (win *ShaderDesigner) particleEmitterValueChanged(e *document.Element) {
+	setObjectValueFromUI(&win.particleEmitter, e)
+}
+
+func loadParticleEmitterData(path string) (particles.EmitterData, bool) {
+	p := particles.DefaultEmitterData()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		slog.Error("failed to load the particle emitter file", "file", path, "error", err)
+		return p, false
+	}
+	if err := json.Unmarshal(data, &p); err != nil {
+		slog.Error("failed to unmarshal the particle emitter data", "error", err)
+		return p, false
+	}
+	return p, true
+}
+
+func OpenParticleEmitter(path string, logStream *logging.LogStream) {
+	if p, ok := loadParticleEmitterData(path); ok {
+		s := New(StateParticleEmitter, logStream)
+		s.particleEmitter = p
+		s.emitterPath = path
+		s.ShowParticleEmitterWindow()
+	}
+}
+
+func (win *ShaderDesigner) particleEmitterSave(e *document.Element) {
+	path := win.emitterPath
+	if path == "" {
+		if err := os.MkdirAll(particleFolder, os.ModePerm); err != nil {
+			slog.Error("failed to create the particles folder",
+				"folder", particleFolder, "error", err)
+		}
+		path = filepath.Join(particleFolder,
+			win.particleEmitter.Name+editor_config.FileExtensionParticleEmitter)
+		if _, err := os.Stat(path); err == nil {
+			ok := <-alert.New("Overwrite?", "You are about to overwrite a particle emitter with the same name, would you like to continue?", "Yes", "No", win.man.Host)
+			if !ok {
+				return
+			}
+		}
+	}
+	win.particleEmitter.Texture = filepath.ToSlash(win.particleEmitter.Texture)
+	win.particleEmitter.ShapeMesh = filepath.ToSlash(win.particleEmitter.ShapeMesh)
+	res, err := json.Marshal(win.particleEmitter)
+	if err != nil {
+		slog.Error("failed to marshal the particle emitter data", "error", err)
+		return
+	}
+	if err := os.WriteFile(path, res, os.ModePerm); err != nil {
+		slog.Error("failed to write the particle emitter data to file", "error", err)
+		return
+	}
+	win.emitterPath = path
+	slog.Info("particle emitter successfully saved", "file", path)
+	if len(e.Children) > 0 {
+		u := e.Children[0].UI
+		if u.IsType(ui.ElementTypeLabel) {
+			u.ToLabel().SetText("File saved!")
+		}
+	}
+}
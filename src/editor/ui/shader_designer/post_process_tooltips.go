@@ -0,0 +1,26 @@
+package shader_designer
+
+var postProcessTooltips = map[string]string{
+	"Name":             "The name of the post-processing stack, it is saved to content/postprocess with this name",
+	"Effects":          "The effects that are drawn, in the order they are checked. Unchecked effects are not drawn",
+	"Bloom":            "Adds a glow around the parts of the image that are brighter than the threshold",
+	"ToneMapping":      "Maps the colors through a filmic curve after scaling them by the exposure",
+	"ColorGrading":     "Maps the colors through a lookup table texture",
+	"Vignette":         "Darkens the edges of the screen",
+	"FXAA":             "Smooths the jagged edges of the final image",
+	"Enabled":          "Draw this effect, disabled effects are skipped",
+	"Threshold":        "How bright a pixel needs to be to glow",
+	"Knee":             "Softens the threshold so the glow fades in, 0 is a hard cut",
+	"Intensity":        "How strong the bloom glow or the vignette darkening is",
+	"Radius":           "The size of the bloom blur in pixels of the half resolution image",
+	"Operator":         "The curve used to map the colors to the screen range",
+	"Exposure":         "Brightens or darkens the image in stops, each stop doubles the brightness",
+	"LUT":              "The lookup table texture, the blue slices laid side by side (a 16 entry table is 256x16)",
+	"Contribution":     "Blends between the original (0) and graded (1) colors",
+	"Smoothness":       "How far in from the corners the vignette fades",
+	"Roundness":        "1 makes the vignette a circle, 0 follows the shape of the screen",
+	"Color":            "The color the edges of the screen fade to",
+	"EdgeThreshold":    "The contrast, relative to the brightest pixel, that an edge needs to be smoothed",
+	"EdgeThresholdMin": "Skips edges in dark areas with less contrast than this",
+	"SpanMax":          "The furthest in pixels that an edge is searched along",
+}
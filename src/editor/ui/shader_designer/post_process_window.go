@@ -0,0 +1,112 @@
+package shader_designer
+
+import (
+	"encoding/json"
+	"kaiju/editor/alert"
+	"kaiju/editor/editor_config"
+	"kaiju/engine/systems/logging"
+	"kaiju/engine/ui"
+	"kaiju/engine/ui/markup"
+	"kaiju/engine/ui/markup/document"
+	"kaiju/rendering"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+)
+
+func (win *ShaderDesigner) reloadPostProcessDoc() {
+	sy := float32(0)
+	if win.postProcessDoc != nil {
+		content := win.postProcessDoc.GetElementsByClass("topFields")[0]
+		sy = content.UIPanel.ScrollY()
+		win.postProcessDoc.Destroy()
+	}
+	listings := map[string][]string{}
+	listings["Operator"] = rendering.ToneMapOperators
+	// The options are sorted for display, so the default order is left alone
+	listings["Effects"] = slices.Clone(rendering.PostProcessEffects)
+	listings["LUT"] = collectTextureOptions()
+	data := reflectUIStructure(&win.postProcess, "", listings)
+	data.Name = "Post Processing Editor"
+	win.postProcessDoc, _ = markup.DocumentFromHTMLAssetRooted(win.man, dataInputHTML,
+		data, map[string]func(*document.Element){
+			"showTooltip":  showPostProcessTooltip,
+			"valueChanged": win.postProcessValueChanged,
+			"returnHome":   win.returnHome,
+			"saveData":     win.postProcessSave,
+		}, win.root)
+	if sy != 0 {
+		content := win.postProcessDoc.GetElementsByClass("topFields")[0]
+		win.man.Host.RunAfterFrames(2, func() {
+			content.UIPanel.SetScrollY(sy)
+		})
+	}
+}
+
+func showPostProcessTooltip(e *document.Element) {
+	showTooltip(postProcessTooltips, e)
+}
+
+func (win *ShaderDesigner) postProcessValueChanged(e *document.Element) {
+	setObjectValueFromUI(&win.postProcess, e)
+}
+
+func loadPostProcessData(path string) (rendering.PostProcessData, bool) {
+	p := rendering.DefaultPostProcessData()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		slog.Error("failed to load the post-processing file", "file", path, "error", err)
+		return p, false
+	}
+	if err := json.Unmarshal(data, &p); err != nil {
+		slog.Error("failed to unmarshal the post-processing data", "error", err)
+		return p, false
+	}
+	return p, true
+}
+
+func OpenPostProcess(path string, logStream *logging.LogStream) {
+	if p, ok := loadPostProcessData(path); ok {
+		s := New(StatePostProcess, logStream)
+		s.postProcess = p
+		s.postProcessPath = path
+		s.ShowPostProcessWindow()
+	}
+}
+
+func (win *ShaderDesigner) postProcessSave(e *document.Element) {
+	path := win.postProcessPath
+	if path == "" {
+		if err := os.MkdirAll(postProcessFolder, os.ModePerm); err != nil {
+			slog.Error("failed to create the post-processing folder",
+				"folder", postProcessFolder, "error", err)
+		}
+		path = filepath.Join(postProcessFolder,
+			win.postProcess.Name+editor_config.FileExtensionPostProcess)
+		if _, err := os.Stat(path); err == nil {
+			ok := <-alert.New("Overwrite?", "You are about to overwrite a post-processing stack with the same name, would you like to continue?", "Yes", "No", win.man.Host)
+			if !ok {
+				return
+			}
+		}
+	}
+	win.postProcess.ColorGrading.LUT = filepath.ToSlash(win.postProcess.ColorGrading.LUT)
+	res, err := json.Marshal(win.postProcess)
+	if err != nil {
+		slog.Error("failed to marshal the post-processing data", "error", err)
+		return
+	}
+	if err := os.WriteFile(path, res, os.ModePerm); err != nil {
+		slog.Error("failed to write the post-processing data to file", "error", err)
+		return
+	}
+	win.postProcessPath = path
+	slog.Info("post-processing successfully saved", "file", path)
+	if len(e.Children) > 0 {
+		u := e.Children[0].UI
+		if u.IsType(ui.ElementTypeLabel) {
+			u.ToLabel().SetText("File saved!")
+		}
+	}
+}
@@ -45,4 +45,8 @@ var renderPassTooltips = map[string]string{
 	"AttachmentImageClear": "Controls how the image should be cleared at the beginning of the render pass",
 	"Sort":                 "The sort for this render pass compared to other render passes. A lower number sort will cause the render pass to run before a higher number sort. This number can be negative, but should be for rare cases as negative numbers are used for sorting visuals in the editor.",
 	"ExistingImage":        "Rather than creating a new image attachment for this render pass, you can input the name of an image for another render pass to be used as an input",
+	"Width":                "The width of the images for this render pass. Leave this and the Height at 0 to have the images follow the size of the window, a fixed size is useful for things like shadow maps",
+	"Height":               "The height of the images for this render pass. Leave this and the Width at 0 to have the images follow the size of the window, a fixed size is useful for things like shadow maps",
+	"Scale":                "Multiplies the size of the window for the images of this render pass when the Width and Height are 0, like 0.5 for half resolution images used by blur effects",
+	"Offscreen":            "An offscreen render pass is drawn but it is not combined into the final image on the screen. Its images are meant to be sampled by other materials, like the shadow map being sampled by the lit materials",
 }
@@ -6,6 +6,7 @@ import (
 	"kaiju/engine/ui/markup/document"
 	"kaiju/rendering"
 	"kaiju/engine/systems/logging"
+	"kaiju/engine/systems/particles"
 	"kaiju/engine/ui"
 	"slices"
 )
@@ -22,6 +23,8 @@ const (
 	StateRenderPass
 	StatePipeline
 	StateMaterial
+	StateParticleEmitter
+	StatePostProcess
 )
 
 type ShaderDesigner struct {
@@ -29,11 +32,17 @@ type ShaderDesigner struct {
 	renderPass        rendering.RenderPassData
 	pipeline          rendering.ShaderPipelineData
 	material          rendering.MaterialData
+	particleEmitter   particles.EmitterData
+	emitterPath       string
+	postProcess       rendering.PostProcessData
+	postProcessPath   string
 	shaderDesignerDoc *document.Document
 	shaderDoc         *document.Document
 	pipelineDoc       *document.Document
 	renderPassDoc     *document.Document
 	materialDoc       *document.Document
+	emitterDoc        *document.Document
+	postProcessDoc    *document.Document
 	man               *ui.Manager
 	root              *document.Element
 	state             ShaderDesignerState
@@ -60,6 +69,10 @@ func (s *ShaderDesigner) Document() *document.Document {
 		return s.pipelineDoc
 	case StateMaterial:
 		return s.materialDoc
+	case StateParticleEmitter:
+		return s.emitterDoc
+	case StatePostProcess:
+		return s.postProcessDoc
 	case StateHome:
 		fallthrough
 	default:
@@ -88,6 +101,14 @@ func (s *ShaderDesigner) Destroy() {
 		s.materialDoc.Destroy()
 		s.materialDoc = nil
 	}
+	if s.emitterDoc != nil {
+		s.emitterDoc.Destroy()
+		s.emitterDoc = nil
+	}
+	if s.postProcessDoc != nil {
+		s.postProcessDoc.Destroy()
+		s.postProcessDoc = nil
+	}
 }
 
 func (s *ShaderDesigner) Reload(uiMan *ui.Manager, root *document.Element) {
@@ -109,6 +130,12 @@ func (s *ShaderDesigner) Reload(uiMan *ui.Manager, root *document.Element) {
 	case StateMaterial:
 		s.reloadMaterialDoc()
 		s.materialDoc.Activate()
+	case StateParticleEmitter:
+		s.reloadParticleEmitterDoc()
+		s.emitterDoc.Activate()
+	case StatePostProcess:
+		s.reloadPostProcessDoc()
+		s.postProcessDoc.Activate()
 	}
 }
 
@@ -143,6 +170,12 @@ func (win *ShaderDesigner) ChangeWindowState(state ShaderDesignerState) {
 	if win.materialDoc != nil {
 		win.materialDoc.Deactivate()
 	}
+	if win.emitterDoc != nil {
+		win.emitterDoc.Deactivate()
+	}
+	if win.postProcessDoc != nil {
+		win.postProcessDoc.Deactivate()
+	}
 	if win.shaderDesignerDoc != nil {
 		win.shaderDesignerDoc.Deactivate()
 	}
@@ -162,6 +195,12 @@ func (win *ShaderDesigner) ChangeWindowState(state ShaderDesignerState) {
 	case StateMaterial:
 		win.reloadMaterialDoc()
 		win.materialDoc.Activate()
+	case StateParticleEmitter:
+		win.reloadParticleEmitterDoc()
+		win.emitterDoc.Activate()
+	case StatePostProcess:
+		win.reloadPostProcessDoc()
+		win.postProcessDoc.Activate()
 	}
 	win.man.Host.Window.Focus()
 }
@@ -186,6 +225,14 @@ func (win *ShaderDesigner) ShowMaterialWindow() {
 	win.ChangeWindowState(StateMaterial)
 }
 
+func (win *ShaderDesigner) ShowParticleEmitterWindow() {
+	win.ChangeWindowState(StateParticleEmitter)
+}
+
+func (win *ShaderDesigner) ShowPostProcessWindow() {
+	win.ChangeWindowState(StatePostProcess)
+}
+
 func (win *ShaderDesigner) returnHome(*document.Element) {
 	win.ShowDesignerWindow()
 }
@@ -212,5 +259,15 @@ func (win *ShaderDesigner) reloadShaderDesigner() {
 				win.material = rendering.MaterialData{}
 				win.ShowMaterialWindow()
 			},
+			"newParticleEmitter": func(*document.Element) {
+				win.particleEmitter = particles.DefaultEmitterData()
+				win.emitterPath = ""
+				win.ShowParticleEmitterWindow()
+			},
+			"newPostProcess": func(*document.Element) {
+				win.postProcess = rendering.DefaultPostProcessData()
+				win.postProcessPath = ""
+				win.ShowPostProcessWindow()
+			},
 		}, win.root)
 }
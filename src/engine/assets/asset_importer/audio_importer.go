@@ -0,0 +1,97 @@
+/******************************************************************************/
+/* audio_importer.go                                                          */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package asset_importer
+
+import (
+	"kaiju/editor/cache/project_cache"
+	"kaiju/editor/editor_config"
+	"kaiju/engine/assets/asset_info"
+	"kaiju/platform/audio"
+	"os"
+)
+
+type AudioMetadata struct {
+	// Volume is the default linear volume of the clip
+	Volume float32
+	// LoopStart is the time (in seconds) that a looping voice jumps back to
+	LoopStart float32
+	// LoopEnd is the time (in seconds) that a looping voice will jump back to
+	// LoopStart, 0 will loop at the end of the clip
+	LoopEnd float32
+}
+
+func defaultAudioMetadata() *AudioMetadata {
+	return &AudioMetadata{
+		Volume: 1,
+	}
+}
+
+func cleanupAudio(adi asset_info.AssetDatabaseInfo) {
+	project_cache.DeleteAudio(adi)
+}
+
+// importAudio decodes the audio file and converts it to the output format of
+// the engine (sample rate, channel count, float32 samples) so that the
+// conversion isn't needed at runtime. The converted clip is written into the
+// project cache along with the loop points and volume from the metadata.
+func importAudio(importer Importer, path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	clip, err := audio.DecodeClip(path, data, audio.OutputSampleRate, audio.OutputChannels)
+	if err != nil {
+		return err
+	}
+	adi, err := createADI(importer, path, cleanupAudio)
+	if err != nil {
+		return err
+	}
+	adi.Type = editor_config.AssetTypeAudio
+	meta, ok := adi.Metadata.(*AudioMetadata)
+	if !ok {
+		meta = defaultAudioMetadata()
+		adi.Metadata = meta
+	}
+	clip.Volume = meta.Volume
+	clip.SetLoopPoints(meta.LoopStart, meta.LoopEnd)
+	if err := project_cache.CacheAudio(adi.ID, clip); err != nil {
+		return err
+	}
+	return asset_info.Write(adi)
+}
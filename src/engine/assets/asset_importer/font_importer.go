@@ -0,0 +1,95 @@
+/******************************************************************************/
+/* font_importer.go                                                           */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package asset_importer
+
+import (
+	"kaiju/editor/editor_config"
+	"kaiju/engine/assets/asset_info"
+	"kaiju/rendering/font_processing"
+	"path/filepath"
+	"strings"
+)
+
+type FontMetadata struct {
+	// Ranges are the unicode characters put into the font atlas, a comma
+	// separated list of hexadecimal code points and ranges (0020-007E, 20AC)
+	Ranges string
+}
+
+func defaultFontMetadata() *FontMetadata {
+	return &FontMetadata{
+		Ranges: font_processing.DefaultRanges,
+	}
+}
+
+// FontImporter generates the multi-channel signed distance field atlas of a
+// TTF/OTF font. The atlas image and glyph data are written next to the font
+// file with the same name, which is what the FontCache reads for the face.
+type FontImporter struct{}
+
+func (m FontImporter) MetadataStructure() any {
+	return defaultFontMetadata()
+}
+
+func (m FontImporter) Handles(path string) bool {
+	ext := strings.ToLower(filepath.Ext(path))
+	return ext == editor_config.FileExtensionTtf || ext == editor_config.FileExtensionOtf
+}
+
+func (m FontImporter) Import(path string) error {
+	adi, err := createADI(m, path, nil)
+	if err != nil {
+		return err
+	}
+	adi.Type = editor_config.AssetTypeFont
+	meta, ok := adi.Metadata.(*FontMetadata)
+	if !ok {
+		meta = defaultFontMetadata()
+		adi.Metadata = meta
+	}
+	runes, err := font_processing.ParseRanges(meta.Ranges)
+	if err != nil {
+		return err
+	}
+	outPath := strings.TrimSuffix(path, filepath.Ext(path))
+	err = font_processing.GenerateFile(path, outPath, runes, font_processing.DefaultOptions())
+	if err != nil {
+		return err
+	}
+	return asset_info.Write(adi)
+}
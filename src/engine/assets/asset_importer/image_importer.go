@@ -1,8 +1,13 @@
 package asset_importer
 
 import (
+	"errors"
+	"kaiju/editor/editor_config"
+	"kaiju/engine/assets/asset_info"
 	"kaiju/rendering"
+	"kaiju/rendering/texture_processing"
 	"log/slog"
+	"os"
 )
 
 var (
@@ -34,16 +39,58 @@ var (
 		"4096": 4096,
 		"8192": 8192,
 	}
+	// "GPU" has the renderer generate the mip levels when the texture is
+	// loaded, if the texture is compressed then it falls back to "Box"
+	imageMipFilter = map[string]texture_processing.MipFilter{
+		imageMipFilterGPU: texture_processing.MipFilterBox,
+		"Box":             texture_processing.MipFilterBox,
+		"Kaiser":          texture_processing.MipFilterKaiser,
+		"Lanczos":         texture_processing.MipFilterLanczos,
+	}
+	imageCompression = map[string]texture_processing.Format{
+		imageCompressionNone: texture_processing.FormatRGBA8,
+		"BC1 (RGB)":          texture_processing.FormatBC1,
+		"BC3 (RGBA)":         texture_processing.FormatBC3,
+		"BC5 (Normal map)":   texture_processing.FormatBC5,
+		"BC7 (RGBA)":         texture_processing.FormatBC7,
+		"ASTC 4x4":           texture_processing.FormatASTC4x4,
+	}
+	imageCompressionQuality = map[string]texture_processing.Quality{
+		"Fast":   texture_processing.QualityFast,
+		"Normal": texture_processing.QualityNormal,
+		"High":   texture_processing.QualityHigh,
+	}
+	imageColorSpace = map[string]bool{
+		"sRGB":   true,
+		"Linear": false,
+	}
+)
+
+const (
+	imageMipFilterGPU    = "GPU"
+	imageCompressionNone = "None"
 )
 
 type ImageMetadata struct {
 	Filter        string `options:"imageFilterOptions"`
 	Pivot         string `options:"imagePivot"`
 	PixelsPerUnit int32
-	Mipmaps       int32
+	// Mipmaps is the number of mip levels, 0 will use the full chain
+	Mipmaps int32
 
-	// TODO:  This needs to be used for packaging the content
+	// MaxSize is the largest the width or height of the image can be, larger
+	// images are scaled down on import using the MipFilter
 	MaxSize string `options:"imageMaxSize"`
+	// MipFilter is the filter used to generate the mip levels on import
+	MipFilter string `options:"imageMipFilter"`
+	// Compression is the block compression format the texture is stored in
+	Compression string `options:"imageCompression"`
+	// Quality is how much time is spent searching for the best block
+	// endpoints when the texture is compressed
+	Quality string `options:"imageCompressionQuality"`
+	// ColorSpace should be "Linear" for images that hold data rather than
+	// color, like normal or roughness maps
+	ColorSpace string `options:"imageColorSpace"`
 }
 
 func defaultImageMetadata() *ImageMetadata {
@@ -53,6 +100,10 @@ func defaultImageMetadata() *ImageMetadata {
 		PixelsPerUnit: 128,
 		Mipmaps:       1,
 		MaxSize:       "8192",
+		MipFilter:     imageMipFilterGPU,
+		Compression:   imageCompressionNone,
+		Quality:       "Normal",
+		ColorSpace:    "sRGB",
 	}
 }
 
@@ -82,3 +133,101 @@ func (m *ImageMetadata) MaxSizeMeta() int32 {
 		"key", m.MaxSize)
 	return 8192
 }
+
+// ProcessOnImport is true when the mip levels, size, or compression of the
+// image need to be generated when it is imported rather than leaving it to
+// the renderer when the texture is loaded
+func (m *ImageMetadata) ProcessOnImport(width, height int) bool {
+	maxSize := int(m.MaxSizeMeta())
+	return (m.MipFilter != "" && m.MipFilter != imageMipFilterGPU) ||
+		(m.Compression != "" && m.Compression != imageCompressionNone) ||
+		width > maxSize || height > maxSize
+}
+
+// ProcessingOptions are the options used to generate the mip levels and
+// compress the image on import
+func (m *ImageMetadata) ProcessingOptions() texture_processing.Options {
+	opts := texture_processing.Options{
+		Format:    texture_processing.FormatRGBA8,
+		Quality:   texture_processing.QualityNormal,
+		MipLevels: int(max(0, m.Mipmaps)),
+		MipFilter: texture_processing.MipFilterBox,
+		SRGB:      true,
+		MaxSize:   int(m.MaxSizeMeta()),
+	}
+	if f, ok := imageMipFilter[m.MipFilter]; ok {
+		opts.MipFilter = f
+	} else if m.MipFilter != "" {
+		slog.Warn("tried to read image mip filter metadata but has invalid key",
+			"key", m.MipFilter)
+	}
+	if f, ok := imageCompression[m.Compression]; ok {
+		opts.Format = f
+	} else if m.Compression != "" {
+		slog.Warn("tried to read image compression metadata but has invalid key",
+			"key", m.Compression)
+	}
+	if q, ok := imageCompressionQuality[m.Quality]; ok {
+		opts.Quality = q
+	}
+	if srgb, ok := imageColorSpace[m.ColorSpace]; ok {
+		opts.SRGB = srgb
+	}
+	return opts
+}
+
+func cleanupTexture(adi asset_info.AssetDatabaseInfo) {
+	err := os.Remove(asset_info.ImportedPath(adi.Path))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		slog.Error("failed to remove the imported texture", "path", adi.Path, "error", err)
+	}
+}
+
+// importImage is shared by the importers of all of the image file formats,
+// the image is decoded to validate it and then processed as set in the
+// metadata
+func importImage(importer Importer, path string, inputType rendering.TextureFileFormat) error {
+	adi, err := createADI(importer, path, cleanupTexture)
+	if err != nil {
+		return err
+	}
+	adi.Type = editor_config.AssetTypeImage
+	meta, ok := adi.Metadata.(*ImageMetadata)
+	if !ok {
+		meta = defaultImageMetadata()
+		adi.Metadata = meta
+	}
+	if err := processImage(path, inputType, meta); err != nil {
+		return err
+	}
+	return asset_info.Write(adi)
+}
+
+// processImage generates the mip levels and compresses the image as set in
+// the metadata, the result is written as a KTX2 file next to the image (see
+// asset_info.ImportedPath) so that it ships with the content, the texture
+// cache loads it in place of the image. Images that are already compressed,
+// have their own mip levels, or hold float data are used as they are.
+func processImage(path string, inputType rendering.TextureFileFormat, meta *ImageMetadata) error {
+	src, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	data := rendering.ReadRawTextureData(src, inputType)
+	if data.Width == 0 || data.Height == 0 {
+		return errors.New("failed to decode the image " + path)
+	}
+	if data.InternalFormat != rendering.TextureInputTypeRgba8 || data.MipLevels > 0 {
+		return nil
+	}
+	if !meta.ProcessOnImport(data.Width, data.Height) {
+		return nil
+	}
+	img := texture_processing.Image{
+		Width:  data.Width,
+		Height: data.Height,
+		Pix:    data.Mem,
+	}
+	ktx2 := texture_processing.Process(img, meta.ProcessingOptions())
+	return os.WriteFile(asset_info.ImportedPath(path), ktx2.Encode(), os.ModePerm)
+}
@@ -40,7 +40,6 @@ package asset_importer
 import (
 	"errors"
 	"kaiju/engine/assets/asset_info"
-	"path/filepath"
 
 	"github.com/KaijuEngine/uuid"
 )
@@ -71,7 +70,7 @@ func (r *ImportRegistry) Register(importer Importer) {
 }
 
 func (r *ImportRegistry) ImportIfNew(path string) error {
-	if filepath.Ext(path) == asset_info.InfoExtension {
+	if asset_info.IsImportFile(path) {
 		return nil
 	}
 	if !asset_info.Exists(path) {
@@ -81,7 +80,7 @@ func (r *ImportRegistry) ImportIfNew(path string) error {
 }
 
 func (r *ImportRegistry) Import(path string) error {
-	if filepath.Ext(path) == asset_info.InfoExtension {
+	if asset_info.IsImportFile(path) {
 		return nil
 	}
 	// We go back to front so devs can override default importers
@@ -94,7 +93,7 @@ func (r *ImportRegistry) Import(path string) error {
 }
 
 func (r *ImportRegistry) MetadataStructure(path string) any {
-	if filepath.Ext(path) == asset_info.InfoExtension {
+	if asset_info.IsImportFile(path) {
 		return nil
 	}
 	// We go back to front so devs can override default importers
@@ -6,6 +6,7 @@ import (
 	"kaiju/engine/assets/asset_info"
 	"kaiju/editor/cache/project_cache"
 	"kaiju/editor/editor_config"
+	"kaiju/rendering"
 	"kaiju/rendering/loaders/load_result"
 
 	"github.com/KaijuEngine/uuid"
@@ -14,6 +15,9 @@ import (
 type MeshMetadata struct {
 	Name     string
 	Material string
+	// PBR holds the factors of the generated pbr material, it is nil when
+	// the mesh uses one of the basic materials
+	PBR *rendering.PBRFactors `json:",omitempty"`
 }
 
 func cleanupMesh(adi asset_info.AssetDatabaseInfo) {
@@ -29,18 +33,31 @@ func importMeshToCache(adi *asset_info.AssetDatabaseInfo, mesh load_result.Resul
 	adi.Metadata = MeshMetadata{
 		Name: mesh.Meshes[0].Name,
 	}
-	for _, o := range mesh.Meshes {
+	materials := newPBRMaterialWriter(assets.NewDatabase(), adi.Path)
+	for i, o := range mesh.Meshes {
 		info := adi.SpawnChild(uuid.New().String())
 		info.Type = editor_config.AssetTypeMesh
 		info.ParentID = adi.ID
-		if err := project_cache.CacheMesh(info.ID, o); err != nil {
-			return err
-		}
-		info.Metadata = MeshMetadata{
-			// TODO:  Write the correct material to the adi
+		meta := MeshMetadata{
 			Material: assets.MaterialDefinitionBasic,
 			Name:     o.MeshName,
 		}
+		if o.Material != nil {
+			key, err := materials.Write(o.Material, i)
+			if err != nil {
+				return err
+			}
+			factors := o.Material.Factors
+			meta.Material = key
+			meta.PBR = &factors
+			// The material lives in its own file, embedded images don't
+			// need to be in the cache
+			o.Material = nil
+		}
+		if err := project_cache.CacheMesh(info.ID, o); err != nil {
+			return err
+		}
+		info.Metadata = meta
 		adi.Children = append(adi.Children, info)
 	}
 	return nil
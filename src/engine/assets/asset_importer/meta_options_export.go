@@ -2,8 +2,12 @@ package asset_importer
 
 var (
 	MetaOptions = map[string]any{
-		"imageFilterOptions": imageFilterOptions,
-		"imagePivot":         imagePivot,
-		"imageMaxSize":       imageMaxSize,
+		"imageFilterOptions":      imageFilterOptions,
+		"imagePivot":              imagePivot,
+		"imageMaxSize":            imageMaxSize,
+		"imageMipFilter":          imageMipFilter,
+		"imageCompression":        imageCompression,
+		"imageCompressionQuality": imageCompressionQuality,
+		"imageColorSpace":         imageColorSpace,
 	}
 )
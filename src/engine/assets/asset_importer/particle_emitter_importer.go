@@ -0,0 +1,28 @@
+package asset_importer
+
+import (
+	"kaiju/editor/editor_config"
+	"kaiju/engine/assets/asset_info"
+	"path/filepath"
+)
+
+type ParticleEmitterImporter struct{}
+
+type ParticleEmitterMetadata struct{}
+
+func (m ParticleEmitterImporter) MetadataStructure() any {
+	return &ParticleEmitterMetadata{}
+}
+
+func (m ParticleEmitterImporter) Handles(path string) bool {
+	return filepath.Ext(path) == editor_config.FileExtensionParticleEmitter
+}
+
+func (m ParticleEmitterImporter) Import(path string) error {
+	adi, err := createADI(m, path, nil)
+	if err != nil {
+		return err
+	}
+	adi.Type = editor_config.AssetTypeParticleEmitter
+	return asset_info.Write(adi)
+}
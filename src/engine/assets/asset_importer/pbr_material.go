@@ -0,0 +1,167 @@
+package asset_importer
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"image"
+	_ "image/jpeg"
+	"image/png"
+	"kaiju/editor/editor_config"
+	"kaiju/engine/assets"
+	"kaiju/engine/assets/asset_info"
+	"kaiju/platform/filesystem"
+	"kaiju/rendering"
+	"kaiju/rendering/loaders/load_result"
+	"path/filepath"
+	"strings"
+	"unicode"
+)
+
+const (
+	pbrMaterialTemplate            = "renderer/materials/" + assets.MaterialDefinitionPBR + ".material"
+	pbrTransparentMaterialTemplate = "renderer/materials/" + assets.MaterialDefinitionPBRTransparent + ".material"
+	pbrDoubleSidedPipeline         = "content/renderer/pipelines/basic_double_sided.shaderpipeline"
+	pbrTransparentDoubleSidedPipe  = "content/renderer/pipelines/basic_transparent_double_sided.shaderpipeline"
+)
+
+// pbrMaterialWriter generates the .material files for the materials of an
+// imported model, the files are written next to the model and named after it
+type pbrMaterialWriter struct {
+	db        assets.Database
+	modelPath string
+	// written maps the model's material names to the generated file so
+	// that meshes sharing a material also share the generated file
+	written map[string]string
+}
+
+func newPBRMaterialWriter(db assets.Database, modelPath string) pbrMaterialWriter {
+	return pbrMaterialWriter{
+		db:        db,
+		modelPath: filepath.ToSlash(modelPath),
+		written:   make(map[string]string),
+	}
+}
+
+// Write creates the material file (unless it already exists so that edits
+// to it survive re-importing the model) and returns its material key
+func (w *pbrMaterialWriter) Write(mat *load_result.Material, meshIndex int) (string, error) {
+	name := pbrSanitizeName(mat.Name)
+	if name == "" {
+		name = fmt.Sprintf("material%d", meshIndex)
+	} else if found, ok := w.written[mat.Name]; ok {
+		return found, nil
+	}
+	key := w.siblingPath(name, editor_config.FileExtensionMaterial)
+	if mat.Name != "" {
+		w.written[mat.Name] = key
+	}
+	if filesystem.FileExists(key) {
+		return key, nil
+	}
+	template := pbrMaterialTemplate
+	if mat.Transparent {
+		template = pbrTransparentMaterialTemplate
+	}
+	var data rendering.MaterialData
+	if str, err := w.db.ReadText(template); err != nil {
+		return key, err
+	} else if err := json.Unmarshal([]byte(str), &data); err != nil {
+		return key, err
+	}
+	data.Name = key
+	if mat.DoubleSided {
+		if mat.Transparent {
+			data.ShaderPipeline = pbrTransparentDoubleSidedPipe
+		} else {
+			data.ShaderPipeline = pbrDoubleSidedPipeline
+		}
+	}
+	// The texture order matches the sampler bindings in pbr.frag
+	textures := []*load_result.Texture{&mat.BaseColor, &mat.MetallicRoughness,
+		&mat.Normal, &mat.Occlusion, &mat.Emissive}
+	for i, t := range textures {
+		if !t.IsValid() || i >= len(data.Textures) {
+			continue
+		}
+		texName := name + "_" + strings.ToLower(pbrTextureSlots[i])
+		if path, err := w.texture(t, texName); err != nil {
+			return key, err
+		} else {
+			data.Textures[i].Texture = path
+		}
+	}
+	out, err := json.Marshal(data)
+	if err != nil {
+		return key, err
+	}
+	if err := filesystem.WriteFile(key, out); err != nil {
+		return key, err
+	}
+	return key, MaterialImporter{}.Import(key)
+}
+
+var pbrTextureSlots = [...]string{"BaseColor", "MetallicRoughness",
+	"Normal", "Occlusion", "Emissive"}
+
+// texture returns the path to an image file of the texture, PNG and JPEG
+// files are used as they are while other and embedded images are converted
+// to PNG
+func (w *pbrMaterialWriter) texture(t *load_result.Texture, name string) (string, error) {
+	if t.Path != "" {
+		switch strings.ToLower(filepath.Ext(t.Path)) {
+		case editor_config.FileExtensionPng, editor_config.FileExtensionJpg,
+			editor_config.FileExtensionJpeg:
+			return t.Path, nil
+		}
+	}
+	if t.Path != "" {
+		name = pbrSanitizeName(strings.TrimSuffix(filepath.Base(t.Path), filepath.Ext(t.Path)))
+	} else if t.Name != "" {
+		name = pbrSanitizeName(t.Name)
+	}
+	path := w.siblingPath(name, editor_config.FileExtensionPng)
+	if filesystem.FileExists(path) {
+		return path, nil
+	}
+	data := t.Data
+	if t.Path != "" {
+		var err error
+		if data, err = w.db.Read(t.Path); err != nil {
+			return path, err
+		}
+	}
+	if t.MimeType != "image/png" || t.Path != "" {
+		img, _, err := image.Decode(bytes.NewReader(data))
+		if err != nil {
+			return path, err
+		}
+		buf := bytes.Buffer{}
+		if err := png.Encode(&buf, img); err != nil {
+			return path, err
+		}
+		data = buf.Bytes()
+	}
+	if err := filesystem.WriteFile(path, data); err != nil {
+		return path, err
+	}
+	if !asset_info.Exists(path) {
+		return path, PngImporter{}.Import(path)
+	}
+	return path, nil
+}
+
+func (w *pbrMaterialWriter) siblingPath(name, ext string) string {
+	dir, file := filepath.Split(w.modelPath)
+	base := strings.TrimSuffix(file, filepath.Ext(file))
+	return filepath.ToSlash(filepath.Join(dir, base+"_"+name+ext))
+}
+
+func pbrSanitizeName(name string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || r == '-' || r == '_' {
+			return r
+		}
+		return '_'
+	}, name)
+}
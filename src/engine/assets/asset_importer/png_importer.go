@@ -39,7 +39,7 @@ package asset_importer
 
 import (
 	"kaiju/editor/editor_config"
-	"kaiju/engine/assets/asset_info"
+	"kaiju/rendering"
 	"path/filepath"
 )
 
@@ -50,14 +50,9 @@ func (m PngImporter) MetadataStructure() any {
 }
 
 func (m PngImporter) Handles(path string) bool {
-	return filepath.Ext(path) == ".png"
+	return filepath.Ext(path) == editor_config.FileExtensionPng
 }
 
 func (m PngImporter) Import(path string) error {
-	adi, err := createADI(m, path, nil)
-	if err != nil {
-		return err
-	}
-	adi.Type = editor_config.AssetTypeImage
-	return asset_info.Write(adi)
+	return importImage(m, path, rendering.TextureFileFormatPng)
 }
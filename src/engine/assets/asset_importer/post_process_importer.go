@@ -0,0 +1,28 @@
+package asset_importer
+
+import (
+	"kaiju/editor/editor_config"
+	"kaiju/engine/assets/asset_info"
+	"path/filepath"
+)
+
+type PostProcessImporter struct{}
+
+type PostProcessMetadata struct{}
+
+func (m PostProcessImporter) MetadataStructure() any {
+	return &PostProcessMetadata{}
+}
+
+func (m PostProcessImporter) Handles(path string) bool {
+	return filepath.Ext(path) == editor_config.FileExtensionPostProcess
+}
+
+func (m PostProcessImporter) Import(path string) error {
+	adi, err := createADI(m, path, nil)
+	if err != nil {
+		return err
+	}
+	adi.Type = editor_config.AssetTypePostProcess
+	return asset_info.Write(adi)
+}
@@ -0,0 +1,28 @@
+package asset_importer
+
+import (
+	"kaiju/editor/editor_config"
+	"kaiju/engine/assets/asset_info"
+	"path/filepath"
+)
+
+type RenderGraphImporter struct{}
+
+type RenderGraphMetadata struct{}
+
+func (m RenderGraphImporter) MetadataStructure() any {
+	return &RenderGraphMetadata{}
+}
+
+func (m RenderGraphImporter) Handles(path string) bool {
+	return filepath.Ext(path) == editor_config.FileExtensionRenderGraph
+}
+
+func (m RenderGraphImporter) Import(path string) error {
+	adi, err := createADI(m, path, nil)
+	if err != nil {
+		return err
+	}
+	adi.Type = editor_config.AssetTypeRenderGraph
+	return asset_info.Write(adi)
+}
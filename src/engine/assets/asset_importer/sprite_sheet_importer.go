@@ -0,0 +1,97 @@
+/******************************************************************************/
+/* sprite_sheet_importer.go                                                   */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package asset_importer
+
+import (
+	"kaiju/editor/cache/project_cache"
+	"kaiju/editor/editor_config"
+	"kaiju/engine/assets/asset_info"
+	"kaiju/engine/systems/visual2d/sprite"
+	"kaiju/platform/filesystem"
+	"path/filepath"
+)
+
+func defaultSpriteSheetMetadata() *sprite.SpriteSheetMetadata {
+	return &sprite.SpriteSheetMetadata{
+		FrameRate: sprite.DefaultSheetFrameRate,
+	}
+}
+
+func cleanupSpriteSheet(adi asset_info.AssetDatabaseInfo) {
+	project_cache.DeleteSpriteSheet(adi)
+}
+
+// readSpriteSheetSource is used by the sprite sheet importers to sniff the
+// exported JSON, both Aseprite and TexturePacker use the .json extension
+func readSpriteSheetSource(path string) (string, bool) {
+	if filepath.Ext(path) != editor_config.FileExtensionJson {
+		return "", false
+	}
+	src, err := filesystem.ReadTextFile(path)
+	return src, err == nil
+}
+
+func importSpriteSheet(importer Importer, path string, image string,
+	convert func(jsonStr string) (string, error)) error {
+
+	adi, err := createADI(importer, path, cleanupSpriteSheet)
+	if err != nil {
+		return err
+	}
+	adi.Type = editor_config.AssetTypeSpriteSheet
+	src, err := filesystem.ReadTextFile(adi.Path)
+	if err != nil {
+		return err
+	}
+	sheet, err := convert(src)
+	if err != nil {
+		return err
+	}
+	if err := project_cache.CacheSpriteSheet(adi.ID, sheet); err != nil {
+		return err
+	}
+	meta, ok := adi.Metadata.(*sprite.SpriteSheetMetadata)
+	if !ok {
+		meta = defaultSpriteSheetMetadata()
+		adi.Metadata = meta
+	}
+	if meta.Image == "" {
+		meta.Image = image
+	}
+	return asset_info.Write(adi)
+}
@@ -48,7 +48,11 @@ import (
 
 const (
 	InfoExtension = ".adi"
-	ProjectCache  = ".cache"
+	// ImportedExtension is added to the path of an asset for the file that
+	// holds the data generated from it on import, like compressed textures.
+	// It sits next to the ADI file so that it ships with the content.
+	ImportedExtension = ".imported"
+	ProjectCache      = ".cache"
 )
 
 var (
@@ -80,6 +84,19 @@ func toADI(path string) string {
 	return path + InfoExtension
 }
 
+// ImportedPath is the path of the data that was generated for the asset at
+// the given path when it was imported
+func ImportedPath(path string) string {
+	return path + ImportedExtension
+}
+
+// IsImportFile returns true for the files that are written next to an asset
+// when it is imported, these are not assets themselves
+func IsImportFile(path string) bool {
+	ext := filepath.Ext(path)
+	return ext == InfoExtension || ext == ImportedExtension
+}
+
 // Exists checks to see if a given path has a generated ADI file
 // the file it searches for will be path/to/file.ext.adi
 func Exists(path string) bool {
@@ -199,7 +216,13 @@ func Move(info AssetDatabaseInfo, newPath string) error {
 	if err := Write(info); err != nil {
 		return err
 	}
-	return os.Rename(oldAdiPath, newAdiFile)
+	if err := os.Rename(oldAdiPath, newAdiFile); err != nil {
+		return err
+	}
+	if _, err := os.Stat(ImportedPath(oldPath)); err == nil {
+		return os.Rename(ImportedPath(oldPath), ImportedPath(newPath))
+	}
+	return nil
 }
 
 // ID returns the ID of the asset within it's ADI file, if
@@ -45,18 +45,37 @@ const (
 
 // Material definitions
 const (
-	MaterialDefinitionGrid              = "grid"
-	MaterialDefinitionBasic             = "basic"
-	MaterialDefinitionBasicTransparent  = "basic_transparent"
-	MaterialDefinitionBasicSkinned      = "basic_skinned"
-	MaterialDefinitionBasicColor        = "basic_color"
-	MaterialDefinitionText3D            = "text3d"
-	MaterialDefinitionText              = "text"
-	MaterialDefinitionCombine           = "combine"
-	MaterialDefinitionComposite         = "composite"
-	MaterialDefinitionUI                = "ui"
-	MaterialDefinitionUITransparent     = "ui_transparent"
-	MaterialDefinitionSprite            = "sprite"
-	MaterialDefinitionSpriteTransparent = "sprite_transparent"
-	MaterialDefinitionOutline           = "outline"
+	MaterialDefinitionGrid                = "grid"
+	MaterialDefinitionGridOverlay         = "grid_overlay"
+	MaterialDefinitionBasic               = "basic"
+	MaterialDefinitionBasicTransparent    = "basic_transparent"
+	MaterialDefinitionBasicSkinned        = "basic_skinned"
+	MaterialDefinitionBasicColor          = "basic_color"
+	MaterialDefinitionBasicLit            = "basic_lit"
+	MaterialDefinitionBasicLitTransparent = "basic_lit_transparent"
+	MaterialDefinitionBasicSkinnedLit     = "basic_skinned_lit"
+	MaterialDefinitionText3D              = "text3d"
+	MaterialDefinitionText3DOverlay       = "text3d_overlay"
+	MaterialDefinitionText                = "text"
+	MaterialDefinitionCombine             = "combine"
+	MaterialDefinitionComposite           = "composite"
+	MaterialDefinitionUI                  = "ui"
+	MaterialDefinitionUITransparent       = "ui_transparent"
+	MaterialDefinitionSprite              = "sprite"
+	MaterialDefinitionSpriteTransparent   = "sprite_transparent"
+	MaterialDefinitionOutline             = "outline"
+	MaterialDefinitionShadow              = "shadow"
+	MaterialDefinitionPBR                 = "pbr"
+	MaterialDefinitionPBRTransparent      = "pbr_transparent"
+	MaterialDefinitionParticle            = "particle"
+	MaterialDefinitionPostBloomExtract    = "postprocess_bloom_extract"
+	MaterialDefinitionPostBloomBlurH      = "postprocess_bloom_blur_h"
+	MaterialDefinitionPostBloomBlurV      = "postprocess_bloom_blur_v"
+	MaterialDefinitionPostBloom           = "postprocess_bloom"
+	MaterialDefinitionPostToneMapping     = "postprocess_tone_mapping"
+	MaterialDefinitionPostColorGrading    = "postprocess_color_grading"
+	MaterialDefinitionPostVignette        = "postprocess_vignette"
+	MaterialDefinitionPostFXAA            = "postprocess_fxaa"
+	MaterialDefinitionTerrain             = "terrain"
+	MaterialDefinitionTerrain8            = "terrain8"
 )
@@ -46,6 +46,8 @@ import (
 
 type EditorContext struct{}
 
+func (a *Database) ToRawPath(key string) string { return a.toContentPath(key) }
+
 func (a *Database) toContentPath(key string) string {
 	const contentPath = "content"
 	if strings.HasPrefix(key, contentPath) {
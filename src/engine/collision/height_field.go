@@ -0,0 +1,259 @@
+/******************************************************************************/
+/* height_field.go                                                            */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package collision
+
+import (
+	"kaiju/matrix"
+)
+
+// HeightField is a grid of heights, like the collision shape of a terrain. The
+// samples are spaced evenly along the X and Z axes starting at the origin, so
+// the field covers (Width-1)*Spacing by (Depth-1)*Spacing units. Each cell is
+// split into two triangles along the diagonal from its +X corner to its +Z
+// corner, matching the triangles of a terrain mesh built from the samples.
+type HeightField struct {
+	// Heights holds Width*Depth samples, row by row along the X axis
+	Heights []matrix.Float
+	Width   int
+	Depth   int
+	Spacing matrix.Float
+}
+
+// NewHeightField creates a field of the given number of samples that are all
+// at a height of 0
+func NewHeightField(width, depth int, spacing matrix.Float) HeightField {
+	return HeightField{
+		Heights: make([]matrix.Float, width*depth),
+		Width:   width,
+		Depth:   depth,
+		Spacing: spacing,
+	}
+}
+
+// Sample returns the height of the sample at the given grid coordinate, the
+// coordinate is clamped to the edges of the field
+func (h *HeightField) Sample(x, z int) matrix.Float {
+	x = max(0, min(x, h.Width-1))
+	z = max(0, min(z, h.Depth-1))
+	return h.Heights[z*h.Width+x]
+}
+
+// SamplePoint returns the local position of the sample at the given grid
+// coordinate, the coordinate is clamped to the edges of the field
+func (h *HeightField) SamplePoint(x, z int) matrix.Vec3 {
+	x = max(0, min(x, h.Width-1))
+	z = max(0, min(z, h.Depth-1))
+	return matrix.Vec3{matrix.Float(x) * h.Spacing,
+		h.Heights[z*h.Width+x], matrix.Float(z) * h.Spacing}
+}
+
+// SampleNormal returns the smooth normal of the sample at the given grid
+// coordinate, found from the heights of its neighbors
+func (h *HeightField) SampleNormal(x, z int) matrix.Vec3 {
+	dx := h.Sample(x-1, z) - h.Sample(x+1, z)
+	dz := h.Sample(x, z-1) - h.Sample(x, z+1)
+	return matrix.Vec3{dx, 2 * h.Spacing, dz}.Normal()
+}
+
+// Size returns how far the field reaches along the X and Z axes
+func (h *HeightField) Size() (width, depth matrix.Float) {
+	return matrix.Float(h.Width-1) * h.Spacing, matrix.Float(h.Depth-1) * h.Spacing
+}
+
+// Contains returns true if the local X and Z coordinate is over the field
+func (h *HeightField) Contains(x, z matrix.Float) bool {
+	w, d := h.Size()
+	return x >= 0 && z >= 0 && x <= w && z <= d
+}
+
+// Bounds returns the box around all of the samples of the field
+func (h *HeightField) Bounds() AABB {
+	if len(h.Heights) == 0 {
+		return AABB{}
+	}
+	low, high := h.Heights[0], h.Heights[0]
+	for _, v := range h.Heights[1:] {
+		low = min(low, v)
+		high = max(high, v)
+	}
+	w, d := h.Size()
+	return AABBFromMinMax(matrix.Vec3{0, low, 0}, matrix.Vec3{w, high, d})
+}
+
+// cell finds the cell of the local X and Z coordinate and how far into the
+// cell the coordinate is, from 0 to 1 along each axis
+func (h *HeightField) cell(x, z matrix.Float) (cx, cz int, fx, fz matrix.Float) {
+	gx := x / h.Spacing
+	gz := z / h.Spacing
+	cx = max(0, min(int(matrix.Floor(gx)), h.Width-2))
+	cz = max(0, min(int(matrix.Floor(gz)), h.Depth-2))
+	return cx, cz, gx - matrix.Float(cx), gz - matrix.Float(cz)
+}
+
+// HeightAt returns the height of the surface at the local X and Z coordinate,
+// false is returned if the coordinate is outside of the field
+func (h *HeightField) HeightAt(x, z matrix.Float) (matrix.Float, bool) {
+	if h.Width < 2 || h.Depth < 2 || !h.Contains(x, z) {
+		return 0, false
+	}
+	cx, cz, fx, fz := h.cell(x, z)
+	a := h.Sample(cx, cz)
+	b := h.Sample(cx+1, cz)
+	c := h.Sample(cx, cz+1)
+	if fx+fz <= 1 {
+		return a + (b-a)*fx + (c-a)*fz, true
+	}
+	d := h.Sample(cx+1, cz+1)
+	return d + (c-d)*(1-fx) + (b-d)*(1-fz), true
+}
+
+// NormalAt returns the smooth normal of the surface at the local X and Z
+// coordinate by blending the normals of the samples around it, false is
+// returned if the coordinate is outside of the field
+func (h *HeightField) NormalAt(x, z matrix.Float) (matrix.Vec3, bool) {
+	if h.Width < 2 || h.Depth < 2 || !h.Contains(x, z) {
+		return matrix.Vec3Up(), false
+	}
+	cx, cz, fx, fz := h.cell(x, z)
+	a := h.SampleNormal(cx, cz)
+	b := h.SampleNormal(cx+1, cz)
+	c := h.SampleNormal(cx, cz+1)
+	d := h.SampleNormal(cx+1, cz+1)
+	top := a.Scale(1 - fx).Add(b.Scale(fx))
+	bottom := c.Scale(1 - fx).Add(d.Scale(fx))
+	return top.Scale(1 - fz).Add(bottom.Scale(fz)).Normal(), true
+}
+
+// RayHit returns the first point where the local space ray hits the surface
+// within the given length. The cells under the ray are walked in order, so
+// only the triangles along the path of the ray are tested.
+func (h *HeightField) RayHit(ray Ray, length matrix.Float) (matrix.Vec3, bool) {
+	if h.Width < 2 || h.Depth < 2 {
+		return matrix.Vec3{}, false
+	}
+	start := matrix.Float(0)
+	bounds := h.Bounds()
+	if !bounds.Contains(ray.Origin) {
+		p, ok := bounds.RayHit(ray)
+		if !ok {
+			return matrix.Vec3{}, false
+		}
+		start = matrix.Vec3Dot(p.Subtract(ray.Origin), ray.Direction) /
+			matrix.Vec3Dot(ray.Direction, ray.Direction)
+	}
+	if start > length {
+		return matrix.Vec3{}, false
+	}
+	p := ray.Point(start)
+	cx, cz, _, _ := h.cell(p.X(), p.Z())
+	stepX, tMaxX, tDeltaX := h.rayStep(ray.Origin.X(), ray.Direction.X(), cx)
+	stepZ, tMaxZ, tDeltaZ := h.rayStep(ray.Origin.Z(), ray.Direction.Z(), cz)
+	for cx >= 0 && cz >= 0 && cx < h.Width-1 && cz < h.Depth-1 {
+		if t, ok := h.cellRayHit(ray, cx, cz); ok && t <= length {
+			return ray.Point(t), true
+		}
+		if tMaxX < tMaxZ {
+			if tMaxX > length {
+				break
+			}
+			cx += stepX
+			tMaxX += tDeltaX
+		} else {
+			if tMaxZ > length {
+				break
+			}
+			cz += stepZ
+			tMaxZ += tDeltaZ
+		}
+	}
+	return matrix.Vec3{}, false
+}
+
+// rayStep sets up the walk of the cells along one axis, it returns which way
+// to step, the distance along the ray to the first cell edge, and the distance
+// between cell edges
+func (h *HeightField) rayStep(origin, dir matrix.Float, cell int) (int, matrix.Float, matrix.Float) {
+	if matrix.Abs(dir) < matrix.FloatSmallestNonzero {
+		return 0, matrix.Inf(1), matrix.Inf(1)
+	}
+	if dir > 0 {
+		edge := matrix.Float(cell+1) * h.Spacing
+		return 1, (edge - origin) / dir, h.Spacing / dir
+	}
+	edge := matrix.Float(cell) * h.Spacing
+	return -1, (edge - origin) / dir, -h.Spacing / dir
+}
+
+func (h *HeightField) cellRayHit(ray Ray, cx, cz int) (matrix.Float, bool) {
+	a := h.SamplePoint(cx, cz)
+	b := h.SamplePoint(cx+1, cz)
+	c := h.SamplePoint(cx, cz+1)
+	d := h.SamplePoint(cx+1, cz+1)
+	t0, hit0 := rayTriangleDistance(ray, a, c, b)
+	t1, hit1 := rayTriangleDistance(ray, b, c, d)
+	if hit0 && (!hit1 || t0 <= t1) {
+		return t0, true
+	}
+	return t1, hit1
+}
+
+// rayTriangleDistance returns the distance along the ray to where it passes
+// through the triangle, from either side of the triangle
+func rayTriangleDistance(ray Ray, a, b, c matrix.Vec3) (matrix.Float, bool) {
+	const epsilon = 1e-7
+	e1 := b.Subtract(a)
+	e2 := c.Subtract(a)
+	p := matrix.Vec3Cross(ray.Direction, e2)
+	det := matrix.Vec3Dot(e1, p)
+	if matrix.Abs(det) < epsilon {
+		return 0, false
+	}
+	inv := 1 / det
+	s := ray.Origin.Subtract(a)
+	u := matrix.Vec3Dot(s, p) * inv
+	if u < 0 || u > 1 {
+		return 0, false
+	}
+	q := matrix.Vec3Cross(s, e1)
+	v := matrix.Vec3Dot(ray.Direction, q) * inv
+	if v < 0 || u+v > 1 {
+		return 0, false
+	}
+	t := matrix.Vec3Dot(e2, q) * inv
+	return t, t >= 0
+}
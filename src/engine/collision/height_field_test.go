@@ -0,0 +1,126 @@
+/******************************************************************************/
+/* height_field_test.go                                                       */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package collision
+
+import (
+	"kaiju/matrix"
+	"testing"
+)
+
+// testSlope is a field that rises one unit for every unit along X
+func testSlope() HeightField {
+	h := NewHeightField(5, 4, 2)
+	for z := range h.Depth {
+		for x := range h.Width {
+			h.Heights[z*h.Width+x] = matrix.Float(x) * 2
+		}
+	}
+	return h
+}
+
+func TestHeightFieldHeightAt(t *testing.T) {
+	h := testSlope()
+	for _, x := range []matrix.Float{0, 1.5, 3, 5.25, 8} {
+		y, ok := h.HeightAt(x, 3.3)
+		if !ok || !matrix.Approx(y, x) {
+			t.Errorf("expected the height at %v to be %v, got %v", x, x, y)
+		}
+	}
+	if _, ok := h.HeightAt(8.5, 1); ok {
+		t.Error("expected a point past the edge to be outside of the field")
+	}
+	if _, ok := h.HeightAt(1, -0.1); ok {
+		t.Error("expected a negative point to be outside of the field")
+	}
+}
+
+func TestHeightFieldHeightFollowsTriangles(t *testing.T) {
+	h := NewHeightField(2, 2, 1)
+	// Only the +X +Z corner is raised, the triangle with the other three
+	// corners is flat
+	h.Heights[3] = 1
+	if y, _ := h.HeightAt(0.25, 0.25); y != 0 {
+		t.Errorf("expected the flat triangle to have no height, got %v", y)
+	}
+	if y, _ := h.HeightAt(0.75, 0.75); !matrix.Approx(y, 0.5) {
+		t.Errorf("expected the raised triangle to be half way up, got %v", y)
+	}
+}
+
+func TestHeightFieldNormal(t *testing.T) {
+	h := testSlope()
+	n, ok := h.NormalAt(4, 2)
+	expected := matrix.Vec3{-1, 1, 0}.Normal()
+	if !ok || !matrix.Vec3Approx(n, expected) {
+		t.Errorf("expected the normal of the slope %v, got %v", expected, n)
+	}
+	flat := NewHeightField(3, 3, 1)
+	if n, _ := flat.NormalAt(1, 1); !matrix.Vec3Approx(n, matrix.Vec3Up()) {
+		t.Errorf("expected a flat field to face up, got %v", n)
+	}
+}
+
+func TestHeightFieldRayHit(t *testing.T) {
+	h := testSlope()
+	down := Ray{matrix.Vec3{3, 20, 3}, matrix.Vec3Down()}
+	p, ok := h.RayHit(down, 100)
+	if !ok || !matrix.Vec3Approx(p, matrix.Vec3{3, 3, 3}) {
+		t.Errorf("expected the ray down to hit the slope, got %v %v", p, ok)
+	}
+	if _, ok = h.RayHit(down, 10); ok {
+		t.Error("expected a ray that is too short to miss")
+	}
+	// A flat ray along X at a height of 4 enters the slope where it is 4 high
+	across := Ray{matrix.Vec3{-10, 4, 1}, matrix.Vec3Right()}
+	p, ok = h.RayHit(across, 100)
+	if !ok || !matrix.Vec3Approx(p, matrix.Vec3{4, 4, 1}) {
+		t.Errorf("expected the ray across to hit the slope, got %v %v", p, ok)
+	}
+	away := Ray{matrix.Vec3{3, 20, 3}, matrix.Vec3Up()}
+	if _, ok = h.RayHit(away, 100); ok {
+		t.Error("expected a ray pointing away to miss")
+	}
+	diagonal := Ray{matrix.Vec3{0, 9, 0}, matrix.Vec3{1, -1, 1}.Normal()}
+	p, ok = h.RayHit(diagonal, 100)
+	if !ok {
+		t.Fatal("expected the diagonal ray to hit")
+	}
+	if y, _ := h.HeightAt(p.X(), p.Z()); !matrix.Approx(y, p.Y()) {
+		t.Errorf("expected the hit to be on the surface, got %v at height %v", p, y)
+	}
+}
@@ -10,6 +10,9 @@ type Shape = int
 const (
 	ShapeAABB = Shape(iota)
 	ShapeOOBB
+	// ShapeHeightField has a *collision.HeightField as its shape data, the
+	// field is in the local space of the transform
+	ShapeHeightField
 )
 
 type CollisionShape struct {
@@ -77,9 +77,10 @@ func (e *entityEditorBindings) AddDrawing(drawing rendering.Drawing) {
 	}
 	drawings = append(drawings, drawing)
 	defs = append(defs.([]drawingDef), drawingDef{
-		Material:   drawing.Material.Name,
-		MeshKey:    drawing.Mesh.Key(),
-		ShaderData: drawing.ShaderData,
+		Material:    drawing.Material.Name,
+		MeshKey:     drawing.Mesh.Key(),
+		CastShadows: drawing.CastShadows,
+		ShaderData:  drawing.ShaderData,
 	})
 	e.Set(editorDrawingBinding, drawings)
 	e.Set(editorDrawingDefinition, defs)
@@ -72,6 +72,7 @@ type drawingDef struct {
 	Material    string
 	MeshKey     string
 	UseBlending bool
+	CastShadows bool
 	ShaderData  rendering.DrawInstance
 }
 
@@ -137,8 +138,9 @@ func setupDrawings(e *Entity, host *Host, defs []drawingDef) ([]rendering.Drawin
 			Renderer:   host.Window.Renderer,
 			Material:   mat,
 			Mesh:       m,
-			ShaderData: d.ShaderData,
-			Transform:  &e.Transform,
+			ShaderData:  d.ShaderData,
+			Transform:   &e.Transform,
+			CastShadows: d.CastShadows,
 		}
 		host.Drawings.AddDrawing(drawing)
 		drawings = append(drawings, drawing)
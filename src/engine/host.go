@@ -90,12 +90,17 @@ type Host struct {
 	UICamera         cameras.Camera
 	collisionManager collision_system.Manager
 	audio            audio.Audio
+	audioListener    hostAudioListener
+	hotReload        hostHotReload
 	shaderCache      rendering.ShaderCache
 	textureCache     rendering.TextureCache
 	meshCache        rendering.MeshCache
 	fontCache        rendering.FontCache
 	materialCache    rendering.MaterialCache
+	renderTargets    []*rendering.RenderTarget
 	Drawings         rendering.Drawings
+	Lights           rendering.LightList
+	PostProcessing   rendering.PostProcessData
 	frame            FrameId
 	frameTime        float64
 	Closing          bool
@@ -130,6 +135,8 @@ func NewHost(name string, logStream *logging.LogStream) *Host {
 		LateUpdater:    NewUpdater(),
 		assetDatabase:  assets.NewDatabase(),
 		Drawings:       rendering.NewDrawings(),
+		Lights:         rendering.NewLightList(),
+		PostProcessing: rendering.DefaultPostProcessData(),
 		CloseSignal:    make(chan struct{}, 1),
 		Camera:         cameras.NewStandardCamera(w, h, w, h, matrix.Vec3Backward()),
 		UICamera:       cameras.NewStandardCameraOrthographic(w, h, w, h, matrix.Vec3{0, 0, 250}),
@@ -156,16 +163,21 @@ func (host *Host) Initialize(width, height, x, y int) error {
 		return err
 	}
 	host.Window = win
+	host.setupWindow(width, height)
+	return nil
+}
+
+func (host *Host) setupWindow(width, height int) {
 	host.threads.Start()
 	host.Camera.ViewportChanged(float32(width), float32(height))
 	host.UICamera.ViewportChanged(float32(width), float32(height))
 	host.shaderCache = rendering.NewShaderCache(host.Window.Renderer, &host.assetDatabase)
 	host.textureCache = rendering.NewTextureCache(host.Window.Renderer, &host.assetDatabase)
+	host.textureCache.SetImportedDataLookup(host.importedTextureData)
 	host.meshCache = rendering.NewMeshCache(host.Window.Renderer, &host.assetDatabase)
 	host.fontCache = rendering.NewFontCache(host.Window.Renderer, &host.assetDatabase)
 	host.materialCache = rendering.NewMaterialCache(host.Window.Renderer, &host.assetDatabase)
 	host.Window.OnResize.Add(host.resized)
-	return nil
 }
 
 func (host *Host) InitializeAudio() error {
@@ -244,6 +256,42 @@ func (host *Host) Audio() *audio.Audio {
 	return &host.audio
 }
 
+// EnableShadows sets up the shadow pass so that lights with CastShadows set
+// will draw the shadows of drawings that have CastShadows set. Only the
+// drawings that are added after shadows are enabled will cast shadows.
+func (host *Host) EnableShadows() error {
+	material, err := host.materialCache.Material(assets.MaterialDefinitionShadow)
+	if err != nil {
+		return err
+	}
+	host.Drawings.EnableShadows(material, &host.Lights)
+	return nil
+}
+
+// EnablePostProcessing draws the effects that are enabled in
+// #Host.PostProcessing over the final image. The settings are read every
+// frame, so they can be changed directly on the host after this call.
+func (host *Host) EnablePostProcessing() {
+	host.Window.Renderer.SetPostProcessing(&host.PostProcessing)
+}
+
+// DisablePostProcessing stops drawing the post-processing effects
+func (host *Host) DisablePostProcessing() {
+	host.Window.Renderer.SetPostProcessing(nil)
+}
+
+// LoadPostProcessing reads the post-processing asset with the given key into
+// #Host.PostProcessing and enables post-processing
+func (host *Host) LoadPostProcessing(key string) error {
+	data, err := rendering.LoadPostProcessData(&host.assetDatabase, key)
+	if err != nil {
+		return err
+	}
+	host.PostProcessing = data
+	host.EnablePostProcessing()
+	return nil
+}
+
 // AddEntity adds an entity to the host. This will add the entity to the
 // standard entity pool. If the host is in the process of creating editor
 // entities, then the entity will be added to the editor entity pool.
@@ -345,8 +393,11 @@ func (host *Host) Update(deltaTime float64) {
 	}
 	host.UIUpdater.Update(deltaTime)
 	host.UILateUpdater.Update(deltaTime)
+	host.updateAudioListener(deltaTime)
+	host.updateHotReload(deltaTime)
 	host.Updater.Update(deltaTime)
 	host.LateUpdater.Update(deltaTime)
+	host.advanceOfflineAudio(deltaTime)
 	host.collisionManager.Update(deltaTime)
 	if host.Window.IsClosed() || host.Window.IsCrashed() {
 		host.Closing = true
@@ -374,16 +425,20 @@ func (host *Host) Render() {
 	defer tracing.NewRegion("Host::Render").End()
 	host.workGroup.Execute(matrix.TransformWorkGroup, &host.threads)
 	host.Drawings.PreparePending()
-	host.shaderCache.CreatePending()
-	host.textureCache.CreatePending()
-	host.meshCache.CreatePending()
-	if host.Drawings.HasDrawings() {
-		if host.Window.Renderer.ReadyFrame(host.Camera,
-			host.UICamera, float32(host.Runtime())) {
-			host.Drawings.Render(host.Window.Renderer)
+	// Headless update-only hosts have no renderer, they still clean the
+	// transformations so that dirty checks behave the same as when rendering
+	if host.Window.Renderer != nil {
+		host.shaderCache.CreatePending()
+		host.textureCache.CreatePending()
+		host.meshCache.CreatePending()
+		if host.Drawings.HasDrawings() {
+			if host.Window.Renderer.ReadyFrame(host.Camera,
+				host.UICamera, &host.Lights, float32(host.Runtime())) {
+				host.Drawings.Render(host.Window.Renderer)
+			}
 		}
+		host.Window.SwapBuffers()
 	}
-	host.Window.SwapBuffers()
 	host.workGroup.Execute(matrix.TransformResetWorkGroup, &host.threads)
 	//host.editorEntities.resetDirty()
 }
@@ -406,18 +461,24 @@ func (host *Host) RunAfterFrames(wait int, call func()) {
 // Teardown will destroy the host and all of its resources. This will also
 // execute the OnClose event. This will also signal the CloseSignal channel.
 func (host *Host) Teardown() {
-	host.Window.Renderer.WaitForRender()
+	renderer := host.Window.Renderer
+	if renderer != nil {
+		renderer.WaitForRender()
+	}
 	host.OnClose.Execute()
 	host.UIUpdater.Destroy()
 	host.UILateUpdater.Destroy()
 	host.Updater.Destroy()
 	host.LateUpdater.Destroy()
-	host.Drawings.Destroy(host.Window.Renderer)
-	host.textureCache.Destroy()
-	host.meshCache.Destroy()
-	host.shaderCache.Destroy()
-	host.fontCache.Destroy()
-	host.materialCache.Destroy()
+	if renderer != nil {
+		host.destroyRenderTargets()
+		host.Drawings.Destroy(renderer)
+		host.textureCache.Destroy()
+		host.meshCache.Destroy()
+		host.shaderCache.Destroy()
+		host.fontCache.Destroy()
+		host.materialCache.Destroy()
+	}
 	host.assetDatabase.Destroy()
 	host.Window.Destroy()
 	host.threads.Stop()
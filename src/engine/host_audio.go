@@ -0,0 +1,153 @@
+/******************************************************************************/
+/* host_audio.go                                                              */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package engine
+
+import (
+	"encoding/json"
+	"kaiju/engine/assets"
+	"kaiju/engine/assets/asset_info"
+	"kaiju/matrix"
+	"kaiju/platform/audio"
+)
+
+// LoadAudioClip loads the clip for the given audio asset key. Within the
+// editor, imported audio is read from the project cache where it has already
+// been converted to the output format along with the loop points and volume
+// set on import. Otherwise the file is decoded and converted from the asset
+// database and the loop points and volume are read from the import settings
+// (the ADI file) next to it.
+func (host *Host) LoadAudioClip(key string) (*audio.Clip, error) {
+	if clip, ok := loadImportedAudioClip(key); ok {
+		return clip, nil
+	}
+	clip, err := host.audio.LoadClip(host.AssetDatabase(), key)
+	if err != nil {
+		return nil, err
+	}
+	if err := applyAudioImportSettings(host.AssetDatabase(), key, clip); err != nil {
+		return nil, err
+	}
+	return clip, nil
+}
+
+// applyAudioImportSettings sets the volume and loop points that the audio
+// was imported with, audio that was never imported has no ADI file and is
+// left as it is
+func applyAudioImportSettings(db *assets.Database, key string, clip *audio.Clip) error {
+	adiKey := key + asset_info.InfoExtension
+	if !db.Exists(adiKey) {
+		return nil
+	}
+	src, err := db.ReadText(adiKey)
+	if err != nil {
+		return err
+	}
+	var adi struct {
+		Metadata struct {
+			Volume    float32
+			LoopStart float32
+			LoopEnd   float32
+		}
+	}
+	adi.Metadata.Volume = 1
+	if err := json.Unmarshal([]byte(src), &adi); err != nil {
+		return err
+	}
+	clip.Volume = adi.Metadata.Volume
+	clip.SetLoopPoints(adi.Metadata.LoopStart, adi.Metadata.LoopEnd)
+	return nil
+}
+
+// InitializeOfflineAudio sets up audio that isn't played through an output
+// device, the audio is mixed into memory as the host updates using the
+// update delta time as its clock (see audio.OfflineOutput). This can be used
+// in place of InitializeAudio when there is no sound card or when capturing.
+func (host *Host) InitializeOfflineAudio() {
+	host.audio = audio.NewOfflineAudio(audio.OutputSampleRate, audio.OutputChannels)
+}
+
+func (host *Host) advanceOfflineAudio(deltaTime float64) {
+	if offline := host.audio.Offline(); offline != nil {
+		offline.Advance(deltaTime)
+	}
+}
+
+// SetAudioListener will have the audio listener follow the given entity, this
+// is typically the player character rather than the camera. Passing nil will
+// have the listener follow #Host.Camera, which is the default.
+func (host *Host) SetAudioListener(entity *Entity) {
+	host.audioListener.entity = entity
+}
+
+// AudioListener returns the entity that the audio listener is following, nil
+// means that the listener is following #Host.Camera
+func (host *Host) AudioListener() *Entity {
+	return host.audioListener.entity
+}
+
+type hostAudioListener struct {
+	entity       *Entity
+	lastPosition matrix.Vec3
+	hasPosition  bool
+}
+
+func (host *Host) updateAudioListener(deltaTime float64) {
+	l := &host.audioListener
+	if l.entity != nil && l.entity.IsDestroyed() {
+		l.entity = nil
+	}
+	listener := audio.Listener{}
+	if l.entity != nil {
+		w := l.entity.Transform.WorldMatrix()
+		listener.Position = l.entity.Transform.WorldPosition()
+		listener.Forward = w.Forward().Normal()
+		listener.Up = w.Up().Normal()
+	} else if host.Camera != nil {
+		listener.Position = host.Camera.Position()
+		listener.Forward = host.Camera.Forward()
+		listener.Up = host.Camera.Up()
+	} else {
+		return
+	}
+	if l.hasPosition && deltaTime > 0 {
+		listener.Velocity = listener.Position.Subtract(l.lastPosition).Shrink(matrix.Float(deltaTime))
+	}
+	l.lastPosition = listener.Position
+	l.hasPosition = true
+	host.audio.SetListener(listener)
+}
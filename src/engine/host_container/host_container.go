@@ -65,6 +65,25 @@ func (c *Container) Run(width, height, x, y int) error {
 	}
 	c.Host.Window.Renderer.Initialize(c.Host, int32(c.Host.Window.Width()), int32(c.Host.Window.Height()))
 	c.Host.FontCache().Init(c.Host.Window.Renderer, c.Host.AssetDatabase(), c.Host)
+	c.runLoop(0)
+	runtime.UnlockOSThread()
+	return nil
+}
+
+// RunHeadless is the same as #Container.Run, except that the host is run
+// without a window (see engine.Host.InitializeHeadless). The OS thread is not
+// locked since there is no native window that requires it. When
+// options.FixedDeltaTime is set, each frame advances by that amount instead of
+// the time that actually passed, which keeps simulations deterministic.
+func (c *Container) RunHeadless(options engine.HeadlessOptions) error {
+	if err := c.Host.InitializeHeadless(options); err != nil {
+		return err
+	}
+	c.runLoop(options.FixedDeltaTime)
+	return nil
+}
+
+func (c *Container) runLoop(fixedDeltaTime float64) {
 	lastTime := time.Now()
 	// Do one clean update and render before opening the prep lock
 	c.Host.Update(0)
@@ -77,9 +96,11 @@ func (c *Container) Run(width, height, x, y int) error {
 		traceRegionName.WriteString(strconv.FormatUint(c.Host.Frame(), 10))
 		r := tracing.NewRegion(traceRegionName.String())
 		c.Host.WaitForFrameRate()
-		since := time.Since(lastTime)
-		deltaTime := since.Seconds()
-		lastTime = time.Now()
+		deltaTime := fixedDeltaTime
+		if deltaTime <= 0 {
+			deltaTime = time.Since(lastTime).Seconds()
+			lastTime = time.Now()
+		}
 		c.Host.Update(deltaTime)
 		if !c.Host.Closing {
 			c.Host.Render()
@@ -88,8 +109,6 @@ func (c *Container) Run(width, height, x, y int) error {
 	}
 	console.UnlinkHost(c.Host)
 	c.Host.Teardown()
-	runtime.UnlockOSThread()
-	return nil
 }
 
 func New(name string, logStream *logging.LogStream) *Container {
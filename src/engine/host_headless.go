@@ -0,0 +1,93 @@
+/******************************************************************************/
+/* host_headless.go                                                           */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package engine
+
+import (
+	"kaiju/platform/windowing"
+	"kaiju/rendering"
+)
+
+// HeadlessOptions describes how a host is run without a window. This is used
+// for dedicated servers, simulations, and automated tests that need to run on
+// machines without a display server or GPU.
+type HeadlessOptions struct {
+	// Width and height of the virtual window, defaults are used when <= 0
+	Width  int
+	Height int
+	// Render will draw frames with the software renderer into memory. When
+	// false there is no renderer at all and #Host.Render only cleans up the
+	// dirty transformations for the frame.
+	Render bool
+	// FixedDeltaTime, when greater than 0, is the number of seconds that
+	// every frame advances by in place of the wall clock
+	FixedDeltaTime float64
+}
+
+// InitializeHeadless will set up the host with a window that has no native
+// window behind it (see windowing.NewHeadless). Unlike #Host.Initialize, the
+// renderer and font cache are fully initialized by this call so that the host
+// can be stepped manually by calling #Host.Update and #Host.Render with any
+// delta time. Input can be fed through the Inject* functions on the window.
+// Build with the nowindow tag to run on machines without the X11 libraries.
+func (host *Host) InitializeHeadless(options HeadlessOptions) error {
+	width, height := options.Width, options.Height
+	if width <= 0 {
+		width = DefaultWindowWidth
+	}
+	if height <= 0 {
+		height = DefaultWindowHeight
+	}
+	var renderer rendering.Renderer
+	if options.Render {
+		renderer = rendering.NewSoftwareRenderer(int32(width), int32(height))
+	}
+	host.Window = windowing.NewHeadless(host.name, width, height, renderer)
+	host.setupWindow(width, height)
+	if renderer == nil {
+		return nil
+	}
+	if err := renderer.Initialize(host, int32(width), int32(height)); err != nil {
+		return err
+	}
+	return host.fontCache.Init(renderer, host.AssetDatabase(), host)
+}
+
+// IsHeadless will return true if the host was initialized without a window
+func (host *Host) IsHeadless() bool {
+	return host.Window != nil && host.Window.IsHeadless()
+}
@@ -0,0 +1,118 @@
+/******************************************************************************/
+/* host_hot_reload.go                                                         */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package engine
+
+import (
+	"kaiju/platform/filesystem"
+	"kaiju/rendering"
+	"log/slog"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// hotReloadInterval is how many seconds pass between checking the watched
+// folders for changes
+const hotReloadInterval = 0.5
+
+type hostHotReload struct {
+	watcher filesystem.Watcher
+	keys    []string
+	folders []string
+	elapsed float64
+	enabled bool
+}
+
+// EnableHotReload watches the given content folders for changes to shader,
+// shader pipeline, material, render pass and GLSL source assets, and reloads
+// them into the live shaders and materials. When no folders are given, the
+// renderer folder is watched. GLSL is compiled with glslc, which must be on
+// the path. Errors, like a shader that fails to compile, are logged and the
+// previously loaded asset is kept.
+func (host *Host) EnableHotReload(folders ...string) {
+	if len(folders) == 0 {
+		folders = []string{"renderer"}
+	}
+	r := &host.hotReload
+	r.keys = folders
+	r.folders = make([]string, len(folders))
+	for i := range folders {
+		r.folders[i] = host.assetDatabase.ToRawPath(folders[i])
+	}
+	r.watcher = filesystem.NewWatcher(r.folders...)
+	r.elapsed = 0
+	r.enabled = true
+}
+
+// DisableHotReload stops watching for asset changes
+func (host *Host) DisableHotReload() { host.hotReload.enabled = false }
+
+func (host *Host) updateHotReload(deltaTime float64) {
+	r := &host.hotReload
+	if !r.enabled || host.Window.Renderer == nil {
+		return
+	}
+	r.elapsed += deltaTime
+	if r.elapsed < hotReloadInterval {
+		return
+	}
+	r.elapsed = 0
+	for _, file := range r.watcher.Changes() {
+		key, ok := r.contentKey(file)
+		if !ok || !rendering.CanHotReload(key) {
+			continue
+		}
+		if err := rendering.HotReload(host.Window.Renderer, host, key); err != nil {
+			slog.Error("failed to hot reload the asset", "asset", key, "error", err)
+		} else {
+			slog.Info("hot reloaded the asset", "asset", key)
+		}
+	}
+}
+
+// contentKey converts the path of a changed file back to the content relative
+// key of the asset by finding the watched folder it is in
+func (r *hostHotReload) contentKey(file string) (string, bool) {
+	for i, folder := range r.folders {
+		rel, err := filepath.Rel(folder, file)
+		if err == nil && !strings.HasPrefix(rel, "..") {
+			return path.Join(filepath.ToSlash(r.keys[i]), filepath.ToSlash(rel)), true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,143 @@
+/******************************************************************************/
+/* host_render_target.go                                                      */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package engine
+
+import (
+	"errors"
+	"image"
+	"image/png"
+	"kaiju/rendering"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+)
+
+var errNoRenderer = errors.New("the host has no renderer")
+
+// NewRenderTarget creates a texture that a secondary camera renders into. The
+// texture of the target can be used in a material instance for things like
+// mirrors, minimaps, and security cameras. Drawings are drawn into the target
+// by adding it to their RenderTargets. The host destroys the target when it is
+// torn down, or it can be destroyed early with #Host.DestroyRenderTarget.
+func (host *Host) NewRenderTarget(name string, width, height int) (*rendering.RenderTarget, error) {
+	if host.Window == nil || host.Window.Renderer == nil {
+		return nil, errNoRenderer
+	}
+	if slices.ContainsFunc(host.renderTargets, func(t *rendering.RenderTarget) bool {
+		return t.Name() == name
+	}) {
+		return nil, errors.New("a render target with the same name already exists")
+	}
+	target, err := rendering.NewRenderTarget(host.Window.Renderer, host, name, width, height)
+	if err != nil {
+		return nil, err
+	}
+	host.renderTargets = append(host.renderTargets, target)
+	return target, nil
+}
+
+// DestroyRenderTarget removes the drawings of the render target and then
+// destroys it, materials using its texture must no longer be drawn
+func (host *Host) DestroyRenderTarget(target *rendering.RenderTarget) {
+	idx := slices.Index(host.renderTargets, target)
+	if idx < 0 {
+		return
+	}
+	host.renderTargets = slices.Delete(host.renderTargets, idx, idx+1)
+	host.Drawings.RemoveRenderTarget(target, host.Window.Renderer)
+	target.Destroy(host.Window.Renderer)
+}
+
+// Screenshot writes the next frame that is drawn to a PNG file at the given
+// path. The frame is captured after post processing, before it is presented.
+// Done is called with the result once the file is written and can be nil, in
+// which case errors are logged.
+func (host *Host) Screenshot(path string, done func(error)) {
+	finish := func(err error) {
+		if done != nil {
+			done(err)
+		} else if err != nil {
+			slog.Error("failed to take the screenshot", "path", path, "error", err)
+		}
+	}
+	if host.Window == nil || host.Window.Renderer == nil {
+		finish(errNoRenderer)
+		return
+	}
+	host.Window.Renderer.CaptureFrame(func(img *image.RGBA, err error) {
+		if err == nil {
+			err = writePNG(path, img)
+		}
+		finish(err)
+	})
+}
+
+// SaveRenderTarget writes what was last drawn into the render target to a PNG
+// file at the given path
+func (host *Host) SaveRenderTarget(target *rendering.RenderTarget, path string) error {
+	if host.Window == nil || host.Window.Renderer == nil {
+		return errNoRenderer
+	}
+	img, err := host.Window.Renderer.TextureReadPixels(target.Texture())
+	if err != nil {
+		return err
+	}
+	return writePNG(path, img)
+}
+
+func (host *Host) destroyRenderTargets() {
+	for len(host.renderTargets) > 0 {
+		host.DestroyRenderTarget(host.renderTargets[len(host.renderTargets)-1])
+	}
+}
+
+func writePNG(path string, img image.Image) error {
+	if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+		return err
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := png.Encode(f, img); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
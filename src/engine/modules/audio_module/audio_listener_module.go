@@ -0,0 +1,11 @@
+package audio_module
+
+import "kaiju/engine"
+
+// AudioListenerModuleBinding will make the entity the audio listener for the
+// host, when the entity is destroyed the listener returns to the host camera
+type AudioListenerModuleBinding struct{}
+
+func (b *AudioListenerModuleBinding) Init(e *engine.Entity, host *engine.Host) {
+	host.SetAudioListener(e)
+}
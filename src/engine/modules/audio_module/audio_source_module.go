@@ -0,0 +1,202 @@
+package audio_module
+
+import (
+	"kaiju/engine"
+	"kaiju/matrix"
+	"kaiju/platform/audio"
+	"log/slog"
+	"strings"
+)
+
+const (
+	AudioSourceEntityDataName = "AudioSource"
+)
+
+// AudioSource plays a clip from the position of an entity, the volume, pan and
+// pitch of the voice are updated every frame based on where the entity is
+// relative to the host's audio listener
+type AudioSource struct {
+	entity       *engine.Entity
+	host         *engine.Host
+	clip         *audio.Clip
+	stream       string
+	handle       audio.VoiceHandle
+	Options      audio.PlayOptions
+	Settings     audio.SpatialSettings
+	lastPosition matrix.Vec3
+	updateId     int
+	inactive     bool
+	// pausedInactive is true when the voice was paused because the entity
+	// was deactivated, so it is only resumed if it wasn't paused before
+	pausedInactive bool
+}
+
+type AudioSourceModuleBinding struct {
+	Clip        string
+	Bus         string  `default:"sfx"`
+	Volume      float32 `clamp:"1,0,1"` //default,min,max
+	Pitch       float32 `default:"1"`
+	Loop        bool
+	PlayOnInit  bool
+	Stream      bool // decode from disk while playing, for music
+	Priority    int
+	Attenuation string  `default:"inverse"` // linear, inverse, none
+	MinDistance float32 `default:"1"`
+	MaxDistance float32 `default:"100"`
+	Rolloff     float32 `default:"1"`
+	Doppler     float32
+	ConeInner   float32
+	ConeOuter   float32
+	ConeGain    float32 `default:"1"`
+}
+
+func (b *AudioSourceModuleBinding) Init(e *engine.Entity, host *engine.Host) {
+	var clip *audio.Clip
+	if b.Clip != "" && !b.Stream {
+		var err error
+		clip, err = host.LoadAudioClip(b.Clip)
+		if err != nil {
+			slog.Error("failed to load the audio source clip", "clip", b.Clip, "error", err)
+		}
+	}
+	opts := audio.PlayOptions{
+		Bus:      b.Bus,
+		Volume:   b.Volume,
+		Pitch:    b.Pitch,
+		Loop:     b.Loop,
+		Priority: b.Priority,
+	}
+	settings := audio.DefaultSpatialSettings()
+	switch strings.ToLower(b.Attenuation) {
+	case "linear":
+		settings.Attenuation = audio.AttenuationLinear
+	case "none":
+		settings.Attenuation = audio.AttenuationNone
+	}
+	settings.MinDistance = matrix.Float(b.MinDistance)
+	settings.MaxDistance = matrix.Float(b.MaxDistance)
+	settings.Rolloff = matrix.Float(b.Rolloff)
+	settings.DopplerFactor = matrix.Float(b.Doppler)
+	settings.ConeInnerAngle = matrix.Float(b.ConeInner)
+	settings.ConeOuterAngle = matrix.Float(b.ConeOuter)
+	settings.ConeOuterGain = matrix.Float(b.ConeGain)
+	s := NewAudioSource(e, host, clip, opts, settings)
+	if b.Stream {
+		s.SetStream(b.Clip)
+	}
+	if b.PlayOnInit {
+		s.Play()
+	}
+}
+
+// NewAudioSource creates a source that follows the entity's transform and
+// attaches it to the entity under the AudioSourceEntityDataName name
+func NewAudioSource(e *engine.Entity, host *engine.Host, clip *audio.Clip,
+	options audio.PlayOptions, settings audio.SpatialSettings) *AudioSource {
+
+	s := &AudioSource{
+		entity:       e,
+		host:         host,
+		clip:         clip,
+		Options:      options,
+		Settings:     settings,
+		lastPosition: e.Transform.WorldPosition(),
+	}
+	e.AddNamedData(AudioSourceEntityDataName, s)
+	s.updateId = host.Updater.AddUpdate(s.update)
+	e.OnDestroy.Add(func() {
+		host.Updater.RemoveUpdate(s.updateId)
+		s.Stop()
+	})
+	return s
+}
+
+// Clip returns the clip that is played by this source
+func (s *AudioSource) Clip() *audio.Clip { return s.clip }
+
+// SetClip changes the clip that will be played the next time Play is called
+func (s *AudioSource) SetClip(clip *audio.Clip) {
+	s.clip = clip
+	s.stream = ""
+}
+
+// Stream returns the key of the audio file that is streamed by this source
+func (s *AudioSource) Stream() string { return s.stream }
+
+// SetStream changes the source to stream the audio file with the given key
+// from the asset database the next time Play is called, a new stream is
+// opened for each call to Play
+func (s *AudioSource) SetStream(key string) {
+	s.stream = key
+	s.clip = nil
+}
+
+// Handle returns the voice handle of the currently playing clip
+func (s *AudioSource) Handle() audio.VoiceHandle { return s.handle }
+
+func (s *AudioSource) IsPlaying() bool { return s.handle.IsPlaying() }
+
+// Play will start playing the clip from the entity, if the source is already
+// playing then the previous voice is stopped
+func (s *AudioSource) Play() {
+	opts := s.Options
+	opts.Paused = true
+	if s.stream != "" {
+		stream, err := s.host.Audio().OpenStream(s.host.AssetDatabase(),
+			s.stream, audio.StreamOptions{})
+		if err != nil {
+			slog.Error("failed to open the audio source stream", "stream", s.stream, "error", err)
+			return
+		}
+		s.handle.Stop()
+		s.handle = s.host.Audio().PlayStream(stream, opts)
+	} else if s.clip != nil {
+		s.handle.Stop()
+		s.handle = s.host.Audio().PlayClip(s.clip, opts)
+	} else {
+		return
+	}
+	// The new voice is paused on the next update if the entity is inactive
+	s.inactive = false
+	s.pausedInactive = false
+	s.spatialize(0)
+	if !s.Options.Paused {
+		s.handle.Resume()
+	}
+}
+
+func (s *AudioSource) Stop() { s.handle.Stop() }
+
+func (s *AudioSource) update(deltaTime float64) {
+	if !s.handle.IsValid() {
+		return
+	}
+	if !s.entity.IsActive() {
+		if !s.inactive {
+			s.pausedInactive = s.handle.IsPlaying()
+			s.handle.Pause()
+			s.inactive = true
+		}
+		return
+	} else if s.inactive {
+		if s.pausedInactive {
+			s.handle.Resume()
+		}
+		s.inactive = false
+		s.pausedInactive = false
+	}
+	s.spatialize(deltaTime)
+}
+
+func (s *AudioSource) spatialize(deltaTime float64) {
+	w := s.entity.Transform.WorldMatrix()
+	emitter := audio.SpatialEmitter{
+		Position: s.entity.Transform.WorldPosition(),
+		Forward:  w.Forward().Normal(),
+	}
+	if deltaTime > 0 {
+		emitter.Velocity = emitter.Position.Subtract(s.lastPosition).Shrink(matrix.Float(deltaTime))
+	}
+	s.lastPosition = emitter.Position
+	s.handle.SetSpatial(s.Settings.Compute(s.host.Audio().Listener(), emitter))
+}
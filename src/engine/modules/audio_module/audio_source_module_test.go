@@ -0,0 +1,100 @@
+package audio_module
+
+import (
+	"bytes"
+	"encoding/binary"
+	"kaiju/engine"
+	"kaiju/platform/audio"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func testAudioSource(t *testing.T) (*engine.Host, *engine.Entity, *AudioSource) {
+	t.Helper()
+	host := engine.NewHost("Test audio source", nil)
+	host.InitializeOfflineAudio()
+	clip := &audio.Clip{
+		Samples:    make([]float32, audio.OutputSampleRate*2),
+		Channels:   2,
+		SampleRate: audio.OutputSampleRate,
+		Volume:     1,
+	}
+	e := engine.NewEntity(host.WorkGroup())
+	opts := audio.DefaultPlayOptions()
+	opts.Loop = true
+	s := NewAudioSource(e, host, clip, opts, audio.DefaultSpatialSettings())
+	s.Play()
+	if !s.IsPlaying() {
+		t.Fatal("expected the audio source to be playing")
+	}
+	return host, e, s
+}
+
+func TestAudioSourceDeactivatePauses(t *testing.T) {
+	host, e, s := testAudioSource(t)
+	e.Deactivate()
+	host.Updater.Update(0.016)
+	if s.IsPlaying() {
+		t.Error("expected the voice to be paused while the entity is inactive")
+	}
+	e.Activate()
+	host.Updater.Update(0.016)
+	if !s.IsPlaying() {
+		t.Error("expected the voice to resume when the entity is activated")
+	}
+}
+
+func TestAudioSourceKeepsUserPause(t *testing.T) {
+	host, e, s := testAudioSource(t)
+	s.Handle().Pause()
+	e.Deactivate()
+	host.Updater.Update(0.016)
+	e.Activate()
+	host.Updater.Update(0.016)
+	if s.IsPlaying() {
+		t.Error("expected the voice paused by the user to stay paused after reactivation")
+	}
+	if !s.Handle().IsValid() {
+		t.Error("expected the voice to still be valid")
+	}
+}
+
+func TestLoadAudioClipImportSettings(t *testing.T) {
+	t.Chdir(t.TempDir())
+	frames := audio.OutputSampleRate
+	var wav bytes.Buffer
+	wav.WriteString("RIFF")
+	binary.Write(&wav, binary.LittleEndian, uint32(36+frames*4))
+	wav.WriteString("WAVEfmt ")
+	for _, v := range []any{uint32(16), uint16(1), uint16(2), uint32(audio.OutputSampleRate),
+		uint32(audio.OutputSampleRate * 4), uint16(4), uint16(16)} {
+		binary.Write(&wav, binary.LittleEndian, v)
+	}
+	wav.WriteString("data")
+	binary.Write(&wav, binary.LittleEndian, uint32(frames*4))
+	wav.Write(make([]byte, frames*4))
+	os.MkdirAll("content/audio", os.ModePerm)
+	os.WriteFile(filepath.Join("content/audio", "loop.wav"), wav.Bytes(), os.ModePerm)
+	os.WriteFile(filepath.Join("content/audio", "plain.wav"), wav.Bytes(), os.ModePerm)
+	os.WriteFile(filepath.Join("content/audio", "loop.wav.adi"),
+		[]byte(`{"ID":"loop","Metadata":{"Volume":0.5,"LoopStart":0.25,"LoopEnd":0.5}}`), os.ModePerm)
+	host := engine.NewHost("Test audio import settings", nil)
+	host.InitializeOfflineAudio()
+	clip, err := host.LoadAudioClip("audio/loop.wav")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if clip.Volume != 0.5 || clip.LoopStart != frames/4 || clip.LoopEnd != frames/2 {
+		t.Errorf("expected the import settings on the clip, got volume %v loop %d-%d",
+			clip.Volume, clip.LoopStart, clip.LoopEnd)
+	}
+	plain, err := host.LoadAudioClip("audio/plain.wav")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if plain.Volume != 1 || plain.LoopStart != 0 || plain.LoopEnd != 0 {
+		t.Errorf("expected audio without an ADI to keep the defaults, got volume %v loop %d-%d",
+			plain.Volume, plain.LoopStart, plain.LoopEnd)
+	}
+}
@@ -0,0 +1,108 @@
+package light_module
+
+import (
+	"kaiju/engine"
+	"kaiju/matrix"
+	"kaiju/rendering"
+)
+
+const (
+	LightModuleEntityDataName = "LightModule"
+)
+
+// LightModule keeps a light in the host's light list in sync with the entity
+// that it is attached to. The light follows the entity's world position and
+// forward direction, is turned off while the entity is inactive, and is
+// removed from the host when the entity is destroyed.
+type LightModule struct {
+	entity   *engine.Entity
+	host     *engine.Host
+	light    *rendering.Light
+	updateId int
+}
+
+type DirectionalLightModuleBinding struct {
+	Color       matrix.Color // white when left empty
+	Intensity   float32      `default:"1"`
+	CastShadows bool
+}
+
+type PointLightModuleBinding struct {
+	Color     matrix.Color // white when left empty
+	Intensity float32      `default:"1"`
+	Range     float32      `default:"10"`
+}
+
+type SpotLightModuleBinding struct {
+	Color       matrix.Color // white when left empty
+	Intensity   float32      `default:"1"`
+	Range       float32      `default:"10"`
+	InnerAngle  float32      `clamp:"20,0,90"` //default,min,max
+	OuterAngle  float32      `clamp:"30,0,90"` //default,min,max
+	CastShadows bool
+}
+
+func (b *DirectionalLightModuleBinding) Init(e *engine.Entity, host *engine.Host) {
+	light := rendering.NewDirectionalLight(matrix.Vec3Forward(),
+		bindingColor(b.Color), matrix.Float(b.Intensity))
+	light.CastShadows = b.CastShadows
+	NewLightModule(e, host, light)
+}
+
+func (b *PointLightModuleBinding) Init(e *engine.Entity, host *engine.Host) {
+	NewLightModule(e, host, rendering.NewPointLight(e.Transform.WorldPosition(),
+		bindingColor(b.Color), matrix.Float(b.Intensity), matrix.Float(b.Range)))
+}
+
+func (b *SpotLightModuleBinding) Init(e *engine.Entity, host *engine.Host) {
+	light := rendering.NewSpotLight(e.Transform.WorldPosition(),
+		matrix.Vec3Forward(), bindingColor(b.Color), matrix.Float(b.Intensity),
+		matrix.Float(b.Range), matrix.Float(b.InnerAngle), matrix.Float(b.OuterAngle))
+	light.CastShadows = b.CastShadows
+	NewLightModule(e, host, light)
+}
+
+func bindingColor(c matrix.Color) matrix.Color {
+	if c == (matrix.Color{}) {
+		return matrix.ColorWhite()
+	}
+	return c
+}
+
+// NewLightModule adds the light to the host and attaches it to the entity
+// under the LightModuleEntityDataName name
+func NewLightModule(e *engine.Entity, host *engine.Host, light *rendering.Light) *LightModule {
+	lm := &LightModule{
+		entity: e,
+		host:   host,
+		light:  light,
+	}
+	lm.sync()
+	host.Lights.Add(light)
+	e.AddNamedData(LightModuleEntityDataName, lm)
+	lm.updateId = host.Updater.AddUpdate(lm.update)
+	e.OnDestroy.Add(func() {
+		host.Updater.RemoveUpdate(lm.updateId)
+		host.Lights.Remove(lm.light)
+	})
+	return lm
+}
+
+// Light returns the light that is controlled by this module, its color,
+// intensity, range and cone can be changed at any time
+func (lm *LightModule) Light() *rendering.Light { return lm.light }
+
+func (lm *LightModule) update(deltaTime float64) {
+	if !lm.entity.IsActive() {
+		lm.light.Deactivate()
+		return
+	}
+	lm.light.Activate()
+	lm.sync()
+}
+
+func (lm *LightModule) sync() {
+	world := lm.entity.Transform.WorldMatrix()
+	lm.light.Position = world.Position()
+	lm.light.Direction = world.Forward().Normal()
+}
@@ -0,0 +1,11 @@
+//go:build !editor
+
+package light_module
+
+import "kaiju/engine"
+
+func init() {
+	engine.RegisterEntityData(&DirectionalLightModuleBinding{})
+	engine.RegisterEntityData(&PointLightModuleBinding{})
+	engine.RegisterEntityData(&SpotLightModuleBinding{})
+}
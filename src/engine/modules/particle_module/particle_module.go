@@ -0,0 +1,132 @@
+package particle_module
+
+import (
+	"errors"
+	"kaiju/engine"
+	"kaiju/engine/assets"
+	"kaiju/engine/systems/particles"
+	"kaiju/rendering"
+	"kaiju/rendering/loaders"
+	"kaiju/rendering/loaders/load_result"
+	"log/slog"
+	"path/filepath"
+	"strings"
+)
+
+const ParticleModuleEntityDataName = "ParticleEmitter"
+
+type ParticleEmitterModuleBinding struct {
+	Emitter    string // the .particle emitter file
+	PlayOnInit bool   `default:"true"`
+}
+
+// ParticleModule simulates a particle emitter at the position of the entity
+type ParticleModule struct {
+	entity   *engine.Entity
+	host     *engine.Host
+	emitter  *particles.Emitter
+	updateId int
+}
+
+func (b *ParticleEmitterModuleBinding) Init(e *engine.Entity, host *engine.Host) {
+	data := particles.DefaultEmitterData()
+	if b.Emitter != "" {
+		var err error
+		if data, err = particles.LoadEmitterData(host.AssetDatabase(), b.Emitter); err != nil {
+			slog.Error("failed to load the particle emitter", "emitter", b.Emitter, "error", err)
+			return
+		}
+	}
+	pm, err := NewParticleModule(e, host, data)
+	if err != nil {
+		slog.Error("failed to create the particle emitter", "emitter", b.Emitter, "error", err)
+		return
+	}
+	if b.PlayOnInit {
+		pm.Emitter().Play()
+	}
+}
+
+// NewParticleModule creates an emitter that spawns particles from the
+// entity's transform and attaches it to the entity under the
+// ParticleModuleEntityDataName name, the emitter is not playing yet
+func NewParticleModule(e *engine.Entity, host *engine.Host, data particles.EmitterData) (*ParticleModule, error) {
+	mat, err := host.MaterialCache().Material(assets.MaterialDefinitionParticle)
+	if err != nil {
+		return nil, err
+	}
+	textureKey := data.Texture
+	if textureKey == "" {
+		textureKey = assets.TextureSquare
+	}
+	tex, err := host.TextureCache().Texture(textureKey, rendering.TextureFilterLinear)
+	if err != nil {
+		return nil, err
+	}
+	pm := &ParticleModule{
+		entity:  e,
+		host:    host,
+		emitter: particles.NewEmitter(data, &e.Transform),
+	}
+	if data.Shape == particles.ShapeMesh {
+		if err := pm.loadShapeMesh(data.ShapeMesh); err != nil {
+			slog.Error("failed to load the particle emitter's shape mesh",
+				"mesh", data.ShapeMesh, "error", err)
+		}
+	}
+	mesh := rendering.NewMeshQuad(host.MeshCache())
+	host.Drawings.AddDrawings(pm.emitter.Drawings(host.Window.Renderer,
+		mat.CreateInstance([]*rendering.Texture{tex}), mesh))
+	e.AddNamedData(ParticleModuleEntityDataName, pm)
+	pm.updateId = host.Updater.AddUpdate(pm.update)
+	e.OnDestroy.Add(func() {
+		host.Updater.RemoveUpdate(pm.updateId)
+		pm.emitter.Destroy()
+	})
+	return pm, nil
+}
+
+// Emitter returns the emitter that is simulated by this module
+func (pm *ParticleModule) Emitter() *particles.Emitter { return pm.emitter }
+
+func (pm *ParticleModule) update(deltaTime float64) {
+	if !pm.entity.IsActive() {
+		pm.emitter.Clear()
+		return
+	}
+	pm.emitter.Update(deltaTime)
+}
+
+// loadShapeMesh reads the triangles of every mesh in the file into the
+// emitter's mesh shape
+func (pm *ParticleModule) loadShapeMesh(key string) error {
+	if key == "" {
+		return errors.New("the emitter has the mesh shape but no mesh")
+	}
+	db := pm.host.AssetDatabase()
+	var res load_result.Result
+	switch strings.ToLower(filepath.Ext(key)) {
+	case ".obj":
+		str, err := db.ReadText(key)
+		if err != nil {
+			return err
+		}
+		res = loaders.OBJ(str)
+	default:
+		var err error
+		if res, err = loaders.GLTF(key, db); err != nil {
+			return err
+		}
+	}
+	var verts []rendering.Vertex
+	var indices []uint32
+	for i := range res.Meshes {
+		offset := uint32(len(verts))
+		verts = append(verts, res.Meshes[i].Verts...)
+		for _, idx := range res.Meshes[i].Indexes {
+			indices = append(indices, idx+offset)
+		}
+	}
+	pm.emitter.SetMeshSurface(verts, indices)
+	return nil
+}
@@ -0,0 +1,9 @@
+//go:build !editor
+
+package particle_module
+
+import "kaiju/engine"
+
+func init() {
+	engine.RegisterEntityData(&ParticleEmitterModuleBinding{})
+}
@@ -0,0 +1,276 @@
+package terrain_module
+
+import (
+	"errors"
+	"fmt"
+	"kaiju/engine"
+	"kaiju/engine/assets"
+	"kaiju/engine/collision"
+	"kaiju/engine/collision_system"
+	"kaiju/engine/systems/terrain"
+	"kaiju/matrix"
+	"kaiju/rendering"
+	"log/slog"
+)
+
+const TerrainModuleEntityDataName = "Terrain"
+
+type TerrainModuleBinding struct {
+	Heightmap   string // the 16-bit .png or .r16 heightmap
+	Splat       string // the RGBA weights of layers 0 to 3
+	Splat2      string // the RGBA weights of layers 4 to 7, leave empty for 4 layers
+	Layer0      string
+	Layer1      string
+	Layer2      string
+	Layer3      string
+	Layer4      string
+	Layer5      string
+	Layer6      string
+	Layer7      string
+	Tiling      matrix.Vec4 `default:"64,64,64,64"`
+	Size        float32     `default:"1024"`
+	Height      float32     `default:"200"`
+	ChunkQuads  int         `default:"64"`
+	LODLevels   int         `default:"5"`
+	LODDistance float32     `default:"128"`
+	CastShadows bool        `default:"true"`
+}
+
+// TerrainModule draws a heightmap terrain at the entity's transform, picking
+// the level of detail of each chunk from its distance to the host's camera.
+// The terrain is also registered as a height field collision shape.
+type TerrainModule struct {
+	entity      *engine.Entity
+	host        *engine.Host
+	terrain     *terrain.Terrain
+	material    *rendering.Material
+	textures    []*rendering.Texture
+	tiling      matrix.Vec4
+	castShadows bool
+	// chunks holds the drawing of each level of detail of each chunk, they
+	// are created the first time the chunk needs that level of detail
+	chunks   [][]*terrain.ShaderData
+	shown    bool
+	shape    *collision_system.CollisionShape
+	updateId int
+}
+
+func (b *TerrainModuleBinding) Init(e *engine.Entity, host *engine.Host) {
+	if b.Heightmap == "" {
+		slog.Error("the terrain has no heightmap")
+		return
+	}
+	hm, err := terrain.LoadHeightmap(host.AssetDatabase(), b.Heightmap)
+	if err != nil {
+		slog.Error("failed to load the terrain heightmap", "heightmap", b.Heightmap, "error", err)
+		return
+	}
+	t, err := terrain.New(hm, terrain.Settings{
+		Size:        matrix.Float(b.Size),
+		Height:      matrix.Float(b.Height),
+		ChunkQuads:  b.ChunkQuads,
+		LODLevels:   b.LODLevels,
+		LODDistance: matrix.Float(b.LODDistance),
+	})
+	if err != nil {
+		slog.Error("failed to create the terrain", "heightmap", b.Heightmap, "error", err)
+		return
+	}
+	splats := []string{b.Splat}
+	layers := []string{b.Layer0, b.Layer1, b.Layer2, b.Layer3}
+	if b.Splat2 != "" {
+		splats = append(splats, b.Splat2)
+		layers = append(layers, b.Layer4, b.Layer5, b.Layer6, b.Layer7)
+	}
+	tm, err := NewTerrainModule(e, host, t, splats, layers, b.Tiling)
+	if err != nil {
+		slog.Error("failed to create the terrain", "heightmap", b.Heightmap, "error", err)
+		return
+	}
+	tm.castShadows = b.CastShadows
+}
+
+// NewTerrainModule draws the terrain for the entity and attaches it to the
+// entity under the TerrainModuleEntityDataName name. There must be either 1
+// splat map with 4 layers or 2 splat maps with 8 layers, an empty texture key
+// will use the default square texture.
+func NewTerrainModule(e *engine.Entity, host *engine.Host, t *terrain.Terrain,
+	splats, layers []string, tiling matrix.Vec4) (*TerrainModule, error) {
+	if len(splats) < 1 || len(splats) > 2 || len(layers) != len(splats)*4 {
+		return nil, errors.New("the terrain needs 4 layers for each splat map, with up to 2 splat maps")
+	}
+	materialKey := assets.MaterialDefinitionTerrain
+	if len(splats) == 2 {
+		materialKey = assets.MaterialDefinitionTerrain8
+	}
+	mat, err := host.MaterialCache().Material(materialKey)
+	if err != nil {
+		return nil, err
+	}
+	tm := &TerrainModule{
+		entity:      e,
+		host:        host,
+		terrain:     t,
+		tiling:      tiling,
+		castShadows: true,
+		chunks:      make([][]*terrain.ShaderData, len(t.Chunks())),
+	}
+	for _, key := range append(splats, layers...) {
+		if key == "" {
+			key = assets.TextureSquare
+		}
+		tex, err := host.TextureCache().Texture(key, rendering.TextureFilterLinear)
+		if err != nil {
+			return nil, err
+		}
+		tm.textures = append(tm.textures, tex)
+	}
+	tm.material = mat.CreateInstance(tm.textures)
+	for i := range tm.chunks {
+		tm.chunks[i] = make([]*terrain.ShaderData, t.Settings().LODLevels)
+	}
+	man := host.CollisionManager()
+	tm.shape = collision_system.RegisterCollisionShape(man, &e.Transform,
+		collision_system.ShapeHeightField, t.HeightField())
+	e.AddNamedData(TerrainModuleEntityDataName, tm)
+	tm.updateId = host.Updater.AddUpdate(tm.update)
+	e.OnActivate.Add(func() { tm.setChunksActive(true) })
+	e.OnDeactivate.Add(func() { tm.setChunksActive(false) })
+	e.OnDestroy.Add(func() {
+		host.Updater.RemoveUpdate(tm.updateId)
+		man.Remove(tm.shape)
+		for _, lods := range tm.chunks {
+			for _, sd := range lods {
+				if sd != nil {
+					sd.Destroy()
+				}
+			}
+		}
+	})
+	return tm, nil
+}
+
+// Terrain returns the terrain that is drawn by this module
+func (tm *TerrainModule) Terrain() *terrain.Terrain { return tm.terrain }
+
+// HeightAt returns the world height of the terrain below or above the world
+// point, false is returned if the point is outside of the terrain
+func (tm *TerrainModule) HeightAt(point matrix.Vec3) (matrix.Float, bool) {
+	hit, ok := tm.surfaceAt(point)
+	return hit.Y(), ok
+}
+
+// NormalAt returns the world normal of the terrain below or above the world
+// point, false is returned if the point is outside of the terrain
+func (tm *TerrainModule) NormalAt(point matrix.Vec3) (matrix.Vec3, bool) {
+	world := tm.entity.Transform.WorldMatrix()
+	inv := world
+	inv.Inverse()
+	local := inv.TransformPoint(point)
+	n, ok := tm.terrain.NormalAt(local.X(), local.Z())
+	if !ok {
+		return matrix.Vec3Up(), false
+	}
+	// Normals are transformed by the inverse transpose to survive non uniform
+	// scaling of the terrain
+	wn := matrix.Vec4MultiplyMat4(matrix.Vec4{n.X(), n.Y(), n.Z(), 0}, inv)
+	return matrix.Vec3{wn.X(), wn.Y(), wn.Z()}.Normal(), true
+}
+
+// RayHit returns the first world point where the world ray hits the terrain
+// within the given length
+func (tm *TerrainModule) RayHit(ray collision.Ray, length matrix.Float) (matrix.Vec3, bool) {
+	world := tm.entity.Transform.WorldMatrix()
+	inv := world
+	inv.Inverse()
+	end := inv.TransformPoint(ray.Origin.Add(ray.Direction.Normal().Scale(length)))
+	local := collision.Ray{Origin: inv.TransformPoint(ray.Origin)}
+	delta := end.Subtract(local.Origin)
+	local.Direction = delta.Normal()
+	hit, ok := tm.terrain.RayHit(local, delta.Length())
+	if !ok {
+		return matrix.Vec3Zero(), false
+	}
+	return world.TransformPoint(hit), true
+}
+
+// surfaceAt returns the world point on the terrain that is straight below or
+// above the world point along the terrain's up axis
+func (tm *TerrainModule) surfaceAt(point matrix.Vec3) (matrix.Vec3, bool) {
+	world := tm.entity.Transform.WorldMatrix()
+	inv := world
+	inv.Inverse()
+	local := inv.TransformPoint(point)
+	h, ok := tm.terrain.HeightAt(local.X(), local.Z())
+	if !ok {
+		return point, false
+	}
+	local.SetY(h)
+	return world.TransformPoint(local), true
+}
+
+func (tm *TerrainModule) update(deltaTime float64) {
+	if !tm.entity.IsActive() {
+		return
+	}
+	inv := tm.entity.Transform.WorldMatrix()
+	inv.Inverse()
+	camera := inv.TransformPoint(tm.host.Camera.Position())
+	changed := tm.terrain.SelectLODs(camera)
+	// The chunks are drawn on the first update so that their level of detail
+	// is already picked for the camera
+	if !tm.shown {
+		tm.shown = true
+		for i := range tm.chunks {
+			tm.showChunk(i)
+		}
+		return
+	}
+	for _, i := range changed {
+		for _, sd := range tm.chunks[i] {
+			if sd != nil {
+				sd.Deactivate()
+			}
+		}
+		tm.showChunk(i)
+	}
+}
+
+func (tm *TerrainModule) showChunk(index int) {
+	lod := tm.terrain.Chunks()[index].LOD
+	if sd := tm.chunks[index][lod]; sd != nil {
+		sd.Activate()
+		return
+	}
+	verts, indices := tm.terrain.ChunkMesh(index, lod)
+	key := fmt.Sprintf("terrain_%p_%d_%d", tm, index, lod)
+	mesh := tm.host.MeshCache().Mesh(key, verts, indices)
+	sd := &terrain.ShaderData{
+		ShaderDataBase: rendering.NewShaderDataBase(),
+		Color:          matrix.ColorWhite(),
+		Tiling:         tm.tiling,
+		ReceiveShadows: 1,
+	}
+	tm.chunks[index][lod] = sd
+	tm.host.Drawings.AddDrawing(rendering.Drawing{
+		Renderer:    tm.host.Window.Renderer,
+		Material:    tm.material,
+		Mesh:        mesh,
+		ShaderData:  sd,
+		Transform:   &tm.entity.Transform,
+		CastShadows: tm.castShadows,
+	})
+}
+
+func (tm *TerrainModule) setChunksActive(active bool) {
+	chunks := tm.terrain.Chunks()
+	for i, lods := range tm.chunks {
+		if sd := lods[chunks[i].LOD]; sd == nil {
+			continue
+		} else if active {
+			sd.Activate()
+		} else {
+			sd.Deactivate()
+		}
+	}
+}
@@ -0,0 +1,9 @@
+//go:build !editor
+
+package terrain_module
+
+import "kaiju/engine"
+
+func init() {
+	engine.RegisterEntityData(&TerrainModuleBinding{})
+}
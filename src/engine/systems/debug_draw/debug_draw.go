@@ -0,0 +1,362 @@
+//go:build !shipping
+
+/******************************************************************************/
+/* debug_draw.go                                                              */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package debug
+
+import (
+	"kaiju/engine"
+	"kaiju/engine/assets"
+	"kaiju/engine/cameras"
+	"kaiju/engine/collision"
+	"kaiju/matrix"
+	"kaiju/platform/profiler/tracing"
+	"kaiju/rendering"
+	"log/slog"
+	"slices"
+	"sync"
+)
+
+const (
+	layerOverlay = iota
+	layerDepthTested
+	layerCount
+)
+
+var layerMaterials = [layerCount]string{
+	layerOverlay:     assets.MaterialDefinitionGridOverlay,
+	layerDepthTested: assets.MaterialDefinitionGrid,
+}
+
+type timedLine struct {
+	line
+	until float64
+}
+
+type timedText struct {
+	create   func() []rendering.Drawing
+	drawings []rendering.Drawing
+	until    float64
+	shown    bool
+}
+
+// lineBatch is the single line mesh that all of the lines of a layer are
+// put into, the mesh is only updated when lines are added or expire
+type lineBatch struct {
+	mesh       *rendering.Mesh
+	shaderData *rendering.ShaderDataBasic
+	segments   []line
+	dirty      bool
+	failed     bool
+}
+
+type drawer struct {
+	host    *engine.Host
+	lines   [layerCount][]timedLine
+	texts   []*timedText
+	batches [layerCount]lineBatch
+	mutex   sync.Mutex
+}
+
+var (
+	drawers      = map[*engine.Host]*drawer{}
+	drawersMutex sync.Mutex
+)
+
+func hostDrawer(host *engine.Host) *drawer {
+	drawersMutex.Lock()
+	defer drawersMutex.Unlock()
+	d, ok := drawers[host]
+	if !ok {
+		d = &drawer{host: host}
+		drawers[host] = d
+		host.LateUpdater.AddUpdate(d.update)
+		host.OnClose.Add(func() {
+			drawersMutex.Lock()
+			defer drawersMutex.Unlock()
+			d.destroy()
+			delete(drawers, host)
+		})
+	}
+	return d
+}
+
+func layer(opts Options) int {
+	if opts.DepthTest {
+		return layerDepthTested
+	}
+	return layerOverlay
+}
+
+func (d *drawer) addLines(lines []line, opts Options) {
+	until := d.host.Runtime() + opts.Duration.Seconds()
+	l := layer(opts)
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	for i := range lines {
+		d.lines[l] = append(d.lines[l], timedLine{lines[i], until})
+	}
+	d.batches[l].dirty = len(lines) > 0 || d.batches[l].dirty
+}
+
+func (d *drawer) addText(opts Options, create func() []rendering.Drawing) {
+	until := d.host.Runtime() + opts.Duration.Seconds()
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	d.texts = append(d.texts, &timedText{create: create, until: until})
+}
+
+// update rebuilds the line meshes that have changed and then drops the
+// lines that have expired, lines that only last a single frame are dropped
+// right after they were put into the mesh
+func (d *drawer) update(float64) {
+	defer tracing.NewRegion("debug.drawer.update").End()
+	now := d.host.Runtime()
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	for i := range d.lines {
+		d.batches[i].build(d.host, d.lines[i], layerMaterials[i])
+		count := len(d.lines[i])
+		d.lines[i] = slices.DeleteFunc(d.lines[i], func(l timedLine) bool {
+			return l.until <= now
+		})
+		d.batches[i].dirty = len(d.lines[i]) != count || d.batches[i].dirty
+	}
+	// Text is kept until it has been drawn for at least one frame
+	d.texts = slices.DeleteFunc(d.texts, func(t *timedText) bool {
+		if !t.shown || t.until > now {
+			return false
+		}
+		destroyDrawings(t.drawings)
+		return true
+	})
+	for _, t := range d.texts {
+		if !t.shown {
+			t.drawings = t.create()
+			d.host.Drawings.AddDrawings(t.drawings)
+			t.shown = true
+		}
+	}
+}
+
+func (d *drawer) clear() {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	for i := range d.lines {
+		d.lines[i] = d.lines[i][:0]
+		d.batches[i].dirty = true
+	}
+	for _, t := range d.texts {
+		destroyDrawings(t.drawings)
+	}
+	d.texts = d.texts[:0]
+}
+
+func (d *drawer) destroy() {
+	d.clear()
+	for i := range d.batches {
+		d.batches[i].destroy(d.host)
+	}
+}
+
+func destroyDrawings(drawings []rendering.Drawing) {
+	for i := range drawings {
+		drawings[i].ShaderData.Destroy()
+	}
+}
+
+func (b *lineBatch) build(host *engine.Host, lines []timedLine, materialKey string) {
+	renderer := host.Window.Renderer
+	if renderer == nil || b.failed || !b.dirty {
+		return
+	}
+	b.dirty = false
+	if len(lines) == 0 {
+		if b.shaderData != nil {
+			b.shaderData.Deactivate()
+		}
+		return
+	}
+	if b.shaderData == nil {
+		material, err := host.MaterialCache().Material(materialKey)
+		if err != nil {
+			slog.Error("failed to load the material for debug drawing",
+				"material", materialKey, "error", err)
+			b.failed = true
+			return
+		}
+		b.mesh = rendering.NewMesh("debug_draw_"+materialKey, nil, nil)
+		b.shaderData = &rendering.ShaderDataBasic{
+			ShaderDataBase: rendering.NewShaderDataBase(),
+			Color:          matrix.ColorWhite(),
+		}
+		host.Drawings.AddDrawing(rendering.Drawing{
+			Renderer:   renderer,
+			Material:   material,
+			Mesh:       b.mesh,
+			ShaderData: b.shaderData,
+		})
+	}
+	b.segments = b.segments[:0]
+	for i := range lines {
+		b.segments = append(b.segments, lines[i].line)
+	}
+	verts, indexes := lineMeshData(b.segments)
+	// The draw instance group of the drawing is found through the mesh, so
+	// the same mesh is kept and its buffers are updated in place
+	renderer.UpdateMesh(b.mesh, verts, indexes)
+	b.shaderData.Activate()
+}
+
+func (b *lineBatch) destroy(host *engine.Host) {
+	if b.shaderData != nil {
+		b.shaderData.Destroy()
+	}
+	if b.mesh != nil && b.mesh.IsReady() && host.Window.Renderer != nil {
+		b.mesh.Destroy(host.Window.Renderer)
+	}
+}
+
+// billboard is the model matrix that places the XY plane at the position
+// facing the camera
+func billboard(camera cameras.Camera, position matrix.Vec3) matrix.Mat4 {
+	right, up, back := camera.Right(), camera.Up(), camera.Forward().Negative()
+	return matrix.Mat4{
+		right.X(), right.Y(), right.Z(), 0,
+		up.X(), up.Y(), up.Z(), 0,
+		back.X(), back.Y(), back.Z(), 0,
+		position.X(), position.Y(), position.Z(), 1,
+	}
+}
+
+// Line draws a line between the two points
+func Line(host *engine.Host, from, to matrix.Vec3, opts Options) {
+	hostDrawer(host).addLines(lineShape(from, to, opts.Color), opts)
+}
+
+// Arrow draws a line between the two points with an arrow head at the end
+func Arrow(host *engine.Host, from, to matrix.Vec3, opts Options) {
+	hostDrawer(host).addLines(arrowShape(from, to, opts.Color), opts)
+}
+
+// AABB draws the edges of the axis aligned box
+func AABB(host *engine.Host, box collision.AABB, opts Options) {
+	hostDrawer(host).addLines(aabbShape(box, opts.Color), opts)
+}
+
+// OOBB draws the edges of the oriented box
+func OOBB(host *engine.Host, box collision.OOBB, opts Options) {
+	hostDrawer(host).addLines(oobbShape(box, opts.Color), opts)
+}
+
+// Sphere draws the circles of the sphere around the X, Y, and Z axes
+func Sphere(host *engine.Host, center matrix.Vec3, radius matrix.Float, opts Options) {
+	hostDrawer(host).addLines(sphereShape(center, radius, opts.Color), opts)
+}
+
+// Capsule draws the capsule that has the centers of its end caps at a and b
+func Capsule(host *engine.Host, a, b matrix.Vec3, radius matrix.Float, opts Options) {
+	hostDrawer(host).addLines(capsuleShape(a, b, radius, opts.Color), opts)
+}
+
+// Frustum draws the edges of the volume that the camera can see, from its
+// near plane to its far plane
+func Frustum(host *engine.Host, camera cameras.Camera, opts Options) {
+	corners := frustumCorners(camera.View(), camera.Projection(), camera.IsOrthographic())
+	hostDrawer(host).addLines(boxShape(corners, opts.Color), opts)
+}
+
+// Grid draws a square grid on the XZ plane that is size wide and has the
+// given number of cells along each side
+func Grid(host *engine.Host, center matrix.Vec3, size matrix.Float, divisions int, opts Options) {
+	hostDrawer(host).addLines(gridShape(center, size, divisions, opts.Color), opts)
+}
+
+// Axes draws the X, Y, and Z axes of the rotation in red, green, and blue,
+// only the alpha of the color in the options is used
+func Axes(host *engine.Host, position matrix.Vec3, rotation matrix.Quaternion, size matrix.Float, opts Options) {
+	hostDrawer(host).addLines(axesShape(position, rotation, size, opts.Color), opts)
+}
+
+// Text draws the text centered on the world position facing the camera, the
+// size is the height of a line of text in world units
+func Text(host *engine.Host, text string, position matrix.Vec3, size matrix.Float, opts Options) {
+	hostDrawer(host).addText(opts, func() []rendering.Drawing {
+		drawings := host.FontCache().RenderMeshes(host, text, 0, 0, 0, size, 0,
+			opts.Color, matrix.ColorTransparent(), rendering.FontJustifyCenter,
+			rendering.FontBaselineCenter, matrix.Vec3One(), true, true,
+			rendering.FontRegular, 0)
+		var overlay *rendering.Material
+		if !opts.DepthTest {
+			var err error
+			if overlay, err = host.MaterialCache().Material(assets.MaterialDefinitionText3DOverlay); err != nil {
+				slog.Error("failed to load the overlay text material", "error", err)
+			}
+		}
+		model := billboard(host.Camera, position)
+		for i := range drawings {
+			sd := drawings[i].ShaderData.(*rendering.TextShaderData)
+			sd.SetModel(matrix.Mat4Multiply(sd.Model(), model))
+			if overlay != nil {
+				drawings[i].Material = overlay.CreateInstance(drawings[i].Material.Textures)
+			}
+		}
+		return drawings
+	})
+}
+
+// ScreenText draws the text with its top left corner at the position, in
+// pixels from the top left of the window. The size is the height of a line
+// of text in pixels. Screen text is always drawn over the scene.
+func ScreenText(host *engine.Host, text string, position matrix.Vec2, size matrix.Float, opts Options) {
+	hostDrawer(host).addText(opts, func() []rendering.Drawing {
+		w := matrix.Float(host.Window.Width())
+		h := matrix.Float(host.Window.Height())
+		return host.FontCache().RenderMeshes(host, text,
+			position.X()-w*0.5, h*0.5-position.Y(), 0, size, 0,
+			opts.Color, matrix.ColorTransparent(), rendering.FontJustifyLeft,
+			rendering.FontBaselineTop, matrix.Vec3One(), true, false,
+			rendering.FontRegular, 0)
+	})
+}
+
+// Clear removes all of the debug shapes and text, including the ones that
+// have time left to be drawn
+func Clear(host *engine.Host) {
+	hostDrawer(host).clear()
+}
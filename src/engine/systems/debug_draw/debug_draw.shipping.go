@@ -0,0 +1,62 @@
+//go:build shipping
+
+/******************************************************************************/
+/* debug_draw.shipping.go                                                     */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package debug
+
+import (
+	"kaiju/engine"
+	"kaiju/engine/cameras"
+	"kaiju/engine/collision"
+	"kaiju/matrix"
+)
+
+func Line(host *engine.Host, from, to matrix.Vec3, opts Options)                                 {}
+func Arrow(host *engine.Host, from, to matrix.Vec3, opts Options)                                {}
+func AABB(host *engine.Host, box collision.AABB, opts Options)                                   {}
+func OOBB(host *engine.Host, box collision.OOBB, opts Options)                                   {}
+func Sphere(host *engine.Host, center matrix.Vec3, radius matrix.Float, opts Options)            {}
+func Capsule(host *engine.Host, a, b matrix.Vec3, radius matrix.Float, opts Options)             {}
+func Frustum(host *engine.Host, camera cameras.Camera, opts Options)                             {}
+func Grid(host *engine.Host, center matrix.Vec3, size matrix.Float, divisions int, opts Options) {}
+func Axes(host *engine.Host, position matrix.Vec3, rotation matrix.Quaternion, size matrix.Float, opts Options) {
+}
+func Text(host *engine.Host, text string, position matrix.Vec3, size matrix.Float, opts Options) {}
+func ScreenText(host *engine.Host, text string, position matrix.Vec2, size matrix.Float, opts Options) {
+}
+func Clear(host *engine.Host) {}
@@ -39,37 +39,14 @@ package debug
 
 import (
 	"kaiju/engine"
-	"kaiju/engine/assets"
 	"kaiju/matrix"
-	"kaiju/rendering"
-	"log/slog"
 	"time"
-
-	"github.com/KaijuEngine/uuid"
 )
 
+// DrawRay draws a depth tested white line between the two points for the
+// duration
 func DrawRay(host *engine.Host, from, to matrix.Vec3, duration time.Duration) {
-	// TODO:  Return the handle to delete this thing
-	grid := rendering.NewMeshGrid(host.MeshCache(),
-		"raycast_"+uuid.New().String(),
-		[]matrix.Vec3{from, to}, matrix.ColorWhite())
-	material, err := host.MaterialCache().Material(assets.MaterialDefinitionGrid)
-	if err != nil {
-		slog.Error("failed to load the grid material for drawing raycast", "error", err)
-		return
-	}
-	sd := &rendering.ShaderDataBasic{
-		ShaderDataBase: rendering.NewShaderDataBase(),
-		Color:          matrix.Color{0.5, 0.5, 0.5, 1},
-	}
-	host.Drawings.AddDrawing(rendering.Drawing{
-		Renderer:   host.Window.Renderer,
-		Material:   material,
-		Mesh:       grid,
-		ShaderData: sd,
-	})
-	func() {
-		time.Sleep(duration)
-		sd.Destroy()
-	}()
+	opts := DefaultOptions()
+	opts.Duration = duration
+	Line(host, from, to, opts)
 }
@@ -0,0 +1,259 @@
+/******************************************************************************/
+/* shapes.go                                                                  */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package debug
+
+import (
+	"kaiju/engine/collision"
+	"kaiju/matrix"
+	"kaiju/rendering"
+	"math"
+	"time"
+)
+
+// circleSegments is the number of lines that make up a full circle
+const circleSegments = 32
+
+// Options are how a debug shape is drawn
+type Options struct {
+	// Color is the color of the lines or text
+	Color matrix.Color
+	// Duration is how long the shape is drawn for, a duration of 0 will only
+	// draw the shape for the frame it was added in
+	Duration time.Duration
+	// DepthTest will hide the parts of the shape that are behind the scene,
+	// otherwise the shape is drawn over everything
+	DepthTest bool
+}
+
+// DefaultOptions draws white, depth tested, shapes for a single frame
+func DefaultOptions() Options {
+	return Options{
+		Color:     matrix.ColorWhite(),
+		DepthTest: true,
+	}
+}
+
+type line struct {
+	from, to matrix.Vec3
+	color    matrix.Color
+}
+
+func lineShape(from, to matrix.Vec3, color matrix.Color) []line {
+	return []line{{from, to, color}}
+}
+
+// basis returns two unit vectors that are perpendicular to the direction and
+// to each other
+func basis(dir matrix.Vec3) (matrix.Vec3, matrix.Vec3) {
+	ref := matrix.Vec3Up()
+	if matrix.Abs(matrix.Vec3Dot(dir, ref)) > 0.9 {
+		ref = matrix.Vec3Right()
+	}
+	u := matrix.Vec3Cross(dir, ref).Normal()
+	v := matrix.Vec3Cross(dir, u).Normal()
+	return u, v
+}
+
+func arrowShape(from, to matrix.Vec3, color matrix.Color) []line {
+	lines := lineShape(from, to, color)
+	dir := to.Subtract(from)
+	length := dir.Length()
+	if matrix.Approx(length, 0) {
+		return lines
+	}
+	dir = dir.Scale(1 / length)
+	head := length * 0.2
+	u, v := basis(dir)
+	back := to.Subtract(dir.Scale(head))
+	for _, side := range []matrix.Vec3{u, u.Negative(), v, v.Negative()} {
+		lines = append(lines, line{to, back.Add(side.Scale(head * 0.5)), color})
+	}
+	return lines
+}
+
+// boxShape connects the corners of a box, the bits of the corner index are
+// which side of the box the corner is on for each axis
+func boxShape(corners [8]matrix.Vec3, color matrix.Color) []line {
+	lines := make([]line, 0, 12)
+	for i := range 8 {
+		for axis := 1; axis < 8; axis <<= 1 {
+			if i&axis == 0 {
+				lines = append(lines, line{corners[i], corners[i|axis], color})
+			}
+		}
+	}
+	return lines
+}
+
+func boxCorners(center matrix.Vec3, extent matrix.Vec3, orientation matrix.Mat3) [8]matrix.Vec3 {
+	var corners [8]matrix.Vec3
+	for i := range corners {
+		local := extent
+		for axis := range 3 {
+			if i&(1<<axis) == 0 {
+				local[axis] = -local[axis]
+			}
+		}
+		corners[i] = center.Add(orientation.MultiplyVec3(local))
+	}
+	return corners
+}
+
+func aabbShape(box collision.AABB, color matrix.Color) []line {
+	return boxShape(boxCorners(box.Center, box.Extent, matrix.Mat3Identity()), color)
+}
+
+func oobbShape(box collision.OOBB, color matrix.Color) []line {
+	return boxShape(boxCorners(box.Center, box.Extent, box.Orientation), color)
+}
+
+// arcShape draws the part of the circle on the plane of the x and y axes
+// between the two angles (in radians)
+func arcShape(center, x, y matrix.Vec3, radius, from, to matrix.Float, color matrix.Color) []line {
+	segments := max(1, int(math.Ceil(float64(circleSegments*matrix.Abs(to-from)/(2*math.Pi)))))
+	lines := make([]line, 0, segments)
+	point := func(angle matrix.Float) matrix.Vec3 {
+		return center.Add(x.Scale(matrix.Cos(angle) * radius)).Add(y.Scale(matrix.Sin(angle) * radius))
+	}
+	last := point(from)
+	for i := 1; i <= segments; i++ {
+		next := point(from + (to-from)*matrix.Float(i)/matrix.Float(segments))
+		lines = append(lines, line{last, next, color})
+		last = next
+	}
+	return lines
+}
+
+func sphereShape(center matrix.Vec3, radius matrix.Float, color matrix.Color) []line {
+	x, y, z := matrix.Vec3Right(), matrix.Vec3Up(), matrix.Vec3Backward()
+	lines := arcShape(center, x, y, radius, 0, 2*math.Pi, color)
+	lines = append(lines, arcShape(center, x, z, radius, 0, 2*math.Pi, color)...)
+	return append(lines, arcShape(center, y, z, radius, 0, 2*math.Pi, color)...)
+}
+
+// capsuleShape is the rings around the two ends, the lines joining them, and
+// the half circles of the caps
+func capsuleShape(a, b matrix.Vec3, radius matrix.Float, color matrix.Color) []line {
+	axis := b.Subtract(a)
+	if matrix.Approx(axis.Length(), 0) {
+		return sphereShape(a, radius, color)
+	}
+	up := axis.Normal()
+	u, v := basis(up)
+	lines := arcShape(a, u, v, radius, 0, 2*math.Pi, color)
+	lines = append(lines, arcShape(b, u, v, radius, 0, 2*math.Pi, color)...)
+	for _, side := range []matrix.Vec3{u, u.Negative(), v, v.Negative()} {
+		offset := side.Scale(radius)
+		lines = append(lines, line{a.Add(offset), b.Add(offset), color})
+	}
+	for _, side := range []matrix.Vec3{u, v} {
+		lines = append(lines, arcShape(b, side, up, radius, 0, math.Pi, color)...)
+		lines = append(lines, arcShape(a, side, up.Negative(), radius, 0, math.Pi, color)...)
+	}
+	return lines
+}
+
+// frustumCorners returns the world space corners of the view of the camera
+// in the same order as boxShape expects, the near plane corners come first
+func frustumCorners(view, projection matrix.Mat4, orthographic bool) [8]matrix.Vec3 {
+	inv := matrix.Mat4Multiply(view, projection)
+	inv.Inverse()
+	nearZ := matrix.Float(-1)
+	if orthographic {
+		nearZ = 0
+	}
+	var corners [8]matrix.Vec3
+	for i := range corners {
+		x := matrix.Float((i&1)*2 - 1)
+		y := matrix.Float((i>>1&1)*2 - 1)
+		z := nearZ
+		if i&4 != 0 {
+			z = 1
+		}
+		corners[i] = inv.TransformPoint(matrix.Vec3{x, y, z})
+	}
+	return corners
+}
+
+// gridShape is a square grid on the XZ plane with the given number of cells
+// along each side
+func gridShape(center matrix.Vec3, size matrix.Float, divisions int, color matrix.Color) []line {
+	divisions = max(1, divisions)
+	half := size * 0.5
+	lines := make([]line, 0, (divisions+1)*2)
+	for i := 0; i <= divisions; i++ {
+		t := -half + size*matrix.Float(i)/matrix.Float(divisions)
+		lines = append(lines,
+			line{center.Add(matrix.Vec3{t, 0, -half}), center.Add(matrix.Vec3{t, 0, half}), color},
+			line{center.Add(matrix.Vec3{-half, 0, t}), center.Add(matrix.Vec3{half, 0, t}), color})
+	}
+	return lines
+}
+
+// axesShape draws the X, Y, and Z axes of the rotation in red, green, and
+// blue, the alpha of the color is used for all of the axes
+func axesShape(position matrix.Vec3, rotation matrix.Quaternion, size matrix.Float, color matrix.Color) []line {
+	axes := [3]matrix.Vec3{matrix.Vec3Right(), matrix.Vec3Up(), matrix.Vec3Backward()}
+	colors := [3]matrix.Color{matrix.ColorRed(), matrix.ColorGreen(), matrix.ColorBlue()}
+	lines := make([]line, 0, len(axes))
+	for i := range axes {
+		colors[i].SetA(color.A())
+		to := position.Add(rotation.MultiplyVec3(axes[i]).Scale(size))
+		lines = append(lines, line{position, to, colors[i]})
+	}
+	return lines
+}
+
+// lineMeshData returns the vertices and indexes of the lines for a line list
+// mesh, the color of each line is stored in the vertex colors
+func lineMeshData(lines []line) ([]rendering.Vertex, []uint32) {
+	verts := make([]rendering.Vertex, 0, len(lines)*2)
+	indexes := make([]uint32, 0, len(lines)*2)
+	for i := range lines {
+		for _, p := range [2]matrix.Vec3{lines[i].from, lines[i].to} {
+			indexes = append(indexes, uint32(len(verts)))
+			verts = append(verts, rendering.Vertex{
+				Position: p,
+				Normal:   matrix.Vec3Backward(),
+				UV0:      matrix.Vec2{0, 1},
+				Color:    lines[i].color,
+			})
+		}
+	}
+	return verts, indexes
+}
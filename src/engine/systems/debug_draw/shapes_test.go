@@ -0,0 +1,171 @@
+/******************************************************************************/
+/* shapes_test.go                                                             */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package debug
+
+import (
+	"kaiju/engine/collision"
+	"kaiju/matrix"
+	"testing"
+)
+
+func TestBoxShapeEdges(t *testing.T) {
+	box := collision.AABB{Center: matrix.Vec3{1, 2, 3}, Extent: matrix.Vec3{1, 2, 3}}
+	lines := aabbShape(box, matrix.ColorWhite())
+	if len(lines) != 12 {
+		t.Fatalf("expected 12 edges, got %d", len(lines))
+	}
+	lengths := map[matrix.Float]int{}
+	for _, l := range lines {
+		lengths[l.to.Subtract(l.from).Length()]++
+		for _, p := range []matrix.Vec3{l.from, l.to} {
+			if !box.Contains(p) {
+				t.Errorf("expected the corner %v to be on the box", p)
+			}
+		}
+	}
+	if lengths[2] != 4 || lengths[4] != 4 || lengths[6] != 4 {
+		t.Errorf("expected 4 edges along each axis, got %v", lengths)
+	}
+}
+
+func TestOOBBShapeIsRotated(t *testing.T) {
+	box := collision.OOBB{
+		Extent:      matrix.Vec3{1, 1, 1},
+		Orientation: matrix.Mat3FromMat4(matrix.QuaternionFromEuler(matrix.Vec3{0, 45, 0}).ToMat4()),
+	}
+	for _, l := range oobbShape(box, matrix.ColorWhite()) {
+		if !box.ContainsPoint(l.from.Scale(0.999)) {
+			t.Errorf("expected the corner %v to be on the oriented box", l.from)
+		}
+	}
+}
+
+func TestArrowShape(t *testing.T) {
+	lines := arrowShape(matrix.Vec3Zero(), matrix.Vec3{0, 0, 10}, matrix.ColorWhite())
+	if len(lines) != 5 {
+		t.Fatalf("expected the line and 4 head lines, got %d", len(lines))
+	}
+	for _, l := range lines[1:] {
+		if l.from != (matrix.Vec3{0, 0, 10}) || !matrix.ApproxTo(l.to.Z(), 8, 0.0001) {
+			t.Errorf("expected the head to go back from the tip, got %v", l)
+		}
+	}
+	if len(arrowShape(matrix.Vec3One(), matrix.Vec3One(), matrix.ColorWhite())) != 1 {
+		t.Error("expected an arrow without length to not have a head")
+	}
+}
+
+func TestSphereShapeRadius(t *testing.T) {
+	center := matrix.Vec3{5, 0, 0}
+	lines := sphereShape(center, 2, matrix.ColorWhite())
+	if len(lines) != circleSegments*3 {
+		t.Fatalf("expected 3 circles, got %d lines", len(lines))
+	}
+	for _, l := range lines {
+		if !matrix.ApproxTo(l.from.Subtract(center).Length(), 2, 0.0001) {
+			t.Errorf("expected %v to be on the sphere", l.from)
+		}
+	}
+}
+
+func TestCapsuleShape(t *testing.T) {
+	a, b := matrix.Vec3Zero(), matrix.Vec3{0, 4, 0}
+	for _, l := range capsuleShape(a, b, 1, matrix.ColorWhite()) {
+		for _, p := range []matrix.Vec3{l.from, l.to} {
+			// The distance to the segment between a and b is the radius
+			y := matrix.Clamp(p.Y(), 0, 4)
+			if !matrix.ApproxTo(p.Subtract(matrix.Vec3{0, y, 0}).Length(), 1, 0.0001) {
+				t.Errorf("expected %v to be on the capsule", p)
+			}
+		}
+	}
+	if len(capsuleShape(a, a, 1, matrix.ColorWhite())) != circleSegments*3 {
+		t.Error("expected a capsule without length to be a sphere")
+	}
+}
+
+func TestGridShape(t *testing.T) {
+	lines := gridShape(matrix.Vec3Zero(), 10, 5, matrix.ColorWhite())
+	if len(lines) != 12 {
+		t.Fatalf("expected 6 lines along each axis, got %d", len(lines))
+	}
+	for _, l := range lines {
+		if l.from.Y() != 0 || l.to.Subtract(l.from).Length() != 10 {
+			t.Errorf("expected a line across the grid, got %v", l)
+		}
+	}
+}
+
+func TestAxesShapeColors(t *testing.T) {
+	lines := axesShape(matrix.Vec3Zero(), matrix.QuaternionIdentity(), 2, matrix.Color{1, 1, 1, 0.5})
+	expected := []matrix.Vec3{{2, 0, 0}, {0, 2, 0}, {0, 0, 2}}
+	for i, l := range lines {
+		if !matrix.Vec3Approx(l.to, expected[i]) || l.color.A() != 0.5 || l.color[i] != 1 {
+			t.Errorf("unexpected axis %d: %v", i, l)
+		}
+	}
+}
+
+func TestFrustumCorners(t *testing.T) {
+	var projection matrix.Mat4
+	projection.Orthographic(-1, 1, -2, 2, 0, 10)
+	corners := frustumCorners(matrix.Mat4Identity(), projection, true)
+	for i, c := range corners {
+		if matrix.Abs(c.X()) != 1 || !matrix.ApproxTo(matrix.Abs(c.Y()), 2, 0.0001) {
+			t.Errorf("unexpected corner %d: %v", i, c)
+		}
+		near := i&4 == 0
+		if near != matrix.ApproxTo(c.Z(), 0, 0.0001) {
+			t.Errorf("expected corner %d near %v, got %v", i, near, c)
+		}
+	}
+}
+
+func TestLineMeshData(t *testing.T) {
+	lines := []line{
+		{matrix.Vec3Zero(), matrix.Vec3One(), matrix.ColorRed()},
+		{matrix.Vec3One(), matrix.Vec3Zero(), matrix.ColorBlue()},
+	}
+	verts, indexes := lineMeshData(lines)
+	if len(verts) != 4 || len(indexes) != 4 {
+		t.Fatalf("expected 2 vertices per line, got %d and %d", len(verts), len(indexes))
+	}
+	if verts[1].Position != matrix.Vec3One() || verts[3].Color != matrix.ColorBlue() || indexes[3] != 3 {
+		t.Errorf("unexpected vertices %v", verts)
+	}
+}
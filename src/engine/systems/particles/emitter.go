@@ -0,0 +1,444 @@
+/******************************************************************************/
+/* emitter.go                                                                 */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package particles
+
+import (
+	"kaiju/matrix"
+	"kaiju/rendering"
+	"math"
+	"math/rand/v2"
+)
+
+type particle struct {
+	position        matrix.Vec3
+	velocity        matrix.Vec3
+	color           matrix.Color
+	age             float32
+	lifetime        float32
+	size            float32
+	rotation        float32
+	angularVelocity float32
+	alive           bool
+}
+
+// Emitter simulates the particles of an EmitterData on the CPU, every
+// particle slot has its own instance data so the particles are drawn with a
+// single instanced draw of a quad mesh. The simulation is deliberately kept
+// on the CPU as the renderer has no compute pipelines to step the particles
+// on the GPU, only the drawing is done on the GPU.
+type Emitter struct {
+	Data       EmitterData
+	transform  *matrix.Transform
+	particles  []particle
+	instances  []ShaderData
+	rng        *rand.Rand
+	surface    meshSurface
+	time       float32
+	spawnDebt  float32
+	burstCount []int32
+	next       int
+	alive      int
+	playing    bool
+}
+
+// NewEmitter creates a stopped emitter, the transform is where the
+// particles spawn from and can be nil to spawn at the origin
+func NewEmitter(data EmitterData, transform *matrix.Transform) *Emitter {
+	count := max(int(data.MaxParticles), 1)
+	seed := data.Seed
+	if seed == 0 {
+		seed = rand.Uint64()
+	}
+	e := &Emitter{
+		Data:       data,
+		transform:  transform,
+		particles:  make([]particle, count),
+		instances:  make([]ShaderData, count),
+		rng:        rand.New(rand.NewPCG(seed, seed^0x9E3779B97F4A7C15)),
+		burstCount: make([]int32, len(data.Bursts)),
+	}
+	for i := range e.instances {
+		e.instances[i] = ShaderData{
+			ShaderDataBase: rendering.NewShaderDataBase(),
+			Color:          matrix.ColorWhite(),
+			UVs:            matrix.Vec4{0, 0, 1, 1},
+		}
+		e.instances[i].Deactivate()
+	}
+	return e
+}
+
+// Drawings creates a drawing for every particle slot, the slots of dead
+// particles are deactivated so they are skipped while rendering
+func (e *Emitter) Drawings(renderer rendering.Renderer, material *rendering.Material, mesh *rendering.Mesh) []rendering.Drawing {
+	drawings := make([]rendering.Drawing, len(e.instances))
+	for i := range e.instances {
+		drawings[i] = rendering.Drawing{
+			Renderer:   renderer,
+			Material:   material,
+			Mesh:       mesh,
+			ShaderData: &e.instances[i],
+		}
+	}
+	return drawings
+}
+
+// Play restarts the cycle of the emitter, particles that are still alive
+// are kept
+func (e *Emitter) Play() {
+	e.playing = true
+	e.time = 0
+	e.spawnDebt = 0
+	clear(e.burstCount)
+}
+
+// Stop stops spawning particles, the particles that are alive finish
+// their lifetime
+func (e *Emitter) Stop() { e.playing = false }
+
+// Clear kills all of the particles that are alive
+func (e *Emitter) Clear() {
+	for i := range e.particles {
+		e.kill(i)
+	}
+}
+
+// Destroy marks the instance data of every particle slot as destroyed so
+// that the drawings are removed by the renderer
+func (e *Emitter) Destroy() {
+	e.playing = false
+	for i := range e.instances {
+		e.instances[i].Destroy()
+	}
+}
+
+func (e *Emitter) IsPlaying() bool { return e.playing }
+
+// AliveCount is the number of particles that are currently alive
+func (e *Emitter) AliveCount() int { return e.alive }
+
+// SetMeshSurface sets the triangles that are used by the mesh shape,
+// particles spawn on the surface and move along the triangle's normal
+func (e *Emitter) SetMeshSurface(verts []rendering.Vertex, indices []uint32) {
+	e.surface = newMeshSurface(verts, indices)
+}
+
+// Emit spawns count particles right away, particles are not spawned when
+// all of the slots are in use
+func (e *Emitter) Emit(count int) {
+	world := e.worldMatrix()
+	for range count {
+		slot := e.freeSlot()
+		if slot < 0 {
+			return
+		}
+		e.spawn(slot, world)
+	}
+}
+
+// Update spawns new particles and moves the living ones deltaTime seconds
+// forward, it is expected to be called every frame
+func (e *Emitter) Update(deltaTime float64) {
+	dt := float32(deltaTime)
+	if e.playing {
+		e.updateSpawning(dt)
+	}
+	world := e.worldMatrix()
+	d := &e.Data
+	for i := range e.particles {
+		p := &e.particles[i]
+		if !p.alive {
+			continue
+		}
+		p.age += dt
+		if p.age >= p.lifetime {
+			e.kill(i)
+			continue
+		}
+		t := p.age / p.lifetime
+		p.velocity.AddAssign(d.Gravity.Scale(matrix.Float(dt)))
+		if d.Drag > 0 {
+			p.velocity.ScaleAssign(matrix.Float(max(0, 1-d.Drag*dt)))
+		}
+		speed := d.SpeedOverLifetime.Evaluate(t)
+		p.position.AddAssign(p.velocity.Scale(matrix.Float(speed * dt)))
+		p.rotation += p.angularVelocity * dt
+		e.writeInstance(i, t, world)
+	}
+}
+
+func (e *Emitter) updateSpawning(dt float32) {
+	d := &e.Data
+	e.spawnDebt += d.SpawnRate * dt
+	if e.spawnDebt >= 1 {
+		n := int(e.spawnDebt)
+		e.spawnDebt -= float32(n)
+		e.Emit(n)
+	}
+	e.time += dt
+	for i := range d.Bursts {
+		b := &d.Bursts[i]
+		for e.burstCount[i] <= b.Cycles {
+			at := b.Time + float32(e.burstCount[i])*b.Interval
+			if at > e.time {
+				break
+			}
+			e.Emit(int(b.Count))
+			e.burstCount[i]++
+		}
+	}
+	if d.Duration > 0 && e.time >= d.Duration {
+		if d.Loop {
+			e.time -= d.Duration
+			clear(e.burstCount)
+		} else {
+			e.playing = false
+		}
+	}
+}
+
+func (e *Emitter) worldMatrix() matrix.Mat4 {
+	if e.transform == nil {
+		return matrix.Mat4Identity()
+	}
+	return e.transform.WorldMatrix()
+}
+
+func (e *Emitter) freeSlot() int {
+	for range len(e.particles) {
+		i := e.next
+		e.next = (e.next + 1) % len(e.particles)
+		if !e.particles[i].alive {
+			return i
+		}
+	}
+	return -1
+}
+
+func (e *Emitter) kill(index int) {
+	if e.particles[index].alive {
+		e.particles[index].alive = false
+		e.alive--
+	}
+	e.instances[index].Deactivate()
+}
+
+func (e *Emitter) spawn(index int, world matrix.Mat4) {
+	d := &e.Data
+	pos, dir := e.shapeSample()
+	speed := e.rangeValue(d.SpeedMin, d.SpeedMax)
+	if d.WorldSpace {
+		// The translation is removed from the direction, only the rotation
+		// of the emitter should change where the particles are heading
+		dir = world.TransformPoint(dir).Subtract(world.Position()).Normal()
+		pos = world.TransformPoint(pos)
+	}
+	e.particles[index] = particle{
+		position:        pos,
+		velocity:        dir.Scale(matrix.Float(speed)),
+		color:           d.StartColor,
+		lifetime:        max(e.rangeValue(d.LifetimeMin, d.LifetimeMax), 0.0001),
+		size:            e.rangeValue(d.SizeMin, d.SizeMax),
+		rotation:        e.rangeValue(d.RotationMin, d.RotationMax),
+		angularVelocity: d.AngularVelocity,
+		alive:           true,
+	}
+	e.alive++
+	e.writeInstance(index, 0, world)
+}
+
+func (e *Emitter) writeInstance(index int, t float32, world matrix.Mat4) {
+	d := &e.Data
+	p := &e.particles[index]
+	inst := &e.instances[index]
+	pos := p.position
+	if !d.WorldSpace {
+		pos = world.TransformPoint(pos)
+	}
+	size := matrix.Float(p.size * d.SizeOverLifetime.Evaluate(t))
+	model := matrix.Mat4Identity()
+	model.Scale(matrix.Vec3{size, size, size})
+	model.SetTranslation(pos)
+	inst.SetModel(model)
+	inst.Color = p.color
+	inst.Color.MultiplyAssign(d.ColorOverLifetime.Evaluate(t))
+	inst.UVs = e.flipbookFrame(p, t)
+	inst.Rotation = p.rotation * (math.Pi / 180)
+	inst.Softness = d.Softness
+	inst.Activate()
+}
+
+// flipbookFrame returns the UV offset and size of the frame of the particle,
+// frames are counted left to right starting at the top row
+func (e *Emitter) flipbookFrame(p *particle, t float32) matrix.Vec4 {
+	cols := max(e.Data.FlipbookColumns, 1)
+	rows := max(e.Data.FlipbookRows, 1)
+	frames := cols * rows
+	if frames == 1 {
+		return matrix.Vec4{0, 0, 1, 1}
+	}
+	var frame int32
+	if e.Data.FlipbookFPS > 0 {
+		frame = int32(p.age*e.Data.FlipbookFPS) % frames
+	} else {
+		frame = min(int32(t*float32(frames)), frames-1)
+	}
+	w := 1 / matrix.Float(cols)
+	h := 1 / matrix.Float(rows)
+	col := frame % cols
+	row := frame / cols
+	return matrix.Vec4{matrix.Float(col) * w, matrix.Float(row) * h, w, h}
+}
+
+func (e *Emitter) rangeValue(low, high float32) float32 {
+	return low + (high-low)*e.rng.Float32()
+}
+
+// unitVector is a random direction that is uniformly distributed on the
+// unit sphere
+func (e *Emitter) unitVector() matrix.Vec3 {
+	z := 2*e.rng.Float64() - 1
+	phi := 2 * math.Pi * e.rng.Float64()
+	r := math.Sqrt(1 - z*z)
+	return matrix.Vec3{matrix.Float(r * math.Cos(phi)),
+		matrix.Float(r * math.Sin(phi)), matrix.Float(z)}
+}
+
+// shapeSample returns a spawn position and direction in the space of the
+// emitter, the cone and box emit along the emitter's forward axis
+func (e *Emitter) shapeSample() (matrix.Vec3, matrix.Vec3) {
+	d := &e.Data
+	switch d.Shape {
+	case ShapeSphere:
+		dir := e.unitVector()
+		dist := d.ShapeRadius
+		if !d.EmitFromShell {
+			dist *= float32(math.Cbrt(e.rng.Float64()))
+		}
+		return dir.Scale(matrix.Float(dist)), dir
+	case ShapeCone:
+		// The point on the base disc also decides how far the direction
+		// leans away from the axis, the edge of the disc leans by the angle
+		r := 1.0
+		if !d.EmitFromShell {
+			r = math.Sqrt(e.rng.Float64())
+		}
+		phi := 2 * math.Pi * e.rng.Float64()
+		radial := matrix.Vec3{matrix.Float(r * math.Cos(phi)),
+			matrix.Float(r * math.Sin(phi)), 0}
+		spread := matrix.Float(math.Tan(float64(d.ShapeAngle) * math.Pi / 180))
+		dir := matrix.Vec3Forward().Add(radial.Scale(spread)).Normal()
+		return radial.Scale(matrix.Float(d.ShapeRadius)), dir
+	case ShapeBox:
+		half := d.ShapeSize.Scale(0.5)
+		pos := matrix.Vec3{
+			half.X() * matrix.Float(2*e.rng.Float64()-1),
+			half.Y() * matrix.Float(2*e.rng.Float64()-1),
+			half.Z() * matrix.Float(2*e.rng.Float64()-1),
+		}
+		if d.EmitFromShell {
+			axis := e.rng.IntN(3)
+			pos[axis] = half[axis]
+			if e.rng.IntN(2) == 0 {
+				pos[axis] = -half[axis]
+			}
+		}
+		return pos, matrix.Vec3Forward()
+	case ShapeMesh:
+		if pos, normal, ok := e.surface.sample(e.rng); ok {
+			return pos, normal
+		}
+	}
+	return matrix.Vec3Zero(), e.unitVector()
+}
+
+// meshSurface picks random points on a triangle mesh, each triangle is
+// picked by its area so the points are spread evenly over the surface
+type meshSurface struct {
+	triangles [][3]matrix.Vec3
+	// areas is the running total of the triangle areas
+	areas []float32
+}
+
+func newMeshSurface(verts []rendering.Vertex, indices []uint32) meshSurface {
+	s := meshSurface{}
+	total := float32(0)
+	for i := 0; i+2 < len(indices); i += 3 {
+		a, b, c := indices[i], indices[i+1], indices[i+2]
+		if int(max(a, b, c)) >= len(verts) {
+			continue
+		}
+		tri := [3]matrix.Vec3{verts[a].Position, verts[b].Position, verts[c].Position}
+		cross := matrix.Vec3Cross(tri[1].Subtract(tri[0]), tri[2].Subtract(tri[0]))
+		area := float32(cross.Length()) * 0.5
+		if area <= 0 {
+			continue
+		}
+		total += area
+		s.triangles = append(s.triangles, tri)
+		s.areas = append(s.areas, total)
+	}
+	return s
+}
+
+func (s *meshSurface) sample(rng *rand.Rand) (matrix.Vec3, matrix.Vec3, bool) {
+	if len(s.triangles) == 0 {
+		return matrix.Vec3{}, matrix.Vec3{}, false
+	}
+	pick := rng.Float32() * s.areas[len(s.areas)-1]
+	idx := len(s.areas) - 1
+	lo, hi := 0, len(s.areas)-1
+	for lo <= hi {
+		mid := (lo + hi) / 2
+		if s.areas[mid] >= pick {
+			idx = mid
+			hi = mid - 1
+		} else {
+			lo = mid + 1
+		}
+	}
+	tri := &s.triangles[idx]
+	u, v := matrix.Float(rng.Float32()), matrix.Float(rng.Float32())
+	if u+v > 1 {
+		u, v = 1-u, 1-v
+	}
+	ab := tri[1].Subtract(tri[0])
+	ac := tri[2].Subtract(tri[0])
+	pos := tri[0].Add(ab.Scale(u)).Add(ac.Scale(v))
+	return pos, matrix.Vec3Cross(ab, ac).Normal(), true
+}
@@ -0,0 +1,235 @@
+/******************************************************************************/
+/* emitter_data.go                                                            */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package particles
+
+import (
+	"encoding/json"
+	"kaiju/engine/assets"
+	"kaiju/matrix"
+)
+
+const (
+	ShapePoint  = "Point"
+	ShapeSphere = "Sphere"
+	ShapeCone   = "Cone"
+	ShapeBox    = "Box"
+	ShapeMesh   = "Mesh"
+)
+
+// Shapes lists the emitter shapes, in the order they are shown in the editor
+var Shapes = []string{ShapePoint, ShapeSphere, ShapeCone, ShapeBox, ShapeMesh}
+
+// CurveKey is a value of a Curve at a normalized time (0 to 1)
+type CurveKey struct {
+	Time  float32
+	Value float32
+}
+
+// Curve is a piecewise linear curve over the normalized lifetime of a
+// particle, a curve without keys is always 1
+type Curve struct {
+	Keys []CurveKey
+}
+
+// ColorKey is a color of a Gradient at a normalized time (0 to 1)
+type ColorKey struct {
+	Time  float32
+	Color matrix.Color
+}
+
+// Gradient is a piecewise linear color ramp over the normalized lifetime of a
+// particle, a gradient without keys is always white
+type Gradient struct {
+	Keys []ColorKey
+}
+
+// Burst emits Count particles at Time seconds into every cycle of the
+// emitter. Cycles is how many times it repeats (0 is once) with Interval
+// seconds between each repeat.
+type Burst struct {
+	Time     float32
+	Count    int32
+	Cycles   int32
+	Interval float32
+}
+
+// EmitterData is the description of a particle emitter, it is stored as a
+// JSON asset so that it can be edited in the editor
+type EmitterData struct {
+	Name         string
+	MaxParticles int32 `default:"256"`
+	// Duration of one cycle of the emitter in seconds
+	Duration float32 `default:"5"`
+	Loop     bool    `default:"true"`
+	// WorldSpace particles stay where they were spawned when the emitter
+	// moves, otherwise they move along with the emitter
+	WorldSpace bool `default:"true"`
+
+	Shape string `default:"Point"`
+	// ShapeRadius is the radius of the sphere and the base of the cone
+	ShapeRadius float32 `default:"1"`
+	// ShapeAngle is the angle in degrees between the cone's axis and its side
+	ShapeAngle float32 `default:"25"`
+	// ShapeSize is the full size of the box
+	ShapeSize matrix.Vec3
+	// ShapeMesh is the mesh file (.gltf, .glb or .obj) used for the mesh
+	// shape, particles spawn on its surface
+	ShapeMesh string
+	// EmitFromShell spawns on the surface of the sphere, cone base or box
+	// rather than inside of it
+	EmitFromShell bool
+
+	SpawnRate float32 `default:"10"` // particles per second
+	Bursts    []Burst
+
+	LifetimeMin float32 `default:"1"`
+	LifetimeMax float32 `default:"2"`
+	SpeedMin    float32 `default:"1"`
+	SpeedMax    float32 `default:"2"`
+	SizeMin     float32 `default:"0.25"`
+	SizeMax     float32 `default:"0.5"`
+	// RotationMin, RotationMax and AngularVelocity are in degrees
+	RotationMin     float32
+	RotationMax     float32
+	AngularVelocity float32
+	StartColor      matrix.Color
+
+	Gravity matrix.Vec3
+	// Drag is the fraction of the velocity that is lost every second
+	Drag float32
+
+	SizeOverLifetime  Curve
+	SpeedOverLifetime Curve
+	ColorOverLifetime Gradient
+
+	Texture string
+	// FlipbookColumns and FlipbookRows split the texture into frames that are
+	// played left to right, top to bottom
+	FlipbookColumns int32 `default:"1"`
+	FlipbookRows    int32 `default:"1"`
+	// FlipbookFPS of 0 plays the frames once over the particle's lifetime
+	FlipbookFPS float32
+	// Softness fades out the particle towards the edges of its quad and as
+	// it gets close to the camera, 0 is a hard edge. The scene depth is not
+	// read so this does not soften where the particle cuts into geometry
+	Softness float32
+	// Seed makes the emitter spawn the same particles every time it plays,
+	// 0 picks a random seed
+	Seed uint64
+}
+
+// DefaultEmitterData is a small looping emitter that sends white particles
+// upward from a point
+func DefaultEmitterData() EmitterData {
+	return EmitterData{
+		MaxParticles:    256,
+		Duration:        5,
+		Loop:            true,
+		WorldSpace:      true,
+		Shape:           ShapePoint,
+		ShapeRadius:     1,
+		ShapeAngle:      25,
+		ShapeSize:       matrix.Vec3One(),
+		SpawnRate:       10,
+		LifetimeMin:     1,
+		LifetimeMax:     2,
+		SpeedMin:        1,
+		SpeedMax:        2,
+		SizeMin:         0.25,
+		SizeMax:         0.5,
+		StartColor:      matrix.ColorWhite(),
+		FlipbookColumns: 1,
+		FlipbookRows:    1,
+	}
+}
+
+// LoadEmitterData reads the emitter asset, fields missing from the file keep
+// the values of DefaultEmitterData
+func LoadEmitterData(db *assets.Database, key string) (EmitterData, error) {
+	data := DefaultEmitterData()
+	str, err := db.ReadText(key)
+	if err != nil {
+		return data, err
+	}
+	err = json.Unmarshal([]byte(str), &data)
+	return data, err
+}
+
+// Evaluate returns the value of the curve at the normalized time t
+func (c *Curve) Evaluate(t float32) float32 {
+	keys := c.Keys
+	if len(keys) == 0 {
+		return 1
+	}
+	if t <= keys[0].Time {
+		return keys[0].Value
+	}
+	for i := 1; i < len(keys); i++ {
+		if t <= keys[i].Time {
+			a, b := keys[i-1], keys[i]
+			span := b.Time - a.Time
+			if span <= 0 {
+				return b.Value
+			}
+			return a.Value + (b.Value-a.Value)*((t-a.Time)/span)
+		}
+	}
+	return keys[len(keys)-1].Value
+}
+
+// Evaluate returns the color of the gradient at the normalized time t
+func (g *Gradient) Evaluate(t float32) matrix.Color {
+	keys := g.Keys
+	if len(keys) == 0 {
+		return matrix.ColorWhite()
+	}
+	if t <= keys[0].Time {
+		return keys[0].Color
+	}
+	for i := 1; i < len(keys); i++ {
+		if t <= keys[i].Time {
+			a, b := keys[i-1], keys[i]
+			span := b.Time - a.Time
+			if span <= 0 {
+				return b.Color
+			}
+			return matrix.ColorMix(a.Color, b.Color, matrix.Float((t-a.Time)/span))
+		}
+	}
+	return keys[len(keys)-1].Color
+}
@@ -0,0 +1,189 @@
+/******************************************************************************/
+/* emitter_test.go                                                            */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package particles
+
+import (
+	"kaiju/matrix"
+	"testing"
+)
+
+func testEmitterData() EmitterData {
+	d := DefaultEmitterData()
+	d.Seed = 1
+	d.SpawnRate = 0
+	d.LifetimeMin = 1
+	d.LifetimeMax = 1
+	return d
+}
+
+func TestShaderDataSize(t *testing.T) {
+	// mat4 model + vec4 color + vec4 uvs + vec2 rotationSoftness
+	if size := (ShaderData{}).Size(); size != 64+16+16+8 {
+		t.Errorf("expected the shader data to be 104 bytes but was %d", size)
+	}
+}
+
+func TestCurveEvaluate(t *testing.T) {
+	c := Curve{}
+	if v := c.Evaluate(0.5); v != 1 {
+		t.Errorf("expected an empty curve to be 1 but was %f", v)
+	}
+	c.Keys = []CurveKey{{0, 0}, {0.5, 1}, {1, 0}}
+	tests := []struct{ time, want float32 }{
+		{-1, 0}, {0.25, 0.5}, {0.5, 1}, {0.75, 0.5}, {2, 0},
+	}
+	for _, test := range tests {
+		if v := c.Evaluate(test.time); !matrix.Approx(matrix.Float(v), matrix.Float(test.want)) {
+			t.Errorf("expected %f at %f but was %f", test.want, test.time, v)
+		}
+	}
+}
+
+func TestGradientEvaluate(t *testing.T) {
+	g := Gradient{}
+	if c := g.Evaluate(0.5); !c.Equals(matrix.ColorWhite()) {
+		t.Errorf("expected an empty gradient to be white but was %v", c)
+	}
+	g.Keys = []ColorKey{{0, matrix.ColorBlack()}, {1, matrix.ColorWhite()}}
+	if c := g.Evaluate(0.5); !matrix.Approx(c.R(), 0.5) || !matrix.Approx(c.A(), 1) {
+		t.Errorf("expected a half gray but was %v", c)
+	}
+}
+
+func TestEmitterSpawnRate(t *testing.T) {
+	d := testEmitterData()
+	d.SpawnRate = 10
+	e := NewEmitter(d, nil)
+	e.Play()
+	for range 5 {
+		e.Update(0.1)
+	}
+	if e.AliveCount() != 5 {
+		t.Errorf("expected 5 particles after half a second but had %d", e.AliveCount())
+	}
+}
+
+func TestEmitterBursts(t *testing.T) {
+	d := testEmitterData()
+	d.Bursts = []Burst{{Time: 0.2, Count: 8, Cycles: 1, Interval: 0.5}}
+	d.LifetimeMin = 10
+	d.LifetimeMax = 10
+	e := NewEmitter(d, nil)
+	e.Play()
+	e.Update(0.1)
+	if e.AliveCount() != 0 {
+		t.Fatalf("expected no particles before the burst but had %d", e.AliveCount())
+	}
+	e.Update(0.2)
+	if e.AliveCount() != 8 {
+		t.Fatalf("expected the first burst to spawn 8 particles but had %d", e.AliveCount())
+	}
+	e.Update(0.5)
+	if e.AliveCount() != 16 {
+		t.Fatalf("expected the repeat to spawn 8 more particles but had %d", e.AliveCount())
+	}
+	e.Update(1)
+	if e.AliveCount() != 16 {
+		t.Errorf("expected the burst to stop after its cycles but had %d", e.AliveCount())
+	}
+}
+
+func TestEmitterMaxParticles(t *testing.T) {
+	d := testEmitterData()
+	d.MaxParticles = 4
+	e := NewEmitter(d, nil)
+	e.Emit(10)
+	if e.AliveCount() != 4 {
+		t.Errorf("expected the emitter to be limited to 4 particles but had %d", e.AliveCount())
+	}
+}
+
+func TestEmitterLifetime(t *testing.T) {
+	e := NewEmitter(testEmitterData(), nil)
+	e.Emit(3)
+	e.Update(0.5)
+	if e.AliveCount() != 3 {
+		t.Fatalf("expected the particles to be alive but had %d", e.AliveCount())
+	}
+	for i := range e.instances {
+		if i < 3 && !e.instances[i].IsActive() {
+			t.Errorf("expected the instance %d of a living particle to be active", i)
+		}
+	}
+	e.Update(0.6)
+	if e.AliveCount() != 0 {
+		t.Fatalf("expected the particles to have died but had %d", e.AliveCount())
+	}
+	for i := range e.instances {
+		if e.instances[i].IsActive() {
+			t.Errorf("expected the instance %d of a dead particle to be inactive", i)
+		}
+	}
+}
+
+func TestEmitterGravity(t *testing.T) {
+	d := testEmitterData()
+	d.SpeedMin = 0
+	d.SpeedMax = 0
+	d.Gravity = matrix.Vec3{0, -10, 0}
+	e := NewEmitter(d, nil)
+	e.Emit(1)
+	e.Update(0.5)
+	if y := e.instances[0].Model().Position().Y(); y >= 0 {
+		t.Errorf("expected the particle to fall but it was at %f", y)
+	}
+}
+
+func TestMeshSurfaceSample(t *testing.T) {
+	e := NewEmitter(testEmitterData(), nil)
+	e.Data.Shape = ShapeMesh
+	// A single triangle on the y = 2 plane facing up
+	e.surface = meshSurface{
+		triangles: [][3]matrix.Vec3{{{0, 2, 0}, {0, 2, 1}, {1, 2, 0}}},
+		areas:     []float32{0.5},
+	}
+	for range 20 {
+		pos, dir := e.shapeSample()
+		if !matrix.Approx(pos.Y(), 2) || pos.X()+pos.Z() > 1.0001 {
+			t.Fatalf("expected the point %v to be on the triangle", pos)
+		}
+		if !matrix.Approx(dir.Y(), 1) {
+			t.Fatalf("expected the direction %v to be the triangle's normal", dir)
+		}
+	}
+}
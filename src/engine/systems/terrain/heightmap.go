@@ -0,0 +1,131 @@
+/******************************************************************************/
+/* heightmap.go                                                               */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package terrain
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"image"
+	"image/color"
+	"image/png"
+	"kaiju/engine/assets"
+	"math"
+	"path/filepath"
+	"strings"
+)
+
+// Heightmap is a grid of 16-bit height samples, 0 is the lowest point of the
+// terrain and 65535 is the highest
+type Heightmap struct {
+	// Samples holds Width*Depth samples, row by row along the X axis
+	Samples []uint16
+	Width   int
+	Depth   int
+}
+
+// LoadHeightmap reads the heightmap asset with the given key, see
+// #DecodeHeightmap for the supported formats
+func LoadHeightmap(db *assets.Database, key string) (Heightmap, error) {
+	data, err := db.Read(key)
+	if err != nil {
+		return Heightmap{}, err
+	}
+	return DecodeHeightmap(data, filepath.Ext(key))
+}
+
+// DecodeHeightmap decodes a heightmap from a PNG (".png") or from raw 16-bit
+// little endian samples (".r16" or ".raw"). 16-bit grayscale PNGs keep their
+// full precision, other PNGs are converted to grayscale. Raw heightmaps have
+// no header, so they must be square.
+func DecodeHeightmap(data []byte, ext string) (Heightmap, error) {
+	switch strings.ToLower(ext) {
+	case ".png":
+		img, err := png.Decode(bytes.NewReader(data))
+		if err != nil {
+			return Heightmap{}, err
+		}
+		return heightmapFromImage(img), nil
+	case ".r16", ".raw":
+		return heightmapFromRaw(data)
+	default:
+		return Heightmap{}, errors.New("unsupported heightmap format, expected .png, .r16 or .raw")
+	}
+}
+
+func heightmapFromImage(img image.Image) Heightmap {
+	b := img.Bounds()
+	h := Heightmap{
+		Samples: make([]uint16, b.Dx()*b.Dy()),
+		Width:   b.Dx(),
+		Depth:   b.Dy(),
+	}
+	if gray, ok := img.(*image.Gray16); ok {
+		for z := range h.Depth {
+			row := gray.Pix[z*gray.Stride:]
+			for x := range h.Width {
+				h.Samples[z*h.Width+x] = binary.BigEndian.Uint16(row[x*2:])
+			}
+		}
+		return h
+	}
+	for z := range h.Depth {
+		for x := range h.Width {
+			c := color.Gray16Model.Convert(img.At(b.Min.X+x, b.Min.Y+z))
+			h.Samples[z*h.Width+x] = c.(color.Gray16).Y
+		}
+	}
+	return h
+}
+
+func heightmapFromRaw(data []byte) (Heightmap, error) {
+	count := len(data) / 2
+	side := int(math.Sqrt(float64(count)))
+	if len(data)%2 != 0 || side*side != count {
+		return Heightmap{}, errors.New("raw heightmaps must be square with 16-bit samples")
+	}
+	h := Heightmap{
+		Samples: make([]uint16, count),
+		Width:   side,
+		Depth:   side,
+	}
+	for i := range h.Samples {
+		h.Samples[i] = binary.LittleEndian.Uint16(data[i*2:])
+	}
+	return h, nil
+}
@@ -0,0 +1,62 @@
+/******************************************************************************/
+/* shader_data.go                                                             */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package terrain
+
+import (
+	"kaiju/matrix"
+	"kaiju/rendering"
+	"unsafe"
+)
+
+// ShaderData is the instance data of a terrain chunk, setting ReceiveShadows
+// to 0 will stop shadows from being drawn onto the chunk
+type ShaderData struct {
+	rendering.ShaderDataBase
+	Color matrix.Color
+	// Tiling is how many times the texture of each of the first four layers
+	// repeats across the terrain, layers 4 to 7 share the tiling of layers 0
+	// to 3 in order
+	Tiling         matrix.Vec4
+	ReceiveShadows float32
+}
+
+func (t ShaderData) Size() int {
+	// The trailing padding of the struct is not part of the shader's layout
+	const end = unsafe.Offsetof(ShaderData{}.ReceiveShadows) + unsafe.Sizeof(float32(0))
+	return int(end - rendering.ShaderBaseDataStart)
+}
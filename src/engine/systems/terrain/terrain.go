@@ -0,0 +1,272 @@
+/******************************************************************************/
+/* terrain.go                                                                 */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package terrain
+
+import (
+	"errors"
+	"kaiju/engine/collision"
+	"kaiju/matrix"
+	"kaiju/rendering"
+	"math/bits"
+)
+
+// Settings describe how a heightmap is turned into a terrain
+type Settings struct {
+	// Size is how far the terrain reaches along the X and Z axes in world
+	// units, the longest side of the heightmap is stretched to this size
+	Size matrix.Float
+	// Height is the world height of the highest possible heightmap sample
+	Height matrix.Float
+	// ChunkQuads is how many quads are along each edge of a chunk at full
+	// detail, it must be a power of 2
+	ChunkQuads int
+	// LODLevels is how many levels of detail the chunks have, each level
+	// halves the number of quads along the edges of the chunk
+	LODLevels int
+	// LODDistance is how far from the camera the chunks are drawn at full
+	// detail, the distance range of each following level doubles
+	LODDistance matrix.Float
+}
+
+// Chunk is a square section of the terrain that is drawn with its own level
+// of detail
+type Chunk struct {
+	// X and Z are the grid coordinates of the first sample of the chunk
+	X, Z   int
+	Bounds collision.AABB
+	LOD    int
+}
+
+// Terrain splits a heightmap into chunks that are drawn at a level of detail
+// that depends on their distance to the camera. Everything on the terrain is
+// in its local space, where the first sample of the heightmap is at the origin
+// and the terrain reaches along +X and +Z.
+type Terrain struct {
+	settings Settings
+	field    collision.HeightField
+	chunks   []Chunk
+}
+
+func DefaultSettings() Settings {
+	return Settings{
+		Size:        1024,
+		Height:      200,
+		ChunkQuads:  64,
+		LODLevels:   5,
+		LODDistance: 128,
+	}
+}
+
+// New creates the terrain for the heightmap, the chunks start at their lowest
+// level of detail until #Terrain.SelectLODs is called
+func New(heightmap Heightmap, settings Settings) (*Terrain, error) {
+	if heightmap.Width < 2 || heightmap.Depth < 2 ||
+		len(heightmap.Samples) != heightmap.Width*heightmap.Depth {
+		return nil, errors.New("the heightmap must have at least 2x2 samples")
+	}
+	if settings.ChunkQuads < 1 || bits.OnesCount(uint(settings.ChunkQuads)) != 1 {
+		return nil, errors.New("the chunk quads of the terrain must be a power of 2")
+	}
+	if settings.Size <= 0 {
+		return nil, errors.New("the size of the terrain must be greater than 0")
+	}
+	// The lowest level of detail is a single quad for the whole chunk
+	maxLevels := bits.TrailingZeros(uint(settings.ChunkQuads)) + 1
+	settings.LODLevels = max(1, min(settings.LODLevels, maxLevels))
+	t := &Terrain{settings: settings}
+	spacing := settings.Size / matrix.Float(max(heightmap.Width, heightmap.Depth)-1)
+	t.field = collision.NewHeightField(heightmap.Width, heightmap.Depth, spacing)
+	for i, s := range heightmap.Samples {
+		t.field.Heights[i] = matrix.Float(s) / 65535 * settings.Height
+	}
+	q := settings.ChunkQuads
+	for z := 0; z < heightmap.Depth-1; z += q {
+		for x := 0; x < heightmap.Width-1; x += q {
+			t.chunks = append(t.chunks, Chunk{
+				X:      x,
+				Z:      z,
+				Bounds: t.chunkBounds(x, z),
+				LOD:    settings.LODLevels - 1,
+			})
+		}
+	}
+	return t, nil
+}
+
+func (t *Terrain) chunkBounds(x, z int) collision.AABB {
+	q := t.settings.ChunkQuads
+	low := t.field.Sample(x, z)
+	high := low
+	for j := z; j <= min(z+q, t.field.Depth-1); j++ {
+		for i := x; i <= min(x+q, t.field.Width-1); i++ {
+			h := t.field.Sample(i, j)
+			low = min(low, h)
+			high = max(high, h)
+		}
+	}
+	a := t.field.SamplePoint(x, z)
+	b := t.field.SamplePoint(x+q, z+q)
+	return collision.AABBFromMinMax(matrix.Vec3{a.X(), low, a.Z()},
+		matrix.Vec3{b.X(), high, b.Z()})
+}
+
+// Settings returns the settings the terrain was created with, the LOD levels
+// are limited to what the chunk size allows
+func (t *Terrain) Settings() Settings { return t.settings }
+
+// HeightField returns the heights of the terrain in its local space, it is the
+// shape used for collision
+func (t *Terrain) HeightField() *collision.HeightField { return &t.field }
+
+// Chunks returns the chunks of the terrain, they should not be modified
+func (t *Terrain) Chunks() []Chunk { return t.chunks }
+
+// HeightAt returns the height of the terrain at the local X and Z coordinate,
+// false is returned if the coordinate is outside of the terrain
+func (t *Terrain) HeightAt(x, z matrix.Float) (matrix.Float, bool) {
+	return t.field.HeightAt(x, z)
+}
+
+// NormalAt returns the smooth normal of the terrain at the local X and Z
+// coordinate, false is returned if the coordinate is outside of the terrain
+func (t *Terrain) NormalAt(x, z matrix.Float) (matrix.Vec3, bool) {
+	return t.field.NormalAt(x, z)
+}
+
+// RayHit returns the first point where the local space ray hits the terrain
+// within the given length
+func (t *Terrain) RayHit(ray collision.Ray, length matrix.Float) (matrix.Vec3, bool) {
+	return t.field.RayHit(ray, length)
+}
+
+// LODForDistance returns the level of detail for a chunk at the given
+// distance from the camera
+func (t *Terrain) LODForDistance(distance matrix.Float) int {
+	lod := 0
+	r := t.settings.LODDistance
+	for lod < t.settings.LODLevels-1 && distance > r {
+		lod++
+		r *= 2
+	}
+	return lod
+}
+
+// SelectLODs picks the level of detail of each chunk from its distance to the
+// camera position, which is in the local space of the terrain. The indexes of
+// the chunks that changed their level of detail are returned.
+func (t *Terrain) SelectLODs(camera matrix.Vec3) []int {
+	var changed []int
+	for i := range t.chunks {
+		c := &t.chunks[i]
+		closest := matrix.Vec3Min(matrix.Vec3Max(camera, c.Bounds.Min()), c.Bounds.Max())
+		lod := t.LODForDistance(camera.Subtract(closest).Length())
+		if lod != c.LOD {
+			c.LOD = lod
+			changed = append(changed, i)
+		}
+	}
+	return changed
+}
+
+// ChunkMesh builds the mesh of the chunk at the given index for a level of
+// detail. The edges of the chunk have skirts that hang down below the surface
+// to hide the cracks between neighboring chunks at different levels of
+// detail. The first UV of the vertices goes from 0 to 1 across the whole
+// terrain so that splat maps cover the terrain once.
+func (t *Terrain) ChunkMesh(index, lod int) ([]rendering.Vertex, []uint32) {
+	c := &t.chunks[index]
+	step := 1 << max(0, min(lod, t.settings.LODLevels-1))
+	n := t.settings.ChunkQuads / step
+	row := n + 1
+	verts := make([]rendering.Vertex, 0, row*row+4*row)
+	indices := make([]uint32, 0, n*n*6+4*n*12)
+	for j := 0; j <= n; j++ {
+		for i := 0; i <= n; i++ {
+			verts = append(verts, t.vertex(c.X+i*step, c.Z+j*step))
+		}
+	}
+	for j := 0; j < n; j++ {
+		for i := 0; i < n; i++ {
+			a := uint32(j*row + i)
+			b := a + 1
+			cc := a + uint32(row)
+			d := cc + 1
+			indices = append(indices, a, cc, b, b, cc, d)
+		}
+	}
+	depth := c.Bounds.Extent.Y()*2 + t.field.Spacing*matrix.Float(step)
+	edges := [4]func(k int) int{
+		func(k int) int { return k },
+		func(k int) int { return n*row + k },
+		func(k int) int { return k * row },
+		func(k int) int { return k*row + n },
+	}
+	for _, edge := range edges {
+		start := uint32(len(verts))
+		for k := 0; k <= n; k++ {
+			v := verts[edge(k)]
+			v.Position.SetY(v.Position.Y() - depth)
+			verts = append(verts, v)
+		}
+		// Skirts are seen from either side depending on the edge, so both
+		// windings are added rather than working out the facing of each edge
+		for k := 0; k < n; k++ {
+			a := uint32(edge(k))
+			b := uint32(edge(k + 1))
+			la := start + uint32(k)
+			lb := la + 1
+			indices = append(indices, a, la, b, b, la, lb, a, b, la, b, lb, la)
+		}
+	}
+	return verts, indices
+}
+
+func (t *Terrain) vertex(x, z int) rendering.Vertex {
+	x = min(x, t.field.Width-1)
+	z = min(z, t.field.Depth-1)
+	return rendering.Vertex{
+		Position: t.field.SamplePoint(x, z),
+		Normal:   t.field.SampleNormal(x, z),
+		Tangent:  matrix.Vec4{1, 0, 0, 1},
+		UV0: matrix.Vec2{
+			matrix.Float(x) / matrix.Float(t.field.Width-1),
+			matrix.Float(z) / matrix.Float(t.field.Depth-1),
+		},
+		Color: matrix.ColorWhite(),
+	}
+}
@@ -0,0 +1,147 @@
+/******************************************************************************/
+/* terrain_test.go                                                            */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package terrain
+
+import (
+	"kaiju/matrix"
+	"testing"
+)
+
+func testHeightmap(width, depth int) Heightmap {
+	h := Heightmap{Samples: make([]uint16, width*depth), Width: width, Depth: depth}
+	for z := range depth {
+		for x := range width {
+			h.Samples[z*width+x] = uint16(x * 1000)
+		}
+	}
+	return h
+}
+
+func testSettings() Settings {
+	return Settings{Size: 16, Height: 65.535, ChunkQuads: 8, LODLevels: 10, LODDistance: 10}
+}
+
+func TestNewTerrainValidates(t *testing.T) {
+	if _, err := New(Heightmap{Width: 1, Depth: 1, Samples: []uint16{0}}, testSettings()); err == nil {
+		t.Error("expected a 1x1 heightmap to be rejected")
+	}
+	s := testSettings()
+	s.ChunkQuads = 6
+	if _, err := New(testHeightmap(17, 17), s); err == nil {
+		t.Error("expected chunk quads that are not a power of 2 to be rejected")
+	}
+}
+
+func TestNewTerrainChunks(t *testing.T) {
+	ter, err := New(testHeightmap(17, 9), testSettings())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ter.Chunks()) != 2 {
+		t.Fatalf("expected 2 chunks, got %d", len(ter.Chunks()))
+	}
+	if ter.Settings().LODLevels != 4 {
+		t.Errorf("expected the LOD levels to be limited to 4, got %d", ter.Settings().LODLevels)
+	}
+	if y, ok := ter.HeightAt(3, 2); !ok || matrix.Abs(y-3) > 0.001 {
+		t.Errorf("expected the height at 3 to be 3, got %v", y)
+	}
+	b := ter.Chunks()[1].Bounds
+	if !matrix.Approx(b.Min().Y(), 8) || !matrix.Approx(b.Max().Y(), 16) {
+		t.Errorf("unexpected chunk height range %v to %v", b.Min().Y(), b.Max().Y())
+	}
+}
+
+func TestChunkMesh(t *testing.T) {
+	ter, _ := New(testHeightmap(17, 9), testSettings())
+	for lod, n := range []int{8, 4, 2, 1} {
+		verts, indices := ter.ChunkMesh(1, lod)
+		row := n + 1
+		if len(verts) != row*row+4*row {
+			t.Errorf("lod %d: expected %d vertices, got %d", lod, row*row+4*row, len(verts))
+		}
+		if len(indices) != n*n*6+4*n*12 {
+			t.Errorf("lod %d: expected %d indices, got %d", lod, n*n*6+4*n*12, len(indices))
+		}
+		last := verts[row*row-1]
+		if !matrix.Vec3Approx(last.Position, matrix.Vec3{16, 16, 8}) {
+			t.Errorf("lod %d: unexpected corner %v", lod, last.Position)
+		}
+		if !matrix.Approx(last.UV0.X(), 1) || !matrix.Approx(last.UV0.Y(), 1) {
+			t.Errorf("lod %d: unexpected corner uv %v", lod, last.UV0)
+		}
+	}
+	// The surface faces up
+	verts, indices := ter.ChunkMesh(0, 0)
+	a, b, c := verts[indices[0]].Position, verts[indices[1]].Position, verts[indices[2]].Position
+	if matrix.Vec3Cross(b.Subtract(a), c.Subtract(a)).Y() <= 0 {
+		t.Error("expected the surface triangles to face up")
+	}
+}
+
+func TestSelectLODs(t *testing.T) {
+	ter, _ := New(testHeightmap(17, 9), testSettings())
+	changed := ter.SelectLODs(matrix.Vec3{4, 4, 4})
+	if len(changed) != 2 {
+		t.Fatalf("expected both chunks to change, got %v", changed)
+	}
+	if ter.Chunks()[0].LOD != 0 {
+		t.Errorf("expected the chunk under the camera at full detail, got %d", ter.Chunks()[0].LOD)
+	}
+	if len(ter.SelectLODs(matrix.Vec3{4, 4, 4})) != 0 {
+		t.Error("expected no changes without the camera moving")
+	}
+	ter.SelectLODs(matrix.Vec3{1000, 0, 0})
+	for i, c := range ter.Chunks() {
+		if c.LOD != 3 {
+			t.Errorf("expected chunk %d at the lowest detail, got %d", i, c.LOD)
+		}
+	}
+}
+
+func TestLODForDistance(t *testing.T) {
+	ter, _ := New(testHeightmap(17, 9), testSettings())
+	for _, c := range []struct {
+		d   matrix.Float
+		lod int
+	}{{0, 0}, {10, 0}, {15, 1}, {30, 2}, {50, 3}, {1e6, 3}} {
+		if got := ter.LODForDistance(c.d); got != c.lod {
+			t.Errorf("expected lod %d at %v, got %d", c.lod, c.d, got)
+		}
+	}
+}
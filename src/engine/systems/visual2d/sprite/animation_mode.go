@@ -0,0 +1,77 @@
+/******************************************************************************/
+/* animation_mode.go                                                          */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package sprite
+
+import "strings"
+
+// AnimationMode controls what happens when a sprite animation reaches the
+// end of its frames
+type AnimationMode int
+
+const (
+	// AnimationModeLoop will restart the animation from the first frame
+	AnimationModeLoop AnimationMode = iota
+	// AnimationModePingPong will play the animation forward and then back
+	// in reverse, repeating indefinitely
+	AnimationModePingPong
+	// AnimationModeOneShot will stop on the last frame and execute the
+	// OnAnimationFinished event of the sprite
+	AnimationModeOneShot
+)
+
+func animationModeFromString(mode string) AnimationMode {
+	switch strings.ToLower(mode) {
+	case "pingpong", "ping-pong", "ping_pong":
+		return AnimationModePingPong
+	case "oneshot", "one-shot", "one_shot", "once":
+		return AnimationModeOneShot
+	default:
+		return AnimationModeLoop
+	}
+}
+
+func (m AnimationMode) String() string {
+	switch m {
+	case AnimationModePingPong:
+		return "pingpong"
+	case AnimationModeOneShot:
+		return "oneshot"
+	default:
+		return "loop"
+	}
+}
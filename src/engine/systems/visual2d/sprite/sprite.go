@@ -40,6 +40,8 @@ package sprite
 import (
 	"kaiju/engine/assets"
 	"kaiju/engine"
+	"kaiju/engine/systems/events"
+	"kaiju/klib"
 	"kaiju/matrix"
 	"kaiju/rendering"
 	"log/slog"
@@ -48,19 +50,27 @@ import (
 var ZAxisScaleFactor = float32(16.0)
 
 type Sprite struct {
-	Entity                   *engine.Entity
+	Entity *engine.Entity
+	// OnAnimationFinished is executed when an animation using the
+	// AnimationModeOneShot mode reaches its last frame, the animation stays
+	// on that frame until it is restarted (see RestartAnimation)
+	OnAnimationFinished      events.Event
 	host                     *engine.Host
 	texture                  *rendering.Texture
 	flipBook                 []*rendering.Texture
+	flipBookDurations        []float32
+	flipBookEvents           map[int][]string
+	frameEvents              map[string]*events.Event
 	frameDelay, fps          float32
 	frameCount, currentFrame int
+	direction                int
+	mode                     AnimationMode
 	paused                   bool
 	spriteSheet              spriteSheet
 	shaderData               ShaderData
 	drawing                  rendering.Drawing
 	currentClipName          string
-	currentClip              []spriteSheetFrameData
-	clipIdx                  int
+	currentClip              spriteSheetClip
 	baseScale                matrix.Vec3
 }
 
@@ -116,6 +126,49 @@ func (s *Sprite) SetFrameRate(framesPerSecond float32) {
 	s.fps = framesPerSecond
 }
 
+func (s Sprite) AnimationMode() AnimationMode { return s.mode }
+
+// SetAnimationMode changes how the current animation behaves once it reaches
+// the end of its frames. Changing the sheet clip will reset the mode to the
+// one described by the clip within the sprite sheet data.
+func (s *Sprite) SetAnimationMode(mode AnimationMode) {
+	s.mode = mode
+	s.direction = 1
+}
+
+// FrameEvent returns the event for the given name, the event is executed
+// any time the animation enters a frame that has been tagged with this name.
+// The event is created if it doesn't already exist so that listeners can be
+// added before the animation is set.
+func (s *Sprite) FrameEvent(name string) *events.Event {
+	if s.frameEvents == nil {
+		s.frameEvents = make(map[string]*events.Event)
+	}
+	evt, ok := s.frameEvents[name]
+	if !ok {
+		evt = &events.Event{}
+		s.frameEvents[name] = evt
+	}
+	return evt
+}
+
+// AddFlipBookFrameEvent tags a frame of the flip-book animation with the
+// named event, see FrameEvent for listening to the event
+func (s *Sprite) AddFlipBookFrameEvent(frame int, name string) {
+	if s.flipBookEvents == nil {
+		s.flipBookEvents = make(map[int][]string)
+	}
+	s.flipBookEvents[frame] = append(s.flipBookEvents[frame], name)
+}
+
+// SetFlipBookFrameDurations sets how long (in seconds) each frame of the
+// flip-book animation is displayed. Any frame that doesn't have a duration
+// (or has a duration of 0) will use the sprite's frame rate.
+func (s *Sprite) SetFlipBookFrameDurations(seconds ...float32) {
+	s.flipBookDurations = append(s.flipBookDurations[:0], seconds...)
+	s.resetDelay()
+}
+
 func (s *Sprite) recreateDrawing() {
 	s.shaderData.Destroy()
 	proxy := s.shaderData
@@ -142,9 +195,11 @@ func (s *Sprite) SetFlipBookAnimation(
 	}
 	s.frameCount = count
 	s.fps = framesPerSecond
-	s.currentFrame = 0
+	s.flipBookDurations = s.flipBookDurations[:0]
+	s.flipBookEvents = nil
+	s.direction = 1
+	s.setFrame(0)
 	s.resetDelay()
-	s.SetTexture(s.flipBook[s.currentFrame])
 }
 
 func (s *Sprite) SetColor(color matrix.Color) {
@@ -157,46 +212,118 @@ func (s *Sprite) SetColor(color matrix.Color) {
 
 func (s Sprite) CurrentClipName() string { return s.currentClipName }
 
+// SetSheetClip changes the sprite sheet clip that is animated, nothing
+// changes when the clip is already the current clip. Use PlaySheetClip to
+// play the clip from its start regardless.
 func (s *Sprite) SetSheetClip(clipName string) {
 	if s.currentClipName != clipName {
-		s.currentClipName = clipName
-		s.currentClip = s.spriteSheet.clips[clipName]
-		s.setSheetFrame(0)
-		s.frameCount = len(s.currentClip)
+		s.changeSheetClip(clipName)
+		s.restart()
+	}
+}
+
+// PlaySheetClip changes to the named sprite sheet clip and plays it from its
+// first frame, even if it is the current clip. This is how a clip using the
+// AnimationModeOneShot mode is played again once it has finished.
+func (s *Sprite) PlaySheetClip(clipName string) {
+	if s.currentClipName != clipName {
+		s.changeSheetClip(clipName)
 	}
+	s.RestartAnimation()
+}
+
+// RestartAnimation plays the current animation from its first frame, this
+// will also resume the animation if it was stopped or had finished
+func (s *Sprite) RestartAnimation() {
+	s.paused = false
+	s.restart()
+}
+
+func (s *Sprite) changeSheetClip(clipName string) {
+	s.currentClipName = clipName
+	s.currentClip = s.spriteSheet.clips[clipName]
+	s.frameCount = len(s.currentClip.frames)
+	s.mode = s.currentClip.mode
+}
+
+func (s *Sprite) restart() {
+	s.direction = 1
+	s.setFrame(0)
+	s.resetDelay()
 }
 
 func (s *Sprite) resetDelay() {
 	s.frameDelay = 1.0 / s.fps
+	if s.isFlipBook() {
+		if s.currentFrame < len(s.flipBookDurations) && s.flipBookDurations[s.currentFrame] > 0 {
+			s.frameDelay = s.flipBookDurations[s.currentFrame]
+		}
+	} else if s.isSpriteSheet() && s.currentFrame < len(s.currentClip.frames) {
+		if d := s.currentClip.frames[s.currentFrame].Duration; d > 0 {
+			s.frameDelay = float32(d) / 1000.0
+		}
+	}
 }
 
 func (s *Sprite) update(deltaTime float64) {
-	if !s.Entity.IsActive() {
+	if !s.Entity.IsActive() || s.paused {
 		return
 	}
 	s.frameDelay -= float32(deltaTime)
 	if s.frameCount > 0 && s.frameDelay <= 0.0 {
-		s.currentFrame++
-		if s.currentFrame == s.frameCount {
-			s.currentFrame = 0
-		}
-		if s.isFlipBook() {
-			s.SetTexture(s.flipBook[s.currentFrame])
-		} else if s.isSpriteSheet() {
-			frame := s.clipIdx + 1
-			if frame < s.frameCount {
-				s.setSheetFrame(frame)
-			} else {
-				s.setSheetFrame(0)
-			}
+		frame, finished := s.nextFrame()
+		if finished {
+			s.paused = true
+			s.OnAnimationFinished.Execute()
+			return
 		}
+		s.setFrame(frame)
 		s.resetDelay()
 	}
 }
 
+func (s *Sprite) nextFrame() (int, bool) {
+	if s.direction == 0 {
+		s.direction = 1
+	}
+	next := s.currentFrame + s.direction
+	switch s.mode {
+	case AnimationModePingPong:
+		if next < 0 || next >= s.frameCount {
+			s.direction = -s.direction
+			next = klib.Clamp(s.currentFrame+s.direction, 0, s.frameCount-1)
+		}
+	case AnimationModeOneShot:
+		if next >= s.frameCount {
+			return s.currentFrame, true
+		}
+	default:
+		if next >= s.frameCount {
+			next = 0
+		}
+	}
+	return next, false
+}
+
+func (s *Sprite) setFrame(frame int) {
+	s.currentFrame = frame
+	var names []string
+	if s.isFlipBook() {
+		s.SetTexture(s.flipBook[frame])
+		names = s.flipBookEvents[frame]
+	} else if s.isSpriteSheet() && frame < len(s.currentClip.frames) {
+		s.setSheetFrame(frame)
+		names = s.currentClip.events[frame]
+	}
+	for i := range names {
+		if evt, ok := s.frameEvents[names[i]]; ok {
+			evt.Execute()
+		}
+	}
+}
+
 func (s *Sprite) setSheetFrame(frame int) {
-	s.clipIdx = frame
-	f := s.currentClip[frame]
+	f := s.currentClip.frames[frame]
 	h := float32(f.Frame.H) / s.texture.Size().Height()
 	s.shaderData.UVs = matrix.Vec4{
 		float32(f.Frame.X) / s.texture.Size().Width(),
@@ -221,9 +348,10 @@ func NewSprite(x, y, width, height matrix.Float,
 
 	e := host.NewEntity()
 	sprite := &Sprite{
-		host:     host,
-		Entity:   e,
-		flipBook: []*rendering.Texture{},
+		host:      host,
+		Entity:    e,
+		flipBook:  []*rendering.Texture{},
+		direction: 1,
 	}
 	sprite.baseScale = matrix.Vec3{width, height, 1.0}
 	mat, err := host.MaterialCache().Material(assets.MaterialDefinitionSprite)
@@ -266,8 +394,7 @@ func NewSpriteFlipBook(x, y, width, height float32,
 	host *engine.Host, images []*rendering.Texture, fps float32) *Sprite {
 
 	s := NewSprite(x, y, width, height, host, images[0], matrix.ColorWhite())
-	s.fps = fps
-	s.flipBook = images
+	s.SetFlipBookAnimation(fps, images...)
 	updateId := host.Updater.AddUpdate(s.update)
 	s.Entity.OnDestroy.Add(func() {
 		host.Updater.RemoveUpdate(updateId)
@@ -279,12 +406,19 @@ func NewSpriteSheet(x, y, width, height float32,
 	host *engine.Host, texture *rendering.Texture, jsonStr string,
 	fps float32, initialClip string) *Sprite {
 
-	s := NewSprite(x, y, width, height, host, texture, matrix.ColorWhite())
-	var err error
-	s.spriteSheet, err = ReadSpriteSheetData(jsonStr)
+	sheet, err := ReadSpriteSheetData(jsonStr)
 	if err != nil {
 		panic(err)
 	}
+	return newSpriteSheet(x, y, width, height, host, texture, sheet, fps, initialClip)
+}
+
+func newSpriteSheet(x, y, width, height float32,
+	host *engine.Host, texture *rendering.Texture, sheet spriteSheet,
+	fps float32, initialClip string) *Sprite {
+
+	s := NewSprite(x, y, width, height, host, texture, matrix.ColorWhite())
+	s.spriteSheet = sheet
 	s.fps = fps
 	s.SetSheetClip(initialClip)
 	updateId := host.Updater.AddUpdate(s.update)
@@ -39,6 +39,7 @@ package sprite
 
 import (
 	"encoding/json"
+	"fmt"
 	"kaiju/klib"
 	"strconv"
 	"strings"
@@ -69,17 +70,47 @@ type spriteSheetFrameData struct {
 	SpriteSourceSize spriteSheetFrameDataRect  `json:"spriteSourceSize"`
 	SourceSize       spriteSheetFrameDataSize  `json:"sourceSize"`
 	Pivot            spriteSheetFrameDataPivot `json:"pivot"`
+	// Duration is how long (in milliseconds) this frame is displayed, a
+	// value of 0 will fall back to the frame rate of the sprite
+	Duration int `json:"duration,omitempty"`
+}
+
+type spriteSheetFrameEvent struct {
+	Frame int    `json:"frame"`
+	Name  string `json:"name"`
+}
+
+type spriteSheetClipData struct {
+	Frames []string                `json:"frames"`
+	Mode   string                  `json:"mode,omitempty"`
+	Events []spriteSheetFrameEvent `json:"events,omitempty"`
 }
 
 type spriteSheetData struct {
 	ClipStart int                             `json:"clipStart"`
 	MirrorX   bool                            `json:"mirrorX"`
 	Frames    map[string]spriteSheetFrameData `json:"frames"`
+	// Clips are explicitly named animations, when a clip is listed here it
+	// will replace any clip that was derived from the frame names
+	Clips map[string]spriteSheetClipData `json:"clips,omitempty"`
+}
+
+type spriteSheetClip struct {
+	frames []spriteSheetFrameData
+	mode   AnimationMode
+	events map[int][]string
 }
 
 type spriteSheet struct {
 	data  spriteSheetData
-	clips map[string][]spriteSheetFrameData
+	clips map[string]spriteSheetClip
+}
+
+func (c *spriteSheetClip) addEvent(frame int, name string) {
+	if c.events == nil {
+		c.events = make(map[int][]string)
+	}
+	c.events[frame] = append(c.events[frame], name)
 }
 
 func ReadSpriteSheetData(jsonStr string) (spriteSheet, error) {
@@ -87,7 +118,7 @@ func ReadSpriteSheetData(jsonStr string) (spriteSheet, error) {
 	err := klib.JsonDecode(json.NewDecoder(strings.NewReader(jsonStr)), &data)
 	sheet := spriteSheet{
 		data:  data,
-		clips: make(map[string][]spriteSheetFrameData),
+		clips: make(map[string]spriteSheetClip),
 	}
 	if err == nil {
 		for k, v := range data.Frames {
@@ -102,26 +133,43 @@ func ReadSpriteSheetData(jsonStr string) (spriteSheet, error) {
 				idx, _ := strconv.Atoi(last)
 				idx -= data.ClipStart
 				clipName := strings.Join(parts[:len(parts)-1], "_")
-				if _, ok := sheet.clips[clipName]; !ok {
-					sheet.clips[clipName] = make([]spriteSheetFrameData, 0)
-				}
-				for len(sheet.clips[clipName]) <= idx {
-					sheet.clips[clipName] = append(sheet.clips[clipName], spriteSheetFrameData{})
+				clip := sheet.clips[clipName]
+				for len(clip.frames) <= idx {
+					clip.frames = append(clip.frames, spriteSheetFrameData{})
 				}
-				sheet.clips[clipName][idx] = v
+				clip.frames[idx] = v
+				sheet.clips[clipName] = clip
 			} else {
-				sheet.clips[k] = []spriteSheetFrameData{v}
+				sheet.clips[k] = spriteSheetClip{frames: []spriteSheetFrameData{v}}
+			}
+		}
+		for name, c := range data.Clips {
+			clip := spriteSheetClip{
+				frames: make([]spriteSheetFrameData, 0, len(c.Frames)),
+				mode:   animationModeFromString(c.Mode),
+			}
+			for _, f := range c.Frames {
+				frame, ok := data.Frames[f]
+				if !ok {
+					return sheet, fmt.Errorf("the clip '%s' references the missing frame '%s'", name, f)
+				}
+				clip.frames = append(clip.frames, frame)
+			}
+			for _, e := range c.Events {
+				clip.addEvent(e.Frame, e.Name)
 			}
+			sheet.clips[name] = clip
 		}
 	}
 	if sheet.data.MirrorX {
 		for k, v := range sheet.clips {
 			if strings.HasSuffix(k, "left") {
-				cpy := make([]spriteSheetFrameData, len(v))
-				for i := range v {
-					cpy[i] = v[i]
-					cpy[i].Frame.X += cpy[i].Frame.W
-					cpy[i].Frame.W *= -1
+				cpy := v
+				cpy.frames = make([]spriteSheetFrameData, len(v.frames))
+				for i := range v.frames {
+					cpy.frames[i] = v.frames[i]
+					cpy.frames[i].Frame.X += cpy.frames[i].Frame.W
+					cpy.frames[i].Frame.W *= -1
 				}
 				sheet.clips[strings.TrimSuffix(k, "left")+"right"] = cpy
 			}
@@ -0,0 +1,111 @@
+/******************************************************************************/
+/* sprite_sheet_asset.go                                                      */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package sprite
+
+import (
+	"errors"
+	"kaiju/engine"
+	"kaiju/engine/assets"
+	"kaiju/rendering"
+	"path"
+)
+
+// DefaultSheetFrameRate is the frame rate of sprite sheet assets that were
+// not given one when they were imported
+const DefaultSheetFrameRate = 12
+
+// SpriteSheetMetadata is the import settings for a sprite sheet JSON asset
+type SpriteSheetMetadata struct {
+	// Image is the texture that the sprite sheet was exported for, it is
+	// relative to the folder containing the sprite sheet JSON
+	Image     string
+	FrameRate float32
+}
+
+// NewSpriteSheetFromAsset creates a sprite sheet sprite from the sprite sheet
+// JSON asset with the given key. Within the editor the sheet that was
+// converted on import is read from the project cache along with the image
+// and frame rate set on import. Otherwise the JSON is read from the asset
+// database, sheets exported from Aseprite or TexturePacker are converted and
+// the image is the one named within the JSON.
+func NewSpriteSheetFromAsset(x, y, width, height float32,
+	host *engine.Host, key string, filter rendering.TextureFilter,
+	initialClip string) (*Sprite, error) {
+
+	jsonStr, meta, err := loadSpriteSheetAsset(host.AssetDatabase(), key)
+	if err != nil {
+		return nil, err
+	}
+	if meta.Image == "" {
+		return nil, errors.New("the sprite sheet " + key + " has no image")
+	}
+	sheet, err := ReadSpriteSheetData(jsonStr)
+	if err != nil {
+		return nil, err
+	}
+	texture, err := host.TextureCache().Texture(path.Join(path.Dir(key), meta.Image), filter)
+	if err != nil {
+		return nil, err
+	}
+	if meta.FrameRate <= 0 {
+		meta.FrameRate = DefaultSheetFrameRate
+	}
+	return newSpriteSheet(x, y, width, height, host, texture, sheet,
+		meta.FrameRate, initialClip), nil
+}
+
+func loadSpriteSheetAsset(db *assets.Database, key string) (string, SpriteSheetMetadata, error) {
+	if jsonStr, meta, ok := loadImportedSpriteSheet(key); ok {
+		return jsonStr, meta, nil
+	}
+	src, err := db.ReadText(key)
+	if err != nil {
+		return "", SpriteSheetMetadata{}, err
+	}
+	meta := SpriteSheetMetadata{
+		Image:     SpriteSheetImage(src),
+		FrameRate: DefaultSheetFrameRate,
+	}
+	switch {
+	case IsAsepriteSheet(src):
+		src, err = ConvertAsepriteSheet(src)
+	case IsTexturePackerSheet(src):
+		src, err = ConvertTexturePackerSheet(src)
+	}
+	return src, meta, err
+}
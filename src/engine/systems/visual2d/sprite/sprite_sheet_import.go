@@ -0,0 +1,307 @@
+/******************************************************************************/
+/* sprite_sheet_import.go                                                     */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package sprite
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"kaiju/klib"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+const (
+	asepriteApp      = "aseprite.org"
+	texturePackerApp = "codeandweb.com/texturepacker"
+)
+
+var ErrUnknownSpriteSheetFormat = errors.New("the sprite sheet json was not exported from a known tool")
+
+type namedSheetFrame struct {
+	name string
+	data spriteSheetFrameData
+}
+
+type exportedSheetMeta struct {
+	App       string                   `json:"app"`
+	Image     string                   `json:"image"`
+	Size      spriteSheetFrameDataSize `json:"size"`
+	FrameTags []asepriteFrameTag       `json:"frameTags"`
+	Layers    []asepriteLayer          `json:"layers"`
+}
+
+type asepriteFrameTag struct {
+	Name      string `json:"name"`
+	From      int    `json:"from"`
+	To        int    `json:"to"`
+	Direction string `json:"direction"`
+	Repeat    string `json:"repeat"`
+	Data      string `json:"data"`
+}
+
+type asepriteCel struct {
+	Frame int    `json:"frame"`
+	Data  string `json:"data"`
+}
+
+type asepriteLayer struct {
+	Name string        `json:"name"`
+	Cels []asepriteCel `json:"cels"`
+}
+
+type exportedSheet struct {
+	Frames     json.RawMessage     `json:"frames"`
+	Animations map[string][]string `json:"animations"`
+	Meta       exportedSheetMeta   `json:"meta"`
+}
+
+// IsAsepriteSheet returns true if the JSON was exported by Aseprite
+func IsAsepriteSheet(jsonStr string) bool {
+	sheet, err := readExportedSheet(jsonStr)
+	return err == nil && strings.Contains(sheet.Meta.App, asepriteApp)
+}
+
+// IsTexturePackerSheet returns true if the JSON was exported by TexturePacker
+func IsTexturePackerSheet(jsonStr string) bool {
+	sheet, err := readExportedSheet(jsonStr)
+	return err == nil && strings.Contains(sheet.Meta.App, texturePackerApp)
+}
+
+// SpriteSheetImage returns the image file name that the exported sprite sheet
+// JSON references, this is typically relative to the JSON file
+func SpriteSheetImage(jsonStr string) string {
+	sheet, err := readExportedSheet(jsonStr)
+	if err != nil {
+		return ""
+	}
+	return sheet.Meta.Image
+}
+
+// ConvertAsepriteSheet converts a JSON file exported from Aseprite (either
+// the hash or array frame layouts) into the sprite sheet JSON format that is
+// read by ReadSpriteSheetData. Frame tags become clips, the tag direction
+// selects the animation mode, and the user data of cels (comma separated)
+// become the frame events for any clip containing that frame.
+func ConvertAsepriteSheet(jsonStr string) (string, error) {
+	sheet, err := readExportedSheet(jsonStr)
+	if err != nil {
+		return "", err
+	}
+	if !strings.Contains(sheet.Meta.App, asepriteApp) {
+		return "", ErrUnknownSpriteSheetFormat
+	}
+	frames, err := readOrderedFrames(sheet.Frames)
+	if err != nil {
+		return "", err
+	}
+	out := newConvertedSheetData(frames)
+	events := map[int][]string{}
+	for _, l := range sheet.Meta.Layers {
+		for _, c := range l.Cels {
+			for _, name := range strings.Split(c.Data, ",") {
+				if name = strings.TrimSpace(name); name != "" {
+					events[c.Frame] = append(events[c.Frame], name)
+				}
+			}
+		}
+	}
+	if len(sheet.Meta.FrameTags) == 0 {
+		sheet.Meta.FrameTags = []asepriteFrameTag{{
+			Name: strings.TrimSuffix(sheet.Meta.Image, filepath.Ext(sheet.Meta.Image)),
+			From: 0,
+			To:   len(frames) - 1,
+		}}
+	}
+	for _, tag := range sheet.Meta.FrameTags {
+		if tag.From < 0 || tag.To >= len(frames) || tag.From > tag.To {
+			return "", fmt.Errorf("the frame tag '%s' has an invalid frame range [%d, %d]",
+				tag.Name, tag.From, tag.To)
+		}
+		clip := spriteSheetClipData{Frames: make([]string, 0, tag.To-tag.From+1)}
+		indexes := make([]int, 0, tag.To-tag.From+1)
+		for i := tag.From; i <= tag.To; i++ {
+			indexes = append(indexes, i)
+		}
+		switch tag.Direction {
+		case "reverse":
+			slices.Reverse(indexes)
+		case "pingpong":
+			clip.Mode = AnimationModePingPong.String()
+		case "pingpong_reverse":
+			slices.Reverse(indexes)
+			clip.Mode = AnimationModePingPong.String()
+		}
+		if tag.Repeat == "1" {
+			clip.Mode = AnimationModeOneShot.String()
+		}
+		for i, idx := range indexes {
+			clip.Frames = append(clip.Frames, frames[idx].name)
+			for _, e := range events[idx] {
+				clip.Events = append(clip.Events, spriteSheetFrameEvent{i, e})
+			}
+		}
+		out.Clips[tag.Name] = clip
+	}
+	return encodeConvertedSheet(out)
+}
+
+// ConvertTexturePackerSheet converts a JSON file exported from TexturePacker
+// (either the hash or array frame layouts) into the sprite sheet JSON format
+// that is read by ReadSpriteSheetData. If the export contains animations
+// they become the clips, otherwise frames are grouped into clips by their
+// name with the trailing number being used as the frame order
+// (walk_0001.png, walk_0002.png, ...).
+func ConvertTexturePackerSheet(jsonStr string) (string, error) {
+	sheet, err := readExportedSheet(jsonStr)
+	if err != nil {
+		return "", err
+	}
+	if !strings.Contains(sheet.Meta.App, texturePackerApp) {
+		return "", ErrUnknownSpriteSheetFormat
+	}
+	frames, err := readOrderedFrames(sheet.Frames)
+	if err != nil {
+		return "", err
+	}
+	out := newConvertedSheetData(frames)
+	if len(sheet.Animations) > 0 {
+		for name, names := range sheet.Animations {
+			for _, n := range names {
+				if _, ok := out.Frames[n]; !ok {
+					return "", fmt.Errorf("the animation '%s' references the missing frame '%s'", name, n)
+				}
+			}
+			out.Clips[name] = spriteSheetClipData{Frames: names}
+		}
+		return encodeConvertedSheet(out)
+	}
+	type numberedFrame struct {
+		number int
+		name   string
+	}
+	groups := map[string][]numberedFrame{}
+	for _, f := range frames {
+		clipName, number := splitFrameNumber(f.name)
+		groups[clipName] = append(groups[clipName], numberedFrame{number, f.name})
+	}
+	for clipName, g := range groups {
+		slices.SortStableFunc(g, func(a, b numberedFrame) int { return a.number - b.number })
+		clip := spriteSheetClipData{Frames: make([]string, len(g))}
+		for i := range g {
+			clip.Frames[i] = g[i].name
+		}
+		out.Clips[clipName] = clip
+	}
+	return encodeConvertedSheet(out)
+}
+
+func readExportedSheet(jsonStr string) (exportedSheet, error) {
+	var sheet exportedSheet
+	err := klib.JsonDecode(json.NewDecoder(strings.NewReader(jsonStr)), &sheet)
+	return sheet, err
+}
+
+// readOrderedFrames reads the frames in the order they appear in the file,
+// the order matters for Aseprite as the frame tags are index based
+func readOrderedFrames(raw json.RawMessage) ([]namedSheetFrame, error) {
+	raw = bytes.TrimSpace(raw)
+	if len(raw) == 0 {
+		return nil, errors.New("the sprite sheet has no frames")
+	}
+	frames := []namedSheetFrame{}
+	if raw[0] == '[' {
+		var list []struct {
+			spriteSheetFrameData
+			Filename string `json:"filename"`
+		}
+		if err := json.Unmarshal(raw, &list); err != nil {
+			return nil, err
+		}
+		for i := range list {
+			frames = append(frames, namedSheetFrame{list[i].Filename, list[i].spriteSheetFrameData})
+		}
+		return frames, nil
+	}
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	if _, err := dec.Token(); err != nil {
+		return nil, err
+	}
+	for dec.More() {
+		t, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		f := namedSheetFrame{name: t.(string)}
+		if err := dec.Decode(&f.data); err != nil {
+			return nil, err
+		}
+		frames = append(frames, f)
+	}
+	return frames, nil
+}
+
+func splitFrameNumber(name string) (string, int) {
+	name = strings.TrimSuffix(name, filepath.Ext(name))
+	end := len(name)
+	for end > 0 && unicode.IsDigit(rune(name[end-1])) {
+		end--
+	}
+	number, _ := strconv.Atoi(name[end:])
+	return strings.TrimRight(name[:end], "_- "), number
+}
+
+func newConvertedSheetData(frames []namedSheetFrame) spriteSheetData {
+	out := spriteSheetData{
+		Frames: make(map[string]spriteSheetFrameData, len(frames)),
+		Clips:  make(map[string]spriteSheetClipData),
+	}
+	for _, f := range frames {
+		out.Frames[f.name] = f.data
+	}
+	return out
+}
+
+func encodeConvertedSheet(data spriteSheetData) (string, error) {
+	out, err := json.Marshal(data)
+	return string(out), err
+}
@@ -0,0 +1,236 @@
+/******************************************************************************/
+/* sprite_sheet_import_test.go                                                */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package sprite
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+const testAsepriteSheet = `{
+	"frames": {
+		"hero 0.aseprite": { "frame": { "x": 0, "y": 0, "w": 16, "h": 16 }, "duration": 100 },
+		"hero 1.aseprite": { "frame": { "x": 16, "y": 0, "w": 16, "h": 16 }, "duration": 50 },
+		"hero 2.aseprite": { "frame": { "x": 32, "y": 0, "w": 16, "h": 16 }, "duration": 100 },
+		"hero 3.aseprite": { "frame": { "x": 48, "y": 0, "w": 16, "h": 16 }, "duration": 200 }
+	},
+	"meta": {
+		"app": "https://www.aseprite.org/",
+		"image": "hero.png",
+		"size": { "w": 64, "h": 16 },
+		"frameTags": [
+			{ "name": "walk", "from": 0, "to": 2, "direction": "forward" },
+			{ "name": "back", "from": 0, "to": 2, "direction": "reverse" },
+			{ "name": "idle", "from": 1, "to": 3, "direction": "pingpong" },
+			{ "name": "jump", "from": 3, "to": 3, "direction": "forward", "repeat": "1" }
+		],
+		"layers": [
+			{ "name": "body", "cels": [ { "frame": 1, "data": "step, dust" } ] },
+			{ "name": "fx", "cels": [ { "frame": 3, "data": "land" } ] }
+		]
+	}
+}`
+
+const testTexturePackerSheet = `{
+	"frames": [
+		{ "filename": "run_0002.png", "frame": { "x": 16, "y": 0, "w": 16, "h": 16 } },
+		{ "filename": "run_0001.png", "frame": { "x": 0, "y": 0, "w": 16, "h": 16 } },
+		{ "filename": "run_0010.png", "frame": { "x": 32, "y": 0, "w": 16, "h": 16 } },
+		{ "filename": "idle.png", "frame": { "x": 48, "y": 0, "w": 16, "h": 16 } }
+	],
+	"meta": {
+		"app": "https://www.codeandweb.com/texturepacker",
+		"image": "player.png"
+	}
+}`
+
+func readConvertedSheet(t *testing.T, convert func(string) (string, error), jsonStr string) spriteSheet {
+	t.Helper()
+	converted, err := convert(jsonStr)
+	if err != nil {
+		t.Fatalf("failed to convert the sheet: %v", err)
+	}
+	sheet, err := ReadSpriteSheetData(converted)
+	if err != nil {
+		t.Fatalf("failed to read the converted sheet: %v", err)
+	}
+	return sheet
+}
+
+func clipFrameXs(clip spriteSheetClip) []int {
+	xs := make([]int, len(clip.frames))
+	for i := range clip.frames {
+		xs[i] = clip.frames[i].Frame.X
+	}
+	return xs
+}
+
+func TestSpriteSheetFormatDetection(t *testing.T) {
+	if !IsAsepriteSheet(testAsepriteSheet) || IsTexturePackerSheet(testAsepriteSheet) {
+		t.Error("the Aseprite sheet was not detected")
+	}
+	if !IsTexturePackerSheet(testTexturePackerSheet) || IsAsepriteSheet(testTexturePackerSheet) {
+		t.Error("the TexturePacker sheet was not detected")
+	}
+	if img := SpriteSheetImage(testAsepriteSheet); img != "hero.png" {
+		t.Errorf("expected the image hero.png but got %s", img)
+	}
+	if _, err := ConvertAsepriteSheet(testTexturePackerSheet); err != ErrUnknownSpriteSheetFormat {
+		t.Errorf("expected ErrUnknownSpriteSheetFormat but got %v", err)
+	}
+	if _, err := ConvertTexturePackerSheet(testAsepriteSheet); err != ErrUnknownSpriteSheetFormat {
+		t.Errorf("expected ErrUnknownSpriteSheetFormat but got %v", err)
+	}
+}
+
+func TestConvertAsepriteSheet(t *testing.T) {
+	sheet := readConvertedSheet(t, ConvertAsepriteSheet, testAsepriteSheet)
+	tests := []struct {
+		clip string
+		xs   []int
+		mode AnimationMode
+	}{
+		{"walk", []int{0, 16, 32}, AnimationModeLoop},
+		{"back", []int{32, 16, 0}, AnimationModeLoop},
+		{"idle", []int{16, 32, 48}, AnimationModePingPong},
+		{"jump", []int{48}, AnimationModeOneShot},
+	}
+	for _, test := range tests {
+		clip, ok := sheet.clips[test.clip]
+		if !ok {
+			t.Fatalf("missing the clip %s", test.clip)
+		}
+		if xs := clipFrameXs(clip); !slices.Equal(xs, test.xs) {
+			t.Errorf("%s: expected the frames %v but got %v", test.clip, test.xs, xs)
+		}
+		if clip.mode != test.mode {
+			t.Errorf("%s: expected the mode %s but got %s", test.clip, test.mode, clip.mode)
+		}
+	}
+	walk := sheet.clips["walk"]
+	if walk.frames[0].Duration != 100 || walk.frames[1].Duration != 50 {
+		t.Errorf("the frame durations were not kept: %d, %d",
+			walk.frames[0].Duration, walk.frames[1].Duration)
+	}
+	// Events are placed on the clip relative frame of the tagged cel
+	if !slices.Equal(walk.events[1], []string{"step", "dust"}) {
+		t.Errorf("expected the walk events [step dust] but got %v", walk.events[1])
+	}
+	if !slices.Equal(sheet.clips["back"].events[1], []string{"step", "dust"}) {
+		t.Errorf("expected the back events [step dust] but got %v", sheet.clips["back"].events[1])
+	}
+	idle := sheet.clips["idle"]
+	if !slices.Equal(idle.events[0], []string{"step", "dust"}) || !slices.Equal(idle.events[2], []string{"land"}) {
+		t.Errorf("unexpected idle events %v", idle.events)
+	}
+}
+
+func TestConvertAsepriteSheetWithoutTags(t *testing.T) {
+	const src = `{
+		"frames": [
+			{ "filename": "a", "frame": { "x": 0, "y": 0, "w": 8, "h": 8 } },
+			{ "filename": "b", "frame": { "x": 8, "y": 0, "w": 8, "h": 8 } }
+		],
+		"meta": { "app": "http://www.aseprite.org/", "image": "coin.png" }
+	}`
+	sheet := readConvertedSheet(t, ConvertAsepriteSheet, src)
+	if xs := clipFrameXs(sheet.clips["coin"]); !slices.Equal(xs, []int{0, 8}) {
+		t.Errorf("expected the whole sheet as the coin clip but got %v", xs)
+	}
+}
+
+func TestConvertAsepriteSheetInvalidTag(t *testing.T) {
+	const src = `{
+		"frames": [ { "filename": "a", "frame": { "x": 0, "y": 0, "w": 8, "h": 8 } } ],
+		"meta": {
+			"app": "http://www.aseprite.org/",
+			"frameTags": [ { "name": "bad", "from": 0, "to": 4 } ]
+		}
+	}`
+	if _, err := ConvertAsepriteSheet(src); err == nil {
+		t.Error("expected an error for a frame tag outside of the frames")
+	}
+}
+
+func TestConvertTexturePackerSheet(t *testing.T) {
+	sheet := readConvertedSheet(t, ConvertTexturePackerSheet, testTexturePackerSheet)
+	if xs := clipFrameXs(sheet.clips["run"]); !slices.Equal(xs, []int{0, 16, 32}) {
+		t.Errorf("expected the run frames ordered by number but got %v", xs)
+	}
+	if xs := clipFrameXs(sheet.clips["idle"]); !slices.Equal(xs, []int{48}) {
+		t.Errorf("expected the single idle frame but got %v", xs)
+	}
+}
+
+func TestConvertTexturePackerAnimations(t *testing.T) {
+	const src = `{
+		"frames": {
+			"a.png": { "frame": { "x": 0, "y": 0, "w": 8, "h": 8 } },
+			"b.png": { "frame": { "x": 8, "y": 0, "w": 8, "h": 8 } }
+		},
+		"animations": { "blink": [ "b.png", "a.png", "b.png" ] },
+		"meta": { "app": "https://www.codeandweb.com/texturepacker" }
+	}`
+	sheet := readConvertedSheet(t, ConvertTexturePackerSheet, src)
+	if xs := clipFrameXs(sheet.clips["blink"]); !slices.Equal(xs, []int{8, 0, 8}) {
+		t.Errorf("expected the blink animation frames but got %v", xs)
+	}
+	missing := `{
+		"frames": { "a.png": { "frame": { "x": 0, "y": 0, "w": 8, "h": 8 } } },
+		"animations": { "blink": [ "c.png" ] },
+		"meta": { "app": "https://www.codeandweb.com/texturepacker" }
+	}`
+	if _, err := ConvertTexturePackerSheet(missing); err == nil {
+		t.Error("expected an error for an animation with a missing frame")
+	}
+}
+
+func TestReadSpriteSheetMissingClipFrame(t *testing.T) {
+	const src = `{
+		"frames": { "a": { "frame": { "x": 0, "y": 0, "w": 8, "h": 8 } } },
+		"clips": { "blink": { "frames": [ "a", "b" ] } }
+	}`
+	_, err := ReadSpriteSheetData(src)
+	if err == nil {
+		t.Fatal("expected an error for a clip with a missing frame")
+	}
+	if msg := err.Error(); !strings.Contains(msg, "blink") || !strings.Contains(msg, "'b'") {
+		t.Errorf("expected the error to name the clip and frame but got %q", msg)
+	}
+}
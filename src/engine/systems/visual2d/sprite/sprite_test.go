@@ -0,0 +1,173 @@
+/******************************************************************************/
+/* sprite_test.go                                                             */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package sprite
+
+import (
+	"kaiju/engine"
+	"kaiju/rendering"
+	"slices"
+	"testing"
+)
+
+// newTestSheetSprite creates a sprite that animates without a host, the
+// drawing is never valid so only the frame state is changed
+func newTestSheetSprite(t *testing.T, clip string) *Sprite {
+	t.Helper()
+	converted, err := ConvertAsepriteSheet(testAsepriteSheet)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sheet, err := ReadSpriteSheetData(converted)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &Sprite{
+		Entity:      engine.NewEntity(nil),
+		texture:     &rendering.Texture{Width: 64, Height: 16},
+		spriteSheet: sheet,
+		fps:         10,
+	}
+	s.SetSheetClip(clip)
+	return s
+}
+
+// playFrames steps the sprite a frame at a time and returns the frames it
+// was on after each step
+func playFrames(s *Sprite, steps int) []int {
+	frames := make([]int, 0, steps)
+	for range steps {
+		s.update(float64(s.frameDelay))
+		frames = append(frames, s.currentFrame)
+	}
+	return frames
+}
+
+func TestSpriteAnimationModes(t *testing.T) {
+	tests := []struct {
+		clip     string
+		expected []int
+	}{
+		{"walk", []int{1, 2, 0, 1, 2, 0}},
+		{"idle", []int{1, 2, 1, 0, 1, 2}},
+		{"jump", []int{0, 0, 0}},
+	}
+	for _, test := range tests {
+		s := newTestSheetSprite(t, test.clip)
+		if frames := playFrames(s, len(test.expected)); !slices.Equal(frames, test.expected) {
+			t.Errorf("%s: expected the frames %v but got %v", test.clip, test.expected, frames)
+		}
+	}
+}
+
+func TestSpriteFrameDurations(t *testing.T) {
+	s := newTestSheetSprite(t, "walk")
+	if s.frameDelay != 0.1 {
+		t.Errorf("expected the first frame to last 0.1s but got %f", s.frameDelay)
+	}
+	s.update(0.1)
+	if s.currentFrame != 1 || s.frameDelay != 0.05 {
+		t.Errorf("expected frame 1 for 0.05s but got frame %d for %fs", s.currentFrame, s.frameDelay)
+	}
+	s.update(0.04)
+	if s.currentFrame != 1 {
+		t.Errorf("expected to still be on frame 1 but got %d", s.currentFrame)
+	}
+	// Frames without a duration fall back to the frame rate
+	s.currentClip.frames[2].Duration = 0
+	s.update(0.02)
+	if s.currentFrame != 2 || s.frameDelay != 1.0/s.fps {
+		t.Errorf("expected frame 2 at the frame rate but got frame %d for %fs", s.currentFrame, s.frameDelay)
+	}
+}
+
+func TestSpriteFrameEvents(t *testing.T) {
+	s := newTestSheetSprite(t, "walk")
+	var fired []string
+	s.FrameEvent("step").Add(func() { fired = append(fired, "step") })
+	s.FrameEvent("dust").Add(func() { fired = append(fired, "dust") })
+	playFrames(s, 3)
+	if !slices.Equal(fired, []string{"step", "dust"}) {
+		t.Errorf("expected the step and dust events once but got %v", fired)
+	}
+	playFrames(s, 3)
+	if len(fired) != 4 {
+		t.Errorf("expected the events again on the next loop but got %v", fired)
+	}
+}
+
+func TestSpriteOneShotReplay(t *testing.T) {
+	s := newTestSheetSprite(t, "idle")
+	s.SetAnimationMode(AnimationModeOneShot)
+	finished := 0
+	s.OnAnimationFinished.Add(func() { finished++ })
+	playFrames(s, 5)
+	if finished != 1 || s.currentFrame != 2 {
+		t.Fatalf("expected to finish once on the last frame but finished %d times on frame %d",
+			finished, s.currentFrame)
+	}
+	s.RestartAnimation()
+	if s.currentFrame != 0 || s.paused {
+		t.Fatalf("expected the restart to play from frame 0")
+	}
+	playFrames(s, 3)
+	if finished != 2 {
+		t.Errorf("expected the restarted animation to finish again but finished %d times", finished)
+	}
+}
+
+func TestSpritePlaySheetClip(t *testing.T) {
+	s := newTestSheetSprite(t, "walk")
+	land := 0
+	s.FrameEvent("land").Add(func() { land++ })
+	s.PlaySheetClip("jump")
+	playFrames(s, 2)
+	if !s.paused || land != 1 {
+		t.Fatalf("expected the jump clip to finish after one landing, paused %v, landed %d",
+			s.paused, land)
+	}
+	// Setting the current clip again keeps it finished, playing it replays it
+	s.SetSheetClip("jump")
+	if !s.paused || land != 1 {
+		t.Errorf("expected SetSheetClip to leave the current clip alone")
+	}
+	s.PlaySheetClip("jump")
+	if s.paused || land != 2 {
+		t.Errorf("expected PlaySheetClip to replay the current clip, paused %v, landed %d",
+			s.paused, land)
+	}
+}
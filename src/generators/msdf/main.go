@@ -38,134 +38,59 @@
 package main
 
 import (
-	"bufio"
-	"encoding/binary"
-	"encoding/json"
 	"kaiju/klib"
+	"kaiju/rendering/font_processing"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"strings"
 )
 
-const binDir = "../tools/content_tools/"
-
-type Rect struct {
-	Left   float32 `json:"left"`
-	Top    float32 `json:"top"`
-	Right  float32 `json:"right"`
-	Bottom float32 `json:"bottom"`
-}
-
-type Glyph struct {
-	Unicode     int     `json:"unicode"`
-	Advance     float32 `json:"advance"`
-	PlaneBounds Rect    `json:"planeBounds"`
-	AtlasBounds Rect    `json:"atlasBounds"`
-}
-
-type Atlas struct {
-	Width  int32 `json:"width"`
-	Height int32 `json:"height"`
-}
-
-type Metrics struct {
-	EmSize             float32 `json:"emSize"`
-	LineHeight         float32 `json:"lineHeight"`
-	Ascender           float32 `json:"ascender"`
-	Descender          float32 `json:"descender"`
-	UnderlineY         float32 `json:"underlineY"`
-	UnderlineThickness float32 `json:"underlineThickness"`
+func processFile(ttfPath, outDir string, runes []rune) {
+	println("Processing", ttfPath)
+	name := strings.TrimSuffix(filepath.Base(ttfPath), filepath.Ext(ttfPath))
+	klib.Must(font_processing.GenerateFile(ttfPath, filepath.Join(outDir, name),
+		runes, font_processing.DefaultOptions()))
 }
 
-type Kerning struct {
-	Unicode1 int32   `json:"unicode1"`
-	Unicode2 int32   `json:"unicode2"`
-	Advance  float32 `json:"advance"`
-}
-
-type FontData struct {
-	Glyphs  []Glyph   `json:"glyphs"`
-	Atlas   Atlas     `json:"atlas"`
-	Metrics Metrics   `json:"metrics"`
-	Kerning []Kerning `json:"kerning"`
-}
-
-func processFile(ttfName string) {
-	println("Processing", ttfName)
-	name := filepath.Base(ttfName)
-	ttfDir := filepath.Dir(ttfName)
-	ttfFile := filepath.Join(binDir, ttfName+".ttf")
-	jsonFile := filepath.Join(ttfDir, "out", name+".json")
-	binFile := filepath.Join(ttfDir, "out", name+".bin")
-	pngFile := filepath.Join(ttfDir, "out", name+".png")
-	cmd := exec.Command(binDir+"msdf-atlas-gen.exe",
-		"-font", ttfFile,
-		"-pxrange", "4",
-		"-size", "64",
-		"-charset", filepath.Join(binDir, ttfDir, "charset.txt"),
-		"-fontname", ttfName,
-		"-type", "msdf",
-		"-format", "png",
-		"-pots",
-		"-json", jsonFile,
-		"-imageout", pngFile)
-	out := klib.MustReturn(cmd.StdoutPipe())
-	scanner := bufio.NewScanner(out)
-	klib.Must(cmd.Start())
-	for scanner.Scan() {
-		println(scanner.Text())
-	}
-	klib.Must(cmd.Wait())
-	jsonBin := klib.MustReturn(os.ReadFile(jsonFile))
-	fout := klib.MustReturn(os.Create(binFile))
-	defer fout.Close()
-	var fontData FontData
-	klib.Must(json.NewDecoder(strings.NewReader(string(jsonBin))).Decode(&fontData))
-	binary.Write(fout, binary.LittleEndian, int32(len(fontData.Glyphs)))
-	binary.Write(fout, binary.LittleEndian, fontData.Atlas.Width)
-	binary.Write(fout, binary.LittleEndian, fontData.Atlas.Height)
-	binary.Write(fout, binary.LittleEndian, fontData.Metrics.EmSize)
-	binary.Write(fout, binary.LittleEndian, fontData.Metrics.LineHeight)
-	binary.Write(fout, binary.LittleEndian, fontData.Metrics.Ascender)
-	binary.Write(fout, binary.LittleEndian, fontData.Metrics.Descender)
-	binary.Write(fout, binary.LittleEndian, fontData.Metrics.UnderlineY)
-	binary.Write(fout, binary.LittleEndian, fontData.Metrics.UnderlineThickness)
-	for _, glyph := range fontData.Glyphs {
-		binary.Write(fout, binary.LittleEndian, int32(glyph.Unicode))
-		binary.Write(fout, binary.LittleEndian, glyph.Advance)
-		binary.Write(fout, binary.LittleEndian, glyph.PlaneBounds.Left)
-		binary.Write(fout, binary.LittleEndian, glyph.PlaneBounds.Top)
-		binary.Write(fout, binary.LittleEndian, glyph.PlaneBounds.Right)
-		binary.Write(fout, binary.LittleEndian, glyph.PlaneBounds.Bottom)
-		binary.Write(fout, binary.LittleEndian, glyph.AtlasBounds.Left)
-		binary.Write(fout, binary.LittleEndian, glyph.AtlasBounds.Top)
-		binary.Write(fout, binary.LittleEndian, glyph.AtlasBounds.Right)
-		binary.Write(fout, binary.LittleEndian, glyph.AtlasBounds.Bottom)
-	}
-	os.Remove(jsonFile)
+func isFont(path string) bool {
+	ext := strings.ToLower(filepath.Ext(path))
+	return ext == ".ttf" || ext == ".otf"
 }
 
+// The first argument is the TTF/OTF file or a folder of them to convert and
+// the optional second argument is the unicode ranges to put into the atlas,
+// like "0020-007E, 00A0-00FF". The atlas files are written to an "out"
+// folder next to the fonts.
 func main() {
 	if len(os.Args) == 1 {
 		panic("Expected the first argument to be the TTF file to convert")
 	}
-	ttfName := os.Args[1]
-	dirName := filepath.Join(binDir, ttfName)
-	if s, err := os.Stat(dirName); err != nil {
+	ranges := font_processing.DefaultRanges
+	if len(os.Args) > 2 {
+		ranges = os.Args[2]
+	}
+	runes := klib.MustReturn(font_processing.ParseRanges(ranges))
+	target := os.Args[1]
+	if s, err := os.Stat(target); err != nil {
 		panic(err)
 	} else if s.IsDir() {
-		os.Mkdir(filepath.Join(dirName, "out"), os.ModePerm)
-		klib.Must(filepath.Walk(dirName, func(path string, _ os.FileInfo, err error) error {
+		outDir := filepath.Join(target, "out")
+		os.Mkdir(outDir, os.ModePerm)
+		klib.Must(filepath.Walk(target, func(path string, info os.FileInfo, err error) error {
 			if err != nil {
 				return err
 			}
-			if filepath.Ext(path) == ".ttf" {
-				processFile(strings.TrimSuffix(path, ".ttf"))
+			if info.IsDir() && path == outDir {
+				return filepath.SkipDir
+			}
+			if isFont(path) {
+				processFile(path, outDir, runes)
 			}
 			return nil
 		}))
 	} else {
-		processFile(ttfName)
+		outDir := filepath.Join(filepath.Dir(target), "out")
+		os.Mkdir(outDir, os.ModePerm)
+		processFile(target, outDir, runes)
 	}
 }
@@ -0,0 +1,115 @@
+/******************************************************************************/
+/* compiler.go                                                                */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package compiler
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// ErrMissingGlslc is returned when the glslc compiler can not be found on the
+// path or in the Vulkan SDK folder
+var ErrMissingGlslc = errors.New("failed to run glslc, make sure you have the Vulkan 'Bin' folder in your environment path")
+
+// OITSuffix is the file ending for the order independent transparency variant
+// of a fragment shader, it replaces the ".spv" ending of the output
+const OITSuffix = ".oit.spv"
+
+func glslc(args ...string) error {
+	var stderr bytes.Buffer
+	cmd := exec.Command("glslc", args...)
+	cmd.Stderr = &stderr
+	err := cmd.Start()
+	if err != nil {
+		vp := os.Getenv("VK_SDK_PATH")
+		if vp == "" {
+			return ErrMissingGlslc
+		}
+		cmd = exec.Command(filepath.Join(vp, "Bin", "glslc"), args...)
+		cmd.Stderr = &stderr
+		if err = cmd.Start(); err != nil {
+			return ErrMissingGlslc
+		}
+	}
+	if err := cmd.Wait(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return fmt.Errorf("%w: %s", err, msg)
+		}
+		return err
+	}
+	return nil
+}
+
+// HasOIT returns true if the GLSL source at the given path has a block for
+// order independent transparency, these shaders are compiled a second time
+// with OIT defined
+func HasOIT(path string) bool {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return false
+	}
+	src := string(data)
+	return strings.Contains(src, `"inc_fragment_oit_block.inl"`) ||
+		strings.Contains(src, "#ifdef OIT")
+}
+
+// Compile compiles the GLSL source at the input path to SPIR-V at the output
+// path. The flags are passed to glslc as they are, in the same way as the
+// compile flags of a shader. Sources that have a block for order independent
+// transparency will also write the OIT variant next to the output. The error
+// will contain the output of glslc when the source fails to compile.
+func Compile(in, out string, flags ...string) error {
+	args := []string{in, "-o", out}
+	for _, f := range flags {
+		args = append(args, strings.Fields(f)...)
+	}
+	if err := glslc(args...); err != nil {
+		return err
+	}
+	if HasOIT(in) {
+		args[2] = strings.TrimSuffix(out, ".spv") + OITSuffix
+		args = append(args, "-DOIT")
+		return glslc(args...)
+	}
+	return nil
+}
@@ -38,52 +38,14 @@
 package main
 
 import (
-	"bufio"
+	"errors"
 	"flag"
-	"kaiju/klib"
+	"kaiju/generators/spirv/compiler"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"strings"
 )
 
-func compile(args ...string) error {
-	cmd := exec.Command("glslc", args...)
-	outPipe := klib.MustReturn(cmd.StderrPipe())
-	scanner := bufio.NewScanner(outPipe)
-	err := cmd.Start()
-	if err != nil {
-		vp := os.Getenv("VK_SDK_PATH")
-		if vp != "" {
-			cmd = exec.Command(filepath.Join(vp, "Bin", "glslc"), args...)
-			outPipe = klib.MustReturn(cmd.StdoutPipe())
-			scanner = bufio.NewScanner(outPipe)
-			err = cmd.Start()
-		}
-		if err != nil {
-			panic("Failed to run glslc, make sure you have the Vulkan 'Bin' folder in your environment path")
-		}
-	}
-	for scanner.Scan() {
-		println(scanner.Text())
-	}
-	if err := cmd.Wait(); err != nil {
-		return err
-	}
-	println("Compiled " + args[2])
-	return nil
-}
-
-func hasOIT(path string) bool {
-	data, err := os.ReadFile(path)
-	if err != nil {
-		return false
-	}
-	src := string(data)
-	return strings.Contains(src, `"inc_fragment_oit_block.inl"`) ||
-		strings.Contains(src, "#ifdef OIT")
-}
-
 func main() {
 	fs := flag.NewFlagSet("Kaiju Spir-V compile", flag.ContinueOnError)
 	dbg := fs.Bool("d", false, "Compile the shader for debugging")
@@ -100,19 +62,17 @@ func main() {
 	if !strings.HasSuffix(*out, ".spv") {
 		outName = filepath.Join(*out, filepath.Base(*in)+".spv")
 	}
-	args := []string{*in,
-		"-o", outName,
-	}
+	var flags []string
 	if *dbg {
-		args = append(args, "-g")
-	}
-	err := compile(args...)
-	if err == nil && hasOIT(*in) {
-		args[2] = strings.TrimSuffix(args[2], ".spv") + ".oit.spv"
-		args = append(args, "-DOIT")
-		err = compile(args...)
+		flags = append(flags, "-g")
 	}
-	if err != nil {
+	err := compiler.Compile(*in, outName, flags...)
+	if errors.Is(err, compiler.ErrMissingGlslc) {
+		panic(err.Error())
+	} else if err != nil {
+		println(err.Error())
 		println("Exiting due to compile error")
+	} else {
+		println("Compiled " + outName)
 	}
 }
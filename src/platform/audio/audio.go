@@ -38,68 +38,179 @@
 package audio
 
 import (
-	"bytes"
+	"errors"
+	"io"
+	"kaiju/engine/assets"
+	"kaiju/matrix"
 	"kaiju/platform/audio/audio_system"
 	"log/slog"
-	"time"
 
 	"github.com/ebitengine/oto/v3"
 )
 
+const (
+	// OutputSampleRate is the sample rate that all audio is mixed at, clips
+	// are converted to this rate when they are imported
+	OutputSampleRate = 48000
+	// OutputChannels is the number of channels that all audio is mixed with
+	OutputChannels = 2
+)
+
 type Audio struct {
-	otoCtx  *oto.Context
-	options oto.NewContextOptions
+	otoCtx   *oto.Context
+	options  oto.NewContextOptions
+	mixer    *Mixer
+	player   *oto.Player
+	offline  *OfflineOutput
+	recorder *recorder
+	listener Listener
 }
 
-func NewAudio() (Audio, error) {
+func newAudio(sampleRate, channels int) Audio {
 	a := Audio{
 		options: oto.NewContextOptions{},
+		listener: Listener{
+			Forward: matrix.Vec3Forward(),
+			Up:      matrix.Vec3Up(),
+		},
 	}
-	a.options.SampleRate = 48000
-	a.options.ChannelCount = 2
+	a.options.SampleRate = sampleRate
+	a.options.ChannelCount = channels
 	a.options.Format = oto.FormatFloat32LE
+	a.mixer = NewMixer(sampleRate, channels)
+	return a
+}
+
+// NewAudio creates the audio and starts playing the mixer through the
+// output device of the system
+func NewAudio() (Audio, error) {
+	a := newAudio(OutputSampleRate, OutputChannels)
 	otoCtx, readyChan, err := oto.NewContext(&a.options)
 	if err != nil {
 		return Audio{}, err
 	}
 	a.otoCtx = otoCtx
 	<-readyChan
+	a.player = a.otoCtx.NewPlayer(a.mixer)
+	a.player.Play()
 	return a, nil
 }
 
-func (a *Audio) Play(wav *audio_system.Wav) {
+// Mixer returns the mixer that all sounds are played through, this will be
+// nil if the audio has not been initialized
+func (a *Audio) Mixer() *Mixer { return a.mixer }
+
+// Bus returns the mixer bus with the given name (see BusMaster, BusMusic,
+// BusSfx and BusVoice), nil if the bus doesn't exist
+func (a *Audio) Bus(name string) *Bus {
+	if a.mixer == nil {
+		return nil
+	}
+	return a.mixer.Bus(name)
+}
+
+// Listener returns the listener that spatial sounds are computed against
+func (a *Audio) Listener() Listener { return a.listener }
+
+// SetListener updates the position, orientation and velocity of the listener
+// that is used to spatialize sounds
+func (a *Audio) SetListener(listener Listener) { a.listener = listener }
+
+// NewClip converts the wav into a clip matching the output format, the clip
+// should be kept and re-used rather than calling Play with the wav each time
+func (a *Audio) NewClip(wav *audio_system.Wav) *Clip {
+	return NewClipFromWav(wav, a.options.SampleRate, a.options.ChannelCount)
+}
+
+// LoadClip loads the audio file (WAV, Ogg Vorbis, MP3, or FLAC) from the
+// asset database and converts it into a clip matching the output format
+func (a *Audio) LoadClip(assetDatabase *assets.Database, file string) (*Clip, error) {
+	return LoadClip(assetDatabase, file, a.options.SampleRate, a.options.ChannelCount)
+}
+
+// Play will play the wav once through the sfx bus. The wav is converted into
+// a clip on every call, imported audio should be loaded as a clip (see
+// Host.LoadAudioClip) and played with PlayClip instead.
+func (a *Audio) Play(wav *audio_system.Wav) VoiceHandle {
 	if wav == nil {
 		slog.Error("Wav is nil")
-		return
-	}
-	data := wav.WavData
-	// TODO:  Rather than doing this real-time, it should be a part of the
-	// import process of the asset.  This is a temporary solution.
-	if int(wav.Channels) != a.options.ChannelCount {
-		slog.Warn("Rechanneling audio, this is a temporary solution",
-			slog.Int("channels", int(wav.Channels)),
-			slog.Int("target", int(a.options.ChannelCount)))
-		data = audio_system.Rechannel(wav, int16(a.options.ChannelCount))
+		return VoiceHandle{}
 	}
-	if int(wav.SampleRate) != a.options.SampleRate {
-		slog.Warn("Resampling audio, this is a temporary solution",
-			slog.Int("sampleRate", int(wav.SampleRate)),
-			slog.Int("target", int(a.options.SampleRate)))
-		data = audio_system.Resample(wav, int32(a.options.SampleRate))
-	}
-	if wav.FormatType != audio_system.WavFormatFloat {
-		slog.Warn("Converting audio to float, this is a temporary solution",
-			slog.String("format", "Float"))
-		data = audio_system.Pcm2Float(wav)
+	return a.PlayClip(a.NewClip(wav), DefaultPlayOptions())
+}
+
+// PlayClip plays the clip through the mixer and returns a handle that can be
+// used to stop, pause, fade, etc. the voice
+func (a *Audio) PlayClip(clip *Clip, options PlayOptions) VoiceHandle {
+	if a.mixer == nil {
+		slog.Error("tried to play audio before the audio was initialized")
+		return VoiceHandle{}
 	}
-	player := a.otoCtx.NewPlayer(bytes.NewReader(data))
-	player.Play()
-	go func() {
-		for player.IsPlaying() {
-			time.Sleep(time.Millisecond)
+	return a.mixer.Play(clip, options)
+}
+
+// OpenStream opens the audio file (WAV, Ogg Vorbis, MP3, or FLAC) from the
+// asset database for streaming, this should be used for long sounds like
+// music rather than loading the whole file as a clip
+func (a *Audio) OpenStream(assetDatabase *assets.Database, file string, options StreamOptions) (*Stream, error) {
+	return NewStream(assetDatabase, file, a.options.SampleRate, a.options.ChannelCount, options)
+}
+
+// PlayStream plays the stream through the mixer and returns a handle that
+// can be used to stop, pause, fade, etc. the voice
+func (a *Audio) PlayStream(stream *Stream, options PlayOptions) VoiceHandle {
+	if a.mixer == nil {
+		slog.Error("tried to play audio before the audio was initialized")
+		if stream != nil {
+			stream.Close()
 		}
-		if err := player.Close(); err != nil {
-			slog.Error(err.Error())
+		return VoiceHandle{}
+	}
+	return a.mixer.PlayStream(stream, options)
+}
+
+// CrossFade fades out the from voice while fading in the stream over the
+// given number of seconds, typically used to transition between music tracks
+func (a *Audio) CrossFade(from VoiceHandle, stream *Stream, options PlayOptions, seconds float32) VoiceHandle {
+	if a.mixer == nil {
+		slog.Error("tried to play audio before the audio was initialized")
+		if stream != nil {
+			stream.Close()
 		}
-	}()
+		return VoiceHandle{}
+	}
+	return a.mixer.CrossFade(from, stream, options, seconds)
+}
+
+// StartRecording writes everything that is mixed from now on into a WAV
+// file, this works when playing through a device as well as offline. Any
+// recording that is already in progress is stopped first.
+func (a *Audio) StartRecording(out io.WriteSeeker) error {
+	if a.mixer == nil {
+		return errors.New("tried to record audio before the audio was initialized")
+	}
+	if err := a.StopRecording(); err != nil {
+		return err
+	}
+	w, err := NewWavWriter(out, a.options.SampleRate, a.options.ChannelCount)
+	if err != nil {
+		return err
+	}
+	// Offline audio is mixed by the caller, so it can wait for the file to
+	// be written rather than dropping samples
+	a.recorder = newRecorder(w, a.options.SampleRate, a.options.ChannelCount, a.offline != nil)
+	a.mixer.setRecorder(a.recorder)
+	return nil
+}
+
+// StopRecording stops the recording started by StartRecording and finishes
+// writing the WAV file, the output is not closed
+func (a *Audio) StopRecording() error {
+	if a.recorder == nil {
+		return nil
+	}
+	a.mixer.setRecorder(nil)
+	err := a.recorder.close()
+	a.recorder = nil
+	return err
 }
@@ -0,0 +1,167 @@
+/******************************************************************************/
+/* audio_codec.go                                                             */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package audio_codec
+
+import (
+	"errors"
+	"io"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	ExtensionOgg  = ".ogg"
+	ExtensionMp3  = ".mp3"
+	ExtensionFlac = ".flac"
+)
+
+var (
+	ErrUnsupportedFormat = errors.New("unsupported audio format")
+	ErrInvalidData       = errors.New("invalid or corrupt audio data")
+)
+
+// PCM is fully decoded audio stored as interleaved float32 samples that are
+// within the range of -1 to 1
+type PCM struct {
+	Samples    []float32
+	Channels   int
+	SampleRate int
+}
+
+// Decoder decodes a compressed audio stream one block at a time so that the
+// audio can be streamed rather than having to be fully decoded up front
+type Decoder interface {
+	Channels() int
+	SampleRate() int
+	// DecodeBlock returns the next block of interleaved samples. The slice
+	// is only valid until the next call to DecodeBlock. Once there are no
+	// more samples in the stream, io.EOF is returned.
+	DecodeBlock() ([]float32, error)
+	// Rewind moves the decoder back to the first sample of the stream
+	Rewind() error
+	// Seek moves the decoder so that the next block starts at the sample
+	// frame, only the part of the stream close to the frame is decoded
+	Seek(frame int) error
+}
+
+// IsSupported returns true if the file extension of the path is one of the
+// compressed formats that can be decoded
+func IsSupported(path string) bool {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ExtensionOgg, ExtensionMp3, ExtensionFlac:
+		return true
+	}
+	return false
+}
+
+// NewDecoder creates a decoder for the data based on the file extension of
+// the given path
+func NewDecoder(path string, data []byte) (Decoder, error) {
+	var d Decoder
+	var err error
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ExtensionOgg:
+		d, err = NewVorbisDecoder(data)
+	case ExtensionMp3:
+		d, err = NewMp3Decoder(data)
+	case ExtensionFlac:
+		d, err = NewFlacDecoder(data)
+	default:
+		return nil, ErrUnsupportedFormat
+	}
+	if err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// NewStreamDecoder creates a decoder that reads the stream from the reader
+// as it is decoded, the reader must stay open while the decoder is in use
+func NewStreamDecoder(path string, r io.ReadSeeker) (Decoder, error) {
+	var d Decoder
+	var err error
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ExtensionOgg:
+		d, err = NewVorbisStreamDecoder(r)
+	case ExtensionMp3:
+		d, err = NewMp3StreamDecoder(r)
+	case ExtensionFlac:
+		d, err = NewFlacStreamDecoder(r)
+	default:
+		return nil, ErrUnsupportedFormat
+	}
+	if err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// Decode fully decodes the data based on the file extension of the path
+func Decode(path string, data []byte) (PCM, error) {
+	d, err := NewDecoder(path, data)
+	if err != nil {
+		return PCM{}, err
+	}
+	return DecodeAll(d)
+}
+
+// DecodeAll reads all of the remaining blocks from the decoder
+func DecodeAll(d Decoder) (PCM, error) {
+	pcm := PCM{
+		Channels:   d.Channels(),
+		SampleRate: d.SampleRate(),
+	}
+	for {
+		block, err := d.DecodeBlock()
+		pcm.Samples = append(pcm.Samples, block...)
+		if err == io.EOF {
+			return pcm, nil
+		} else if err != nil {
+			return pcm, err
+		}
+	}
+}
+
+// growSlice resizes the slice to the given length, only allocating if the
+// capacity is too small. The contents of the slice are not preserved.
+func growSlice[T any](s []T, length int) []T {
+	if cap(s) < length {
+		return make([]T, length)
+	}
+	return s[:length]
+}
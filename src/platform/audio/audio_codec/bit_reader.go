@@ -0,0 +1,134 @@
+/******************************************************************************/
+/* bit_reader.go                                                              */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package audio_codec
+
+// msbBitReader reads bits starting from the most significant bit of each
+// byte, this is the bit order used by FLAC and MP3
+type msbBitReader struct {
+	data []byte
+	pos  int
+}
+
+func (r *msbBitReader) read(n int) uint64 {
+	var v uint64
+	for n > 0 {
+		idx := r.pos >> 3
+		if idx >= len(r.data) {
+			r.pos += n
+			return v << n
+		}
+		avail := 8 - (r.pos & 7)
+		take := min(avail, n)
+		b := uint64(r.data[idx]>>(avail-take)) & (1<<take - 1)
+		v = v<<take | b
+		r.pos += take
+		n -= take
+	}
+	return v
+}
+
+func (r *msbBitReader) readBool() bool { return r.read(1) == 1 }
+
+// readSigned reads a two's complement signed value of n bits
+func (r *msbBitReader) readSigned(n int) int64 {
+	if n == 0 {
+		return 0
+	}
+	v := r.read(n)
+	return int64(v<<(64-n)) >> (64 - n)
+}
+
+// readUnary counts the number of 0 bits before the next 1 bit
+func (r *msbBitReader) readUnary() int {
+	count := 0
+	for {
+		idx := r.pos >> 3
+		if idx >= len(r.data) {
+			r.pos++
+			return count
+		}
+		off := r.pos & 7
+		b := r.data[idx] << off
+		if b == 0 {
+			count += 8 - off
+			r.pos += 8 - off
+			continue
+		}
+		for b&0x80 == 0 {
+			b <<= 1
+			count++
+			r.pos++
+		}
+		r.pos++
+		return count
+	}
+}
+
+func (r *msbBitReader) alignByte()    { r.pos = (r.pos + 7) &^ 7 }
+func (r *msbBitReader) bytePos() int  { return r.pos >> 3 }
+func (r *msbBitReader) overrun() bool { return r.pos > len(r.data)*8 }
+
+// lsbBitReader reads bits starting from the least significant bit of each
+// byte, this is the bit order used by Vorbis packets
+type lsbBitReader struct {
+	data []byte
+	pos  int
+}
+
+func (r *lsbBitReader) read(n int) uint32 {
+	var v uint64
+	shift := 0
+	for n > 0 {
+		idx := r.pos >> 3
+		if idx >= len(r.data) {
+			r.pos += n
+			return uint32(v)
+		}
+		off := r.pos & 7
+		take := min(8-off, n)
+		b := uint64(r.data[idx]>>off) & (1<<take - 1)
+		v |= b << shift
+		shift += take
+		r.pos += take
+		n -= take
+	}
+	return uint32(v)
+}
+
+func (r *lsbBitReader) readBool() bool { return r.read(1) == 1 }
+func (r *lsbBitReader) overrun() bool  { return r.pos > len(r.data)*8 }
@@ -0,0 +1,354 @@
+/******************************************************************************/
+/* codec_test.go                                                              */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package audio_codec
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+// The fixtures are tiny streams that exercise most of the coding tools of
+// each format, the expected values were taken from other decoders
+const (
+	fixtureFlac   = "sine.flac"
+	fixtureMp3    = "tone.mp3"
+	fixtureVorbis = "tone.ogg"
+	// fixtureSeek is a longer FLAC stream with a seek table
+	fixtureSeek = "chirp.flac"
+)
+
+type referenceSample struct {
+	index int
+	value float64
+}
+
+func readFixture(t *testing.T, name string) []byte {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func decodeFixture(t *testing.T, name string) PCM {
+	t.Helper()
+	pcm, err := Decode(name, readFixture(t, name))
+	if err != nil {
+		t.Fatalf("failed to decode %s: %v", name, err)
+	}
+	return pcm
+}
+
+func checkFormat(t *testing.T, pcm PCM, channels, sampleRate, samples int) {
+	t.Helper()
+	if pcm.Channels != channels {
+		t.Errorf("expected %d channels but got %d", channels, pcm.Channels)
+	}
+	if pcm.SampleRate != sampleRate {
+		t.Errorf("expected a sample rate of %d but got %d", sampleRate, pcm.SampleRate)
+	}
+	if len(pcm.Samples) != samples {
+		t.Fatalf("expected %d samples but got %d", samples, len(pcm.Samples))
+	}
+}
+
+func checkReference(t *testing.T, pcm PCM, energy float64, samples []referenceSample, tolerance float64) {
+	t.Helper()
+	sum := 0.0
+	for _, s := range pcm.Samples {
+		sum += float64(s) * float64(s)
+	}
+	if math.Abs(sum-energy) > energy*1e-3 {
+		t.Errorf("expected an energy of %f but got %f", energy, sum)
+	}
+	for _, s := range samples {
+		if got := float64(pcm.Samples[s.index]); math.Abs(got-s.value) > tolerance {
+			t.Errorf("sample %d: expected %f but got %f", s.index, s.value, got)
+		}
+	}
+}
+
+func TestDecodeFlac(t *testing.T) {
+	// 4 blocks of a 440hz and 660hz sine followed by a block of silence,
+	// covering verbatim, fixed, LPC, constant and all of the stereo modes
+	const rate, sines, silence = 8000, 2048, 200
+	pcm := decodeFixture(t, fixtureFlac)
+	checkFormat(t, pcm, 2, rate, (sines+silence)*2)
+	for i := 0; i < sines+silence; i++ {
+		var left, right float64
+		if i < sines {
+			x := 2 * math.Pi * float64(i) / rate
+			left = math.RoundToEven(0.5*math.Sin(440*x)*32767) / 32768
+			right = math.RoundToEven(0.25*math.Sin(660*x)*32767) / 32768
+		}
+		if pcm.Samples[i*2] != float32(left) || pcm.Samples[i*2+1] != float32(right) {
+			t.Fatalf("frame %d: expected (%f, %f) but got (%f, %f)", i,
+				left, right, pcm.Samples[i*2], pcm.Samples[i*2+1])
+		}
+	}
+}
+
+func TestDecodeMp3(t *testing.T) {
+	// 4 MPEG-1 layer III frames using the bit reservoir and joint stereo
+	pcm := decodeFixture(t, fixtureMp3)
+	checkFormat(t, pcm, 2, 48000, 9216)
+	checkReference(t, pcm, 7.110283655114472, []referenceSample{
+		{1000, -0.013397216796875},
+		{1001, -0.001922607421875},
+		{2086, -0.0557861328125},
+		{2087, 0.00848388671875},
+		{5000, 0.0384521484375},
+		{5001, -0.03594970703125},
+		{9000, 0.00067138671875},
+		{9001, 0.02874755859375},
+	}, 1e-4)
+}
+
+func TestDecodeVorbis(t *testing.T) {
+	// Floor 1, residue 2 with coupled channels and an end granule that trims
+	// the last packet
+	pcm := decodeFixture(t, fixtureVorbis)
+	checkFormat(t, pcm, 2, 8000, 2736)
+	checkReference(t, pcm, 0.6343595153452579, []referenceSample{
+		{0, -0.0015709652798250318},
+		{1, -0.02961733750998974},
+		{300, -0.00704775657504797},
+		{301, 0.005687165539711714},
+		{1500, 0.0038954922929406166},
+		{1501, 0.009973546490073204},
+		{2734, -0.0003511081449687481},
+		{2735, 0.0008424763800576329},
+	}, 1e-4)
+}
+
+// decodeNoPanic decodes the data and fails the test rather than crashing the
+// test binary when the decoder panics
+func decodeNoPanic(t *testing.T, name string, data []byte, desc string) (pcm PCM, err error) {
+	t.Helper()
+	defer func() {
+		if r := recover(); r != nil {
+			t.Fatalf("%s %s: decoder panicked: %v", name, desc, r)
+		}
+	}()
+	return Decode(name, data)
+}
+
+func TestDecodeTruncated(t *testing.T) {
+	// Cutting into the headers is an error, past them a stream that was cut
+	// off ends at the last whole frame
+	tests := []struct {
+		name    string
+		headers int
+	}{
+		{fixtureFlac, 42},
+		{fixtureMp3, 192},
+		{fixtureVorbis, 212},
+	}
+	for _, test := range tests {
+		data := readFixture(t, test.name)
+		full := decodeFixture(t, test.name)
+		for n := 0; n < len(data); n++ {
+			pcm, err := decodeNoPanic(t, test.name, data[:n], "truncated")
+			if n < test.headers {
+				if err == nil {
+					t.Fatalf("%s truncated to %d bytes: expected an error", test.name, n)
+				}
+			} else if len(pcm.Samples) > len(full.Samples) {
+				t.Fatalf("%s truncated to %d bytes: decoded %d samples, more than the %d of the whole stream",
+					test.name, n, len(pcm.Samples), len(full.Samples))
+			}
+		}
+	}
+}
+
+func TestDecodeCorrupt(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for _, name := range []string{fixtureFlac, fixtureMp3, fixtureVorbis} {
+		data := readFixture(t, name)
+		for i := 0; i < 2000; i++ {
+			corrupt := append([]byte(nil), data...)
+			for j := 1 + r.Intn(8); j > 0; j-- {
+				corrupt[r.Intn(len(corrupt))] = byte(r.Intn(256))
+			}
+			decodeNoPanic(t, name, corrupt, "corrupted")
+		}
+	}
+}
+
+// seekFixtures are streams long enough for seeking to skip part of them
+func seekFixtures(t *testing.T) map[string][]byte {
+	t.Helper()
+	chirp := readFixture(t, fixtureSeek)
+	// Changing the seek table into padding makes seeking bisect the stream
+	noTable := bytes.Clone(chirp)
+	noTable[42] = 0x80 | 1
+	// Repeating the frames makes a stream long enough to seek past the reach
+	// of the bit reservoir
+	mp3 := bytes.Repeat(readFixture(t, fixtureMp3), 20)
+	return map[string][]byte{
+		"table.flac":  chirp,
+		"bisect.flac": noTable,
+		"repeat.mp3":  mp3,
+		"paged.ogg":   repaginateVorbis(t, readFixture(t, fixtureVorbis)),
+		fixtureFlac:   readFixture(t, fixtureFlac),
+		fixtureMp3:    readFixture(t, fixtureMp3),
+		fixtureVorbis: readFixture(t, fixtureVorbis),
+	}
+}
+
+// repaginateVorbis rewrites the stream so that each page holds two packets
+// so there are many pages to seek between
+func repaginateVorbis(t *testing.T, data []byte) []byte {
+	t.Helper()
+	d, err := NewVorbisDecoder(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := oggReader{src: newMemorySource(data)}
+	var packets [][]byte
+	for {
+		p, err := r.nextPacket()
+		if err != nil {
+			break
+		}
+		packets = append(packets, bytes.Clone(p))
+	}
+	var out []byte
+	seq := uint32(0)
+	writePage := func(flags byte, granule int64, segments []byte, body []byte) {
+		page := []byte("OggS\x00")
+		page = append(page, flags)
+		page = binary.LittleEndian.AppendUint64(page, uint64(granule))
+		page = binary.LittleEndian.AppendUint32(page, r.serial)
+		page = binary.LittleEndian.AppendUint32(page, seq)
+		page = binary.LittleEndian.AppendUint32(page, 0)
+		page = append(page, byte(len(segments)))
+		page = append(page, segments...)
+		page = append(page, body...)
+		binary.LittleEndian.PutUint32(page[22:], oggCRC(page))
+		out = append(out, page...)
+		seq++
+	}
+	lacing := func(packet []byte) []byte {
+		l := bytes.Repeat([]byte{255}, len(packet)/255)
+		return append(l, byte(len(packet)%255))
+	}
+	writePage(oggFlagBeginning, 0, lacing(packets[0]), packets[0])
+	writePage(0, 0, append(lacing(packets[1]), lacing(packets[2])...),
+		append(bytes.Clone(packets[1]), packets[2]...))
+	audio := packets[3:]
+	granules := make([]int64, len(audio))
+	prev := 0
+	for i, p := range audio {
+		size := d.packetBlockSize(p)
+		if i > 0 {
+			granules[i] = granules[i-1] + int64(prev/4+size/4)
+		}
+		prev = size
+	}
+	granules[len(granules)-1] = d.totalSamples
+	for i := 0; i < len(audio); i += 2 {
+		var segments, body []byte
+		for _, p := range audio[i:min(i+2, len(audio))] {
+			segments = append(segments, lacing(p)...)
+			body = append(body, p...)
+		}
+		writePage(0, granules[min(i+1, len(audio)-1)], segments, body)
+	}
+	return out
+}
+
+func TestStreamDecoder(t *testing.T) {
+	for name, data := range seekFixtures(t) {
+		full, err := Decode(name, data)
+		if err != nil {
+			t.Fatalf("failed to decode %s: %v", name, err)
+		}
+		d, err := NewStreamDecoder(name, bytes.NewReader(data))
+		if err != nil {
+			t.Fatalf("failed to open %s as a stream: %v", name, err)
+		}
+		pcm, err := DecodeAll(d)
+		if err != nil {
+			t.Fatalf("failed to stream %s: %v", name, err)
+		}
+		if !slices.Equal(pcm.Samples, full.Samples) {
+			t.Errorf("%s: streaming decoded %d samples that differ from the %d decoded in memory",
+				name, len(pcm.Samples), len(full.Samples))
+		}
+	}
+}
+
+func TestSeek(t *testing.T) {
+	for name, data := range seekFixtures(t) {
+		full, err := Decode(name, data)
+		if err != nil {
+			t.Fatalf("failed to decode %s: %v", name, err)
+		}
+		d, err := NewStreamDecoder(name, bytes.NewReader(data))
+		if err != nil {
+			t.Fatal(err)
+		}
+		frames := len(full.Samples) / full.Channels
+		targets := []int{frames / 2, 0, 1, 1000, frames / 3, frames - 1, frames, frames + 100}
+		for i := 1; i < 16; i++ {
+			targets = append(targets, frames*i/16+i*7)
+		}
+		for _, target := range targets {
+			if err := d.Seek(target); err != nil {
+				t.Fatalf("%s: failed to seek to %d: %v", name, target, err)
+			}
+			pcm, err := DecodeAll(d)
+			if err != nil {
+				t.Fatalf("%s: failed to decode after seeking to %d: %v", name, target, err)
+			}
+			want := full.Samples[min(target, frames)*full.Channels:]
+			if !slices.Equal(pcm.Samples, want) {
+				t.Errorf("%s: seeking to %d decoded %d samples that differ from the %d expected",
+					name, target, len(pcm.Samples), len(want))
+			}
+		}
+	}
+}
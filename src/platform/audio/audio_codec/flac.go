@@ -0,0 +1,621 @@
+/******************************************************************************/
+/* flac.go                                                                    */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package audio_codec
+
+import (
+	"bytes"
+	"errors"
+	"io"
+)
+
+const (
+	flacMetaStreamInfo = 0
+	flacMetaSeekTable  = 3
+	flacSyncCode       = 0x3FFE
+	flacMaxHeaderSize  = 16
+	flacSeekPointSize  = 18
+)
+
+var flacBlockSizes = [16]int{0, 192, 576, 1152, 2304, 4608, -8, -16,
+	256, 512, 1024, 2048, 4096, 8192, 16384, 32768}
+
+var flacSampleRates = [12]int{0, 88200, 176400, 192000, 8000, 16000,
+	22050, 24000, 32000, 44100, 48000, 96000}
+
+var flacSampleSizes = [8]int{0, 8, 12, 0, 16, 20, 24, 32}
+
+// FlacDecoder decodes a native FLAC stream (.flac) one frame at a time
+type FlacDecoder struct {
+	src           *source
+	firstFrame    int
+	offset        int
+	sampleRate    int
+	channels      int
+	bitsPerSample int
+	blockSize     int
+	maxFrameSize  int
+	seekPoints    []flacSeekPoint
+	skip          int
+	samples       [][]int32
+	out           []float32
+}
+
+type flacSeekPoint struct {
+	sample int
+	offset int
+}
+
+type flacFrameHeader struct {
+	blockSize         int
+	sampleRate        int
+	bps               int
+	channels          int
+	channelAssignment int
+	// sample is the position within the stream of the first sample
+	sample int
+}
+
+func NewFlacDecoder(data []byte) (*FlacDecoder, error) {
+	return newFlacDecoder(newMemorySource(data))
+}
+
+// NewFlacStreamDecoder creates a decoder that reads the stream from the
+// reader as it decodes rather than needing the whole stream in memory
+func NewFlacStreamDecoder(r io.ReadSeeker) (*FlacDecoder, error) {
+	src, err := newReaderSource(r)
+	if err != nil {
+		return nil, err
+	}
+	return newFlacDecoder(src)
+}
+
+func newFlacDecoder(src *source) (*FlacDecoder, error) {
+	d := &FlacDecoder{src: src}
+	if err := d.readMetadata(); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+func (d *FlacDecoder) Channels() int   { return d.channels }
+func (d *FlacDecoder) SampleRate() int { return d.sampleRate }
+
+func (d *FlacDecoder) Rewind() error {
+	d.offset = d.firstFrame
+	d.skip = 0
+	return nil
+}
+
+// Seek moves the decoder so that the next block starts at the sample frame.
+// Decoding starts from the closest seek point before the frame, or a frame
+// found by bisecting the stream, so only a few frames are decoded to reach
+// the target.
+func (d *FlacDecoder) Seek(frame int) error {
+	frame = max(0, frame)
+	offset, sample := d.firstFrame, 0
+	for _, p := range d.seekPoints {
+		if p.sample <= frame && p.sample >= sample {
+			offset, sample = d.firstFrame+p.offset, p.sample
+		}
+	}
+	for lo, hi := offset, d.src.size; hi-lo > d.maxFrameSize; {
+		mid := lo + (hi-lo)/2
+		at, s, ok := d.findFrame(mid, hi)
+		if !ok || s > frame {
+			hi = mid
+			continue
+		}
+		lo, offset, sample = at, at, s
+	}
+	d.offset = offset
+	d.skip = frame - sample
+	return nil
+}
+
+func (d *FlacDecoder) readMetadata() error {
+	head, err := d.src.at(0, 10)
+	if err != nil {
+		return err
+	}
+	// Skip over any ID3v2 tag that was put in front of the stream
+	if len(head) >= 10 && bytes.Equal(head[:3], []byte("ID3")) {
+		size := int(head[6]&0x7F)<<21 | int(head[7]&0x7F)<<14 |
+			int(head[8]&0x7F)<<7 | int(head[9]&0x7F)
+		d.offset = 10 + size
+	}
+	marker, err := d.src.at(d.offset, 4)
+	if err != nil {
+		return err
+	}
+	if string(marker) != "fLaC" {
+		return errors.New("flac: missing stream marker")
+	}
+	d.offset += 4
+	hasInfo := false
+	for last := false; !last; {
+		header, err := d.src.at(d.offset, 4)
+		if err != nil {
+			return err
+		}
+		if len(header) < 4 {
+			return ErrInvalidData
+		}
+		last = header[0]&0x80 != 0
+		blockType := header[0] & 0x7F
+		length := int(header[1])<<16 | int(header[2])<<8 | int(header[3])
+		d.offset += 4
+		if d.offset+length > d.src.size {
+			return ErrInvalidData
+		}
+		switch blockType {
+		case flacMetaStreamInfo:
+			if length < 34 {
+				return ErrInvalidData
+			}
+			info, err := d.src.at(d.offset, length)
+			if err != nil {
+				return err
+			}
+			r := msbBitReader{data: info}
+			r.read(16) // Min block size
+			d.blockSize = int(r.read(16))
+			r.read(24) // Min frame size
+			r.read(24) // Max frame size
+			d.sampleRate = int(r.read(20))
+			d.channels = int(r.read(3)) + 1
+			d.bitsPerSample = int(r.read(5)) + 1
+			hasInfo = true
+		case flacMetaSeekTable:
+			table, err := d.src.at(d.offset, length)
+			if err != nil {
+				return err
+			}
+			d.readSeekTable(table)
+		}
+		d.offset += length
+	}
+	if !hasInfo {
+		return errors.New("flac: missing stream info")
+	}
+	// The max frame size in the stream info is optional, so the bound is
+	// worked out from the largest block with residuals escaped to 32 bits
+	maxBlock := d.blockSize
+	if maxBlock < 16 {
+		maxBlock = 1 << 16
+	}
+	d.maxFrameSize = maxBlock*d.channels*4 + 1024
+	d.firstFrame = d.offset
+	return nil
+}
+
+func (d *FlacDecoder) readSeekTable(table []byte) {
+	r := msbBitReader{data: table}
+	for range len(table) / flacSeekPointSize {
+		sample := r.read(64)
+		offset := r.read(64)
+		r.read(16) // Samples in the frame
+		// Placeholder points have all of the sample bits set
+		if sample != 1<<64-1 && sample < 1<<62 && offset < uint64(d.src.size) {
+			d.seekPoints = append(d.seekPoints, flacSeekPoint{int(sample), int(offset)})
+		}
+	}
+}
+
+// readFrameHeader reads the header of the frame at the start of the reader,
+// false is returned if it isn't a valid frame header. The CRC is checked
+// when looking for frames within the stream, as audio data can look like
+// the sync code.
+func (d *FlacDecoder) readFrameHeader(r *msbBitReader, checkCRC bool) (flacFrameHeader, bool) {
+	h := flacFrameHeader{}
+	if r.read(14) != flacSyncCode {
+		return h, false
+	}
+	r.read(1) // Reserved
+	variable := r.readBool()
+	blockCode := int(r.read(4))
+	rateCode := int(r.read(4))
+	h.channelAssignment = int(r.read(4))
+	sizeCode := int(r.read(3))
+	r.read(1) // Reserved
+	// Frame or sample number is UTF-8 coded
+	number, ok := readFlacNumber(r)
+	if !ok {
+		return h, false
+	}
+	h.blockSize = flacBlockSizes[blockCode]
+	switch h.blockSize {
+	case -8:
+		h.blockSize = int(r.read(8)) + 1
+	case -16:
+		h.blockSize = int(r.read(16)) + 1
+	case 0:
+		return h, false
+	}
+	switch {
+	case rateCode == 12:
+		h.sampleRate = int(r.read(8)) * 1000
+	case rateCode == 13:
+		h.sampleRate = int(r.read(16))
+	case rateCode == 14:
+		h.sampleRate = int(r.read(16)) * 10
+	case rateCode == 15:
+		return h, false
+	case rateCode > 0:
+		h.sampleRate = flacSampleRates[rateCode]
+	}
+	h.bps = d.bitsPerSample
+	if sizeCode != 0 {
+		h.bps = flacSampleSizes[sizeCode]
+	}
+	headerEnd := r.bytePos()
+	crc := uint8(r.read(8))
+	if h.bps == 0 || h.channelAssignment > 10 || r.overrun() {
+		return h, false
+	}
+	if checkCRC && flacCRC8(r.data[:headerEnd]) != crc {
+		return h, false
+	}
+	h.channels = h.channelAssignment + 1
+	if h.channelAssignment >= 8 {
+		h.channels = 2
+	}
+	h.sample = number
+	if !variable {
+		h.sample = number * d.blockSize
+	}
+	return h, true
+}
+
+func readFlacNumber(r *msbBitReader) (int, bool) {
+	first := r.read(8)
+	length := 0
+	for mask := uint64(0x80); first&mask != 0 && mask > 0; mask >>= 1 {
+		length++
+	}
+	if length == 0 {
+		return int(first), true
+	} else if length == 1 || length > 7 {
+		return 0, false
+	}
+	v := first & (0xFF >> (length + 1))
+	for i := 1; i < length; i++ {
+		b := r.read(8)
+		if b&0xC0 != 0x80 {
+			return 0, false
+		}
+		v = v<<6 | b&0x3F
+	}
+	return int(v), v < 1<<48
+}
+
+func flacCRC8(data []byte) uint8 {
+	crc := uint8(0)
+	for _, b := range data {
+		crc ^= b
+		for range 8 {
+			if crc&0x80 != 0 {
+				crc = crc<<1 ^ 0x07
+			} else {
+				crc <<= 1
+			}
+		}
+	}
+	return crc
+}
+
+// findFrame looks for the first frame that starts between the offsets and
+// returns where it starts along with the position of its first sample
+func (d *FlacDecoder) findFrame(from, to int) (int, int, bool) {
+	for pos := from; pos < to; {
+		data, err := d.src.at(pos, sourceReadSize)
+		if err != nil || len(data) < 2 {
+			return 0, 0, false
+		}
+		atEnd := pos+len(data) >= d.src.size
+		i := 0
+		for ; i+1 < len(data) && pos+i < to; i++ {
+			if i+flacMaxHeaderSize > len(data) && !atEnd {
+				break
+			}
+			if data[i] != 0xFF || data[i+1]&0xFC != 0xF8 {
+				continue
+			}
+			r := msbBitReader{data: data[i:min(i+flacMaxHeaderSize, len(data))]}
+			if h, ok := d.readFrameHeader(&r, true); ok && h.channels == d.channels {
+				return pos + i, h.sample, true
+			}
+		}
+		if atEnd || i == 0 || pos+i >= to {
+			return 0, 0, false
+		}
+		pos += i
+	}
+	return 0, 0, false
+}
+
+// frameData returns the stream from the next frame onward, it is long enough
+// to hold the largest possible frame unless the stream ends first
+func (d *FlacDecoder) frameData() ([]byte, error) {
+	for {
+		data, err := d.src.at(d.offset, d.maxFrameSize)
+		if err != nil {
+			return nil, err
+		}
+		if len(data) <= 2 {
+			return nil, io.EOF
+		}
+		if data[0] == 0xFF && data[1]&0xFC == 0xF8 {
+			return data, nil
+		}
+		// Search for the next frame in case of garbage between frames
+		i := 1
+		for i < len(data)-1 && (data[i] != 0xFF || data[i+1]&0xFC != 0xF8) {
+			i++
+		}
+		if i >= len(data)-1 && d.offset+len(data) >= d.src.size {
+			return nil, io.EOF
+		}
+		d.offset += i
+	}
+}
+
+func (d *FlacDecoder) DecodeBlock() ([]float32, error) {
+	for {
+		data, err := d.frameData()
+		if err != nil {
+			return nil, err
+		}
+		r := msbBitReader{data: data}
+		h, ok := d.readFrameHeader(&r, false)
+		if !ok || h.channels != d.channels {
+			return nil, ErrInvalidData
+		}
+		if h.sampleRate > 0 {
+			d.sampleRate = h.sampleRate
+		}
+		for len(d.samples) < h.channels {
+			d.samples = append(d.samples, nil)
+		}
+		for ch := 0; ch < h.channels; ch++ {
+			d.samples[ch] = growSlice(d.samples[ch], h.blockSize)
+			chBps := h.bps
+			if (h.channelAssignment == 8 && ch == 1) || (h.channelAssignment == 9 && ch == 0) ||
+				(h.channelAssignment == 10 && ch == 1) {
+				chBps++ // Side channels have an extra bit
+			}
+			if err := flacDecodeSubframe(&r, d.samples[ch], chBps); err != nil {
+				if r.overrun() {
+					// The stream was cut off part way through the last frame
+					return nil, io.EOF
+				}
+				return nil, err
+			}
+		}
+		r.alignByte()
+		r.read(16) // CRC-16
+		if r.overrun() {
+			return nil, io.EOF
+		}
+		d.offset += r.bytePos()
+		if d.skip >= h.blockSize {
+			d.skip -= h.blockSize
+			continue
+		}
+		flacDecorrelate(d.samples, h.channelAssignment)
+		out := d.interleave(h.blockSize, h.channels, h.bps)
+		out = out[d.skip*h.channels:]
+		d.skip = 0
+		return out, nil
+	}
+}
+
+func (d *FlacDecoder) interleave(blockSize, channels, bps int) []float32 {
+	count := blockSize * channels
+	d.out = growSlice(d.out, count)
+	scale := 1.0 / float32(int64(1)<<(bps-1))
+	for i := 0; i < blockSize; i++ {
+		for ch := 0; ch < channels; ch++ {
+			d.out[i*channels+ch] = float32(d.samples[ch][i]) * scale
+		}
+	}
+	return d.out
+}
+
+func flacDecorrelate(samples [][]int32, assignment int) {
+	switch assignment {
+	case 8: // Left/side
+		for i := range samples[0] {
+			samples[1][i] = samples[0][i] - samples[1][i]
+		}
+	case 9: // Side/right
+		for i := range samples[0] {
+			samples[0][i] += samples[1][i]
+		}
+	case 10: // Mid/side
+		for i := range samples[0] {
+			side := samples[1][i]
+			mid := samples[0][i]<<1 | side&1
+			samples[0][i] = (mid + side) >> 1
+			samples[1][i] = (mid - side) >> 1
+		}
+	}
+}
+
+func flacDecodeSubframe(r *msbBitReader, out []int32, bps int) error {
+	if r.read(1) != 0 {
+		return ErrInvalidData
+	}
+	kind := int(r.read(6))
+	wasted := 0
+	if r.readBool() {
+		wasted = r.readUnary() + 1
+		bps -= wasted
+	}
+	switch {
+	case kind == 0:
+		v := int32(r.readSigned(bps))
+		for i := range out {
+			out[i] = v
+		}
+	case kind == 1:
+		for i := range out {
+			out[i] = int32(r.readSigned(bps))
+		}
+	case kind >= 8 && kind <= 12:
+		order := kind - 8
+		if order > len(out) {
+			return ErrInvalidData
+		}
+		for i := 0; i < order; i++ {
+			out[i] = int32(r.readSigned(bps))
+		}
+		if err := flacDecodeResidual(r, out, order); err != nil {
+			return err
+		}
+		flacRestoreFixed(out, order)
+	case kind >= 32:
+		order := kind - 31
+		if order > len(out) {
+			return ErrInvalidData
+		}
+		for i := 0; i < order; i++ {
+			out[i] = int32(r.readSigned(bps))
+		}
+		precision := int(r.read(4)) + 1
+		if precision == 16 {
+			return ErrInvalidData
+		}
+		shift := int(r.readSigned(5))
+		if shift < 0 {
+			return ErrInvalidData
+		}
+		coeffs := make([]int32, order)
+		for i := range coeffs {
+			coeffs[i] = int32(r.readSigned(precision))
+		}
+		if err := flacDecodeResidual(r, out, order); err != nil {
+			return err
+		}
+		flacRestoreLPC(out, coeffs, shift)
+	default:
+		return ErrInvalidData
+	}
+	if wasted > 0 {
+		for i := range out {
+			out[i] <<= wasted
+		}
+	}
+	return nil
+}
+
+func flacDecodeResidual(r *msbBitReader, out []int32, order int) error {
+	method := r.read(2)
+	if method > 1 {
+		return ErrInvalidData
+	}
+	paramBits, escape := 4, uint64(0xF)
+	if method == 1 {
+		paramBits, escape = 5, 0x1F
+	}
+	partitionOrder := int(r.read(4))
+	partitions := 1 << partitionOrder
+	partitionSize := len(out) >> partitionOrder
+	idx := order
+	for p := 0; p < partitions; p++ {
+		count := partitionSize
+		if p == 0 {
+			count -= order
+		}
+		if count < 0 || idx+count > len(out) {
+			return ErrInvalidData
+		}
+		param := r.read(paramBits)
+		if param == escape {
+			bits := int(r.read(5))
+			for i := 0; i < count; i++ {
+				out[idx] = int32(r.readSigned(bits))
+				idx++
+			}
+			continue
+		}
+		k := int(param)
+		for i := 0; i < count; i++ {
+			q := uint32(r.readUnary())
+			v := q<<k | uint32(r.read(k))
+			// Zig-zag decode back into a signed value
+			out[idx] = int32(v>>1) ^ -int32(v&1)
+			idx++
+		}
+		if r.overrun() {
+			return ErrInvalidData
+		}
+	}
+	return nil
+}
+
+func flacRestoreFixed(s []int32, order int) {
+	switch order {
+	case 1:
+		for i := 1; i < len(s); i++ {
+			s[i] += s[i-1]
+		}
+	case 2:
+		for i := 2; i < len(s); i++ {
+			s[i] += 2*s[i-1] - s[i-2]
+		}
+	case 3:
+		for i := 3; i < len(s); i++ {
+			s[i] += 3*s[i-1] - 3*s[i-2] + s[i-3]
+		}
+	case 4:
+		for i := 4; i < len(s); i++ {
+			s[i] += 4*s[i-1] - 6*s[i-2] + 4*s[i-3] - s[i-4]
+		}
+	}
+}
+
+func flacRestoreLPC(s []int32, coeffs []int32, shift int) {
+	order := len(coeffs)
+	for i := order; i < len(s); i++ {
+		var sum int64
+		for j, c := range coeffs {
+			sum += int64(c) * int64(s[i-j-1])
+		}
+		s[i] += int32(sum >> shift)
+	}
+}
@@ -0,0 +1,133 @@
+/******************************************************************************/
+/* imdct.go                                                                   */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package audio_codec
+
+import (
+	"math"
+	"math/cmplx"
+)
+
+// imdct computes the inverse MDCT of n/2 coefficients into n samples using
+// a DCT-IV which is itself calculated with an n/8 point complex FFT
+type imdct struct {
+	n       int
+	twiddle []complex128
+	post    []complex128
+	fft     []complex128
+	bitRev  []int
+	work    []complex128
+	dct     []float64
+}
+
+func newIMDCT(n int) *imdct {
+	m := n / 2
+	quarter := m / 2
+	t := &imdct{
+		n:       n,
+		twiddle: make([]complex128, quarter),
+		post:    make([]complex128, quarter),
+		fft:     make([]complex128, quarter/2),
+		bitRev:  make([]int, quarter),
+		work:    make([]complex128, quarter),
+		dct:     make([]float64, m),
+	}
+	for k := 0; k < quarter; k++ {
+		t.twiddle[k] = cmplx.Exp(complex(0, -math.Pi*float64(4*k+1)/float64(4*m)))
+		t.post[k] = cmplx.Exp(complex(0, -math.Pi*float64(k)/float64(m)))
+	}
+	for k := range t.fft {
+		t.fft[k] = cmplx.Exp(complex(0, -2*math.Pi*float64(k)/float64(quarter)))
+	}
+	bits := ilog(quarter) - 1
+	for i := range t.bitRev {
+		r := 0
+		for b := 0; b < bits; b++ {
+			if i&(1<<b) != 0 {
+				r |= 1 << (bits - 1 - b)
+			}
+		}
+		t.bitRev[i] = r
+	}
+	return t
+}
+
+func (t *imdct) transform(in []float32, out []float32) {
+	m := t.n / 2
+	quarter := m / 2
+	z := t.work
+	for k := 0; k < quarter; k++ {
+		v := complex(float64(in[2*k]), float64(in[m-1-2*k]))
+		z[t.bitRev[k]] = v * t.twiddle[k]
+	}
+	t.transformFFT(z)
+	for k := 0; k < quarter; k++ {
+		v := z[k] * t.post[k]
+		t.dct[2*k] = real(v)
+		t.dct[m-1-2*k] = -imag(v)
+	}
+	// Unfold the DCT-IV into the full output using its symmetries
+	q := t.n / 4
+	u := t.dct
+	for i := 0; i < q; i++ {
+		out[i] = float32(u[i+q])
+	}
+	for i := q; i < 3*q; i++ {
+		out[i] = float32(-u[3*q-1-i])
+	}
+	for i := 3 * q; i < t.n; i++ {
+		out[i] = float32(-u[i-3*q])
+	}
+}
+
+// transformFFT is an in place radix 2 FFT of the bit reversed input
+func (t *imdct) transformFFT(z []complex128) {
+	size := len(z)
+	for span := 2; span <= size; span <<= 1 {
+		half := span >> 1
+		step := size / span
+		for start := 0; start < size; start += span {
+			for k := 0; k < half; k++ {
+				w := t.fft[k*step]
+				a := z[start+k]
+				b := z[start+k+half] * w
+				z[start+k] = a + b
+				z[start+k+half] = a - b
+			}
+		}
+	}
+}
@@ -0,0 +1,74 @@
+/******************************************************************************/
+/* imdct_test.go                                                              */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package audio_codec
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func naiveIMDCT(in []float32, n int) []float64 {
+	out := make([]float64, n)
+	for i := range out {
+		for k, x := range in {
+			out[i] += float64(x) * math.Cos(2*math.Pi/float64(n)*
+				(float64(i)+0.5+float64(n)/4)*(float64(k)+0.5))
+		}
+	}
+	return out
+}
+
+func TestIMDCT(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for _, n := range []int{64, 256, 2048} {
+		in := make([]float32, n/2)
+		for i := range in {
+			in[i] = r.Float32()*2 - 1
+		}
+		out := make([]float32, n)
+		newIMDCT(n).transform(in, out)
+		expected := naiveIMDCT(in, n)
+		for i := range out {
+			if math.Abs(float64(out[i])-expected[i]) > 1e-4 {
+				t.Fatalf("size %d sample %d: expected %f but got %f",
+					n, i, expected[i], out[i])
+			}
+		}
+	}
+}
@@ -0,0 +1,1180 @@
+/******************************************************************************/
+/* mp3.go                                                                     */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package audio_codec
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"math"
+	"sync"
+)
+
+const (
+	mp3ModeJointStereo   = 1
+	mp3ModeMono          = 3
+	mp3BlockShort        = 2
+	mp3DecoderDelay      = 529
+	mp3MaxReservoir      = 511
+	mp3GranuleSamples    = 576
+	mp3SubbandSamples    = 18
+	mp3Subbands          = 32
+	mp3MixedSwitchSample = 36
+	mp3MaxFrameSize      = 1441
+	mp3SeekInterval      = 64
+	mp3SeekHistory       = 32
+)
+
+type mp3Header struct {
+	lsf           bool
+	protected     bool
+	sampleRateIdx int
+	sampleRate    int
+	channels      int
+	mode          int
+	modeExt       int
+	frameSize     int
+}
+
+type mp3Granule struct {
+	part23Length     int
+	bigValues        int
+	globalGain       int
+	scalefacCompress int
+	windowSwitching  bool
+	blockType        int
+	mixed            bool
+	tableSelect      [3]int
+	subblockGain     [3]int
+	region0Count     int
+	region1Count     int
+	preflag          int
+	scalefacScale    int
+	count1Table      int
+}
+
+func (g *mp3Granule) isShort() bool {
+	return g.windowSwitching && g.blockType == mp3BlockShort
+}
+
+type mp3SideInfo struct {
+	mainDataBegin int
+	scfsi         [2][4]bool
+	granules      [2][2]mp3Granule
+}
+
+// Mp3Decoder decodes MPEG-1, MPEG-2, and MPEG-2.5 Layer III streams (.mp3)
+// one frame at a time
+type Mp3Decoder struct {
+	src          *source
+	firstFrame   int
+	frameOffsets []int
+	offset       int
+	sampleRate   int
+	channels     int
+	lsf          bool
+	skipStart    int
+	totalSamples int
+	skip         int
+	remaining    int
+	reservoir    []byte
+	mainData     []byte
+	side         mp3SideInfo
+	values       [2][mp3GranuleSamples]int
+	nonZero      [2]int
+	xr           [2][mp3GranuleSamples]float32
+	scalefacL    [2][22]int
+	scalefacS    [2][13][3]int
+	isMaxL       [22]int
+	isMaxS       [13][3]int
+	overlap      [2][mp3Subbands][mp3SubbandSamples]float32
+	synthesis    [2][1024]float32
+	out          []float32
+}
+
+type mp3HuffmanTree []int32
+
+var (
+	mp3Tables struct {
+		once      sync.Once
+		trees     [len(mp3HuffmanCodes)]mp3HuffmanTree
+		pow43     [8207]float32
+		imdctLong [18][36]float32
+		imdctShrt [6][12]float32
+		windows   [4][36]float32
+		synthesis [64][32]float32
+		aliasCs   [8]float32
+		aliasCa   [8]float32
+	}
+)
+
+func NewMp3Decoder(data []byte) (*Mp3Decoder, error) {
+	return newMp3Decoder(newMemorySource(data))
+}
+
+// NewMp3StreamDecoder creates a decoder that reads the stream from the
+// reader as it decodes rather than needing the whole stream in memory
+func NewMp3StreamDecoder(r io.ReadSeeker) (*Mp3Decoder, error) {
+	src, err := newReaderSource(r)
+	if err != nil {
+		return nil, err
+	}
+	return newMp3Decoder(src)
+}
+
+func newMp3Decoder(src *source) (*Mp3Decoder, error) {
+	mp3Tables.once.Do(mp3InitTables)
+	d := &Mp3Decoder{src: src, totalSamples: -1}
+	head, err := src.at(0, 10)
+	if err != nil {
+		return nil, err
+	}
+	if len(head) >= 10 && bytes.Equal(head[:3], []byte("ID3")) {
+		size := int(head[6]&0x7F)<<21 | int(head[7]&0x7F)<<14 |
+			int(head[8]&0x7F)<<7 | int(head[9]&0x7F)
+		d.offset = 10 + size
+	}
+	start, h, ok := d.findFrame(d.offset)
+	if !ok {
+		return nil, errors.New("mp3: no valid frames were found")
+	}
+	d.offset = start
+	d.sampleRate = h.sampleRate
+	d.channels = h.channels
+	d.lsf = h.lsf
+	if err := d.readInfoFrame(h); err != nil {
+		return nil, err
+	}
+	d.firstFrame = d.offset
+	d.Rewind()
+	return d, nil
+}
+
+func (d *Mp3Decoder) Channels() int   { return d.channels }
+func (d *Mp3Decoder) SampleRate() int { return d.sampleRate }
+
+func (d *Mp3Decoder) Rewind() error {
+	d.offset = d.firstFrame
+	d.skip = d.skipStart
+	d.remaining = d.totalSamples
+	d.reservoir = d.reservoir[:0]
+	d.overlap = [2][mp3Subbands][mp3SubbandSamples]float32{}
+	d.synthesis = [2][1024]float32{}
+	return nil
+}
+
+// Seek moves the decoder so that the next block starts at the sample frame.
+// Decoding starts early enough that the two frames before the target, which
+// the synthesis overlaps with, have their bit reservoir data available.
+func (d *Mp3Decoder) Seek(frame int) error {
+	d.Rewind()
+	frame = max(0, frame)
+	if d.totalSamples >= 0 {
+		frame = min(frame, d.totalSamples)
+		d.remaining = d.totalSamples - frame
+	}
+	target := frame + d.skipStart
+	spf := d.samplesPerFrame()
+	history, reached := d.scanFrames(target / spf)
+	first := reached - len(history)
+	d.offset = d.firstFrame
+	if len(history) > 0 {
+		i := max(0, len(history)-2)
+		for reservoir := 0; i > 0 && reservoir < mp3MaxReservoir; i-- {
+			reservoir += history[i-1].dataSize
+		}
+		first += i
+		d.offset = history[i].offset
+	} else if reached > 0 {
+		first = reached
+		d.offset, _ = d.frameOffset(reached)
+	}
+	d.skip = target - first*spf
+	return nil
+}
+
+type mp3FramePos struct {
+	offset   int
+	dataSize int
+}
+
+// scanFrames walks the stream up to the frame and returns the frames just
+// before it along with the frame that was reached in case the stream ends
+// first
+func (d *Mp3Decoder) scanFrames(frame int) ([]mp3FramePos, int) {
+	from := max(0, frame-mp3SeekHistory)
+	offset, n := d.frameOffset(from)
+	var history []mp3FramePos
+	for n < frame {
+		start, h, ok := d.findFrame(offset)
+		if !ok {
+			break
+		}
+		size := h.frameSize - 4 - h.sideInfoSize()
+		if h.protected {
+			size -= 2
+		}
+		history = append(history, mp3FramePos{start, max(0, size)})
+		offset = start + h.frameSize
+		n++
+		d.indexFrame(n, offset)
+	}
+	return history, n
+}
+
+// frameOffset finds where the audio frame starts, counting from the first
+// audio frame, along with the frame that was reached in case the stream
+// ends first. The offset of every mp3SeekInterval frame is kept as the
+// stream is scanned so that later seeks don't scan from the start again.
+func (d *Mp3Decoder) frameOffset(frame int) (int, int) {
+	if len(d.frameOffsets) == 0 {
+		d.frameOffsets = append(d.frameOffsets, d.firstFrame)
+	}
+	idx := min(frame/mp3SeekInterval, len(d.frameOffsets)-1)
+	n := idx * mp3SeekInterval
+	offset := d.frameOffsets[idx]
+	for n < frame {
+		start, h, ok := d.findFrame(offset)
+		if !ok {
+			break
+		}
+		offset = start + h.frameSize
+		n++
+		d.indexFrame(n, offset)
+	}
+	return offset, n
+}
+
+func (d *Mp3Decoder) indexFrame(frame, offset int) {
+	if frame == len(d.frameOffsets)*mp3SeekInterval {
+		d.frameOffsets = append(d.frameOffsets, offset)
+	}
+}
+
+func (d *Mp3Decoder) samplesPerFrame() int {
+	if d.lsf {
+		return mp3GranuleSamples
+	}
+	return mp3GranuleSamples * 2
+}
+
+func parseMp3Header(b []byte) (mp3Header, bool) {
+	h := mp3Header{}
+	if len(b) < 4 || b[0] != 0xFF || b[1]&0xE0 != 0xE0 {
+		return h, false
+	}
+	version := (b[1] >> 3) & 3
+	layer := (b[1] >> 1) & 3
+	bitrateIdx := int(b[2] >> 4)
+	rateIdx := int(b[2]>>2) & 3
+	// Only Layer III is supported, free format bitrates are not supported
+	if version == 1 || layer != 1 || bitrateIdx == 0 || bitrateIdx == 15 || rateIdx == 3 {
+		return h, false
+	}
+	h.lsf = version != 3
+	h.protected = b[1]&1 == 0
+	versionIdx := 0
+	switch version {
+	case 2:
+		versionIdx = 1
+	case 0:
+		versionIdx = 2
+	}
+	h.sampleRateIdx = versionIdx*3 + rateIdx
+	h.sampleRate = mp3SampleRates[versionIdx][rateIdx]
+	h.mode = int(b[3] >> 6)
+	h.modeExt = int(b[3]>>4) & 3
+	h.channels = 2
+	if h.mode == mp3ModeMono {
+		h.channels = 1
+	}
+	padding := int(b[2]>>1) & 1
+	if h.lsf {
+		bitrate := mp3Bitrates[1][bitrateIdx] * 1000
+		h.frameSize = 72*bitrate/h.sampleRate + padding
+	} else {
+		bitrate := mp3Bitrates[0][bitrateIdx] * 1000
+		h.frameSize = 144*bitrate/h.sampleRate + padding
+	}
+	return h, true
+}
+
+func (h *mp3Header) sideInfoSize() int {
+	if h.lsf {
+		if h.channels == 1 {
+			return 9
+		}
+		return 17
+	}
+	if h.channels == 1 {
+		return 17
+	}
+	return 32
+}
+
+// findFrame searches for the next frame header starting at the offset. To
+// avoid false positives from data that happens to look like a sync word, the
+// frame that follows must also have a matching header unless this is the
+// last frame in the stream.
+func (d *Mp3Decoder) findFrame(offset int) (int, mp3Header, bool) {
+	for pos := offset; pos+4 <= d.src.size; {
+		data, err := d.src.at(pos, sourceReadSize)
+		if err != nil {
+			return 0, mp3Header{}, false
+		}
+		atEnd := pos+len(data) >= d.src.size
+		i := 0
+		for ; i+4 <= len(data); i++ {
+			// Leave room to check the header of the frame that follows
+			if i+mp3MaxFrameSize+4 > len(data) && !atEnd {
+				break
+			}
+			if data[i] != 0xFF {
+				continue
+			}
+			h, ok := parseMp3Header(data[i:])
+			if !ok || i+h.frameSize > len(data) {
+				continue
+			}
+			next := i + h.frameSize
+			if next+4 <= len(data) {
+				n, ok := parseMp3Header(data[next:])
+				if !ok || n.sampleRate != h.sampleRate || n.lsf != h.lsf {
+					continue
+				}
+			}
+			return pos + i, h, true
+		}
+		if atEnd {
+			break
+		}
+		pos += i
+	}
+	return 0, mp3Header{}, false
+}
+
+// readInfoFrame checks if the first frame is a Xing/Info or VBRI frame. These
+// frames contain no audio and are skipped. The LAME extension of the Info
+// frame provides the encoder delay and padding which are trimmed from the
+// decoded output to allow for gapless playback.
+func (d *Mp3Decoder) readInfoFrame(h mp3Header) error {
+	frame, err := d.src.at(d.offset, h.frameSize)
+	if err != nil {
+		return err
+	}
+	pos := 4 + h.sideInfoSize()
+	if h.protected {
+		pos += 2
+	}
+	if len(frame) >= 40 && string(frame[36:40]) == "VBRI" {
+		d.offset += h.frameSize
+		return nil
+	}
+	if pos+8 > len(frame) {
+		return nil
+	}
+	tag := string(frame[pos : pos+4])
+	if tag != "Xing" && tag != "Info" {
+		return nil
+	}
+	d.offset += h.frameSize
+	flags := be32(frame[pos+4:])
+	pos += 8
+	frames := -1
+	if flags&1 != 0 && pos+4 <= len(frame) {
+		frames = int(be32(frame[pos:]))
+		pos += 4
+	}
+	if flags&2 != 0 {
+		pos += 4
+	}
+	if flags&4 != 0 {
+		pos += 100
+	}
+	if flags&8 != 0 {
+		pos += 4
+	}
+	if pos+24 > len(frame) || string(frame[pos:pos+4]) != "LAME" {
+		return nil
+	}
+	delay := int(frame[pos+21])<<4 | int(frame[pos+22]>>4)
+	padding := int(frame[pos+22]&0xF)<<8 | int(frame[pos+23])
+	d.skipStart = delay + mp3DecoderDelay
+	if frames > 0 {
+		d.totalSamples = max(0, frames*d.samplesPerFrame()-delay-padding)
+	}
+	return nil
+}
+
+func be32(b []byte) uint32 {
+	return uint32(b[0])<<24 | uint32(b[1])<<16 | uint32(b[2])<<8 | uint32(b[3])
+}
+
+func (d *Mp3Decoder) DecodeBlock() ([]float32, error) {
+	for {
+		if d.remaining == 0 {
+			return nil, io.EOF
+		}
+		start, h, ok := d.findFrame(d.offset)
+		if !ok {
+			return nil, io.EOF
+		}
+		d.offset = start + h.frameSize
+		if h.channels != d.channels || h.sampleRate != d.sampleRate {
+			continue
+		}
+		frame, err := d.src.at(start, h.frameSize)
+		if err != nil {
+			return nil, err
+		}
+		if !d.decodeFrame(&h, frame) {
+			// Keep the skipped samples in step with the stream position
+			d.skip -= min(d.skip, d.samplesPerFrame())
+			continue
+		}
+		out := d.out
+		frames := len(out) / d.channels
+		if d.skip > 0 {
+			trim := min(d.skip, frames)
+			d.skip -= trim
+			out = out[trim*d.channels:]
+			frames -= trim
+		}
+		if d.remaining > 0 {
+			frames = min(frames, d.remaining)
+			d.remaining -= frames
+			out = out[:frames*d.channels]
+		}
+		if frames > 0 {
+			return out, nil
+		}
+	}
+}
+
+func (d *Mp3Decoder) decodeFrame(h *mp3Header, frame []byte) bool {
+	pos := 4
+	if h.protected {
+		pos += 2
+	}
+	sideSize := h.sideInfoSize()
+	if pos+sideSize > len(frame) {
+		return false
+	}
+	d.readSideInfo(h, frame[pos:pos+sideSize])
+	frameData := frame[pos+sideSize:]
+	// The main data may begin in the data of previous frames (the bit
+	// reservoir) so the two are joined before decoding
+	begin := d.side.mainDataBegin
+	if begin > len(d.reservoir) {
+		d.storeReservoir(frameData)
+		return false
+	}
+	d.mainData = append(d.mainData[:0], d.reservoir[len(d.reservoir)-begin:]...)
+	d.mainData = append(d.mainData, frameData...)
+	d.storeReservoir(frameData)
+	granules := 2
+	if h.lsf {
+		granules = 1
+	}
+	samples := granules * mp3GranuleSamples * h.channels
+	d.out = growSlice(d.out, samples)
+	r := msbBitReader{data: d.mainData}
+	for gr := 0; gr < granules; gr++ {
+		for ch := 0; ch < h.channels; ch++ {
+			g := &d.side.granules[gr][ch]
+			part2Start := r.pos
+			if h.lsf {
+				d.readLsfScalefactors(h, &r, g, ch)
+			} else {
+				d.readScalefactors(&r, g, gr, ch)
+			}
+			d.readHuffman(h, &r, g, ch, part2Start+g.part23Length)
+			r.pos = part2Start + g.part23Length
+		}
+		for ch := 0; ch < h.channels; ch++ {
+			d.requantize(h, &d.side.granules[gr][ch], ch)
+		}
+		if h.mode == mp3ModeJointStereo && h.channels == 2 {
+			d.stereo(h, &d.side.granules[gr][1])
+		}
+		for ch := 0; ch < h.channels; ch++ {
+			g := &d.side.granules[gr][ch]
+			d.reorder(h, g, ch)
+			d.antialias(g, ch)
+			d.hybridSynthesis(g, ch)
+			d.synthesize(ch, d.out[gr*mp3GranuleSamples*h.channels:], h.channels)
+		}
+	}
+	return true
+}
+
+func (d *Mp3Decoder) storeReservoir(frameData []byte) {
+	d.reservoir = append(d.reservoir, frameData...)
+	if len(d.reservoir) > mp3MaxReservoir {
+		n := copy(d.reservoir, d.reservoir[len(d.reservoir)-mp3MaxReservoir:])
+		d.reservoir = d.reservoir[:n]
+	}
+}
+
+func (d *Mp3Decoder) readSideInfo(h *mp3Header, data []byte) {
+	r := msbBitReader{data: data}
+	s := &d.side
+	granules := 2
+	if h.lsf {
+		granules = 1
+		s.mainDataBegin = int(r.read(8))
+		r.read(h.channels) // Private bits
+	} else {
+		s.mainDataBegin = int(r.read(9))
+		if h.channels == 1 {
+			r.read(5)
+		} else {
+			r.read(3)
+		}
+		for ch := 0; ch < h.channels; ch++ {
+			for band := 0; band < 4; band++ {
+				s.scfsi[ch][band] = r.readBool()
+			}
+		}
+	}
+	for gr := 0; gr < granules; gr++ {
+		for ch := 0; ch < h.channels; ch++ {
+			g := &s.granules[gr][ch]
+			g.part23Length = int(r.read(12))
+			g.bigValues = min(int(r.read(9)), mp3GranuleSamples/2)
+			g.globalGain = int(r.read(8))
+			if h.lsf {
+				g.scalefacCompress = int(r.read(9))
+			} else {
+				g.scalefacCompress = int(r.read(4))
+			}
+			g.windowSwitching = r.readBool()
+			if g.windowSwitching {
+				g.blockType = int(r.read(2))
+				g.mixed = r.readBool()
+				for i := 0; i < 2; i++ {
+					g.tableSelect[i] = int(r.read(5))
+				}
+				g.tableSelect[2] = 0
+				for i := 0; i < 3; i++ {
+					g.subblockGain[i] = int(r.read(3))
+				}
+				if g.blockType == mp3BlockShort && !g.mixed {
+					g.region0Count = 8
+				} else {
+					g.region0Count = 7
+				}
+				g.region1Count = 20 - g.region0Count
+			} else {
+				g.blockType = 0
+				g.mixed = false
+				for i := 0; i < 3; i++ {
+					g.tableSelect[i] = int(r.read(5))
+				}
+				g.subblockGain = [3]int{}
+				g.region0Count = int(r.read(4))
+				g.region1Count = int(r.read(3))
+			}
+			g.preflag = 0
+			if !h.lsf {
+				g.preflag = int(r.read(1))
+			}
+			g.scalefacScale = int(r.read(1))
+			g.count1Table = int(r.read(1))
+		}
+	}
+}
+
+func (d *Mp3Decoder) readScalefactors(r *msbBitReader, g *mp3Granule, gr, ch int) {
+	slen1 := mp3ScalefacLengths[0][g.scalefacCompress]
+	slen2 := mp3ScalefacLengths[1][g.scalefacCompress]
+	if g.isShort() {
+		first := 0
+		if g.mixed {
+			for sfb := 0; sfb < 8; sfb++ {
+				d.scalefacL[ch][sfb] = int(r.read(slen1))
+			}
+			first = 3
+		}
+		for sfb := first; sfb < 12; sfb++ {
+			slen := slen1
+			if sfb >= 6 {
+				slen = slen2
+			}
+			for win := 0; win < 3; win++ {
+				d.scalefacS[ch][sfb][win] = int(r.read(slen))
+			}
+		}
+		d.scalefacS[ch][12] = [3]int{}
+		return
+	}
+	groups := [5]int{0, 6, 11, 16, 21}
+	for i := 0; i < 4; i++ {
+		// Scale factors can be shared with the first granule
+		if gr == 1 && d.side.scfsi[ch][i] {
+			continue
+		}
+		slen := slen1
+		if i >= 2 {
+			slen = slen2
+		}
+		for sfb := groups[i]; sfb < groups[i+1]; sfb++ {
+			d.scalefacL[ch][sfb] = int(r.read(slen))
+		}
+	}
+	d.scalefacL[ch][21] = 0
+}
+
+func (d *Mp3Decoder) readLsfScalefactors(h *mp3Header, r *msbBitReader, g *mp3Granule, ch int) {
+	var slen [4]int
+	table := 0
+	sfc := g.scalefacCompress
+	intensity := ch == 1 && h.mode == mp3ModeJointStereo && h.modeExt&1 != 0
+	if intensity {
+		sfc >>= 1
+		switch {
+		case sfc < 180:
+			slen = [4]int{sfc / 36, (sfc % 36) / 6, (sfc % 36) % 6, 0}
+			table = 3
+		case sfc < 244:
+			sfc -= 180
+			slen = [4]int{(sfc & 63) >> 4, (sfc & 15) >> 2, sfc & 3, 0}
+			table = 4
+		default:
+			sfc -= 244
+			slen = [4]int{sfc / 3, sfc % 3, 0, 0}
+			table = 5
+		}
+	} else {
+		switch {
+		case sfc < 400:
+			slen = [4]int{(sfc >> 4) / 5, (sfc >> 4) % 5, (sfc & 15) >> 2, sfc & 3}
+		case sfc < 500:
+			sfc -= 400
+			slen = [4]int{(sfc >> 2) / 5, (sfc >> 2) % 5, sfc & 3, 0}
+			table = 1
+		default:
+			sfc -= 500
+			slen = [4]int{sfc / 3, sfc % 3, 0, 0}
+			table = 2
+			g.preflag = 1
+		}
+	}
+	block := 0
+	if g.isShort() {
+		block = 1
+		if g.mixed {
+			block = 2
+		}
+	}
+	counts := mp3LsfBandCounts[table][block]
+	// Scale factors are read in order, first any long bands then each
+	// window of the short bands
+	longBands, shortStart := 21, 12
+	if block == 1 {
+		longBands, shortStart = 0, 0
+	} else if block == 2 {
+		longBands, shortStart = 6, 3
+	}
+	d.scalefacL[ch] = [22]int{}
+	d.scalefacS[ch] = [13][3]int{}
+	slot := 0
+	for p := 0; p < 4; p++ {
+		for i := 0; i < counts[p]; i++ {
+			v := int(r.read(slen[p]))
+			maxPos := 1<<slen[p] - 1
+			if slot < longBands {
+				d.scalefacL[ch][slot] = v
+				d.isMaxL[slot] = maxPos
+			} else {
+				s := slot - longBands
+				sfb := shortStart + s/3
+				if sfb < 13 {
+					d.scalefacS[ch][sfb][s%3] = v
+					d.isMaxS[sfb][s%3] = maxPos
+				}
+			}
+			slot++
+		}
+	}
+}
+
+func (d *Mp3Decoder) readHuffman(h *mp3Header, r *msbBitReader, g *mp3Granule, ch int, end int) {
+	values := &d.values[ch]
+	*values = [mp3GranuleSamples]int{}
+	long := &mp3BandsLong[h.sampleRateIdx]
+	var region1, region2 int
+	if g.isShort() && !g.mixed {
+		region1 = mp3BandsShort[h.sampleRateIdx][3] * 3
+		region2 = mp3GranuleSamples
+	} else if g.windowSwitching {
+		region1 = long[8]
+		region2 = mp3GranuleSamples
+	} else {
+		region1 = long[min(g.region0Count+1, 22)]
+		region2 = long[min(g.region0Count+g.region1Count+2, 22)]
+	}
+	bigEnd := g.bigValues * 2
+	i := 0
+	for ; i < bigEnd; i += 2 {
+		table := g.tableSelect[2]
+		if i < region1 {
+			table = g.tableSelect[0]
+		} else if i < region2 {
+			table = g.tableSelect[1]
+		}
+		values[i], values[i+1] = mp3DecodePair(r, table)
+	}
+	tree := mp3Tables.trees[mp3HuffmanQuadA]
+	if g.count1Table == 1 {
+		tree = mp3Tables.trees[mp3HuffmanQuadB]
+	}
+	for i+4 <= mp3GranuleSamples && r.pos < end {
+		q := tree.decode(r)
+		var quad [4]int
+		for j := 0; j < 4; j++ {
+			if q&(8>>j) != 0 {
+				quad[j] = 1
+				if r.readBool() {
+					quad[j] = -1
+				}
+			}
+		}
+		// The last quad may run past the end of the data and is discarded
+		if r.pos > end {
+			break
+		}
+		copy(values[i:i+4], quad[:])
+		i += 4
+	}
+	d.nonZero[ch] = i
+}
+
+func mp3DecodePair(r *msbBitReader, table int) (int, int) {
+	code := mp3HuffmanCodeTable[table]
+	if code < 0 {
+		return 0, 0
+	}
+	v := mp3Tables.trees[code].decode(r)
+	x, y := v>>4, v&0xF
+	linBits := mp3LinBits[table]
+	if linBits > 0 && x == 15 {
+		x += int(r.read(linBits))
+	}
+	if x != 0 && r.readBool() {
+		x = -x
+	}
+	if linBits > 0 && y == 15 {
+		y += int(r.read(linBits))
+	}
+	if y != 0 && r.readBool() {
+		y = -y
+	}
+	return x, y
+}
+
+func (t mp3HuffmanTree) decode(r *msbBitReader) int {
+	node := int32(0)
+	for {
+		next := t[node*2+int32(r.read(1))]
+		if next < 0 {
+			return int(-next - 1)
+		}
+		if next == 0 || r.overrun() {
+			return 0
+		}
+		node = next
+	}
+}
+
+func buildMp3HuffmanTree(codes []uint32) mp3HuffmanTree {
+	// Nodes are stored as pairs of children, a child that is negative is a
+	// leaf holding the value and a child of 0 is unused
+	tree := mp3HuffmanTree{0, 0}
+	for _, c := range codes {
+		length := int(c >> 24)
+		code := (c >> 8) & 0xFFFF
+		value := int32(c & 0xFF)
+		node := int32(0)
+		for b := length - 1; b >= 0; b-- {
+			idx := node*2 + int32((code>>b)&1)
+			if b == 0 {
+				tree[idx] = -value - 1
+				break
+			}
+			if tree[idx] <= 0 {
+				tree[idx] = int32(len(tree) / 2)
+				tree = append(tree, 0, 0)
+			}
+			node = tree[idx]
+		}
+	}
+	return tree
+}
+
+func (d *Mp3Decoder) requantize(h *mp3Header, g *mp3Granule, ch int) {
+	values := &d.values[ch]
+	xr := &d.xr[ch]
+	long := &mp3BandsLong[h.sampleRateIdx]
+	short := &mp3BandsShort[h.sampleRateIdx]
+	multiplier := 0.5 * float64(1+g.scalefacScale)
+	gain := float64(g.globalGain - 210)
+	longEnd := mp3GranuleSamples
+	shortStart := 13
+	if g.isShort() {
+		longEnd, shortStart = 0, 0
+		if g.mixed {
+			longEnd, shortStart = short[3]*3, 3
+		}
+	}
+	for sfb := 0; sfb < 22 && long[sfb] < longEnd; sfb++ {
+		sf := float64(d.scalefacL[ch][sfb] + g.preflag*mp3Pretab[sfb])
+		scale := float32(math.Pow(2, 0.25*gain-multiplier*sf))
+		for i := long[sfb]; i < min(long[sfb+1], longEnd); i++ {
+			xr[i] = mp3Pow43(values[i]) * scale
+		}
+	}
+	for sfb := shortStart; sfb < 13; sfb++ {
+		width := short[sfb+1] - short[sfb]
+		for win := 0; win < 3; win++ {
+			sf := float64(d.scalefacS[ch][sfb][win])
+			exp := 0.25*(gain-8*float64(g.subblockGain[win])) - multiplier*sf
+			scale := float32(math.Pow(2, exp))
+			start := short[sfb]*3 + win*width
+			for i := start; i < start+width; i++ {
+				xr[i] = mp3Pow43(values[i]) * scale
+			}
+		}
+	}
+}
+
+func mp3Pow43(v int) float32 {
+	if v < 0 {
+		return -mp3Tables.pow43[min(-v, len(mp3Tables.pow43)-1)]
+	}
+	return mp3Tables.pow43[min(v, len(mp3Tables.pow43)-1)]
+}
+
+// stereo applies mid/side and intensity stereo processing, this happens
+// before the short blocks are reordered so that each window of a short
+// scale factor band is a continuous range of samples
+func (d *Mp3Decoder) stereo(h *mp3Header, g *mp3Granule) {
+	ms := h.modeExt&2 != 0
+	intensity := h.modeExt&1 != 0
+	if !ms && !intensity {
+		return
+	}
+	if !intensity {
+		d.midSide(0, mp3GranuleSamples)
+		return
+	}
+	long := &mp3BandsLong[h.sampleRateIdx]
+	short := &mp3BandsShort[h.sampleRateIdx]
+	right := &d.xr[1]
+	if g.isShort() {
+		shortStart := 0
+		if g.mixed {
+			shortStart = 3
+			if ms {
+				d.midSide(0, short[3]*3)
+			}
+		}
+		for win := 0; win < 3; win++ {
+			last := -1
+			for sfb := shortStart; sfb < 13; sfb++ {
+				width := short[sfb+1] - short[sfb]
+				start := short[sfb]*3 + win*width
+				for i := start; i < start+width; i++ {
+					if right[i] != 0 {
+						last = sfb
+						break
+					}
+				}
+			}
+			for sfb := shortStart; sfb < 13; sfb++ {
+				width := short[sfb+1] - short[sfb]
+				start := short[sfb]*3 + win*width
+				if sfb <= last {
+					if ms {
+						d.midSide(start, start+width)
+					}
+					continue
+				}
+				posBand := min(sfb, 11)
+				d.intensityBand(h, g, start, start+width, d.scalefacS[1][posBand][win],
+					d.isMaxS[posBand][win], ms)
+			}
+		}
+		return
+	}
+	for sfb := 0; sfb < 22; sfb++ {
+		start, end := long[sfb], long[sfb+1]
+		if start < d.nonZero[1] {
+			if ms {
+				d.midSide(start, end)
+			}
+			continue
+		}
+		posBand := min(sfb, 20)
+		d.intensityBand(h, g, start, end, d.scalefacL[1][posBand], d.isMaxL[posBand], ms)
+	}
+}
+
+func (d *Mp3Decoder) midSide(start, end int) {
+	const invSqrt2 = 1 / math.Sqrt2
+	left, right := &d.xr[0], &d.xr[1]
+	for i := start; i < end; i++ {
+		m, s := left[i], right[i]
+		left[i] = (m + s) * invSqrt2
+		right[i] = (m - s) * invSqrt2
+	}
+}
+
+func (d *Mp3Decoder) intensityBand(h *mp3Header, g *mp3Granule, start, end, pos, maxPos int, ms bool) {
+	var kl, kr float64
+	if h.lsf {
+		if pos == maxPos {
+			if ms {
+				d.midSide(start, end)
+			}
+			return
+		}
+		io := math.Pow(2, -0.25)
+		if g.scalefacCompress&1 != 0 {
+			io = math.Pow(2, -0.5)
+		}
+		kl, kr = 1, 1
+		if pos&1 != 0 {
+			kl = math.Pow(io, float64(pos+1)/2)
+		} else if pos > 0 {
+			kr = math.Pow(io, float64(pos)/2)
+		}
+	} else {
+		if pos >= 7 {
+			if ms {
+				d.midSide(start, end)
+			}
+			return
+		}
+		if pos == 6 {
+			kl, kr = 1, 0
+		} else {
+			ratio := math.Tan(float64(pos) * math.Pi / 12)
+			kl, kr = ratio/(1+ratio), 1/(1+ratio)
+		}
+	}
+	left, right := &d.xr[0], &d.xr[1]
+	for i := start; i < end; i++ {
+		v := left[i]
+		left[i] = v * float32(kl)
+		right[i] = v * float32(kr)
+	}
+}
+
+// reorder interleaves the windows of short blocks so that the samples for
+// each subband are grouped together for the IMDCT
+func (d *Mp3Decoder) reorder(h *mp3Header, g *mp3Granule, ch int) {
+	if !g.isShort() {
+		return
+	}
+	short := &mp3BandsShort[h.sampleRateIdx]
+	first := 0
+	if g.mixed {
+		first = 3
+	}
+	xr := &d.xr[ch]
+	var tmp [mp3GranuleSamples]float32
+	for sfb := first; sfb < 13; sfb++ {
+		start := short[sfb] * 3
+		width := short[sfb+1] - short[sfb]
+		for win := 0; win < 3; win++ {
+			for i := 0; i < width; i++ {
+				tmp[start+i*3+win] = xr[start+win*width+i]
+			}
+		}
+		copy(xr[start:start+width*3], tmp[start:start+width*3])
+	}
+}
+
+func (d *Mp3Decoder) antialias(g *mp3Granule, ch int) {
+	limit := mp3Subbands
+	if g.isShort() {
+		if !g.mixed {
+			return
+		}
+		limit = 2
+	}
+	xr := &d.xr[ch]
+	for sb := 1; sb < limit; sb++ {
+		for i := 0; i < 8; i++ {
+			lo := sb*mp3SubbandSamples - 1 - i
+			hi := sb*mp3SubbandSamples + i
+			a, b := xr[lo], xr[hi]
+			xr[lo] = a*mp3Tables.aliasCs[i] - b*mp3Tables.aliasCa[i]
+			xr[hi] = b*mp3Tables.aliasCs[i] + a*mp3Tables.aliasCa[i]
+		}
+	}
+}
+
+// hybridSynthesis runs the IMDCT for each subband and overlaps the result
+// with the previous granule, afterwards the odd time samples of the odd
+// subbands are inverted to prepare for the polyphase filter bank
+func (d *Mp3Decoder) hybridSynthesis(g *mp3Granule, ch int) {
+	xr := &d.xr[ch]
+	var raw [36]float32
+	for sb := 0; sb < mp3Subbands; sb++ {
+		in := xr[sb*mp3SubbandSamples : (sb+1)*mp3SubbandSamples]
+		blockType := 0
+		if g.windowSwitching && !(g.mixed && sb < 2) {
+			blockType = g.blockType
+		}
+		raw = [36]float32{}
+		if blockType == mp3BlockShort {
+			for win := 0; win < 3; win++ {
+				for p := 0; p < 12; p++ {
+					var sum float32
+					for m := 0; m < 6; m++ {
+						sum += in[win+3*m] * mp3Tables.imdctShrt[m][p]
+					}
+					raw[6*win+p+6] += sum * mp3Tables.windows[mp3BlockShort][p]
+				}
+			}
+		} else {
+			window := &mp3Tables.windows[blockType]
+			for p := 0; p < 36; p++ {
+				var sum float32
+				for m := 0; m < 18; m++ {
+					sum += in[m] * mp3Tables.imdctLong[m][p]
+				}
+				raw[p] = sum * window[p]
+			}
+		}
+		overlap := &d.overlap[ch][sb]
+		for i := 0; i < mp3SubbandSamples; i++ {
+			in[i] = raw[i] + overlap[i]
+			overlap[i] = raw[i+mp3SubbandSamples]
+		}
+		if sb&1 == 1 {
+			for i := 1; i < mp3SubbandSamples; i += 2 {
+				in[i] = -in[i]
+			}
+		}
+	}
+}
+
+// synthesize runs the polyphase filter bank to turn the 32 subbands of 18
+// samples back into 576 time domain samples
+func (d *Mp3Decoder) synthesize(ch int, out []float32, channels int) {
+	xr := &d.xr[ch]
+	v := &d.synthesis[ch]
+	var s [mp3Subbands]float32
+	for ss := 0; ss < mp3SubbandSamples; ss++ {
+		copy(v[64:], v[:1024-64])
+		for sb := 0; sb < mp3Subbands; sb++ {
+			s[sb] = xr[sb*mp3SubbandSamples+ss]
+		}
+		for i := 0; i < 64; i++ {
+			var sum float32
+			row := &mp3Tables.synthesis[i]
+			for k := 0; k < mp3Subbands; k++ {
+				sum += row[k] * s[k]
+			}
+			v[i] = sum
+		}
+		for j := 0; j < 32; j++ {
+			var sum float32
+			for i := 0; i < 8; i++ {
+				sum += v[i*128+j] * mp3SynthesisWindow[i*64+j]
+				sum += v[i*128+96+j] * mp3SynthesisWindow[i*64+32+j]
+			}
+			out[(ss*32+j)*channels+ch] = sum
+		}
+	}
+}
+
+func mp3InitTables() {
+	t := &mp3Tables
+	for i := range mp3HuffmanCodes {
+		t.trees[i] = buildMp3HuffmanTree(mp3HuffmanCodes[i])
+	}
+	for i := range t.pow43 {
+		t.pow43[i] = float32(math.Pow(float64(i), 4.0/3.0))
+	}
+	for m := 0; m < 18; m++ {
+		for p := 0; p < 36; p++ {
+			t.imdctLong[m][p] = float32(math.Cos(math.Pi / 72 * float64((2*p+1+18)*(2*m+1))))
+		}
+	}
+	for m := 0; m < 6; m++ {
+		for p := 0; p < 12; p++ {
+			t.imdctShrt[m][p] = float32(math.Cos(math.Pi / 24 * float64((2*p+1+6)*(2*m+1))))
+		}
+	}
+	for i := 0; i < 36; i++ {
+		t.windows[0][i] = float32(math.Sin(math.Pi / 36 * (float64(i) + 0.5)))
+	}
+	for i := 0; i < 18; i++ {
+		t.windows[1][i] = t.windows[0][i]
+		t.windows[3][i+18] = t.windows[0][i+18]
+	}
+	for i := 18; i < 24; i++ {
+		t.windows[1][i] = 1
+	}
+	for i := 24; i < 30; i++ {
+		t.windows[1][i] = float32(math.Sin(math.Pi / 12 * (float64(i-18) + 0.5)))
+	}
+	for i := 6; i < 12; i++ {
+		t.windows[3][i] = float32(math.Sin(math.Pi / 12 * (float64(i-6) + 0.5)))
+	}
+	for i := 12; i < 18; i++ {
+		t.windows[3][i] = 1
+	}
+	for i := 0; i < 12; i++ {
+		t.windows[2][i] = float32(math.Sin(math.Pi / 12 * (float64(i) + 0.5)))
+	}
+	for i := 0; i < 64; i++ {
+		for k := 0; k < 32; k++ {
+			t.synthesis[i][k] = float32(math.Cos(float64((16+i)*(2*k+1)) * math.Pi / 64))
+		}
+	}
+	for i, c := range mp3AliasCoefficients {
+		sq := math.Sqrt(1 + c*c)
+		t.aliasCs[i] = float32(1 / sq)
+		t.aliasCa[i] = float32(c / sq)
+	}
+}
@@ -0,0 +1,419 @@
+/******************************************************************************/
+/* mp3_tables.go                                                              */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package audio_codec
+
+var mp3Bitrates = [2][16]int{
+	{0, 32, 40, 48, 56, 64, 80, 96, 112, 128, 160, 192, 224, 256, 320, 0},
+	{0, 8, 16, 24, 32, 40, 48, 56, 64, 80, 96, 112, 128, 144, 160, 0},
+}
+
+var mp3SampleRates = [3][3]int{
+	{44100, 48000, 32000},
+	{22050, 24000, 16000},
+	{11025, 12000, 8000},
+}
+
+// mp3BandsLong holds the scale factor band boundaries for long blocks by
+// sample rate, ordered the same as the flattened mp3SampleRates table
+var mp3BandsLong = [9][23]int{
+	{0, 4, 8, 12, 16, 20, 24, 30, 36, 44, 52, 62, 74, 90, 110, 134, 162, 196, 238, 288, 342, 418, 576},
+	{0, 4, 8, 12, 16, 20, 24, 30, 36, 42, 50, 60, 72, 88, 106, 128, 156, 190, 230, 276, 330, 384, 576},
+	{0, 4, 8, 12, 16, 20, 24, 30, 36, 44, 54, 66, 82, 102, 126, 156, 194, 240, 296, 364, 448, 550, 576},
+	{0, 6, 12, 18, 24, 30, 36, 44, 54, 66, 80, 96, 116, 140, 168, 200, 238, 284, 336, 396, 464, 522, 576},
+	{0, 6, 12, 18, 24, 30, 36, 44, 54, 66, 80, 96, 114, 136, 162, 194, 232, 278, 332, 394, 464, 540, 576},
+	{0, 6, 12, 18, 24, 30, 36, 44, 54, 66, 80, 96, 116, 140, 168, 200, 238, 284, 336, 396, 464, 522, 576},
+	{0, 6, 12, 18, 24, 30, 36, 44, 54, 66, 80, 96, 116, 140, 168, 200, 238, 284, 336, 396, 464, 522, 576},
+	{0, 6, 12, 18, 24, 30, 36, 44, 54, 66, 80, 96, 116, 140, 168, 200, 238, 284, 336, 396, 464, 522, 576},
+	{0, 12, 24, 36, 48, 60, 72, 88, 108, 132, 160, 192, 232, 280, 336, 400, 476, 566, 568, 570, 572, 574, 576},
+}
+
+// mp3BandsShort holds the scale factor band boundaries for a single window
+// of a short block by sample rate
+var mp3BandsShort = [9][14]int{
+	{0, 4, 8, 12, 16, 22, 30, 40, 52, 66, 84, 106, 136, 192},
+	{0, 4, 8, 12, 16, 22, 28, 38, 50, 64, 80, 100, 126, 192},
+	{0, 4, 8, 12, 16, 22, 30, 42, 58, 78, 104, 138, 180, 192},
+	{0, 4, 8, 12, 18, 24, 32, 42, 56, 74, 100, 132, 174, 192},
+	{0, 4, 8, 12, 18, 26, 36, 48, 62, 80, 104, 136, 180, 192},
+	{0, 4, 8, 12, 18, 26, 36, 48, 62, 80, 104, 134, 174, 192},
+	{0, 4, 8, 12, 18, 26, 36, 48, 62, 80, 104, 134, 174, 192},
+	{0, 4, 8, 12, 18, 26, 36, 48, 62, 80, 104, 134, 174, 192},
+	{0, 8, 16, 24, 36, 52, 72, 96, 124, 160, 162, 164, 166, 192},
+}
+
+var mp3Pretab = [22]int{0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 1, 1, 1, 1, 2, 2, 3, 3, 3, 2, 0}
+
+var mp3ScalefacLengths = [2][16]int{
+	{0, 0, 0, 0, 3, 1, 1, 1, 2, 2, 2, 3, 3, 3, 4, 4},
+	{0, 1, 2, 3, 0, 1, 2, 3, 1, 2, 3, 1, 2, 3, 2, 3},
+}
+
+// mp3LsfBandCounts is the number of scale factors in each of the 4 slen
+// partitions for MPEG-2 streams, indexed by the scale factor compression
+// table and then by long, short, and mixed blocks
+var mp3LsfBandCounts = [6][3][4]int{
+	{{6, 5, 5, 5}, {9, 9, 9, 9}, {6, 9, 9, 9}},
+	{{6, 5, 7, 3}, {9, 9, 12, 6}, {6, 9, 12, 6}},
+	{{11, 10, 0, 0}, {18, 18, 0, 0}, {15, 18, 0, 0}},
+	{{7, 7, 7, 0}, {12, 12, 12, 0}, {6, 15, 12, 0}},
+	{{6, 6, 6, 3}, {12, 9, 9, 6}, {6, 12, 9, 6}},
+	{{8, 8, 5, 0}, {15, 12, 9, 0}, {6, 18, 9, 0}},
+}
+
+var mp3AliasCoefficients = [8]float64{
+	-0.6, -0.535, -0.33, -0.185, -0.095, -0.041, -0.0142, -0.0037,
+}
+
+var mp3LinBits = [32]int{0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0,
+	1, 2, 3, 4, 6, 8, 10, 13, 4, 5, 6, 7, 8, 9, 11, 13}
+
+// mp3HuffmanCodeTable maps a big value table number to the index of its
+// codes in mp3HuffmanCodes, tables with no codes are set to -1
+var mp3HuffmanCodeTable = [32]int{-1, 0, 1, 2, -1, 3, 4, 5, 6, 7, 8, 9, 10,
+	11, -1, 12, 13, 13, 13, 13, 13, 13, 13, 13, 14, 14, 14, 14, 14, 14, 14, 14}
+
+const (
+	mp3HuffmanQuadA = 15
+	mp3HuffmanQuadB = 16
+)
+
+// mp3HuffmanCodes are the Huffman code words for the big value tables and
+// the two count1 quad tables. Each entry packs the code length in the top 8
+// bits, the code word in the following 16 bits, and the decoded x/y pair (or
+// vwxy quad) in the bottom 8 bits.
+var mp3HuffmanCodes = [17][]uint32{
+	// Table 1
+	{
+		0x3000011, 0x3000101, 0x2000110, 0x1000100,
+	},
+	// Table 2
+	{
+		0x6000022, 0x6000102, 0x5000112, 0x5000221, 0x5000320, 0x3000111, 0x3000201, 0x3000310,
+		0x1000100,
+	},
+	// Table 3
+	{
+		0x6000022, 0x6000102, 0x5000112, 0x5000221, 0x5000320, 0x3000110, 0x2000111, 0x2000201,
+		0x2000300,
+	},
+	// Table 5
+	{
+		0x8000033, 0x8000123, 0x7000132, 0x6000131, 0x7000413, 0x7000503, 0x7000630, 0x7000722,
+		0x6000412, 0x6000521, 0x6000602, 0x6000720, 0x3000111, 0x3000201, 0x3000310, 0x1000100,
+	},
+	// Table 6
+	{
+		0x7000033, 0x7000103, 0x6000123, 0x6000232, 0x6000330, 0x5000213, 0x5000331, 0x5000422,
+		0x5000502, 0x4000312, 0x4000421, 0x4000520, 0x3000301, 0x2000211, 0x3000610, 0x3000700,
+	},
+	// Table 7
+	{
+		0xa000055, 0xa000145, 0xa000254, 0xa000353, 0x9000235, 0x9000344, 0x9000425, 0x9000552,
+		0x8000315, 0x8000451, 0x9000a05, 0x9000b34, 0x8000650, 0x9000e43, 0x9000f33, 0x8000824,
+		0x8000942, 0x7000514, 0x7000641, 0x7000740, 0x8001004, 0x8001123, 0x8001232, 0x8001303,
+		0x7000a13, 0x7000b31, 0x7000c30, 0x7000d22, 0x6000712, 0x5000421, 0x6000a02, 0x6000b20,
+		0x4000311, 0x3000201, 0x3000310, 0x1000100,
+	},
+	// Table 8
+	{
+		0xb000055, 0xb000154, 0xa000145, 0x9000153, 0xa000435, 0xa000544, 0x9000325, 0x9000452,
+		0x9000505, 0x8000315, 0x8000451, 0x9000a34, 0x9000b43, 0x9000c50, 0x9000d33, 0x8000724,
+		0x8000842, 0x8000914, 0x7000541, 0x8000c04, 0x8000d40, 0x8000e23, 0x8000f32, 0x8001013,
+		0x8001131, 0x8001203, 0x8001330, 0x6000522, 0x6000602, 0x6000720, 0x4000212, 0x4000321,
+		0x2000111, 0x3000401, 0x3000510, 0x2000300,
+	},
+	// Table 9
+	{
+		0x9000055, 0x9000145, 0x8000135, 0x8000253, 0x9000654, 0x9000705, 0x8000444, 0x8000525,
+		0x8000652, 0x8000715, 0x7000451, 0x7000534, 0x7000643, 0x8000e50, 0x8000f04, 0x7000824,
+		0x7000942, 0x7000a33, 0x7000b40, 0x6000614, 0x6000741, 0x6000823, 0x6000932, 0x5000513,
+		0x5000631, 0x6000e03, 0x6000f30, 0x5000822, 0x5000902, 0x4000512, 0x4000621, 0x4000720,
+		0x3000411, 0x3000501, 0x3000610, 0x3000700,
+	},
+	// Table 10
+	{
+		0xb000077, 0xb000167, 0xb000276, 0xb000357, 0xb000475, 0xb000566, 0xa000347, 0xa000474,
+		0xa000556, 0xa000665, 0xa000737, 0xa000873, 0xa000946, 0xb001455, 0xb001554, 0xa000b63,
+		0x9000627, 0x9000772, 0xa001064, 0xa001107, 0x9000970, 0x9000a62, 0xa001645, 0xa001735,
+		0x9000c06, 0xa001a53, 0xa001b44, 0x8000717, 0x8000871, 0x9001236, 0x9001326, 0xa002825,
+		0xa002952, 0x9001515, 0x9001651, 0xa002e34, 0xa002f43, 0x8000c16, 0x8000d61, 0x8000e60,
+		0x9001e05, 0x9001f50, 0x9002024, 0x9002142, 0x9002233, 0x9002304, 0x8001214, 0x8001341,
+		0x8001440, 0x8001523, 0x8001632, 0x8001703, 0x7000c13, 0x7000d31, 0x7000e30, 0x7000f22,
+		0x6000812, 0x6000921, 0x6000a02, 0x6000b20, 0x4000311, 0x3000201, 0x3000310, 0x1000100,
+	},
+	// Table 11
+	{
+		0xa000077, 0xa000167, 0xa000276, 0xa000375, 0xa000466, 0xa000547, 0xa000674, 0xb000e57,
+		0xb000f55, 0xa000856, 0xa000965, 0x9000537, 0x9000673, 0x9000746, 0xa001045, 0xa001154,
+		0xa001235, 0xa001353, 0x8000527, 0x8000672, 0x9000e64, 0x9000f07, 0x7000471, 0x8000a17,
+		0x8000b70, 0x8000c36, 0x8000d63, 0x8000e60, 0x9001e44, 0x9001f25, 0x9002052, 0x9002105,
+		0x8001115, 0x7000962, 0x8001426, 0x8001506, 0x7000b16, 0x7000c61, 0x8001a51, 0x8001b34,
+		0x8001c50, 0x9003a43, 0x9003b33, 0x8001e24, 0x8001f42, 0x8002014, 0x8002141, 0x8002204,
+		0x8002340, 0x7001223, 0x7001332, 0x6000a13, 0x6000b31, 0x7001803, 0x7001930, 0x6000d22,
+		0x5000721, 0x4000412, 0x5000a02, 0x5000b20, 0x3000311, 0x3000401, 0x3000510, 0x2000300,
+	},
+	// Table 12
+	{
+		0xa000077, 0xa000167, 0x9000176, 0x9000257, 0x9000375, 0x9000466, 0x9000547, 0x9000674,
+		0x9000765, 0x8000456, 0x8000537, 0x9000c73, 0x9000d55, 0x8000727, 0x8000872, 0x8000946,
+		0x8000a64, 0x8000b17, 0x8000c71, 0x9001a07, 0x9001b70, 0x8000e36, 0x8000f63, 0x8001045,
+		0x8001154, 0x8001244, 0x9002606, 0x9002705, 0x7000a26, 0x7000b62, 0x7000c61, 0x8001a16,
+		0x8001b60, 0x8001c35, 0x8001d53, 0x8001e25, 0x8001f52, 0x7001015, 0x7001151, 0x7001234,
+		0x7001343, 0x8002850, 0x8002904, 0x7001524, 0x7001642, 0x7001714, 0x6000c33, 0x6000d41,
+		0x6000e23, 0x6000f32, 0x7002040, 0x7002103, 0x6001130, 0x5000913, 0x5000a31, 0x5000b22,
+		0x4000612, 0x4000721, 0x5001002, 0x5001120, 0x4000900, 0x3000511, 0x3000601, 0x3000710,
+	},
+	// Table 13
+	{
+		0x130000fe, 0x130001fc, 0x120001fd, 0x110001ed, 0x100001ff, 0x100002ef, 0x100003df, 0x100004ee,
+		0x100005cf, 0x100006de, 0x100007bf, 0x100008fb, 0x100009ce, 0x10000adc, 0x110016af, 0x110017e9,
+		0xf0006ec, 0xf0007dd, 0x100010fa, 0x100011cd, 0xf0009be, 0xf000aeb, 0xf000b9f, 0xf000cf9,
+		0xf000dea, 0xf000ebd, 0xf000fdb, 0xf00108f, 0xf0011f8, 0xf0012cc, 0x100026ae, 0x1000279e,
+		0xf00148e, 0x10002a7f, 0x10002b7e, 0xe000bf7, 0xe000cda, 0xf001aad, 0xf001bbc, 0xf001ccb,
+		0xf001df6, 0xe000f6f, 0xe0010e8, 0xe00115f, 0xe00129d, 0xe0013d9, 0xe0014f5, 0xe0015e7,
+		0xe0016ac, 0xe0017bb, 0xe00184f, 0xe0019f4, 0xf0034ca, 0xf0035e6, 0xe001bf3, 0xd000e3f,
+		0xe001e8d, 0xe001fd8, 0xd00102f, 0xd0011f2, 0xe00246e, 0xe00259c, 0xd00130f, 0xe0028c9,
+		0xe00295e, 0xd0015ab, 0xe002c7d, 0xe002dd7, 0xd00174e, 0xe0030c8, 0xe0031d6, 0xd00193e,
+		0xd001ab9, 0xe00369b, 0xe0037aa, 0xc000e1f, 0xc000ff1, 0xc0010f0, 0xd0022ba, 0xd0023e5,
+		0xd0024e4, 0xd00258c, 0xd00266d, 0xd0027e3, 0xc0014e2, 0xd002a2e, 0xd002b0e, 0xc00161e,
+		0xc0017e1, 0xd0030e0, 0xd00315d, 0xd0032d5, 0xd00337c, 0xd0034c7, 0xd00354d, 0xd00368b,
+		0xd0037b8, 0xd0038d4, 0xd00399a, 0xd003aa9, 0xd003b6c, 0xc001ec6, 0xc001f3d, 0xd0040d3,
+		0xd00417b, 0xc00212d, 0xc0022d2, 0xc00231d, 0xc0024b7, 0xd004a5c, 0xd004bc5, 0xd004c99,
+		0xd004d7a, 0xc0027c3, 0xd0050a7, 0xd005197, 0xc00294b, 0xb0015d1, 0xc002c0d, 0xc002dd0,
+		0xc002e8a, 0xc002fa8, 0xc00304c, 0xc0031c4, 0xc00326b, 0xc0033b6, 0xb001a3c, 0xb001b2c,
+		0xb001cc2, 0xb001d5b, 0xc003cb5, 0xc003d89, 0xb001f1c, 0xb0020c1, 0xc004298, 0xc00430c,
+		0xb0022c0, 0xc0046b4, 0xc00476a, 0xc0048a6, 0xc004979, 0xb00253b, 0xb0026b3, 0xc004e88,
+		0xc004f5a, 0xb00282b, 0xc0052a5, 0xc005369, 0xb002aa4, 0xc005678, 0xc005787, 0xb002c94,
+		0xc005a77, 0xc005b76, 0xa0017b2, 0xa00181b, 0xa0019b1, 0xb00340b, 0xb0035b0, 0xb003696,
+		0xb00374a, 0xb00383a, 0xb0039a3, 0xb003a59, 0xb003b95, 0xa001e2a, 0xa001fa2, 0xa00201a,
+		0xa0021a1, 0xb00440a, 0xb004568, 0xa0023a0, 0xb004886, 0xb004949, 0xa002593, 0xb004c39,
+		0xb004d58, 0xb004e85, 0xb004f67, 0xa002829, 0xa002992, 0xb005457, 0xb005575, 0xa002b38,
+		0xa002c83, 0xb005a66, 0xb005b47, 0xb005c74, 0xb005d56, 0xb005e65, 0xb005f73, 0x9001819,
+		0x9001991, 0xa003409, 0xa003590, 0xa003648, 0xa003784, 0xa003872, 0xb007246, 0xb007364,
+		0x9001d28, 0x9001e82, 0x9001f18, 0xa004037, 0xa004127, 0x9002117, 0x9002271, 0xa004655,
+		0xa004707, 0xa004870, 0xa004936, 0xa004a63, 0xa004b45, 0xa004c54, 0xa004d26, 0xa004e62,
+		0xa004f35, 0x8001481, 0x9002a08, 0x9002b80, 0x9002c16, 0x9002d61, 0x9002e06, 0x9002f60,
+		0xa006053, 0xa006144, 0x9003125, 0x9003252, 0x9003305, 0x8001a15, 0x8001b51, 0x9003834,
+		0x9003943, 0x9003a50, 0x9003b24, 0x9003c42, 0x9003d33, 0x8001f14, 0x7001041, 0x8002204,
+		0x8002340, 0x8002423, 0x8002532, 0x7001313, 0x7001431, 0x7001503, 0x7001630, 0x7001722,
+		0x6000c12, 0x6000d21, 0x6000e02, 0x6000f20, 0x4000411, 0x4000501, 0x3000310, 0x1000100,
+	},
+	// Table 15
+	{
+		0xd0000ff, 0xd0001ef, 0xd0002fe, 0xd0003df, 0xc0002ee, 0xd0006fd, 0xd0007cf, 0xd0008fc,
+		0xd0009de, 0xd000aed, 0xd000bbf, 0xc0006fb, 0xd000ece, 0xd000fec, 0xc0008dd, 0xc0009af,
+		0xc000afa, 0xc000bbe, 0xc000ceb, 0xc000dcd, 0xc000edc, 0xc000f9f, 0xc0010f9, 0xc0011ea,
+		0xc0012bd, 0xc0013db, 0xc00148f, 0xc0015f8, 0xc0016cc, 0xc00179e, 0xc0018e9, 0xc00197f,
+		0xc001af7, 0xc001bad, 0xc001cda, 0xc001dbc, 0xc001e6f, 0xd003eae, 0xd003f0f, 0xb0010cb,
+		0xb0011f6, 0xc00248e, 0xc0025e8, 0xc00265f, 0xc00279d, 0xb0014f5, 0xb00157e, 0xb0016e7,
+		0xb0017ac, 0xb0018ca, 0xb0019bb, 0xc0034d9, 0xc00358d, 0xb001b4f, 0xb001cf4, 0xb001d3f,
+		0xb001ef3, 0xb001fd8, 0xb0020e6, 0xb00212f, 0xb0022f2, 0xc00466e, 0xc0047f0, 0xb00241f,
+		0xb0025f1, 0xb00269c, 0xb0027c9, 0xb00285e, 0xb0029ab, 0xb002aba, 0xb002be5, 0xb002c7d,
+		0xb002dd7, 0xb002e4e, 0xb002fe4, 0xb00308c, 0xb0031c8, 0xb00323e, 0xb00336d, 0xb0034d6,
+		0xb0035e3, 0xb00369b, 0xb0037b9, 0xb00382e, 0xb0039aa, 0xb003ae2, 0xb003b1e, 0xb003ce1,
+		0xc007a0e, 0xc007be0, 0xb003e5d, 0xb003fd5, 0xb00407c, 0xb0041c7, 0xb00424d, 0xb00438b,
+		0xa0022d4, 0xb0046b8, 0xb00479a, 0xb0048a9, 0xb00496c, 0xb004ac6, 0xb004b3d, 0xa0026d3,
+		0xa0027d2, 0xb00502d, 0xb00510d, 0xa00291d, 0xa002a7b, 0xa002bb7, 0xa002cd1, 0xb005a5c,
+		0xb005bd0, 0xa002ec5, 0xa002f8a, 0xa0030a8, 0xa00314c, 0xa0032c4, 0xa00336b, 0xa0034b6,
+		0xb006a99, 0xb006b0c, 0xa00363c, 0xa0037c3, 0xa00387a, 0xa0039a7, 0xa003aa6, 0xb0076c0,
+		0xb00770b, 0x9001ec2, 0xa003e2c, 0xa003f5b, 0xa0040b5, 0xa00411c, 0xa004289, 0xa004398,
+		0xa0044c1, 0xa00454b, 0xa0046b4, 0xa00476a, 0xa00483b, 0xa004979, 0x90025b3, 0xa004c97,
+		0xa004d88, 0xa004e2b, 0xa004f5a, 0x90028b2, 0xa0052a5, 0xa00531b, 0x9002ab1, 0xa0056b0,
+		0xa005769, 0xa005896, 0xa00594a, 0xa005aa4, 0xa005b78, 0xa005c87, 0xa005d3a, 0x9002fa3,
+		0x9003059, 0x9003195, 0x900322a, 0x90033a2, 0x900341a, 0x90035a1, 0xa006c0a, 0xa006da0,
+		0x9003768, 0x9003886, 0x9003949, 0x9003a94, 0x9003b39, 0x9003c93, 0xa007a77, 0xa007b09,
+		0x9003e58, 0x9003f85, 0x9004029, 0x9004167, 0x9004276, 0x9004392, 0x8002291, 0x9004619,
+		0x9004790, 0x9004848, 0x9004984, 0x9004a57, 0x9004b75, 0x9004c38, 0x9004d83, 0x9004e66,
+		0x9004f47, 0x8002828, 0x8002982, 0x8002a18, 0x8002b81, 0x9005874, 0x9005908, 0x9005a80,
+		0x9005b56, 0x9005c65, 0x9005d37, 0x9005e73, 0x9005f46, 0x8003027, 0x8003172, 0x8003264,
+		0x8003317, 0x8003455, 0x8003571, 0x9006c07, 0x9006d70, 0x8003736, 0x8003863, 0x8003945,
+		0x8003a54, 0x8003b26, 0x8003c62, 0x8003d16, 0x9007c06, 0x9007d60, 0x8003f35, 0x7002061,
+		0x8004253, 0x8004344, 0x7002225, 0x7002352, 0x7002415, 0x7002551, 0x8004c05, 0x8004d50,
+		0x7002734, 0x7002843, 0x7002924, 0x7002a42, 0x7002b33, 0x6001641, 0x7002e14, 0x7002f04,
+		0x6001823, 0x6001932, 0x7003440, 0x7003503, 0x6001b13, 0x6001c31, 0x6001d30, 0x5000f22,
+		0x5001012, 0x5001121, 0x5001202, 0x5001320, 0x3000511, 0x4000c01, 0x4000d10, 0x3000700,
+	},
+	// Table 16
+	{
+		0xb0000ef, 0xb0001fe, 0xb0002df, 0xb0003fd, 0xb0004cf, 0xb0005fc, 0xb0006bf, 0xb0007fb,
+		0xa0004af, 0xb000afa, 0xb000b9f, 0xb000cf9, 0xb000df8, 0xa00078f, 0xa00087f, 0xa0009f7,
+		0xa000a6f, 0xa000bf6, 0x80003ff, 0xa00105f, 0xa0011f5, 0x900094f, 0x9000af4, 0x9000bf3,
+		0x9000cf0, 0xa001a3f, 0x1006c0ce, 0x110d82ec, 0x110d83dd, 0xf0361de, 0xf0362e9, 0x1006c6ea,
+		0x1006c7d9, 0xe01b2ee, 0xf0366ed, 0xf0367eb, 0xe01b4be, 0xe01b5cd, 0xf036cdc, 0xf036ddb,
+		0xe01b7ae, 0xe01b8cc, 0xf0372ad, 0xf0373da, 0xf03747e, 0xf0375ac, 0xe01bbca, 0xf0378c9,
+		0xf03797d, 0xe01bd5e, 0xd00dfbd, 0x80007f2, 0x900102f, 0x900110f, 0x800091f, 0x8000af1,
+		0xd01609e, 0xe02c2bc, 0xe02c3cb, 0xe02c48e, 0xe02c5e8, 0xe02c69d, 0xe02c7e7, 0xe02c8bb,
+		0xe02c98d, 0xe02cad8, 0xe02cb6e, 0xd0166e6, 0xd01679c, 0xe02d0ab, 0xe02d1ba, 0xe02d2e5,
+		0xe02d3d7, 0xd016a4e, 0xe02d6e4, 0xe02d78c, 0xd016cc8, 0xd016d3e, 0xd016e6d, 0xe02ded6,
+		0xe02df9b, 0xe02e0b9, 0xe02e1aa, 0xd0171e1, 0xd0172d4, 0xe02e6b8, 0xe02e7a9, 0xd01747b,
+		0xe02eab7, 0xe02ebd0, 0xc00bbe3, 0xd01780e, 0xd0179e0, 0xd017a5d, 0xd017bd5, 0xd017c7c,
+		0xd017dc7, 0xd017e4d, 0xd017f8b, 0xd01809a, 0xd01816c, 0xd0182c6, 0xd01833d, 0xd01845c,
+		0xd0185c5, 0xc00c30d, 0xd01888a, 0xd0189a8, 0xd018a99, 0xd018b4c, 0xd018cb6, 0xd018d7a,
+		0xc00c73c, 0xd01905b, 0xd019189, 0xc00c91c, 0xc00cac0, 0xd019698, 0xd019779, 0xb0066e2,
+		0xc00ce2e, 0xc00cf1e, 0xc00d0d3, 0xc00d12d, 0xc00d2d2, 0xc00d3d1, 0xc00d43b, 0xd01aa97,
+		0xd01ab88, 0xb006b1d, 0xc00d8c4, 0xc00d96b, 0xc00dac3, 0xc00dba7, 0xb006e2c, 0xc00dec2,
+		0xc00dfb5, 0xc00e0c1, 0xc00e10c, 0xc00e24b, 0xc00e3b4, 0xc00e46a, 0xc00e5a6, 0xb0073b3,
+		0xc00e85a, 0xc00e9a5, 0xb00752b, 0xb0076b2, 0xb00771b, 0xb0078b1, 0xc00f20b, 0xc00f3b0,
+		0xc00f469, 0xc00f596, 0xc00f64a, 0xc00f7a4, 0xc00f878, 0xc00f987, 0xb007da3, 0xc00fc3a,
+		0xc00fd59, 0xb007f2a, 0xc010095, 0xc010168, 0xb0081a1, 0xc010486, 0xc010577, 0xb008394,
+		0xc010849, 0xc010957, 0xb008567, 0xa0043a2, 0xa00441a, 0xb008a0a, 0xb008ba0, 0xb008c39,
+		0xb008d93, 0xb008e58, 0xb008f85, 0xa004829, 0xa004992, 0xb009476, 0xb009509, 0xa004b19,
+		0xa004c91, 0xb009a90, 0xb009b48, 0xb009c84, 0xb009d75, 0xb009e38, 0xb009f83, 0xb00a066,
+		0xb00a128, 0xa005182, 0xb00a447, 0xb00a574, 0xa005318, 0xa005481, 0xa005580, 0xb00ac08,
+		0xb00ad56, 0xa005737, 0xa005873, 0xb00b265, 0xb00b346, 0xa005a27, 0xa005b72, 0xb00b864,
+		0xb00b955, 0xa005d07, 0x9002f17, 0x9003071, 0xa006270, 0xa006336, 0xa006463, 0xa006545,
+		0xa006654, 0xa006726, 0x9003462, 0x9003516, 0x9003661, 0xa006e06, 0xa006f60, 0x9003853,
+		0xa007235, 0xa007344, 0x9003a25, 0x9003b52, 0x8001e51, 0x9003e15, 0x9003f05, 0x9004034,
+		0x9004143, 0x9004250, 0x9004324, 0x9004442, 0x9004533, 0x8002314, 0x8002441, 0x9004a04,
+		0x9004b40, 0x8002623, 0x8002732, 0x7001413, 0x7001531, 0x8002c03, 0x8002d30, 0x7001722,
+		0x6000c12, 0x6000d21, 0x6000e02, 0x6000f20, 0x4000411, 0x4000501, 0x3000310, 0x1000100,
+	},
+	// Table 24
+	{
+		0x80000ef, 0x80001fe, 0x80002df, 0x80003fd, 0x80004cf, 0x80005fc, 0x80006bf, 0x80007fb,
+		0x70004fa, 0x8000aaf, 0x8000b9f, 0x70006f9, 0x70007f8, 0x800108f, 0x800117f, 0x70009f7,
+		0x7000a6f, 0x7000bf6, 0x7000c5f, 0x7000df5, 0x7000e4f, 0x7000ff4, 0x700103f, 0x70011f3,
+		0x700122f, 0x70013f2, 0x70014f1, 0x8002a1f, 0x8002bf0, 0x900580f, 0xb0164ee, 0xb0165de,
+		0xb0166ed, 0xb0167ce, 0xb0168ec, 0xb0169dd, 0xb016abe, 0xb016beb, 0xb016ccd, 0xb016ddc,
+		0xb016eae, 0xb016fea, 0xb0170bd, 0xb0171db, 0xb0172cc, 0xb01739e, 0xb0174e9, 0xb0175ad,
+		0xb0176da, 0xb0177bc, 0xb0178cb, 0xb01798e, 0xb017ae8, 0xb017b9d, 0xb017cd9, 0xb017d7e,
+		0xb017ee7, 0xb017fac, 0x40003ff, 0xb0200ca, 0xb0201bb, 0xb02028d, 0xb0203d8, 0xc04080e,
+		0xc0409e0, 0xb02050d, 0xa0103e6, 0xb02086e, 0xb02099c, 0xa0105c9, 0xa01065e, 0xa0107ba,
+		0xa0108e5, 0xb0212ab, 0xb02137d, 0xa010ad7, 0xa010be4, 0xa010c8c, 0xa010dc8, 0xb021c4e,
+		0xb021d2e, 0xa010f3e, 0xa01106d, 0xa0111d6, 0xa0112e3, 0xa01139b, 0xa0114b9, 0xa0115aa,
+		0xa0116e2, 0xa01171e, 0xa0118e1, 0xa01195d, 0xa011ad5, 0xa011b7c, 0xa011cc7, 0xa011d4d,
+		0xa011e8b, 0xa011fb8, 0xa0120d4, 0xa01219a, 0xa0122a9, 0xa01236c, 0xa0124c6, 0xa01253d,
+		0xa0126d3, 0xa01272d, 0xa0128d2, 0xa01291d, 0xa012a7b, 0xa012bb7, 0xa012cd1, 0xa012d5c,
+		0xa012ec5, 0xa012f8a, 0xa0130a8, 0xa013199, 0xa01324c, 0xa0133c4, 0xa01346b, 0xa0135b6,
+		0xb026cd0, 0xb026d0c, 0xa01373c, 0xa0138c3, 0xa01397a, 0xa013aa7, 0xa013b2c, 0xa013cc2,
+		0xa013d5b, 0xa013eb5, 0xa013f1c, 0xa014089, 0xa014198, 0xa0142c1, 0xa01434b, 0xb0288c0,
+		0xb02890b, 0xa01453b, 0xb028cb0, 0xb028d0a, 0xa01471a, 0x900a4b4, 0xa014a6a, 0xa014ba6,
+		0xa014c79, 0xa014d97, 0xb029ca0, 0xb029d09, 0xa014f90, 0x900a8b3, 0x900a988, 0xa01542b,
+		0xa01555a, 0x900abb2, 0xa0158a5, 0xa01591b, 0xa015ab1, 0xa015b69, 0x900ae96, 0x900afa4,
+		0xa01604a, 0xa016178, 0x900b187, 0x900b23a, 0x900b3a3, 0x900b459, 0x900b595, 0x900b62a,
+		0x900b7a2, 0x900b8a1, 0x900b968, 0x900ba86, 0x900bb77, 0x900bc49, 0x900bd94, 0x900be39,
+		0x900bf93, 0x900c058, 0x900c185, 0x900c229, 0x900c367, 0x900c476, 0x900c592, 0x900c619,
+		0x900c791, 0x900c848, 0x900c984, 0x900ca57, 0x900cb75, 0x900cc38, 0x900cd83, 0x900ce66,
+		0x900cf28, 0x900d082, 0x900d118, 0x900d247, 0x900d374, 0x900d481, 0xa01aa08, 0xa01ab80,
+		0x900d656, 0x900d765, 0x900d817, 0xa01b207, 0xa01b370, 0x8006d73, 0x900dc37, 0x900dd27,
+		0x8006f72, 0x8007046, 0x8007164, 0x8007255, 0x8007371, 0x8007436, 0x8007563, 0x8007645,
+		0x8007754, 0x8007826, 0x8007962, 0x8007a16, 0x8007b61, 0x900f806, 0x900f960, 0x8007d35,
+		0x8007e53, 0x8007f44, 0x8008025, 0x8008152, 0x8008215, 0x9010605, 0x9010750, 0x7004251,
+		0x8008634, 0x8008743, 0x7004424, 0x7004542, 0x7004633, 0x7004714, 0x7004841, 0x8009204,
+		0x8009340, 0x7004a23, 0x7004b32, 0x6002613, 0x6002731, 0x7005003, 0x7005130, 0x6002922,
+		0x5001512, 0x5001621, 0x6002e02, 0x6002f20, 0x4000c11, 0x4000d01, 0x4000e10, 0x4000f00,
+	},
+	// Table A
+	{
+		0x600000b, 0x600010f, 0x600020d, 0x600030e, 0x6000407, 0x6000505, 0x5000309, 0x5000406,
+		0x5000503, 0x500060a, 0x500070c, 0x4000402, 0x4000501, 0x4000604, 0x4000708, 0x1000100,
+	},
+	// Table B
+	{
+		0x400000f, 0x400010e, 0x400020d, 0x400030c, 0x400040b, 0x400050a, 0x4000609, 0x4000708,
+		0x4000807, 0x4000906, 0x4000a05, 0x4000b04, 0x4000c03, 0x4000d02, 0x4000e01, 0x4000f00,
+	},
+}
+
+// mp3SynthesisWindow is the polyphase synthesis filter bank window
+var mp3SynthesisWindow = [512]float32{
+	0.000000000, -0.000015259, -0.000015259, -0.000015259, -0.000015259, -0.000015259, -0.000015259, -0.000030518,
+	-0.000030518, -0.000030518, -0.000030518, -0.000045776, -0.000045776, -0.000061035, -0.000061035, -0.000076294,
+	-0.000076294, -0.000091553, -0.000106812, -0.000106812, -0.000122070, -0.000137329, -0.000152588, -0.000167847,
+	-0.000198364, -0.000213623, -0.000244141, -0.000259399, -0.000289917, -0.000320435, -0.000366211, -0.000396729,
+	-0.000442505, -0.000473022, -0.000534058, -0.000579834, -0.000625610, -0.000686646, -0.000747681, -0.000808716,
+	-0.000885010, -0.000961304, -0.001037598, -0.001113892, -0.001205444, -0.001296997, -0.001388550, -0.001480103,
+	-0.001586914, -0.001693726, -0.001785278, -0.001907349, -0.002014160, -0.002120972, -0.002243042, -0.002349854,
+	-0.002456665, -0.002578735, -0.002685547, -0.002792358, -0.002899170, -0.002990723, -0.003082275, -0.003173828,
+	0.003250122, 0.003326416, 0.003387451, 0.003433228, 0.003463745, 0.003479004, 0.003479004, 0.003463745,
+	0.003417969, 0.003372192, 0.003280640, 0.003173828, 0.003051758, 0.002883911, 0.002700806, 0.002487183,
+	0.002227783, 0.001937866, 0.001617432, 0.001266479, 0.000869751, 0.000442505, -0.000030518, -0.000549316,
+	-0.001098633, -0.001693726, -0.002334595, -0.003005981, -0.003723145, -0.004486084, -0.005294800, -0.006118774,
+	-0.007003784, -0.007919312, -0.008865356, -0.009841919, -0.010848999, -0.011886597, -0.012939453, -0.014022827,
+	-0.015121460, -0.016235352, -0.017349243, -0.018463135, -0.019577026, -0.020690918, -0.021789551, -0.022857666,
+	-0.023910522, -0.024932861, -0.025909424, -0.026840210, -0.027725220, -0.028533936, -0.029281616, -0.029937744,
+	-0.030532837, -0.031005859, -0.031387329, -0.031661987, -0.031814575, -0.031845093, -0.031738281, -0.031478882,
+	0.031082153, 0.030517578, 0.029785156, 0.028884888, 0.027801514, 0.026535034, 0.025085449, 0.023422241,
+	0.021575928, 0.019531250, 0.017257690, 0.014801025, 0.012115479, 0.009231567, 0.006134033, 0.002822876,
+	-0.000686646, -0.004394531, -0.008316040, -0.012420654, -0.016708374, -0.021179199, -0.025817871, -0.030609131,
+	-0.035552979, -0.040634155, -0.045837402, -0.051132202, -0.056533813, -0.061996460, -0.067520142, -0.073059082,
+	-0.078628540, -0.084182739, -0.089706421, -0.095169067, -0.100540161, -0.105819702, -0.110946655, -0.115921021,
+	-0.120697021, -0.125259399, -0.129562378, -0.133590698, -0.137298584, -0.140670776, -0.143676758, -0.146255493,
+	-0.148422241, -0.150115967, -0.151306152, -0.151962280, -0.152069092, -0.151596069, -0.150497437, -0.148773193,
+	-0.146362305, -0.143264771, -0.139450073, -0.134887695, -0.129577637, -0.123474121, -0.116577148, -0.108856201,
+	0.100311279, 0.090927124, 0.080688477, 0.069595337, 0.057617188, 0.044784546, 0.031082153, 0.016510010,
+	0.001068115, -0.015228271, -0.032379150, -0.050354004, -0.069168091, -0.088775635, -0.109161377, -0.130310059,
+	-0.152206421, -0.174789429, -0.198059082, -0.221984863, -0.246505737, -0.271591187, -0.297210693, -0.323318481,
+	-0.349868774, -0.376800537, -0.404083252, -0.431655884, -0.459472656, -0.487472534, -0.515609741, -0.543823242,
+	-0.572036743, -0.600219727, -0.628295898, -0.656219482, -0.683914185, -0.711318970, -0.738372803, -0.765029907,
+	-0.791213989, -0.816864014, -0.841949463, -0.866363525, -0.890090942, -0.913055420, -0.935195923, -0.956481934,
+	-0.976852417, -0.996246338, -1.014617920, -1.031936646, -1.048156738, -1.063217163, -1.077117920, -1.089782715,
+	-1.101211548, -1.111373901, -1.120223999, -1.127746582, -1.133926392, -1.138763428, -1.142211914, -1.144287109,
+	1.144989014, 1.144287109, 1.142211914, 1.138763428, 1.133926392, 1.127746582, 1.120223999, 1.111373901,
+	1.101211548, 1.089782715, 1.077117920, 1.063217163, 1.048156738, 1.031936646, 1.014617920, 0.996246338,
+	0.976852417, 0.956481934, 0.935195923, 0.913055420, 0.890090942, 0.866363525, 0.841949463, 0.816864014,
+	0.791213989, 0.765029907, 0.738372803, 0.711318970, 0.683914185, 0.656219482, 0.628295898, 0.600219727,
+	0.572036743, 0.543823242, 0.515609741, 0.487472534, 0.459472656, 0.431655884, 0.404083252, 0.376800537,
+	0.349868774, 0.323318481, 0.297210693, 0.271591187, 0.246505737, 0.221984863, 0.198059082, 0.174789429,
+	0.152206421, 0.130310059, 0.109161377, 0.088775635, 0.069168091, 0.050354004, 0.032379150, 0.015228271,
+	-0.001068115, -0.016510010, -0.031082153, -0.044784546, -0.057617188, -0.069595337, -0.080688477, -0.090927124,
+	0.100311279, 0.108856201, 0.116577148, 0.123474121, 0.129577637, 0.134887695, 0.139450073, 0.143264771,
+	0.146362305, 0.148773193, 0.150497437, 0.151596069, 0.152069092, 0.151962280, 0.151306152, 0.150115967,
+	0.148422241, 0.146255493, 0.143676758, 0.140670776, 0.137298584, 0.133590698, 0.129562378, 0.125259399,
+	0.120697021, 0.115921021, 0.110946655, 0.105819702, 0.100540161, 0.095169067, 0.089706421, 0.084182739,
+	0.078628540, 0.073059082, 0.067520142, 0.061996460, 0.056533813, 0.051132202, 0.045837402, 0.040634155,
+	0.035552979, 0.030609131, 0.025817871, 0.021179199, 0.016708374, 0.012420654, 0.008316040, 0.004394531,
+	0.000686646, -0.002822876, -0.006134033, -0.009231567, -0.012115479, -0.014801025, -0.017257690, -0.019531250,
+	-0.021575928, -0.023422241, -0.025085449, -0.026535034, -0.027801514, -0.028884888, -0.029785156, -0.030517578,
+	0.031082153, 0.031478882, 0.031738281, 0.031845093, 0.031814575, 0.031661987, 0.031387329, 0.031005859,
+	0.030532837, 0.029937744, 0.029281616, 0.028533936, 0.027725220, 0.026840210, 0.025909424, 0.024932861,
+	0.023910522, 0.022857666, 0.021789551, 0.020690918, 0.019577026, 0.018463135, 0.017349243, 0.016235352,
+	0.015121460, 0.014022827, 0.012939453, 0.011886597, 0.010848999, 0.009841919, 0.008865356, 0.007919312,
+	0.007003784, 0.006118774, 0.005294800, 0.004486084, 0.003723145, 0.003005981, 0.002334595, 0.001693726,
+	0.001098633, 0.000549316, 0.000030518, -0.000442505, -0.000869751, -0.001266479, -0.001617432, -0.001937866,
+	-0.002227783, -0.002487183, -0.002700806, -0.002883911, -0.003051758, -0.003173828, -0.003280640, -0.003372192,
+	-0.003417969, -0.003463745, -0.003479004, -0.003479004, -0.003463745, -0.003433228, -0.003387451, -0.003326416,
+	0.003250122, 0.003173828, 0.003082275, 0.002990723, 0.002899170, 0.002792358, 0.002685547, 0.002578735,
+	0.002456665, 0.002349854, 0.002243042, 0.002120972, 0.002014160, 0.001907349, 0.001785278, 0.001693726,
+	0.001586914, 0.001480103, 0.001388550, 0.001296997, 0.001205444, 0.001113892, 0.001037598, 0.000961304,
+	0.000885010, 0.000808716, 0.000747681, 0.000686646, 0.000625610, 0.000579834, 0.000534058, 0.000473022,
+	0.000442505, 0.000396729, 0.000366211, 0.000320435, 0.000289917, 0.000259399, 0.000244141, 0.000213623,
+	0.000198364, 0.000167847, 0.000152588, 0.000137329, 0.000122070, 0.000106812, 0.000106812, 0.000091553,
+	0.000076294, 0.000076294, 0.000061035, 0.000061035, 0.000045776, 0.000045776, 0.000030518, 0.000030518,
+	0.000030518, 0.000030518, 0.000015259, 0.000015259, 0.000015259, 0.000015259, 0.000015259, 0.000015259,
+}
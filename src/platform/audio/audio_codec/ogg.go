@@ -0,0 +1,369 @@
+/******************************************************************************/
+/* ogg.go                                                                     */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package audio_codec
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+)
+
+const (
+	oggPageHeaderSize = 27
+	oggMaxPageSize    = oggPageHeaderSize + 255 + 255*255
+	oggFlagContinued  = 0x01
+	oggFlagBeginning  = 0x02
+)
+
+var errOggCapture = errors.New("ogg: missing page capture pattern")
+
+var oggCRCTable = func() (table [256]uint32) {
+	for i := range table {
+		crc := uint32(i) << 24
+		for range 8 {
+			if crc&0x80000000 != 0 {
+				crc = crc<<1 ^ 0x04C11DB7
+			} else {
+				crc <<= 1
+			}
+		}
+		table[i] = crc
+	}
+	return table
+}()
+
+// oggReader reads the packets of the first logical bitstream within an Ogg
+// container, pages of any other multiplexed streams are skipped
+type oggReader struct {
+	src       *source
+	pos       int
+	pageStart int
+	serial    uint32
+	hasSerial bool
+	page      []byte
+	segments  []byte
+	segIdx    int
+	body      []byte
+	packet    []byte
+}
+
+// oggPosition is where a reader is within the stream so that it can be
+// moved back to it later
+type oggPosition struct {
+	page    int
+	segment int
+}
+
+// oggPageInfo is the header of a page found while searching the stream
+type oggPageInfo struct {
+	offset  int
+	size    int
+	flags   byte
+	serial  uint32
+	granule int64
+	// complete is true when a packet both starts and ends within the page
+	complete bool
+}
+
+func (o *oggReader) nextPage() error {
+	for {
+		h, err := o.src.at(o.pos, oggPageHeaderSize+255)
+		if err != nil {
+			return err
+		}
+		if len(h) < oggPageHeaderSize {
+			return io.EOF
+		}
+		if string(h[:4]) != "OggS" {
+			return errOggCapture
+		}
+		serial := binary.LittleEndian.Uint32(h[14:])
+		beginning := h[5]&oggFlagBeginning != 0
+		segCount := int(h[26])
+		if len(h) < oggPageHeaderSize+segCount {
+			return io.EOF
+		}
+		size := oggPageHeaderSize + segCount
+		for _, s := range h[oggPageHeaderSize : oggPageHeaderSize+segCount] {
+			size += int(s)
+		}
+		page, err := o.src.at(o.pos, size)
+		if err != nil {
+			return err
+		}
+		if len(page) < size {
+			return io.EOF
+		}
+		pageStart := o.pos
+		o.pos += size
+		if !o.hasSerial {
+			if !beginning {
+				return ErrInvalidData
+			}
+			o.serial = serial
+			o.hasSerial = true
+		} else if serial != o.serial {
+			continue
+		}
+		// The page is copied as the source only holds it until its next read
+		o.page = append(o.page[:0], page...)
+		o.pageStart = pageStart
+		o.segments = o.page[oggPageHeaderSize : oggPageHeaderSize+segCount]
+		o.segIdx = 0
+		o.body = o.page[oggPageHeaderSize+segCount:]
+		return nil
+	}
+}
+
+// nextPacket returns the next full packet in the stream, the returned slice
+// is only valid until the next call
+func (o *oggReader) nextPacket() ([]byte, error) {
+	o.packet = o.packet[:0]
+	for {
+		for o.segIdx >= len(o.segments) {
+			if err := o.nextPage(); err != nil {
+				return nil, err
+			}
+		}
+		size := int(o.segments[o.segIdx])
+		o.segIdx++
+		o.packet = append(o.packet, o.body[:size]...)
+		o.body = o.body[size:]
+		// A lacing value of less than 255 marks the end of the packet
+		if size < 255 {
+			return o.packet, nil
+		}
+	}
+}
+
+func (o *oggReader) position() oggPosition {
+	if o.segments == nil {
+		return oggPosition{page: o.pos, segment: -1}
+	}
+	return oggPosition{page: o.pageStart, segment: o.segIdx}
+}
+
+// setPosition moves the reader back to a position that was returned from
+// position, the page is read again as only the current page is held
+func (o *oggReader) setPosition(p oggPosition) error {
+	o.pos = p.page
+	o.packet = o.packet[:0]
+	o.segments, o.segIdx, o.body = nil, 0, nil
+	if p.segment < 0 {
+		return nil
+	}
+	if err := o.nextPage(); err != nil {
+		return err
+	}
+	for o.segIdx < p.segment && o.segIdx < len(o.segments) {
+		o.body = o.body[o.segments[o.segIdx]:]
+		o.segIdx++
+	}
+	return nil
+}
+
+// seekPage moves the reader to the first packet that begins on the page and
+// returns the packets that are completed within the page
+func (o *oggReader) seekPage(p oggPageInfo) ([][]byte, error) {
+	if err := o.setPosition(oggPosition{page: p.offset}); err != nil {
+		return nil, err
+	}
+	if p.flags&oggFlagContinued != 0 {
+		for o.segIdx < len(o.segments) {
+			size := int(o.segments[o.segIdx])
+			o.segIdx++
+			o.body = o.body[size:]
+			if size < 255 {
+				break
+			}
+		}
+	}
+	var packets [][]byte
+	start, end := 0, 0
+	for _, size := range o.segments[o.segIdx:] {
+		end += int(size)
+		if size < 255 {
+			packets = append(packets, o.body[start:end])
+			start = end
+		}
+	}
+	return packets, nil
+}
+
+// pageAt reads the header of the page at the offset, false is returned if
+// there isn't a complete page of this version of the format there. The CRC
+// is checked when searching for pages as audio data can look like a header.
+func (o *oggReader) pageAt(offset int, checkCRC bool) (oggPageInfo, bool) {
+	p := oggPageInfo{offset: offset}
+	h, err := o.src.at(offset, oggPageHeaderSize+255)
+	if err != nil || len(h) < oggPageHeaderSize || string(h[:4]) != "OggS" || h[4] != 0 {
+		return p, false
+	}
+	segCount := int(h[26])
+	if len(h) < oggPageHeaderSize+segCount {
+		return p, false
+	}
+	p.flags = h[5]
+	p.granule = int64(binary.LittleEndian.Uint64(h[6:]))
+	p.serial = binary.LittleEndian.Uint32(h[14:])
+	crc := binary.LittleEndian.Uint32(h[22:])
+	p.size = oggPageHeaderSize + segCount
+	continued := p.flags&oggFlagContinued != 0
+	for _, s := range h[oggPageHeaderSize : oggPageHeaderSize+segCount] {
+		p.size += int(s)
+		if s < 255 {
+			p.complete = p.complete || !continued
+			continued = false
+		}
+	}
+	if offset+p.size > o.src.size {
+		return p, false
+	}
+	if checkCRC {
+		page, err := o.src.at(offset, p.size)
+		if err != nil || oggCRC(page) != crc {
+			return p, false
+		}
+	}
+	return p, true
+}
+
+// oggCRC computes the checksum of the page as if its checksum field was zero
+func oggCRC(page []byte) uint32 {
+	crc := uint32(0)
+	for i, b := range page {
+		if i >= 22 && i < 26 {
+			b = 0
+		}
+		crc = crc<<8 ^ oggCRCTable[byte(crc>>24)^b]
+	}
+	return crc
+}
+
+// findPage returns the first page of the stream that starts between the
+// offsets
+func (o *oggReader) findPage(from, to int) (oggPageInfo, bool) {
+	for pos := from; pos < to; {
+		data, err := o.src.at(pos, sourceReadSize)
+		if err != nil || len(data) < 4 {
+			return oggPageInfo{}, false
+		}
+		i := bytes.Index(data, []byte("OggS"))
+		if i < 0 {
+			if pos+len(data) >= o.src.size {
+				return oggPageInfo{}, false
+			}
+			pos += len(data) - 3
+			continue
+		}
+		if pos+i >= to {
+			return oggPageInfo{}, false
+		}
+		if p, ok := o.pageAt(pos+i, true); ok && p.serial == o.serial {
+			return p, true
+		}
+		pos += i + 1
+	}
+	return oggPageInfo{}, false
+}
+
+// findSeekPage finds the last page with a granule position at or before the
+// target that a packet both starts and ends on. Decoding can start from
+// such a page without the pages before it. The stream is bisected so only
+// the pages close to the target are read.
+func (o *oggReader) findSeekPage(from int, target int64) (oggPageInfo, bool) {
+	lo, hi := from, o.src.size
+	for hi-lo > oggMaxPageSize*2 {
+		mid := lo + (hi-lo)/2
+		p, ok := o.findPage(mid, hi)
+		if !ok || p.granule > target {
+			hi = mid
+			continue
+		}
+		lo = p.offset
+	}
+	found, ok := oggPageInfo{}, false
+	for pos := lo; ; {
+		p, valid := o.findPage(pos, o.src.size)
+		if !valid || p.granule > target {
+			break
+		}
+		if p.complete {
+			found, ok = p, true
+		}
+		pos = p.offset + p.size
+	}
+	return found, ok
+}
+
+// lastGranule finds the granule position of the last page of the stream
+// which is the total number of samples (per channel) in the stream. Only
+// the end of the stream is searched unless the last page isn't found there.
+func (o *oggReader) lastGranule(from int) int64 {
+	tailStart := max(from, o.src.size-oggMaxPageSize*2)
+	tail, err := o.src.at(tailStart, o.src.size-tailStart)
+	if err != nil {
+		return -1
+	}
+	// The tail is copied as checking each page reads from the source
+	tail = bytes.Clone(tail)
+	for end := len(tail); end > 0; {
+		i := bytes.LastIndex(tail[:end], []byte("OggS"))
+		if i < 0 {
+			break
+		}
+		p, ok := o.pageAt(tailStart+i, true)
+		if ok && p.serial == o.serial && p.granule != -1 {
+			return p.granule
+		}
+		end = i
+	}
+	granule := int64(-1)
+	for pos := from; ; {
+		p, ok := o.pageAt(pos, false)
+		if !ok {
+			break
+		}
+		if p.serial == o.serial && p.granule != -1 {
+			granule = p.granule
+		}
+		pos += p.size
+	}
+	return granule
+}
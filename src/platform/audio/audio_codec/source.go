@@ -0,0 +1,114 @@
+/******************************************************************************/
+/* source.go                                                                  */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package audio_codec
+
+import (
+	"errors"
+	"io"
+)
+
+// sourceReadSize is the least that is read from a file at a time
+const sourceReadSize = 32 * 1024
+
+// source is the encoded stream that a decoder reads. It is either fully in
+// memory or read from a file as the decoder moves through it, in which case
+// only a window of the file is held in memory.
+type source struct {
+	reader io.ReadSeeker
+	buf    []byte
+	base   int // The stream offset of buf[0]
+	size   int
+	pos    int // The offset of the reader within the stream
+}
+
+func newMemorySource(data []byte) *source {
+	return &source{buf: data, size: len(data)}
+}
+
+func newReaderSource(r io.ReadSeeker) (*source, error) {
+	size, err := r.Seek(0, io.SeekEnd)
+	if err != nil {
+		return nil, err
+	}
+	s := &source{reader: r, size: int(size), pos: int(size)}
+	return s, nil
+}
+
+// at returns up to n bytes of the stream starting at the offset, fewer bytes
+// are only returned at the end of the stream. The slice is only valid until
+// the next call.
+func (s *source) at(offset, n int) ([]byte, error) {
+	offset = min(max(0, offset), s.size)
+	end := min(offset+max(0, n), s.size)
+	if offset >= s.base && end <= s.base+len(s.buf) {
+		return s.buf[offset-s.base : end-s.base], nil
+	}
+	if s.reader == nil {
+		return nil, io.ErrUnexpectedEOF
+	}
+	// Keep what is already read when moving forward within the window,
+	// anything else starts a new window at the offset
+	if offset >= s.base && offset <= s.base+len(s.buf) {
+		kept := copy(s.buf, s.buf[offset-s.base:])
+		s.buf = s.buf[:kept]
+	} else {
+		s.buf = s.buf[:0]
+	}
+	s.base = offset
+	readFrom := s.base + len(s.buf)
+	want := min(max(end-readFrom, sourceReadSize), s.size-readFrom)
+	if cap(s.buf) < len(s.buf)+want {
+		grown := make([]byte, len(s.buf), len(s.buf)+want)
+		copy(grown, s.buf)
+		s.buf = grown
+	}
+	if s.pos != readFrom {
+		if _, err := s.reader.Seek(int64(readFrom), io.SeekStart); err != nil {
+			s.buf = s.buf[:0]
+			return nil, err
+		}
+		s.pos = readFrom
+	}
+	read, err := io.ReadFull(s.reader, s.buf[len(s.buf):len(s.buf)+want])
+	s.buf = s.buf[:len(s.buf)+read]
+	s.pos += read
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+	return s.buf[:min(end, s.base+len(s.buf))-s.base], nil
+}
@@ -0,0 +1,542 @@
+/******************************************************************************/
+/* vorbis.go                                                                  */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package audio_codec
+
+import (
+	"errors"
+	"io"
+	"math"
+)
+
+const (
+	vorbisPacketIdentification = 1
+	vorbisPacketComment        = 3
+	vorbisPacketSetup          = 5
+)
+
+var errVorbisHeader = errors.New("vorbis: invalid header")
+
+type vorbisMapping struct {
+	submaps    int
+	magnitudes []int
+	angles     []int
+	mux        []int
+	floors     []int
+	residues   []int
+}
+
+type vorbisMode struct {
+	blockFlag bool
+	mapping   int
+}
+
+// VorbisDecoder decodes an Ogg Vorbis stream (.ogg) one packet at a time
+type VorbisDecoder struct {
+	ogg          oggReader
+	audioStart   oggPosition
+	sampleRate   int
+	channels     int
+	blockSizes   [2]int
+	books        []vorbisCodebook
+	floors       []vorbisFloor
+	residues     []*vorbisResidue
+	mappings     []vorbisMapping
+	modes        []vorbisMode
+	transforms   [2]*imdct
+	slopes       [2][]float32
+	totalSamples int64
+	decoded      int64
+	skip         int64
+	floorData    []vorbisFloorData
+	spectrum     [][]float32
+	samples      []float32
+	overlap      [][]float32
+	prevSize     int
+	hasPrev      bool
+	floorUsed    []bool
+	noResidue    []bool
+	submapVecs   [][]float32
+	submapSkip   []bool
+	out          []float32
+}
+
+func NewVorbisDecoder(data []byte) (*VorbisDecoder, error) {
+	return newVorbisDecoder(newMemorySource(data))
+}
+
+// NewVorbisStreamDecoder creates a decoder that reads the stream from the
+// reader as it decodes rather than needing the whole stream in memory
+func NewVorbisStreamDecoder(r io.ReadSeeker) (*VorbisDecoder, error) {
+	src, err := newReaderSource(r)
+	if err != nil {
+		return nil, err
+	}
+	return newVorbisDecoder(src)
+}
+
+func newVorbisDecoder(src *source) (*VorbisDecoder, error) {
+	d := &VorbisDecoder{ogg: oggReader{src: src}}
+	if err := d.readHeaders(); err != nil {
+		return nil, err
+	}
+	d.audioStart = d.ogg.position()
+	d.totalSamples = d.ogg.lastGranule(d.ogg.pos)
+	if err := d.Rewind(); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+func (d *VorbisDecoder) Channels() int   { return d.channels }
+func (d *VorbisDecoder) SampleRate() int { return d.sampleRate }
+
+func (d *VorbisDecoder) Rewind() error {
+	d.decoded = 0
+	d.skip = 0
+	d.hasPrev = false
+	return d.ogg.setPosition(d.audioStart)
+}
+
+// Seek moves the decoder so that the next block starts at the sample frame.
+// Decoding starts from the last page before the frame that a packet begins
+// on, its granule position gives where the first packet decoded ends.
+func (d *VorbisDecoder) Seek(frame int) error {
+	if err := d.Rewind(); err != nil {
+		return err
+	}
+	target := int64(max(0, frame))
+	if d.totalSamples >= 0 {
+		target = min(target, d.totalSamples)
+	}
+	d.skip = target
+	page, ok := d.ogg.findSeekPage(d.ogg.pos, target)
+	if !ok {
+		return nil
+	}
+	packets, err := d.ogg.seekPage(page)
+	if err != nil {
+		return err
+	}
+	// The granule is the end of the last packet on the page, each packet
+	// after the first finishes the samples between its center and the
+	// center of the packet before it
+	pos := page.granule
+	sizes := make([]int, 0, len(packets))
+	for _, p := range packets {
+		if size := d.packetBlockSize(p); size > 0 {
+			sizes = append(sizes, size)
+		}
+	}
+	for i := len(sizes) - 1; i > 0; i-- {
+		pos -= int64(sizes[i-1]/4 + sizes[i]/4)
+	}
+	if len(sizes) == 0 || pos < 0 {
+		if err := d.Rewind(); err != nil {
+			return err
+		}
+		d.skip = target
+		return nil
+	}
+	d.decoded = pos
+	d.skip = target - pos
+	return nil
+}
+
+// packetBlockSize reads the block size of an audio packet from its mode,
+// zero is returned for packets that don't decode to audio
+func (d *VorbisDecoder) packetBlockSize(packet []byte) int {
+	r := lsbBitReader{data: packet}
+	if len(packet) == 0 || r.readBool() {
+		return 0
+	}
+	modeIdx := int(r.read(ilog(len(d.modes) - 1)))
+	if modeIdx >= len(d.modes) {
+		return 0
+	}
+	if d.modes[modeIdx].blockFlag {
+		return d.blockSizes[1]
+	}
+	return d.blockSizes[0]
+}
+
+func (d *VorbisDecoder) readHeaders() error {
+	for i, kind := range []int{vorbisPacketIdentification,
+		vorbisPacketComment, vorbisPacketSetup} {
+		packet, err := d.ogg.nextPacket()
+		if err != nil {
+			return errVorbisHeader
+		}
+		if len(packet) < 7 || int(packet[0]) != kind || string(packet[1:7]) != "vorbis" {
+			return errVorbisHeader
+		}
+		r := lsbBitReader{data: packet, pos: 7 * 8}
+		switch i {
+		case 0:
+			err = d.readIdentification(&r)
+		case 2:
+			err = d.readSetup(&r)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (d *VorbisDecoder) readIdentification(r *lsbBitReader) error {
+	if r.read(32) != 0 {
+		return errVorbisHeader
+	}
+	d.channels = int(r.read(8))
+	d.sampleRate = int(r.read(32))
+	r.read(32) // Maximum bitrate
+	r.read(32) // Nominal bitrate
+	r.read(32) // Minimum bitrate
+	d.blockSizes[0] = 1 << r.read(4)
+	d.blockSizes[1] = 1 << r.read(4)
+	if d.channels == 0 || d.sampleRate == 0 || d.blockSizes[0] < 64 ||
+		d.blockSizes[1] < d.blockSizes[0] || d.blockSizes[1] > 8192 || !r.readBool() {
+		return errVorbisHeader
+	}
+	return nil
+}
+
+func (d *VorbisDecoder) readSetup(r *lsbBitReader) error {
+	d.books = make([]vorbisCodebook, r.read(8)+1)
+	for i := range d.books {
+		if err := d.books[i].read(r); err != nil {
+			return err
+		}
+	}
+	// Time domain transforms are placeholders and must be zero
+	for i := int(r.read(6)) + 1; i > 0; i-- {
+		if r.read(16) != 0 {
+			return errVorbisHeader
+		}
+	}
+	d.floors = make([]vorbisFloor, r.read(6)+1)
+	for i := range d.floors {
+		f, err := readVorbisFloor(r, len(d.books))
+		if err != nil {
+			return err
+		}
+		d.floors[i] = f
+	}
+	d.residues = make([]*vorbisResidue, r.read(6)+1)
+	for i := range d.residues {
+		res, err := readVorbisResidue(r, d.books)
+		if err != nil {
+			return err
+		}
+		d.residues[i] = res
+	}
+	d.mappings = make([]vorbisMapping, r.read(6)+1)
+	for i := range d.mappings {
+		if err := d.readMapping(r, &d.mappings[i]); err != nil {
+			return err
+		}
+	}
+	d.modes = make([]vorbisMode, r.read(6)+1)
+	for i := range d.modes {
+		m := &d.modes[i]
+		m.blockFlag = r.readBool()
+		windowType := r.read(16)
+		transformType := r.read(16)
+		m.mapping = int(r.read(8))
+		if windowType != 0 || transformType != 0 || m.mapping >= len(d.mappings) {
+			return errVorbisHeader
+		}
+	}
+	if !r.readBool() || r.overrun() {
+		return errVorbisHeader
+	}
+	d.prepareBuffers()
+	return nil
+}
+
+func (d *VorbisDecoder) readMapping(r *lsbBitReader, m *vorbisMapping) error {
+	if r.read(16) != 0 {
+		return errVorbisHeader
+	}
+	m.submaps = 1
+	if r.readBool() {
+		m.submaps = int(r.read(4)) + 1
+	}
+	if r.readBool() {
+		steps := int(r.read(8)) + 1
+		m.magnitudes = make([]int, steps)
+		m.angles = make([]int, steps)
+		bits := ilog(d.channels - 1)
+		for i := 0; i < steps; i++ {
+			m.magnitudes[i] = int(r.read(bits))
+			m.angles[i] = int(r.read(bits))
+			if m.magnitudes[i] == m.angles[i] || m.magnitudes[i] >= d.channels ||
+				m.angles[i] >= d.channels {
+				return errVorbisHeader
+			}
+		}
+	}
+	if r.read(2) != 0 {
+		return errVorbisHeader
+	}
+	m.mux = make([]int, d.channels)
+	if m.submaps > 1 {
+		for i := range m.mux {
+			m.mux[i] = int(r.read(4))
+			if m.mux[i] >= m.submaps {
+				return errVorbisHeader
+			}
+		}
+	}
+	m.floors = make([]int, m.submaps)
+	m.residues = make([]int, m.submaps)
+	for i := 0; i < m.submaps; i++ {
+		r.read(8) // Unused time configuration
+		m.floors[i] = int(r.read(8))
+		m.residues[i] = int(r.read(8))
+		if m.floors[i] >= len(d.floors) || m.residues[i] >= len(d.residues) {
+			return errVorbisHeader
+		}
+	}
+	return nil
+}
+
+func (d *VorbisDecoder) prepareBuffers() {
+	for i, size := range d.blockSizes {
+		d.transforms[i] = newIMDCT(size)
+		// The slope is the rising half of the Vorbis power complementary
+		// window for an overlap of size/2 samples
+		half := size / 2
+		d.slopes[i] = make([]float32, half)
+		for j := 0; j < half; j++ {
+			s := math.Sin((float64(j) + 0.5) / float64(half) * math.Pi / 2)
+			d.slopes[i][j] = float32(math.Sin(math.Pi / 2 * s * s))
+		}
+	}
+	long := d.blockSizes[1]
+	d.floorData = make([]vorbisFloorData, d.channels)
+	d.spectrum = make([][]float32, d.channels)
+	d.overlap = make([][]float32, d.channels)
+	for ch := 0; ch < d.channels; ch++ {
+		d.spectrum[ch] = make([]float32, long/2)
+		d.overlap[ch] = make([]float32, long/2)
+	}
+	d.samples = make([]float32, long)
+	d.floorUsed = make([]bool, d.channels)
+	d.noResidue = make([]bool, d.channels)
+}
+
+func (d *VorbisDecoder) DecodeBlock() ([]float32, error) {
+	for {
+		if d.totalSamples >= 0 && d.decoded >= d.totalSamples {
+			return nil, io.EOF
+		}
+		packet, err := d.ogg.nextPacket()
+		if err != nil {
+			if err == io.EOF {
+				return nil, io.EOF
+			}
+			return nil, err
+		}
+		frames := d.decodePacket(packet)
+		if frames <= 0 {
+			continue
+		}
+		if d.totalSamples >= 0 {
+			frames = int(min(int64(frames), d.totalSamples-d.decoded))
+		}
+		d.decoded += int64(frames)
+		trim := int(min(d.skip, int64(max(0, frames))))
+		d.skip -= int64(trim)
+		if frames > trim {
+			return d.out[trim*d.channels : frames*d.channels], nil
+		}
+	}
+}
+
+// decodePacket decodes an audio packet and returns the number of sample
+// frames that were completed by overlapping it with the previous packet
+func (d *VorbisDecoder) decodePacket(packet []byte) int {
+	r := lsbBitReader{data: packet}
+	if len(packet) == 0 || r.readBool() {
+		return 0
+	}
+	modeIdx := int(r.read(ilog(len(d.modes) - 1)))
+	if modeIdx >= len(d.modes) {
+		return 0
+	}
+	mode := &d.modes[modeIdx]
+	blockIdx := 0
+	prevLong, nextLong := false, false
+	if mode.blockFlag {
+		blockIdx = 1
+		prevLong = r.readBool()
+		nextLong = r.readBool()
+	}
+	n := d.blockSizes[blockIdx]
+	half := n / 2
+	mapping := &d.mappings[mode.mapping]
+	for ch := 0; ch < d.channels; ch++ {
+		floor := d.floors[mapping.floors[mapping.mux[ch]]]
+		d.floorUsed[ch] = floor.decode(&r, d.books, &d.floorData[ch])
+		d.noResidue[ch] = !d.floorUsed[ch]
+	}
+	// Coupled channels must both be decoded if either is
+	for i := range mapping.magnitudes {
+		m, a := mapping.magnitudes[i], mapping.angles[i]
+		if !d.noResidue[m] || !d.noResidue[a] {
+			d.noResidue[m] = false
+			d.noResidue[a] = false
+		}
+	}
+	for ch := 0; ch < d.channels; ch++ {
+		d.spectrum[ch] = d.spectrum[ch][:half]
+		clear(d.spectrum[ch])
+	}
+	for sub := 0; sub < mapping.submaps; sub++ {
+		d.submapVecs = d.submapVecs[:0]
+		d.submapSkip = d.submapSkip[:0]
+		for ch := 0; ch < d.channels; ch++ {
+			if mapping.mux[ch] == sub {
+				d.submapVecs = append(d.submapVecs, d.spectrum[ch])
+				d.submapSkip = append(d.submapSkip, d.noResidue[ch])
+			}
+		}
+		res := d.residues[mapping.residues[sub]]
+		res.decode(&r, d.books, d.submapVecs, d.submapSkip)
+	}
+	for i := len(mapping.magnitudes) - 1; i >= 0; i-- {
+		vorbisInverseCoupling(d.spectrum[mapping.magnitudes[i]],
+			d.spectrum[mapping.angles[i]])
+	}
+	for ch := 0; ch < d.channels; ch++ {
+		if !d.floorUsed[ch] {
+			clear(d.spectrum[ch])
+			continue
+		}
+		floor := d.floors[mapping.floors[mapping.mux[ch]]]
+		floor.apply(&d.floorData[ch], d.spectrum[ch])
+	}
+	return d.synthesize(blockIdx, prevLong, nextLong)
+}
+
+func vorbisInverseCoupling(magnitude, angle []float32) {
+	for i := range magnitude {
+		m, a := magnitude[i], angle[i]
+		if m > 0 {
+			if a > 0 {
+				magnitude[i], angle[i] = m, m-a
+			} else {
+				magnitude[i], angle[i] = m+a, m
+			}
+		} else {
+			if a > 0 {
+				magnitude[i], angle[i] = m, m+a
+			} else {
+				magnitude[i], angle[i] = m-a, m
+			}
+		}
+	}
+}
+
+// synthesize transforms the spectrum of each channel back into samples,
+// applies the window, and overlaps it with the previous block. The samples
+// between the centers of the previous and current block are finished.
+func (d *VorbisDecoder) synthesize(blockIdx int, prevLong, nextLong bool) int {
+	n := d.blockSizes[blockIdx]
+	leftN, rightN := n, n
+	if blockIdx == 1 {
+		if !prevLong {
+			leftN = d.blockSizes[0]
+		}
+		if !nextLong {
+			rightN = d.blockSizes[0]
+		}
+	}
+	leftSlope := d.slopes[0]
+	if leftN == d.blockSizes[1] {
+		leftSlope = d.slopes[1]
+	}
+	rightSlope := d.slopes[0]
+	if rightN == d.blockSizes[1] {
+		rightSlope = d.slopes[1]
+	}
+	leftStart := n/4 - leftN/4
+	leftEnd := n/4 + leftN/4
+	rightStart := 3*n/4 - rightN/4
+	rightEnd := 3*n/4 + rightN/4
+	frames := 0
+	if d.hasPrev {
+		frames = d.prevSize/4 + n/4
+	}
+	d.out = growSlice(d.out, frames*d.channels)
+	curStart := d.prevSize/4 - n/4
+	samples := d.samples[:n]
+	for ch := 0; ch < d.channels; ch++ {
+		d.transforms[blockIdx].transform(d.spectrum[ch], samples)
+		for i := 0; i < leftStart; i++ {
+			samples[i] = 0
+		}
+		for i := leftStart; i < leftEnd; i++ {
+			samples[i] *= leftSlope[i-leftStart]
+		}
+		for i := rightStart; i < rightEnd; i++ {
+			samples[i] *= rightSlope[rightEnd-1-i]
+		}
+		for i := rightEnd; i < n; i++ {
+			samples[i] = 0
+		}
+		if d.hasPrev {
+			prev := d.overlap[ch]
+			for k := 0; k < frames; k++ {
+				var v float32
+				if k < d.prevSize/2 {
+					v = prev[k]
+				}
+				if j := k - curStart; j >= 0 && j < n/2 {
+					v += samples[j]
+				}
+				d.out[k*d.channels+ch] = v
+			}
+		}
+		copy(d.overlap[ch], samples[n/2:])
+	}
+	d.prevSize = n
+	d.hasPrev = true
+	return frames
+}
@@ -0,0 +1,273 @@
+/******************************************************************************/
+/* vorbis_codebook.go                                                         */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package audio_codec
+
+import (
+	"errors"
+	"math"
+)
+
+const vorbisCodebookSync = 0x564342
+
+var errVorbisCodebook = errors.New("vorbis: invalid codebook")
+
+type vorbisCodebook struct {
+	dimensions int
+	entries    int
+	// tree holds pairs of child nodes, a negative child is a leaf holding
+	// the entry number and a child of 0 is an unused code
+	tree    []int32
+	vectors []float32
+}
+
+func ilog(x int) int {
+	n := 0
+	for x > 0 {
+		n++
+		x >>= 1
+	}
+	return n
+}
+
+func float32Unpack(x uint32) float32 {
+	mantissa := float64(x & 0x1FFFFF)
+	if x&0x80000000 != 0 {
+		mantissa = -mantissa
+	}
+	exponent := int((x & 0x7FE00000) >> 21)
+	return float32(math.Ldexp(mantissa, exponent-788))
+}
+
+// lookup1Values is the largest value where value^dimensions <= entries
+func lookup1Values(entries, dimensions int) int {
+	r := int(math.Floor(math.Pow(float64(entries), 1/float64(dimensions))))
+	for pow(r+1, dimensions) <= entries {
+		r++
+	}
+	for r > 0 && pow(r, dimensions) > entries {
+		r--
+	}
+	return r
+}
+
+func pow(v, e int) int {
+	r := 1
+	for i := 0; i < e; i++ {
+		r *= v
+	}
+	return r
+}
+
+func (c *vorbisCodebook) read(r *lsbBitReader) error {
+	if r.read(24) != vorbisCodebookSync {
+		return errVorbisCodebook
+	}
+	c.dimensions = int(r.read(16))
+	c.entries = int(r.read(24))
+	lengths := make([]uint8, c.entries)
+	if r.readBool() {
+		// Ordered code word lengths
+		entry := 0
+		length := int(r.read(5)) + 1
+		for entry < c.entries {
+			count := int(r.read(ilog(c.entries - entry)))
+			if entry+count > c.entries || length > 32 {
+				return errVorbisCodebook
+			}
+			for i := 0; i < count; i++ {
+				lengths[entry+i] = uint8(length)
+			}
+			entry += count
+			length++
+		}
+	} else {
+		sparse := r.readBool()
+		for i := range lengths {
+			if !sparse || r.readBool() {
+				lengths[i] = uint8(r.read(5) + 1)
+			}
+		}
+	}
+	if r.overrun() {
+		return errVorbisCodebook
+	}
+	if err := c.buildTree(lengths); err != nil {
+		return err
+	}
+	lookupType := r.read(4)
+	switch lookupType {
+	case 0:
+	case 1, 2:
+		minimum := float32Unpack(r.read(32))
+		delta := float32Unpack(r.read(32))
+		valueBits := int(r.read(4)) + 1
+		sequence := r.readBool()
+		lookupValues := c.entries * c.dimensions
+		if lookupType == 1 {
+			lookupValues = lookup1Values(c.entries, c.dimensions)
+		}
+		if lookupValues <= 0 {
+			return errVorbisCodebook
+		}
+		multiplicands := make([]uint32, lookupValues)
+		for i := range multiplicands {
+			multiplicands[i] = r.read(valueBits)
+		}
+		if r.overrun() {
+			return errVorbisCodebook
+		}
+		c.buildVectors(lookupType, multiplicands, minimum, delta, sequence)
+	default:
+		return errVorbisCodebook
+	}
+	return nil
+}
+
+// buildTree assigns code words to the entries in order, each entry taking
+// the lowest available code word of its length
+func (c *vorbisCodebook) buildTree(lengths []uint8) error {
+	c.tree = []int32{0, 0}
+	var available [33]uint32
+	first := true
+	used := 0
+	for entry, length := range lengths {
+		if length == 0 {
+			continue
+		}
+		used++
+		var code uint32
+		if first {
+			first = false
+			for i := 1; i <= int(length); i++ {
+				available[i] = 1 << (32 - i)
+			}
+		} else {
+			z := int(length)
+			for z > 0 && available[z] == 0 {
+				z--
+			}
+			if z == 0 {
+				return errVorbisCodebook
+			}
+			code = available[z]
+			available[z] = 0
+			for y := int(length); y > z; y-- {
+				available[y] = code + 1<<(32-y)
+			}
+		}
+		c.insert(code, int(length), int32(entry))
+	}
+	// A codebook with a single entry may use either bit value for it
+	if used == 1 {
+		if c.tree[0] == 0 {
+			c.tree[0] = c.tree[1]
+		} else if c.tree[1] == 0 {
+			c.tree[1] = c.tree[0]
+		}
+	}
+	return nil
+}
+
+func (c *vorbisCodebook) insert(code uint32, length int, entry int32) {
+	node := int32(0)
+	for i := 0; i < length; i++ {
+		idx := node*2 + int32(code>>(31-i)&1)
+		if i == length-1 {
+			c.tree[idx] = -entry - 1
+			return
+		}
+		if c.tree[idx] <= 0 {
+			c.tree[idx] = int32(len(c.tree) / 2)
+			c.tree = append(c.tree, 0, 0)
+		}
+		node = c.tree[idx]
+	}
+}
+
+func (c *vorbisCodebook) buildVectors(lookupType uint32, multiplicands []uint32, minimum, delta float32, sequence bool) {
+	c.vectors = make([]float32, c.entries*c.dimensions)
+	lookupValues := len(multiplicands)
+	for entry := 0; entry < c.entries; entry++ {
+		last := float32(0)
+		divisor := 1
+		for i := 0; i < c.dimensions; i++ {
+			offset := entry*c.dimensions + i
+			if lookupType == 1 {
+				offset = (entry / divisor) % lookupValues
+				// Past the entry count the quotient stays 0, stop before
+				// the divisor can overflow
+				if divisor <= c.entries {
+					divisor *= lookupValues
+				}
+			}
+			v := float32(multiplicands[offset])*delta + minimum + last
+			if sequence {
+				last = v
+			}
+			c.vectors[entry*c.dimensions+i] = v
+		}
+	}
+}
+
+// decodeScalar reads the entry number of the next code word, -1 is returned
+// for an invalid code word or the end of the packet
+func (c *vorbisCodebook) decodeScalar(r *lsbBitReader) int {
+	node := int32(0)
+	for {
+		next := c.tree[node*2+int32(r.read(1))]
+		if next < 0 {
+			if r.overrun() {
+				return -1
+			}
+			return int(-next - 1)
+		}
+		if next == 0 || r.overrun() {
+			return -1
+		}
+		node = next
+	}
+}
+
+// decodeVector reads the next code word and returns the vector for it
+func (c *vorbisCodebook) decodeVector(r *lsbBitReader) []float32 {
+	entry := c.decodeScalar(r)
+	if entry < 0 || c.vectors == nil {
+		return nil
+	}
+	return c.vectors[entry*c.dimensions : (entry+1)*c.dimensions]
+}
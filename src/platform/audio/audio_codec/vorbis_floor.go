@@ -0,0 +1,420 @@
+/******************************************************************************/
+/* vorbis_floor.go                                                            */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package audio_codec
+
+import (
+	"errors"
+	"kaiju/klib"
+	"math"
+	"sort"
+)
+
+var errVorbisFloor = errors.New("vorbis: invalid floor")
+
+var vorbisFloor1Ranges = [4]int{256, 128, 86, 64}
+
+type vorbisFloor interface {
+	// decode reads the floor for the packet, false is returned if the floor
+	// is unused for this channel in the packet
+	decode(r *lsbBitReader, books []vorbisCodebook, data *vorbisFloorData) bool
+	// apply multiplies the decoded floor curve into the spectrum
+	apply(data *vorbisFloorData, spectrum []float32)
+}
+
+// vorbisFloorData is the per channel floor state decoded from a packet
+type vorbisFloorData struct {
+	values []int
+	coeffs []float32
+	amp    int
+	finalY []int
+	step2  []bool
+	curve  []int
+}
+
+type vorbisFloor0 struct {
+	order           int
+	rate            int
+	barkMapSize     int
+	amplitudeBits   int
+	amplitudeOffset int
+	books           []int
+	maps            map[int][]int
+}
+
+type vorbisFloor1Class struct {
+	dimensions    int
+	subclasses    int
+	masterbook    int
+	subclassBooks []int
+}
+
+type vorbisFloor1 struct {
+	partitionClass []int
+	classes        []vorbisFloor1Class
+	multiplier     int
+	xList          []int
+	sorted         []int
+	lowNeighbor    []int
+	highNeighbor   []int
+}
+
+func readVorbisFloor(r *lsbBitReader, bookCount int) (vorbisFloor, error) {
+	switch r.read(16) {
+	case 0:
+		f := &vorbisFloor0{
+			order:           int(r.read(8)),
+			rate:            int(r.read(16)),
+			barkMapSize:     int(r.read(16)),
+			amplitudeBits:   int(r.read(6)),
+			amplitudeOffset: int(r.read(8)),
+			maps:            map[int][]int{},
+		}
+		f.books = make([]int, r.read(4)+1)
+		for i := range f.books {
+			f.books[i] = int(r.read(8))
+			if f.books[i] >= bookCount {
+				return nil, errVorbisFloor
+			}
+		}
+		if f.barkMapSize == 0 {
+			return nil, errVorbisFloor
+		}
+		return f, nil
+	case 1:
+		f := &vorbisFloor1{}
+		f.partitionClass = make([]int, r.read(5))
+		maxClass := -1
+		for i := range f.partitionClass {
+			f.partitionClass[i] = int(r.read(4))
+			maxClass = max(maxClass, f.partitionClass[i])
+		}
+		f.classes = make([]vorbisFloor1Class, maxClass+1)
+		for i := range f.classes {
+			c := &f.classes[i]
+			c.dimensions = int(r.read(3)) + 1
+			c.subclasses = int(r.read(2))
+			if c.subclasses > 0 {
+				c.masterbook = int(r.read(8))
+				if c.masterbook >= bookCount {
+					return nil, errVorbisFloor
+				}
+			}
+			c.subclassBooks = make([]int, 1<<c.subclasses)
+			for j := range c.subclassBooks {
+				c.subclassBooks[j] = int(r.read(8)) - 1
+				if c.subclassBooks[j] >= bookCount {
+					return nil, errVorbisFloor
+				}
+			}
+		}
+		f.multiplier = int(r.read(2)) + 1
+		rangeBits := int(r.read(4))
+		f.xList = []int{0, 1 << rangeBits}
+		for _, class := range f.partitionClass {
+			for j := 0; j < f.classes[class].dimensions; j++ {
+				f.xList = append(f.xList, int(r.read(rangeBits)))
+			}
+		}
+		f.prepare()
+		return f, nil
+	}
+	return nil, errVorbisFloor
+}
+
+func (f *vorbisFloor1) prepare() {
+	count := len(f.xList)
+	f.sorted = make([]int, count)
+	for i := range f.sorted {
+		f.sorted[i] = i
+	}
+	sort.SliceStable(f.sorted, func(a, b int) bool {
+		return f.xList[f.sorted[a]] < f.xList[f.sorted[b]]
+	})
+	f.lowNeighbor = make([]int, count)
+	f.highNeighbor = make([]int, count)
+	for i := 2; i < count; i++ {
+		low, high := 0, 1
+		lowX, highX := -1, math.MaxInt
+		x := f.xList[i]
+		for j := 0; j < i; j++ {
+			xj := f.xList[j]
+			if xj < x && xj > lowX {
+				low, lowX = j, xj
+			}
+			if xj > x && xj < highX {
+				high, highX = j, xj
+			}
+		}
+		f.lowNeighbor[i] = low
+		f.highNeighbor[i] = high
+	}
+}
+
+func (f *vorbisFloor1) decode(r *lsbBitReader, books []vorbisCodebook, data *vorbisFloorData) bool {
+	if !r.readBool() {
+		return false
+	}
+	rng := vorbisFloor1Ranges[f.multiplier-1]
+	bits := ilog(rng - 1)
+	data.values = growSlice(data.values, len(f.xList))
+	y := data.values
+	y[0] = int(r.read(bits))
+	y[1] = int(r.read(bits))
+	offset := 2
+	for _, classIdx := range f.partitionClass {
+		class := &f.classes[classIdx]
+		csub := 1<<class.subclasses - 1
+		cval := 0
+		if class.subclasses > 0 {
+			cval = books[class.masterbook].decodeScalar(r)
+			if cval < 0 {
+				return false
+			}
+		}
+		for j := 0; j < class.dimensions; j++ {
+			book := class.subclassBooks[cval&csub]
+			cval >>= class.subclasses
+			y[offset+j] = 0
+			if book >= 0 {
+				v := books[book].decodeScalar(r)
+				if v < 0 {
+					return false
+				}
+				y[offset+j] = v
+			}
+		}
+		offset += class.dimensions
+	}
+	return true
+}
+
+func (f *vorbisFloor1) apply(data *vorbisFloorData, spectrum []float32) {
+	n := len(spectrum)
+	count := len(f.xList)
+	rng := vorbisFloor1Ranges[f.multiplier-1]
+	y := data.values
+	data.finalY = growSlice(data.finalY, count)
+	data.step2 = growSlice(data.step2, count)
+	finalY, step2 := data.finalY, data.step2
+	finalY[0], finalY[1] = y[0], y[1]
+	step2[0], step2[1] = true, true
+	for i := 2; i < count; i++ {
+		low, high := f.lowNeighbor[i], f.highNeighbor[i]
+		predicted := vorbisRenderPoint(f.xList[low], finalY[low],
+			f.xList[high], finalY[high], f.xList[i])
+		val := y[i]
+		highRoom := rng - predicted
+		lowRoom := predicted
+		room := lowRoom * 2
+		if highRoom < lowRoom {
+			room = highRoom * 2
+		}
+		if val == 0 {
+			finalY[i] = predicted
+			step2[i] = false
+			continue
+		}
+		step2[low], step2[high], step2[i] = true, true, true
+		if val >= room {
+			if highRoom > lowRoom {
+				finalY[i] = val - lowRoom + predicted
+			} else {
+				finalY[i] = predicted - val + highRoom - 1
+			}
+		} else if val&1 == 1 {
+			finalY[i] = predicted - (val+1)/2
+		} else {
+			finalY[i] = predicted + val/2
+		}
+	}
+	data.curve = growSlice(data.curve, n)
+	floor := data.curve
+	lx, hx := 0, 0
+	ly := finalY[f.sorted[0]] * f.multiplier
+	hy := 0
+	for _, idx := range f.sorted[1:] {
+		if !step2[idx] {
+			continue
+		}
+		hy = finalY[idx] * f.multiplier
+		hx = f.xList[idx]
+		vorbisRenderLine(lx, ly, hx, hy, floor)
+		lx, ly = hx, hy
+	}
+	if hx < n {
+		vorbisRenderLine(hx, hy, n, hy, floor)
+	}
+	for i := range spectrum {
+		spectrum[i] *= vorbisInverseDB[klib.Clamp(floor[i], 0, 255)]
+	}
+}
+
+func vorbisRenderPoint(x0, y0, x1, y1, x int) int {
+	dy := y1 - y0
+	adx := x1 - x0
+	ady := dy
+	if ady < 0 {
+		ady = -ady
+	}
+	off := ady * (x - x0) / adx
+	if dy < 0 {
+		return y0 - off
+	}
+	return y0 + off
+}
+
+func vorbisRenderLine(x0, y0, x1, y1 int, v []int) {
+	dy := y1 - y0
+	adx := x1 - x0
+	ady := dy
+	if ady < 0 {
+		ady = -ady
+	}
+	base := dy / adx
+	sy := base + 1
+	if dy < 0 {
+		sy = base - 1
+	}
+	absBase := base
+	if absBase < 0 {
+		absBase = -absBase
+	}
+	ady -= absBase * adx
+	y := y0
+	err := 0
+	if x0 < len(v) {
+		v[x0] = y
+	}
+	for x := x0 + 1; x < x1 && x < len(v); x++ {
+		err += ady
+		if err >= adx {
+			err -= adx
+			y += sy
+		} else {
+			y += base
+		}
+		v[x] = y
+	}
+}
+
+func (f *vorbisFloor0) decode(r *lsbBitReader, books []vorbisCodebook, data *vorbisFloorData) bool {
+	data.amp = int(r.read(f.amplitudeBits))
+	if data.amp == 0 {
+		return false
+	}
+	bookIdx := int(r.read(ilog(len(f.books))))
+	if bookIdx >= len(f.books) {
+		return false
+	}
+	book := &books[f.books[bookIdx]]
+	data.coeffs = data.coeffs[:0]
+	last := float32(0)
+	for len(data.coeffs) < f.order {
+		vec := book.decodeVector(r)
+		if vec == nil {
+			return false
+		}
+		for _, v := range vec {
+			data.coeffs = append(data.coeffs, v+last)
+		}
+		last = data.coeffs[len(data.coeffs)-1]
+	}
+	data.coeffs = data.coeffs[:f.order]
+	return true
+}
+
+func vorbisBark(x float64) float64 {
+	return 13.1*math.Atan(0.00074*x) + 2.24*math.Atan(0.0000000185*x*x) + 0.0001*x
+}
+
+func (f *vorbisFloor0) barkMap(n int) []int {
+	if m, ok := f.maps[n]; ok {
+		return m
+	}
+	m := make([]int, n+1)
+	scale := float64(f.barkMapSize) / vorbisBark(0.5*float64(f.rate))
+	for i := 0; i < n; i++ {
+		v := int(math.Floor(vorbisBark(float64(f.rate)*float64(i)/(2*float64(n))) * scale))
+		m[i] = min(f.barkMapSize-1, v)
+	}
+	m[n] = -1
+	f.maps[n] = m
+	return m
+}
+
+func (f *vorbisFloor0) apply(data *vorbisFloorData, spectrum []float32) {
+	n := len(spectrum)
+	m := f.barkMap(n)
+	cosCoeffs := make([]float64, f.order)
+	for i, c := range data.coeffs {
+		cosCoeffs[i] = math.Cos(float64(c))
+	}
+	ampScale := float64(data.amp) * float64(f.amplitudeOffset) / float64(int(1)<<f.amplitudeBits-1)
+	for i := 0; i < n; {
+		omega := math.Pi * float64(m[i]) / float64(f.barkMapSize)
+		cw := math.Cos(omega)
+		var p, q float64
+		if f.order&1 == 1 {
+			p = 1 - cw*cw
+			q = 0.25
+			for j := 0; j < (f.order-1)/2; j++ {
+				d := cosCoeffs[2*j+1] - cw
+				p *= 4 * d * d
+			}
+			for j := 0; j <= (f.order-1)/2; j++ {
+				d := cosCoeffs[2*j] - cw
+				q *= 4 * d * d
+			}
+		} else {
+			p = (1 - cw) / 2
+			q = (1 + cw) / 2
+			for j := 0; j < f.order/2; j++ {
+				d := cosCoeffs[2*j+1] - cw
+				p *= 4 * d * d
+				d = cosCoeffs[2*j] - cw
+				q *= 4 * d * d
+			}
+		}
+		value := float32(math.Exp(0.11512925 * (ampScale/math.Sqrt(p+q) - float64(f.amplitudeOffset))))
+		current := m[i]
+		for i < n && m[i] == current {
+			spectrum[i] *= value
+			i++
+		}
+	}
+}
@@ -0,0 +1,202 @@
+/******************************************************************************/
+/* vorbis_residue.go                                                          */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package audio_codec
+
+import "errors"
+
+var errVorbisResidue = errors.New("vorbis: invalid residue")
+
+type vorbisResidue struct {
+	kind            int
+	begin           int
+	end             int
+	partitionSize   int
+	classifications int
+	classbook       int
+	books           [][8]int
+	classes         []int
+	interleaved     []float32
+}
+
+func readVorbisResidue(r *lsbBitReader, books []vorbisCodebook) (*vorbisResidue, error) {
+	res := &vorbisResidue{kind: int(r.read(16))}
+	if res.kind > 2 {
+		return nil, errVorbisResidue
+	}
+	res.begin = int(r.read(24))
+	res.end = int(r.read(24))
+	res.partitionSize = int(r.read(24)) + 1
+	res.classifications = int(r.read(6)) + 1
+	res.classbook = int(r.read(8))
+	if res.classbook >= len(books) || books[res.classbook].dimensions == 0 {
+		return nil, errVorbisResidue
+	}
+	cascade := make([]int, res.classifications)
+	for i := range cascade {
+		low := int(r.read(3))
+		high := 0
+		if r.readBool() {
+			high = int(r.read(5))
+		}
+		cascade[i] = high<<3 | low
+	}
+	res.books = make([][8]int, res.classifications)
+	for i := range res.books {
+		for j := 0; j < 8; j++ {
+			res.books[i][j] = -1
+			if cascade[i]&(1<<j) != 0 {
+				res.books[i][j] = int(r.read(8))
+				if res.books[i][j] >= len(books) {
+					return nil, errVorbisResidue
+				}
+			}
+		}
+	}
+	return res, nil
+}
+
+// decode reads the residue vectors for the channels, the vectors must be
+// zeroed before calling and are half the block size in length
+func (res *vorbisResidue) decode(r *lsbBitReader, books []vorbisCodebook, vectors [][]float32, skip []bool) {
+	if res.kind != 2 {
+		res.decodeVectors(r, books, vectors, skip, res.kind)
+		return
+	}
+	// Type 2 interleaves all of the channels into a single vector and then
+	// decodes it the same as type 1
+	decode := false
+	for _, s := range skip {
+		decode = decode || !s
+	}
+	if !decode {
+		return
+	}
+	n := len(vectors[0])
+	channels := len(vectors)
+	res.interleaved = growSlice(res.interleaved, n*channels)
+	clear(res.interleaved)
+	res.decodeVectors(r, books, [][]float32{res.interleaved}, []bool{false}, 1)
+	for i := 0; i < n; i++ {
+		for ch := range vectors {
+			vectors[ch][i] = res.interleaved[i*channels+ch]
+		}
+	}
+}
+
+func (res *vorbisResidue) decodeVectors(r *lsbBitReader, books []vorbisCodebook, vectors [][]float32, skip []bool, kind int) {
+	size := len(vectors[0])
+	begin := min(res.begin, size)
+	end := min(res.end, size)
+	if end <= begin {
+		return
+	}
+	classbook := &books[res.classbook]
+	perCodeword := classbook.dimensions
+	partitions := (end - begin) / res.partitionSize
+	stride := partitions + perCodeword
+	res.classes = growSlice(res.classes, len(vectors)*stride)
+	for pass := 0; pass < 8; pass++ {
+		for count := 0; count < partitions; {
+			if pass == 0 {
+				for ch := range vectors {
+					if skip[ch] {
+						continue
+					}
+					temp := classbook.decodeScalar(r)
+					if temp < 0 {
+						return
+					}
+					for i := perCodeword - 1; i >= 0; i-- {
+						res.classes[ch*stride+count+i] = temp % res.classifications
+						temp /= res.classifications
+					}
+				}
+			}
+			for i := 0; i < perCodeword && count < partitions; i++ {
+				for ch := range vectors {
+					if skip[ch] {
+						continue
+					}
+					book := res.books[res.classes[ch*stride+count]][pass]
+					if book < 0 {
+						continue
+					}
+					offset := begin + count*res.partitionSize
+					out := vectors[ch][offset : offset+res.partitionSize]
+					if !res.decodePartition(r, &books[book], out, kind) {
+						return
+					}
+				}
+				count++
+			}
+		}
+	}
+}
+
+func (res *vorbisResidue) decodePartition(r *lsbBitReader, book *vorbisCodebook, out []float32, kind int) bool {
+	if book.dimensions == 0 {
+		return true
+	}
+	if kind == 0 {
+		step := len(out) / book.dimensions
+		for i := 0; i < step; i++ {
+			vec := book.decodeVector(r)
+			if vec == nil {
+				return false
+			}
+			for j, v := range vec {
+				out[i+j*step] += v
+			}
+		}
+		return true
+	}
+	for i := 0; i < len(out); {
+		vec := book.decodeVector(r)
+		if vec == nil {
+			return false
+		}
+		for _, v := range vec {
+			if i >= len(out) {
+				break
+			}
+			out[i] += v
+			i++
+		}
+	}
+	return true
+}
@@ -86,6 +86,12 @@ func LoadWav(assetDatabase *assets.Database, wavFile string) (*Wav, error) {
 	if err != nil {
 		return nil, err
 	}
+	return ParseWav(data)
+}
+
+// ParseWav reads the header of the RIFF WAVE file data, the returned wav
+// references the data rather than copying it
+func ParseWav(data []byte) (*Wav, error) {
 	if len(data) == 0 {
 		return nil, errors.New("empty file")
 	}
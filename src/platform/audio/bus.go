@@ -0,0 +1,131 @@
+/******************************************************************************/
+/* bus.go                                                                     */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package audio
+
+const (
+	BusMaster = "master"
+	BusMusic  = "music"
+	BusSfx    = "sfx"
+	BusVoice  = "voice"
+)
+
+// Bus is a named mixing group that voices are played through. Buses form a
+// hierarchy where each bus (other than master) outputs into a parent bus, so
+// that changing the volume or muting a bus affects all of its children.
+type Bus struct {
+	mixer   *Mixer
+	name    string
+	parent  *Bus
+	volume  float32
+	muted   bool
+	buffer  []float32
+	effects EffectChain
+}
+
+// Name returns the name the bus was created with
+func (b *Bus) Name() string { return b.name }
+
+// Parent returns the bus that this bus outputs into, nil for master
+func (b *Bus) Parent() *Bus { return b.parent }
+
+// Volume returns the volume of this bus, not including the volume of any of
+// its parents
+func (b *Bus) Volume() float32 {
+	b.mixer.mutex.Lock()
+	defer b.mixer.mutex.Unlock()
+	return b.volume
+}
+
+// SetVolume sets the linear volume (0 to 1) of this bus
+func (b *Bus) SetVolume(volume float32) {
+	b.mixer.mutex.Lock()
+	defer b.mixer.mutex.Unlock()
+	b.volume = max(0, volume)
+}
+
+func (b *Bus) IsMuted() bool {
+	b.mixer.mutex.Lock()
+	defer b.mixer.mutex.Unlock()
+	return b.muted
+}
+
+// SetMuted will silence this bus (and all of its children) without changing
+// the volume so it can be restored later
+func (b *Bus) SetMuted(muted bool) {
+	b.mixer.mutex.Lock()
+	defer b.mixer.mutex.Unlock()
+	b.muted = muted
+}
+
+// AddEffect appends the effect to the end of the bus effect chain, effects
+// process the mix of everything played through the bus (and its children)
+// before the bus volume is applied
+func (b *Bus) AddEffect(effect Effect) {
+	b.mixer.mutex.Lock()
+	defer b.mixer.mutex.Unlock()
+	b.effects.add(effect)
+}
+
+// RemoveEffect removes the effect from the bus effect chain
+func (b *Bus) RemoveEffect(effect Effect) {
+	b.mixer.mutex.Lock()
+	defer b.mixer.mutex.Unlock()
+	b.effects.remove(effect)
+}
+
+// ClearEffects removes all of the effects from the bus
+func (b *Bus) ClearEffects() {
+	b.mixer.mutex.Lock()
+	defer b.mixer.mutex.Unlock()
+	b.effects = EffectChain{}
+}
+
+func (b *Bus) gain() float32 {
+	if b.muted {
+		return 0
+	}
+	return b.volume
+}
+
+func (b *Bus) prepare(samples int) {
+	if cap(b.buffer) < samples {
+		b.buffer = make([]float32, samples)
+	}
+	b.buffer = b.buffer[:samples]
+	clear(b.buffer)
+}
@@ -0,0 +1,226 @@
+/******************************************************************************/
+/* clip.go                                                                    */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package audio
+
+import (
+	"kaiju/engine/assets"
+	"kaiju/klib"
+	"kaiju/platform/audio/audio_codec"
+	"kaiju/platform/audio/audio_system"
+	"math"
+)
+
+// Clip is a fully loaded sound that has been converted into interleaved
+// float32 samples. Clips are created once and can be played by any number of
+// voices at the same time.
+type Clip struct {
+	Samples    []float32
+	Channels   int
+	SampleRate int
+	// Volume is the default linear volume of the clip, it is multiplied with
+	// the volume of any voice playing the clip
+	Volume float32
+	// LoopStart is the frame that a looping voice will jump back to once it
+	// reaches LoopEnd, this allows a clip to have an intro that isn't looped
+	LoopStart int
+	// LoopEnd is the frame (exclusive) at which a looping voice jumps back
+	// to LoopStart, 0 will loop at the end of the clip
+	LoopEnd int
+}
+
+// Frames returns the number of frames (a sample for each channel) in the clip
+func (c *Clip) Frames() int {
+	if c.Channels == 0 {
+		return 0
+	}
+	return len(c.Samples) / c.Channels
+}
+
+// Duration returns the length of the clip in seconds
+func (c *Clip) Duration() float32 {
+	if c.SampleRate == 0 {
+		return 0
+	}
+	return float32(c.Frames()) / float32(c.SampleRate)
+}
+
+// SetLoopPoints sets the loop region of the clip in seconds, an end of 0 (or
+// past the end of the clip) will loop at the end of the clip
+func (c *Clip) SetLoopPoints(start, end float32) {
+	frames := c.Frames()
+	c.LoopStart = min(max(0, int(start*float32(c.SampleRate))), frames)
+	c.LoopEnd = min(max(0, int(end*float32(c.SampleRate))), frames)
+	if c.LoopEnd <= c.LoopStart {
+		c.LoopEnd = 0
+	}
+}
+
+// loopRegion returns the start and (exclusive) end frame of the loop region
+func (c *Clip) loopRegion() (int, int) {
+	frames := c.Frames()
+	end := c.LoopEnd
+	if end <= 0 || end > frames {
+		end = frames
+	}
+	start := c.LoopStart
+	if start < 0 || start >= end {
+		start = 0
+	}
+	return start, end
+}
+
+func (c *Clip) sample(frame, channel int) float32 {
+	return c.Samples[frame*c.Channels+channel%c.Channels]
+}
+
+// NewClipFromWav converts the wav data into a clip using the given sample
+// rate and channel count
+func NewClipFromWav(wav *audio_system.Wav, sampleRate, channels int) *Clip {
+	clip := &Clip{
+		Samples:    wavToFloat(wav),
+		Channels:   int(wav.Channels),
+		SampleRate: int(wav.SampleRate),
+		Volume:     1,
+	}
+	clip.rechannel(channels)
+	clip.resample(sampleRate)
+	return clip
+}
+
+// NewClipFromPCM converts the decoded audio into a clip using the given
+// sample rate and channel count
+func NewClipFromPCM(pcm audio_codec.PCM, sampleRate, channels int) *Clip {
+	clip := &Clip{
+		Samples:    pcm.Samples,
+		Channels:   pcm.Channels,
+		SampleRate: pcm.SampleRate,
+		Volume:     1,
+	}
+	clip.rechannel(channels)
+	clip.resample(sampleRate)
+	return clip
+}
+
+// LoadClip reads the audio file from the asset database and converts it into
+// a clip using the given sample rate and channel count. WAV files as well as
+// any of the formats supported by audio_codec (Ogg Vorbis, MP3, and FLAC)
+// can be loaded.
+func LoadClip(assetDatabase *assets.Database, file string, sampleRate, channels int) (*Clip, error) {
+	data, err := assetDatabase.Read(file)
+	if err != nil {
+		return nil, err
+	}
+	return DecodeClip(file, data, sampleRate, channels)
+}
+
+// DecodeClip decodes the audio file data into a clip using the given sample
+// rate and channel count, the file extension selects the decoder to use. Any
+// file that isn't supported by audio_codec is treated as a WAV file.
+func DecodeClip(file string, data []byte, sampleRate, channels int) (*Clip, error) {
+	if !audio_codec.IsSupported(file) {
+		wav, err := audio_system.ParseWav(data)
+		if err != nil {
+			return nil, err
+		}
+		return NewClipFromWav(wav, sampleRate, channels), nil
+	}
+	pcm, err := audio_codec.Decode(file, data)
+	if err != nil {
+		return nil, err
+	}
+	return NewClipFromPCM(pcm, sampleRate, channels), nil
+}
+
+func wavToFloat(wav *audio_system.Wav) []float32 {
+	if wav.FormatType == audio_system.WavFormatFloat {
+		src := klib.ByteSliceToFloat32Slice(wav.WavData)
+		out := make([]float32, len(src))
+		copy(out, src)
+		return out
+	}
+	src := klib.ByteSliceToUInt16Slice(wav.WavData)
+	out := make([]float32, len(src))
+	for i := range src {
+		out[i] = float32(int16(src[i])) / math.MaxInt16
+	}
+	return out
+}
+
+func (c *Clip) rechannel(channels int) {
+	if c.Channels == channels || c.Channels == 0 {
+		return
+	}
+	out := make([]float32, 0, c.Frames()*channels)
+	c.Samples = rechannelSamples(c.Samples, c.Channels, channels, out)
+	c.Channels = channels
+}
+
+// rechannelSamples converts the interleaved samples from one channel count to
+// another and appends them to out. Down-mixing averages all of the channels
+// and up-mixing repeats the source channels.
+func rechannelSamples(in []float32, from, to int, out []float32) []float32 {
+	frames := len(in) / from
+	for f := 0; f < frames; f++ {
+		src := in[f*from : (f+1)*from]
+		if to < from {
+			sum := float32(0)
+			for i := range src {
+				sum += src[i]
+			}
+			for i := 0; i < to; i++ {
+				out = append(out, sum/float32(from))
+			}
+		} else {
+			for i := 0; i < to; i++ {
+				out = append(out, src[i%from])
+			}
+		}
+	}
+	return out
+}
+
+func (c *Clip) resample(sampleRate int) {
+	if c.SampleRate == sampleRate || c.SampleRate == 0 {
+		return
+	}
+	r := NewResampler(c.SampleRate, sampleRate, c.Channels)
+	expected := int64(c.Frames())*int64(sampleRate)/int64(c.SampleRate) + 1
+	out := make([]float32, 0, expected*int64(c.Channels))
+	c.Samples = r.Flush(r.Process(c.Samples, out))
+	c.SampleRate = sampleRate
+}
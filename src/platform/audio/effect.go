@@ -0,0 +1,179 @@
+/******************************************************************************/
+/* effect.go                                                                  */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package audio
+
+import (
+	"math"
+	"sync"
+)
+
+// paramSmoothSeconds is how long a parameter takes to reach a value given
+// to Set, this prevents clicks and zipper noise when changing parameters
+const paramSmoothSeconds = 0.02
+
+// Effect processes interleaved float32 samples in place. Effects are run on
+// the audio goroutine through an EffectChain that is attached to a Bus or a
+// voice, their parameters (see Param) are safe to change from any goroutine.
+type Effect interface {
+	// Process applies the effect to the interleaved samples in place, the
+	// channel count and sample rate will match the mixer output
+	Process(samples []float32, channels, sampleRate int)
+	// Reset clears any internal state (delay lines, envelopes, etc.)
+	Reset()
+}
+
+// Param is an automatable effect parameter. Values can be set from any
+// goroutine while the effect is processing on the audio goroutine. Set will
+// quickly smooth to the new value and RampTo can be used to automate the
+// parameter towards a value over time (e.g. muffling sound when the camera
+// goes under water).
+type Param struct {
+	mutex    sync.Mutex
+	target   float32
+	seconds  float32
+	changed  bool
+	value    float32
+	step     float32
+	frames   int
+	min, max float32
+}
+
+func newParam(value, minValue, maxValue float32) Param {
+	value = min(max(value, minValue), maxValue)
+	return Param{target: value, value: value, min: minValue, max: maxValue}
+}
+
+// Target returns the value that the parameter is set to (or ramping to)
+func (p *Param) Target() float32 {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	return p.target
+}
+
+// Set changes the value of the parameter, the value is clamped to the range
+// of the parameter
+func (p *Param) Set(value float32) { p.RampTo(value, paramSmoothSeconds) }
+
+// RampTo moves the parameter linearly to the value over the given number of
+// seconds, the value is clamped to the range of the parameter
+func (p *Param) RampTo(value, seconds float32) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.target = min(max(value, p.min), p.max)
+	p.seconds = max(0, seconds)
+	p.changed = true
+}
+
+// Jump immediately sets the parameter to the value without any smoothing
+func (p *Param) Jump(value float32) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.target = min(max(value, p.min), p.max)
+	p.seconds = 0
+	p.changed = true
+}
+
+// advance moves the parameter forward by the number of frames and returns
+// the value to use for them, this is called from the audio goroutine
+func (p *Param) advance(frames, sampleRate int) float32 {
+	p.mutex.Lock()
+	if p.changed {
+		p.changed = false
+		p.frames = int(p.seconds * float32(sampleRate))
+		if p.frames <= 0 {
+			p.value = p.target
+		} else {
+			p.step = (p.target - p.value) / float32(p.frames)
+		}
+	}
+	p.mutex.Unlock()
+	if p.frames > 0 {
+		n := min(frames, p.frames)
+		p.value += p.step * float32(n)
+		p.frames -= n
+		if p.frames == 0 {
+			p.value = p.target
+		}
+	}
+	return p.value
+}
+
+// EffectChain is an ordered list of effects, the output of each effect is
+// the input of the next
+type EffectChain struct {
+	effects []Effect
+}
+
+func (c *EffectChain) add(effect Effect) {
+	c.effects = append(c.effects, effect)
+}
+
+func (c *EffectChain) remove(effect Effect) {
+	for i := range c.effects {
+		if c.effects[i] == effect {
+			c.effects = append(c.effects[:i], c.effects[i+1:]...)
+			return
+		}
+	}
+}
+
+func (c *EffectChain) len() int { return len(c.effects) }
+
+func (c *EffectChain) process(samples []float32, channels, sampleRate int) {
+	for i := range c.effects {
+		c.effects[i].Process(samples, channels, sampleRate)
+	}
+}
+
+// DecibelsToGain converts decibels into a linear gain
+func DecibelsToGain(db float32) float32 {
+	return float32(math.Pow(10, float64(db)/20))
+}
+
+// GainToDecibels converts a linear gain into decibels
+func GainToDecibels(gain float32) float32 {
+	return float32(20 * math.Log10(max(float64(gain), 1e-9)))
+}
+
+// growChannelState makes sure there is a state for each channel
+func growChannelState[T any](s []T, channels int) []T {
+	if len(s) < channels {
+		s = append(s, make([]T, channels-len(s))...)
+	}
+	return s
+}
@@ -0,0 +1,115 @@
+/******************************************************************************/
+/* effect_compressor.go                                                       */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package audio
+
+import "math"
+
+// Compressor reduces the dynamic range of the signal by lowering the volume
+// of anything louder than the threshold. The channels are linked so that
+// the stereo image doesn't shift when only one side is loud. A limiter is a
+// compressor with a very high ratio and a fast attack, see NewLimiter.
+type Compressor struct {
+	// Threshold is the level (in dB) that compression starts at
+	Threshold Param
+	// Ratio is how much the level above the threshold is reduced, a ratio
+	// of 4 will turn a signal 8dB over the threshold into 2dB over
+	Ratio Param
+	// Attack is the time (in seconds) to react to the signal getting louder
+	Attack Param
+	// Release is the time (in seconds) to recover once the signal is quieter
+	Release Param
+	// Makeup is the gain (in dB) applied after compression
+	Makeup   Param
+	envelope float32
+}
+
+func NewCompressor(threshold, ratio float32) *Compressor {
+	return &Compressor{
+		Threshold: newParam(threshold, -60, 0),
+		Ratio:     newParam(ratio, 1, 100),
+		Attack:    newParam(0.01, 0.0001, 1),
+		Release:   newParam(0.1, 0.001, 5),
+		Makeup:    newParam(0, 0, 24),
+	}
+}
+
+// NewLimiter creates a compressor that keeps the signal from going above
+// the threshold (in dB)
+func NewLimiter(threshold float32) *Compressor {
+	c := NewCompressor(threshold, 100)
+	c.Attack.Jump(0.0005)
+	c.Release.Jump(0.05)
+	return c
+}
+
+func (c *Compressor) Reset() { c.envelope = 0 }
+
+func timeCoefficient(seconds float32, sampleRate int) float32 {
+	return float32(math.Exp(-1 / (float64(seconds) * float64(sampleRate))))
+}
+
+func (c *Compressor) Process(samples []float32, channels, sampleRate int) {
+	frames := len(samples) / channels
+	threshold := c.Threshold.advance(frames, sampleRate)
+	ratio := c.Ratio.advance(frames, sampleRate)
+	attack := timeCoefficient(c.Attack.advance(frames, sampleRate), sampleRate)
+	release := timeCoefficient(c.Release.advance(frames, sampleRate), sampleRate)
+	makeup := DecibelsToGain(c.Makeup.advance(frames, sampleRate))
+	thresholdGain := DecibelsToGain(threshold)
+	slope := 1 - 1/ratio
+	for f := 0; f < frames; f++ {
+		frame := samples[f*channels : (f+1)*channels]
+		peak := float32(0)
+		for _, s := range frame {
+			peak = max(peak, float32(math.Abs(float64(s))))
+		}
+		coef := release
+		if peak > c.envelope {
+			coef = attack
+		}
+		c.envelope = peak + (c.envelope-peak)*coef
+		gain := makeup
+		if c.envelope > thresholdGain {
+			over := GainToDecibels(c.envelope) - threshold
+			gain *= DecibelsToGain(-over * slope)
+		}
+		for i := range frame {
+			frame[i] *= gain
+		}
+	}
+}
@@ -0,0 +1,105 @@
+/******************************************************************************/
+/* effect_delay.go                                                            */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package audio
+
+// maxDelaySeconds is the longest delay time a Delay can be set to
+const maxDelaySeconds = 4
+
+// Delay is an echo effect, the delayed signal is fed back into the delay
+// line so that each echo is quieter than the last
+type Delay struct {
+	// Time is the time (in seconds) between each echo
+	Time Param
+	// Feedback is how much of each echo is fed back into the delay (0 to 1)
+	Feedback Param
+	// Mix blends between the dry (0) and delayed (1) signal
+	Mix   Param
+	lines [][]float32
+	write int
+	rate  int
+}
+
+func NewDelay(seconds, feedback float32) *Delay {
+	return &Delay{
+		Time:     newParam(seconds, 0.001, maxDelaySeconds),
+		Feedback: newParam(feedback, 0, 0.98),
+		Mix:      newParam(0.5, 0, 1),
+	}
+}
+
+func (d *Delay) Reset() {
+	for i := range d.lines {
+		clear(d.lines[i])
+	}
+	d.write = 0
+}
+
+func (d *Delay) Process(samples []float32, channels, sampleRate int) {
+	frames := len(samples) / channels
+	if d.rate != sampleRate || len(d.lines) < channels {
+		d.rate = sampleRate
+		d.lines = make([][]float32, channels)
+		for i := range d.lines {
+			d.lines[i] = make([]float32, maxDelaySeconds*sampleRate+2)
+		}
+		d.write = 0
+	}
+	// The delay time is interpolated across the block so that automating it
+	// bends the pitch of the echoes rather than clicking
+	startTime := d.Time.value
+	endTime := d.Time.advance(frames, sampleRate)
+	feedback := d.Feedback.advance(frames, sampleRate)
+	mix := d.Mix.advance(frames, sampleRate)
+	size := len(d.lines[0])
+	for f := 0; f < frames; f++ {
+		t := startTime + (endTime-startTime)*float32(f)/float32(frames)
+		delay := t * float32(sampleRate)
+		whole := int(delay)
+		frac := delay - float32(whole)
+		r0 := (d.write - whole + size) % size
+		r1 := (r0 - 1 + size) % size
+		for c := 0; c < channels; c++ {
+			line := d.lines[c]
+			echo := line[r0] + (line[r1]-line[r0])*frac
+			x := samples[f*channels+c]
+			line[d.write] = x + echo*feedback
+			samples[f*channels+c] = x + (echo-x)*mix
+		}
+		d.write = (d.write + 1) % size
+	}
+}
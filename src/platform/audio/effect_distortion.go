@@ -0,0 +1,73 @@
+/******************************************************************************/
+/* effect_distortion.go                                                       */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package audio
+
+import "math"
+
+// Distortion is a soft clipping (tanh) waveshaper
+type Distortion struct {
+	// Drive is the gain applied before clipping, higher is more distorted
+	Drive Param
+	// Mix blends between the dry (0) and distorted (1) signal
+	Mix Param
+	// Output is the linear gain applied to the distorted signal
+	Output Param
+}
+
+func NewDistortion(drive float32) *Distortion {
+	return &Distortion{
+		Drive:  newParam(drive, 1, 100),
+		Mix:    newParam(1, 0, 1),
+		Output: newParam(1, 0, 4),
+	}
+}
+
+func (d *Distortion) Reset() {}
+
+func (d *Distortion) Process(samples []float32, channels, sampleRate int) {
+	frames := len(samples) / channels
+	drive := d.Drive.advance(frames, sampleRate)
+	mix := d.Mix.advance(frames, sampleRate)
+	output := d.Output.advance(frames, sampleRate)
+	// Normalize so that a full scale input is still full scale when clipped
+	norm := 1 / float32(math.Tanh(float64(drive)))
+	for i, x := range samples {
+		wet := float32(math.Tanh(float64(x*drive))) * norm * output
+		samples[i] = x + (wet-x)*mix
+	}
+}
@@ -0,0 +1,166 @@
+/******************************************************************************/
+/* effect_filter.go                                                           */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package audio
+
+import (
+	"math"
+	"sync"
+)
+
+type FilterType int
+
+const (
+	FilterLowPass FilterType = iota
+	FilterHighPass
+)
+
+type biquadState struct {
+	x1, x2, y1, y2 float32
+}
+
+// Filter is a 2-pole (biquad) low-pass or high-pass filter. A low-pass
+// filter with a low cutoff can be used to muffle sound, for example when
+// the listener is under water or behind a wall.
+type Filter struct {
+	// Cutoff is the frequency (in Hz) where the filter starts cutting
+	Cutoff Param
+	// Resonance is the Q of the filter, 0.707 gives a flat response
+	Resonance Param
+	state     []biquadState
+	b0, b1    float32
+	b2, a1    float32
+	a2        float32
+	cutoff    float32
+	resonance float32
+	rate      int
+	// filterType is set from any goroutine through SetType, typeChanged has
+	// the coefficients computed again on the audio goroutine
+	mutex       sync.Mutex
+	filterType  FilterType
+	typeChanged bool
+}
+
+// NewLowPassFilter creates a filter that removes frequencies above the cutoff
+func NewLowPassFilter(cutoff float32) *Filter {
+	return newFilter(FilterLowPass, cutoff)
+}
+
+// NewHighPassFilter creates a filter that removes frequencies below the cutoff
+func NewHighPassFilter(cutoff float32) *Filter {
+	return newFilter(FilterHighPass, cutoff)
+}
+
+func newFilter(filterType FilterType, cutoff float32) *Filter {
+	return &Filter{
+		filterType: filterType,
+		Cutoff:     newParam(cutoff, 10, 24000),
+		Resonance:  newParam(math.Sqrt2/2, 0.1, 20),
+	}
+}
+
+// Type returns if the filter is a low-pass or high-pass filter
+func (f *Filter) Type() FilterType {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	return f.filterType
+}
+
+// SetType changes the filter between low-pass and high-pass, this is safe to
+// call while the filter is processing
+func (f *Filter) SetType(filterType FilterType) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	if f.filterType != filterType {
+		f.filterType = filterType
+		f.typeChanged = true
+	}
+}
+
+func (f *Filter) Reset() { clear(f.state) }
+
+// updateCoefficients computes the filter coefficients using the formulas
+// from the Audio EQ Cookbook (Robert Bristow-Johnson)
+func (f *Filter) updateCoefficients(cutoff, resonance float32, sampleRate int) {
+	f.mutex.Lock()
+	filterType, typeChanged := f.filterType, f.typeChanged
+	f.typeChanged = false
+	f.mutex.Unlock()
+	if !typeChanged && cutoff == f.cutoff && resonance == f.resonance && sampleRate == f.rate {
+		return
+	}
+	f.cutoff, f.resonance, f.rate = cutoff, resonance, sampleRate
+	nyquist := float64(sampleRate) * 0.5
+	w0 := 2 * math.Pi * min(float64(cutoff), nyquist*0.99) / float64(sampleRate)
+	cosW0 := math.Cos(w0)
+	alpha := math.Sin(w0) / (2 * float64(resonance))
+	a0 := 1 + alpha
+	var b0, b1, b2 float64
+	switch filterType {
+	case FilterHighPass:
+		b0 = (1 + cosW0) / 2
+		b1 = -(1 + cosW0)
+		b2 = b0
+	default:
+		b0 = (1 - cosW0) / 2
+		b1 = 1 - cosW0
+		b2 = b0
+	}
+	f.b0 = float32(b0 / a0)
+	f.b1 = float32(b1 / a0)
+	f.b2 = float32(b2 / a0)
+	f.a1 = float32(-2 * cosW0 / a0)
+	f.a2 = float32((1 - alpha) / a0)
+}
+
+func (f *Filter) Process(samples []float32, channels, sampleRate int) {
+	frames := len(samples) / channels
+	f.updateCoefficients(f.Cutoff.advance(frames, sampleRate),
+		f.Resonance.advance(frames, sampleRate), sampleRate)
+	f.state = growChannelState(f.state, channels)
+	for c := 0; c < channels; c++ {
+		s := f.state[c]
+		for i := c; i < len(samples); i += channels {
+			x := samples[i]
+			y := f.b0*x + f.b1*s.x1 + f.b2*s.x2 - f.a1*s.y1 - f.a2*s.y2
+			s.x2, s.x1 = s.x1, x
+			s.y2, s.y1 = s.y1, y
+			samples[i] = y
+		}
+		f.state[c] = s
+	}
+}
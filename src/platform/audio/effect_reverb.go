@@ -0,0 +1,208 @@
+/******************************************************************************/
+/* effect_reverb.go                                                           */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package audio
+
+// The Freeverb tunings (by Jezar at Dreampoint) are in samples at 44.1kHz
+// and are scaled to the sample rate of the mixer
+var (
+	reverbCombTuning    = [...]int{1116, 1188, 1277, 1356, 1422, 1491, 1557, 1617}
+	reverbAllpassTuning = [...]int{556, 441, 341, 225}
+)
+
+const (
+	reverbStereoSpread  = 23
+	reverbInputGain     = 0.015
+	reverbScaleWet      = 3
+	reverbScaleDamping  = 0.4
+	reverbScaleRoom     = 0.28
+	reverbOffsetRoom    = 0.7
+	reverbAllpassFactor = 0.5
+	reverbTuningRate    = 44100
+)
+
+// ReverbPreset is a set of reverb parameters that describe a space, use
+// Reverb.RampToPreset to change the reverb as the listener moves between
+// rooms
+type ReverbPreset struct {
+	RoomSize float32
+	Damping  float32
+	Wet      float32
+	Dry      float32
+	Width    float32
+}
+
+var (
+	ReverbPresetNone       = ReverbPreset{RoomSize: 0, Damping: 0.5, Wet: 0, Dry: 1, Width: 1}
+	ReverbPresetSmallRoom  = ReverbPreset{RoomSize: 0.4, Damping: 0.6, Wet: 0.2, Dry: 1, Width: 1}
+	ReverbPresetHall       = ReverbPreset{RoomSize: 0.85, Damping: 0.4, Wet: 0.35, Dry: 0.9, Width: 1}
+	ReverbPresetCave       = ReverbPreset{RoomSize: 0.95, Damping: 0.2, Wet: 0.5, Dry: 0.8, Width: 1}
+	ReverbPresetUnderwater = ReverbPreset{RoomSize: 0.7, Damping: 0.9, Wet: 0.6, Dry: 0.6, Width: 0.5}
+)
+
+type reverbComb struct {
+	buffer []float32
+	index  int
+	store  float32
+}
+
+func (c *reverbComb) process(input, feedback, damp1, damp2 float32) float32 {
+	output := c.buffer[c.index]
+	c.store = output*damp2 + c.store*damp1
+	c.buffer[c.index] = input + c.store*feedback
+	if c.index++; c.index >= len(c.buffer) {
+		c.index = 0
+	}
+	return output
+}
+
+type reverbAllpass struct {
+	buffer []float32
+	index  int
+}
+
+func (a *reverbAllpass) process(input float32) float32 {
+	buffered := a.buffer[a.index]
+	a.buffer[a.index] = input + buffered*reverbAllpassFactor
+	if a.index++; a.index >= len(a.buffer) {
+		a.index = 0
+	}
+	return buffered - input
+}
+
+// Reverb simulates the reflections of a room using the Freeverb algorithm
+// (parallel comb filters followed by series all-pass filters per channel)
+type Reverb struct {
+	// RoomSize is the size of the room (0 to 1), larger rooms ring longer
+	RoomSize Param
+	// Damping is how much the high frequencies are absorbed (0 to 1)
+	Damping Param
+	// Wet is the level of the reverberated signal (0 to 1)
+	Wet Param
+	// Dry is the level of the original signal (0 to 1)
+	Dry Param
+	// Width is the stereo width of the reverb (0 to 1)
+	Width   Param
+	combs   [2][len(reverbCombTuning)]reverbComb
+	allpass [2][len(reverbAllpassTuning)]reverbAllpass
+	rate    int
+}
+
+func NewReverb(preset ReverbPreset) *Reverb {
+	return &Reverb{
+		RoomSize: newParam(preset.RoomSize, 0, 1),
+		Damping:  newParam(preset.Damping, 0, 1),
+		Wet:      newParam(preset.Wet, 0, 1),
+		Dry:      newParam(preset.Dry, 0, 1),
+		Width:    newParam(preset.Width, 0, 1),
+	}
+}
+
+// RampToPreset automates all of the parameters to the preset over the given
+// number of seconds
+func (r *Reverb) RampToPreset(preset ReverbPreset, seconds float32) {
+	r.RoomSize.RampTo(preset.RoomSize, seconds)
+	r.Damping.RampTo(preset.Damping, seconds)
+	r.Wet.RampTo(preset.Wet, seconds)
+	r.Dry.RampTo(preset.Dry, seconds)
+	r.Width.RampTo(preset.Width, seconds)
+}
+
+func (r *Reverb) Reset() {
+	for s := range r.combs {
+		for i := range r.combs[s] {
+			clear(r.combs[s][i].buffer)
+			r.combs[s][i].store = 0
+		}
+		for i := range r.allpass[s] {
+			clear(r.allpass[s][i].buffer)
+		}
+	}
+}
+
+func (r *Reverb) allocate(sampleRate int) {
+	r.rate = sampleRate
+	scale := func(length, spread int) int {
+		return max(1, (length+spread)*sampleRate/reverbTuningRate)
+	}
+	for s := range r.combs {
+		for i := range r.combs[s] {
+			r.combs[s][i] = reverbComb{
+				buffer: make([]float32, scale(reverbCombTuning[i], s*reverbStereoSpread)),
+			}
+		}
+		for i := range r.allpass[s] {
+			r.allpass[s][i] = reverbAllpass{
+				buffer: make([]float32, scale(reverbAllpassTuning[i], s*reverbStereoSpread)),
+			}
+		}
+	}
+}
+
+func (r *Reverb) Process(samples []float32, channels, sampleRate int) {
+	if r.rate != sampleRate {
+		r.allocate(sampleRate)
+	}
+	frames := len(samples) / channels
+	feedback := r.RoomSize.advance(frames, sampleRate)*reverbScaleRoom + reverbOffsetRoom
+	damp1 := r.Damping.advance(frames, sampleRate) * reverbScaleDamping
+	damp2 := 1 - damp1
+	wet := r.Wet.advance(frames, sampleRate) * reverbScaleWet
+	dry := r.Dry.advance(frames, sampleRate)
+	width := r.Width.advance(frames, sampleRate)
+	wet1 := wet * (width/2 + 0.5)
+	wet2 := wet * ((1 - width) / 2)
+	right := min(1, channels-1)
+	for f := 0; f < frames; f++ {
+		frame := samples[f*channels : (f+1)*channels]
+		inL, inR := frame[0], frame[right]
+		input := (inL + inR) * reverbInputGain
+		var outs [2]float32
+		for s := range outs {
+			for i := range r.combs[s] {
+				outs[s] += r.combs[s][i].process(input, feedback, damp1, damp2)
+			}
+			for i := range r.allpass[s] {
+				outs[s] = r.allpass[s][i].process(outs[s])
+			}
+		}
+		frame[0] = outs[0]*wet1 + outs[1]*wet2 + inL*dry
+		if right > 0 {
+			frame[right] = outs[1]*wet1 + outs[0]*wet2 + inR*dry
+		}
+	}
+}
@@ -0,0 +1,134 @@
+/******************************************************************************/
+/* effect_test.go                                                             */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package audio
+
+import (
+	"math"
+	"slices"
+	"testing"
+)
+
+func rms(samples []float32) float64 {
+	sum := 0.0
+	for _, s := range samples {
+		sum += float64(s) * float64(s)
+	}
+	return math.Sqrt(sum / float64(len(samples)))
+}
+
+func TestFilterResponse(t *testing.T) {
+	const rate = 48000
+	tests := []struct {
+		filter   *Filter
+		freq     float64
+		minRatio float64
+		maxRatio float64
+	}{
+		{NewLowPassFilter(1000), 100, 0.95, 1.05},
+		{NewLowPassFilter(1000), 10000, 0, 0.02},
+		{NewHighPassFilter(1000), 10000, 0.95, 1.05},
+		{NewHighPassFilter(1000), 100, 0, 0.02},
+	}
+	for i, test := range tests {
+		in := sineWave(rate, 2, rate, test.freq)
+		out := make([]float32, len(in))
+		copy(out, in)
+		test.filter.Process(out, 2, rate)
+		// Skip the first part of the output while the filter settles
+		ratio := rms(out[rate/2:]) / rms(in[rate/2:])
+		if ratio < test.minRatio || ratio > test.maxRatio {
+			t.Errorf("test %d: expected a gain between %f and %f but got %f",
+				i, test.minRatio, test.maxRatio, ratio)
+		}
+	}
+}
+
+func TestFilterSetType(t *testing.T) {
+	const rate = 48000
+	filter := NewLowPassFilter(1000)
+	in := sineWave(rate, 2, rate, 10000)
+	out := slices.Clone(in)
+	filter.Process(out, 2, rate)
+	if ratio := rms(out[rate/2:]) / rms(in[rate/2:]); ratio > 0.02 {
+		t.Fatalf("expected the low-pass filter to remove the tone but got a gain of %f", ratio)
+	}
+	// The cutoff and resonance are the same, so only the type changing can
+	// have the coefficients computed again
+	filter.SetType(FilterHighPass)
+	if filter.Type() != FilterHighPass {
+		t.Fatal("expected the filter to be a high-pass filter")
+	}
+	out = slices.Clone(in)
+	filter.Process(out, 2, rate)
+	if ratio := rms(out[rate/2:]) / rms(in[rate/2:]); ratio < 0.95 || ratio > 1.05 {
+		t.Fatalf("expected the high-pass filter to keep the tone but got a gain of %f", ratio)
+	}
+}
+
+func TestParamRamp(t *testing.T) {
+	p := newParam(0, 0, 10)
+	p.RampTo(10, 1)
+	if v := p.advance(24000, 48000); math.Abs(float64(v-5)) > 1e-3 {
+		t.Fatalf("expected the ramp to be half way but got %f", v)
+	}
+	if v := p.advance(48000, 48000); v != 10 {
+		t.Fatalf("expected the ramp to finish at 10 but got %f", v)
+	}
+	p.Jump(20)
+	if v := p.advance(1, 48000); v != 10 {
+		t.Fatalf("expected the value to be clamped to 10 but got %f", v)
+	}
+}
+
+func TestLimiter(t *testing.T) {
+	const rate = 48000
+	in := sineWave(rate, 2, rate, 440)
+	for i := range in {
+		in[i] *= 2
+	}
+	limiter := NewLimiter(-6)
+	limiter.Process(in, 2, rate)
+	limit := float64(DecibelsToGain(-6))
+	peak := 0.0
+	for _, s := range in[rate/10:] {
+		peak = max(peak, math.Abs(float64(s)))
+	}
+	if peak > limit*1.05 {
+		t.Fatalf("expected the peak to be limited to %f but got %f", limit, peak)
+	}
+}
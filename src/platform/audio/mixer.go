@@ -0,0 +1,363 @@
+/******************************************************************************/
+/* mixer.go                                                                   */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package audio
+
+import (
+	"kaiju/klib"
+	"log/slog"
+	"sync"
+)
+
+const defaultMaxVoices = 64
+
+// Mixer combines all of the playing voices through their buses into a single
+// interleaved float32 stream. The mixer is an io.Reader so that it can be
+// given directly to the output device, which will pull from it on its own
+// goroutine; all public functions are safe to call from any goroutine.
+type Mixer struct {
+	mutex      sync.Mutex
+	sampleRate int
+	channels   int
+	voices     []voice
+	buses      []*Bus
+	master     *Bus
+	frame      uint64
+	scratch    []float32
+	recorder   *recorder
+}
+
+// NewMixer creates a mixer with the default master, music, sfx and voice
+// buses, the output will be in the given sample rate and channel count
+func NewMixer(sampleRate, channels int) *Mixer {
+	m := &Mixer{
+		sampleRate: sampleRate,
+		channels:   channels,
+		voices:     make([]voice, defaultMaxVoices),
+	}
+	m.master = &Bus{mixer: m, name: BusMaster, volume: 1}
+	m.buses = append(m.buses, m.master)
+	m.CreateBus(BusMusic, m.master)
+	m.CreateBus(BusSfx, m.master)
+	m.CreateBus(BusVoice, m.master)
+	return m
+}
+
+func (m *Mixer) SampleRate() int { return m.sampleRate }
+func (m *Mixer) Channels() int   { return m.channels }
+
+// Master returns the bus that all other buses output into
+func (m *Mixer) Master() *Bus { return m.master }
+
+// Bus finds the bus with the given name, nil is returned if not found
+func (m *Mixer) Bus(name string) *Bus {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	return m.findBus(name)
+}
+
+func (m *Mixer) findBus(name string) *Bus {
+	for i := range m.buses {
+		if m.buses[i].name == name {
+			return m.buses[i]
+		}
+	}
+	return nil
+}
+
+// CreateBus creates a new bus that outputs into the given parent bus. If the
+// parent is nil then the master bus will be used. If a bus with the name
+// already exists, then that bus will be returned instead.
+func (m *Mixer) CreateBus(name string, parent *Bus) *Bus {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	if b := m.findBus(name); b != nil {
+		return b
+	}
+	if parent == nil {
+		parent = m.master
+	}
+	b := &Bus{mixer: m, name: name, parent: parent, volume: 1}
+	m.buses = append(m.buses, b)
+	return b
+}
+
+// SetMaxVoices changes the number of sounds that can be played at the same
+// time, this will stop any voices that are playing
+func (m *Mixer) SetMaxVoices(count int) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	for i := range m.voices {
+		m.voices[i].release()
+	}
+	// Voices past the length are kept in the backing array so that their
+	// generations carry on if the pool grows again, otherwise a stale handle
+	// could match a voice that is started after regrowing
+	count = max(1, count)
+	if count > cap(m.voices) {
+		m.voices = append(m.voices[:cap(m.voices)], make([]voice, count-cap(m.voices))...)
+	}
+	m.voices = m.voices[:count]
+}
+
+// ActiveVoices returns the number of voices that are currently playing
+func (m *Mixer) ActiveVoices() int {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	count := 0
+	for i := range m.voices {
+		if m.voices[i].active {
+			count++
+		}
+	}
+	return count
+}
+
+// StopAll immediately stops all voices that are playing through the mixer
+func (m *Mixer) StopAll() {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	for i := range m.voices {
+		m.voices[i].release()
+	}
+}
+
+// Play starts playing the clip and returns a handle to control the voice. If
+// all voices are in use, the voice with the lowest priority (the oldest if
+// there is a tie) will be stolen, as long as its priority is not higher than
+// the requested priority; otherwise an invalid handle is returned.
+func (m *Mixer) Play(clip *Clip, options PlayOptions) VoiceHandle {
+	if clip == nil || clip.Frames() == 0 {
+		slog.Error("tried to play an empty audio clip")
+		return VoiceHandle{}
+	}
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	return m.play(clip, nil, options)
+}
+
+// PlayStream starts playing the stream and returns a handle to control the
+// voice, voices are claimed the same way as Play. The stream will start
+// decoding in the background and is closed once the voice ends.
+func (m *Mixer) PlayStream(stream *Stream, options PlayOptions) VoiceHandle {
+	if stream == nil {
+		slog.Error("tried to play a nil audio stream")
+		return VoiceHandle{}
+	}
+	if stream.Channels() != m.channels || stream.SampleRate() != m.sampleRate {
+		slog.Error("the audio stream format doesn't match the mixer",
+			"channels", stream.Channels(), "sampleRate", stream.SampleRate())
+		return VoiceHandle{}
+	}
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	h := m.play(nil, stream, options)
+	if h.mixer == nil {
+		stream.Close()
+	} else if !stream.start(options.Loop) {
+		slog.Error("the audio stream has already been played or was closed")
+		m.voices[h.index].release()
+		return VoiceHandle{}
+	}
+	return h
+}
+
+// CrossFade fades out the from voice while fading in the stream over the
+// given number of seconds, the handle for the new voice is returned
+func (m *Mixer) CrossFade(from VoiceHandle, stream *Stream, options PlayOptions, seconds float32) VoiceHandle {
+	from.FadeOut(seconds)
+	options.FadeIn = seconds
+	return m.PlayStream(stream, options)
+}
+
+func (m *Mixer) play(clip *Clip, stream *Stream, options PlayOptions) VoiceHandle {
+	bus := m.findBus(options.Bus)
+	if bus == nil {
+		if options.Bus != "" {
+			slog.Warn("audio bus not found, using the sfx bus", "bus", options.Bus)
+		}
+		bus = m.findBus(BusSfx)
+	}
+	idx := m.claimVoice(options.Priority)
+	if idx < 0 {
+		return VoiceHandle{}
+	}
+	v := &m.voices[idx]
+	v.release()
+	clipVolume := float32(1)
+	if clip != nil {
+		clipVolume = clip.Volume
+	}
+	*v = voice{
+		clip:         clip,
+		stream:       stream,
+		streamBuffer: v.streamBuffer,
+		bus:          bus,
+		generation:   v.generation + 1,
+		active:       true,
+		paused:       options.Paused,
+		loop:         options.Loop,
+		priority:     options.Priority,
+		startFrame:   m.frame,
+		volume:       max(0, options.Volume),
+		clipVolume:   clipVolume,
+		pitch:        options.Pitch,
+		fadeGain:     1,
+		spatialGain:  1,
+		dopplerPitch: 1,
+		panGains:     [2]float32{1, 1},
+	}
+	for _, e := range options.Effects {
+		v.effects.add(e)
+	}
+	if v.pitch <= 0 {
+		v.pitch = 1
+	}
+	if options.FadeIn > 0 {
+		v.fadeGain = 0
+		v.fadeTo(1, m.secondsToFrames(options.FadeIn), false)
+	}
+	return VoiceHandle{mixer: m, index: idx, generation: v.generation}
+}
+
+func (m *Mixer) claimVoice(priority int) int {
+	steal := -1
+	for i := range m.voices {
+		v := &m.voices[i]
+		if !v.active {
+			return i
+		}
+		if steal < 0 || v.priority < m.voices[steal].priority ||
+			(v.priority == m.voices[steal].priority && v.startFrame < m.voices[steal].startFrame) {
+			steal = i
+		}
+	}
+	if steal >= 0 && m.voices[steal].priority <= priority {
+		return steal
+	}
+	return -1
+}
+
+func (m *Mixer) secondsToFrames(seconds float32) int {
+	return int(seconds * float32(m.sampleRate))
+}
+
+// Read will mix all of the active voices into the buffer as interleaved
+// little endian float32 samples. This will never return io.EOF, silence is
+// written when there is nothing playing.
+func (m *Mixer) Read(p []byte) (int, error) {
+	frameSize := m.channels * 4
+	frames := len(p) / frameSize
+	if frames == 0 {
+		return 0, nil
+	}
+	out := klib.ByteSliceToFloat32Slice(p[:frames*frameSize])
+	m.Mix(out)
+	return frames * frameSize, nil
+}
+
+// Mix will mix all of the active voices into the interleaved sample buffer,
+// the buffer length should be a multiple of the channel count
+func (m *Mixer) Mix(out []float32) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	samples := len(out) - len(out)%m.channels
+	out = out[:samples]
+	for i := range m.buses {
+		m.buses[i].prepare(samples)
+	}
+	for i := range m.voices {
+		v := &m.voices[i]
+		if v.active && !v.paused {
+			rateScale := 1.0
+			if v.clip != nil {
+				rateScale = float64(v.clip.SampleRate) / float64(m.sampleRate)
+			}
+			if v.effects.len() == 0 {
+				v.mix(v.bus.buffer, m.channels, rateScale)
+			} else {
+				m.mixVoiceEffects(v, samples, rateScale)
+			}
+		}
+		// Voices can also be stopped through their handle, so this releases
+		// the resources of any voice that has ended since the last mix
+		if !v.active && (v.clip != nil || v.stream != nil) {
+			v.release()
+		}
+	}
+	// Children are always created after their parents, so walking backwards
+	// will have fully mixed a bus before it is added to its parent
+	for i := len(m.buses) - 1; i > 0; i-- {
+		b := m.buses[i]
+		b.effects.process(b.buffer, m.channels, m.sampleRate)
+		g := b.gain()
+		dst := b.parent.buffer
+		for j := range b.buffer {
+			dst[j] += b.buffer[j] * g
+		}
+	}
+	m.master.effects.process(m.master.buffer, m.channels, m.sampleRate)
+	g := m.master.gain()
+	for i := range out {
+		out[i] = klib.Clamp(m.master.buffer[i]*g, -1, 1)
+	}
+	m.frame += uint64(samples / m.channels)
+	if m.recorder != nil {
+		m.recorder.push(out)
+	}
+}
+
+func (m *Mixer) setRecorder(recorder *recorder) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.recorder = recorder
+}
+
+// mixVoiceEffects mixes the voice on its own so that its effects can be
+// applied before it is added to its bus
+func (m *Mixer) mixVoiceEffects(v *voice, samples int, rateScale float64) {
+	if cap(m.scratch) < samples {
+		m.scratch = make([]float32, samples)
+	}
+	buffer := m.scratch[:samples]
+	clear(buffer)
+	v.mix(buffer, m.channels, rateScale)
+	v.effects.process(buffer, m.channels, m.sampleRate)
+	for i := range buffer {
+		v.bus.buffer[i] += buffer[i]
+	}
+}
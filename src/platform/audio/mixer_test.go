@@ -0,0 +1,210 @@
+/******************************************************************************/
+/* mixer_test.go                                                              */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package audio
+
+import (
+	"math"
+	"testing"
+)
+
+func mixFrames(m *Mixer, frames int) []float32 {
+	out := make([]float32, frames*m.Channels())
+	m.Mix(out)
+	return out
+}
+
+func expectSamples(t *testing.T, out []float32, want float32) {
+	t.Helper()
+	for i := range out {
+		if math.Abs(float64(out[i]-want)) > 1e-5 {
+			t.Fatalf("expected sample %d to be %f but got %f", i, want, out[i])
+		}
+	}
+}
+
+func TestMixerBusVolumes(t *testing.T) {
+	m := NewMixer(48000, 2)
+	m.Play(constantClip(1000, 0.5), DefaultPlayOptions())
+	expectSamples(t, mixFrames(m, 10), 0.5)
+	m.Bus(BusSfx).SetVolume(0.5)
+	expectSamples(t, mixFrames(m, 10), 0.25)
+	m.Master().SetVolume(0.5)
+	expectSamples(t, mixFrames(m, 10), 0.125)
+	m.Bus(BusMusic).SetMuted(true)
+	expectSamples(t, mixFrames(m, 10), 0.125)
+	m.Master().SetMuted(true)
+	expectSamples(t, mixFrames(m, 10), 0)
+	m.Master().SetMuted(false)
+	expectSamples(t, mixFrames(m, 10), 0.125)
+}
+
+func TestMixerChildBus(t *testing.T) {
+	m := NewMixer(48000, 2)
+	footsteps := m.CreateBus("footsteps", m.Bus(BusSfx))
+	if footsteps.Parent() != m.Bus(BusSfx) {
+		t.Fatal("expected the bus to output into its parent")
+	}
+	if m.CreateBus("footsteps", nil) != footsteps {
+		t.Fatal("expected creating an existing bus to return it")
+	}
+	opts := DefaultPlayOptions()
+	opts.Bus = "footsteps"
+	m.Play(constantClip(1000, 0.5), opts)
+	footsteps.SetVolume(0.5)
+	m.Bus(BusSfx).SetVolume(0.5)
+	expectSamples(t, mixFrames(m, 10), 0.125)
+	m.Bus(BusSfx).SetMuted(true)
+	expectSamples(t, mixFrames(m, 10), 0)
+}
+
+func TestMixerVoiceEnds(t *testing.T) {
+	m := NewMixer(48000, 2)
+	h := m.Play(constantClip(100, 0.5), DefaultPlayOptions())
+	out := mixFrames(m, 200)
+	expectSamples(t, out[:100*2], 0.5)
+	expectSamples(t, out[100*2:], 0)
+	if h.IsValid() || m.ActiveVoices() != 0 {
+		t.Error("expected the voice to end with its clip")
+	}
+}
+
+func TestMixerPausedVoice(t *testing.T) {
+	m := NewMixer(48000, 2)
+	opts := DefaultPlayOptions()
+	opts.Paused = true
+	h := m.Play(constantClip(1000, 0.5), opts)
+	expectSamples(t, mixFrames(m, 10), 0)
+	if !h.IsValid() || h.IsPlaying() {
+		t.Fatal("expected the voice to be valid but not playing")
+	}
+	h.Resume()
+	expectSamples(t, mixFrames(m, 10), 0.5)
+}
+
+func TestMixerVoiceStealing(t *testing.T) {
+	m := NewMixer(48000, 2)
+	m.SetMaxVoices(2)
+	opts := DefaultPlayOptions()
+	opts.Loop = true
+	opts.Priority = 1
+	first := m.Play(constantClip(1000, 0.1), opts)
+	mixFrames(m, 1)
+	second := m.Play(constantClip(1000, 0.1), opts)
+	mixFrames(m, 1)
+	low := opts
+	low.Priority = 0
+	if h := m.Play(constantClip(1000, 0.1), low); h.IsValid() {
+		t.Fatal("expected a lower priority sound to not steal a voice")
+	}
+	third := m.Play(constantClip(1000, 0.1), opts)
+	if !third.IsValid() || first.IsValid() || !second.IsValid() {
+		t.Fatal("expected the oldest voice of the same priority to be stolen")
+	}
+	high := opts
+	high.Priority = 2
+	mixFrames(m, 1)
+	m.Play(constantClip(1000, 0.1), high)
+	mixFrames(m, 1)
+	if second.IsValid() || !third.IsValid() {
+		t.Fatal("expected the oldest voice to be stolen by a higher priority")
+	}
+	if h := m.Play(constantClip(1000, 0.1), opts); !h.IsValid() || third.IsValid() {
+		t.Fatal("expected the lowest priority voice to be stolen before a higher one")
+	}
+	if m.ActiveVoices() != 2 {
+		t.Errorf("expected 2 active voices, got %d", m.ActiveVoices())
+	}
+}
+
+func TestVoiceHandleGenerationReuse(t *testing.T) {
+	m := NewMixer(48000, 2)
+	m.SetMaxVoices(1)
+	old := m.Play(constantClip(1000, 0.5), DefaultPlayOptions())
+	old.Stop()
+	mixFrames(m, 1)
+	h := m.Play(constantClip(1000, 0.25), DefaultPlayOptions())
+	if h.index != old.index || h.generation == old.generation {
+		t.Fatal("expected the voice to be reused with a new generation")
+	}
+	if old.IsValid() {
+		t.Fatal("expected the stale handle to be invalid")
+	}
+	old.Pause()
+	old.SetVolume(0)
+	old.Stop()
+	if !h.IsPlaying() {
+		t.Fatal("expected the stale handle to not control the reused voice")
+	}
+	expectSamples(t, mixFrames(m, 10), 0.25)
+}
+
+func TestMixerResizeStaleHandles(t *testing.T) {
+	m := NewMixer(48000, 2)
+	m.SetMaxVoices(4)
+	opts := DefaultPlayOptions()
+	opts.Loop = true
+	var handles []VoiceHandle
+	for range 4 {
+		handles = append(handles, m.Play(constantClip(1000, 0.1), opts))
+	}
+	m.SetMaxVoices(1)
+	for _, h := range handles {
+		if h.IsValid() {
+			t.Fatal("expected resizing the voices to stop every voice")
+		}
+		h.SetVolume(0)
+		h.Stop()
+	}
+	m.SetMaxVoices(4)
+	var fresh []VoiceHandle
+	for range 4 {
+		fresh = append(fresh, m.Play(constantClip(1000, 0.25), opts))
+	}
+	for _, h := range handles {
+		if h.IsValid() {
+			t.Fatalf("expected the stale handle for voice %d to stay invalid", h.index)
+		}
+		h.Stop()
+	}
+	for _, h := range fresh {
+		if !h.IsPlaying() {
+			t.Fatal("expected the stale handles to not stop the new voices")
+		}
+	}
+	expectSamples(t, mixFrames(m, 10), 1)
+}
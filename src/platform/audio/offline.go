@@ -0,0 +1,111 @@
+/******************************************************************************/
+/* offline.go                                                                 */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package audio
+
+// OfflineOutput replaces the audio device when audio is created with
+// NewOfflineAudio. Nothing is mixed until the clock is advanced manually
+// with Advance or Render, which makes the output fully deterministic. This
+// is used to test mixing, spatialization and effects on machines without a
+// sound card, and to render audio for captures (see Audio.StartRecording).
+type OfflineOutput struct {
+	mixer     *Mixer
+	buffer    []float32
+	remainder float64
+	elapsed   uint64
+	capture   bool
+	captured  []float32
+}
+
+// NewOfflineAudio creates audio that mixes into memory rather than playing
+// through an output device, use Offline to get the output to drive it
+func NewOfflineAudio(sampleRate, channels int) Audio {
+	a := newAudio(sampleRate, channels)
+	a.offline = &OfflineOutput{mixer: a.mixer}
+	return a
+}
+
+// Offline returns the offline output for audio created with NewOfflineAudio,
+// nil is returned for audio that is playing through an output device
+func (a *Audio) Offline() *OfflineOutput { return a.offline }
+
+// Render mixes the given number of frames and returns the interleaved
+// samples, the returned slice is only valid until the next call
+func (o *OfflineOutput) Render(frames int) []float32 {
+	samples := max(0, frames) * o.mixer.channels
+	if cap(o.buffer) < samples {
+		o.buffer = make([]float32, samples)
+	}
+	o.buffer = o.buffer[:samples]
+	o.mixer.Mix(o.buffer)
+	o.elapsed += uint64(max(0, frames))
+	if o.capture {
+		o.captured = append(o.captured, o.buffer...)
+	}
+	return o.buffer
+}
+
+// Advance moves the clock forward by the given number of seconds and mixes
+// the frames for that time. Partial frames are carried over to the next call
+// so that advancing by a variable delta time doesn't drift.
+func (o *OfflineOutput) Advance(seconds float64) []float32 {
+	exact := max(0, seconds)*float64(o.mixer.sampleRate) + o.remainder
+	frames := int(exact)
+	o.remainder = exact - float64(frames)
+	return o.Render(frames)
+}
+
+// Elapsed returns the number of seconds that have been rendered
+func (o *OfflineOutput) Elapsed() float64 {
+	return float64(o.elapsed) / float64(o.mixer.sampleRate)
+}
+
+// StartCapture will keep all of the samples rendered from now on in memory,
+// any previously captured samples are cleared
+func (o *OfflineOutput) StartCapture() {
+	o.capture = true
+	o.captured = o.captured[:0]
+}
+
+// StopCapture stops capturing and returns all of the captured samples
+func (o *OfflineOutput) StopCapture() []float32 {
+	o.capture = false
+	return o.captured
+}
+
+// Captured returns the samples that have been captured so far
+func (o *OfflineOutput) Captured() []float32 { return o.captured }
@@ -0,0 +1,227 @@
+/******************************************************************************/
+/* offline_test.go                                                            */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package audio
+
+import (
+	"bytes"
+	"io"
+	"kaiju/matrix"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func constantClip(frames int, value float32) *Clip {
+	clip := &Clip{
+		Samples:    make([]float32, frames*2),
+		Channels:   2,
+		SampleRate: 48000,
+		Volume:     1,
+	}
+	for i := range clip.Samples {
+		clip.Samples[i] = value
+	}
+	return clip
+}
+
+func TestOfflineAdvance(t *testing.T) {
+	a := NewOfflineAudio(48000, 2)
+	total := 0
+	for i := 0; i < 600; i++ {
+		total += len(a.Offline().Advance(1.0/60.0)) / 2
+	}
+	if total != 480000 && total != 479999 {
+		t.Fatalf("expected 10 seconds of frames but got %d", total)
+	}
+}
+
+func TestOfflineMixing(t *testing.T) {
+	a := NewOfflineAudio(48000, 2)
+	opts := DefaultPlayOptions()
+	opts.Volume = 0.5
+	h := a.PlayClip(constantClip(1000, 0.5), opts)
+	out := a.Offline().Render(600)
+	for i := range out {
+		if out[i] != 0.25 {
+			t.Fatalf("sample %d expected 0.25 but got %f", i, out[i])
+		}
+	}
+	out = a.Offline().Render(600)
+	for i := range out {
+		expected := float32(0.25)
+		if i >= 400*2 {
+			expected = 0
+		}
+		if out[i] != expected {
+			t.Fatalf("sample %d expected %f but got %f", i, expected, out[i])
+		}
+	}
+	if h.IsValid() {
+		t.Fatal("expected the voice to end with the clip")
+	}
+}
+
+func TestOfflineSpatialPan(t *testing.T) {
+	a := NewOfflineAudio(48000, 2)
+	opts := DefaultPlayOptions()
+	opts.Loop = true
+	h := a.PlayClip(constantClip(100, 1), opts)
+	settings := DefaultSpatialSettings()
+	settings.Attenuation = AttenuationNone
+	h.SetSpatial(settings.Compute(a.Listener(), SpatialEmitter{
+		Position: matrix.Vec3Right().Scale(10),
+	}))
+	// Render twice, the first buffer has the pan ramp
+	a.Offline().Render(256)
+	out := a.Offline().Render(256)
+	if out[0] >= out[1] || math.Abs(float64(out[0])) > 1e-4 {
+		t.Fatalf("expected the sound to be fully to the right but got %f, %f", out[0], out[1])
+	}
+}
+
+func TestOfflineRecording(t *testing.T) {
+	a := NewOfflineAudio(48000, 2)
+	path := filepath.Join(t.TempDir(), "capture.wav")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := a.StartRecording(f); err != nil {
+		t.Fatal(err)
+	}
+	a.PlayClip(constantClip(300, 0.75), DefaultPlayOptions())
+	a.Offline().Render(500)
+	if err := a.StopRecording(); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	src, err := newWavStreamSource(nopSeekCloser{bytes.NewReader(data)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var samples []float32
+	for {
+		block, err := src.DecodeBlock()
+		samples = append(samples, block...)
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(samples) != 500*2 {
+		t.Fatalf("expected 500 frames but got %d", len(samples)/2)
+	}
+	for i := range samples {
+		expected := float32(0.75)
+		if i >= 300*2 {
+			expected = 0
+		}
+		if samples[i] != expected {
+			t.Fatalf("sample %d expected %f but got %f", i, expected, samples[i])
+		}
+	}
+}
+
+func TestOfflineRecordingLongRender(t *testing.T) {
+	// More is rendered at once than the recorder buffers, offline audio
+	// waits for the file to be written rather than dropping samples
+	a := NewOfflineAudio(48000, 2)
+	f, err := os.Create(filepath.Join(t.TempDir(), "capture.wav"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if err := a.StartRecording(f); err != nil {
+		t.Fatal(err)
+	}
+	a.Offline().Render(48000 * (recorderBufferSeconds + 1))
+	if err := a.StopRecording(); err != nil {
+		t.Fatal(err)
+	}
+	info, err := f.Stat()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := int64(wavWriterHeaderSize + 48000*(recorderBufferSeconds+1)*2*4); info.Size() != expected {
+		t.Fatalf("expected the recording to be %d bytes but it is %d", expected, info.Size())
+	}
+}
+
+type gatedFile struct {
+	*os.File
+	gate chan struct{}
+}
+
+func (f gatedFile) Write(p []byte) (int, error) {
+	<-f.gate
+	return f.File.Write(p)
+}
+
+func TestRecorderDropsWhenBehind(t *testing.T) {
+	f, err := os.Create(filepath.Join(t.TempDir(), "capture.wav"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	out := gatedFile{File: f, gate: make(chan struct{})}
+	w, err := NewWavWriter(out, 48000, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The file is blocked so pushing more than the buffer holds must return
+	// rather than wait, as it would be holding up the output device
+	r := newRecorder(w, 48000, 2, false)
+	r.push(make([]float32, 48000*2*(recorderBufferSeconds+1)))
+	close(out.gate)
+	if err := r.close(); err == nil {
+		t.Fatal("expected an error for the dropped samples")
+	}
+}
+
+func TestRecordingWithoutMixer(t *testing.T) {
+	a := Audio{}
+	if err := a.StartRecording(nil); err == nil {
+		t.Fatal("expected an error when recording before the audio is initialized")
+	}
+}
@@ -0,0 +1,143 @@
+/******************************************************************************/
+/* recorder.go                                                                */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package audio
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+)
+
+const (
+	recorderBufferSeconds = 2
+	recorderWriteSamples  = 4096
+)
+
+// recorder writes the mix into a WAV file on its own goroutine so that the
+// file I/O is kept off of the goroutine that the output device mixes on. The
+// mixed samples are handed over through a ring buffer.
+type recorder struct {
+	mutex   sync.Mutex
+	cond    *sync.Cond
+	ring    ringBuffer
+	writer  *WavWriter
+	wait    bool
+	dropped int
+	closed  bool
+	err     error
+	done    chan struct{}
+}
+
+// newRecorder starts writing to the writer, when wait is true pushing waits
+// for the writer to catch up rather than dropping samples, which is only
+// safe when nothing is waiting on the mix (like offline audio)
+func newRecorder(writer *WavWriter, sampleRate, channels int, wait bool) *recorder {
+	r := &recorder{
+		ring:   newRingBuffer(recorderBufferSeconds * sampleRate * channels),
+		writer: writer,
+		wait:   wait,
+		done:   make(chan struct{}),
+	}
+	r.cond = sync.NewCond(&r.mutex)
+	go r.run()
+	return r
+}
+
+// push queues the mixed samples to be written, the samples that don't fit
+// while the writer has fallen behind are dropped
+func (r *recorder) push(samples []float32) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	for len(samples) > 0 && r.err == nil {
+		n := r.ring.push(samples)
+		samples = samples[n:]
+		r.cond.Broadcast()
+		if !r.wait || len(samples) == 0 {
+			break
+		}
+		r.cond.Wait()
+	}
+	if r.err == nil {
+		r.dropped += len(samples)
+	}
+}
+
+func (r *recorder) run() {
+	defer close(r.done)
+	buffer := make([]float32, recorderWriteSamples)
+	for {
+		r.mutex.Lock()
+		for r.ring.len() == 0 && !r.closed {
+			r.cond.Wait()
+		}
+		n := r.ring.pop(buffer)
+		failed := r.err != nil
+		r.cond.Broadcast()
+		r.mutex.Unlock()
+		if n == 0 {
+			return
+		}
+		if failed {
+			continue
+		}
+		if err := r.writer.Write(buffer[:n]); err != nil {
+			slog.Error("failed to write the audio recording, recording stopped", "error", err)
+			r.mutex.Lock()
+			r.err = err
+			r.ring.clear()
+			r.cond.Broadcast()
+			r.mutex.Unlock()
+		}
+	}
+}
+
+// close waits for the queued samples to be written and then finishes the
+// WAV file, the mixer must no longer be pushing to the recorder
+func (r *recorder) close() error {
+	r.mutex.Lock()
+	r.closed = true
+	r.cond.Broadcast()
+	r.mutex.Unlock()
+	<-r.done
+	err := r.err
+	if err == nil && r.dropped > 0 {
+		err = fmt.Errorf("the recording fell behind the mix and %d samples were dropped", r.dropped)
+	}
+	return errors.Join(err, r.writer.Close())
+}
@@ -0,0 +1,224 @@
+/******************************************************************************/
+/* resampler.go                                                               */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package audio
+
+import "math"
+
+const (
+	// resamplerPhases is the number of filter phases that are pre-computed,
+	// coefficients between two phases are linearly interpolated
+	resamplerPhases = 256
+	// resamplerZeroCrossings is the number of sinc zero crossings on each
+	// side of the filter center, more gives a sharper cutoff
+	resamplerZeroCrossings = 16
+	resamplerKaiserBeta    = 8.6
+	// resamplerRolloff moves the cutoff slightly below the Nyquist frequency
+	// so that the transition band doesn't alias
+	resamplerRolloff = 0.95
+)
+
+// Resampler converts interleaved float32 samples from one sample rate to
+// another using a polyphase windowed sinc (Kaiser) filter. Samples can be
+// fed to Process in any sized block, which allows it to be used on streamed
+// audio as well as fully loaded clips. Call Flush once there is no more
+// input to get the remaining output.
+type Resampler struct {
+	table     []float32
+	coefs     []float32
+	history   []float32
+	channels  int
+	taps      int
+	half      int
+	pos       int
+	frac      int
+	stepWhole int
+	stepFrac  int
+	num       int
+	den       int
+	inFrames  int64
+	outFrames int64
+}
+
+// NewResampler creates a resampler that converts interleaved audio with the
+// given channel count from the in sample rate to the out sample rate
+func NewResampler(inRate, outRate, channels int) *Resampler {
+	g := gcd(inRate, outRate)
+	num := inRate / g
+	den := outRate / g
+	cutoff := 1.0
+	if inRate != outRate {
+		cutoff = resamplerRolloff * min(1, float64(outRate)/float64(inRate))
+	}
+	half := int(math.Ceil(resamplerZeroCrossings / cutoff))
+	r := &Resampler{
+		channels:  max(1, channels),
+		taps:      half * 2,
+		half:      half,
+		stepWhole: num / den,
+		stepFrac:  num % den,
+		num:       num,
+		den:       den,
+	}
+	r.buildTable(cutoff)
+	r.Reset()
+	return r
+}
+
+func gcd(a, b int) int {
+	for b != 0 {
+		a, b = b, a%b
+	}
+	return max(1, a)
+}
+
+// besselI0 is the zeroth order modified Bessel function of the first kind,
+// used to compute the Kaiser window
+func besselI0(x float64) float64 {
+	sum, term := 1.0, 1.0
+	halfX := x * 0.5
+	for k := 1; k < 64; k++ {
+		term *= (halfX / float64(k)) * (halfX / float64(k))
+		sum += term
+		if term < sum*1e-12 {
+			break
+		}
+	}
+	return sum
+}
+
+func (r *Resampler) buildTable(cutoff float64) {
+	r.table = make([]float32, (resamplerPhases+1)*r.taps)
+	r.coefs = make([]float32, r.taps)
+	norm := besselI0(resamplerKaiserBeta)
+	for p := 0; p <= resamplerPhases; p++ {
+		frac := float64(p) / resamplerPhases
+		row := r.table[p*r.taps : (p+1)*r.taps]
+		sum := 0.0
+		coefs := make([]float64, r.taps)
+		for j := range coefs {
+			// Distance (in input frames) from the tap to the output position
+			d := frac + float64(r.half-1-j)
+			x := d / float64(r.half)
+			if x <= -1 || x >= 1 {
+				continue
+			}
+			s := cutoff
+			if d != 0 {
+				s = math.Sin(math.Pi*cutoff*d) / (math.Pi * d)
+			}
+			w := besselI0(resamplerKaiserBeta*math.Sqrt(1-x*x)) / norm
+			coefs[j] = s * w
+			sum += coefs[j]
+		}
+		// Normalize each phase so that a constant signal keeps its level
+		for j := range coefs {
+			row[j] = float32(coefs[j] / sum)
+		}
+	}
+}
+
+// Reset clears any buffered input so the resampler can be used for a new
+// (or rewound) stream of audio
+func (r *Resampler) Reset() {
+	r.history = r.history[:0]
+	for i := 0; i < (r.half-1)*r.channels; i++ {
+		r.history = append(r.history, 0)
+	}
+	r.pos = 0
+	r.frac = 0
+	r.inFrames = 0
+	r.outFrames = 0
+}
+
+// Process feeds the interleaved input samples to the resampler and appends
+// any output samples that can be computed to out, the output is returned
+func (r *Resampler) Process(in []float32, out []float32) []float32 {
+	r.history = append(r.history, in...)
+	r.inFrames += int64(len(in) / r.channels)
+	return r.drain(out, -1)
+}
+
+// Flush appends the remaining output samples to out once all of the input
+// has been given to Process, the total number of output frames will match
+// the length of the input at the new sample rate
+func (r *Resampler) Flush(out []float32) []float32 {
+	expected := (r.inFrames*int64(r.den) + int64(r.num) - 1) / int64(r.num)
+	for r.outFrames < expected {
+		for i := 0; i < r.taps*r.channels; i++ {
+			r.history = append(r.history, 0)
+		}
+		out = r.drain(out, expected)
+	}
+	return out
+}
+
+func (r *Resampler) drain(out []float32, limit int64) []float32 {
+	frames := len(r.history) / r.channels
+	for r.pos+r.taps <= frames && (limit < 0 || r.outFrames < limit) {
+		phase := float32(r.frac) * resamplerPhases / float32(r.den)
+		p := min(int(phase), resamplerPhases-1)
+		a := phase - float32(p)
+		c0 := r.table[p*r.taps : (p+1)*r.taps]
+		c1 := r.table[(p+1)*r.taps : (p+2)*r.taps]
+		for j := range r.coefs {
+			r.coefs[j] = c0[j] + (c1[j]-c0[j])*a
+		}
+		src := r.history[r.pos*r.channels : (r.pos+r.taps)*r.channels]
+		for c := 0; c < r.channels; c++ {
+			sum := float32(0)
+			for j, coef := range r.coefs {
+				sum += src[j*r.channels+c] * coef
+			}
+			out = append(out, sum)
+		}
+		r.outFrames++
+		r.pos += r.stepWhole
+		r.frac += r.stepFrac
+		if r.frac >= r.den {
+			r.frac -= r.den
+			r.pos++
+		}
+	}
+	// Drop the frames that will no longer be read by the filter
+	if drop := min(r.pos, frames); drop > 0 {
+		n := copy(r.history, r.history[drop*r.channels:])
+		r.history = r.history[:n]
+		r.pos -= drop
+	}
+	return out
+}
@@ -0,0 +1,99 @@
+/******************************************************************************/
+/* resampler_test.go                                                          */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package audio
+
+import (
+	"math"
+	"testing"
+)
+
+func sineWave(frames, channels, sampleRate int, freq float64) []float32 {
+	out := make([]float32, frames*channels)
+	for f := 0; f < frames; f++ {
+		s := float32(math.Sin(2 * math.Pi * freq * float64(f) / float64(sampleRate)))
+		for c := 0; c < channels; c++ {
+			out[f*channels+c] = s
+		}
+	}
+	return out
+}
+
+func TestResamplerSine(t *testing.T) {
+	rates := [][2]int{{44100, 48000}, {48000, 44100}, {22050, 48000}, {96000, 48000}}
+	for _, r := range rates {
+		const freq = 1000
+		inFrames := r[0] / 2
+		in := sineWave(inFrames, 2, r[0], freq)
+		res := NewResampler(r[0], r[1], 2)
+		out := res.Flush(res.Process(in, nil))
+		expectedFrames := int(math.Ceil(float64(inFrames) * float64(r[1]) / float64(r[0])))
+		if len(out) != expectedFrames*2 {
+			t.Fatalf("%d->%d: expected %d frames but got %d",
+				r[0], r[1], expectedFrames, len(out)/2)
+		}
+		want := sineWave(expectedFrames, 2, r[1], freq)
+		// Skip the edges where the filter is reading the zero padding
+		edge := 64
+		for i := edge * 2; i < len(out)-edge*2; i++ {
+			if math.Abs(float64(out[i]-want[i])) > 1e-3 {
+				t.Fatalf("%d->%d: sample %d expected %f but got %f",
+					r[0], r[1], i, want[i], out[i])
+			}
+		}
+	}
+}
+
+func TestResamplerStreaming(t *testing.T) {
+	in := sineWave(10000, 2, 44100, 440)
+	whole := NewResampler(44100, 48000, 2)
+	expected := whole.Flush(whole.Process(in, nil))
+	stream := NewResampler(44100, 48000, 2)
+	out := []float32{}
+	for i := 0; i < len(in); i += 734 {
+		out = stream.Process(in[i:min(i+734, len(in))], out)
+	}
+	out = stream.Flush(out)
+	if len(out) != len(expected) {
+		t.Fatalf("expected %d samples but got %d", len(expected), len(out))
+	}
+	for i := range out {
+		if out[i] != expected[i] {
+			t.Fatalf("sample %d expected %f but got %f", i, expected[i], out[i])
+		}
+	}
+}
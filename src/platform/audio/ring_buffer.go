@@ -0,0 +1,81 @@
+/******************************************************************************/
+/* ring_buffer.go                                                             */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package audio
+
+// ringBuffer is a fixed size FIFO of interleaved samples, it does no locking
+// of its own so the owner is responsible for synchronizing access to it
+type ringBuffer struct {
+	data  []float32
+	read  int
+	count int
+}
+
+func newRingBuffer(size int) ringBuffer {
+	return ringBuffer{data: make([]float32, max(1, size))}
+}
+
+func (r *ringBuffer) len() int  { return r.count }
+func (r *ringBuffer) free() int { return len(r.data) - r.count }
+
+func (r *ringBuffer) clear() {
+	r.read = 0
+	r.count = 0
+}
+
+// push writes as many of the samples as will fit and returns the count
+func (r *ringBuffer) push(samples []float32) int {
+	n := min(len(samples), r.free())
+	write := (r.read + r.count) % len(r.data)
+	first := min(n, len(r.data)-write)
+	copy(r.data[write:], samples[:first])
+	copy(r.data, samples[first:n])
+	r.count += n
+	return n
+}
+
+// pop reads as many samples as are available (up to the length of out) and
+// returns the count
+func (r *ringBuffer) pop(out []float32) int {
+	n := min(len(out), r.count)
+	first := min(n, len(r.data)-r.read)
+	copy(out, r.data[r.read:r.read+first])
+	copy(out[first:n], r.data)
+	r.read = (r.read + n) % len(r.data)
+	r.count -= n
+	return n
+}
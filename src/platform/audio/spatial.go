@@ -0,0 +1,199 @@
+/******************************************************************************/
+/* spatial.go                                                                 */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package audio
+
+import (
+	"kaiju/matrix"
+)
+
+// DefaultSpeedOfSound is the speed of sound in units (meters) per second
+const DefaultSpeedOfSound = 343.0
+
+type AttenuationModel int
+
+const (
+	// AttenuationLinear fades the volume linearly from the min distance to
+	// the max distance
+	AttenuationLinear AttenuationModel = iota
+	// AttenuationInverse uses a physically based inverse distance rolloff,
+	// the volume is halved every time the distance doubles (at a rolloff of 1)
+	AttenuationInverse
+	// AttenuationCustom uses the Curve function of the spatial settings
+	AttenuationCustom
+	// AttenuationNone keeps the volume constant regardless of distance
+	AttenuationNone
+)
+
+// Listener is the "ears" of the scene that all spatial sounds are heard from
+type Listener struct {
+	Position matrix.Vec3
+	Forward  matrix.Vec3
+	Up       matrix.Vec3
+	Velocity matrix.Vec3
+}
+
+// SpatialEmitter describes where a sound is being played from in the world
+type SpatialEmitter struct {
+	Position matrix.Vec3
+	// Forward is the direction that the emitter is facing, only used for
+	// cone attenuation
+	Forward  matrix.Vec3
+	Velocity matrix.Vec3
+}
+
+// SpatialResult is the output of SpatialSettings.Compute which can be given
+// to VoiceHandle.SetSpatial
+type SpatialResult struct {
+	Gain  float32
+	Pan   float32
+	Pitch float32
+}
+
+// SpatialSettings describes how a sound is attenuated, panned and pitched
+// based on its position relative to the Listener
+type SpatialSettings struct {
+	Attenuation AttenuationModel
+	MinDistance matrix.Float
+	MaxDistance matrix.Float
+	Rolloff     matrix.Float
+	// Curve is used with AttenuationCustom, it is given the distance between
+	// the min and max distance normalized to 0-1 and returns the gain
+	Curve func(normalizedDistance matrix.Float) matrix.Float
+	// DopplerFactor scales the doppler effect, 0 will disable it
+	DopplerFactor matrix.Float
+	SpeedOfSound  matrix.Float
+	// The cone angles are in degrees, the sound is full volume within the
+	// inner angle and fades to the ConeOuterGain at the outer angle. A cone
+	// outer angle of 0 (or 360) disables cone attenuation
+	ConeInnerAngle matrix.Float
+	ConeOuterAngle matrix.Float
+	ConeOuterGain  matrix.Float
+}
+
+func DefaultSpatialSettings() SpatialSettings {
+	return SpatialSettings{
+		Attenuation:   AttenuationInverse,
+		MinDistance:   1,
+		MaxDistance:   100,
+		Rolloff:       1,
+		DopplerFactor: 0,
+		SpeedOfSound:  DefaultSpeedOfSound,
+		ConeOuterGain: 1,
+	}
+}
+
+// Compute calculates the gain, stereo pan and doppler pitch for the emitter
+// as heard by the listener
+func (s SpatialSettings) Compute(listener Listener, emitter SpatialEmitter) SpatialResult {
+	res := SpatialResult{Gain: 1, Pitch: 1}
+	toEmitter := emitter.Position.Subtract(listener.Position)
+	distance := toEmitter.Length()
+	gain := s.distanceGain(distance)
+	res.Gain = float32(gain)
+	if distance <= matrix.FloatSmallestNonzero {
+		return res
+	}
+	dir := toEmitter.Shrink(distance)
+	right := matrix.Vec3Cross(listener.Forward, listener.Up)
+	if !right.IsZero() {
+		res.Pan = float32(matrix.Clamp(matrix.Vec3Dot(dir, right.Normal()), -1, 1))
+	}
+	res.Gain = float32(gain * s.coneGain(emitter, dir))
+	if s.DopplerFactor > 0 {
+		c := s.SpeedOfSound
+		if c <= 0 {
+			c = DefaultSpeedOfSound
+		}
+		// Positive speeds are the listener moving toward the emitter and the
+		// emitter moving away from the listener
+		vl := matrix.Vec3Dot(listener.Velocity, dir) * s.DopplerFactor
+		ve := matrix.Vec3Dot(emitter.Velocity, dir) * s.DopplerFactor
+		vl = min(vl, c*0.5)
+		ve = min(ve, c*0.5)
+		res.Pitch = float32(matrix.Clamp((c+vl)/(c+ve), 0.5, 2))
+	}
+	return res
+}
+
+func (s SpatialSettings) distanceGain(distance matrix.Float) matrix.Float {
+	minD := max(s.MinDistance, 0)
+	maxD := max(s.MaxDistance, minD)
+	d := matrix.Clamp(distance, minD, maxD)
+	switch s.Attenuation {
+	case AttenuationLinear:
+		if maxD <= minD {
+			return 1
+		}
+		return matrix.Clamp(1-s.Rolloff*(d-minD)/(maxD-minD), 0, 1)
+	case AttenuationInverse:
+		if minD <= 0 {
+			minD = matrix.FloatSmallestNonzero
+		}
+		return matrix.Clamp(minD/(minD+s.Rolloff*(d-minD)), 0, 1)
+	case AttenuationCustom:
+		if s.Curve == nil {
+			return 1
+		}
+		t := matrix.Float(0)
+		if maxD > minD {
+			t = (d - minD) / (maxD - minD)
+		}
+		return max(0, s.Curve(t))
+	default:
+		return 1
+	}
+}
+
+func (s SpatialSettings) coneGain(emitter SpatialEmitter, dir matrix.Vec3) matrix.Float {
+	if s.ConeOuterAngle <= 0 || s.ConeOuterAngle >= 360 || emitter.Forward.IsZero() {
+		return 1
+	}
+	// The angle between where the emitter faces and the listener
+	toListener := dir.Negative()
+	cos := matrix.Clamp(matrix.Vec3Dot(emitter.Forward.Normal(), toListener), -1, 1)
+	angle := matrix.Rad2Deg(matrix.Acos(cos)) * 2
+	inner := min(s.ConeInnerAngle, s.ConeOuterAngle)
+	if angle <= inner {
+		return 1
+	}
+	if angle >= s.ConeOuterAngle {
+		return s.ConeOuterGain
+	}
+	t := (angle - inner) / (s.ConeOuterAngle - inner)
+	return 1 + (s.ConeOuterGain-1)*t
+}
@@ -0,0 +1,324 @@
+/******************************************************************************/
+/* stream.go                                                                  */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package audio
+
+import (
+	"errors"
+	"io"
+	"kaiju/engine/assets"
+	"log/slog"
+	"sync"
+)
+
+const defaultStreamBufferSeconds = 1
+
+// StreamOptions describe the loop sections and buffering of a Stream
+type StreamOptions struct {
+	// LoopStart is the time (in seconds) that the stream jumps back to when
+	// looping, anything before it is an intro that is only played once
+	LoopStart float32
+	// LoopEnd is the time (in seconds) at which a looping stream jumps back
+	// to LoopStart, 0 will loop at the end of the audio
+	LoopEnd float32
+	// BufferSeconds is the amount of decoded audio kept ahead of playback,
+	// 0 will use the default of 1 second
+	BufferSeconds float32
+}
+
+// Stream is a long sound (typically music) that is decoded in the background
+// a chunk at a time into a ring buffer rather than being fully loaded into
+// memory. A stream is played through the mixer with PlayStream and is
+// controlled with the returned VoiceHandle, just like a Clip. A stream can
+// only be played once; once its voice ends the stream is closed and a new
+// stream should be opened to play the audio again.
+//
+// Voices playing a stream ignore the pitch (and doppler) settings.
+type Stream struct {
+	mutex      sync.Mutex
+	cond       *sync.Cond
+	ring       ringBuffer
+	source     streamSource
+	resampler  *Resampler
+	channels   int
+	sampleRate int
+	loopStart  int
+	loopEnd    int
+	loop       bool
+	seekTo     int
+	generation uint32
+	position   int64
+	endFrame   int64
+	started    bool
+	finished   bool
+	closed     bool
+	closeOnce  sync.Once
+	err        error
+}
+
+// NewStream opens the audio file (WAV, Ogg Vorbis, MP3, or FLAC) through the
+// asset database for streaming, the audio will be converted to the given
+// sample rate and channel count as it is decoded
+func NewStream(assetDatabase *assets.Database, file string, sampleRate, channels int, options StreamOptions) (*Stream, error) {
+	src, err := openStreamSource(assetDatabase, file)
+	if err != nil {
+		return nil, err
+	}
+	return newStream(src, sampleRate, channels, options)
+}
+
+func newStream(src streamSource, sampleRate, channels int, options StreamOptions) (*Stream, error) {
+	if src.Channels() <= 0 || src.SampleRate() <= 0 {
+		src.Close()
+		return nil, errors.New("the audio stream has an invalid format")
+	}
+	bufferSeconds := options.BufferSeconds
+	if bufferSeconds <= 0 {
+		bufferSeconds = defaultStreamBufferSeconds
+	}
+	s := &Stream{
+		ring:       newRingBuffer(int(bufferSeconds*float32(sampleRate)) * channels),
+		source:     src,
+		resampler:  NewResampler(src.SampleRate(), sampleRate, channels),
+		channels:   channels,
+		sampleRate: sampleRate,
+		loopStart:  max(0, int(options.LoopStart*float32(src.SampleRate()))),
+		loopEnd:    max(0, int(options.LoopEnd*float32(src.SampleRate()))),
+		seekTo:     -1,
+		endFrame:   -1,
+	}
+	if s.loopEnd <= s.loopStart {
+		s.loopEnd = 0
+	}
+	s.cond = sync.NewCond(&s.mutex)
+	return s, nil
+}
+
+func (s *Stream) Channels() int   { return s.channels }
+func (s *Stream) SampleRate() int { return s.sampleRate }
+
+// Err returns the error that stopped the stream from decoding, if any
+func (s *Stream) Err() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.err
+}
+
+// Close stops the background decoding and closes the file, this is done
+// automatically when the voice playing the stream ends
+func (s *Stream) Close() {
+	s.closeOnce.Do(func() {
+		s.mutex.Lock()
+		s.closed = true
+		started := s.started
+		s.cond.Broadcast()
+		s.mutex.Unlock()
+		if !started {
+			s.source.Close()
+		}
+	})
+}
+
+// start begins decoding on a background goroutine, it returns false if the
+// stream has already been started or has been closed
+func (s *Stream) start(loop bool) bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.started || s.closed {
+		return false
+	}
+	s.started = true
+	s.loop = loop
+	go s.run()
+	return true
+}
+
+func (s *Stream) setLoop(loop bool) {
+	s.mutex.Lock()
+	s.loop = loop
+	s.mutex.Unlock()
+}
+
+func (s *Stream) toOutputFrames(sourceFrames int) int64 {
+	return int64(sourceFrames) * int64(s.sampleRate) / int64(s.source.SampleRate())
+}
+
+// seek clears any buffered audio and has the decoder start at the time
+func (s *Stream) seek(seconds float32) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.seekTo = max(0, int(seconds*float32(s.source.SampleRate())))
+	s.position = s.toOutputFrames(s.seekTo)
+	s.generation++
+	s.finished = false
+	s.ring.clear()
+	s.cond.Broadcast()
+}
+
+// positionSeconds returns the playback position in the audio, taking any
+// loops that have happened into account
+func (s *Stream) positionSeconds() float32 {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	pos := s.position
+	end := s.endFrame
+	if s.loopEnd > 0 {
+		end = s.toOutputFrames(s.loopEnd)
+	}
+	if start := s.toOutputFrames(s.loopStart); end > start && pos >= end {
+		pos = start + (pos-start)%(end-start)
+	}
+	return float32(pos) / float32(s.sampleRate)
+}
+
+// read pops the decoded samples into out (from the audio thread), it never
+// waits on the decoder. The number of frames read is returned along with
+// true if the stream has played all of its audio.
+func (s *Stream) read(out []float32) (int, bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	n := s.ring.pop(out) / s.channels
+	s.position += int64(n)
+	if n > 0 {
+		s.cond.Broadcast()
+	}
+	return n, s.ring.len() == 0 && (s.finished || s.err != nil || s.closed)
+}
+
+// push waits for room in the ring buffer to write all of the samples, false
+// is returned if the stream was closed or seeked while waiting
+func (s *Stream) push(samples []float32, generation uint32) bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	for len(samples) > 0 {
+		for s.ring.free() == 0 && !s.closed && s.generation == generation {
+			s.cond.Wait()
+		}
+		if s.closed || s.generation != generation {
+			return false
+		}
+		n := s.ring.push(samples)
+		samples = samples[n:]
+	}
+	return true
+}
+
+func (s *Stream) fail(err error) {
+	slog.Error("failed to decode the audio stream", "error", err)
+	s.mutex.Lock()
+	s.err = err
+	s.mutex.Unlock()
+}
+
+func (s *Stream) run() {
+	defer s.source.Close()
+	srcChannels := s.source.Channels()
+	srcFrame := 0
+	loopFrames := 0
+	var converted, block []float32
+	for {
+		s.mutex.Lock()
+		for s.finished && s.seekTo < 0 && !s.closed {
+			s.cond.Wait()
+		}
+		if s.closed {
+			s.mutex.Unlock()
+			return
+		}
+		generation := s.generation
+		seekTo := s.seekTo
+		s.seekTo = -1
+		loop := s.loop
+		s.mutex.Unlock()
+		if seekTo >= 0 {
+			if err := s.source.Seek(seekTo); err != nil {
+				s.fail(err)
+				return
+			}
+			srcFrame = seekTo
+			s.resampler.Reset()
+		}
+		samples, err := s.source.DecodeBlock()
+		if err != nil && !errors.Is(err, io.EOF) {
+			s.fail(err)
+			return
+		}
+		end := err != nil
+		frames := len(samples) / srcChannels
+		if s.loopEnd > 0 && srcFrame+frames >= s.loopEnd {
+			frames = max(0, s.loopEnd-srcFrame)
+			samples = samples[:frames*srcChannels]
+			end = true
+		}
+		srcFrame += frames
+		loopFrames += frames
+		converted = rechannelSamples(samples, srcChannels, s.channels, converted[:0])
+		block = s.resampler.Process(converted, block[:0])
+		if end {
+			// Nothing was decoded since the loop started, so there is nothing
+			// to loop and looping would spin forever
+			loop = loop && loopFrames > 0
+			loopFrames = 0
+			if s.loopEnd == 0 {
+				s.mutex.Lock()
+				s.endFrame = s.toOutputFrames(srcFrame)
+				s.mutex.Unlock()
+			}
+			if loop {
+				// The resampler is not reset so the seam between the end and
+				// the start of the loop is filtered like any other samples
+				if err := s.source.Seek(s.loopStart); err != nil {
+					s.fail(err)
+					return
+				}
+				srcFrame = s.loopStart
+			} else {
+				block = s.resampler.Flush(block)
+			}
+		}
+		if !s.push(block, generation) {
+			continue
+		}
+		if end && !loop {
+			s.mutex.Lock()
+			if s.generation == generation {
+				s.finished = true
+			}
+			s.mutex.Unlock()
+		}
+	}
+}
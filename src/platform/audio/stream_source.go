@@ -0,0 +1,239 @@
+/******************************************************************************/
+/* stream_source.go                                                           */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package audio
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+	"kaiju/engine/assets"
+	"kaiju/platform/audio/audio_codec"
+	"kaiju/platform/audio/audio_system"
+	"math"
+)
+
+const wavStreamBlockFrames = 4096
+
+var ErrUnsupportedWav = errors.New("unsupported or invalid wav file")
+
+// streamSource produces blocks of interleaved float32 samples in the source
+// sample rate and channel count for a Stream
+type streamSource interface {
+	Channels() int
+	SampleRate() int
+	// DecodeBlock returns the next block of samples, io.EOF is returned once
+	// the end of the audio has been reached
+	DecodeBlock() ([]float32, error)
+	// Seek moves the source so that the next block starts at the frame
+	Seek(frame int) error
+	Close() error
+}
+
+func openStreamSource(assetDatabase *assets.Database, file string) (streamSource, error) {
+	f, err := assetDatabase.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	var src streamSource
+	if audio_codec.IsSupported(file) {
+		// The decoder reads the file as it decodes, only a small window of
+		// the compressed file is held in memory at a time
+		var dec audio_codec.Decoder
+		if dec, err = audio_codec.NewStreamDecoder(file, f); err == nil {
+			src = &codecStreamSource{decoder: dec, file: f}
+		}
+	} else {
+		src, err = newWavStreamSource(f)
+	}
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	return src, nil
+}
+
+type codecStreamSource struct {
+	decoder audio_codec.Decoder
+	file    io.Closer
+}
+
+func (s *codecStreamSource) Channels() int   { return s.decoder.Channels() }
+func (s *codecStreamSource) SampleRate() int { return s.decoder.SampleRate() }
+func (s *codecStreamSource) Close() error    { return s.file.Close() }
+
+func (s *codecStreamSource) DecodeBlock() ([]float32, error) {
+	return s.decoder.DecodeBlock()
+}
+
+func (s *codecStreamSource) Seek(frame int) error {
+	return s.decoder.Seek(frame)
+}
+
+type wavStreamSource struct {
+	file          io.ReadSeekCloser
+	buffer        []byte
+	block         []float32
+	format        uint16
+	channels      int
+	sampleRate    int
+	bytesPerFrame int
+	dataOffset    int64
+	dataFrames    int
+	frame         int
+}
+
+func newWavStreamSource(file io.ReadSeekCloser) (*wavStreamSource, error) {
+	var riff [12]byte
+	if _, err := io.ReadFull(file, riff[:]); err != nil {
+		return nil, err
+	}
+	if string(riff[:4]) != "RIFF" || string(riff[8:]) != "WAVE" {
+		return nil, ErrUnsupportedWav
+	}
+	s := &wavStreamSource{file: file}
+	bits := 0
+	for {
+		var header [8]byte
+		if _, err := io.ReadFull(file, header[:]); err != nil {
+			return nil, ErrUnsupportedWav
+		}
+		size := int64(binary.LittleEndian.Uint32(header[4:]))
+		switch string(header[:4]) {
+		case "fmt ":
+			if size < 16 {
+				return nil, ErrUnsupportedWav
+			}
+			fmtData := make([]byte, size+size&1)
+			if _, err := io.ReadFull(file, fmtData); err != nil {
+				return nil, err
+			}
+			s.format = binary.LittleEndian.Uint16(fmtData)
+			s.channels = int(binary.LittleEndian.Uint16(fmtData[2:]))
+			s.sampleRate = int(binary.LittleEndian.Uint32(fmtData[4:]))
+			bits = int(binary.LittleEndian.Uint16(fmtData[14:]))
+			// WAVE_FORMAT_EXTENSIBLE stores the real format in the sub-format
+			if s.format == 0xFFFE && size >= 26 {
+				s.format = binary.LittleEndian.Uint16(fmtData[24:])
+			}
+		case "data":
+			if s.channels == 0 || bits == 0 {
+				return nil, ErrUnsupportedWav
+			}
+			offset, err := file.Seek(0, io.SeekCurrent)
+			if err != nil {
+				return nil, err
+			}
+			s.bytesPerFrame = s.channels * bits / 8
+			s.dataOffset = offset
+			s.dataFrames = int(size) / s.bytesPerFrame
+			if !s.supported(bits) {
+				return nil, ErrUnsupportedWav
+			}
+			return s, nil
+		default:
+			if _, err := file.Seek(size+size&1, io.SeekCurrent); err != nil {
+				return nil, err
+			}
+		}
+	}
+}
+
+func (s *wavStreamSource) supported(bits int) bool {
+	switch s.format {
+	case uint16(audio_system.WavFormatPcm):
+		return bits == 8 || bits == 16 || bits == 24 || bits == 32
+	case uint16(audio_system.WavFormatFloat):
+		return bits == 32
+	}
+	return false
+}
+
+func (s *wavStreamSource) Channels() int   { return s.channels }
+func (s *wavStreamSource) SampleRate() int { return s.sampleRate }
+func (s *wavStreamSource) Close() error    { return s.file.Close() }
+
+func (s *wavStreamSource) Seek(frame int) error {
+	s.frame = min(max(0, frame), s.dataFrames)
+	_, err := s.file.Seek(s.dataOffset+int64(s.frame*s.bytesPerFrame), io.SeekStart)
+	return err
+}
+
+func (s *wavStreamSource) DecodeBlock() ([]float32, error) {
+	frames := min(wavStreamBlockFrames, s.dataFrames-s.frame)
+	if frames <= 0 {
+		return nil, io.EOF
+	}
+	s.buffer = growBuffer(s.buffer, frames*s.bytesPerFrame)
+	n, err := io.ReadFull(s.file, s.buffer)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return nil, err
+	}
+	frames = n / s.bytesPerFrame
+	s.frame += frames
+	if frames == 0 {
+		// The data chunk claimed to be bigger than the file
+		s.frame = s.dataFrames
+		return nil, io.EOF
+	}
+	s.block = growBuffer(s.block, frames*s.channels)
+	sampleSize := s.bytesPerFrame / s.channels
+	for i := range s.block {
+		b := s.buffer[i*sampleSize:]
+		switch {
+		case s.format == uint16(audio_system.WavFormatFloat):
+			s.block[i] = math.Float32frombits(binary.LittleEndian.Uint32(b))
+		case sampleSize == 1:
+			s.block[i] = (float32(b[0]) - 128) / 128
+		case sampleSize == 2:
+			s.block[i] = float32(int16(binary.LittleEndian.Uint16(b))) / math.MaxInt16
+		case sampleSize == 3:
+			v := int32(uint32(b[0])<<8|uint32(b[1])<<16|uint32(b[2])<<24) >> 8
+			s.block[i] = float32(v) / (1 << 23)
+		default:
+			s.block[i] = float32(int32(binary.LittleEndian.Uint32(b))) / math.MaxInt32
+		}
+	}
+	return s.block, nil
+}
+
+func growBuffer[T any](s []T, length int) []T {
+	if cap(s) < length {
+		return make([]T, length)
+	}
+	return s[:length]
+}
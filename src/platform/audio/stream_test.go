@@ -0,0 +1,183 @@
+/******************************************************************************/
+/* stream_test.go                                                             */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package audio
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"math"
+	"testing"
+	"time"
+)
+
+type testStreamSource struct {
+	frames []float32
+	pos    int
+}
+
+func (s *testStreamSource) Channels() int   { return 1 }
+func (s *testStreamSource) SampleRate() int { return 48000 }
+func (s *testStreamSource) Close() error    { return nil }
+
+func (s *testStreamSource) Seek(frame int) error {
+	s.pos = frame
+	return nil
+}
+
+func (s *testStreamSource) DecodeBlock() ([]float32, error) {
+	if s.pos >= len(s.frames) {
+		return nil, io.EOF
+	}
+	end := min(s.pos+37, len(s.frames))
+	block := s.frames[s.pos:end]
+	s.pos = end
+	return block, nil
+}
+
+type nopSeekCloser struct{ *bytes.Reader }
+
+func (nopSeekCloser) Close() error { return nil }
+
+func readStream(t *testing.T, s *Stream, frames int) []float32 {
+	out := make([]float32, 0, frames*s.channels)
+	buf := make([]float32, 64*s.channels)
+	deadline := time.Now().Add(5 * time.Second)
+	for len(out) < frames*s.channels {
+		n, done := s.read(buf[:min(len(buf), frames*s.channels-len(out))])
+		out = append(out, buf[:n*s.channels]...)
+		if done {
+			break
+		}
+		if n > 0 {
+			deadline = time.Now().Add(5 * time.Second)
+		} else if time.Now().After(deadline) {
+			t.Fatal("timed out waiting on the stream")
+		} else {
+			time.Sleep(time.Millisecond)
+		}
+	}
+	return out
+}
+
+func TestStreamIntroLoop(t *testing.T) {
+	src := &testStreamSource{frames: make([]float32, 300)}
+	for i := range src.frames {
+		src.frames[i] = float32(i) / 1000
+	}
+	// Loop from frame 100 to frame 250, the last 50 frames are never played
+	s, err := newStream(src, 48000, 2, StreamOptions{
+		LoopStart:     100.0 / 48000,
+		LoopEnd:       250.0 / 48000,
+		BufferSeconds: 64.0 / 48000,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	s.start(true)
+	out := readStream(t, s, 1000)
+	expected := 0
+	for f := 0; f < 1000; f++ {
+		for c := 0; c < 2; c++ {
+			if math.Abs(float64(out[f*2+c]-src.frames[expected])) > 1e-5 {
+				t.Fatalf("frame %d expected %f but got %f", f, src.frames[expected], out[f*2+c])
+			}
+		}
+		if expected++; expected == 250 {
+			expected = 100
+		}
+	}
+}
+
+func TestStreamEnd(t *testing.T) {
+	src := &testStreamSource{frames: make([]float32, 500)}
+	s, err := newStream(src, 48000, 2, StreamOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	s.start(false)
+	out := readStream(t, s, 1000)
+	if len(out) != 500*2 {
+		t.Fatalf("expected 500 frames but got %d", len(out)/2)
+	}
+}
+
+func TestWavStreamSource(t *testing.T) {
+	samples := []int16{0, 16384, -16384, math.MaxInt16, 100, -100}
+	var data bytes.Buffer
+	data.WriteString("RIFF")
+	binary.Write(&data, binary.LittleEndian, uint32(36+len(samples)*2))
+	data.WriteString("WAVEfmt ")
+	for _, v := range []any{uint32(16), uint16(1), uint16(2), uint32(44100),
+		uint32(44100 * 4), uint16(4), uint16(16)} {
+		binary.Write(&data, binary.LittleEndian, v)
+	}
+	data.WriteString("data")
+	binary.Write(&data, binary.LittleEndian, uint32(len(samples)*2))
+	binary.Write(&data, binary.LittleEndian, samples)
+	src, err := newWavStreamSource(nopSeekCloser{bytes.NewReader(data.Bytes())})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if src.Channels() != 2 || src.SampleRate() != 44100 {
+		t.Fatalf("expected 2 channels at 44100 but got %d at %d",
+			src.Channels(), src.SampleRate())
+	}
+	for pass := 0; pass < 2; pass++ {
+		block, err := src.DecodeBlock()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(block) != len(samples) {
+			t.Fatalf("expected %d samples but got %d", len(samples), len(block))
+		}
+		for i := range samples {
+			if block[i] != float32(samples[i])/math.MaxInt16 {
+				t.Fatalf("sample %d expected %d but got %f", i, samples[i], block[i])
+			}
+		}
+		if _, err := src.DecodeBlock(); err != io.EOF {
+			t.Fatalf("expected io.EOF but got %v", err)
+		}
+		if err := src.Seek(0); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,380 @@
+/******************************************************************************/
+/* voice.go                                                                   */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package audio
+
+import "math"
+
+// PlayOptions describe how a clip should be played by the mixer, use
+// DefaultPlayOptions to get a set of options with sensible defaults
+type PlayOptions struct {
+	// Bus is the name of the bus to play through, empty will use BusSfx
+	Bus    string
+	Volume float32
+	// Pitch is the playback rate, 1 is normal speed, 2 is an octave higher
+	Pitch float32
+	Loop  bool
+	// Priority is used when the mixer is out of voices, a voice with a lower
+	// (or equal) priority will be stolen to play this sound
+	Priority int
+	// FadeIn is the time (in seconds) to fade from silence to the volume
+	FadeIn float32
+	// Paused will start the voice in a paused state, call Resume to play
+	Paused bool
+	// Effects are applied to the voice before it is mixed into its bus
+	Effects []Effect
+}
+
+func DefaultPlayOptions() PlayOptions {
+	return PlayOptions{
+		Bus:    BusSfx,
+		Volume: 1,
+		Pitch:  1,
+	}
+}
+
+type voice struct {
+	clip         *Clip
+	stream       *Stream
+	streamBuffer []float32
+	bus          *Bus
+	generation   uint32
+	active       bool
+	paused       bool
+	loop         bool
+	priority     int
+	startFrame   uint64
+	position     float64
+	volume       float32
+	clipVolume   float32
+	pitch        float32
+	fadeGain     float32
+	fadeStep     float32
+	fadeFrames   int
+	fadeStop     bool
+	spatial      bool
+	pan          float32
+	spatialGain  float32
+	dopplerPitch float32
+	panGains     [2]float32
+	effects      EffectChain
+}
+
+// targetPanGains computes the gains for the left and right channel using
+// constant power panning when the voice is spatialized
+func (v *voice) targetPanGains(channels int) [2]float32 {
+	if !v.spatial {
+		return [2]float32{1, 1}
+	}
+	if channels < 2 {
+		return [2]float32{v.spatialGain, v.spatialGain}
+	}
+	theta := float64(v.pan+1) * math.Pi * 0.25
+	return [2]float32{
+		float32(math.Cos(theta)) * v.spatialGain,
+		float32(math.Sin(theta)) * v.spatialGain,
+	}
+}
+
+func (v *voice) fadeTo(gain float32, frames int, stop bool) {
+	if frames <= 0 {
+		v.fadeGain = gain
+		v.fadeFrames = 0
+		if stop {
+			v.active = false
+		}
+		return
+	}
+	v.fadeStep = (gain - v.fadeGain) / float32(frames)
+	v.fadeFrames = frames
+	v.fadeStop = stop
+}
+
+// panRamp returns the amount to change the pan gains each frame so that pan
+// changes are ramped across the buffer to prevent clicking
+func (v *voice) panRamp(frames, channels int) [2]float32 {
+	target := v.targetPanGains(channels)
+	return [2]float32{
+		(target[0] - v.panGains[0]) / float32(max(1, frames)),
+		(target[1] - v.panGains[1]) / float32(max(1, frames)),
+	}
+}
+
+func (v *voice) stepFade() {
+	if v.fadeFrames > 0 {
+		v.fadeGain += v.fadeStep
+		v.fadeFrames--
+		if v.fadeFrames == 0 && v.fadeStop {
+			v.active = false
+		}
+	}
+}
+
+// release stops the voice and closes the stream that it was playing
+func (v *voice) release() {
+	v.active = false
+	if v.stream != nil {
+		v.stream.Close()
+		v.stream = nil
+	}
+	v.clip = nil
+	v.effects = EffectChain{}
+}
+
+func (v *voice) mix(out []float32, channels int, rateScale float64) {
+	if v.stream != nil {
+		v.mixStream(out, channels)
+		return
+	}
+	frames := len(out) / channels
+	clipFrames := v.clip.Frames()
+	loopStart, loopEnd := v.clip.loopRegion()
+	step := float64(v.pitch*v.dopplerPitch) * rateScale
+	panStep := v.panRamp(frames, channels)
+	for f := 0; f < frames && v.active; f++ {
+		idx := int(v.position)
+		frac := float32(v.position - float64(idx))
+		next := idx + 1
+		if v.loop && next >= loopEnd {
+			next = loopStart
+		} else if next >= clipFrames {
+			next = idx
+		}
+		gain := v.volume * v.clipVolume * v.fadeGain
+		v.panGains[0] += panStep[0]
+		v.panGains[1] += panStep[1]
+		for c := 0; c < channels; c++ {
+			a := v.clip.sample(idx, c)
+			b := v.clip.sample(next, c)
+			out[f*channels+c] += (a + (b-a)*frac) * gain * v.panGains[min(c, 1)]
+		}
+		v.stepFade()
+		v.position += step
+		if v.loop && loopEnd > loopStart && v.position >= float64(loopEnd) {
+			for v.position >= float64(loopEnd) {
+				v.position -= float64(loopEnd - loopStart)
+			}
+		} else if v.position >= float64(clipFrames) {
+			v.active = false
+		}
+	}
+}
+
+// mixStream mixes the decoded samples that are ready in the stream, if the
+// decoder has fallen behind then the rest of the buffer is left silent
+func (v *voice) mixStream(out []float32, channels int) {
+	frames := len(out) / channels
+	if cap(v.streamBuffer) < len(out) {
+		v.streamBuffer = make([]float32, len(out))
+	}
+	buffer := v.streamBuffer[:len(out)]
+	read, done := v.stream.read(buffer)
+	panStep := v.panRamp(read, channels)
+	for f := 0; f < read && v.active; f++ {
+		gain := v.volume * v.clipVolume * v.fadeGain
+		v.panGains[0] += panStep[0]
+		v.panGains[1] += panStep[1]
+		for c := 0; c < channels; c++ {
+			out[f*channels+c] += buffer[f*channels+c] * gain * v.panGains[min(c, 1)]
+		}
+		v.stepFade()
+	}
+	if done && read < frames {
+		v.active = false
+	}
+}
+
+// VoiceHandle is returned when playing a sound and is used to control the
+// voice while it plays. A handle will safely do nothing once the voice has
+// finished or has been stolen to play another sound.
+type VoiceHandle struct {
+	mixer      *Mixer
+	index      int
+	generation uint32
+}
+
+func (h VoiceHandle) with(fn func(v *voice)) bool {
+	if h.mixer == nil {
+		return false
+	}
+	h.mixer.mutex.Lock()
+	defer h.mixer.mutex.Unlock()
+	// The voice may no longer exist if the mixer was given fewer voices
+	if h.index >= len(h.mixer.voices) {
+		return false
+	}
+	v := &h.mixer.voices[h.index]
+	if v.generation != h.generation || !v.active {
+		return false
+	}
+	fn(v)
+	return true
+}
+
+// IsValid returns true if the voice this handle points to is still active
+func (h VoiceHandle) IsValid() bool {
+	return h.with(func(*voice) {})
+}
+
+// IsPlaying returns true if the voice is active and not paused
+func (h VoiceHandle) IsPlaying() bool {
+	playing := false
+	h.with(func(v *voice) { playing = !v.paused })
+	return playing
+}
+
+func (h VoiceHandle) Stop() {
+	h.with(func(v *voice) { v.active = false })
+}
+
+func (h VoiceHandle) Pause() {
+	h.with(func(v *voice) { v.paused = true })
+}
+
+func (h VoiceHandle) Resume() {
+	h.with(func(v *voice) { v.paused = false })
+}
+
+func (h VoiceHandle) Volume() float32 {
+	volume := float32(0)
+	h.with(func(v *voice) { volume = v.volume })
+	return volume
+}
+
+// SetVolume sets the linear volume (0 to 1) of the voice, this is multiplied
+// with any active fade and the volume of the bus
+func (h VoiceHandle) SetVolume(volume float32) {
+	h.with(func(v *voice) { v.volume = max(0, volume) })
+}
+
+func (h VoiceHandle) Pitch() float32 {
+	pitch := float32(0)
+	h.with(func(v *voice) { pitch = v.pitch })
+	return pitch
+}
+
+// SetPitch sets the playback rate of the voice, values <= 0 are ignored
+func (h VoiceHandle) SetPitch(pitch float32) {
+	if pitch <= 0 {
+		return
+	}
+	h.with(func(v *voice) { v.pitch = pitch })
+}
+
+func (h VoiceHandle) SetLoop(loop bool) {
+	h.with(func(v *voice) {
+		v.loop = loop
+		if v.stream != nil {
+			v.stream.setLoop(loop)
+		}
+	})
+}
+
+// Position returns the current playback position in seconds
+func (h VoiceHandle) Position() float32 {
+	pos := float32(0)
+	h.with(func(v *voice) {
+		if v.stream != nil {
+			pos = v.stream.positionSeconds()
+		} else {
+			pos = float32(v.position / float64(v.clip.SampleRate))
+		}
+	})
+	return pos
+}
+
+// Seek moves the playback position to the given time in seconds
+func (h VoiceHandle) Seek(seconds float32) {
+	h.with(func(v *voice) {
+		if v.stream != nil {
+			v.stream.seek(seconds)
+			return
+		}
+		frames := float64(v.clip.Frames())
+		v.position = min(max(0, float64(seconds)*float64(v.clip.SampleRate)), frames)
+	})
+}
+
+// SetSpatial applies the result of a spatial calculation (distance/cone
+// attenuation, panning and doppler) to the voice. See SpatialSettings.Compute
+func (h VoiceHandle) SetSpatial(result SpatialResult) {
+	h.with(func(v *voice) {
+		v.spatialGain = max(0, result.Gain)
+		v.pan = min(max(result.Pan, -1), 1)
+		v.dopplerPitch = result.Pitch
+		if v.dopplerPitch <= 0 {
+			v.dopplerPitch = 1
+		}
+		if !v.spatial {
+			v.spatial = true
+			v.panGains = v.targetPanGains(h.mixer.channels)
+		}
+	})
+}
+
+// ClearSpatial removes any spatialization from the voice
+func (h VoiceHandle) ClearSpatial() {
+	h.with(func(v *voice) {
+		v.spatial = false
+		v.dopplerPitch = 1
+	})
+}
+
+// AddEffect appends the effect to the end of the voice effect chain
+func (h VoiceHandle) AddEffect(effect Effect) {
+	h.with(func(v *voice) { v.effects.add(effect) })
+}
+
+// RemoveEffect removes the effect from the voice effect chain
+func (h VoiceHandle) RemoveEffect(effect Effect) {
+	h.with(func(v *voice) { v.effects.remove(effect) })
+}
+
+// FadeIn will fade the voice from silence to its volume over the duration
+func (h VoiceHandle) FadeIn(seconds float32) {
+	h.with(func(v *voice) {
+		v.fadeGain = 0
+		v.fadeTo(1, h.mixer.secondsToFrames(seconds), false)
+	})
+}
+
+// FadeOut will fade the voice to silence over the duration and then stop it
+func (h VoiceHandle) FadeOut(seconds float32) {
+	h.with(func(v *voice) {
+		v.fadeTo(0, h.mixer.secondsToFrames(seconds), true)
+	})
+}
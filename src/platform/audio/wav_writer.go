@@ -0,0 +1,142 @@
+/******************************************************************************/
+/* wav_writer.go                                                              */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package audio
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"io"
+	"kaiju/platform/audio/audio_system"
+	"math"
+)
+
+const wavWriterHeaderSize = 44
+
+// WavWriter writes interleaved float32 samples into a 32-bit float WAV file.
+// The sizes in the header are filled in when the writer is closed, so the
+// output must be seekable.
+type WavWriter struct {
+	out        io.WriteSeeker
+	buffer     *bufio.Writer
+	sampleRate int
+	channels   int
+	samples    int64
+	closed     bool
+}
+
+// NewWavWriter writes the WAV header to out and returns a writer for the
+// samples, Close must be called to finish the file
+func NewWavWriter(out io.WriteSeeker, sampleRate, channels int) (*WavWriter, error) {
+	w := &WavWriter{
+		out:        out,
+		buffer:     bufio.NewWriter(out),
+		sampleRate: sampleRate,
+		channels:   channels,
+	}
+	if err := w.writeHeader(); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+func (w *WavWriter) writeHeader() error {
+	dataSize := uint32(min(w.samples*4, math.MaxUint32-wavWriterHeaderSize))
+	var header [wavWriterHeaderSize]byte
+	copy(header[0:], "RIFF")
+	binary.LittleEndian.PutUint32(header[4:], dataSize+wavWriterHeaderSize-8)
+	copy(header[8:], "WAVEfmt ")
+	binary.LittleEndian.PutUint32(header[16:], 16)
+	binary.LittleEndian.PutUint16(header[20:], uint16(audio_system.WavFormatFloat))
+	binary.LittleEndian.PutUint16(header[22:], uint16(w.channels))
+	binary.LittleEndian.PutUint32(header[24:], uint32(w.sampleRate))
+	binary.LittleEndian.PutUint32(header[28:], uint32(w.sampleRate*w.channels*4))
+	binary.LittleEndian.PutUint16(header[32:], uint16(w.channels*4))
+	binary.LittleEndian.PutUint16(header[34:], 32)
+	copy(header[36:], "data")
+	binary.LittleEndian.PutUint32(header[40:], dataSize)
+	_, err := w.buffer.Write(header[:])
+	return err
+}
+
+// Write appends the interleaved samples to the file
+func (w *WavWriter) Write(samples []float32) error {
+	if w.closed {
+		return errors.New("the wav writer has been closed")
+	}
+	var b [4]byte
+	for _, s := range samples {
+		binary.LittleEndian.PutUint32(b[:], math.Float32bits(s))
+		if _, err := w.buffer.Write(b[:]); err != nil {
+			return err
+		}
+	}
+	w.samples += int64(len(samples))
+	return nil
+}
+
+// Duration returns the length (in seconds) of the audio written so far
+func (w *WavWriter) Duration() float64 {
+	return float64(w.samples/int64(w.channels)) / float64(w.sampleRate)
+}
+
+// Close fills in the sizes in the header, it does not close the output
+func (w *WavWriter) Close() error {
+	if w.closed {
+		return nil
+	}
+	w.closed = true
+	if err := w.buffer.Flush(); err != nil {
+		return err
+	}
+	end, err := w.out.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return err
+	}
+	if _, err := w.out.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	if err := w.writeHeader(); err != nil {
+		return err
+	}
+	if err := w.buffer.Flush(); err != nil {
+		return err
+	}
+	_, err = w.out.Seek(end, io.SeekStart)
+	return err
+}
@@ -0,0 +1,84 @@
+/******************************************************************************/
+/* watcher.go                                                                 */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package filesystem
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Watcher polls the files within a set of folders for changes. It is meant for
+// development features like hot reloading, where a few hundred files are
+// checked a couple of times a second, rather than for watching large trees.
+type Watcher struct {
+	folders  []string
+	modified map[string]time.Time
+}
+
+// NewWatcher creates a watcher for all of the files within the given folders
+// and their subfolders. The files that exist when the watcher is created are
+// not reported as changes.
+func NewWatcher(folders ...string) Watcher {
+	w := Watcher{
+		folders:  folders,
+		modified: make(map[string]time.Time),
+	}
+	w.Changes()
+	return w
+}
+
+// Changes returns the files that were created or modified since the last time
+// changes were checked. Folders that do not exist are skipped.
+func (w *Watcher) Changes() []string {
+	var changes []string
+	for _, folder := range w.folders {
+		filepath.Walk(folder, func(path string, info os.FileInfo, err error) error {
+			if err != nil || info.IsDir() {
+				return nil
+			}
+			last, ok := w.modified[path]
+			if !ok || info.ModTime().After(last) {
+				w.modified[path] = info.ModTime()
+				changes = append(changes, path)
+			}
+			return nil
+		})
+	}
+	return changes
+}
@@ -4,5 +4,5 @@ package tracing
 
 type TraceRegion struct{}
 
-func NewTraceRegion(name string) TraceRegion { return TraceRegion{} }
-func (t TraceRegion) End()                   {}
+func NewRegion(name string) TraceRegion { return TraceRegion{} }
+func (t TraceRegion) End()              {}
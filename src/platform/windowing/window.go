@@ -76,6 +76,9 @@ type Window struct {
 	isCrashed                bool
 	fatalFromNativeAPI       bool
 	resizedFromNativeAPI     bool
+	headless                 bool
+	headlessInput            headlessInputQueue
+	headlessClipboard        string
 }
 
 type FileSearch struct {
@@ -149,8 +152,21 @@ func (w *Window) ToLocalPosition(x, y int) (int, int) {
 	return x - (w.x + leftBorder), y - (w.y + topBorder)
 }
 
-func (w *Window) PlatformWindow() unsafe.Pointer   { return w.cHandle() }
-func (w *Window) PlatformInstance() unsafe.Pointer { return w.cInstance() }
+func (w *Window) PlatformWindow() unsafe.Pointer {
+	if w.headless {
+		return nil
+	}
+	return w.cHandle()
+}
+
+func (w *Window) PlatformInstance() unsafe.Pointer {
+	if w.headless {
+		return nil
+	}
+	return w.cInstance()
+}
+
+func (w *Window) IsHeadless() bool { return w.headless }
 
 func (w *Window) IsClosed() bool  { return w.isClosed }
 func (w *Window) IsCrashed() bool { return w.isCrashed }
@@ -273,7 +289,11 @@ func (w *Window) Poll() {
 		<-w.windowSync
 		w.syncRequest = false
 	}
-	w.poll()
+	if w.headless {
+		w.headlessInput.apply(w)
+	} else {
+		w.poll()
+	}
 	if w.resizedFromNativeAPI {
 		w.resizedFromNativeAPI = false
 		if w.Renderer != nil {
@@ -303,12 +323,18 @@ func (w *Window) EndUpdate() {
 
 func (w *Window) SwapBuffers() {
 	defer tracing.NewRegion("Window::SwapBuffers").End()
-	if w.Renderer.SwapFrame(int32(w.Width()), int32(w.Height())) {
+	if w.Renderer == nil {
+		return
+	}
+	if w.Renderer.SwapFrame(int32(w.Width()), int32(w.Height())) && !w.headless {
 		swapBuffers(w.handle)
 	}
 }
 
 func (w *Window) SizeMM() (int, int, error) {
+	if w.headless {
+		return headlessSizeMM(w.width, w.height)
+	}
 	return w.sizeMM()
 }
 
@@ -332,41 +358,53 @@ func DPI2PX(pixels, mm, targetMM int) int {
 
 func (w *Window) CursorStandard() {
 	w.cursorChangeCount = max(0, w.cursorChangeCount-1)
-	if w.cursorChangeCount == 0 {
+	if w.cursorChangeCount == 0 && !w.headless {
 		w.cursorStandard()
 	}
 }
 
 func (w *Window) CursorIbeam() {
-	if w.canChangeCursor() {
+	if w.canChangeCursor() && !w.headless {
 		w.cursorIbeam()
 	}
 	w.cursorChangeCount++
 }
 
 func (w *Window) CursorSizeAll() {
-	if w.canChangeCursor() {
+	if w.canChangeCursor() && !w.headless {
 		w.cursorSizeAll()
 	}
 	w.cursorChangeCount++
 }
 
 func (w *Window) CursorSizeNS() {
-	if w.canChangeCursor() {
+	if w.canChangeCursor() && !w.headless {
 		w.cursorSizeNS()
 	}
 	w.cursorChangeCount++
 }
 
 func (w *Window) CursorSizeWE() {
-	if w.canChangeCursor() {
+	if w.canChangeCursor() && !w.headless {
 		w.cursorSizeWE()
 	}
 	w.cursorChangeCount++
 }
 
-func (w *Window) CopyToClipboard(text string) { w.copyToClipboard(text) }
-func (w *Window) ClipboardContents() string   { return w.clipboardContents() }
+func (w *Window) CopyToClipboard(text string) {
+	if w.headless {
+		w.headlessClipboard = text
+	} else {
+		w.copyToClipboard(text)
+	}
+}
+
+func (w *Window) ClipboardContents() string {
+	if w.headless {
+		return w.headlessClipboard
+	}
+	return w.clipboardContents()
+}
 
 func (w *Window) removeFromActiveWindows() {
 	for i := range activeWindows {
@@ -379,17 +417,28 @@ func (w *Window) removeFromActiveWindows() {
 
 func (w *Window) Destroy() {
 	w.isClosed = true
-	w.Renderer.Destroy()
+	if w.Renderer != nil {
+		w.Renderer.Destroy()
+	}
+	if w.headless {
+		return
+	}
 	w.destroy()
 	w.removeFromActiveWindows()
 }
 
 func (w *Window) Focus() {
+	if w.headless {
+		return
+	}
 	w.focus()
 	w.cursorStandard()
 }
 
 func (w *Window) Position() (x int, y int) {
+	if w.headless {
+		return w.x, w.y
+	}
 	x, y = w.position()
 	w.x = x
 	w.y = y
@@ -397,19 +446,37 @@ func (w *Window) Position() (x int, y int) {
 }
 
 func (w *Window) SetPosition(x, y int) {
-	w.setPosition(x, y)
+	if !w.headless {
+		w.setPosition(x, y)
+	}
 	w.x = x
 	w.y = y
 }
 
 func (w *Window) SetSize(width, height int) {
-	w.setSize(width, height)
+	if w.headless {
+		// There is no native window to report the resize back, so the
+		// resize is applied on the next poll like a native one would be
+		w.resizedFromNativeAPI = w.resizedFromNativeAPI ||
+			w.width != width || w.height != height
+	} else {
+		w.setSize(width, height)
+	}
 	w.width = width
 	w.height = height
 }
 
-func (w *Window) RemoveBorder() { w.removeBorder() }
-func (w *Window) AddBorder()    { w.addBorder() }
+func (w *Window) RemoveBorder() {
+	if !w.headless {
+		w.removeBorder()
+	}
+}
+
+func (w *Window) AddBorder() {
+	if !w.headless {
+		w.addBorder()
+	}
+}
 
 func (w *Window) Center() (x int, y int) {
 	x, y = w.Position()
@@ -425,6 +492,9 @@ func (w *Window) becameInactive() {
 }
 
 func (w *Window) becameActive() {
+	if w.headless {
+		return
+	}
 	w.cursorStandard()
 	idx := -1
 	for i := range activeWindows {
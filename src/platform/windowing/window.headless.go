@@ -0,0 +1,190 @@
+/******************************************************************************/
+/* window.headless.go                                                         */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package windowing
+
+import (
+	"kaiju/platform/hid"
+	"kaiju/rendering"
+	"sync"
+)
+
+// headlessDPI is the assumed pixel density of a headless window, it is only
+// used to answer SizeMM the same way a common desktop monitor would
+const headlessDPI = 96
+
+type headlessInputQueue struct {
+	mutex   sync.Mutex
+	pending []func(w *Window)
+}
+
+// NewHeadless creates a window that has no native window or graphics context
+// behind it. Input is supplied through the Inject* functions and is applied
+// on the next call to Poll, as if it came from the OS. The renderer may be
+// nil, in which case the window is only useful for running updates (servers,
+// simulations, tests). Headless windows are not part of the active window
+// list, so they never take part in cross-window drag and drop.
+//
+// The native window backends are still linked into the binary, so on Linux
+// the X11 libraries need to be installed even if only headless windows are
+// used. Building with the nowindow tag leaves them out, New then always
+// returns an error.
+func NewHeadless(windowName string, width, height int, renderer rendering.Renderer) *Window {
+	w := &Window{
+		Keyboard:   hid.NewKeyboard(),
+		Mouse:      hid.NewMouse(),
+		Touch:      hid.NewTouch(),
+		Stylus:     hid.NewStylus(),
+		Controller: hid.NewController(),
+		Renderer:   renderer,
+		width:      width,
+		height:     height,
+		right:      width,
+		bottom:     height,
+		title:      windowName,
+		windowSync: make(chan struct{}),
+		headless:   true,
+	}
+	w.Cursor = hid.NewCursor(&w.Mouse, &w.Touch, &w.Stylus)
+	return w
+}
+
+func headlessSizeMM(width, height int) (int, int, error) {
+	const mmPerInch = 25.4
+	return int(float64(width) / headlessDPI * mmPerInch),
+		int(float64(height) / headlessDPI * mmPerInch), nil
+}
+
+func (q *headlessInputQueue) push(fn func(w *Window)) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	q.pending = append(q.pending, fn)
+}
+
+func (q *headlessInputQueue) apply(w *Window) {
+	q.mutex.Lock()
+	pending := q.pending
+	q.pending = nil
+	q.mutex.Unlock()
+	for i := range pending {
+		pending[i](w)
+	}
+}
+
+// InjectKey queues a key press or release on a headless window. It is safe to
+// call from any goroutine, the key is applied during the next Poll.
+func (w *Window) InjectKey(key hid.KeyboardKey, down bool) {
+	w.headlessInput.push(func(w *Window) {
+		if down {
+			w.Keyboard.SetKeyDown(key)
+		} else {
+			w.Keyboard.SetKeyUp(key)
+		}
+	})
+}
+
+// InjectMouseMove queues a mouse move to the given window position, where
+// 0,0 is the top left of the window like it is for native mouse events
+func (w *Window) InjectMouseMove(x, y float32) {
+	w.headlessInput.push(func(w *Window) {
+		w.Mouse.SetPosition(x, y, float32(w.width), float32(w.height))
+	})
+}
+
+// InjectMouseButton queues a mouse button (hid.MouseButtonLeft, etc.) press
+// or release on a headless window
+func (w *Window) InjectMouseButton(button int, down bool) {
+	w.headlessInput.push(func(w *Window) {
+		if down {
+			w.Mouse.SetDown(button)
+		} else {
+			w.Mouse.SetUp(button)
+		}
+	})
+}
+
+// InjectMouseScroll queues a scroll of the mouse wheel by the given delta
+func (w *Window) InjectMouseScroll(deltaX, deltaY float32) {
+	w.headlessInput.push(func(w *Window) {
+		s := w.Mouse.Scroll()
+		w.Mouse.SetScroll(s.X()+deltaX, s.Y()+deltaY)
+	})
+}
+
+// InjectTouch queues a touch event for the given pointer id. The action is
+// one of hid.TouchActionDown, hid.TouchActionUp, or hid.TouchActionMove.
+func (w *Window) InjectTouch(id int64, action hid.TouchAction, x, y float32) {
+	w.headlessInput.push(func(w *Window) {
+		h := float32(w.height)
+		switch action {
+		case hid.TouchActionDown:
+			w.Touch.SetDown(id, x, y, h)
+		case hid.TouchActionUp:
+			w.Touch.SetUp(id, x, y, h)
+		case hid.TouchActionMove:
+			w.Touch.SetMoved(id, x, y, h)
+		}
+	})
+}
+
+// InjectControllerButton queues a button press or release on the controller
+// with the given id, the controller is connected if it wasn't already
+func (w *Window) InjectControllerButton(id, button int, down bool) {
+	w.headlessInput.push(func(w *Window) {
+		w.Controller.Connected(id)
+		if down {
+			w.Controller.SetButtonDown(id, button)
+		} else {
+			w.Controller.SetButtonUp(id, button)
+		}
+	})
+}
+
+// InjectControllerAxis queues a stick or trigger axis value on the controller
+// with the given id, the controller is connected if it wasn't already
+func (w *Window) InjectControllerAxis(id, stick int, axis float32) {
+	w.headlessInput.push(func(w *Window) {
+		w.Controller.Connected(id)
+		w.Controller.SetAxis(id, stick, axis)
+	})
+}
+
+// InjectClose queues a close request, the same as the user closing a native
+// window, so that IsClosed reports true after the next Poll
+func (w *Window) InjectClose() {
+	w.headlessInput.push(func(w *Window) { w.isClosed = true })
+}
@@ -0,0 +1,80 @@
+//go:build nowindow && !android && !ios
+
+/******************************************************************************/
+/* window.nonative.go                                                         */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package windowing
+
+/*
+// Leaves the native window backends (x11.c, win32.c) out of the build so
+// nothing links against the windowing libraries of the OS
+#cgo CFLAGS: -DKAIJU_NO_WINDOW
+*/
+import "C"
+import (
+	"log/slog"
+	"unsafe"
+)
+
+func scaleScrollDelta(delta float32) float32 {
+	return delta
+}
+
+func (w *Window) createWindow(windowName string, x, y int) {
+	slog.Error("native windows are not available when built with the nowindow tag, use NewHeadless instead")
+	w.fatalFromNativeAPI = true
+}
+
+func (w *Window) showWindow()                 {}
+func (w *Window) destroy()                    {}
+func (w *Window) poll()                       {}
+func (w *Window) cursorStandard()             {}
+func (w *Window) cursorIbeam()                {}
+func (w *Window) cursorSizeAll()              {}
+func (w *Window) cursorSizeNS()               {}
+func (w *Window) cursorSizeWE()               {}
+func (w *Window) copyToClipboard(text string) {}
+func (w *Window) clipboardContents() string   { return "" }
+func (w *Window) cHandle() unsafe.Pointer     { return nil }
+func (w *Window) cInstance() unsafe.Pointer   { return nil }
+func (w *Window) focus()                      {}
+func (w *Window) position() (x, y int)        { return 0, 0 }
+func (w *Window) setPosition(x, y int)        {}
+func (w *Window) setSize(width, height int)   {}
+func (w *Window) removeBorder()               {}
+func (w *Window) addBorder()                  {}
+func (w *Window) sizeMM() (int, int, error)   { return headlessSizeMM(w.width, w.height) }
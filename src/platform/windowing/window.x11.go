@@ -1,4 +1,4 @@
-//go:build linux && !android
+//go:build linux && !android && !nowindow
 
 /******************************************************************************/
 /* window.x11.go                                                             */
@@ -0,0 +1,93 @@
+/******************************************************************************/
+/* window_headless_test.go                                                    */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package windowing
+
+import (
+	"kaiju/platform/hid"
+	"testing"
+)
+
+func TestHeadlessInjectedInput(t *testing.T) {
+	w := NewHeadless("test", 320, 240, nil)
+	w.InjectKey(hid.KeyboardKeyA, true)
+	w.InjectMouseMove(10, 20)
+	w.InjectMouseButton(hid.MouseButtonLeft, true)
+	if w.Keyboard.KeyDown(hid.KeyboardKeyA) {
+		t.Fatal("injected input should not apply until the window is polled")
+	}
+	w.Poll()
+	if !w.Keyboard.KeyDown(hid.KeyboardKeyA) {
+		t.Error("expected the injected key to be down after polling")
+	}
+	if !w.Mouse.Pressed(hid.MouseButtonLeft) {
+		t.Error("expected the injected mouse button to be pressed")
+	}
+	if p := w.Mouse.Position(); p.X() != 10 {
+		t.Errorf("expected the mouse x position to be 10, got %f", p.X())
+	}
+	w.EndUpdate()
+	w.Poll()
+	if !w.Keyboard.KeyHeld(hid.KeyboardKeyA) {
+		t.Error("expected the injected key to be held on the next frame")
+	}
+	w.InjectClose()
+	w.Poll()
+	if !w.IsClosed() {
+		t.Error("expected the window to be closed after an injected close")
+	}
+	w.Destroy()
+}
+
+func TestHeadlessResize(t *testing.T) {
+	w := NewHeadless("test", 320, 240, nil)
+	resized := 0
+	w.OnResize.Add(func() { resized++ })
+	w.SetSize(640, 480)
+	w.Poll()
+	w.SetSize(640, 480)
+	w.Poll()
+	if resized != 1 {
+		t.Errorf("expected a single resize event, got %d", resized)
+	}
+	if w.Width() != 640 || w.Height() != 480 {
+		t.Errorf("unexpected size %dx%d", w.Width(), w.Height())
+	}
+	if wmm, _, _ := w.SizeMM(); wmm != 169 {
+		t.Errorf("expected 640 pixels at 96 DPI to be 169mm, got %d", wmm)
+	}
+}
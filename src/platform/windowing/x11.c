@@ -35,7 +35,7 @@
 /* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
 /******************************************************************************/
 
-#if (defined(__linux__) || defined(__unix__)) && !defined(__ANDROID__)
+#if (defined(__linux__) || defined(__unix__)) && !defined(__ANDROID__) && !defined(KAIJU_NO_WINDOW)
 
 #include "x11.h"
 #include <stdlib.h>
@@ -47,6 +47,8 @@ import (
 
 func init() {
 	gob.Register(&ShaderDataBasic{})
+	gob.Register(&ShaderDataLit{})
+	gob.Register(&ShaderDataPBR{})
 }
 
 type DrawInstance interface {
@@ -96,6 +98,28 @@ func (t ShaderDataBasic) Size() int {
 	return int(unsafe.Sizeof(ShaderDataBasic{}) - ShaderBaseDataStart)
 }
 
+// ShaderDataLit is the instance data for the basic lit materials, setting
+// ReceiveShadows to 0 will stop shadows from being drawn onto the instance
+type ShaderDataLit struct {
+	ShaderDataBase
+	Color          matrix.Color
+	ReceiveShadows float32
+}
+
+func NewShaderDataLit(color matrix.Color) *ShaderDataLit {
+	return &ShaderDataLit{
+		ShaderDataBase: NewShaderDataBase(),
+		Color:          color,
+		ReceiveShadows: 1,
+	}
+}
+
+func (t ShaderDataLit) Size() int {
+	// The trailing padding of the struct is not part of the shader's layout
+	const end = unsafe.Offsetof(ShaderDataLit{}.ReceiveShadows) + unsafe.Sizeof(float32(0))
+	return int(end - ShaderBaseDataStart)
+}
+
 func NewShaderDataBase() ShaderDataBase {
 	sdb := ShaderDataBase{}
 	sdb.Setup()
@@ -214,7 +238,13 @@ func (d *DrawInstanceGroup) AddInstance(instance DrawInstance) {
 				b := &g.Layouts[j]
 				n := b.FullName()
 				s := d.namedInstanceData[n]
-				if len(s.bytes) < b.Capacity() {
+				if d.isMaterialParameters(n) {
+					// Material parameters are shared by all of the instances
+					if len(s.bytes) != len(d.MaterialInstance.parameters.data) {
+						s.bytes = make([]byte, len(d.MaterialInstance.parameters.data))
+						d.namedInstanceData[n] = s
+					}
+				} else if len(s.bytes) < b.Capacity() {
 					s.bytes = append(s.bytes, make([]byte, instance.NamedDataInstanceSize(n)+s.padding)...)
 					d.namedInstanceData[n] = s
 				}
@@ -246,6 +276,14 @@ func (d *DrawInstanceGroup) VisibleSize() int {
 	return d.visibleCount * (d.instanceSize + d.rawData.padding)
 }
 
+// isMaterialParameters returns true if the named buffer is the material
+// parameters block, which is filled from the material instance rather than
+// from the draw instances
+func (d *DrawInstanceGroup) isMaterialParameters(name string) bool {
+	p := &d.MaterialInstance.parameters
+	return p.name != "" && p.name == name
+}
+
 func (d *DrawInstanceGroup) updateNamedData(index int, instance DrawInstance, name string) {
 	if !instance.UpdateNamedData(index, d.namedBuffers[name].capacity, name) {
 		return
@@ -274,7 +312,9 @@ func (d *DrawInstanceGroup) UpdateData(renderer Renderer) {
 		} else if instance.IsActive() {
 			if d.generatedSets {
 				for k := range d.namedInstanceData {
-					d.updateNamedData(instanceIndex, instance, k)
+					if !d.isMaterialParameters(k) {
+						d.updateNamedData(instanceIndex, instance, k)
+					}
 				}
 			}
 			to := unsafe.Pointer(uintptr(base) + offset)
@@ -284,6 +324,11 @@ func (d *DrawInstanceGroup) UpdateData(renderer Renderer) {
 			instanceIndex++
 		}
 	}
+	for k, v := range d.namedInstanceData {
+		if d.isMaterialParameters(k) {
+			copy(v.bytes, d.MaterialInstance.parameters.data)
+		}
+	}
 	if count < len(d.Instances) {
 		newMemLen := count * (d.instanceSize + d.rawData.padding)
 		d.Instances = d.Instances[:count]
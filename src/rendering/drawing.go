@@ -40,7 +40,8 @@ package rendering
 import (
 	"kaiju/matrix"
 	"kaiju/platform/profiler/tracing"
-	"sort"
+	"log/slog"
+	"slices"
 	"sync"
 )
 
@@ -50,6 +51,15 @@ type Drawing struct {
 	Mesh       *Mesh
 	ShaderData DrawInstance
 	Transform  *matrix.Transform
+	// CastShadows will also draw the mesh into the shadow map of the lights
+	// that cast shadows, this requires Drawings.EnableShadows to have been
+	// called before the drawing is added. Skinned meshes cast the shadow of
+	// their bind pose.
+	CastShadows bool
+	// RenderTargets will also draw the mesh into each of the render targets,
+	// as seen through the camera of the target. Materials that draw into more
+	// than one color image, like order independent transparency, are skipped.
+	RenderTargets []*RenderTarget
 }
 
 func (d *Drawing) IsValid() bool {
@@ -64,6 +74,8 @@ type RenderPassGroup struct {
 type Drawings struct {
 	renderPassGroups []RenderPassGroup
 	backDraws        []Drawing
+	shadowMaterial   *Material
+	shadowViews      *shadowViews
 	mutex            sync.RWMutex
 }
 
@@ -123,10 +135,7 @@ func (d *Drawings) PreparePending() {
 		drawing := &d.backDraws[i]
 		rpGroup, ok := d.findRenderPassGroup(drawing.Material.renderPass)
 		if !ok {
-			d.renderPassGroups = append(d.renderPassGroups, RenderPassGroup{
-				renderPass: drawing.Material.renderPass,
-			})
-			rpGroup = &d.renderPassGroups[len(d.renderPassGroups)-1]
+			rpGroup = d.addRenderPassGroup(drawing.Material.renderPass)
 		}
 		draw, ok := rpGroup.findShaderDraw(drawing.Material)
 		if !ok {
@@ -153,23 +162,90 @@ func (d *Drawings) PreparePending() {
 	d.backDraws = d.backDraws[:0]
 }
 
-func (d *Drawings) AddDrawing(drawing Drawing) {
+// addRenderPassGroup inserts a group for the render pass, the groups are kept
+// in the sort order of their render pass as that is the order they are drawn
+func (d *Drawings) addRenderPassGroup(renderPass *RenderPass) *RenderPassGroup {
+	idx := len(d.renderPassGroups)
+	for i := range d.renderPassGroups {
+		if renderPass.construction.Sort < d.renderPassGroups[i].renderPass.construction.Sort {
+			idx = i
+			break
+		}
+	}
+	d.renderPassGroups = slices.Insert(d.renderPassGroups, idx,
+		RenderPassGroup{renderPass: renderPass})
+	return &d.renderPassGroups[idx]
+}
+
+// EnableShadows sets the material that shadow casting drawings are drawn
+// into the shadow atlas with and turns on the shadows for the lights. Only
+// drawings that are added after this call will cast shadows.
+func (d *Drawings) EnableShadows(material *Material, lights *LightList) {
+	if material == nil || material.renderPass == nil {
+		slog.Error("the shadow material is missing its render pass, shadows will not be enabled")
+		return
+	}
 	d.mutex.Lock()
 	defer d.mutex.Unlock()
-	d.backDraws = append(d.backDraws, drawing)
+	d.shadowMaterial = material
+	d.shadowViews = &lights.shadowViews
+	// The shadow pass is always drawn, even without casters, so that the
+	// atlas is cleared before the lit materials sample it
+	if _, ok := d.findRenderPassGroup(material.renderPass); !ok {
+		d.addRenderPassGroup(material.renderPass)
+	}
+	lights.mutex.Lock()
+	defer lights.mutex.Unlock()
+	if w := material.renderPass.construction.Width; w > 0 {
+		lights.shadowSlotSize = matrix.Float(w / ShadowAtlasColumns)
+	}
+	lights.Shadows.Enabled = true
+}
+
+// appendDraw adds the drawing to the back draws along with a drawing for
+// each of the shadow atlas slots when it casts shadows
+func (d *Drawings) appendDraw(drawing Drawing) {
 	if drawing.Material == nil {
 		panic("no")
 	}
+	d.backDraws = append(d.backDraws, drawing)
+	for _, target := range drawing.RenderTargets {
+		if material := target.material(drawing.Material); material != nil {
+			d.backDraws = append(d.backDraws, Drawing{
+				Renderer:   drawing.Renderer,
+				Material:   material,
+				Mesh:       drawing.Mesh,
+				ShaderData: &renderTargetInstance{drawing.ShaderData},
+				Transform:  drawing.Transform,
+			})
+		}
+	}
+	if !drawing.CastShadows || d.shadowMaterial == nil {
+		return
+	}
+	for slot := range int32(MaxShadowViews) {
+		caster := &shadowCaster{caster: drawing.ShaderData, views: d.shadowViews}
+		caster.data.slot = slot
+		d.backDraws = append(d.backDraws, Drawing{
+			Renderer:   drawing.Renderer,
+			Material:   d.shadowMaterial,
+			Mesh:       drawing.Mesh,
+			ShaderData: caster,
+		})
+	}
+}
+
+func (d *Drawings) AddDrawing(drawing Drawing) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	d.appendDraw(drawing)
 }
 
 func (d *Drawings) AddDrawings(drawings []Drawing) {
 	d.mutex.Lock()
 	defer d.mutex.Unlock()
-	d.backDraws = append(d.backDraws, drawings...)
 	for i := range drawings {
-		if drawings[i].Material == nil {
-			panic("no")
-		}
+		d.appendDraw(drawings[i])
 	}
 }
 
@@ -181,18 +257,34 @@ func (d *Drawings) Render(renderer Renderer) {
 	passes := make([]*RenderPass, 0, len(d.renderPassGroups))
 	for i := range d.renderPassGroups {
 		rp := d.renderPassGroups[i].renderPass
-		if renderer.Draw(rp, d.renderPassGroups[i].draws) {
+		if renderer.Draw(rp, d.renderPassGroups[i].draws) && !rp.construction.Offscreen {
 			passes = append(passes, rp)
 		}
 	}
 	if len(passes) > 0 {
-		sort.Slice(passes, func(i, j int) bool {
-			return passes[i].construction.Sort < passes[j].construction.Sort
-		})
 		renderer.BlitTargets(passes)
 	}
 }
 
+// RemoveRenderTarget destroys the drawings that draw into the render target,
+// this is to be called before the render target is destroyed
+func (d *Drawings) RemoveRenderTarget(target *RenderTarget, renderer Renderer) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	d.backDraws = slices.DeleteFunc(d.backDraws, func(drawing Drawing) bool {
+		return drawing.Material.renderPass == target.pass
+	})
+	d.renderPassGroups = slices.DeleteFunc(d.renderPassGroups, func(g RenderPassGroup) bool {
+		if g.renderPass != target.pass {
+			return false
+		}
+		for i := range g.draws {
+			g.draws[i].Destroy(renderer)
+		}
+		return true
+	})
+}
+
 func (d *Drawings) Destroy(renderer Renderer) {
 	for i := range d.renderPassGroups {
 		for j := range d.renderPassGroups[i].draws {
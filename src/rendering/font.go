@@ -45,6 +45,7 @@ import (
 	"kaiju/klib"
 	"kaiju/matrix"
 	"log/slog"
+	"slices"
 	"strings"
 	"sync"
 	"unicode"
@@ -141,11 +142,16 @@ type fontBinChar struct {
 	planeBounds, atlasBounds [4]float32
 }
 
+type fontKerningPair struct {
+	left, right rune
+}
+
 type fontBin struct {
 	texture                           *Texture
 	width, height                     int32
 	metrics                           fontBinMetrics
 	letters                           map[rune]fontBinChar
+	kerning                           map[fontKerningPair]float32
 	cachedLetters, cachedOrthoLetters map[rune]*cachedLetterMesh
 }
 
@@ -167,6 +173,7 @@ type FontCache struct {
 	renderCaches                 RenderCaches
 	assetDb                      *assets.Database
 	fontFaces                    map[string]fontBin
+	fallbackFaces                []FontFace
 	instanceKey                  int64
 	FaceMutex                    sync.RWMutex
 }
@@ -203,7 +210,35 @@ func (cache *FontCache) nextInstanceKey(key rune) string {
 	return fmt.Sprintf("font_%c_%d", key, cache.instanceKey)
 }
 
+// SetFallbackFaces sets the ordered list of faces that are searched for the
+// glyph of a rune that is missing from the face the text is drawn with, for
+// example CJK or emoji faces
+func (cache *FontCache) SetFallbackFaces(faces ...FontFace) {
+	cache.FaceMutex.Lock()
+	defer cache.FaceMutex.Unlock()
+	// A new slice is used as readers keep the old one after unlocking
+	cache.fallbackFaces = slices.Clone(faces)
+}
+
+// FallbackFaces returns the ordered list of faces that are searched for
+// runes that are missing from a face
+func (cache *FontCache) FallbackFaces() []FontFace {
+	cache.FaceMutex.RLock()
+	defer cache.FaceMutex.RUnlock()
+	return slices.Clone(cache.fallbackFaces)
+}
+
 func (cache *FontCache) requireFace(face FontFace) {
+	cache.loadFace(face)
+	cache.FaceMutex.RLock()
+	fallbacks := cache.fallbackFaces
+	cache.FaceMutex.RUnlock()
+	for _, f := range fallbacks {
+		cache.loadFace(f)
+	}
+}
+
+func (cache *FontCache) loadFace(face FontFace) {
 	cache.FaceMutex.RLock()
 	if _, ok := cache.fontFaces[face.string()]; !ok {
 		cache.FaceMutex.RUnlock()
@@ -253,6 +288,72 @@ func findBinChar(font fontBin, letter rune) fontBinChar {
 	return cached
 }
 
+// findGlyph returns the glyph for the rune and the face it was found in. The
+// fallback faces are searched in order when the font doesn't have the rune,
+// and the invalid rune proxy of the font is used when none of them have it.
+func (cache *FontCache) findGlyph(font fontBin, letter rune) (fontBinChar, fontBin) {
+	if ch, ok := font.letters[letter]; ok {
+		return ch, font
+	}
+	cache.FaceMutex.RLock()
+	defer cache.FaceMutex.RUnlock()
+	for _, f := range cache.fallbackFaces {
+		if fb, ok := cache.fontFaces[f.string()]; ok {
+			if ch, ok := fb.letters[letter]; ok {
+				return ch, fb
+			}
+		}
+	}
+	return font.letters[invalidRuneProxy], font
+}
+
+func (cache *FontCache) hasGlyph(font fontBin, letter rune) bool {
+	_, from := cache.findGlyph(font, letter)
+	_, ok := from.letters[letter]
+	return ok
+}
+
+// shape swaps the runes for the Arabic joining forms and ligatures that the
+// font, or one of the fallback faces, has glyphs for
+func (cache *FontCache) shape(font fontBin, runes []rune) []rune {
+	return shapeRunes(runes, func(r rune) bool { return cache.hasGlyph(font, r) })
+}
+
+// kern returns the kerning between two glyphs that are drawn next to each
+// other, glyphs from different faces are not kerned
+func (f fontBin) kern(left, right rune) float32 {
+	return f.kerning[fontKerningPair{left, right}]
+}
+
+// advance returns how far the pen moves for the rune when it follows the
+// previous rune on the line, including their kerning. The previous rune is
+// 0 at the start of a line.
+func (cache *FontCache) advance(font fontBin, prev, r rune) float32 {
+	ch, from := cache.findGlyph(font, r)
+	if prev != 0 {
+		if _, prevFrom := cache.findGlyph(font, prev); prevFrom.texture == from.texture {
+			return ch.advance + from.kern(prev, r)
+		}
+	}
+	return ch.advance
+}
+
+// visualLines splits the shaped runes into their lines ordered for drawing
+// from left to right
+func visualLines(runes []rune) [][]rune {
+	levels := bidiLevels(runes)
+	lines := make([][]rune, 0, 1)
+	start := 0
+	for i := 0; i <= len(runes); i++ {
+		if i == len(runes) || runes[i] == '\n' {
+			base := bidiBaseLevel(runes[start:i])
+			lines = append(lines, bidiVisualLine(runes[start:i], levels[start:i], base))
+			start = i + 1
+		}
+	}
+	return lines
+}
+
 func (cache *FontCache) charCountInWidth(font fontBin, runes []rune, maxWidth, scale float32) int {
 	wrap := false
 	spaceIndex := 0
@@ -266,9 +367,11 @@ func (cache *FontCache) charCountInWidth(font fontBin, runes []rune, maxWidth, s
 		} else if unicode.IsSpace(r) {
 			spaceIndex = i
 		}
-		// TODO:  Optimize this to use a linear array
-		ch := findBinChar(font, r)
-		wx += ch.advance * scale
+		prev := rune(0)
+		if i > 0 {
+			prev = runes[i-1]
+		}
+		wx += cache.advance(font, prev, r) * scale
 		if wx >= maxWidth && spaceIndex != 0 {
 			wrap = true
 			break
@@ -370,6 +473,18 @@ func (cache *FontCache) initFont(face FontFace, renderer Renderer, assetDb *asse
 		binary.Read(read, binary.LittleEndian, &fbc.atlasBounds)
 		bin.letters[fbc.letter] = fbc
 	}
+	// Kerning pairs follow the glyphs, older font files end after the glyphs
+	var kerningCount int32
+	if binary.Read(read, binary.LittleEndian, &kerningCount) == nil {
+		bin.kerning = make(map[fontKerningPair]float32, kerningCount)
+		for i := int32(0); i < kerningCount; i++ {
+			var pair [2]int32
+			var advance float32
+			binary.Read(read, binary.LittleEndian, &pair)
+			binary.Read(read, binary.LittleEndian, &advance)
+			bin.kerning[fontKerningPair{rune(pair[0]), rune(pair[1])}] = advance
+		}
+	}
 	sample := findBinChar(bin, 'j')
 	cSpace := fontBinChar{
 		letter:      ' ',
@@ -449,8 +564,11 @@ func (cache *FontCache) RenderMeshes(caches RenderCaches,
 		material = cache.textOrthoMaterial
 	}
 
-	// Iterate through all characters
-	runes := []rune(text)
+	// Iterate through all characters, the runes are shaped in logical order
+	// and each line is reordered for drawing once it has been wrapped
+	runes := cache.shape(fontFace, []rune(text))
+	levels := bidiLevels(runes)
+	paragraph := 0
 	textLen := len(runes)
 	charLen := textLen
 	//size_t lenLeft = textLen;
@@ -466,12 +584,15 @@ func (cache *FontCache) RenderMeshes(caches RenderCaches,
 		if maxWidth > 0 {
 			charLen = cache.charCountInWidth(fontFace, runes[current:], maxWidth, scale)
 		}
+		line := bidiVisualLine(runes[current:current+charLen],
+			levels[current:current+charLen], bidiBaseLevel(runes[paragraph:]))
 		lineWidth := float32(0.0)
 		if charLen > 0 || unicode.IsSpace(runes[current]) {
-			for _, c := range runes[current : current+charLen] {
+			prev := rune(0)
+			for _, c := range line {
 				if c != '\n' {
-					ch := findBinChar(fontFace, c)
-					lineWidth += ch.advance * scale
+					lineWidth += cache.advance(fontFace, prev, c) * scale
+					prev = c
 				}
 			}
 		}
@@ -500,12 +621,14 @@ func (cache *FontCache) RenderMeshes(caches RenderCaches,
 		yOffset *= inverseHeight
 
 		if charLen > 0 || (unicode.IsSpace(runes[current]) && runes[current] != '\n') {
-			for i := current; i < current+charLen; i++ {
-				c := runes[i]
+			prev := rune(0)
+			for _, c := range line {
 				if c == '\n' {
 					continue
 				}
-				ch := findBinChar(fontFace, c)
+				ch, glyphFace := cache.findGlyph(fontFace, c)
+				cx += (cache.advance(fontFace, prev, c) - ch.advance) * scale * inverseWidth
+				prev = c
 
 				// TODO:  Can probably use bounds directly
 				//float xpos = cx + ch.bearingX * scale;
@@ -528,7 +651,7 @@ func (cache *FontCache) RenderMeshes(caches RenderCaches,
 				var uvs matrix.Vec4
 				var clm *cachedLetterMesh = nil
 				if instanced {
-					clm = cache.cachedMeshLetter(fontFace, c, !is3D)
+					clm = cache.cachedMeshLetter(glyphFace, c, !is3D)
 				}
 				var m *Mesh
 				model := matrix.Mat4Identity()
@@ -557,8 +680,8 @@ func (cache *FontCache) RenderMeshes(caches RenderCaches,
 					uvw := ch.atlasBounds[2] - ch.atlasBounds[0]
 					uvh := ch.atlasBounds[3] - ch.atlasBounds[1]
 					uvs = matrix.Vec4{
-						uvx / float32(fontFace.width), uvy / float32(fontFace.height),
-						uvw / float32(fontFace.width), uvh / float32(fontFace.height)}
+						uvx / float32(glyphFace.width), uvy / float32(glyphFace.height),
+						uvw / float32(glyphFace.width), uvh / float32(glyphFace.height)}
 				} else {
 					// TODO:  Scale and place the mesh based on justify, baseline, etc.
 					model.MultiplyAssign(clm.transformation)
@@ -578,7 +701,7 @@ func (cache *FontCache) RenderMeshes(caches RenderCaches,
 				shaderData.SetModel(model)
 				fontMeshes = append(fontMeshes, Drawing{
 					Renderer:   cache.renderer,
-					Material:   material.CreateInstance([]*Texture{fontFace.texture}),
+					Material:   material.CreateInstance([]*Texture{glyphFace.texture}),
 					Mesh:       m,
 					ShaderData: shaderData,
 					Transform:  nil,
@@ -592,20 +715,23 @@ func (cache *FontCache) RenderMeshes(caches RenderCaches,
 		cy -= height
 		//lenLeft -= charLen;
 		current += charLen
+		if charLen > 0 && runes[current-1] == '\n' {
+			paragraph = current
+		}
 	}
 	return fontMeshes
 }
 
 func (cache *FontCache) MeasureString(face FontFace, text string, scale float32) float32 {
 	cache.requireFace(face)
-	x, maxX := float32(0.0), float32(0.0)
-	for _, r := range text {
-		if r == '\n' {
-			x = 0.0
-		} else {
-			ch := findBinChar(cache.fontFaces[face.string()], r)
-			x += ch.advance * scale
+	font := cache.fontFaces[face.string()]
+	maxX := float32(0.0)
+	for _, line := range visualLines(cache.shape(font, []rune(text))) {
+		x, prev := float32(0.0), rune(0)
+		for _, r := range line {
+			x += cache.advance(font, prev, r) * scale
 			maxX = matrix.Max(maxX, x)
+			prev = r
 		}
 	}
 	return maxX
@@ -619,7 +745,7 @@ func (cache *FontCache) MeasureStringWithin(face FontFace, text string, scale, m
 		maxHeight = max(maxHeight, lineHeight)
 	}
 	var x, y float32 = 0.0, 0.0
-	clip := []rune(text)
+	clip := cache.shape(fontFace, []rune(text))
 	for len(clip) > 0 {
 		count := klib.Clamp(cache.charCountInWidth(fontFace, clip, maxWidth, scale), 0, len(clip))
 		x = max(x, cache.MeasureString(face, string(clip[:count]), scale))
@@ -640,9 +766,10 @@ func (cache *FontCache) StringRectsWithinNew(face FontFace, text string, scale,
 		offset := current
 		count := cache.charCountInWidth(fontFace, runes[current:], maxWidth, scale)
 		x = 0.0
+		prev := rune(0)
 		for _, r := range runes[offset : offset+count] {
-			ch := findBinChar(fontFace, r)
-			w := ch.advance * scale
+			w := cache.advance(fontFace, prev, r) * scale
+			prev = r
 			h := fontFace.metrics.LineHeight * scale
 			rects = append(rects, matrix.Vec4{x, y, w, h})
 			current++
@@ -669,7 +796,7 @@ func (cache *FontCache) LineCountWithin(face FontFace, text string, scale, maxWi
 }
 
 func (cache *FontCache) MeasureCharacter(face string, r rune, pixelSize float32) matrix.Vec2 {
-	ch := findBinChar(cache.fontFaces[face], r)
+	ch, _ := cache.findGlyph(cache.fontFaces[face], r)
 	return matrix.Vec2{ch.Width() * pixelSize,
 		ch.Height() * pixelSize}
 }
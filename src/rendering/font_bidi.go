@@ -0,0 +1,297 @@
+/******************************************************************************/
+/* font_bidi.go                                                               */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package rendering
+
+import (
+	"golang.org/x/text/unicode/bidi"
+)
+
+// bidiClass returns the bidirectional class of the rune, the explicit
+// embedding, override and isolate controls are not supported and are
+// treated as boundary neutrals
+func bidiClass(r rune) bidi.Class {
+	if r == '\n' {
+		return bidi.B
+	}
+	p, _ := bidi.LookupRune(r)
+	c := p.Class()
+	if c >= bidi.Control {
+		return bidi.BN
+	}
+	return c
+}
+
+func bidiIsStrong(c bidi.Class) bool {
+	return c == bidi.L || c == bidi.R || c == bidi.AL
+}
+
+// bidiBaseLevel returns the embedding level of the paragraph that starts at
+// the first rune, it is 1 when the first strong rune before the end of the
+// paragraph is right to left and 0 otherwise
+func bidiBaseLevel(runes []rune) uint8 {
+	for _, r := range runes {
+		switch bidiClass(r) {
+		case bidi.L:
+			return 0
+		case bidi.R, bidi.AL:
+			return 1
+		case bidi.B:
+			return 0
+		}
+	}
+	return 0
+}
+
+// bidiLevels resolves the embedding level of each rune using the implicit
+// rules of the Unicode bidirectional algorithm (UAX #9). Each line break
+// starts a new paragraph with its own base level. Explicit embeddings,
+// overrides and isolates are not supported, the text that needs them is
+// rare in UI strings.
+func bidiLevels(runes []rune) []uint8 {
+	levels := make([]uint8, len(runes))
+	start := 0
+	for i := 0; i <= len(runes); i++ {
+		if i == len(runes) || runes[i] == '\n' {
+			bidiParagraphLevels(runes[start:i], levels[start:i])
+			if i < len(runes) {
+				levels[i] = bidiBaseLevel(runes[start:i])
+			}
+			start = i + 1
+		}
+	}
+	return levels
+}
+
+func bidiParagraphLevels(runes []rune, levels []uint8) {
+	base := bidiBaseLevel(runes)
+	sos := bidi.L
+	if base == 1 {
+		sos = bidi.R
+	}
+	classes := make([]bidi.Class, len(runes))
+	for i, r := range runes {
+		classes[i] = bidiClass(r)
+	}
+	// W1: non spacing marks take the class of the rune before them
+	for i, c := range classes {
+		if c == bidi.NSM {
+			if i == 0 {
+				classes[i] = sos
+			} else {
+				classes[i] = classes[i-1]
+			}
+		}
+	}
+	// W2 and W3: European numbers after Arabic letters are Arabic numbers,
+	// then Arabic letters become right to left
+	lastStrong := sos
+	for i, c := range classes {
+		switch c {
+		case bidi.L, bidi.R, bidi.AL:
+			lastStrong = c
+		case bidi.EN:
+			if lastStrong == bidi.AL {
+				classes[i] = bidi.AN
+			}
+		}
+	}
+	for i, c := range classes {
+		if c == bidi.AL {
+			classes[i] = bidi.R
+		}
+	}
+	// W4: a single separator between two numbers of the same type joins them
+	for i := 1; i < len(classes)-1; i++ {
+		prev, next := classes[i-1], classes[i+1]
+		switch classes[i] {
+		case bidi.ES:
+			if prev == bidi.EN && next == bidi.EN {
+				classes[i] = bidi.EN
+			}
+		case bidi.CS:
+			if prev == next && (prev == bidi.EN || prev == bidi.AN) {
+				classes[i] = prev
+			}
+		}
+	}
+	// W5: terminators next to European numbers are part of the number
+	for i := 0; i < len(classes); i++ {
+		if classes[i] != bidi.ET {
+			continue
+		}
+		end := i
+		for end < len(classes) && classes[end] == bidi.ET {
+			end++
+		}
+		if (i > 0 && classes[i-1] == bidi.EN) ||
+			(end < len(classes) && classes[end] == bidi.EN) {
+			for j := i; j < end; j++ {
+				classes[j] = bidi.EN
+			}
+		}
+		i = end - 1
+	}
+	// W6 and W7: leftover separators are neutral and European numbers after
+	// left to right text are left to right
+	lastStrong = sos
+	for i, c := range classes {
+		switch c {
+		case bidi.ES, bidi.ET, bidi.CS:
+			classes[i] = bidi.ON
+		case bidi.L, bidi.R:
+			lastStrong = c
+		case bidi.EN:
+			if lastStrong == bidi.L {
+				classes[i] = bidi.L
+			}
+		}
+	}
+	// N1 and N2: neutrals take the direction of the text around them when
+	// both sides agree, otherwise the direction of the paragraph. Numbers
+	// count as right to left here.
+	strongOf := func(c bidi.Class) bidi.Class {
+		switch c {
+		case bidi.L:
+			return bidi.L
+		case bidi.R, bidi.EN, bidi.AN:
+			return bidi.R
+		}
+		return bidi.ON
+	}
+	for i := 0; i < len(classes); i++ {
+		if strongOf(classes[i]) != bidi.ON {
+			continue
+		}
+		end := i
+		for end < len(classes) && strongOf(classes[end]) == bidi.ON {
+			end++
+		}
+		before, after := sos, sos
+		if i > 0 {
+			before = strongOf(classes[i-1])
+		}
+		if end < len(classes) {
+			after = strongOf(classes[end])
+		}
+		dir := sos
+		if before == after {
+			dir = before
+		}
+		for j := i; j < end; j++ {
+			classes[j] = dir
+		}
+		i = end - 1
+	}
+	// I1 and I2: resolve the levels from the paragraph's level
+	for i, c := range classes {
+		level := base
+		if base%2 == 0 {
+			switch c {
+			case bidi.R:
+				level++
+			case bidi.EN, bidi.AN:
+				level += 2
+			}
+		} else if c == bidi.L || c == bidi.EN || c == bidi.AN {
+			level++
+		}
+		levels[i] = level
+	}
+}
+
+// bidiVisualLine returns the runes of a single line in the order they are
+// drawn from left to right. The whitespace at the end of the line is moved
+// back to the paragraph level and the mirrored runes of right to left runs,
+// like brackets, are swapped for their mirror.
+func bidiVisualLine(line []rune, levels []uint8, base uint8) []rune {
+	lv := make([]uint8, len(line))
+	copy(lv, levels)
+	// L1: trailing whitespace goes back to the paragraph level
+	for i := len(line) - 1; i >= 0; i-- {
+		c := bidiClass(line[i])
+		if c != bidi.WS && c != bidi.S && c != bidi.B && c != bidi.BN {
+			break
+		}
+		lv[i] = base
+	}
+	out := make([]rune, len(line))
+	highest, lowestOdd := uint8(0), uint8(255)
+	for i, r := range line {
+		highest = max(highest, lv[i])
+		if lv[i]%2 == 1 {
+			lowestOdd = min(lowestOdd, lv[i])
+			r = bidiMirror(r)
+		}
+		out[i] = r
+	}
+	// L2: reverse every run at or above each level, from the highest level
+	// down to the lowest odd level
+	for level := highest; level >= lowestOdd && level > 0; level-- {
+		for i := 0; i < len(out); i++ {
+			if lv[i] < level {
+				continue
+			}
+			end := i
+			for end < len(out) && lv[end] >= level {
+				end++
+			}
+			for a, b := i, end-1; a < b; a, b = a+1, b-1 {
+				out[a], out[b] = out[b], out[a]
+				lv[a], lv[b] = lv[b], lv[a]
+			}
+			i = end
+		}
+	}
+	return out
+}
+
+var bidiMirrors = map[rune]rune{
+	'(': ')', ')': '(',
+	'[': ']', ']': '[',
+	'{': '}', '}': '{',
+	'<': '>', '>': '<',
+	'«': '»', '»': '«',
+	'‹': '›', '›': '‹',
+}
+
+func bidiMirror(r rune) rune {
+	if m, ok := bidiMirrors[r]; ok {
+		return m
+	}
+	return r
+}
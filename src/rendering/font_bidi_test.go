@@ -0,0 +1,107 @@
+/******************************************************************************/
+/* font_bidi_test.go                                                          */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package rendering
+
+import "testing"
+
+func testVisual(text string) string {
+	var out []rune
+	for i, line := range visualLines([]rune(text)) {
+		if i > 0 {
+			out = append(out, '\n')
+		}
+		out = append(out, line...)
+	}
+	return string(out)
+}
+
+func TestBidiLeftToRight(t *testing.T) {
+	if got := testVisual("hello (world) 42"); got != "hello (world) 42" {
+		t.Errorf("expected left to right text to be unchanged, got %q", got)
+	}
+}
+
+func TestBidiRightToLeft(t *testing.T) {
+	// Hebrew shalom olam
+	if got := testVisual("שלום עולם"); got != "םלוע םולש" {
+		t.Errorf("unexpected visual order %q", got)
+	}
+}
+
+func TestBidiNumbersInRightToLeft(t *testing.T) {
+	// The number stays left to right inside of the right to left paragraph
+	if got := testVisual("אב 123 גד"); got != "דג 123 בא" {
+		t.Errorf("unexpected visual order %q", got)
+	}
+}
+
+func TestBidiMixed(t *testing.T) {
+	if got := testVisual("abc אבג def"); got != "abc גבא def" {
+		t.Errorf("unexpected visual order %q", got)
+	}
+	if got := testVisual("אבג abc דהו"); got != "והד abc גבא" {
+		t.Errorf("unexpected visual order %q", got)
+	}
+}
+
+func TestBidiMirrorsBrackets(t *testing.T) {
+	if got := testVisual("א(ב)"); got != "(ב)א" {
+		t.Errorf("expected the brackets to be mirrored, got %q", got)
+	}
+}
+
+func TestBidiParagraphs(t *testing.T) {
+	if got := testVisual("אב\nab"); got != "בא\nab" {
+		t.Errorf("expected each line to have its own direction, got %q", got)
+	}
+	levels := bidiLevels([]rune("ab\nאב"))
+	want := []uint8{0, 0, 0, 1, 1}
+	for i := range want {
+		if levels[i] != want[i] {
+			t.Fatalf("expected levels %v, got %v", want, levels)
+		}
+	}
+}
+
+func TestBidiTrailingWhitespace(t *testing.T) {
+	// The trailing space is at the paragraph level, so it stays at the end
+	// of the left to right line rather than joining the Hebrew run
+	if got := testVisual("ab אב "); got != "ab בא " {
+		t.Errorf("unexpected visual order %q", got)
+	}
+}
@@ -0,0 +1,104 @@
+/******************************************************************************/
+/* font_fallback_test.go                                                      */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package rendering
+
+import (
+	"sync"
+	"testing"
+)
+
+func testFontCache() *FontCache {
+	cache := &FontCache{fontFaces: map[string]fontBin{
+		"latin": {letters: map[rune]fontBinChar{
+			'a': {letter: 'a'}, invalidRuneProxy: {letter: invalidRuneProxy},
+		}},
+		"cjk":   {letters: map[rune]fontBinChar{'中': {letter: '中'}}},
+		"emoji": {letters: map[rune]fontBinChar{'中': {letter: '中', advance: 1}, '🙂': {letter: '🙂'}}},
+	}}
+	cache.SetFallbackFaces("cjk", "emoji")
+	return cache
+}
+
+func TestFontFallbackGlyph(t *testing.T) {
+	cache := testFontCache()
+	font := cache.fontFaces["latin"]
+	if ch, _ := cache.findGlyph(font, 'a'); ch.letter != 'a' {
+		t.Errorf("expected the glyph from the font, got %q", ch.letter)
+	}
+	// The first fallback face with the glyph is used
+	if ch, _ := cache.findGlyph(font, '中'); ch.letter != '中' || ch.advance != 0 {
+		t.Errorf("expected the glyph from the first fallback face, got %+v", ch)
+	}
+	if ch, _ := cache.findGlyph(font, '🙂'); ch.letter != '🙂' {
+		t.Errorf("expected the glyph from the second fallback face, got %q", ch.letter)
+	}
+	if ch, _ := cache.findGlyph(font, 'z'); ch.letter != invalidRuneProxy {
+		t.Errorf("expected the invalid rune proxy, got %q", ch.letter)
+	}
+}
+
+func TestFontFallbackFacesCopy(t *testing.T) {
+	cache := testFontCache()
+	faces := cache.FallbackFaces()
+	faces[0] = "emoji"
+	if got := cache.FallbackFaces()[0]; got != "cjk" {
+		t.Errorf("expected changing the returned faces to leave the cache alone, got %s", got)
+	}
+}
+
+func TestFontFallbackConcurrent(t *testing.T) {
+	// Run with -race, the faces are set while text is being measured
+	cache := testFontCache()
+	font := cache.fontFaces["latin"]
+	wg := sync.WaitGroup{}
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		for range 1000 {
+			cache.SetFallbackFaces("emoji", "cjk")
+			cache.SetFallbackFaces("cjk")
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for range 1000 {
+			cache.findGlyph(font, '中')
+		}
+	}()
+	wg.Wait()
+}
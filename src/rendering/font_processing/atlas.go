@@ -0,0 +1,417 @@
+/******************************************************************************/
+/* atlas.go                                                                   */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package font_processing
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"image"
+	"image/png"
+	"math"
+	"os"
+	"runtime"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+var ErrAtlasTooSmall = errors.New("the glyphs do not fit in the largest atlas size")
+
+// DefaultRanges are the printable ASCII and Latin-1 supplement characters
+const DefaultRanges = "0020-007E, 00A0-00FF"
+
+// invalidRune is drawn by the FontCache in place of runes the atlas is
+// missing, so it is always put into the atlas
+const invalidRune = '_'
+
+// Options are how the distance field atlas of a font is generated, the
+// default size and range match what the FontCache draws text with
+type Options struct {
+	// Size is the number of texels per em
+	Size float64
+	// PxRange is the number of texels that the distance field spans across
+	// the edge of a glyph
+	PxRange float64
+	// MaxAtlasSize is the largest the width and height of the atlas can be,
+	// the atlas is the smallest power of 2 that fits the glyphs
+	MaxAtlasSize int
+}
+
+func DefaultOptions() Options {
+	return Options{
+		Size:         64,
+		PxRange:      4,
+		MaxAtlasSize: 8192,
+	}
+}
+
+// Metrics are the line metrics of the font in ems
+type Metrics struct {
+	EMSize, LineHeight, Ascender, Descender, UnderlineY, UnderlineThickness float32
+}
+
+// Glyph is the placement of a rune in the atlas, the bounds are left, top,
+// right, bottom. The plane bounds are in ems from the pen position with Y
+// going up and the atlas bounds are in texels from the bottom left of the
+// atlas.
+type Glyph struct {
+	Rune        rune
+	Advance     float32
+	PlaneBounds [4]float32
+	AtlasBounds [4]float32
+}
+
+// Kerning is the adjustment in ems to the advance of the left rune when it
+// is followed by the right rune
+type Kerning struct {
+	Left, Right rune
+	Advance     float32
+}
+
+// Atlas is the multi-channel signed distance field of a set of glyphs along
+// with what is needed to lay them out
+type Atlas struct {
+	Width, Height int
+	Metrics       Metrics
+	Glyphs        []Glyph
+	Kerning       []Kerning
+	// Pix is the RGBA atlas with the rows stored top to bottom
+	Pix []byte
+}
+
+type atlasGlyph struct {
+	glyph Glyph
+	msdf  msdfGlyph
+	x, y  int
+}
+
+// Generate builds the atlas for the runes that the font has glyphs for, the
+// runes the font is missing are skipped
+func Generate(font *Font, runes []rune, opts Options) (*Atlas, error) {
+	runes = slices.Clone(runes)
+	slices.Sort(runes)
+	runes = slices.Compact(runes)
+	upem := float64(font.UnitsPerEm)
+	scale := opts.Size / upem
+	a := &Atlas{
+		Metrics: Metrics{
+			EMSize:             1,
+			LineHeight:         float32(float64(font.Ascender-font.Descender+font.LineGap) / upem),
+			Ascender:           float32(float64(font.Ascender) / upem),
+			Descender:          float32(float64(font.Descender) / upem),
+			UnderlineY:         float32(float64(font.UnderlinePosition) / upem),
+			UnderlineThickness: float32(float64(font.UnderlineThickness) / upem),
+		},
+	}
+	glyphs := make([]atlasGlyph, 0, len(runes))
+	for _, r := range runes {
+		index, ok := font.GlyphIndex(r)
+		if !ok {
+			continue
+		}
+		contours, err := font.Outline(index)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read the outline of %q: %w", r, err)
+		}
+		g := atlasGlyph{glyph: Glyph{
+			Rune:    r,
+			Advance: float32(float64(font.Advance(index)) / upem),
+		}}
+		if bounds, ok := outlineBounds(contours); ok {
+			g.msdf = placeGlyph(contours, bounds, scale, opts.PxRange)
+			// The bounds are at the center of the edge texels
+			l := (0.5 - g.msdf.originX) / scale / upem
+			b := (0.5 - g.msdf.originY) / scale / upem
+			r := (float64(g.msdf.width) - 0.5 - g.msdf.originX) / scale / upem
+			t := (float64(g.msdf.height) - 0.5 - g.msdf.originY) / scale / upem
+			g.glyph.PlaneBounds = [4]float32{float32(l), float32(t), float32(r), float32(b)}
+		}
+		glyphs = append(glyphs, g)
+	}
+	if err := a.pack(glyphs, opts.MaxAtlasSize); err != nil {
+		return nil, err
+	}
+	a.Pix = make([]byte, a.Width*a.Height*4)
+	a.render(glyphs)
+	for i := range glyphs {
+		a.Glyphs = append(a.Glyphs, glyphs[i].glyph)
+	}
+	a.Kerning = kerningPairs(font, runes)
+	return a, nil
+}
+
+func outlineBounds(contours []Contour) ([4]float64, bool) {
+	b := [4]float64{math.Inf(1), math.Inf(1), math.Inf(-1), math.Inf(-1)}
+	for _, c := range contours {
+		for _, s := range c {
+			for _, p := range flatten(s) {
+				b[0], b[1] = math.Min(b[0], p.X), math.Min(b[1], p.Y)
+				b[2], b[3] = math.Max(b[2], p.X), math.Max(b[3], p.Y)
+			}
+		}
+	}
+	return b, b[0] <= b[2] && b[1] <= b[3]
+}
+
+// placeGlyph sizes the cell of the glyph to hold its outline with half of
+// the distance range around it
+func placeGlyph(contours []Contour, bounds [4]float64, scale, pxRange float64) msdfGlyph {
+	pad := pxRange / 2
+	x0 := math.Floor(bounds[0]*scale - pad)
+	y0 := math.Floor(bounds[1]*scale - pad)
+	x1 := math.Ceil(bounds[2]*scale + pad)
+	y1 := math.Ceil(bounds[3]*scale + pad)
+	return msdfGlyph{
+		contours: contours,
+		scale:    scale,
+		originX:  -x0,
+		originY:  -y0,
+		width:    int(x1-x0) + 1,
+		height:   int(y1-y0) + 1,
+		pxRange:  pxRange,
+	}
+}
+
+// pack places the glyph cells on shelves, tallest first, in the smallest
+// power of 2 square that they fit in
+func (a *Atlas) pack(glyphs []atlasGlyph, maxSize int) error {
+	order := make([]int, len(glyphs))
+	for i := range order {
+		order[i] = i
+	}
+	slices.SortStableFunc(order, func(i, j int) int {
+		return glyphs[j].msdf.height - glyphs[i].msdf.height
+	})
+	const spacing = 1
+	for size := 64; size <= max(64, maxSize); size *= 2 {
+		x, y, shelf := 0, 0, 0
+		fits := true
+		for _, i := range order {
+			w, h := glyphs[i].msdf.width, glyphs[i].msdf.height
+			if w == 0 {
+				continue
+			}
+			if x+w > size {
+				x, y, shelf = 0, y+shelf+spacing, 0
+			}
+			if w > size || y+h > size {
+				fits = false
+				break
+			}
+			glyphs[i].x, glyphs[i].y = x, y
+			x += w + spacing
+			shelf = max(shelf, h)
+		}
+		if fits {
+			a.Width, a.Height = size, size
+			for i := range glyphs {
+				g := &glyphs[i]
+				if g.msdf.width == 0 {
+					continue
+				}
+				g.glyph.AtlasBounds = [4]float32{
+					float32(g.x) + 0.5,
+					float32(g.y+g.msdf.height) - 0.5,
+					float32(g.x+g.msdf.width) - 0.5,
+					float32(g.y) + 0.5,
+				}
+			}
+			return nil
+		}
+	}
+	return ErrAtlasTooSmall
+}
+
+// render generates the glyphs in parallel and copies them into the atlas,
+// the glyph rows go from the bottom up while the atlas rows go top down
+func (a *Atlas) render(glyphs []atlasGlyph) {
+	work := make(chan int)
+	wg := sync.WaitGroup{}
+	for range runtime.NumCPU() {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range work {
+				g := &glyphs[i]
+				pix := g.msdf.generate()
+				stride := g.msdf.width * 4
+				for row := range g.msdf.height {
+					atlasRow := a.Height - 1 - (g.y + row)
+					at := (atlasRow*a.Width + g.x) * 4
+					copy(a.Pix[at:at+stride], pix[row*stride:(row+1)*stride])
+				}
+			}
+		}()
+	}
+	for i := range glyphs {
+		if glyphs[i].msdf.width > 0 {
+			work <- i
+		}
+	}
+	close(work)
+	wg.Wait()
+}
+
+func kerningPairs(font *Font, runes []rune) []Kerning {
+	var pairs []Kerning
+	upem := float32(font.UnitsPerEm)
+	for _, left := range runes {
+		lg, ok := font.GlyphIndex(left)
+		if !ok || !font.kerning.hasLeft(lg) {
+			continue
+		}
+		for _, right := range runes {
+			rg, ok := font.GlyphIndex(right)
+			if !ok {
+				continue
+			}
+			if k := font.Kerning(lg, rg); k != 0 {
+				pairs = append(pairs, Kerning{left, right, float32(k) / upem})
+			}
+		}
+	}
+	return pairs
+}
+
+// Image returns the atlas as an image
+func (a *Atlas) Image() *image.NRGBA {
+	return &image.NRGBA{
+		Pix:    a.Pix,
+		Stride: a.Width * 4,
+		Rect:   image.Rect(0, 0, a.Width, a.Height),
+	}
+}
+
+// EncodePNG returns the atlas image encoded as a PNG
+func (a *Atlas) EncodePNG() ([]byte, error) {
+	buf := bytes.Buffer{}
+	err := png.Encode(&buf, a.Image())
+	return buf.Bytes(), err
+}
+
+// EncodeBin returns the glyphs, metrics and kerning in the binary layout
+// that the FontCache reads from the .bin file next to the atlas image
+func (a *Atlas) EncodeBin() []byte {
+	buf := bytes.Buffer{}
+	w := func(v any) { binary.Write(&buf, binary.LittleEndian, v) }
+	w(int32(len(a.Glyphs)))
+	w(int32(a.Width))
+	w(int32(a.Height))
+	w(a.Metrics)
+	for _, g := range a.Glyphs {
+		w(int32(g.Rune))
+		w(g.Advance)
+		w(g.PlaneBounds)
+		w(g.AtlasBounds)
+	}
+	w(int32(len(a.Kerning)))
+	for _, k := range a.Kerning {
+		w(int32(k.Left))
+		w(int32(k.Right))
+		w(k.Advance)
+	}
+	return buf.Bytes()
+}
+
+// ParseRanges reads a comma separated list of hexadecimal code points and
+// inclusive code point ranges, like "0020-007E, 00A0-00FF, 20AC"
+func ParseRanges(ranges string) ([]rune, error) {
+	var runes []rune
+	for _, part := range strings.Split(ranges, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		first, last, isRange := strings.Cut(part, "-")
+		start, err := parseCodePoint(first)
+		if err != nil {
+			return nil, err
+		}
+		end := start
+		if isRange {
+			if end, err = parseCodePoint(last); err != nil {
+				return nil, err
+			}
+		}
+		if end < start {
+			return nil, fmt.Errorf("the unicode range %q ends before it starts", part)
+		}
+		for r := start; r <= end; r++ {
+			runes = append(runes, r)
+		}
+	}
+	return runes, nil
+}
+
+func parseCodePoint(s string) (rune, error) {
+	s = strings.TrimPrefix(strings.TrimPrefix(strings.TrimSpace(s), "U+"), "u+")
+	v, err := strconv.ParseUint(s, 16, 32)
+	if err != nil || v > unicodeMax {
+		return 0, fmt.Errorf("invalid unicode code point %q", s)
+	}
+	return rune(v), nil
+}
+
+// GenerateFile reads the TTF/OTF font file at path and writes the atlas for
+// the runes to outPath with the .png and .bin extensions added, these are
+// the files the FontCache reads for a font face
+func GenerateFile(path, outPath string, runes []rune, opts Options) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	font, err := Parse(data)
+	if err != nil {
+		return err
+	}
+	atlas, err := Generate(font, append(runes, invalidRune), opts)
+	if err != nil {
+		return err
+	}
+	img, err := atlas.EncodePNG()
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(outPath+".png", img, os.ModePerm); err != nil {
+		return err
+	}
+	return os.WriteFile(outPath+".bin", atlas.EncodeBin(), os.ModePerm)
+}
@@ -0,0 +1,539 @@
+/******************************************************************************/
+/* cff.go                                                                     */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package font_processing
+
+import (
+	"errors"
+)
+
+var errCharstring = errors.New("the font has a malformed CFF charstring")
+
+const (
+	cffMaxSubrDepth = 10
+	cffMaxStack     = 48
+)
+
+// cffFont holds the parts of a CFF table needed to draw the glyphs, CID
+// keyed fonts select the local subroutines of each glyph through FDSelect
+type cffFont struct {
+	charStrings [][]byte
+	globalSubrs [][]byte
+	localSubrs  [][][]byte
+	fdSelect    []uint8
+}
+
+type cffReader struct {
+	sfntReader
+}
+
+func (r cffReader) offset(at, size int) int {
+	v := 0
+	for i := range size {
+		v = v<<8 | int(r.u8(at+i))
+	}
+	return v
+}
+
+// index reads a CFF INDEX and returns its objects and where it ends
+func (r cffReader) index(at int) ([][]byte, int, error) {
+	count := int(r.u16(at))
+	if count == 0 {
+		return nil, at + 2, nil
+	}
+	offSize := int(r.u8(at + 2))
+	if offSize < 1 || offSize > 4 {
+		return nil, 0, ErrMalformedFont
+	}
+	offsets := at + 3
+	base := offsets + (count+1)*offSize - 1
+	items := make([][]byte, count)
+	for i := range items {
+		start := r.offset(offsets+i*offSize, offSize)
+		end := r.offset(offsets+(i+1)*offSize, offSize)
+		item, ok := r.slice(base+start, end-start)
+		if !ok {
+			return nil, 0, ErrMalformedFont
+		}
+		items[i] = item
+	}
+	return items, base + r.offset(offsets+count*offSize, offSize), nil
+}
+
+// cffDict reads a DICT into a map of operator to operands, two byte
+// operators are stored as 1200 + the second byte
+func cffDict(data []byte) map[int][]float64 {
+	r := cffReader{sfntReader{data}}
+	dict := map[int][]float64{}
+	var operands []float64
+	for at := 0; at < len(data); {
+		b0 := int(data[at])
+		switch {
+		case b0 <= 21:
+			op := b0
+			at++
+			if b0 == 12 {
+				op = 1200 + int(r.u8(at))
+				at++
+			}
+			dict[op] = operands
+			operands = nil
+		case b0 == 28:
+			operands = append(operands, float64(r.i16(at+1)))
+			at += 3
+		case b0 == 29:
+			operands = append(operands, float64(int32(r.u32(at+1))))
+			at += 5
+		case b0 == 30:
+			// Real numbers are only used for things like the font matrix,
+			// they are skipped
+			at++
+			for at < len(data) {
+				b := data[at]
+				at++
+				if b&0x0F == 0x0F || b>>4 == 0x0F {
+					break
+				}
+			}
+			operands = append(operands, 0)
+		case b0 >= 32 && b0 <= 246:
+			operands = append(operands, float64(b0-139))
+			at++
+		case b0 >= 247 && b0 <= 250:
+			operands = append(operands, float64((b0-247)*256+int(r.u8(at+1))+108))
+			at += 2
+		case b0 >= 251 && b0 <= 254:
+			operands = append(operands, float64(-(b0-251)*256-int(r.u8(at+1))-108))
+			at += 2
+		default:
+			at++
+		}
+	}
+	return dict
+}
+
+const (
+	cffOpCharStrings = 17
+	cffOpPrivate     = 18
+	cffOpSubrs       = 19
+	cffOpFDArray     = 1236
+	cffOpFDSelect    = 1237
+)
+
+func parseCFF(data []byte) (*cffFont, error) {
+	r := cffReader{sfntReader{data}}
+	at := int(r.u8(2))
+	_, at, err := r.index(at) // names
+	if err != nil {
+		return nil, err
+	}
+	topDicts, at, err := r.index(at)
+	if err != nil || len(topDicts) == 0 {
+		return nil, ErrMalformedFont
+	}
+	_, at, err = r.index(at) // strings
+	if err != nil {
+		return nil, err
+	}
+	f := &cffFont{}
+	if f.globalSubrs, _, err = r.index(at); err != nil {
+		return nil, err
+	}
+	top := cffDict(topDicts[0])
+	cs, ok := top[cffOpCharStrings]
+	if !ok || len(cs) == 0 {
+		return nil, ErrMalformedFont
+	}
+	if f.charStrings, _, err = r.index(int(cs[0])); err != nil {
+		return nil, err
+	}
+	if fdArray, ok := top[cffOpFDArray]; ok && len(fdArray) > 0 {
+		fonts, _, err := r.index(int(fdArray[0]))
+		if err != nil {
+			return nil, err
+		}
+		for _, fd := range fonts {
+			subrs, err := r.privateSubrs(cffDict(fd))
+			if err != nil {
+				return nil, err
+			}
+			f.localSubrs = append(f.localSubrs, subrs)
+		}
+		if sel, ok := top[cffOpFDSelect]; ok && len(sel) > 0 {
+			f.fdSelect = r.fdSelect(int(sel[0]), len(f.charStrings))
+		}
+	} else {
+		subrs, err := r.privateSubrs(top)
+		if err != nil {
+			return nil, err
+		}
+		f.localSubrs = [][][]byte{subrs}
+	}
+	return f, nil
+}
+
+func (r cffReader) privateSubrs(dict map[int][]float64) ([][]byte, error) {
+	private, ok := dict[cffOpPrivate]
+	if !ok || len(private) < 2 {
+		return nil, nil
+	}
+	size, offset := int(private[0]), int(private[1])
+	data, ok := r.slice(offset, size)
+	if !ok {
+		return nil, ErrMalformedFont
+	}
+	subrs, ok := cffDict(data)[cffOpSubrs]
+	if !ok || len(subrs) == 0 {
+		return nil, nil
+	}
+	items, _, err := r.index(offset + int(subrs[0]))
+	return items, err
+}
+
+func (r cffReader) fdSelect(at, glyphs int) []uint8 {
+	sel := make([]uint8, glyphs)
+	switch r.u8(at) {
+	case 0:
+		for i := range sel {
+			sel[i] = r.u8(at + 1 + i)
+		}
+	case 3:
+		ranges := int(r.u16(at + 1))
+		for i := range ranges {
+			rec := at + 3 + i*3
+			first, fd, next := int(r.u16(rec)), r.u8(rec+2), int(r.u16(rec+3))
+			for g := first; g < next && g < glyphs; g++ {
+				sel[g] = fd
+			}
+		}
+	}
+	return sel
+}
+
+func cffSubrBias(count int) int {
+	switch {
+	case count < 1240:
+		return 107
+	case count < 33900:
+		return 1131
+	}
+	return 32768
+}
+
+// cffPath builds the contours of a Type 2 charstring
+type cffPath struct {
+	contours []Contour
+	current  Contour
+	start    Point
+	pen      Point
+	open     bool
+}
+
+func (p *cffPath) moveTo(d Point) {
+	p.close()
+	p.pen = p.pen.add(d)
+	p.start = p.pen
+	p.open = true
+}
+
+func (p *cffPath) lineTo(d Point) {
+	end := p.pen.add(d)
+	p.current = append(p.current, Segment{Degree: 1, Points: [4]Point{p.pen, end}})
+	p.pen = end
+}
+
+func (p *cffPath) curveTo(d1, d2, d3 Point) {
+	c1 := p.pen.add(d1)
+	c2 := c1.add(d2)
+	end := c2.add(d3)
+	p.current = append(p.current, Segment{Degree: 3, Points: [4]Point{p.pen, c1, c2, end}})
+	p.pen = end
+}
+
+func (p *cffPath) close() {
+	if !p.open {
+		return
+	}
+	if p.pen != p.start {
+		p.current = append(p.current, Segment{Degree: 1, Points: [4]Point{p.pen, p.start}})
+	}
+	if len(p.current) > 0 {
+		p.contours = append(p.contours, p.current)
+	}
+	p.current = nil
+	p.open = false
+}
+
+type cffInterpreter struct {
+	font     *cffFont
+	local    [][]byte
+	path     cffPath
+	stack    []float64
+	stems    int
+	sawWidth bool
+	finished bool
+}
+
+func (f *cffFont) outline(glyph int) ([]Contour, error) {
+	if glyph >= len(f.charStrings) {
+		return nil, ErrGlyphOutOfBounds
+	}
+	in := cffInterpreter{font: f}
+	fd := 0
+	if glyph < len(f.fdSelect) {
+		fd = int(f.fdSelect[glyph])
+	}
+	if fd < len(f.localSubrs) {
+		in.local = f.localSubrs[fd]
+	}
+	if err := in.run(f.charStrings[glyph], 0); err != nil {
+		return nil, err
+	}
+	in.path.close()
+	return in.path.contours, nil
+}
+
+// clearWidth drops the optional width that comes before the arguments of
+// the first stack clearing operator
+func (in *cffInterpreter) clearWidth(hasWidth bool) {
+	if !in.sawWidth && hasWidth && len(in.stack) > 0 {
+		in.stack = in.stack[1:]
+	}
+	in.sawWidth = true
+}
+
+func (in *cffInterpreter) run(code []byte, depth int) error {
+	if depth > cffMaxSubrDepth {
+		return errCharstring
+	}
+	r := sfntReader{code}
+	for at := 0; at < len(code) && !in.finished; {
+		b0 := int(code[at])
+		if b0 >= 32 || b0 == 28 {
+			var v float64
+			switch {
+			case b0 == 28:
+				v = float64(r.i16(at + 1))
+				at += 3
+			case b0 <= 246:
+				v = float64(b0 - 139)
+				at++
+			case b0 <= 250:
+				v = float64((b0-247)*256 + int(r.u8(at+1)) + 108)
+				at += 2
+			case b0 <= 254:
+				v = float64(-(b0-251)*256 - int(r.u8(at+1)) - 108)
+				at += 2
+			default:
+				v = float64(int32(r.u32(at+1))) / 65536
+				at += 5
+			}
+			if len(in.stack) >= cffMaxStack {
+				return errCharstring
+			}
+			in.stack = append(in.stack, v)
+			continue
+		}
+		at++
+		s := in.stack
+		switch b0 {
+		case 1, 3, 18, 23: // hstem, vstem, hstemhm, vstemhm
+			in.clearWidth(len(s)%2 == 1)
+			in.stems += len(in.stack) / 2
+		case 19, 20: // hintmask, cntrmask
+			in.clearWidth(len(s)%2 == 1)
+			in.stems += len(in.stack) / 2
+			at += (in.stems + 7) / 8
+		case 21: // rmoveto
+			in.clearWidth(len(s) > 2)
+			if len(in.stack) < 2 {
+				return errCharstring
+			}
+			in.path.moveTo(Point{in.stack[0], in.stack[1]})
+		case 22: // hmoveto
+			in.clearWidth(len(s) > 1)
+			if len(in.stack) < 1 {
+				return errCharstring
+			}
+			in.path.moveTo(Point{in.stack[0], 0})
+		case 4: // vmoveto
+			in.clearWidth(len(s) > 1)
+			if len(in.stack) < 1 {
+				return errCharstring
+			}
+			in.path.moveTo(Point{0, in.stack[0]})
+		case 5: // rlineto
+			for i := 0; i+1 < len(s); i += 2 {
+				in.path.lineTo(Point{s[i], s[i+1]})
+			}
+		case 6, 7: // hlineto, vlineto
+			horizontal := b0 == 6
+			for _, v := range s {
+				if horizontal {
+					in.path.lineTo(Point{v, 0})
+				} else {
+					in.path.lineTo(Point{0, v})
+				}
+				horizontal = !horizontal
+			}
+		case 8: // rrcurveto
+			for i := 0; i+5 < len(s); i += 6 {
+				in.path.curveTo(Point{s[i], s[i+1]}, Point{s[i+2], s[i+3]}, Point{s[i+4], s[i+5]})
+			}
+		case 24: // rcurveline
+			i := 0
+			for ; i+5 < len(s)-2; i += 6 {
+				in.path.curveTo(Point{s[i], s[i+1]}, Point{s[i+2], s[i+3]}, Point{s[i+4], s[i+5]})
+			}
+			if i+1 < len(s) {
+				in.path.lineTo(Point{s[i], s[i+1]})
+			}
+		case 25: // rlinecurve
+			i := 0
+			for ; i+1 < len(s)-6; i += 2 {
+				in.path.lineTo(Point{s[i], s[i+1]})
+			}
+			if i+5 < len(s) {
+				in.path.curveTo(Point{s[i], s[i+1]}, Point{s[i+2], s[i+3]}, Point{s[i+4], s[i+5]})
+			}
+		case 26: // vvcurveto
+			i, dx1 := 0, 0.0
+			if len(s)%4 == 1 {
+				dx1 = s[0]
+				i = 1
+			}
+			for ; i+3 < len(s); i += 4 {
+				in.path.curveTo(Point{dx1, s[i]}, Point{s[i+1], s[i+2]}, Point{0, s[i+3]})
+				dx1 = 0
+			}
+		case 27: // hhcurveto
+			i, dy1 := 0, 0.0
+			if len(s)%4 == 1 {
+				dy1 = s[0]
+				i = 1
+			}
+			for ; i+3 < len(s); i += 4 {
+				in.path.curveTo(Point{s[i], dy1}, Point{s[i+1], s[i+2]}, Point{s[i+3], 0})
+				dy1 = 0
+			}
+		case 30, 31: // vhcurveto, hvcurveto
+			horizontal := b0 == 31
+			for i := 0; i+3 < len(s); i += 4 {
+				last := 0.0
+				if len(s)-i == 5 {
+					last = s[i+4]
+				}
+				if horizontal {
+					in.path.curveTo(Point{s[i], 0}, Point{s[i+1], s[i+2]}, Point{last, s[i+3]})
+				} else {
+					in.path.curveTo(Point{0, s[i]}, Point{s[i+1], s[i+2]}, Point{s[i+3], last})
+				}
+				horizontal = !horizontal
+			}
+		case 10, 29: // callsubr, callgsubr
+			if len(s) == 0 {
+				return errCharstring
+			}
+			subrs := in.local
+			if b0 == 29 {
+				subrs = in.font.globalSubrs
+			}
+			index := int(s[len(s)-1]) + cffSubrBias(len(subrs))
+			in.stack = s[:len(s)-1]
+			if index < 0 || index >= len(subrs) {
+				return errCharstring
+			}
+			if err := in.run(subrs[index], depth+1); err != nil {
+				return err
+			}
+			continue
+		case 11: // return
+			return nil
+		case 14: // endchar
+			in.clearWidth(len(s) == 1 || len(s) == 5)
+			in.path.close()
+			in.finished = true
+		case 12:
+			op := int(r.u8(at))
+			at++
+			in.flex(op, s)
+		default:
+			return errCharstring
+		}
+		in.stack = in.stack[:0]
+	}
+	return nil
+}
+
+// flex draws the flex hints as the two curves they are made of
+func (in *cffInterpreter) flex(op int, s []float64) {
+	switch op {
+	case 35: // flex
+		if len(s) >= 12 {
+			in.path.curveTo(Point{s[0], s[1]}, Point{s[2], s[3]}, Point{s[4], s[5]})
+			in.path.curveTo(Point{s[6], s[7]}, Point{s[8], s[9]}, Point{s[10], s[11]})
+		}
+	case 34: // hflex
+		if len(s) >= 7 {
+			in.path.curveTo(Point{s[0], 0}, Point{s[1], s[2]}, Point{s[3], 0})
+			in.path.curveTo(Point{s[4], 0}, Point{s[5], -s[2]}, Point{s[6], 0})
+		}
+	case 36: // hflex1
+		if len(s) >= 9 {
+			in.path.curveTo(Point{s[0], s[1]}, Point{s[2], s[3]}, Point{s[4], 0})
+			in.path.curveTo(Point{s[5], 0}, Point{s[6], s[7]}, Point{s[8], -(s[1] + s[3] + s[7])})
+		}
+	case 37: // flex1
+		if len(s) >= 11 {
+			dx := s[0] + s[2] + s[4] + s[6] + s[8]
+			dy := s[1] + s[3] + s[5] + s[7] + s[9]
+			last := Point{s[10], -dy}
+			if abs(dx) <= abs(dy) {
+				last = Point{-dx, s[10]}
+			}
+			in.path.curveTo(Point{s[0], s[1]}, Point{s[2], s[3]}, Point{s[4], s[5]})
+			in.path.curveTo(Point{s[6], s[7]}, Point{s[8], s[9]}, last)
+		}
+	}
+}
+
+func abs(v float64) float64 {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
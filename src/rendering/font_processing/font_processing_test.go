@@ -0,0 +1,450 @@
+/******************************************************************************/
+/* font_processing_test.go                                                    */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package font_processing
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"slices"
+	"sort"
+	"testing"
+)
+
+func be(values ...any) []byte {
+	buf := bytes.Buffer{}
+	for _, v := range values {
+		binary.Write(&buf, binary.BigEndian, v)
+	}
+	return buf.Bytes()
+}
+
+// testSfnt builds a font file out of the tables
+func testSfnt(version uint32, tables map[string][]byte) []byte {
+	tags := make([]string, 0, len(tables))
+	for t := range tables {
+		tags = append(tags, t)
+	}
+	sort.Strings(tags)
+	head := be(version, uint16(len(tags)), uint16(0), uint16(0), uint16(0))
+	offset := len(head) + len(tags)*16
+	var dir, body []byte
+	for _, t := range tags {
+		data := tables[t]
+		dir = append(dir, be([4]byte([]byte(t)), uint32(0), uint32(offset+len(body)), uint32(len(data)))...)
+		body = append(body, data...)
+		for len(body)%4 != 0 {
+			body = append(body, 0)
+		}
+	}
+	return append(append(head, dir...), body...)
+}
+
+func testCommonTables(glyphs int, cmap map[rune]uint16, advances []uint16) map[string][]byte {
+	head := make([]byte, 54)
+	binary.BigEndian.PutUint16(head[18:], 1000)
+	binary.BigEndian.PutUint16(head[50:], 1) // long loca offsets
+	hhea := make([]byte, 36)
+	binary.BigEndian.PutUint16(hhea[4:], 800)
+	binary.BigEndian.PutUint16(hhea[6:], uint16(0xFFFF-200+1))
+	binary.BigEndian.PutUint16(hhea[8:], 100)
+	binary.BigEndian.PutUint16(hhea[34:], uint16(len(advances)))
+	var hmtx []byte
+	for _, a := range advances {
+		hmtx = append(hmtx, be(a, int16(0))...)
+	}
+	post := make([]byte, 32)
+	binary.BigEndian.PutUint16(post[8:], uint16(0xFFFF-100+1))
+	binary.BigEndian.PutUint16(post[10:], 50)
+	// A format 12 character map
+	runes := make([]rune, 0, len(cmap))
+	for r := range cmap {
+		runes = append(runes, r)
+	}
+	slices.Sort(runes)
+	sub := be(uint16(12), uint16(0), uint32(16+len(runes)*12), uint32(0), uint32(len(runes)))
+	for _, r := range runes {
+		sub = append(sub, be(uint32(r), uint32(r), uint32(cmap[r]))...)
+	}
+	cmapTable := append(be(uint16(0), uint16(1), uint16(3), uint16(10), uint32(12)), sub...)
+	return map[string][]byte{
+		"head": head,
+		"hhea": hhea,
+		"hmtx": hmtx,
+		"maxp": be(uint32(0x5000), uint16(glyphs)),
+		"cmap": cmapTable,
+		"post": post,
+	}
+}
+
+// testGlyf is a simple glyph with all points on the curve unless listed in
+// offCurve
+func testGlyf(points [][2]int16, offCurve map[int]bool) []byte {
+	data := be(int16(1), int16(0), int16(0), int16(0), int16(0), uint16(len(points)-1), uint16(0))
+	for i := range points {
+		flag := uint8(glyfOnCurve)
+		if offCurve[i] {
+			flag = 0
+		}
+		data = append(data, flag)
+	}
+	var x, y int16
+	for _, p := range points {
+		data = append(data, be(p[0]-x)...)
+		x = p[0]
+	}
+	for _, p := range points {
+		data = append(data, be(p[1]-y)...)
+		y = p[1]
+	}
+	return data
+}
+
+// testGlyfTables lays out the glyphs in a glyf table with long loca offsets
+func testGlyfTables(glyphs [][]byte) (glyf, loca []byte) {
+	loca = be(uint32(0))
+	for _, g := range glyphs {
+		glyf = append(glyf, g...)
+		for len(glyf)%4 != 0 {
+			glyf = append(glyf, 0)
+		}
+		loca = append(loca, be(uint32(len(glyf)))...)
+	}
+	return glyf, loca
+}
+
+func testTrueType() []byte {
+	square := testGlyf([][2]int16{{0, 0}, {0, 700}, {500, 700}, {500, 0}}, nil)
+	// A diamond made of quadratic curves with all of the points off the
+	// curve, the on curve points are implied half way between them
+	round := testGlyf([][2]int16{{0, 0}, {0, 600}, {600, 600}, {600, 0}},
+		map[int]bool{0: true, 1: true, 2: true, 3: true})
+	composite := be(int16(-1), int16(0), int16(0), int16(0), int16(0),
+		uint16(compositeArgsAreWords|compositeArgsAreXY), uint16(1), int16(100), int16(50))
+	glyphs := [][]byte{nil, square, round, composite}
+	tables := testCommonTables(len(glyphs),
+		map[rune]uint16{'A': 1, 'O': 2, 'B': 3, ' ': 0}, []uint16{250, 600, 650, 700})
+	tables["glyf"], tables["loca"] = testGlyfTables(glyphs)
+	tables["kern"] = be(uint16(0), uint16(1),
+		uint16(0), uint16(14+6), uint16(0x0001), uint16(1), uint16(0), uint16(0), uint16(0),
+		uint16(1), uint16(3), int16(-50))
+	return testSfnt(0x00010000, tables)
+}
+
+func cffIndex(items ...[]byte) []byte {
+	if len(items) == 0 {
+		return be(uint16(0))
+	}
+	out := be(uint16(len(items)), uint8(4))
+	offset := uint32(1)
+	out = append(out, be(offset)...)
+	for _, it := range items {
+		offset += uint32(len(it))
+		out = append(out, be(offset)...)
+	}
+	for _, it := range items {
+		out = append(out, it...)
+	}
+	return out
+}
+
+func cffInt(v int) []byte { return be(uint8(29), int32(v)) }
+
+func testOpenType() []byte {
+	// A 400 by 500 square drawn with a global subroutine and a curve
+	subr := []byte{248, 136, 7, 11} // 500 vlineto return
+	var square []byte
+	square = append(square, 239, 139, 21)                    // 100 0 rmoveto
+	square = append(square, 248, 36, 6)                      // 400 hlineto
+	square = append(square, 32, 29)                          // callgsubr 0
+	square = append(square, 252, 36, 6)                      // -400 hlineto
+	square = append(square, 139, 139, 139, 139, 139, 139, 8) // a zero rrcurveto
+	square = append(square, 14)                              // endchar
+	notdef := []byte{14}
+	charStrings := cffIndex(notdef, square)
+	private := []byte{}
+	build := func(csOffset, privateOffset int) []byte {
+		top := append(cffInt(csOffset), cffOpCharStrings)
+		top = append(top, append(append(cffInt(len(private)), cffInt(privateOffset)...), cffOpPrivate)...)
+		out := []byte{1, 0, 4, 4}
+		out = append(out, cffIndex([]byte("Test"))...)
+		out = append(out, cffIndex(top)...)
+		out = append(out, cffIndex()...)
+		out = append(out, cffIndex(subr)...)
+		return out
+	}
+	head := build(0, 0)
+	cff := build(len(head), len(head)+len(charStrings))
+	cff = append(cff, charStrings...)
+	cff = append(cff, private...)
+	tables := testCommonTables(2, map[rune]uint16{'A': 1}, []uint16{0, 600})
+	tables["CFF "] = cff
+	return testSfnt(0x4F54544F, tables)
+}
+
+func contourArea(c Contour) float64 {
+	area := 0.0
+	for _, s := range c {
+		pts := flatten(s)
+		for i := 1; i < len(pts); i++ {
+			area += pts[i-1].cross(pts[i])
+		}
+	}
+	return area / 2
+}
+
+func TestParseTrueType(t *testing.T) {
+	f, err := Parse(testTrueType())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f.UnitsPerEm != 1000 || f.Ascender != 800 || f.Descender != -200 || f.LineGap != 100 {
+		t.Errorf("unexpected metrics %d %d %d %d", f.UnitsPerEm, f.Ascender, f.Descender, f.LineGap)
+	}
+	if g, ok := f.GlyphIndex('O'); !ok || g != 2 || f.Advance(g) != 650 {
+		t.Errorf("unexpected glyph %d for O", g)
+	}
+	if f.HasRune('Z') {
+		t.Error("expected the font to not have Z")
+	}
+	square, err := f.Outline(1)
+	if err != nil || len(square) != 1 || len(square[0]) != 4 {
+		t.Fatalf("expected a square of 4 lines, got %v %v", square, err)
+	}
+	if a := contourArea(square[0]); math.Abs(a) != 350000 {
+		t.Errorf("expected the square area to be 350000, got %v", a)
+	}
+	round, _ := f.Outline(2)
+	if len(round) != 1 || len(round[0]) != 4 || round[0][0].Degree != 2 {
+		t.Fatalf("expected 4 quadratic curves, got %v", round)
+	}
+	if p := round[0][0].Start(); p != (Point{0, 300}) {
+		t.Errorf("expected the curve to start at the implied point, got %v", p)
+	}
+	composite, _ := f.Outline(3)
+	if len(composite) != 1 || composite[0][0].Start() != (Point{100, 50}) {
+		t.Errorf("expected the composite to offset the square, got %v", composite)
+	}
+	if k := f.Kerning(1, 3); k != -50 {
+		t.Errorf("expected the kerning of A B to be -50, got %d", k)
+	}
+}
+
+func TestParseOpenType(t *testing.T) {
+	f, err := Parse(testOpenType())
+	if err != nil {
+		t.Fatal(err)
+	}
+	contours, err := f.Outline(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(contours) != 1 {
+		t.Fatalf("expected 1 contour, got %d", len(contours))
+	}
+	if a := contourArea(contours[0]); math.Abs(math.Abs(a)-200000) > 1e-6 {
+		t.Errorf("expected the area to be 200000, got %v", a)
+	}
+	if p := contours[0][0].Start(); p != (Point{100, 0}) {
+		t.Errorf("expected the contour to start at the moveto, got %v", p)
+	}
+}
+
+func TestParseRejectsOtherData(t *testing.T) {
+	if _, err := Parse([]byte("not a font at all")); err != ErrNotFont {
+		t.Errorf("expected ErrNotFont, got %v", err)
+	}
+}
+
+func TestParseMalformedCmap(t *testing.T) {
+	tables := testCommonTables(2, map[rune]uint16{'A': 1}, []uint16{250, 600})
+	tables["glyf"], tables["loca"] = testGlyfTables([][]byte{nil, nil})
+	// A group count far larger than the subtable must not be walked
+	cmap := bytes.Clone(tables["cmap"])
+	binary.BigEndian.PutUint32(cmap[12+12:], 0xFFFFFFFF)
+	tables["cmap"] = cmap
+	if _, err := Parse(testSfnt(0x00010000, tables)); err != ErrMalformedFont {
+		t.Errorf("expected ErrMalformedFont for the group count, got %v", err)
+	}
+	// Overlapping groups would read the same code points again
+	sub := be(uint16(12), uint16(0), uint32(16+2*12), uint32(0), uint32(2),
+		uint32(0), uint32(unicodeMax), uint32(0), uint32(0), uint32(unicodeMax), uint32(0))
+	tables["cmap"] = append(be(uint16(0), uint16(1), uint16(3), uint16(10), uint32(12)), sub...)
+	if _, err := Parse(testSfnt(0x00010000, tables)); err != ErrMalformedFont {
+		t.Errorf("expected ErrMalformedFont for overlapping groups, got %v", err)
+	}
+}
+
+func TestParseSkipsMissingGlyphs(t *testing.T) {
+	tables := testCommonTables(2, map[rune]uint16{'A': 1, 'B': 2, 'C': 0xFFFF}, []uint16{250, 600})
+	tables["glyf"], tables["loca"] = testGlyfTables([][]byte{nil, nil})
+	f, err := Parse(testSfnt(0x00010000, tables))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !f.HasRune('A') {
+		t.Error("expected the font to have A")
+	}
+	if f.HasRune('B') || f.HasRune('C') {
+		t.Error("expected the runes mapped past the glyph count to be left out")
+	}
+}
+
+func TestParseCompositeFanOut(t *testing.T) {
+	// Every composite draws the glyph before it 4 times, within the depth
+	// limit this is 4^8 copies of the square
+	square := testGlyf([][2]int16{{0, 0}, {0, 700}, {500, 700}, {500, 0}}, nil)
+	glyphs := [][]byte{nil, square}
+	for g := 1; g <= maxCompositeDepth; g++ {
+		composite := be(int16(-1), int16(0), int16(0), int16(0), int16(0))
+		for i := range 4 {
+			flags := uint16(compositeArgsAreWords | compositeArgsAreXY)
+			if i < 3 {
+				flags |= compositeMoreComponents
+			}
+			composite = append(composite, be(flags, uint16(g), int16(i), int16(0))...)
+		}
+		glyphs = append(glyphs, composite)
+	}
+	advances := make([]uint16, len(glyphs))
+	tables := testCommonTables(len(glyphs), map[rune]uint16{'A': 1}, advances)
+	tables["glyf"], tables["loca"] = testGlyfTables(glyphs)
+	f, err := Parse(testSfnt(0x00010000, tables))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if contours, err := f.Outline(3); err != nil || len(contours) != 16 {
+		t.Errorf("expected 16 squares, got %d %v", len(contours), err)
+	}
+	if _, err := f.Outline(uint16(len(glyphs) - 1)); err != ErrMalformedFont {
+		t.Errorf("expected ErrMalformedFont for the fan out, got %v", err)
+	}
+}
+
+func FuzzParse(f *testing.F) {
+	f.Add(testTrueType())
+	f.Add(testOpenType())
+	f.Fuzz(func(t *testing.T, data []byte) {
+		font, err := Parse(data)
+		if err != nil {
+			return
+		}
+		for g := range min(font.GlyphCount(), 64) {
+			font.Outline(uint16(g))
+		}
+	})
+}
+
+func TestParseRanges(t *testing.T) {
+	runes, err := ParseRanges("0041-0043, U+20AC,,")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(runes) != "ABC€" {
+		t.Errorf("unexpected runes %q", string(runes))
+	}
+	if _, err := ParseRanges("0043-0041"); err == nil {
+		t.Error("expected a backwards range to fail")
+	}
+	if _, err := ParseRanges("zz"); err == nil {
+		t.Error("expected an invalid code point to fail")
+	}
+}
+
+func TestGenerateAtlas(t *testing.T) {
+	f, _ := Parse(testTrueType())
+	a, err := Generate(f, []rune("AOB Z"), DefaultOptions())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(a.Glyphs) != 4 {
+		t.Fatalf("expected the 4 glyphs the font has, got %d", len(a.Glyphs))
+	}
+	if a.Width != 128 || a.Height != 128 {
+		t.Errorf("expected a 128 atlas, got %dx%d", a.Width, a.Height)
+	}
+	if !approx(a.Metrics.LineHeight, 1.1) || !approx(a.Metrics.Descender, -0.2) {
+		t.Errorf("unexpected metrics %+v", a.Metrics)
+	}
+	if len(a.Kerning) != 1 || a.Kerning[0] != (Kerning{'A', 'B', -0.05}) {
+		t.Errorf("unexpected kerning %v", a.Kerning)
+	}
+	var square Glyph
+	for _, g := range a.Glyphs {
+		if g.Rune == 'A' {
+			square = g
+		}
+	}
+	// The plane bounds hold the glyph with half of the range around it
+	pad := float32(DefaultOptions().PxRange / 2 / DefaultOptions().Size)
+	if square.PlaneBounds[0] > -pad+0.01 || square.PlaneBounds[2] < 0.5+pad-0.01 ||
+		square.PlaneBounds[1] < 0.7+pad-0.01 || square.PlaneBounds[3] > -pad+0.01 {
+		t.Errorf("unexpected plane bounds %v", square.PlaneBounds)
+	}
+	// The center of the square is inside and the corner of the cell is not
+	ab := square.AtlasBounds
+	inside := testMedian(a, int((ab[0]+ab[2])/2), int((ab[1]+ab[3])/2))
+	outside := testMedian(a, int(ab[0]), int(ab[3]))
+	if inside <= 127 || outside >= 127 {
+		t.Errorf("expected the inside to be over half and the outside under, got %d and %d", inside, outside)
+	}
+}
+
+func TestEncodeBin(t *testing.T) {
+	a := &Atlas{
+		Width: 64, Height: 32,
+		Metrics: Metrics{EMSize: 1},
+		Glyphs:  []Glyph{{Rune: 'A', Advance: 0.5}},
+		Kerning: []Kerning{{'A', 'V', -0.1}},
+	}
+	bin := a.EncodeBin()
+	if len(bin) != 12+24+40+4+12 {
+		t.Fatalf("unexpected size %d", len(bin))
+	}
+	if binary.LittleEndian.Uint32(bin[36:]) != 'A' || binary.LittleEndian.Uint32(bin[80:]) != 'A' {
+		t.Error("unexpected glyph or kerning rune")
+	}
+}
+
+func testMedian(a *Atlas, x, y int) int {
+	row := a.Height - 1 - y
+	at := (row*a.Width + x) * 4
+	return int(median(float64(a.Pix[at]), float64(a.Pix[at+1]), float64(a.Pix[at+2])))
+}
+
+func approx(a, b float32) bool { return math.Abs(float64(a-b)) < 1e-5 }
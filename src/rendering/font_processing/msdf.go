@@ -0,0 +1,340 @@
+/******************************************************************************/
+/* msdf.go                                                                    */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package font_processing
+
+import "math"
+
+// edgeColor is the set of the red (1), green (2) and blue (4) channels an
+// edge contributes its distance to
+type edgeColor uint8
+
+const (
+	colorRed     = edgeColor(1)
+	colorGreen   = edgeColor(2)
+	colorBlue    = edgeColor(4)
+	colorYellow  = colorRed | colorGreen
+	colorMagenta = colorRed | colorBlue
+	colorCyan    = colorGreen | colorBlue
+	colorWhite   = colorRed | colorGreen | colorBlue
+)
+
+const (
+	// cornerCrossThreshold is the sine of the angle, about 8 degrees, that
+	// the direction has to change by between two edges to be a corner
+	cornerCrossThreshold = 0.14
+	curveSteps           = 16
+)
+
+// coloredEdge is an edge of the shape flattened into a polyline, curves
+// are flattened finely enough that the error is well under a texel
+type coloredEdge struct {
+	points   []Point
+	color    edgeColor
+	min, max Point
+}
+
+func newColoredEdge(s Segment) coloredEdge {
+	e := coloredEdge{points: flatten(s), color: colorWhite}
+	e.min, e.max = e.points[0], e.points[0]
+	for _, p := range e.points[1:] {
+		e.min = Point{math.Min(e.min.X, p.X), math.Min(e.min.Y, p.Y)}
+		e.max = Point{math.Max(e.max.X, p.X), math.Max(e.max.Y, p.Y)}
+	}
+	return e
+}
+
+// boundsDistance is the distance from the point to the bounds of the edge,
+// no part of the edge can be closer than this
+func (e *coloredEdge) boundsDistance(p Point) float64 {
+	dx := math.Max(0, math.Max(e.min.X-p.X, p.X-e.max.X))
+	dy := math.Max(0, math.Max(e.min.Y-p.Y, p.Y-e.max.Y))
+	return math.Hypot(dx, dy)
+}
+
+func flatten(s Segment) []Point {
+	if s.Degree == 1 {
+		return []Point{s.Points[0], s.Points[1]}
+	}
+	pts := make([]Point, curveSteps+1)
+	for i := range pts {
+		pts[i] = s.At(float64(i) / curveSteps)
+	}
+	return pts
+}
+
+// colorEdges gives each edge of the contours a color so that the two edges
+// meeting at a corner share only one channel, which keeps the corner sharp
+// when the median of the channels is taken. Smooth contours are white.
+func colorEdges(contours []Contour) [][]coloredEdge {
+	out := make([][]coloredEdge, 0, len(contours))
+	for _, c := range contours {
+		segs := make([]Segment, 0, len(c))
+		for _, s := range c {
+			if s.End().sub(s.Start()).length() > 0 || s.Degree > 1 {
+				segs = append(segs, s)
+			}
+		}
+		if len(segs) == 0 {
+			continue
+		}
+		var corners []int
+		for i, s := range segs {
+			prev := segs[(i+len(segs)-1)%len(segs)]
+			a := prev.direction(1).normal()
+			b := s.direction(0).normal()
+			if a.dot(b) <= 0 || math.Abs(a.cross(b)) > cornerCrossThreshold {
+				corners = append(corners, i)
+			}
+		}
+		edges := make([]coloredEdge, len(segs))
+		for i, s := range segs {
+			edges[i] = newColoredEdge(s)
+		}
+		switch len(corners) {
+		case 0:
+		case 1:
+			// A teardrop has a single corner, its edges are split into
+			// three groups so that both sides of the corner still differ
+			colors := [3]edgeColor{colorMagenta, colorWhite, colorYellow}
+			if len(edges) < 3 {
+				colors = [3]edgeColor{colorMagenta, colorYellow, colorYellow}
+			}
+			for k := range edges {
+				i := (corners[0] + k) % len(edges)
+				edges[i].color = colors[k*3/len(edges)]
+			}
+		default:
+			cycle := [3]edgeColor{colorCyan, colorMagenta, colorYellow}
+			current := 0
+			first := corners[0]
+			for k := range edges {
+				i := (first + k) % len(edges)
+				if k > 0 && isCorner(corners, i) {
+					current = (current + 1) % 3
+					// The last group meets the first at the starting corner
+					if isLastCorner(corners, i) && current == 0 {
+						current = 1
+					}
+				}
+				edges[i].color = cycle[current]
+			}
+		}
+		out = append(out, edges)
+	}
+	return out
+}
+
+func isCorner(corners []int, i int) bool {
+	for _, c := range corners {
+		if c == i {
+			return true
+		}
+	}
+	return false
+}
+
+func isLastCorner(corners []int, i int) bool {
+	return corners[len(corners)-1] == i
+}
+
+// shapeOrientation is 1 when the filled area of the shape is on the left
+// of its edges and -1 when it is on the right
+func shapeOrientation(shape [][]coloredEdge) float64 {
+	area := 0.0
+	for _, contour := range shape {
+		for _, e := range contour {
+			for i := 1; i < len(e.points); i++ {
+				area += e.points[i-1].cross(e.points[i])
+			}
+		}
+	}
+	if area < 0 {
+		return -1
+	}
+	return 1
+}
+
+// edgeDistance is the distance from a point to an edge, the pseudo distance
+// extends the first and last pieces of the edge past its ends so that the
+// channels of the edges meeting at a corner cross at the corner
+type edgeDistance struct {
+	distance float64
+	pseudo   float64
+}
+
+func (e *coloredEdge) distanceTo(p Point) edgeDistance {
+	best := edgeDistance{distance: math.Inf(1)}
+	last := len(e.points) - 2
+	for i := 0; i <= last; i++ {
+		a, b := e.points[i], e.points[i+1]
+		ab := b.sub(a)
+		ap := p.sub(a)
+		lenSq := ab.dot(ab)
+		if lenSq == 0 {
+			continue
+		}
+		t := ap.dot(ab) / lenSq
+		ct := math.Max(0, math.Min(1, t))
+		d := p.sub(a.add(ab.scale(ct))).length()
+		if d >= best.distance {
+			continue
+		}
+		side := 1.0
+		if ab.cross(ap) < 0 {
+			side = -1
+		}
+		best.distance = d
+		best.pseudo = side * d
+		if (i == 0 && t < 0) || (i == last && t > 1) {
+			if perp := math.Abs(ab.cross(ap)) / math.Sqrt(lenSq); perp < d {
+				best.pseudo = side * perp
+			}
+		}
+	}
+	return best
+}
+
+// winding returns the non zero winding number of the shape around the point
+func winding(shape [][]coloredEdge, p Point) int {
+	w := 0
+	for _, contour := range shape {
+		for _, e := range contour {
+			for i := 1; i < len(e.points); i++ {
+				a, b := e.points[i-1], e.points[i]
+				if a.Y <= p.Y {
+					if b.Y > p.Y && b.sub(a).cross(p.sub(a)) > 0 {
+						w++
+					}
+				} else if b.Y <= p.Y && b.sub(a).cross(p.sub(a)) < 0 {
+					w--
+				}
+			}
+		}
+	}
+	return w
+}
+
+// msdfGlyph is the placement of a glyph's distance field in font units
+type msdfGlyph struct {
+	contours []Contour
+	// scale is the number of texels per font unit
+	scale float64
+	// originX and originY are the texel coordinates of the font origin
+	// inside of the glyph's cell
+	originX, originY float64
+	width, height    int
+	pxRange          float64
+}
+
+// generate writes the RGBA distance field of the glyph with the rows going
+// from the bottom up. Distances are positive inside of the glyph and map a
+// range of pxRange texels across the edge to the 0 to 255 values.
+func (g msdfGlyph) generate() []byte {
+	pix := make([]byte, g.width*g.height*4)
+	shape := colorEdges(g.contours)
+	orientation := shapeOrientation(shape)
+	toByte := func(d float64) byte {
+		v := (d*g.scale/g.pxRange + 0.5) * 255
+		return byte(math.Max(0, math.Min(255, math.Round(v))))
+	}
+	channels := [3]edgeColor{colorRed, colorGreen, colorBlue}
+	for y := 0; y < g.height; y++ {
+		for x := 0; x < g.width; x++ {
+			p := Point{
+				(float64(x) + 0.5 - g.originX) / g.scale,
+				(float64(y) + 0.5 - g.originY) / g.scale,
+			}
+			var nearest [3]edgeDistance
+			for c := range nearest {
+				nearest[c].distance = math.Inf(1)
+			}
+			closest := math.Inf(1)
+			for _, contour := range shape {
+				for i := range contour {
+					e := &contour[i]
+					// Edges that can't be closer than what was found for
+					// every channel they add to are skipped
+					reach := closest
+					for c, ch := range channels {
+						if e.color&ch != 0 {
+							reach = math.Max(reach, nearest[c].distance)
+						}
+					}
+					if e.boundsDistance(p) >= reach {
+						continue
+					}
+					d := e.distanceTo(p)
+					closest = math.Min(closest, d.distance)
+					for c, ch := range channels {
+						if e.color&ch != 0 && d.distance < nearest[c].distance {
+							nearest[c] = d
+						}
+					}
+				}
+			}
+			var values [3]float64
+			for c := range values {
+				if math.IsInf(nearest[c].distance, 1) {
+					values[c] = -math.Inf(1)
+				} else {
+					values[c] = nearest[c].pseudo * orientation
+				}
+			}
+			// Channels that disagree with the real inside of the glyph would
+			// leave artifacts, those texels fall back to the true distance
+			inside := winding(shape, p) != 0
+			if (median(values[0], values[1], values[2]) > 0) != inside {
+				d := closest
+				if !inside {
+					d = -d
+				}
+				values = [3]float64{d, d, d}
+			}
+			at := (y*g.width + x) * 4
+			pix[at] = toByte(values[0])
+			pix[at+1] = toByte(values[1])
+			pix[at+2] = toByte(values[2])
+			pix[at+3] = 255
+		}
+	}
+	return pix
+}
+
+func median(a, b, c float64) float64 {
+	return math.Max(math.Min(a, b), math.Min(math.Max(a, b), c))
+}
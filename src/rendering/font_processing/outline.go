@@ -0,0 +1,115 @@
+/******************************************************************************/
+/* outline.go                                                                 */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package font_processing
+
+import "math"
+
+// Point is a position in font units with Y going up
+type Point struct {
+	X, Y float64
+}
+
+func (p Point) add(o Point) Point     { return Point{p.X + o.X, p.Y + o.Y} }
+func (p Point) sub(o Point) Point     { return Point{p.X - o.X, p.Y - o.Y} }
+func (p Point) scale(s float64) Point { return Point{p.X * s, p.Y * s} }
+func (p Point) dot(o Point) float64   { return p.X*o.X + p.Y*o.Y }
+func (p Point) cross(o Point) float64 { return p.X*o.Y - p.Y*o.X }
+func (p Point) length() float64       { return math.Hypot(p.X, p.Y) }
+func (p Point) lerp(o Point, t float64) Point {
+	return Point{p.X + (o.X-p.X)*t, p.Y + (o.Y-p.Y)*t}
+}
+
+func (p Point) normal() Point {
+	l := p.length()
+	if l == 0 {
+		return Point{}
+	}
+	return Point{p.X / l, p.Y / l}
+}
+
+// Segment is a line (degree 1), quadratic (degree 2), or cubic (degree 3)
+// curve, only the first Degree+1 points are used
+type Segment struct {
+	Points [4]Point
+	Degree int
+}
+
+// Contour is a closed loop of segments where each segment starts at the end
+// of the one before it
+type Contour []Segment
+
+// Start returns the first point of the segment
+func (s Segment) Start() Point { return s.Points[0] }
+
+// End returns the last point of the segment
+func (s Segment) End() Point { return s.Points[s.Degree] }
+
+// At returns the point at t, from 0 to 1, along the segment
+func (s Segment) At(t float64) Point {
+	p := s.Points
+	switch s.Degree {
+	case 2:
+		return p[0].lerp(p[1], t).lerp(p[1].lerp(p[2], t), t)
+	case 3:
+		a, b, c := p[0].lerp(p[1], t), p[1].lerp(p[2], t), p[2].lerp(p[3], t)
+		return a.lerp(b, t).lerp(b.lerp(c, t), t)
+	}
+	return p[0].lerp(p[1], t)
+}
+
+// direction returns the tangent of the segment at t
+func (s Segment) direction(t float64) Point {
+	p := s.Points
+	var d Point
+	switch s.Degree {
+	case 2:
+		d = p[1].sub(p[0]).lerp(p[2].sub(p[1]), t)
+		if d == (Point{}) {
+			d = p[2].sub(p[0])
+		}
+	case 3:
+		a, b, c := p[1].sub(p[0]), p[2].sub(p[1]), p[3].sub(p[2])
+		d = a.lerp(b, t).lerp(b.lerp(c, t), t)
+		if d == (Point{}) {
+			d = p[3].sub(p[0])
+		}
+	default:
+		d = p[1].sub(p[0])
+	}
+	return d
+}
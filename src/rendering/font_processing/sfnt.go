@@ -0,0 +1,800 @@
+/******************************************************************************/
+/* sfnt.go                                                                    */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package font_processing
+
+import (
+	"encoding/binary"
+	"errors"
+)
+
+var (
+	ErrNotFont          = errors.New("the data is not a TrueType or OpenType font")
+	ErrMissingTable     = errors.New("the font is missing a required table")
+	ErrMalformedFont    = errors.New("the font has a malformed table")
+	ErrUnsupportedCmap  = errors.New("the font has no unicode character map that is supported")
+	ErrGlyphOutOfBounds = errors.New("the glyph index is outside of the font")
+)
+
+const (
+	maxCompositeDepth = 8
+	// maxCompositeParts limits the glyphs a composite can draw in total, the
+	// depth alone still lets each level multiply the components below it
+	maxCompositeParts = 1024
+)
+
+// Font is a parsed TrueType (glyf outlines) or OpenType (CFF outlines) font,
+// all of the measurements are in font units
+type Font struct {
+	UnitsPerEm         int
+	Ascender           int
+	Descender          int
+	LineGap            int
+	UnderlinePosition  int
+	UnderlineThickness int
+	numGlyphs          int
+	runes              map[rune]uint16
+	advances           []uint16
+	glyf               []byte
+	loca               []uint32
+	cff                *cffFont
+	kerning            fontKerning
+}
+
+type sfntReader struct {
+	data []byte
+}
+
+func (r sfntReader) u8(at int) uint8 {
+	if at < 0 || at >= len(r.data) {
+		return 0
+	}
+	return r.data[at]
+}
+
+func (r sfntReader) u16(at int) uint16 {
+	if at < 0 || at+2 > len(r.data) {
+		return 0
+	}
+	return binary.BigEndian.Uint16(r.data[at:])
+}
+
+func (r sfntReader) i16(at int) int16 { return int16(r.u16(at)) }
+
+func (r sfntReader) u32(at int) uint32 {
+	if at < 0 || at+4 > len(r.data) {
+		return 0
+	}
+	return binary.BigEndian.Uint32(r.data[at:])
+}
+
+func (r sfntReader) slice(at, size int) ([]byte, bool) {
+	if at < 0 || size < 0 || at+size > len(r.data) {
+		return nil, false
+	}
+	return r.data[at : at+size], true
+}
+
+// Parse reads the tables of a TrueType or OpenType font that are needed to
+// build its distance field atlas, font collections are not supported
+func Parse(data []byte) (*Font, error) {
+	r := sfntReader{data}
+	switch r.u32(0) {
+	case 0x00010000, 0x74727565, 0x4F54544F: // 1.0, "true", "OTTO"
+	default:
+		return nil, ErrNotFont
+	}
+	tables := map[string][]byte{}
+	count := int(r.u16(4))
+	for i := range count {
+		rec := 12 + i*16
+		tag, ok := r.slice(rec, 4)
+		if !ok {
+			return nil, ErrMalformedFont
+		}
+		table, ok := r.slice(int(r.u32(rec+8)), int(r.u32(rec+12)))
+		if !ok {
+			return nil, ErrMalformedFont
+		}
+		tables[string(tag)] = table
+	}
+	for _, tag := range []string{"head", "hhea", "hmtx", "maxp", "cmap"} {
+		if _, ok := tables[tag]; !ok {
+			return nil, ErrMissingTable
+		}
+	}
+	f := &Font{}
+	head := sfntReader{tables["head"]}
+	f.UnitsPerEm = int(head.u16(18))
+	if f.UnitsPerEm == 0 {
+		return nil, ErrMalformedFont
+	}
+	f.numGlyphs = int(sfntReader{tables["maxp"]}.u16(4))
+	hhea := sfntReader{tables["hhea"]}
+	f.Ascender = int(hhea.i16(4))
+	f.Descender = int(hhea.i16(6))
+	f.LineGap = int(hhea.i16(8))
+	if post, ok := tables["post"]; ok {
+		p := sfntReader{post}
+		f.UnderlinePosition = int(p.i16(8))
+		f.UnderlineThickness = int(p.i16(10))
+	}
+	f.readAdvances(sfntReader{tables["hmtx"]}, int(hhea.u16(34)))
+	var err error
+	if f.runes, err = parseCmap(sfntReader{tables["cmap"]}, f.numGlyphs); err != nil {
+		return nil, err
+	}
+	if cff, ok := tables["CFF "]; ok {
+		if f.cff, err = parseCFF(cff); err != nil {
+			return nil, err
+		}
+	} else {
+		glyf, ok := tables["glyf"]
+		loca, ok2 := tables["loca"]
+		if !ok || !ok2 {
+			return nil, ErrMissingTable
+		}
+		f.glyf = glyf
+		if f.loca, err = parseLoca(sfntReader{loca}, int(head.i16(50)), f.numGlyphs); err != nil {
+			return nil, err
+		}
+	}
+	f.kerning = parseKern(sfntReader{tables["kern"]})
+	if gpos, ok := tables["GPOS"]; ok {
+		parseGPOSKerning(sfntReader{gpos}, &f.kerning)
+	}
+	return f, nil
+}
+
+func (f *Font) readAdvances(hmtx sfntReader, metricCount int) {
+	f.advances = make([]uint16, f.numGlyphs)
+	last := uint16(0)
+	for i := range f.advances {
+		if i < metricCount {
+			last = hmtx.u16(i * 4)
+		}
+		// Glyphs past the long metrics share the advance of the last one
+		f.advances[i] = last
+	}
+}
+
+func parseLoca(loca sfntReader, format, numGlyphs int) ([]uint32, error) {
+	offsets := make([]uint32, numGlyphs+1)
+	for i := range offsets {
+		if format == 0 {
+			offsets[i] = uint32(loca.u16(i*2)) * 2
+		} else {
+			offsets[i] = loca.u32(i * 4)
+		}
+	}
+	for i := 1; i < len(offsets); i++ {
+		if offsets[i] < offsets[i-1] {
+			return nil, ErrMalformedFont
+		}
+	}
+	return offsets, nil
+}
+
+// parseCmap reads the best unicode subtable of the character map, the full
+// repertoire (format 12) is preferred over the basic multilingual plane
+// (format 4), glyphs outside of the font are left out
+func parseCmap(cmap sfntReader, numGlyphs int) (map[rune]uint16, error) {
+	count := int(cmap.u16(2))
+	best, bestFormat := -1, 0
+	for i := range count {
+		rec := 4 + i*8
+		platform, encoding := cmap.u16(rec), cmap.u16(rec+2)
+		offset := int(cmap.u32(rec + 4))
+		unicode := platform == 0 || (platform == 3 && (encoding == 1 || encoding == 10))
+		if !unicode {
+			continue
+		}
+		format := int(cmap.u16(offset))
+		if (format == 12 && bestFormat != 12) || (format == 4 && bestFormat == 0) {
+			best, bestFormat = offset, format
+		}
+	}
+	if best < 0 {
+		return nil, ErrUnsupportedCmap
+	}
+	runes := map[rune]uint16{}
+	if bestFormat == 12 {
+		// The group count can't claim more groups than the subtable holds
+		length := int(cmap.u32(best + 4))
+		groups := int(cmap.u32(best + 12))
+		if length < 16 || best+length > len(cmap.data) || groups > (length-16)/12 {
+			return nil, ErrMalformedFont
+		}
+		next := uint32(0)
+		for i := range groups {
+			g := best + 16 + i*12
+			start, end, glyph := cmap.u32(g), cmap.u32(g+4), cmap.u32(g+8)
+			// Groups are sorted and don't overlap, so every code point is
+			// read at most once
+			if end < start || end > unicodeMax || (i > 0 && start < next) {
+				return nil, ErrMalformedFont
+			}
+			next = end + 1
+			if glyph >= uint32(numGlyphs) {
+				continue
+			}
+			end = min(end, start+uint32(numGlyphs)-1-glyph)
+			for c := start; c <= end; c++ {
+				runes[rune(c)] = uint16(glyph + c - start)
+			}
+		}
+		return runes, nil
+	}
+	segCount := int(cmap.u16(best+6)) / 2
+	ends := best + 14
+	starts := ends + segCount*2 + 2
+	deltas := starts + segCount*2
+	rangeOffsets := deltas + segCount*2
+	for i := range segCount {
+		start, end := int(cmap.u16(starts+i*2)), int(cmap.u16(ends+i*2))
+		delta := cmap.u16(deltas + i*2)
+		ro := int(cmap.u16(rangeOffsets + i*2))
+		for c := start; c <= end && c != 0xFFFF; c++ {
+			var glyph uint16
+			if ro == 0 {
+				glyph = uint16(c) + delta
+			} else {
+				at := rangeOffsets + i*2 + ro + (c-start)*2
+				if glyph = cmap.u16(at); glyph != 0 {
+					glyph += delta
+				}
+			}
+			if glyph != 0 && int(glyph) < numGlyphs {
+				runes[rune(c)] = glyph
+			}
+		}
+	}
+	return runes, nil
+}
+
+const unicodeMax = 0x10FFFF
+
+// GlyphCount returns the number of glyphs in the font
+func (f *Font) GlyphCount() int { return f.numGlyphs }
+
+// GlyphIndex returns the glyph that the font draws for the rune
+func (f *Font) GlyphIndex(r rune) (uint16, bool) {
+	g, ok := f.runes[r]
+	return g, ok
+}
+
+// HasRune returns true if the font has a glyph for the rune
+func (f *Font) HasRune(r rune) bool {
+	_, ok := f.runes[r]
+	return ok
+}
+
+// Advance returns how far the pen moves after drawing the glyph
+func (f *Font) Advance(glyph uint16) int {
+	if int(glyph) >= len(f.advances) {
+		return 0
+	}
+	return int(f.advances[glyph])
+}
+
+// Kerning returns the adjustment to the advance of the left glyph when it is
+// followed by the right glyph
+func (f *Font) Kerning(left, right uint16) int {
+	return f.kerning.lookup(left, right)
+}
+
+// Outline returns the contours of the glyph, the contours of TrueType fonts
+// only have lines and quadratic curves while OpenType CFF fonts only have
+// lines and cubic curves
+func (f *Font) Outline(glyph uint16) ([]Contour, error) {
+	if int(glyph) >= f.numGlyphs {
+		return nil, ErrGlyphOutOfBounds
+	}
+	if f.cff != nil {
+		return f.cff.outline(int(glyph))
+	}
+	parts := 0
+	return f.glyfOutline(glyph, 0, &parts)
+}
+
+func (f *Font) glyfOutline(glyph uint16, depth int, parts *int) ([]Contour, error) {
+	if depth > maxCompositeDepth {
+		return nil, ErrMalformedFont
+	}
+	start, end := f.loca[glyph], f.loca[glyph+1]
+	if start == end {
+		return nil, nil
+	}
+	if int(end) > len(f.glyf) {
+		return nil, ErrMalformedFont
+	}
+	g := sfntReader{f.glyf[start:end]}
+	contourCount := int(g.i16(0))
+	if contourCount < 0 {
+		return f.compositeOutline(g, depth, parts)
+	}
+	return simpleOutline(g, contourCount)
+}
+
+const (
+	glyfOnCurve  = 0x01
+	glyfXShort   = 0x02
+	glyfYShort   = 0x04
+	glyfRepeat   = 0x08
+	glyfXSameOrP = 0x10
+	glyfYSameOrP = 0x20
+)
+
+type glyfPoint struct {
+	x, y    float64
+	onCurve bool
+}
+
+func simpleOutline(g sfntReader, contourCount int) ([]Contour, error) {
+	ends := make([]int, contourCount)
+	for i := range ends {
+		ends[i] = int(g.u16(10 + i*2))
+	}
+	pointCount := 0
+	if contourCount > 0 {
+		pointCount = ends[contourCount-1] + 1
+	}
+	at := 10 + contourCount*2
+	at += 2 + int(g.u16(at))
+	flags := make([]uint8, 0, pointCount)
+	for len(flags) < pointCount {
+		if at >= len(g.data) {
+			return nil, ErrMalformedFont
+		}
+		flag := g.u8(at)
+		at++
+		flags = append(flags, flag)
+		if flag&glyfRepeat != 0 {
+			repeat := int(g.u8(at))
+			at++
+			for k := 0; k < repeat && len(flags) < pointCount; k++ {
+				flags = append(flags, flag)
+			}
+		}
+	}
+	points := make([]glyfPoint, pointCount)
+	v := 0
+	for i, flag := range flags {
+		switch {
+		case flag&glyfXShort != 0:
+			d := int(g.u8(at))
+			at++
+			if flag&glyfXSameOrP == 0 {
+				d = -d
+			}
+			v += d
+		case flag&glyfXSameOrP == 0:
+			v += int(g.i16(at))
+			at += 2
+		}
+		points[i].x = float64(v)
+		points[i].onCurve = flag&glyfOnCurve != 0
+	}
+	v = 0
+	for i, flag := range flags {
+		switch {
+		case flag&glyfYShort != 0:
+			d := int(g.u8(at))
+			at++
+			if flag&glyfYSameOrP == 0 {
+				d = -d
+			}
+			v += d
+		case flag&glyfYSameOrP == 0:
+			v += int(g.i16(at))
+			at += 2
+		}
+		points[i].y = float64(v)
+	}
+	if at > len(g.data) {
+		return nil, ErrMalformedFont
+	}
+	contours := make([]Contour, 0, contourCount)
+	first := 0
+	for _, last := range ends {
+		if last < first || last >= pointCount {
+			return nil, ErrMalformedFont
+		}
+		if c := quadraticContour(points[first : last+1]); len(c) > 0 {
+			contours = append(contours, c)
+		}
+		first = last + 1
+	}
+	return contours, nil
+}
+
+// quadraticContour turns the on and off curve points of a TrueType contour
+// into segments, two off curve points in a row have an implied on curve
+// point half way between them
+func quadraticContour(points []glyfPoint) Contour {
+	n := len(points)
+	if n < 2 {
+		return nil
+	}
+	mid := func(a, b glyfPoint) Point {
+		return Point{(a.x + b.x) / 2, (a.y + b.y) / 2}
+	}
+	// Start at an on curve point, or the implied one between the first two
+	// off curve points
+	startIndex := -1
+	for i, p := range points {
+		if p.onCurve {
+			startIndex = i
+			break
+		}
+	}
+	var start Point
+	if startIndex < 0 {
+		start = mid(points[0], points[1])
+		startIndex = 0
+	} else {
+		start = Point{points[startIndex].x, points[startIndex].y}
+	}
+	var c Contour
+	pen := start
+	var control *Point
+	for k := 1; k <= n; k++ {
+		p := points[(startIndex+k)%n]
+		pt := Point{p.x, p.y}
+		if k == n && !points[startIndex].onCurve {
+			// The loop ends back at the implied start point
+			p.onCurve = false
+		}
+		if p.onCurve {
+			if control != nil {
+				c = append(c, Segment{Degree: 2, Points: [4]Point{pen, *control, pt}})
+				control = nil
+			} else {
+				c = append(c, Segment{Degree: 1, Points: [4]Point{pen, pt}})
+			}
+			pen = pt
+			continue
+		}
+		if control != nil {
+			m := Point{(control.X + pt.X) / 2, (control.Y + pt.Y) / 2}
+			c = append(c, Segment{Degree: 2, Points: [4]Point{pen, *control, m}})
+			pen = m
+		}
+		ctl := pt
+		control = &ctl
+	}
+	if control != nil {
+		c = append(c, Segment{Degree: 2, Points: [4]Point{pen, *control, start}})
+	} else if pen != start {
+		c = append(c, Segment{Degree: 1, Points: [4]Point{pen, start}})
+	}
+	return c
+}
+
+const (
+	compositeArgsAreWords   = 0x0001
+	compositeArgsAreXY      = 0x0002
+	compositeHaveScale      = 0x0008
+	compositeMoreComponents = 0x0020
+	compositeHaveXYScale    = 0x0040
+	compositeHave2x2        = 0x0080
+)
+
+func (f *Font) compositeOutline(g sfntReader, depth int, parts *int) ([]Contour, error) {
+	var contours []Contour
+	at := 10
+	for {
+		flags := g.u16(at)
+		glyph := g.u16(at + 2)
+		at += 4
+		var dx, dy float64
+		if flags&compositeArgsAreWords != 0 {
+			dx, dy = float64(g.i16(at)), float64(g.i16(at+2))
+			at += 4
+		} else {
+			dx, dy = float64(int8(g.u8(at))), float64(int8(g.u8(at+1)))
+			at += 2
+		}
+		if flags&compositeArgsAreXY == 0 {
+			// Aligning matching points is rare in fonts and is not supported,
+			// the component is placed without an offset
+			dx, dy = 0, 0
+		}
+		a, b, c, d := 1.0, 0.0, 0.0, 1.0
+		f2dot14 := func(at int) float64 { return float64(g.i16(at)) / 16384 }
+		switch {
+		case flags&compositeHaveScale != 0:
+			a = f2dot14(at)
+			d = a
+			at += 2
+		case flags&compositeHaveXYScale != 0:
+			a, d = f2dot14(at), f2dot14(at+2)
+			at += 4
+		case flags&compositeHave2x2 != 0:
+			a, b, c, d = f2dot14(at), f2dot14(at+2), f2dot14(at+4), f2dot14(at+6)
+			at += 8
+		}
+		if int(glyph) >= f.numGlyphs {
+			return nil, ErrMalformedFont
+		}
+		if *parts++; *parts > maxCompositeParts {
+			return nil, ErrMalformedFont
+		}
+		components, err := f.glyfOutline(glyph, depth+1, parts)
+		if err != nil {
+			return nil, err
+		}
+		for _, part := range components {
+			for i := range part {
+				for k := 0; k <= part[i].Degree; k++ {
+					p := part[i].Points[k]
+					part[i].Points[k] = Point{a*p.X + c*p.Y + dx, b*p.X + d*p.Y + dy}
+				}
+			}
+			contours = append(contours, part)
+		}
+		if flags&compositeMoreComponents == 0 || at >= len(g.data) {
+			break
+		}
+	}
+	return contours, nil
+}
+
+// fontKerning holds the kerning pairs of the font's kern table and the pair
+// adjustments of its GPOS table
+type fontKerning struct {
+	pairs   map[uint32]int16
+	lefts   map[uint16]bool
+	classes []gposClassKerning
+}
+
+type gposClassKerning struct {
+	coverage    map[uint16]bool
+	class1      map[uint16]uint16
+	class2      map[uint16]uint16
+	class2Count int
+	values      []int16
+}
+
+func kerningKey(left, right uint16) uint32 { return uint32(left)<<16 | uint32(right) }
+
+func (k *fontKerning) lookup(left, right uint16) int {
+	if v, ok := k.pairs[kerningKey(left, right)]; ok {
+		return int(v)
+	}
+	for _, c := range k.classes {
+		if !c.coverage[left] {
+			continue
+		}
+		c1, c2 := int(c.class1[left]), int(c.class2[right])
+		if i := c1*c.class2Count + c2; i < len(c.values) {
+			return int(c.values[i])
+		}
+	}
+	return 0
+}
+
+// hasLeft returns true if the glyph starts any kerning pair
+func (k *fontKerning) hasLeft(left uint16) bool {
+	if k.lefts[left] {
+		return true
+	}
+	for _, c := range k.classes {
+		if c.coverage[left] {
+			return true
+		}
+	}
+	return false
+}
+
+func parseKern(kern sfntReader) fontKerning {
+	k := fontKerning{pairs: map[uint32]int16{}, lefts: map[uint16]bool{}}
+	if len(kern.data) < 4 || kern.u16(0) != 0 {
+		return k
+	}
+	tables := int(kern.u16(2))
+	at := 4
+	for range tables {
+		length := int(kern.u16(at + 2))
+		coverage := kern.u16(at + 4)
+		// Only the horizontal format 0 subtables hold plain pairs
+		if coverage>>8 == 0 && coverage&0x1 != 0 {
+			count := int(kern.u16(at + 6))
+			for i := range count {
+				rec := at + 14 + i*6
+				left := kern.u16(rec)
+				key := kerningKey(left, kern.u16(rec+2))
+				if _, ok := k.pairs[key]; !ok {
+					k.pairs[key] = kern.i16(rec + 4)
+					k.lefts[left] = true
+				}
+			}
+		}
+		if length <= 0 {
+			break
+		}
+		at += length
+	}
+	return k
+}
+
+// parseGPOSKerning reads the horizontal advance of the first glyph from the
+// pair adjustment lookups, the pairs of the kern table win over them
+func parseGPOSKerning(gpos sfntReader, k *fontKerning) {
+	lookupList := int(gpos.u16(8))
+	if lookupList == 0 {
+		return
+	}
+	count := int(gpos.u16(lookupList))
+	for i := range count {
+		lookup := lookupList + int(gpos.u16(lookupList+2+i*2))
+		lookupType := gpos.u16(lookup)
+		subCount := int(gpos.u16(lookup + 4))
+		for s := range subCount {
+			sub := lookup + int(gpos.u16(lookup+6+s*2))
+			kind := lookupType
+			if kind == 9 {
+				// Extension lookups point to the real subtable
+				kind = gpos.u16(sub + 2)
+				sub += int(gpos.u32(sub + 4))
+			}
+			if kind == 2 {
+				parsePairPos(gpos, sub, k)
+			}
+		}
+	}
+}
+
+func valueRecordSize(format uint16) int {
+	size := 0
+	for bit := uint16(1); bit <= 0x80; bit <<= 1 {
+		if format&bit != 0 {
+			size += 2
+		}
+	}
+	return size
+}
+
+// xAdvanceOffset is where the X advance is in a value record, or -1
+func xAdvanceOffset(format uint16) int {
+	if format&0x4 == 0 {
+		return -1
+	}
+	offset := 0
+	for bit := uint16(1); bit < 0x4; bit <<= 1 {
+		if format&bit != 0 {
+			offset += 2
+		}
+	}
+	return offset
+}
+
+func parsePairPos(gpos sfntReader, sub int, k *fontKerning) {
+	format := gpos.u16(sub)
+	coverage := parseCoverage(gpos, sub+int(gpos.u16(sub+2)))
+	vf1, vf2 := gpos.u16(sub+4), gpos.u16(sub+6)
+	xa := xAdvanceOffset(vf1)
+	if xa < 0 {
+		return
+	}
+	recordSize := valueRecordSize(vf1) + valueRecordSize(vf2)
+	switch format {
+	case 1:
+		setCount := int(gpos.u16(sub + 8))
+		for first, index := range coverage.ordered {
+			if index >= setCount {
+				continue
+			}
+			set := sub + int(gpos.u16(sub+10+index*2))
+			pairs := int(gpos.u16(set))
+			for p := range pairs {
+				rec := set + 2 + p*(2+recordSize)
+				key := kerningKey(first, gpos.u16(rec))
+				if _, ok := k.pairs[key]; !ok {
+					if v := gpos.i16(rec + 2 + xa); v != 0 {
+						k.pairs[key] = v
+						k.lefts[first] = true
+					}
+				}
+			}
+		}
+	case 2:
+		c := gposClassKerning{
+			coverage:    map[uint16]bool{},
+			class1:      parseClassDef(gpos, sub+int(gpos.u16(sub+8))),
+			class2:      parseClassDef(gpos, sub+int(gpos.u16(sub+10))),
+			class2Count: int(gpos.u16(sub + 14)),
+		}
+		class1Count := int(gpos.u16(sub + 12))
+		for g := range coverage.ordered {
+			c.coverage[g] = true
+		}
+		c.values = make([]int16, class1Count*c.class2Count)
+		for i := range c.values {
+			c.values[i] = gpos.i16(sub + 16 + i*recordSize + xa)
+		}
+		k.classes = append(k.classes, c)
+	}
+}
+
+type coverageTable struct {
+	// ordered maps each covered glyph to its coverage index
+	ordered map[uint16]int
+}
+
+func parseCoverage(r sfntReader, at int) coverageTable {
+	c := coverageTable{ordered: map[uint16]int{}}
+	switch r.u16(at) {
+	case 1:
+		count := int(r.u16(at + 2))
+		for i := range count {
+			c.ordered[r.u16(at+4+i*2)] = i
+		}
+	case 2:
+		count := int(r.u16(at + 2))
+		for i := range count {
+			rec := at + 4 + i*6
+			start, end, index := int(r.u16(rec)), int(r.u16(rec+2)), int(r.u16(rec+4))
+			for g := start; g <= end; g++ {
+				c.ordered[uint16(g)] = index + g - start
+			}
+		}
+	}
+	return c
+}
+
+func parseClassDef(r sfntReader, at int) map[uint16]uint16 {
+	classes := map[uint16]uint16{}
+	switch r.u16(at) {
+	case 1:
+		start := int(r.u16(at + 2))
+		count := int(r.u16(at + 4))
+		for i := range count {
+			classes[uint16(start+i)] = r.u16(at + 6 + i*2)
+		}
+	case 2:
+		count := int(r.u16(at + 2))
+		for i := range count {
+			rec := at + 4 + i*6
+			start, end, class := int(r.u16(rec)), int(r.u16(rec+2)), r.u16(rec+4)
+			for g := start; g <= end; g++ {
+				classes[uint16(g)] = class
+			}
+		}
+	}
+	return classes
+}
@@ -0,0 +1,265 @@
+/******************************************************************************/
+/* font_shaping.go                                                            */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package rendering
+
+import "unicode"
+
+// The MSDF atlases only hold glyphs for code points, so shaping swaps runes
+// for the presentation form code points that have the shaped glyph. A form
+// is only used when the font has a glyph for it, otherwise the original rune
+// is kept.
+
+type arabicJoining uint8
+
+const (
+	arabicJoinNone = arabicJoining(iota)
+	arabicJoinRight
+	arabicJoinDual
+	// arabicJoinCausing joins on both sides but has no forms of its own
+	arabicJoinCausing
+)
+
+type arabicLetter struct {
+	// isolated is the isolated presentation form, the final, initial and
+	// medial forms follow it in that order
+	isolated rune
+	joining  arabicJoining
+}
+
+var arabicLetters = map[rune]arabicLetter{
+	0x0621: {0xFE80, arabicJoinNone},  // hamza
+	0x0622: {0xFE81, arabicJoinRight}, // alef with madda above
+	0x0623: {0xFE83, arabicJoinRight}, // alef with hamza above
+	0x0624: {0xFE85, arabicJoinRight}, // waw with hamza above
+	0x0625: {0xFE87, arabicJoinRight}, // alef with hamza below
+	0x0626: {0xFE89, arabicJoinDual},  // yeh with hamza above
+	0x0627: {0xFE8D, arabicJoinRight}, // alef
+	0x0628: {0xFE8F, arabicJoinDual},  // beh
+	0x0629: {0xFE93, arabicJoinRight}, // teh marbuta
+	0x062A: {0xFE95, arabicJoinDual},  // teh
+	0x062B: {0xFE99, arabicJoinDual},  // theh
+	0x062C: {0xFE9D, arabicJoinDual},  // jeem
+	0x062D: {0xFEA1, arabicJoinDual},  // hah
+	0x062E: {0xFEA5, arabicJoinDual},  // khah
+	0x062F: {0xFEA9, arabicJoinRight}, // dal
+	0x0630: {0xFEAB, arabicJoinRight}, // thal
+	0x0631: {0xFEAD, arabicJoinRight}, // reh
+	0x0632: {0xFEAF, arabicJoinRight}, // zain
+	0x0633: {0xFEB1, arabicJoinDual},  // seen
+	0x0634: {0xFEB5, arabicJoinDual},  // sheen
+	0x0635: {0xFEB9, arabicJoinDual},  // sad
+	0x0636: {0xFEBD, arabicJoinDual},  // dad
+	0x0637: {0xFEC1, arabicJoinDual},  // tah
+	0x0638: {0xFEC5, arabicJoinDual},  // zah
+	0x0639: {0xFEC9, arabicJoinDual},  // ain
+	0x063A: {0xFECD, arabicJoinDual},  // ghain
+	0x0640: {0x0640, arabicJoinCausing},
+	0x0641: {0xFED1, arabicJoinDual},  // feh
+	0x0642: {0xFED5, arabicJoinDual},  // qaf
+	0x0643: {0xFED9, arabicJoinDual},  // kaf
+	0x0644: {0xFEDD, arabicJoinDual},  // lam
+	0x0645: {0xFEE1, arabicJoinDual},  // meem
+	0x0646: {0xFEE5, arabicJoinDual},  // noon
+	0x0647: {0xFEE9, arabicJoinDual},  // heh
+	0x0648: {0xFEED, arabicJoinRight}, // waw
+	0x0649: {0xFEEF, arabicJoinRight}, // alef maksura
+	0x064A: {0xFEF1, arabicJoinDual},  // yeh
+}
+
+const arabicLam = 0x0644
+
+// arabicLamAlef is the isolated lam alef ligature for each alef, the final
+// form follows it
+var arabicLamAlef = map[rune]rune{
+	0x0622: 0xFEF5,
+	0x0623: 0xFEF7,
+	0x0625: 0xFEF9,
+	0x0627: 0xFEFB,
+}
+
+type arabicForm = rune
+
+const (
+	arabicFormIsolated = arabicForm(iota)
+	arabicFormFinal
+	arabicFormInitial
+	arabicFormMedial
+)
+
+// latinLigatures are tried in order, so the longer ligatures come first
+var latinLigatures = []struct {
+	runes    []rune
+	ligature rune
+}{
+	{[]rune("ffi"), 0xFB03},
+	{[]rune("ffl"), 0xFB04},
+	{[]rune("ff"), 0xFB00},
+	{[]rune("fi"), 0xFB01},
+	{[]rune("fl"), 0xFB02},
+}
+
+// shapeRunes returns the runes with the Arabic letters swapped for their
+// joining forms and the ligatures that the font has glyphs for. The runes
+// stay in logical order, has reports if the font has a glyph for a rune.
+func shapeRunes(runes []rune, has func(rune) bool) []rune {
+	out := make([]rune, 0, len(runes))
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		if letter, ok := arabicLetters[r]; ok {
+			joinsPrev := arabicJoinsPrev(runes, i)
+			if r == arabicLam {
+				if next, ok := arabicNextLetter(runes, i); ok && next == i+1 {
+					if lig, ok := arabicLamAlef[runes[next]]; ok {
+						if joinsPrev {
+							lig++
+						}
+						if has(lig) {
+							out = append(out, lig)
+							i = next
+							continue
+						}
+					}
+				}
+			}
+			out = append(out, arabicShape(r, letter, joinsPrev, arabicJoinsNext(runes, i), has))
+			continue
+		}
+		if lig, n := latinLigature(runes[i:], has); n > 0 {
+			out = append(out, lig)
+			i += n - 1
+			continue
+		}
+		out = append(out, r)
+	}
+	return out
+}
+
+func arabicShape(r rune, letter arabicLetter, joinsPrev, joinsNext bool, has func(rune) bool) rune {
+	if letter.joining == arabicJoinCausing || letter.joining == arabicJoinNone {
+		return r
+	}
+	form := arabicFormIsolated
+	if letter.joining == arabicJoinDual {
+		switch {
+		case joinsPrev && joinsNext:
+			form = arabicFormMedial
+		case joinsNext:
+			form = arabicFormInitial
+		case joinsPrev:
+			form = arabicFormFinal
+		}
+	} else if joinsPrev {
+		form = arabicFormFinal
+	}
+	// Fonts without the presentation forms draw the base letter unjoined
+	if shaped := letter.isolated + form; has(shaped) {
+		return shaped
+	}
+	return r
+}
+
+func arabicIsTransparent(r rune) bool {
+	return unicode.Is(unicode.Mn, r)
+}
+
+func arabicPrevLetter(runes []rune, i int) (int, bool) {
+	for j := i - 1; j >= 0; j-- {
+		if !arabicIsTransparent(runes[j]) {
+			return j, true
+		}
+	}
+	return 0, false
+}
+
+func arabicNextLetter(runes []rune, i int) (int, bool) {
+	for j := i + 1; j < len(runes); j++ {
+		if !arabicIsTransparent(runes[j]) {
+			return j, true
+		}
+	}
+	return 0, false
+}
+
+// arabicJoinsPrev reports if the letter at i connects to the letter before
+// it, which is on its right side
+func arabicJoinsPrev(runes []rune, i int) bool {
+	cur := arabicLetters[runes[i]]
+	if cur.joining == arabicJoinNone {
+		return false
+	}
+	p, ok := arabicPrevLetter(runes, i)
+	if !ok {
+		return false
+	}
+	prev, ok := arabicLetters[runes[p]]
+	return ok && (prev.joining == arabicJoinDual || prev.joining == arabicJoinCausing)
+}
+
+// arabicJoinsNext reports if the letter at i connects to the letter after
+// it, which is on its left side
+func arabicJoinsNext(runes []rune, i int) bool {
+	cur := arabicLetters[runes[i]]
+	if cur.joining != arabicJoinDual && cur.joining != arabicJoinCausing {
+		return false
+	}
+	n, ok := arabicNextLetter(runes, i)
+	if !ok {
+		return false
+	}
+	next, ok := arabicLetters[runes[n]]
+	return ok && next.joining != arabicJoinNone
+}
+
+func latinLigature(runes []rune, has func(rune) bool) (rune, int) {
+	for _, l := range latinLigatures {
+		if len(runes) < len(l.runes) {
+			continue
+		}
+		match := true
+		for k, r := range l.runes {
+			if runes[k] != r {
+				match = false
+				break
+			}
+		}
+		if match && has(l.ligature) {
+			return l.ligature, len(l.runes)
+		}
+	}
+	return 0, 0
+}
@@ -0,0 +1,133 @@
+/******************************************************************************/
+/* font_shaping_test.go                                                       */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package rendering
+
+import "testing"
+
+func testHasAll(rune) bool { return true }
+
+func testHasNone(rune) bool { return false }
+
+func TestShapeArabicJoining(t *testing.T) {
+	// beh beh beh is initial, medial and final
+	got := shapeRunes([]rune{0x0628, 0x0628, 0x0628}, testHasAll)
+	want := []rune{0xFE91, 0xFE92, 0xFE90}
+	if string(got) != string(want) {
+		t.Errorf("expected %U, got %U", want, got)
+	}
+	// A single letter is isolated
+	if got := shapeRunes([]rune{0x0628}, testHasAll); got[0] != 0xFE8F {
+		t.Errorf("expected the isolated beh, got %U", got)
+	}
+}
+
+func TestShapeArabicRightJoining(t *testing.T) {
+	// beh dal beh, dal doesn't join the letter after it
+	got := shapeRunes([]rune{0x0628, 0x062F, 0x0628}, testHasAll)
+	want := []rune{0xFE91, 0xFEAA, 0xFE8F}
+	if string(got) != string(want) {
+		t.Errorf("expected %U, got %U", want, got)
+	}
+}
+
+func TestShapeArabicSkipsMarks(t *testing.T) {
+	// The fatha between the letters doesn't break the join
+	got := shapeRunes([]rune{0x0628, 0x064E, 0x0628}, testHasAll)
+	want := []rune{0xFE91, 0x064E, 0xFE90}
+	if string(got) != string(want) {
+		t.Errorf("expected %U, got %U", want, got)
+	}
+}
+
+func TestShapeLamAlef(t *testing.T) {
+	if got := shapeRunes([]rune{0x0644, 0x0627}, testHasAll); string(got) != string([]rune{0xFEFB}) {
+		t.Errorf("expected the isolated lam alef, got %U", got)
+	}
+	got := shapeRunes([]rune{0x0628, 0x0644, 0x0627}, testHasAll)
+	want := []rune{0xFE91, 0xFEFC}
+	if string(got) != string(want) {
+		t.Errorf("expected %U, got %U", want, got)
+	}
+}
+
+func TestShapeKeepsMissingForms(t *testing.T) {
+	in := []rune{0x0628, 0x0644, 0x0627, 'f', 'i'}
+	if got := shapeRunes(in, testHasNone); string(got) != string(in) {
+		t.Errorf("expected the runes to be unchanged, got %U", got)
+	}
+}
+
+func TestShapeLatinLigatures(t *testing.T) {
+	has := func(r rune) bool { return r == 0xFB01 || r == 0xFB03 }
+	got := shapeRunes([]rune("office fly"), has)
+	want := []rune{'o', 0xFB03, 'c', 'e', ' ', 'f', 'l', 'y'}
+	if string(got) != string(want) {
+		t.Errorf("expected %q, got %q", string(want), string(got))
+	}
+}
+
+func TestFontCacheFallbackAndKerning(t *testing.T) {
+	primary := fontBin{
+		texture: &Texture{Key: "primary"},
+		letters: map[rune]fontBinChar{
+			'A':              {letter: 'A', advance: 0.6},
+			'V':              {letter: 'V', advance: 0.6},
+			invalidRuneProxy: {letter: invalidRuneProxy, advance: 0.5},
+		},
+		kerning: map[fontKerningPair]float32{{'A', 'V'}: -0.1},
+	}
+	cjk := fontBin{
+		texture: &Texture{Key: "cjk"},
+		letters: map[rune]fontBinChar{'日': {letter: '日', advance: 1}},
+	}
+	cache := FontCache{fontFaces: map[string]fontBin{"primary": primary, "cjk": cjk}}
+	if got := cache.advance(primary, 'A', 'V'); got != 0.5 {
+		t.Errorf("expected the kerned advance 0.5, got %v", got)
+	}
+	if ch, _ := cache.findGlyph(primary, '日'); ch.letter != invalidRuneProxy {
+		t.Error("expected the proxy glyph without fallback faces")
+	}
+	cache.SetFallbackFaces("cjk")
+	ch, from := cache.findGlyph(primary, '日')
+	if ch.letter != '日' || from.texture != cjk.texture {
+		t.Error("expected the glyph to come from the fallback face")
+	}
+	if got := cache.advance(primary, 'A', '日'); got != 1 {
+		t.Errorf("expected no kerning across faces, got %v", got)
+	}
+}
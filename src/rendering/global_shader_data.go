@@ -37,7 +37,10 @@
 
 package rendering
 
-import "kaiju/matrix"
+import (
+	"kaiju/engine/cameras"
+	"kaiju/matrix"
+)
 
 const (
 	MaxJoints        = 50
@@ -54,6 +57,37 @@ type GlobalShaderData struct {
 	_                matrix.Float
 	ScreenSize       matrix.Vec2
 	Time             float32
+	LightCount       uint32
+	AmbientLight     matrix.Vec4
+	Lights           [MaxLights]ShaderLight
+	LightTiles       [LightTileCount / 4][4]uint32
+	ShadowMatrices   [MaxShadowViews]matrix.Mat4
+	// ShadowCascadeSplits is the view distance that each cascade ends at
+	ShadowCascadeSplits matrix.Vec4
+	// ShadowParams x = cascade count, y = normal bias in texels
+	ShadowParams matrix.Vec4
+}
+
+func newGlobalShaderData(camera, uiCamera cameras.Camera, lights *LightList,
+	screenWidth, screenHeight matrix.Float, runtime float32) GlobalShaderData {
+	camOrtho := matrix.Float(0)
+	if camera.IsOrthographic() {
+		camOrtho = 1
+	}
+	data := GlobalShaderData{
+		View:             camera.View(),
+		UIView:           uiCamera.View(),
+		Projection:       camera.Projection(),
+		UIProjection:     uiCamera.Projection(),
+		CameraPosition:   camera.Position().AsVec4WithW(camOrtho),
+		UICameraPosition: uiCamera.Position(),
+		Time:             runtime,
+		ScreenSize:       matrix.Vec2{screenWidth, screenHeight},
+	}
+	if lights != nil {
+		lights.writeShaderData(&data, camera)
+	}
+	return data
 }
 
 type SkinnedShaderData struct {
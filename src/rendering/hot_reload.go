@@ -0,0 +1,288 @@
+/******************************************************************************/
+/* hot_reload.go                                                              */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package rendering
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"kaiju/engine/assets"
+	"kaiju/generators/spirv/compiler"
+	"kaiju/platform/filesystem"
+	"kaiju/platform/profiler/tracing"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+const hotReloadShaderFolder = "renderer/shaders"
+
+var errHotReloadLayout = errors.New("the shader layout changed, a restart is required to use the new layout")
+
+type shaderSource struct {
+	path  string
+	flags string
+}
+
+// HotReload loads the shader, shader pipeline, material, render pass or GLSL
+// source asset with the given key again and swaps the result into what is
+// already loaded. GLSL sources are compiled to SPIR-V first, along with the
+// sources that include them. Materials and shaders keep their identity, so
+// drawings pick up the changes on the next frame without being recreated.
+// When an error is returned, what was loaded before is kept.
+func HotReload(renderer Renderer, caches RenderCaches, key string) error {
+	defer tracing.NewRegion("rendering.HotReload").End()
+	key = hotReloadKey(key)
+	if !CanHotReload(key) {
+		return nil
+	}
+	switch path.Ext(key) {
+	case ".shader":
+		return hotReloadShader(caches, key)
+	case ".shaderpipeline":
+		return hotReloadMaterials(caches, func(d *MaterialData) bool {
+			return hotReloadKey(d.ShaderPipeline) == key
+		})
+	case ".material":
+		return hotReloadMaterial(caches, key)
+	case ".renderpass":
+		return hotReloadRenderPass(renderer, caches, key)
+	default:
+		return hotReloadGLSL(caches, key)
+	}
+}
+
+// CanHotReload returns true if the asset with the given key is one of the
+// kinds of assets that #HotReload supports
+func CanHotReload(key string) bool {
+	switch path.Ext(key) {
+	case ".shader", ".shaderpipeline", ".material", ".renderpass",
+		".vert", ".frag", ".geom", ".tesc", ".tese", ".inl", ".glsl":
+		return true
+	}
+	return false
+}
+
+// hotReloadKey makes asset keys comparable, as the keys within asset files
+// can start with the content folder or not
+func hotReloadKey(key string) string {
+	return strings.TrimPrefix(path.Clean(filepath.ToSlash(key)), "content/")
+}
+
+func (d *ShaderData) sources() []shaderSource {
+	all := []shaderSource{
+		{d.Vertex, d.VertexFlags},
+		{d.Fragment, d.FragmentFlags},
+		{d.Geometry, d.GeometryFlags},
+		{d.TessellationControl, d.TessellationControlFlags},
+		{d.TessellationEvaluation, d.TessellationEvaluationFlags},
+	}
+	sources := make([]shaderSource, 0, len(all))
+	for i := range all {
+		if all[i].path != "" {
+			sources = append(sources, all[i])
+		}
+	}
+	return sources
+}
+
+// compileSource compiles the GLSL source of one of the stages of the shader
+// to the same SPIR-V file that the compiled shader data reads
+func (d *ShaderData) compileSource(db *assets.Database, src shaderSource) error {
+	flags := strings.TrimSpace(src.flags)
+	out := d.CompileVariantName(src.path, flags)
+	if d.EnableDebug {
+		flags += " -g"
+	}
+	if err := compiler.Compile(db.ToRawPath(src.path), db.ToRawPath(out), flags); err != nil {
+		return fmt.Errorf("failed to compile %s: %w", src.path, err)
+	}
+	return nil
+}
+
+func hotReloadShader(caches RenderCaches, key string) error {
+	db := caches.AssetDatabase()
+	var sd ShaderData
+	if err := materialUnmarshallData(db, key, &sd); err != nil {
+		return err
+	}
+	for _, src := range sd.sources() {
+		if err := sd.compileSource(db, src); err != nil {
+			return err
+		}
+	}
+	return caches.ShaderCache().reload(sd.Compile())
+}
+
+func hotReloadMaterial(caches RenderCaches, key string) error {
+	var data MaterialData
+	if err := materialUnmarshallData(caches.AssetDatabase(), key, &data); err != nil {
+		return err
+	}
+	material, err := caches.MaterialCache().reloadData(data)
+	if material != nil && err == nil {
+		caches.ShaderCache().rebuildShader(material.Shader)
+	}
+	return err
+}
+
+func hotReloadMaterials(caches RenderCaches, match func(d *MaterialData) bool) error {
+	materials, err := caches.MaterialCache().reload(match)
+	rebuilt := make(map[*Shader]struct{}, len(materials))
+	for _, m := range materials {
+		if _, ok := rebuilt[m.Shader]; !ok {
+			caches.ShaderCache().rebuildShader(m.Shader)
+			rebuilt[m.Shader] = struct{}{}
+		}
+	}
+	return err
+}
+
+func hotReloadRenderPass(renderer Renderer, caches RenderCaches, key string) error {
+	var data RenderPassData
+	if err := materialUnmarshallData(caches.AssetDatabase(), key, &data); err != nil {
+		return err
+	}
+	var pass *RenderPass
+	var err error
+	switch r := renderer.(type) {
+	case *Vulkan:
+		pass, err = r.reloadRenderPass(&data)
+	case *Software:
+		pass, err = r.reloadRenderPass(&data)
+	default:
+		return errors.New("the renderer does not support reloading render passes")
+	}
+	if pass != nil && err == nil {
+		caches.ShaderCache().rebuildRenderPass(pass)
+	}
+	return err
+}
+
+// hotReloadGLSL compiles the changed GLSL source, or the sources that include
+// it, with the flags of each shader that uses them and then reloads those
+// shaders. Sources that are not used by a shader are compiled into the SPIR-V
+// folder the same way the spirv generator does.
+func hotReloadGLSL(caches RenderCaches, key string) error {
+	db := caches.AssetDatabase()
+	affected := glslAffected(db, key)
+	compiled := make(map[string]bool, len(affected))
+	var errs []error
+	for _, file := range listHotReloadFolder(db, hotReloadShaderFolder) {
+		if filepath.Ext(file) != ".shader" {
+			continue
+		}
+		src, err := filesystem.ReadFile(file)
+		if err != nil {
+			continue
+		}
+		var sd ShaderData
+		if err := json.Unmarshal(src, &sd); err != nil {
+			continue
+		}
+		changed := false
+		for _, s := range sd.sources() {
+			if !affected[hotReloadKey(s.path)] {
+				continue
+			}
+			compiled[hotReloadKey(s.path)] = true
+			if err := sd.compileSource(db, s); err != nil {
+				errs = append(errs, err)
+			} else {
+				changed = true
+			}
+		}
+		if changed {
+			if err := caches.ShaderCache().reload(sd.Compile()); err != nil {
+				errs = append(errs, fmt.Errorf("shader %s: %w", sd.Name, err))
+			}
+		}
+	}
+	for k := range affected {
+		if compiled[k] {
+			continue
+		}
+		if ext := path.Ext(k); ext == ".inl" || ext == ".glsl" {
+			continue
+		}
+		standalone := ShaderData{}
+		if err := standalone.compileSource(db, shaderSource{path: k}); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func listHotReloadFolder(db *assets.Database, key string) []string {
+	folder := db.ToRawPath(key)
+	if !filesystem.DirectoryExists(folder) {
+		return nil
+	}
+	files, _ := filesystem.ListFilesRecursive(folder)
+	return files
+}
+
+// glslAffected finds the GLSL sources next to the changed source that include
+// it, either directly or through other includes, along with the source itself
+func glslAffected(db *assets.Database, key string) map[string]bool {
+	dir := path.Dir(key)
+	affected := map[string]bool{key: true}
+	files := listHotReloadFolder(db, dir)
+	sources := make(map[string]string, len(files))
+	for _, file := range files {
+		if txt, err := filesystem.ReadTextFile(file); err == nil {
+			sources[path.Join(dir, filepath.Base(file))] = txt
+		}
+	}
+	for found := true; found; {
+		found = false
+		for k, txt := range sources {
+			if affected[k] {
+				continue
+			}
+			for a := range affected {
+				if strings.Contains(txt, `#include "`+path.Base(a)+`"`) {
+					affected[k] = true
+					found = true
+					break
+				}
+			}
+		}
+	}
+	return affected
+}
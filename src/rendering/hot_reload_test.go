@@ -0,0 +1,185 @@
+/******************************************************************************/
+/* hot_reload_test.go                                                         */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package rendering
+
+import (
+	"errors"
+	"kaiju/engine/assets"
+	"os"
+	"path/filepath"
+	"testing"
+
+	vk "kaiju/rendering/vulkan"
+)
+
+const (
+	testHotReloadShader = `{"Name":"test","LayoutGroups":[{"Type":"Vertex","Layouts":[` +
+		`{"Location":8,"Binding":-1,"Type":"mat4","Name":"model","Source":"in"}]}]}`
+	testHotReloadMaterial = `{"Name":"test",` +
+		`"Shader":"content/renderer/shaders/test.shader",` +
+		`"RenderPass":"content/renderer/passes/test.renderpass",` +
+		`"ShaderPipeline":"content/renderer/pipelines/test.shaderpipeline"}`
+)
+
+func testHotReloadWrite(t *testing.T, key, data string) {
+	t.Helper()
+	path := filepath.Join("content", key)
+	if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(data), os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func testHotReloadCaches(t *testing.T) (*Software, *testRenderCaches) {
+	t.Chdir(t.TempDir())
+	testHotReloadWrite(t, "renderer/shaders/test.shader", testHotReloadShader)
+	testHotReloadWrite(t, "renderer/passes/test.renderpass", `{"Name":"test"}`)
+	testHotReloadWrite(t, "renderer/pipelines/test.shaderpipeline",
+		`{"Name":"test","Rasterization":{"CullMode":"Back"}}`)
+	testHotReloadWrite(t, "renderer/materials/test.material", testHotReloadMaterial)
+	db := assets.NewDatabase()
+	sr := NewSoftwareRenderer(4, 4)
+	caches := &testRenderCaches{
+		shaders:   NewShaderCache(sr, &db),
+		materials: NewMaterialCache(sr, &db),
+		db:        &db,
+	}
+	sr.caches = caches
+	return sr, caches
+}
+
+func TestHotReloadPipeline(t *testing.T) {
+	sr, caches := testHotReloadCaches(t)
+	material, err := caches.materials.Material("test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	caches.shaders.CreatePending()
+	instance := material.CreateInstance(nil)
+	shader := material.Shader
+	testHotReloadWrite(t, "renderer/pipelines/test.shaderpipeline",
+		`{"Name":"test","Rasterization":{"CullMode":"Front"}}`)
+	if err := HotReload(sr, caches, "content/renderer/pipelines/test.shaderpipeline"); err != nil {
+		t.Fatal(err)
+	}
+	front := vk.CullModeFlags(vk.CullModeFrontBit)
+	if material.pipelineInfo.Rasterization.CullMode != front ||
+		instance.pipelineInfo.Rasterization.CullMode != front {
+		t.Error("expected the material and its instance to use the new pipeline")
+	}
+	if material.Shader != shader || instance.Shader != shader {
+		t.Error("expected the material to keep its shader")
+	}
+	if shader.pipelineInfo != &material.pipelineInfo {
+		t.Error("expected the shader to be rebuilt with the pipeline of the material")
+	}
+	if _, ok := sr.shaders[shader]; !ok {
+		t.Error("expected the shader to be created again")
+	}
+}
+
+func TestHotReloadKeepsMaterialOnError(t *testing.T) {
+	sr, caches := testHotReloadCaches(t)
+	material, err := caches.materials.Material("test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	caches.shaders.CreatePending()
+	testHotReloadWrite(t, "renderer/shaders/test.shader", `{"Name":"test"}`)
+	err = HotReload(sr, caches, "renderer/shaders/test.shader")
+	if !errors.Is(err, errHotReloadLayout) {
+		t.Errorf("expected changing the layout to fail, got %v", err)
+	}
+	if len(material.Shader.data.LayoutGroups) != 1 {
+		t.Error("expected the shader to keep its layout")
+	}
+	testHotReloadWrite(t, "renderer/materials/test.material", "{")
+	if err = HotReload(sr, caches, "renderer/materials/test.material"); err == nil {
+		t.Error("expected a broken material file to fail")
+	}
+	if _, ok := sr.shaders[material.Shader]; !ok {
+		t.Error("expected the shader to still be created")
+	}
+}
+
+func TestHotReloadRenderPass(t *testing.T) {
+	sr, caches := testHotReloadCaches(t)
+	material, err := caches.materials.Material("test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	pass := material.renderPass
+	testHotReloadWrite(t, "renderer/passes/test.renderpass", `{"Name":"test","Sort":7}`)
+	if err := HotReload(sr, caches, "renderer/passes/test.renderpass"); err != nil {
+		t.Fatal(err)
+	}
+	if material.renderPass != pass || pass.construction.Sort != 7 {
+		t.Error("expected the render pass to be reloaded in place")
+	}
+}
+
+func TestGLSLAffected(t *testing.T) {
+	t.Chdir(t.TempDir())
+	testHotReloadWrite(t, "renderer/src/inc_a.inl", "")
+	testHotReloadWrite(t, "renderer/src/inc_b.inl", `#include "inc_a.inl"`)
+	testHotReloadWrite(t, "renderer/src/lit.frag", `#include "inc_b.inl"`)
+	testHotReloadWrite(t, "renderer/src/basic.frag", `#include "inc_c.inl"`)
+	db := assets.NewDatabase()
+	affected := glslAffected(&db, "renderer/src/inc_a.inl")
+	for _, k := range []string{"renderer/src/inc_a.inl", "renderer/src/inc_b.inl", "renderer/src/lit.frag"} {
+		if !affected[k] {
+			t.Errorf("expected %s to be affected", k)
+		}
+	}
+	if affected["renderer/src/basic.frag"] {
+		t.Error("expected a source that doesn't include the change to be skipped")
+	}
+}
+
+func TestCanHotReload(t *testing.T) {
+	for _, k := range []string{"a.shader", "a.material", "a.renderpass", "a.shaderpipeline", "a.vert", "a.inl"} {
+		if !CanHotReload(k) {
+			t.Errorf("expected %s to be reloadable", k)
+		}
+	}
+	if CanHotReload("a.png") {
+		t.Error("expected textures to not be reloadable")
+	}
+}
@@ -0,0 +1,301 @@
+/******************************************************************************/
+/* light.go                                                                   */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package rendering
+
+import (
+	"cmp"
+	"kaiju/engine/cameras"
+	"kaiju/matrix"
+	"kaiju/platform/profiler/tracing"
+	"slices"
+	"sync"
+)
+
+const (
+	MaxLights        = 32
+	LightTileColumns = 16
+	LightTileRows    = 9
+	LightTileCount   = LightTileColumns * LightTileRows
+)
+
+type LightType int32
+
+const (
+	LightTypeDirectional LightType = iota
+	LightTypePoint
+	LightTypeSpot
+)
+
+// Light is a dynamic light that is sent to the shaders through the global
+// shader data. Point and spot lights only reach as far as their range and are
+// culled against the camera by it, directional lights always affect the whole
+// scene. Lights are moved by setting their position and direction, the changes
+// are picked up on the next frame.
+type Light struct {
+	Type      LightType
+	Position  matrix.Vec3
+	Direction matrix.Vec3
+	Color     matrix.Color
+	Intensity matrix.Float
+	Range     matrix.Float
+	// InnerAngle and OuterAngle are the half angles (in degrees) of a spot
+	// light's cone, the light fades out between the inner and outer angle
+	InnerAngle matrix.Float
+	OuterAngle matrix.Float
+	// CastShadows will render the shadow casting drawings from the light's
+	// point of view, only directional and spot lights can cast shadows
+	CastShadows bool
+	deactivated bool
+}
+
+// ShaderLight is the layout of a single light within the global shader data,
+// it matches the Light struct in the lighting shader include
+type ShaderLight struct {
+	Position  matrix.Vec4 // w = type
+	Direction matrix.Vec4 // w = range
+	Color     matrix.Vec4 // a = intensity
+	Cone      matrix.Vec4 // x = cos(inner), y = cos(outer), z = shadow slot
+}
+
+// LightList holds all of the lights for a host, it is safe to add and remove
+// lights from any goroutine
+type LightList struct {
+	// Ambient is the light that is applied to all lit surfaces, the alpha
+	// channel is used as the intensity
+	Ambient        matrix.Color
+	Shadows        ShadowSettings
+	lights         []*Light
+	visible        []*Light
+	shadowViews    shadowViews
+	shadowSlotSize matrix.Float
+	mutex          sync.Mutex
+}
+
+func NewDirectionalLight(direction matrix.Vec3, color matrix.Color, intensity matrix.Float) *Light {
+	return &Light{
+		Type:      LightTypeDirectional,
+		Direction: direction,
+		Color:     color,
+		Intensity: intensity,
+	}
+}
+
+func NewPointLight(position matrix.Vec3, color matrix.Color, intensity, lightRange matrix.Float) *Light {
+	return &Light{
+		Type:      LightTypePoint,
+		Position:  position,
+		Color:     color,
+		Intensity: intensity,
+		Range:     lightRange,
+	}
+}
+
+func NewSpotLight(position, direction matrix.Vec3, color matrix.Color,
+	intensity, lightRange, innerAngle, outerAngle matrix.Float) *Light {
+	return &Light{
+		Type:       LightTypeSpot,
+		Position:   position,
+		Direction:  direction,
+		Color:      color,
+		Intensity:  intensity,
+		Range:      lightRange,
+		InnerAngle: innerAngle,
+		OuterAngle: outerAngle,
+	}
+}
+
+func (l *Light) Activate()      { l.deactivated = false }
+func (l *Light) Deactivate()    { l.deactivated = true }
+func (l *Light) IsActive() bool { return !l.deactivated }
+
+func (l *Light) toShader() ShaderLight {
+	dir := l.Direction
+	if !dir.IsZero() {
+		dir = dir.Normal()
+	}
+	outer := max(l.OuterAngle, l.InnerAngle)
+	return ShaderLight{
+		Position:  l.Position.AsVec4WithW(matrix.Float(l.Type)),
+		Direction: dir.AsVec4WithW(l.Range),
+		Color:     matrix.Vec4{l.Color.R(), l.Color.G(), l.Color.B(), l.Intensity},
+		Cone: matrix.Vec4{
+			matrix.Cos(matrix.Deg2Rad(l.InnerAngle)),
+			matrix.Cos(matrix.Deg2Rad(outer)), 0, 0,
+		},
+	}
+}
+
+func NewLightList() LightList {
+	return LightList{
+		Ambient:        matrix.NewColor(1, 1, 1, 0.05),
+		Shadows:        DefaultShadowSettings(),
+		lights:         make([]*Light, 0),
+		visible:        make([]*Light, 0, MaxLights),
+		shadowSlotSize: 1024,
+	}
+}
+
+func (l *LightList) Add(light *Light) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	l.lights = append(l.lights, light)
+}
+
+func (l *LightList) Remove(light *Light) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	if idx := slices.Index(l.lights, light); idx >= 0 {
+		l.lights = slices.Delete(l.lights, idx, idx+1)
+	}
+}
+
+// Count returns the total number of lights, including the ones that are not
+// active or not visible to the camera
+func (l *LightList) Count() int {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	return len(l.lights)
+}
+
+// writeShaderData culls the lights against the camera and writes the visible
+// ones into the global shader data. The screen is split into a grid of
+// LightTileColumns by LightTileRows tiles and each tile gets a bit mask of
+// the lights whose range overlaps it. When there are more than MaxLights
+// visible, directional lights are kept first and then the lights closest to
+// the camera.
+func (l *LightList) writeShaderData(data *GlobalShaderData, camera cameras.Camera) {
+	defer tracing.NewRegion("LightList::writeShaderData").End()
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	data.AmbientLight = matrix.Vec4{l.Ambient.R(), l.Ambient.G(),
+		l.Ambient.B(), l.Ambient.A()}
+	viewProjection := matrix.Mat4Multiply(camera.View(), camera.Projection())
+	camPos := camera.Position()
+	l.visible = l.visible[:0]
+	for _, light := range l.lights {
+		if !light.IsActive() || light.Intensity <= 0 {
+			continue
+		}
+		if light.Type != LightTypeDirectional {
+			if _, ok := lightScreenBounds(light, viewProjection); !ok {
+				continue
+			}
+		}
+		l.visible = append(l.visible, light)
+	}
+	if len(l.visible) > MaxLights {
+		slices.SortStableFunc(l.visible, func(a, b *Light) int {
+			return cmp.Compare(lightPriority(a, camPos), lightPriority(b, camPos))
+		})
+		l.visible = l.visible[:MaxLights]
+	}
+	data.LightCount = uint32(len(l.visible))
+	for i, light := range l.visible {
+		data.Lights[i] = light.toShader()
+		bit := uint32(1) << i
+		if light.Type == LightTypeDirectional {
+			for t := range LightTileCount {
+				data.LightTiles[t/4][t%4] |= bit
+			}
+			continue
+		}
+		rect, _ := lightScreenBounds(light, viewProjection)
+		for y := rect[1]; y <= rect[3]; y++ {
+			for x := rect[0]; x <= rect[2]; x++ {
+				t := y*LightTileColumns + x
+				data.LightTiles[t/4][t%4] |= bit
+			}
+		}
+	}
+	l.writeShadowData(data, camera)
+}
+
+func lightPriority(light *Light, cameraPosition matrix.Vec3) matrix.Float {
+	if light.Type == LightTypeDirectional {
+		return -matrix.FloatMax
+	}
+	return light.Position.Distance(cameraPosition) - light.Range
+}
+
+// lightScreenBounds returns the inclusive tile rectangle (min x, min y, max x,
+// max y) that the light's range covers on screen, or false if the range is
+// completely outside of the view frustum. The corners of the box around the
+// range sphere are used, which is conservative but cheap.
+func lightScreenBounds(light *Light, viewProjection matrix.Mat4) ([4]int, bool) {
+	r := light.Range
+	var outside [6]int
+	minX, minY := matrix.Float(1), matrix.Float(1)
+	maxX, maxY := matrix.Float(-1), matrix.Float(-1)
+	behind := false
+	for i := range 8 {
+		corner := light.Position.Add(matrix.Vec3{
+			r * matrix.Float((i&1)*2-1),
+			r * matrix.Float((i>>1&1)*2-1),
+			r * matrix.Float((i>>2&1)*2-1),
+		})
+		c := matrix.Mat4MultiplyVec4(viewProjection, corner.AsVec4WithW(1))
+		x, y, z, w := c.X(), c.Y(), c.Z(), c.W()
+		for p, out := range [6]bool{x < -w, x > w, y < -w, y > w, z < -w, z > w} {
+			if out {
+				outside[p]++
+			}
+		}
+		if w <= matrix.FloatSmallestNonzero {
+			behind = true
+			continue
+		}
+		minX, maxX = min(minX, x/w), max(maxX, x/w)
+		minY, maxY = min(minY, y/w), max(maxY, y/w)
+	}
+	for i := range outside {
+		if outside[i] == 8 {
+			return [4]int{}, false
+		}
+	}
+	if behind {
+		return [4]int{0, 0, LightTileColumns - 1, LightTileRows - 1}, true
+	}
+	toTile := func(ndc matrix.Float, count int) int {
+		t := int((matrix.Clamp(ndc, -1, 1)*0.5 + 0.5) * matrix.Float(count))
+		return min(t, count-1)
+	}
+	return [4]int{
+		toTile(minX, LightTileColumns), toTile(minY, LightTileRows),
+		toTile(maxX, LightTileColumns), toTile(maxY, LightTileRows),
+	}, true
+}
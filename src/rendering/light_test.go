@@ -0,0 +1,128 @@
+/******************************************************************************/
+/* light_test.go                                                              */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package rendering
+
+import (
+	"kaiju/engine/cameras"
+	"kaiju/matrix"
+	"testing"
+)
+
+func testLightCamera() cameras.Camera {
+	c := cameras.NewStandardCamera(1600, 900, 1600, 900, matrix.Vec3{0, 0, 10})
+	c.SetLookAt(matrix.Vec3Zero())
+	return c
+}
+
+func lightTileHas(data *GlobalShaderData, column, row, light int) bool {
+	t := row*LightTileColumns + column
+	return data.LightTiles[t/4][t%4]&(1<<light) != 0
+}
+
+func TestLightListTiles(t *testing.T) {
+	lights := NewLightList()
+	lights.Add(NewPointLight(matrix.Vec3Zero(), matrix.ColorWhite(), 1, 1))
+	lights.Add(NewDirectionalLight(matrix.Vec3Down(), matrix.ColorWhite(), 1))
+	data := GlobalShaderData{}
+	lights.writeShaderData(&data, testLightCamera())
+	if data.LightCount != 2 {
+		t.Fatalf("expected 2 visible lights, got %d", data.LightCount)
+	}
+	if !lightTileHas(&data, LightTileColumns/2, LightTileRows/2, 0) {
+		t.Error("expected the point light to cover the center tile")
+	}
+	if lightTileHas(&data, 0, 0, 0) {
+		t.Error("expected the point light to not reach the corner tile")
+	}
+	for _, tile := range [][2]int{{0, 0}, {LightTileColumns - 1, LightTileRows - 1}} {
+		if !lightTileHas(&data, tile[0], tile[1], 1) {
+			t.Errorf("expected the directional light to cover tile %v", tile)
+		}
+	}
+}
+
+func TestLightListCullsByRange(t *testing.T) {
+	lights := NewLightList()
+	behind := NewPointLight(matrix.Vec3{0, 0, 20}, matrix.ColorWhite(), 1, 5)
+	lights.Add(behind)
+	off := NewSpotLight(matrix.Vec3{100, 0, 0}, matrix.Vec3Down(),
+		matrix.ColorWhite(), 1, 5, 20, 30)
+	lights.Add(off)
+	inactive := NewPointLight(matrix.Vec3Zero(), matrix.ColorWhite(), 1, 5)
+	inactive.Deactivate()
+	lights.Add(inactive)
+	data := GlobalShaderData{}
+	lights.writeShaderData(&data, testLightCamera())
+	if data.LightCount != 0 {
+		t.Fatalf("expected every light to be culled, got %d", data.LightCount)
+	}
+	// Moving the light into range should make it visible on the next frame
+	behind.Position = matrix.Vec3{0, 0, 11}
+	lights.writeShaderData(&data, testLightCamera())
+	if data.LightCount != 1 {
+		t.Fatalf("expected the moved light to be visible, got %d", data.LightCount)
+	}
+	lights.Remove(behind)
+	if lights.Count() != 2 {
+		t.Errorf("expected 2 lights after removing one, got %d", lights.Count())
+	}
+}
+
+func TestLightListKeepsClosestLights(t *testing.T) {
+	lights := NewLightList()
+	for i := range MaxLights + 4 {
+		lights.Add(NewPointLight(matrix.Vec3{0, 0, -matrix.Float(i)},
+			matrix.ColorWhite(), 1, 1))
+	}
+	sun := NewDirectionalLight(matrix.Vec3Down(), matrix.ColorWhite(), 1)
+	lights.Add(sun)
+	data := GlobalShaderData{}
+	lights.writeShaderData(&data, testLightCamera())
+	if data.LightCount != MaxLights {
+		t.Fatalf("expected the light count to be capped at %d, got %d",
+			MaxLights, data.LightCount)
+	}
+	if data.Lights[0].Position.W() != matrix.Float(LightTypeDirectional) {
+		t.Error("expected the directional light to be kept first")
+	}
+	for i := 1; i < MaxLights; i++ {
+		if z := data.Lights[i].Position.Z(); z < -matrix.Float(MaxLights) {
+			t.Errorf("expected only the closest lights to be kept, got z %f", z)
+		}
+	}
+}
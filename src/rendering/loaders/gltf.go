@@ -38,6 +38,7 @@
 package loaders
 
 import (
+	"encoding/base64"
 	"encoding/binary"
 	"errors"
 	"kaiju/engine/assets"
@@ -46,6 +47,7 @@ import (
 	"kaiju/rendering"
 	"kaiju/rendering/loaders/gltf"
 	"kaiju/rendering/loaders/load_result"
+	"net/url"
 	"path/filepath"
 	"slices"
 	"strings"
@@ -55,12 +57,14 @@ import (
 type fullGLTF struct {
 	glTF gltf.GLTF
 	bins [][]byte
+	// root is the folder of the file, external images are relative to it
+	root string
 }
 
 func readFileGLB(file string, assetDB *assets.Database) (fullGLTF, error) {
 	const headSize = 12
 	const chunkHeadSize = 8
-	g := fullGLTF{}
+	g := fullGLTF{root: filepath.Dir(file)}
 	data, err := assetDB.Read(file)
 	if err != nil {
 		return g, err
@@ -114,7 +118,7 @@ func readFileGLB(file string, assetDB *assets.Database) (fullGLTF, error) {
 }
 
 func readFileGLTF(file string, assetDB *assets.Database) (fullGLTF, error) {
-	g := fullGLTF{}
+	g := fullGLTF{root: filepath.Dir(file)}
 	str, err := assetDB.ReadText(file)
 	if err != nil {
 		return g, err
@@ -124,9 +128,8 @@ func readFileGLTF(file string, assetDB *assets.Database) (fullGLTF, error) {
 		return g, err
 	}
 	g.bins = make([][]byte, len(g.glTF.Buffers))
-	root := filepath.Dir(file)
 	for i, path := range g.glTF.Buffers {
-		uri := filepath.Join(root, path.URI)
+		uri := filepath.Join(g.root, path.URI)
 		if !assetDB.Exists(uri) {
 			return g, errors.New("bin file (" + uri + ") does not exist")
 		}
@@ -210,8 +213,9 @@ func gltfParse(doc *fullGLTF) (load_result.Result, error) {
 		} else if indices, err := gltfReadMeshIndices(m, doc); err != nil {
 			return res, err
 		} else {
-			textures := gltfReadMeshTextures(m, &doc.glTF)
-			res.Add(n.Name, m.Name, verts, indices, klib.MapValues(textures))
+			material := gltfReadMeshMaterial(m, doc)
+			res.Add(n.Name, m.Name, verts, indices, material.TexturePaths())
+			res.Meshes[len(res.Meshes)-1].Material = material
 		}
 	}
 	res.Animations = gltfReadAnimations(doc)
@@ -355,7 +359,7 @@ func gltfReadMeshVerts(mesh *gltf.Mesh, doc *fullGLTF) ([]rendering.Vertex, erro
 	for i := int32(0); i < vertCount; i++ {
 		vertData[i].Position = matrix.Vec3FromSlice(verts)
 		verts = verts[3:]
-		vertData[i].Color = vertColor
+		vertData[i].Color = matrix.ColorWhite()
 		vertData[i].MorphTarget = vertData[i].Position
 		// NAN is being exported for colors, so skipping this line
 		//vertData[j].color = (vertColors != NULL ? ((color*)vertColors)[j] : color_white());
@@ -457,28 +461,88 @@ func gltfReadMeshIndices(mesh *gltf.Mesh, doc *fullGLTF) ([]uint32, error) {
 	return convertedIndices, nil
 }
 
-func gltfReadMeshTextures(mesh *gltf.Mesh, doc *gltf.GLTF) map[string]string {
-	textures := make(map[string]string)
-	if len(doc.Materials) == 0 || mesh.Primitives[0].Material == nil {
-		return textures
+func gltfReadMeshMaterial(mesh *gltf.Mesh, doc *fullGLTF) *load_result.Material {
+	g := &doc.glTF
+	if len(g.Materials) == 0 || mesh.Primitives[0].Material == nil ||
+		int(*mesh.Primitives[0].Material) >= len(g.Materials) {
+		return nil
+	}
+	mat := &g.Materials[*mesh.Primitives[0].Material]
+	pbr := &mat.PBRMetallicRoughness
+	res := &load_result.Material{
+		Name:        mat.Name,
+		Factors:     rendering.DefaultPBRFactors(),
+		DoubleSided: mat.DoubleSided,
+		Transparent: mat.AlphaMode == gltf.AlphaModeBlend,
+	}
+	if pbr.BaseColorFactor != nil {
+		res.Factors.BaseColor = *pbr.BaseColorFactor
+	}
+	if pbr.MetallicFactor != nil {
+		res.Factors.Metallic = *pbr.MetallicFactor
 	}
-	mat := doc.Materials[*mesh.Primitives[0].Material]
-	if mat.PBRMetallicRoughness.BaseColorTexture != nil {
-		textures["baseColor"] = doc.Images[mat.PBRMetallicRoughness.BaseColorTexture.Index].URI
+	if pbr.RoughnessFactor != nil {
+		res.Factors.Roughness = *pbr.RoughnessFactor
 	}
-	if mat.PBRMetallicRoughness.MetallicRoughnessTexture != nil {
-		textures["metallicRoughness"] = doc.Images[mat.PBRMetallicRoughness.MetallicRoughnessTexture.Index].URI
+	if mat.EmissiveFactor != nil {
+		res.Factors.Emissive = *mat.EmissiveFactor
+	}
+	if mat.Extensions.EmissiveStrength != nil {
+		res.Factors.EmissiveStrength = mat.Extensions.EmissiveStrength.EmissiveStrength
+	}
+	if mat.AlphaMode == gltf.AlphaModeMask {
+		res.Factors.AlphaCutoff = 0.5
+		if mat.AlphaCutoff != nil {
+			res.Factors.AlphaCutoff = *mat.AlphaCutoff
+		}
 	}
-	if mat.NormalTexture != nil {
-		textures["normal"] = doc.Images[mat.NormalTexture.Index].URI
+	if mat.NormalTexture != nil && mat.NormalTexture.Scale != nil {
+		res.Factors.NormalScale = *mat.NormalTexture.Scale
 	}
-	if mat.OcclusionTexture != nil {
-		textures["occlusion"] = doc.Images[mat.OcclusionTexture.Index].URI
+	if mat.OcclusionTexture != nil && mat.OcclusionTexture.Strength != nil {
+		res.Factors.OcclusionStrength = *mat.OcclusionTexture.Strength
 	}
-	if mat.EmissiveTexture != nil {
-		textures["emissive"] = doc.Images[mat.EmissiveTexture.Index].URI
+	res.BaseColor = gltfReadTexture(pbr.BaseColorTexture, doc)
+	res.MetallicRoughness = gltfReadTexture(pbr.MetallicRoughnessTexture, doc)
+	res.Normal = gltfReadTexture(mat.NormalTexture, doc)
+	res.Occlusion = gltfReadTexture(mat.OcclusionTexture, doc)
+	res.Emissive = gltfReadTexture(mat.EmissiveTexture, doc)
+	return res
+}
+
+func gltfReadTexture(id *gltf.TextureId, doc *fullGLTF) load_result.Texture {
+	g := &doc.glTF
+	if id == nil {
+		return load_result.Texture{}
+	}
+	imgIdx := id.Index
+	// Older exports referenced the image directly rather than the texture
+	if int(id.Index) < len(g.Textures) {
+		imgIdx = g.Textures[id.Index].Source
+	}
+	if imgIdx < 0 || int(imgIdx) >= len(g.Images) {
+		return load_result.Texture{}
+	}
+	img := &g.Images[imgIdx]
+	tex := load_result.Texture{Name: img.Name, MimeType: img.MimeType}
+	if img.BufferView != nil {
+		if int(*img.BufferView) < len(g.BufferViews) {
+			tex.Data = gltfViewBytes(doc, &g.BufferViews[*img.BufferView])
+		}
+	} else if strings.HasPrefix(img.URI, "data:") {
+		header, data, ok := strings.Cut(img.URI, ",")
+		if ok && strings.HasSuffix(header, ";base64") {
+			tex.MimeType = strings.TrimSuffix(strings.TrimPrefix(header, "data:"), ";base64")
+			tex.Data, _ = base64.StdEncoding.DecodeString(data)
+		}
+	} else if img.URI != "" {
+		uri, err := url.PathUnescape(img.URI)
+		if err != nil {
+			uri = img.URI
+		}
+		tex.Path = filepath.ToSlash(filepath.Join(doc.root, uri))
 	}
-	return textures
+	return tex
 }
 
 func gltfReadAnimations(doc *fullGLTF) []load_result.Animation {
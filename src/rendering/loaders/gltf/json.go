@@ -117,25 +117,50 @@ type Animation struct {
 	Samplers []AnimationSampler `json:"samplers"`
 }
 
+const (
+	AlphaModeOpaque = "OPAQUE"
+	AlphaModeMask   = "MASK"
+	AlphaModeBlend  = "BLEND"
+)
+
+// TextureId is a textureInfo, Scale is only used by normal textures and
+// Strength is only used by occlusion textures
 type TextureId struct {
-	Index int32 `json:"index"`
+	Index    int32    `json:"index"`
+	TexCoord int32    `json:"texCoord"`
+	Scale    *float32 `json:"scale"`
+	Strength *float32 `json:"strength"`
 }
 
+// PBRMetallicRoughness factors are nil when not in the file, the glTF
+// default for each of them is 1
 type PBRMetallicRoughness struct {
 	BaseColorTexture         *TextureId    `json:"baseColorTexture"`
 	MetallicRoughnessTexture *TextureId    `json:"metallicRoughnessTexture"`
-	MetallicFactor           float32       `json:"metallicFactor"`
-	RoughnessFactor          float32       `json:"roughnessFactor"`
+	MetallicFactor           *float32      `json:"metallicFactor"`
+	RoughnessFactor          *float32      `json:"roughnessFactor"`
 	BaseColorFactor          *matrix.Color `json:"baseColorFactor"`
 }
 
+type EmissiveStrength struct {
+	EmissiveStrength float32 `json:"emissiveStrength"`
+}
+
+type MaterialExtensions struct {
+	EmissiveStrength *EmissiveStrength `json:"KHR_materials_emissive_strength"`
+}
+
 type Material struct {
 	Name                 string               `json:"name"`
 	DoubleSided          bool                 `json:"doubleSided"`
+	AlphaMode            string               `json:"alphaMode"`
+	AlphaCutoff          *float32             `json:"alphaCutoff"`
 	NormalTexture        *TextureId           `json:"normalTexture"`
 	OcclusionTexture     *TextureId           `json:"occlusionTexture"`
 	EmissiveTexture      *TextureId           `json:"emissiveTexture"`
+	EmissiveFactor       *matrix.Vec3         `json:"emissiveFactor"`
 	PBRMetallicRoughness PBRMetallicRoughness `json:"pbrMetallicRoughness"`
+	Extensions           MaterialExtensions   `json:"extensions"`
 }
 
 type Target struct {
@@ -174,10 +199,12 @@ type Texture struct {
 	Source  int32 `json:"source"`
 }
 
+// Image has either a URI or it is embedded in the BufferView
 type Image struct {
-	Name     string `json:"name"`
-	URI      string `json:"uri"`
-	MimeType string `json:"mimeType"`
+	Name       string `json:"name"`
+	URI        string `json:"uri"`
+	MimeType   string `json:"mimeType"`
+	BufferView *int32 `json:"bufferView"`
 }
 
 type Accessor struct {
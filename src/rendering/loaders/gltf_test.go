@@ -0,0 +1,111 @@
+/******************************************************************************/
+/* gltf_test.go                                                               */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package loaders
+
+import (
+	"encoding/base64"
+	"kaiju/matrix"
+	"kaiju/rendering/loaders/gltf"
+	"kaiju/rendering/loaders/load_result"
+	"testing"
+)
+
+func TestGLTFReadMeshMaterial(t *testing.T) {
+	png := base64.StdEncoding.EncodeToString([]byte("\x89PNG"))
+	doc, err := gltf.LoadGLTF(`{
+		"materials": [{
+			"name": "Metal",
+			"alphaMode": "MASK",
+			"doubleSided": true,
+			"emissiveFactor": [1, 0.5, 0],
+			"normalTexture": {"index": 1, "scale": 0.5},
+			"pbrMetallicRoughness": {
+				"baseColorFactor": [1, 0, 0, 1],
+				"baseColorTexture": {"index": 0},
+				"roughnessFactor": 0.25
+			},
+			"extensions": {"KHR_materials_emissive_strength": {"emissiveStrength": 4}}
+		}],
+		"meshes": [{"primitives": [{"material": 0}]}],
+		"textures": [{"source": 1}, {"source": 0}],
+		"images": [{"uri": "tex%20normal.png"}, {"uri": "data:image/png;base64,` + png + `"}]
+	}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	full := fullGLTF{glTF: doc, root: "content/models"}
+	mat := gltfReadMeshMaterial(&doc.Meshes[0], &full)
+	if mat == nil {
+		t.Fatal("expected the mesh to have a material")
+	}
+	if !mat.DoubleSided || mat.Transparent {
+		t.Errorf("unexpected material flags %v %v", mat.DoubleSided, mat.Transparent)
+	}
+	f := mat.Factors
+	if f.BaseColor != matrix.ColorRed() || f.Metallic != 1 || f.Roughness != 0.25 {
+		t.Errorf("unexpected factors %+v", f)
+	}
+	if f.Emissive != (matrix.Vec3{1, 0.5, 0}) || f.EmissiveStrength != 4 {
+		t.Errorf("unexpected emissive %v %v", f.Emissive, f.EmissiveStrength)
+	}
+	if f.AlphaCutoff != 0.5 || f.NormalScale != 0.5 || f.OcclusionStrength != 1 {
+		t.Errorf("unexpected alpha cutoff, normal scale or occlusion %+v", f)
+	}
+	if string(mat.BaseColor.Data) != "\x89PNG" || mat.BaseColor.MimeType != "image/png" {
+		t.Errorf("expected the base color to be the embedded image, got %+v", mat.BaseColor)
+	}
+	if mat.Normal.Path != "content/models/tex normal.png" {
+		t.Errorf("expected the normal map to be next to the model, got %q", mat.Normal.Path)
+	}
+	if mat.Occlusion.IsValid() || mat.MetallicRoughness.IsValid() {
+		t.Error("expected the unused textures to be invalid")
+	}
+	if paths := mat.TexturePaths(); len(paths) != 1 || paths[0] != mat.Normal.Path {
+		t.Errorf("unexpected texture paths %v", paths)
+	}
+}
+
+func TestGLTFReadMeshMaterialMissing(t *testing.T) {
+	doc := fullGLTF{glTF: gltf.GLTF{Meshes: []gltf.Mesh{{Primitives: []gltf.Primitive{{}}}}}}
+	if mat := gltfReadMeshMaterial(&doc.glTF.Meshes[0], &doc); mat != nil {
+		t.Errorf("expected no material, got %+v", mat)
+	}
+	if paths := (*load_result.Material)(nil).TexturePaths(); len(paths) != 0 {
+		t.Errorf("expected no texture paths, got %v", paths)
+	}
+}
@@ -63,11 +63,54 @@ const (
 	AnimInterpolateCubicSpline
 )
 
+// Texture is a texture used by a Material, it is either the path to the
+// image or the image file contents that were embedded in the model file
+type Texture struct {
+	Path     string
+	Data     []byte
+	MimeType string
+	Name     string
+}
+
+func (t *Texture) IsValid() bool { return t.Path != "" || len(t.Data) > 0 }
+
+// Material is the metallic-roughness material of a mesh, textures that are
+// not used by the material are left invalid
+type Material struct {
+	Name              string
+	Factors           rendering.PBRFactors
+	BaseColor         Texture
+	MetallicRoughness Texture
+	Normal            Texture
+	Occlusion         Texture
+	Emissive          Texture
+	Transparent       bool
+	DoubleSided       bool
+}
+
+// TexturePaths lists the textures of the material that are files, it is safe
+// to call on a nil material
+func (m *Material) TexturePaths() []string {
+	paths := []string{}
+	if m == nil {
+		return paths
+	}
+	for _, t := range []*Texture{&m.BaseColor, &m.MetallicRoughness,
+		&m.Normal, &m.Occlusion, &m.Emissive} {
+		if t.Path != "" {
+			paths = append(paths, t.Path)
+		}
+	}
+	return paths
+}
+
 type Mesh struct {
 	Name     string
 	MeshName string
 	Verts    []rendering.Vertex
 	Indexes  []uint32
+	// Material is nil when the model file did not describe one
+	Material *Material
 }
 
 type AnimBone struct {
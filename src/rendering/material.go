@@ -2,8 +2,11 @@ package rendering
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
 	"kaiju/engine/assets"
 	"log/slog"
+	"reflect"
 	"slices"
 	"strings"
 	"sync"
@@ -19,6 +22,9 @@ type Material struct {
 	Textures     []*Texture
 	Instances    map[string]*Material
 	Root         weak.Pointer[Material]
+	source       MaterialData
+	parameters   materialParameters
+	overrides    []MaterialParameterData
 	mutex        sync.Mutex
 }
 
@@ -32,6 +38,11 @@ func (m *Material) SelectRoot() *Material {
 type MaterialTextureData struct {
 	Texture string `options:""` // Blank = fallback
 	Filter  string `options:"StringVkFilter"`
+	// RenderPass, when set, uses an image of the render pass as the texture
+	// rather than the Texture file. RenderPassImage is the name of the image
+	// attachment, blank will use the first image of the render pass.
+	RenderPass      string `options:""`
+	RenderPassImage string
 }
 
 type MaterialData struct {
@@ -40,14 +51,27 @@ type MaterialData struct {
 	RenderPass     string `options:""` // Blank = fallback
 	ShaderPipeline string `options:""` // Blank = fallback
 	Textures       []MaterialTextureData
+	// Parameters are the default values of the parameters that the shader
+	// declares in its MaterialParameters uniform block
+	Parameters []MaterialParameterData
 }
 
 func (m *Material) CreateInstance(textures []*Texture) *Material {
+	return m.CreateInstanceWithParameters(textures, nil)
+}
+
+// CreateInstanceWithParameters creates an instance of the material that uses
+// the given textures, and overrides the default values of the named material
+// parameters. Instances with the same textures and overrides are shared.
+func (m *Material) CreateInstanceWithParameters(textures []*Texture, overrides []MaterialParameterData) *Material {
 	instanceKey := strings.Builder{}
 	for i := range textures {
 		instanceKey.WriteString(textures[i].Key)
 		instanceKey.WriteRune(';')
 	}
+	for i := range overrides {
+		fmt.Fprintf(&instanceKey, "%s=%v;", overrides[i].Name, overrides[i].Value)
+	}
 	key := instanceKey.String()
 	// TODO:  Use a read lock?
 	m.mutex.Lock()
@@ -58,6 +82,9 @@ func (m *Material) CreateInstance(textures []*Texture) *Material {
 	copy := &Material{}
 	*copy = *m
 	copy.Textures = slices.Clone(textures)
+	copy.parameters = m.parameters.clone()
+	copy.overrides = slices.Clone(overrides)
+	copy.parameters.apply(overrides)
 	// TODO:  If using a read lock, then make sure to write lock the following line
 	m.Instances[key] = copy
 	copy.Root = weak.Make(m)
@@ -91,11 +118,11 @@ func materialUnmarshallData(assets *assets.Database, file string, to any) error
 }
 
 func (d *MaterialData) Compile(assets *assets.Database, renderer Renderer) (*Material, error) {
-	vr := renderer.(*Vulkan)
 	c := &Material{
 		Name:      d.Name,
 		Textures:  make([]*Texture, len(d.Textures)),
 		Instances: make(map[string]*Material),
+		source:    *d,
 	}
 	sd := ShaderData{}
 	rp := RenderPassData{}
@@ -110,18 +137,35 @@ func (d *MaterialData) Compile(assets *assets.Database, renderer Renderer) (*Mat
 		return c, err
 	}
 	c.shaderInfo = sd.Compile()
-	if pass, ok := vr.renderPassCache[rp.Name]; !ok {
-		rpc := rp.Compile(vr)
-		if p, ok := rpc.ConstructRenderPass(vr); ok {
-			vr.renderPassCache[rp.Name] = p
-			c.renderPass = p
+	if p, ok := newMaterialParameters(&c.shaderInfo); ok {
+		c.parameters = p
+		c.parameters.apply(d.Parameters)
+	} else if len(d.Parameters) > 0 {
+		slog.Warn("the material has parameters but its shader doesn't declare a MaterialParameters block",
+			"material", d.Name)
+	}
+	var caches RenderCaches
+	switch r := renderer.(type) {
+	case *Vulkan:
+		caches = r.caches
+		if pass, ok := r.renderPassCache[rp.Name]; !ok {
+			rpc := rp.Compile(r)
+			if p, ok := rpc.ConstructRenderPass(r); ok {
+				r.renderPassCache[rp.Name] = p
+				c.renderPass = p
+			} else {
+				slog.Error("failed to load the render pass for the material", "material", d.Name, "renderPass", rp.Name)
+			}
 		} else {
-			slog.Error("failed to load the render pass for the material", "material", d.Name, "renderPass", rp.Name)
+			c.renderPass = pass
 		}
-	} else {
-		c.renderPass = pass
+	case *Software:
+		caches = r.caches
+		c.renderPass = r.renderPass(&rp)
+	default:
+		return c, errors.New("the renderer does not support compiling materials")
 	}
-	c.pipelineInfo = sp.Compile(vr)
+	c.pipelineInfo = sp.Compile(renderer)
 	shaderConfig, err := assets.ReadText(d.Shader)
 	if err != nil {
 		return c, err
@@ -130,11 +174,17 @@ func (d *MaterialData) Compile(assets *assets.Database, renderer Renderer) (*Mat
 	if err := json.Unmarshal([]byte(shaderConfig), &rawSD); err != nil {
 		return c, err
 	}
-	c.Shader, _ = vr.caches.ShaderCache().Shader(rawSD.Compile())
+	c.Shader, _ = caches.ShaderCache().Shader(rawSD.Compile())
 	c.Shader.pipelineInfo = &c.pipelineInfo
 	c.Shader.renderPass = c.renderPass
 	for i := range d.Textures {
-		tex, err := vr.caches.TextureCache().Texture(
+		if d.Textures[i].RenderPass != "" {
+			if tex, ok := materialRenderPassTexture(assets, renderer, &d.Textures[i]); ok {
+				c.Textures[i] = tex
+				continue
+			}
+		}
+		tex, err := caches.TextureCache().Texture(
 			d.Textures[i].Texture, d.Textures[i].FilterToVK())
 		if err != nil {
 			return c, err
@@ -144,7 +194,79 @@ func (d *MaterialData) Compile(assets *assets.Database, renderer Renderer) (*Mat
 	return c, nil
 }
 
+// materialRenderPassTexture finds the image of a render pass that is used as a
+// material texture, like a shadow map. Only the Vulkan renderer has render pass
+// images, other renderers will fall back to the Texture file.
+func materialRenderPassTexture(assets *assets.Database, renderer Renderer, d *MaterialTextureData) (*Texture, bool) {
+	vr, ok := renderer.(*Vulkan)
+	if !ok {
+		return nil, false
+	}
+	rp := RenderPassData{}
+	if err := materialUnmarshallData(assets, d.RenderPass, &rp); err != nil {
+		slog.Error("failed to load the render pass for the material texture",
+			"renderPass", d.RenderPass, "error", err)
+		return nil, false
+	}
+	pass, ok := vr.renderPassCache[rp.Name]
+	if !ok {
+		rpc := rp.Compile(vr)
+		if pass, ok = rpc.ConstructRenderPass(vr); !ok {
+			return nil, false
+		}
+		vr.renderPassCache[rp.Name] = pass
+	}
+	if d.RenderPassImage == "" {
+		if len(pass.textures) == 0 {
+			return nil, false
+		}
+		return &pass.textures[0], true
+	}
+	tex, ok := pass.findTextureByName(d.RenderPassImage)
+	if !ok {
+		slog.Error("failed to find the render pass image for the material texture",
+			"renderPass", rp.Name, "image", d.RenderPassImage)
+	}
+	return tex, ok
+}
+
+// swap moves the result of compiling the material again into the material and
+// its instances, the instances keep their own textures. Drawings are grouped
+// by render pass and have created their descriptors from the shader layout, so
+// neither of those, nor the number of textures, can change.
+func (m *Material) swap(from *Material) error {
+	if from.renderPass != m.renderPass {
+		return errors.New("the render pass of the material changed, a restart is required to use the new render pass")
+	}
+	if !reflect.DeepEqual(from.shaderInfo.LayoutGroups, m.shaderInfo.LayoutGroups) {
+		return errHotReloadLayout
+	}
+	if len(from.Textures) != len(m.Textures) {
+		return errors.New("the number of material textures changed, a restart is required to use the new textures")
+	}
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.shaderInfo = from.shaderInfo
+	m.pipelineInfo = from.pipelineInfo
+	m.Shader = from.Shader
+	m.Shader.pipelineInfo = &m.pipelineInfo
+	m.Shader.renderPass = m.renderPass
+	m.Textures = from.Textures
+	m.source = from.source
+	m.parameters = from.parameters
+	for _, instance := range m.Instances {
+		instance.shaderInfo = m.shaderInfo
+		instance.pipelineInfo = m.pipelineInfo
+		instance.Shader = m.Shader
+		instance.source = m.source
+		instance.parameters = m.parameters.clone()
+		instance.parameters.apply(instance.overrides)
+	}
+	return nil
+}
+
 func (m *Material) Destroy(renderer Renderer) {
-	vr := renderer.(*Vulkan)
-	m.renderPass.Destroy(vr)
+	if vr, ok := renderer.(*Vulkan); ok {
+		m.renderPass.Destroy(vr)
+	}
 }
@@ -2,6 +2,8 @@ package rendering
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
 	"kaiju/engine/assets"
 	"path/filepath"
 	"sync"
@@ -49,8 +51,13 @@ func (m *MaterialCache) Material(key string) (*Material, error) {
 	if material, ok := m.materials[key]; ok {
 		return material, nil
 	} else {
-		matStr, err := m.assetDatabase.ReadText(
-			filepath.Join("renderer/materials/", key+".material"))
+		// Keys with the material extension are paths to the material file,
+		// such as the materials generated when importing a model
+		file := key
+		if filepath.Ext(key) != ".material" {
+			file = filepath.Join("renderer/materials/", key+".material")
+		}
+		matStr, err := m.assetDatabase.ReadText(file)
 		if err != nil {
 			return nil, err
 		}
@@ -68,6 +75,47 @@ func (m *MaterialCache) Material(key string) (*Material, error) {
 	}
 }
 
+// reloadData compiles the material data and swaps the result into the loaded
+// material with the same name, nil is returned if it isn't loaded
+func (m *MaterialCache) reloadData(data MaterialData) (*Material, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	material, ok := m.materials[data.Name]
+	if !ok {
+		return nil, nil
+	}
+	return material, m.recompile(material, &data)
+}
+
+// reload compiles the loaded materials that match again from the data they
+// were loaded with. The materials that were swapped are returned along with
+// the errors of those that failed.
+func (m *MaterialCache) reload(match func(data *MaterialData) bool) ([]*Material, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	var reloaded []*Material
+	var errs []error
+	for _, material := range m.materials {
+		if !match(&material.source) {
+			continue
+		}
+		if err := m.recompile(material, &material.source); err != nil {
+			errs = append(errs, fmt.Errorf("material %s: %w", material.Name, err))
+		} else {
+			reloaded = append(reloaded, material)
+		}
+	}
+	return reloaded, errors.Join(errs...)
+}
+
+func (m *MaterialCache) recompile(material *Material, data *MaterialData) error {
+	compiled, err := data.Compile(m.assetDatabase, m.renderer)
+	if err != nil {
+		return err
+	}
+	return material.swap(compiled)
+}
+
 func (m *MaterialCache) Destroy() {
 	for _, mat := range m.pendingMaterials {
 		mat.Destroy(m.renderer)
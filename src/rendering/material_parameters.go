@@ -0,0 +1,347 @@
+/******************************************************************************/
+/* material_parameters.go                                                     */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package rendering
+
+import (
+	"encoding/binary"
+	"fmt"
+	"kaiju/matrix"
+	"log/slog"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// MaterialParametersBlock is the type name of the uniform block that a shader
+// declares to hold the named parameters of its materials, for example:
+//
+//	layout(set = 0, binding = 3) uniform MaterialParameters {
+//		vec4 tint;
+//		float roughness;
+//	};
+//
+// The values of the block come from the material rather than the instance
+// data of the drawings, so materials using the same shader can be tweaked
+// without writing a new #DrawInstance type.
+const MaterialParametersBlock = "MaterialParameters"
+
+type MaterialParameterType int
+
+const (
+	MaterialParameterFloat MaterialParameterType = iota
+	MaterialParameterVec2
+	MaterialParameterVec3
+	MaterialParameterVec4
+	MaterialParameterColor
+	MaterialParameterInt
+)
+
+// MaterialParameterData is the value of a named material parameter within a
+// .material file. Only as many values as the parameter uses are read, an int
+// parameter reads its value from the first one.
+type MaterialParameterData struct {
+	Name  string
+	Value [4]float32
+}
+
+// MaterialParameter is a parameter declared by a field of the shader's
+// #MaterialParametersBlock. The vec4 fields with a name ending in color or
+// tint are colors, which default to white rather than zero.
+type MaterialParameter struct {
+	Name   string
+	Type   MaterialParameterType
+	Offset int
+}
+
+type materialParameters struct {
+	// name is the full name of the uniform block, it matches the key of the
+	// named buffers of the draw instance groups
+	name   string
+	fields []MaterialParameter
+	data   []byte
+}
+
+func (t MaterialParameterType) String() string {
+	switch t {
+	case MaterialParameterFloat:
+		return "float"
+	case MaterialParameterVec2:
+		return "vec2"
+	case MaterialParameterVec3:
+		return "vec3"
+	case MaterialParameterVec4:
+		return "vec4"
+	case MaterialParameterColor:
+		return "color"
+	case MaterialParameterInt:
+		return "int"
+	default:
+		return "unknown"
+	}
+}
+
+// Components is the number of values the parameter type is made of
+func (t MaterialParameterType) Components() int {
+	switch t {
+	case MaterialParameterVec2:
+		return 2
+	case MaterialParameterVec3:
+		return 3
+	case MaterialParameterVec4, MaterialParameterColor:
+		return 4
+	default:
+		return 1
+	}
+}
+
+// Default is the value a parameter has when the material doesn't set it
+func (t MaterialParameterType) Default() [4]float32 {
+	if t == MaterialParameterColor {
+		return [4]float32{1, 1, 1, 1}
+	}
+	return [4]float32{}
+}
+
+// std140Field returns the alignment and size of a field of a uniform block,
+// along with the parameter type for the fields that can be parameters
+func std140Field(fieldType string) (align, size int, t MaterialParameterType, ok bool) {
+	switch fieldType {
+	case "float":
+		return 4, 4, MaterialParameterFloat, true
+	case "int":
+		return 4, 4, MaterialParameterInt, true
+	case "vec2":
+		return 8, 8, MaterialParameterVec2, true
+	case "vec3":
+		return 16, 12, MaterialParameterVec3, true
+	case "vec4":
+		return 16, 16, MaterialParameterVec4, true
+	case "uint":
+		return 4, 4, 0, false
+	case "mat4":
+		return 16, 64, 0, false
+	default:
+		return 16, 16, 0, false
+	}
+}
+
+// MaterialParametersLayout finds the #MaterialParametersBlock of the shader
+// and returns the parameters it declares, in the order they are declared
+func MaterialParametersLayout(shaderInfo *ShaderDataCompiled) []MaterialParameter {
+	p, _ := newMaterialParameters(shaderInfo)
+	return p.fields
+}
+
+func newMaterialParameters(shaderInfo *ShaderDataCompiled) (materialParameters, bool) {
+	p := materialParameters{}
+	var block *ShaderLayout
+	for i := 0; i < len(shaderInfo.LayoutGroups) && block == nil; i++ {
+		g := &shaderInfo.LayoutGroups[i]
+		for j := range g.Layouts {
+			if g.Layouts[j].Type == MaterialParametersBlock && g.Layouts[j].Source == "uniform" {
+				block = &g.Layouts[j]
+				break
+			}
+		}
+	}
+	if block == nil {
+		return p, false
+	}
+	p.name = block.FullName()
+	offset := 0
+	for i := range block.Fields {
+		f := &block.Fields[i]
+		align, size, t, ok := std140Field(f.Type)
+		if matches := arrStringReg.FindAllStringSubmatch(f.Name, -1); len(matches) > 0 {
+			// Elements of arrays are aligned to 16 bytes in std140
+			align = 16
+			size = alignUp(size, 16)
+			for j := range matches {
+				count, _ := strconv.Atoi(matches[j][1])
+				size *= count
+			}
+			ok = false
+		}
+		offset = alignUp(offset, align)
+		if ok {
+			if t == MaterialParameterVec4 {
+				lower := strings.ToLower(f.Name)
+				if strings.HasSuffix(lower, "color") || strings.HasSuffix(lower, "tint") {
+					t = MaterialParameterColor
+				}
+			}
+			p.fields = append(p.fields, MaterialParameter{
+				Name:   f.Name,
+				Type:   t,
+				Offset: offset,
+			})
+		} else {
+			slog.Warn("the material parameter field type is not supported, it will be left as zero",
+				"field", f.Name, "type", f.Type)
+		}
+		offset += size
+	}
+	p.data = make([]byte, alignUp(offset, 16))
+	for i := range p.fields {
+		p.write(&p.fields[i], p.fields[i].Type.Default())
+	}
+	return p, true
+}
+
+func alignUp(value, align int) int {
+	return (value + align - 1) / align * align
+}
+
+func (p *materialParameters) clone() materialParameters {
+	c := *p
+	c.data = make([]byte, len(p.data))
+	copy(c.data, p.data)
+	return c
+}
+
+func (p *materialParameters) find(name string) (*MaterialParameter, bool) {
+	for i := range p.fields {
+		if p.fields[i].Name == name {
+			return &p.fields[i], true
+		}
+	}
+	return nil, false
+}
+
+// apply writes the values from the material file into the parameters, values
+// for parameters that the shader does not declare are reported and skipped
+func (p *materialParameters) apply(values []MaterialParameterData) {
+	for i := range values {
+		f, ok := p.find(values[i].Name)
+		if !ok {
+			slog.Warn("the material sets a parameter that its shader doesn't declare",
+				"parameter", values[i].Name)
+			continue
+		}
+		p.write(f, values[i].Value)
+	}
+}
+
+func (p *materialParameters) write(f *MaterialParameter, value [4]float32) {
+	if f.Type == MaterialParameterInt {
+		binary.LittleEndian.PutUint32(p.data[f.Offset:], uint32(int32(value[0])))
+		return
+	}
+	for i := range f.Type.Components() {
+		binary.LittleEndian.PutUint32(p.data[f.Offset+i*4:], math.Float32bits(value[i]))
+	}
+}
+
+func (p *materialParameters) read(f *MaterialParameter) [4]float32 {
+	value := [4]float32{}
+	if f.Type == MaterialParameterInt {
+		value[0] = float32(int32(binary.LittleEndian.Uint32(p.data[f.Offset:])))
+		return value
+	}
+	for i := range f.Type.Components() {
+		value[i] = math.Float32frombits(binary.LittleEndian.Uint32(p.data[f.Offset+i*4:]))
+	}
+	return value
+}
+
+func (p *materialParameters) set(name string, value [4]float32, t MaterialParameterType) error {
+	f, ok := p.find(name)
+	if !ok {
+		return fmt.Errorf("the material has no parameter named %s", name)
+	}
+	compatible := f.Type == t ||
+		(f.Type == MaterialParameterColor && t == MaterialParameterVec4) ||
+		(f.Type == MaterialParameterVec4 && t == MaterialParameterColor)
+	if !compatible {
+		return fmt.Errorf("the material parameter %s is a %s, not a %s", name, f.Type, t)
+	}
+	p.write(f, value)
+	return nil
+}
+
+// Parameters returns the named parameters that the shader of the material
+// declares in its #MaterialParametersBlock
+func (m *Material) Parameters() []MaterialParameter {
+	return m.parameters.fields
+}
+
+// ParameterValue returns the current value of the named parameter, an int
+// parameter is returned in the first value
+func (m *Material) ParameterValue(name string) ([4]float32, bool) {
+	f, ok := m.parameters.find(name)
+	if !ok {
+		return [4]float32{}, false
+	}
+	return m.parameters.read(f), true
+}
+
+// SetFloat sets the value of a float parameter of the material. Parameters
+// belong to the material instance, so every drawing using the instance will
+// see the new value on the next frame.
+func (m *Material) SetFloat(name string, value float32) error {
+	return m.parameters.set(name, [4]float32{value}, MaterialParameterFloat)
+}
+
+// SetInt sets the value of an int parameter of the material
+func (m *Material) SetInt(name string, value int32) error {
+	return m.parameters.set(name, [4]float32{float32(value)}, MaterialParameterInt)
+}
+
+// SetVec2 sets the value of a vec2 parameter of the material
+func (m *Material) SetVec2(name string, value matrix.Vec2) error {
+	return m.parameters.set(name, [4]float32{float32(value.X()), float32(value.Y())},
+		MaterialParameterVec2)
+}
+
+// SetVec3 sets the value of a vec3 parameter of the material
+func (m *Material) SetVec3(name string, value matrix.Vec3) error {
+	return m.parameters.set(name, [4]float32{float32(value.X()),
+		float32(value.Y()), float32(value.Z())}, MaterialParameterVec3)
+}
+
+// SetVec4 sets the value of a vec4 or color parameter of the material
+func (m *Material) SetVec4(name string, value matrix.Vec4) error {
+	return m.parameters.set(name, [4]float32{float32(value.X()), float32(value.Y()),
+		float32(value.Z()), float32(value.W())}, MaterialParameterVec4)
+}
+
+// SetColor sets the value of a color or vec4 parameter of the material
+func (m *Material) SetColor(name string, value matrix.Color) error {
+	return m.parameters.set(name, [4]float32{float32(value.R()), float32(value.G()),
+		float32(value.B()), float32(value.A())}, MaterialParameterColor)
+}
@@ -0,0 +1,214 @@
+/******************************************************************************/
+/* material_parameters_test.go                                                */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package rendering
+
+import (
+	"encoding/binary"
+	"kaiju/matrix"
+	"math"
+	"testing"
+	"weak"
+)
+
+func testParameterShader() ShaderDataCompiled {
+	return ShaderDataCompiled{
+		LayoutGroups: []ShaderLayoutGroup{{
+			Type: "Fragment",
+			Layouts: []ShaderLayout{{
+				Location: -1, Binding: 3, Set: 0, InputAttachment: -1,
+				Type: MaterialParametersBlock, Source: "uniform",
+				Fields: []ShaderLayoutStructField{
+					{Type: "float", Name: "roughness"},
+					{Type: "vec3", Name: "offset"},
+					{Type: "vec4", Name: "tint"},
+					{Type: "int", Name: "mode"},
+					{Type: "vec2", Name: "scale"},
+					{Type: "vec4", Name: "weights"},
+				},
+			}},
+		}},
+	}
+}
+
+func testParameterMaterial(t *testing.T) *Material {
+	t.Helper()
+	shader := testParameterShader()
+	p, ok := newMaterialParameters(&shader)
+	if !ok {
+		t.Fatal("expected to find the material parameters block")
+	}
+	m := &Material{
+		Name:       "test",
+		shaderInfo: shader,
+		parameters: p,
+		Instances:  make(map[string]*Material),
+	}
+	return m
+}
+
+func TestMaterialParametersLayout(t *testing.T) {
+	shader := testParameterShader()
+	fields := MaterialParametersLayout(&shader)
+	expected := []MaterialParameter{
+		{"roughness", MaterialParameterFloat, 0},
+		{"offset", MaterialParameterVec3, 16},
+		{"tint", MaterialParameterColor, 32},
+		{"mode", MaterialParameterInt, 48},
+		{"scale", MaterialParameterVec2, 56},
+		{"weights", MaterialParameterVec4, 64},
+	}
+	if len(fields) != len(expected) {
+		t.Fatalf("expected %d parameters, got %d", len(expected), len(fields))
+	}
+	for i := range expected {
+		if fields[i] != expected[i] {
+			t.Errorf("expected parameter %v, got %v", expected[i], fields[i])
+		}
+	}
+	p, _ := newMaterialParameters(&shader)
+	if len(p.data) != 80 {
+		t.Errorf("expected the block to be 80 bytes, got %d", len(p.data))
+	}
+	if len(MaterialParametersLayout(&ShaderDataCompiled{})) != 0 {
+		t.Error("expected no parameters for a shader without the block")
+	}
+}
+
+func TestMaterialParametersDefaults(t *testing.T) {
+	m := testParameterMaterial(t)
+	if v, _ := m.ParameterValue("tint"); v != [4]float32{1, 1, 1, 1} {
+		t.Errorf("expected colors to default to white, got %v", v)
+	}
+	if v, _ := m.ParameterValue("weights"); v != [4]float32{} {
+		t.Errorf("expected vectors to default to zero, got %v", v)
+	}
+	m.parameters.apply([]MaterialParameterData{
+		{Name: "roughness", Value: [4]float32{0.5}},
+		{Name: "mode", Value: [4]float32{3}},
+		{Name: "missing", Value: [4]float32{1}},
+	})
+	if v, _ := m.ParameterValue("roughness"); v[0] != 0.5 {
+		t.Errorf("expected the roughness from the file, got %v", v)
+	}
+	if got := int32(binary.LittleEndian.Uint32(m.parameters.data[48:])); got != 3 {
+		t.Errorf("expected the int to be written as an int, got %d", got)
+	}
+	if got := math.Float32frombits(binary.LittleEndian.Uint32(m.parameters.data[0:])); got != 0.5 {
+		t.Errorf("expected the float to be written at the start, got %f", got)
+	}
+}
+
+func TestMaterialParametersSet(t *testing.T) {
+	m := testParameterMaterial(t)
+	if err := m.SetColor("tint", matrix.Color{1, 0, 0, 1}); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.SetVec4("tint", matrix.Vec4{0, 1, 0, 1}); err != nil {
+		t.Errorf("expected a vec4 to be accepted for a color, got %v", err)
+	}
+	if err := m.SetVec2("scale", matrix.Vec2{2, 3}); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.SetVec3("offset", matrix.Vec3{1, 2, 3}); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.SetInt("mode", -2); err != nil {
+		t.Fatal(err)
+	}
+	if v, _ := m.ParameterValue("tint"); v != [4]float32{0, 1, 0, 1} {
+		t.Errorf("unexpected tint %v", v)
+	}
+	if v, _ := m.ParameterValue("offset"); v != [4]float32{1, 2, 3, 0} {
+		t.Errorf("unexpected offset %v", v)
+	}
+	if v, _ := m.ParameterValue("mode"); v[0] != -2 {
+		t.Errorf("unexpected mode %v", v)
+	}
+	if m.SetFloat("tint", 1) == nil {
+		t.Error("expected an error when setting a float on a color")
+	}
+	if m.SetFloat("missing", 1) == nil {
+		t.Error("expected an error when setting a missing parameter")
+	}
+}
+
+func TestMaterialParametersInstances(t *testing.T) {
+	m := testParameterMaterial(t)
+	red := []MaterialParameterData{{Name: "tint", Value: [4]float32{1, 0, 0, 1}}}
+	a := m.CreateInstanceWithParameters(nil, red)
+	b := m.CreateInstanceWithParameters(nil, red)
+	plain := m.CreateInstance(nil)
+	if a != b {
+		t.Error("expected instances with the same overrides to be shared")
+	}
+	if a == plain {
+		t.Error("expected instances with different overrides to be different")
+	}
+	if v, _ := a.ParameterValue("tint"); v != [4]float32{1, 0, 0, 1} {
+		t.Errorf("expected the override to be applied, got %v", v)
+	}
+	if v, _ := plain.ParameterValue("tint"); v != [4]float32{1, 1, 1, 1} {
+		t.Errorf("expected the instance without overrides to keep the default, got %v", v)
+	}
+	a.SetFloat("roughness", 1)
+	if v, _ := m.ParameterValue("roughness"); v[0] != 0 {
+		t.Error("expected changing an instance to not change the root material")
+	}
+	if a.Root != weak.Make(m) {
+		t.Error("expected the instance to point to its root")
+	}
+}
+
+func TestMaterialParametersDrawGroup(t *testing.T) {
+	m := testParameterMaterial(t)
+	instance := m.CreateInstance(nil)
+	instance.SetFloat("roughness", 0.25)
+	sd := &ShaderDataBasic{NewShaderDataBase(), matrix.ColorWhite()}
+	group := NewDrawInstanceGroup(&Mesh{}, sd.Size())
+	group.MaterialInstance = instance
+	group.AddInstance(sd)
+	group.AddInstance(&ShaderDataBasic{NewShaderDataBase(), matrix.ColorWhite()})
+	group.UpdateData(nil)
+	data := group.namedInstanceData[MaterialParametersBlock].bytes
+	if len(data) != len(instance.parameters.data) {
+		t.Fatalf("expected a single copy of the parameters, got %d bytes", len(data))
+	}
+	if got := math.Float32frombits(binary.LittleEndian.Uint32(data)); got != 0.25 {
+		t.Errorf("expected the instance parameters in the group data, got %f", got)
+	}
+}
@@ -0,0 +1,91 @@
+/******************************************************************************/
+/* pbr.go                                                                     */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package rendering
+
+import (
+	"kaiju/matrix"
+	"unsafe"
+)
+
+// PBRFactors are multiplied with the textures of the metallic-roughness
+// material, they follow the glTF material factors
+type PBRFactors struct {
+	BaseColor        matrix.Color
+	Emissive         matrix.Vec3
+	EmissiveStrength float32
+	Metallic         float32
+	Roughness        float32
+	NormalScale      float32
+	// OcclusionStrength of 0 ignores the occlusion map
+	OcclusionStrength float32
+	// AlphaCutoff discards opaque fragments with less alpha, 0 is no cutoff
+	AlphaCutoff float32
+}
+
+// ShaderDataPBR is the instance data for the pbr materials
+type ShaderDataPBR struct {
+	ShaderDataBase
+	PBRFactors
+	ReceiveShadows float32
+}
+
+// DefaultPBRFactors are the glTF defaults, except for the emissive color
+// being black which makes the emissive map have no effect
+func DefaultPBRFactors() PBRFactors {
+	return PBRFactors{
+		BaseColor:         matrix.ColorWhite(),
+		EmissiveStrength:  1,
+		Metallic:          1,
+		Roughness:         1,
+		NormalScale:       1,
+		OcclusionStrength: 1,
+	}
+}
+
+func NewShaderDataPBR(factors PBRFactors) *ShaderDataPBR {
+	return &ShaderDataPBR{
+		ShaderDataBase: NewShaderDataBase(),
+		PBRFactors:     factors,
+		ReceiveShadows: 1,
+	}
+}
+
+func (t ShaderDataPBR) Size() int {
+	const end = unsafe.Offsetof(ShaderDataPBR{}.ReceiveShadows) + unsafe.Sizeof(float32(0))
+	return int(end - ShaderBaseDataStart)
+}
@@ -0,0 +1,344 @@
+/******************************************************************************/
+/* post_process.go                                                            */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package rendering
+
+import (
+	"encoding/json"
+	"fmt"
+	"kaiju/engine/assets"
+	"kaiju/matrix"
+	"math"
+	"slices"
+	"unsafe"
+)
+
+const (
+	ToneMapACES   = "ACES"
+	ToneMapFilmic = "Filmic"
+	// ToneMapNone only applies the exposure
+	ToneMapNone = "None"
+)
+
+// ToneMapOperators lists the tone mapping operators, in the order they are
+// shown in the editor
+var ToneMapOperators = []string{ToneMapACES, ToneMapFilmic, ToneMapNone}
+
+const (
+	PostProcessBloom        = "Bloom"
+	PostProcessToneMapping  = "ToneMapping"
+	PostProcessColorGrading = "ColorGrading"
+	PostProcessVignette     = "Vignette"
+	PostProcessFXAA         = "FXAA"
+)
+
+// PostProcessEffects lists the effects in their default drawing order, bloom
+// and exposure work before the colors are mapped to the screen range and
+// FXAA works best on the final colors
+var PostProcessEffects = []string{PostProcessBloom, PostProcessToneMapping,
+	PostProcessColorGrading, PostProcessVignette, PostProcessFXAA}
+
+// BloomSettings adds a blurred glow around the parts of the image that are
+// brighter than the threshold. The glow is blurred at half resolution.
+type BloomSettings struct {
+	Enabled   bool
+	Threshold float32 `default:"0.8"`
+	// Knee softens the threshold so that the glow fades in, 0 is a hard cut
+	Knee      float32 `default:"0.2"`
+	Intensity float32 `default:"0.6"`
+	// Radius of the blur in pixels of the half resolution image
+	Radius float32 `default:"1"`
+}
+
+// ToneMappingSettings maps the colors through a filmic curve after scaling
+// them by the exposure
+type ToneMappingSettings struct {
+	Enabled  bool
+	Operator string `default:"ACES"`
+	// Exposure in stops, each stop doubles the brightness
+	Exposure float32
+}
+
+// ColorGradingSettings maps the colors through a 3D lookup table. The LUT
+// texture is the slices of the table laid out side by side along the blue
+// axis, a 16 entry table is a 256x16 image.
+type ColorGradingSettings struct {
+	Enabled bool
+	LUT     string
+	// Contribution blends between the original (0) and graded (1) colors
+	Contribution float32 `default:"1"`
+}
+
+// VignetteSettings darkens the edges of the screen towards the color
+type VignetteSettings struct {
+	Enabled   bool
+	Intensity float32 `default:"0.4"`
+	// Smoothness is how far in from the corners the vignette fades
+	Smoothness float32 `default:"0.5"`
+	// Roundness of 1 is a circle, 0 follows the aspect ratio of the screen
+	Roundness float32 `default:"1"`
+	Color     matrix.Color
+}
+
+// FXAASettings smooths the jagged edges of the final image
+type FXAASettings struct {
+	Enabled bool
+	// EdgeThreshold is the contrast, relative to the brightest pixel, that
+	// an edge needs to be smoothed
+	EdgeThreshold float32 `default:"0.125"`
+	// EdgeThresholdMin skips edges in dark areas below this contrast
+	EdgeThresholdMin float32 `default:"0.0312"`
+	// SpanMax is the furthest in pixels that an edge is searched along
+	SpanMax float32 `default:"8"`
+}
+
+// PostProcessData is the post-processing stack that is drawn over the final
+// image. The effects are drawn in the order of Effects, effects that are not
+// listed or are disabled are skipped. Each effect is drawn by the materials
+// listed in postProcessEffects, so the shaders and render passes can be
+// changed by editing those assets.
+type PostProcessData struct {
+	Name string
+	// Effects are the names of the effects that are drawn, in the order they
+	// are drawn
+	Effects      []string
+	Bloom        BloomSettings
+	ToneMapping  ToneMappingSettings
+	ColorGrading ColorGradingSettings
+	Vignette     VignetteSettings
+	FXAA         FXAASettings
+}
+
+// DefaultPostProcessData has every effect disabled with their default
+// settings
+func DefaultPostProcessData() PostProcessData {
+	return PostProcessData{
+		Effects: slices.Clone(PostProcessEffects),
+		Bloom: BloomSettings{
+			Threshold: 0.8,
+			Knee:      0.2,
+			Intensity: 0.6,
+			Radius:    1,
+		},
+		ToneMapping: ToneMappingSettings{
+			Operator: ToneMapACES,
+		},
+		ColorGrading: ColorGradingSettings{
+			Contribution: 1,
+		},
+		Vignette: VignetteSettings{
+			Intensity:  0.4,
+			Smoothness: 0.5,
+			Roundness:  1,
+			Color:      matrix.ColorBlack(),
+		},
+		FXAA: FXAASettings{
+			EdgeThreshold:    0.125,
+			EdgeThresholdMin: 0.0312,
+			SpanMax:          8,
+		},
+	}
+}
+
+// LoadPostProcessData reads the post-processing asset, fields missing from
+// the file keep the values of DefaultPostProcessData
+func LoadPostProcessData(db *assets.Database, key string) (PostProcessData, error) {
+	data := DefaultPostProcessData()
+	str, err := db.ReadText(key)
+	if err != nil {
+		return data, err
+	}
+	if err = json.Unmarshal([]byte(str), &data); err != nil {
+		return data, err
+	}
+	return data, data.validateEffects()
+}
+
+// validateEffects checks that every effect in Effects is known and is only
+// listed once
+func (d *PostProcessData) validateEffects() error {
+	for i, name := range d.Effects {
+		if findPostProcessEffect(name) < 0 {
+			return fmt.Errorf("unknown post-processing effect '%s'", name)
+		}
+		if slices.Contains(d.Effects[:i], name) {
+			return fmt.Errorf("the post-processing effect '%s' is listed more than once", name)
+		}
+	}
+	return nil
+}
+
+// ShaderDataPostProcess is the instance data of the full screen quad that
+// draws a post-processing pass, the meaning of the parameters depends on the
+// pass
+type ShaderDataPostProcess struct {
+	ShaderDataBase
+	Params0 matrix.Vec4
+	Params1 matrix.Vec4
+}
+
+func (t ShaderDataPostProcess) Size() int {
+	return int(unsafe.Sizeof(ShaderDataPostProcess{}) - ShaderBaseDataStart)
+}
+
+type postProcessPass struct {
+	material string
+	// inputs are the images sampled by the pass, in the order of the
+	// material's textures. -1 is the image going into the effect and other
+	// values are the outputs of the earlier passes of the same effect.
+	inputs []int
+}
+
+type postProcessEffect struct {
+	name    string
+	passes  []postProcessPass
+	enabled func(d *PostProcessData) bool
+	params  func(d *PostProcessData, pass int) (matrix.Vec4, matrix.Vec4)
+	// texture is an extra texture file sampled after the inputs
+	texture func(d *PostProcessData) string
+}
+
+// postProcessEffects are the passes and parameters of every effect, the
+// effects that are drawn and their order come from PostProcessData.Effects
+var postProcessEffects = []postProcessEffect{
+	{
+		name: PostProcessBloom,
+		passes: []postProcessPass{
+			{assets.MaterialDefinitionPostBloomExtract, []int{-1}},
+			{assets.MaterialDefinitionPostBloomBlurH, []int{0}},
+			{assets.MaterialDefinitionPostBloomBlurV, []int{1}},
+			{assets.MaterialDefinitionPostBloom, []int{-1, 2}},
+		},
+		enabled: func(d *PostProcessData) bool { return d.Bloom.Enabled },
+		params: func(d *PostProcessData, pass int) (matrix.Vec4, matrix.Vec4) {
+			b := &d.Bloom
+			radius := matrix.Float(b.Radius)
+			switch pass {
+			case 1:
+				return matrix.Vec4{radius, 0, 0, 0}, matrix.Vec4{}
+			case 2:
+				return matrix.Vec4{0, radius, 0, 0}, matrix.Vec4{}
+			}
+			return matrix.Vec4{matrix.Float(b.Threshold), matrix.Float(b.Knee),
+				matrix.Float(b.Intensity), 0}, matrix.Vec4{}
+		},
+	},
+	{
+		name:    PostProcessToneMapping,
+		passes:  []postProcessPass{{assets.MaterialDefinitionPostToneMapping, []int{-1}}},
+		enabled: func(d *PostProcessData) bool { return d.ToneMapping.Enabled },
+		params: func(d *PostProcessData, pass int) (matrix.Vec4, matrix.Vec4) {
+			t := &d.ToneMapping
+			operator := matrix.Float(0)
+			switch t.Operator {
+			case ToneMapFilmic:
+				operator = 1
+			case ToneMapNone:
+				operator = 2
+			}
+			exposure := matrix.Float(math.Exp2(float64(t.Exposure)))
+			return matrix.Vec4{exposure, operator, 0, 0}, matrix.Vec4{}
+		},
+	},
+	{
+		name:   PostProcessColorGrading,
+		passes: []postProcessPass{{assets.MaterialDefinitionPostColorGrading, []int{-1}}},
+		enabled: func(d *PostProcessData) bool {
+			return d.ColorGrading.Enabled && d.ColorGrading.LUT != ""
+		},
+		params: func(d *PostProcessData, pass int) (matrix.Vec4, matrix.Vec4) {
+			return matrix.Vec4{matrix.Float(d.ColorGrading.Contribution), 0, 0, 0}, matrix.Vec4{}
+		},
+		texture: func(d *PostProcessData) string { return d.ColorGrading.LUT },
+	},
+	{
+		name:    PostProcessVignette,
+		passes:  []postProcessPass{{assets.MaterialDefinitionPostVignette, []int{-1}}},
+		enabled: func(d *PostProcessData) bool { return d.Vignette.Enabled },
+		params: func(d *PostProcessData, pass int) (matrix.Vec4, matrix.Vec4) {
+			v := &d.Vignette
+			return matrix.Vec4{matrix.Float(v.Intensity), matrix.Float(v.Smoothness),
+				matrix.Float(v.Roundness), 0}, matrix.Vec4(v.Color)
+		},
+	},
+	{
+		name:    PostProcessFXAA,
+		passes:  []postProcessPass{{assets.MaterialDefinitionPostFXAA, []int{-1}}},
+		enabled: func(d *PostProcessData) bool { return d.FXAA.Enabled },
+		params: func(d *PostProcessData, pass int) (matrix.Vec4, matrix.Vec4) {
+			f := &d.FXAA
+			return matrix.Vec4{matrix.Float(f.EdgeThreshold),
+				matrix.Float(f.EdgeThresholdMin), matrix.Float(f.SpanMax), 0}, matrix.Vec4{}
+		},
+	},
+}
+
+func findPostProcessEffect(name string) int {
+	return slices.IndexFunc(postProcessEffects, func(e postProcessEffect) bool {
+		return e.name == name
+	})
+}
+
+// postProcessLayout is what decides which passes are drawn, in which order
+// and what they sample, the passes are rebuilt when it changes
+type postProcessLayout struct {
+	// effects are the indices of the enabled effects in postProcessEffects,
+	// in the order they are drawn, only the first count are used
+	effects  [8]int
+	textures [8]string
+	count    int
+}
+
+func (d *PostProcessData) layout() postProcessLayout {
+	l := postProcessLayout{}
+	for _, name := range d.Effects {
+		i := findPostProcessEffect(name)
+		if i < 0 || l.count == len(l.effects) || slices.Contains(l.effects[:l.count], i) {
+			continue
+		}
+		e := &postProcessEffects[i]
+		if !e.enabled(d) {
+			continue
+		}
+		l.effects[l.count] = i
+		if e.texture != nil {
+			l.textures[l.count] = e.texture(d)
+		}
+		l.count++
+	}
+	return l
+}
@@ -0,0 +1,180 @@
+/******************************************************************************/
+/* post_process_test.go                                                       */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package rendering
+
+import (
+	"encoding/json"
+	"kaiju/matrix"
+	"slices"
+	"testing"
+
+	vk "kaiju/rendering/vulkan"
+)
+
+func TestShaderDataPostProcessSize(t *testing.T) {
+	// Matches the params0 and params1 vec4 inputs of postprocess.vert
+	if s := (ShaderDataPostProcess{}).Size(); s != 96 {
+		t.Errorf("expected the instance data to be 96 bytes, got %d", s)
+	}
+}
+
+func TestPostProcessLayout(t *testing.T) {
+	d := DefaultPostProcessData()
+	if empty := d.layout(); empty.count != 0 {
+		t.Errorf("expected every effect to be disabled by default, got %d", empty.count)
+	}
+	d.Bloom.Enabled = true
+	d.ColorGrading.Enabled = true
+	l := d.layout()
+	if l.count != 1 || l.effects[0] != findPostProcessEffect(PostProcessBloom) {
+		t.Error("expected only bloom to be enabled, color grading without a LUT is skipped")
+	}
+	d.ColorGrading.LUT = "textures/lut_neutral.png"
+	l2 := d.layout()
+	if l2 == l || l2.count != 2 || l2.textures[1] != d.ColorGrading.LUT {
+		t.Error("expected setting the LUT to change the layout")
+	}
+	d.Bloom.Intensity = 2
+	if d.layout() != l2 {
+		t.Error("expected changing a parameter to keep the layout")
+	}
+}
+
+func TestPostProcessLayoutOrder(t *testing.T) {
+	d := DefaultPostProcessData()
+	d.Bloom.Enabled = true
+	d.Vignette.Enabled = true
+	d.FXAA.Enabled = true
+	d.Effects = []string{PostProcessFXAA, PostProcessVignette, "Unknown", PostProcessFXAA}
+	l := d.layout()
+	expected := []int{findPostProcessEffect(PostProcessFXAA), findPostProcessEffect(PostProcessVignette)}
+	if !slices.Equal(l.effects[:l.count], expected) {
+		t.Errorf("expected the effects %v in the listed order, got %v", expected, l.effects[:l.count])
+	}
+}
+
+func TestPostProcessDataEffects(t *testing.T) {
+	d := DefaultPostProcessData()
+	if !slices.Equal(d.Effects, PostProcessEffects) {
+		t.Errorf("expected the default effects %v, got %v", PostProcessEffects, d.Effects)
+	}
+	if err := json.Unmarshal([]byte(`{"Effects":["Vignette","Bloom"]}`), &d); err != nil {
+		t.Fatal(err)
+	}
+	if err := d.validateEffects(); err != nil {
+		t.Errorf("expected the effects to be valid, got %v", err)
+	}
+	if !slices.Equal(d.Effects, []string{PostProcessVignette, PostProcessBloom}) {
+		t.Errorf("expected the effects from the file, got %v", d.Effects)
+	}
+	d.Effects = []string{PostProcessBloom, "Blur"}
+	if err := d.validateEffects(); err == nil {
+		t.Error("expected an error for an unknown effect")
+	}
+	d.Effects = []string{PostProcessBloom, PostProcessBloom}
+	if err := d.validateEffects(); err == nil {
+		t.Error("expected an error for an effect that is listed twice")
+	}
+}
+
+func TestPostProcessParams(t *testing.T) {
+	d := DefaultPostProcessData()
+	d.ToneMapping.Exposure = 1
+	d.ToneMapping.Operator = ToneMapFilmic
+	p0, _ := postProcessEffects[findPostProcessEffect(PostProcessToneMapping)].params(&d, 0)
+	if !matrix.Approx(p0.X(), 2) || p0.Y() != 1 {
+		t.Errorf("expected an exposure scale of 2 and the filmic operator, got %v", p0)
+	}
+	d.Bloom.Radius = 3
+	h, _ := postProcessEffects[findPostProcessEffect(PostProcessBloom)].params(&d, 1)
+	v, _ := postProcessEffects[findPostProcessEffect(PostProcessBloom)].params(&d, 2)
+	if h != (matrix.Vec4{3, 0, 0, 0}) || v != (matrix.Vec4{0, 3, 0, 0}) {
+		t.Errorf("expected the blur passes to blur along x then y, got %v and %v", h, v)
+	}
+	d.Vignette.Color = matrix.ColorRed()
+	_, p1 := postProcessEffects[findPostProcessEffect(PostProcessVignette)].params(&d, 0)
+	if p1 != matrix.Vec4(matrix.ColorRed()) {
+		t.Errorf("expected the vignette color in the second parameter, got %v", p1)
+	}
+}
+
+func TestPostProcessDataKeepsDefaults(t *testing.T) {
+	d := DefaultPostProcessData()
+	src := `{"Name":"test","Bloom":{"Enabled":true,"Intensity":1.5}}`
+	if err := json.Unmarshal([]byte(src), &d); err != nil {
+		t.Fatal(err)
+	}
+	if !d.Bloom.Enabled || d.Bloom.Intensity != 1.5 {
+		t.Error("expected the bloom settings from the file")
+	}
+	if d.Bloom.Threshold != 0.8 || d.FXAA.SpanMax != 8 {
+		t.Error("expected the fields missing from the file to keep their defaults")
+	}
+}
+
+func TestApplyHDRFormat(t *testing.T) {
+	color := vk.ImageUsageFlags(vk.ImageUsageColorAttachmentBit)
+	c := RenderPassDataCompiled{
+		Name: "transparent",
+		AttachmentDescriptions: []RenderPassAttachmentDescriptionCompiled{
+			{Format: vk.FormatR16g16b16a16Sfloat, Image: RenderPassAttachmentImageCompiled{Usage: color}},
+			{Format: vk.FormatR16Sfloat, Image: RenderPassAttachmentImageCompiled{Usage: color}},
+			{Format: vk.FormatR8g8b8a8Unorm, Image: RenderPassAttachmentImageCompiled{ExistingImage: "opaque.color"}},
+			{Format: vk.FormatD32Sfloat, Image: RenderPassAttachmentImageCompiled{ExistingImage: "opaque.depth"}},
+		},
+	}
+	applyHDRFormat(&c)
+	expected := []vk.Format{vk.FormatR16g16b16a16Sfloat, vk.FormatR16Sfloat,
+		vk.FormatR16g16b16a16Sfloat, vk.FormatD32Sfloat}
+	for i := range expected {
+		if c.AttachmentDescriptions[i].Format != expected[i] {
+			t.Errorf("attachment %d expected format %d, got %d", i,
+				expected[i], c.AttachmentDescriptions[i].Format)
+		}
+	}
+	ui := RenderPassDataCompiled{
+		Name: "ui_opaque",
+		AttachmentDescriptions: []RenderPassAttachmentDescriptionCompiled{
+			{Format: vk.FormatR8g8b8a8Unorm, Image: RenderPassAttachmentImageCompiled{Usage: color}},
+		},
+	}
+	applyHDRFormat(&ui)
+	if ui.AttachmentDescriptions[0].Format != vk.FormatR8g8b8a8Unorm {
+		t.Error("expected passes that don't draw the scene to keep their format")
+	}
+}
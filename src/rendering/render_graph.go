@@ -0,0 +1,338 @@
+/******************************************************************************/
+/* render_graph.go                                                            */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package rendering
+
+import (
+	"encoding/json"
+	"fmt"
+	vk "kaiju/rendering/vulkan"
+	"slices"
+	"strings"
+)
+
+// RenderGraphDefault is the render graph that the renderer loads when it is
+// created, projects can replace it to add their own render passes
+const RenderGraphDefault = "renderer/graphs/default.rendergraph"
+
+// renderGraphSortScale is the room given to the passes of the graph between
+// two Sort values of the render passes. Once there is a render graph every
+// Sort is multiplied by it so that the graph order fits between them.
+const renderGraphSortScale = 1024
+
+// RenderGraphData is the description of the render passes that make up a frame
+// and how the images of one pass feed into the others. The passes can be
+// declared in any order, the graph is sorted using the images they read and
+// the passes they depend on.
+//
+// The graph only orders and validates the passes, it does not create their
+// attachments. The attachments, their formats, layouts and load operations
+// come from the .renderpass file of each pass, which has to agree with the
+// images the graph declares for it (see #RenderGraphPass.validateRenderPass).
+type RenderGraphData struct {
+	Name   string
+	Passes []RenderGraphPassData
+}
+
+// RenderGraphPassData declares a single render pass within the render graph
+type RenderGraphPassData struct {
+	// Name is the name of the render pass, it matches the Name found within
+	// the .renderpass file
+	Name string
+	// RenderPass is the asset key of the .renderpass file for the pass
+	RenderPass string
+	// Reads are the images of other passes that this pass loads as its own
+	// attachments, through the ExistingImage of the attachment
+	Reads []string
+	// Samples are the images of other passes that the shaders of this pass
+	// sample. They are transitioned to a read only layout before the pass is
+	// drawn and back to an attachment layout after it.
+	Samples []string
+	// Writes are the images of this pass that other passes read or sample
+	Writes []string
+	// Transient are the images of this pass that are only used while the pass
+	// is drawn, their contents are not stored after the pass ends
+	Transient []string
+	// DependsOn are the names of the passes that need to be drawn before this
+	// one even though they don't share any images
+	DependsOn []string
+}
+
+// RenderGraphBarrier is an image written by one pass that is sampled by
+// another, the image layout is transitioned around the pass that samples it
+type RenderGraphBarrier struct {
+	Image  string
+	Writer string
+}
+
+// RenderGraphPass is a pass within the sorted render graph
+type RenderGraphPass struct {
+	RenderGraphPassData
+	// Order is the position of the pass within the sorted graph
+	Order int
+	// Sort replaces the Sort of the render pass, it keeps the passes in the
+	// graph order while passes outside of the graph (like the shadow pass)
+	// are still drawn before or after them based on their own Sort
+	Sort     int
+	Barriers []RenderGraphBarrier
+}
+
+// RenderGraph is the compiled form of #RenderGraphData, the passes are sorted
+// so that each pass comes after all of the passes it depends on
+type RenderGraph struct {
+	Name   string
+	Passes []RenderGraphPass
+	lookup map[string]int
+}
+
+func NewRenderGraphData(src string) (RenderGraphData, error) {
+	var g RenderGraphData
+	err := json.Unmarshal([]byte(src), &g)
+	return g, err
+}
+
+// Compile validates the graph and sorts the passes. Passes that don't depend
+// on each other keep the order they were declared in. An error is returned
+// if a pass reads an image that no pass writes, if an image is written by
+// more than one pass, or if the dependencies form a cycle.
+func (d *RenderGraphData) Compile() (RenderGraph, error) {
+	g := RenderGraph{Name: d.Name}
+	count := len(d.Passes)
+	if count >= renderGraphSortScale {
+		return g, fmt.Errorf("the render graph has %d passes, it can have at most %d",
+			count, renderGraphSortScale-1)
+	}
+	index := make(map[string]int, count)
+	for i := range d.Passes {
+		p := &d.Passes[i]
+		if p.Name == "" {
+			return g, fmt.Errorf("render graph pass %d is missing a name", i)
+		}
+		if _, ok := index[p.Name]; ok {
+			return g, fmt.Errorf("the render pass %s is declared more than once", p.Name)
+		}
+		index[p.Name] = i
+	}
+	writers := make(map[string]int)
+	for i := range d.Passes {
+		p := &d.Passes[i]
+		for _, img := range p.Writes {
+			if w, ok := writers[img]; ok {
+				return g, fmt.Errorf("the image %s is written by both %s and %s",
+					img, d.Passes[w].Name, p.Name)
+			}
+			if slices.Contains(p.Transient, img) {
+				return g, fmt.Errorf("the image %s of %s can't be both written and transient",
+					img, p.Name)
+			}
+			writers[img] = i
+		}
+	}
+	edges := make([][]int, count)
+	inDegree := make([]int, count)
+	link := func(from, to int) error {
+		if from == to {
+			return fmt.Errorf("the render pass %s depends on itself", d.Passes[to].Name)
+		}
+		if !slices.Contains(edges[from], to) {
+			edges[from] = append(edges[from], to)
+			inDegree[to]++
+		}
+		return nil
+	}
+	for i := range d.Passes {
+		p := &d.Passes[i]
+		for _, img := range slices.Concat(p.Reads, p.Samples) {
+			w, ok := writers[img]
+			if !ok {
+				return g, fmt.Errorf("the render pass %s reads the image %s which no pass writes",
+					p.Name, img)
+			}
+			if err := link(w, i); err != nil {
+				return g, err
+			}
+		}
+		for _, dep := range p.DependsOn {
+			from, ok := index[dep]
+			if !ok {
+				return g, fmt.Errorf("the render pass %s depends on the unknown pass %s",
+					p.Name, dep)
+			}
+			if err := link(from, i); err != nil {
+				return g, err
+			}
+		}
+	}
+	// Kahn's algorithm, always taking the first ready pass in declaration
+	// order so that the result is stable
+	done := make([]bool, count)
+	g.Passes = make([]RenderGraphPass, 0, count)
+	g.lookup = make(map[string]int, count)
+	for len(g.Passes) < count {
+		next := -1
+		for i := 0; i < count && next < 0; i++ {
+			if !done[i] && inDegree[i] == 0 {
+				next = i
+			}
+		}
+		if next < 0 {
+			remaining := make([]string, 0, count-len(g.Passes))
+			for i := range d.Passes {
+				if !done[i] {
+					remaining = append(remaining, d.Passes[i].Name)
+				}
+			}
+			return g, fmt.Errorf("the render graph has a dependency cycle between: %s",
+				strings.Join(remaining, ", "))
+		}
+		done[next] = true
+		for _, to := range edges[next] {
+			inDegree[to]--
+		}
+		p := RenderGraphPass{
+			RenderGraphPassData: d.Passes[next],
+			Order:               len(g.Passes),
+			Sort:                len(g.Passes),
+		}
+		for _, img := range p.Samples {
+			p.Barriers = append(p.Barriers, RenderGraphBarrier{
+				Image:  img,
+				Writer: d.Passes[writers[img]].Name,
+			})
+		}
+		g.lookup[p.Name] = len(g.Passes)
+		g.Passes = append(g.Passes, p)
+	}
+	return g, nil
+}
+
+// Pass returns the pass in the graph with the given render pass name
+func (g *RenderGraph) Pass(name string) (*RenderGraphPass, bool) {
+	idx, ok := g.lookup[name]
+	if !ok {
+		return nil, false
+	}
+	return &g.Passes[idx], true
+}
+
+// Order returns the names of the passes in the order they are drawn
+func (g *RenderGraph) Order() []string {
+	names := make([]string, len(g.Passes))
+	for i := range g.Passes {
+		names[i] = g.Passes[i].Name
+	}
+	return names
+}
+
+// validateRenderPass checks that the .renderpass file of the pass uses the
+// images the graph declares for it. Every image it loads through an
+// ExistingImage has to be one of Reads and every one of Reads has to be
+// loaded, while the images of Writes and Transient have to be created by it.
+func (p *RenderGraphPass) validateRenderPass(rp *RenderPassData) error {
+	if rp.Name != p.Name {
+		return fmt.Errorf("the render graph pass %s points to the render pass %s", p.Name, rp.Name)
+	}
+	loads := make([]string, 0, len(rp.AttachmentDescriptions))
+	owns := make([]string, 0, len(rp.AttachmentDescriptions))
+	for i := range rp.AttachmentDescriptions {
+		img := &rp.AttachmentDescriptions[i].Image
+		if img.ExistingImage != "" {
+			loads = append(loads, img.ExistingImage)
+		} else if img.Name != "" {
+			owns = append(owns, img.Name)
+		}
+	}
+	for _, img := range loads {
+		if !slices.Contains(p.Reads, img) {
+			return fmt.Errorf("the render pass %s loads the image %s that the render graph doesn't declare it reads", p.Name, img)
+		}
+	}
+	for _, img := range p.Reads {
+		if !slices.Contains(loads, img) {
+			return fmt.Errorf("the render graph declares that %s reads the image %s but its render pass doesn't load it", p.Name, img)
+		}
+	}
+	for _, img := range slices.Concat(p.Writes, p.Transient) {
+		if !slices.Contains(owns, img) {
+			return fmt.Errorf("the render graph declares that %s creates the image %s but its render pass doesn't have it", p.Name, img)
+		}
+	}
+	return nil
+}
+
+// resolveSorts places the passes of the graph among the passes outside of it
+// using the Sort declared by their render pass, which sorts returns. Each
+// pass is placed at the highest Sort of itself and the passes before it in
+// the graph, so a pass is never placed before a pass it depends on.
+func (g *RenderGraph) resolveSorts(sorts func(p *RenderGraphPass) (int, bool)) {
+	anchor, found := 0, false
+	for i := range g.Passes {
+		p := &g.Passes[i]
+		if s, ok := sorts(p); ok && (!found || s > anchor) {
+			anchor, found = s, true
+		}
+		p.Sort = anchor*renderGraphSortScale + p.Order
+	}
+}
+
+// applyTo replaces the sort of a render pass that is in the graph with its
+// resolved sort, and scales the sort of the passes outside of the graph so
+// they are drawn after the graph passes placed at the same Sort. The images
+// the graph marks as transient are not stored at the end of the pass, and
+// when nothing outside of the pass can use them they are created as
+// transient attachments.
+func (g *RenderGraph) applyTo(c *RenderPassDataCompiled) {
+	p, ok := g.Pass(c.Name)
+	if !ok {
+		c.Sort = c.Sort*renderGraphSortScale + renderGraphSortScale - 1
+		return
+	}
+	c.Sort = p.Sort
+	const persistentUsage = vk.ImageUsageSampledBit | vk.ImageUsageStorageBit |
+		vk.ImageUsageTransferSrcBit | vk.ImageUsageTransferDstBit
+	for i := range c.AttachmentDescriptions {
+		a := &c.AttachmentDescriptions[i]
+		if a.Image.IsInvalid() || !slices.Contains(p.Transient, a.Image.Name) {
+			continue
+		}
+		a.StoreOp = vk.AttachmentStoreOpDontCare
+		a.StencilStoreOp = vk.AttachmentStoreOpDontCare
+		if a.Image.Usage&vk.ImageUsageFlags(persistentUsage) == 0 {
+			a.Image.Usage |= vk.ImageUsageFlags(vk.ImageUsageTransientAttachmentBit)
+		}
+	}
+}
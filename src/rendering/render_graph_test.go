@@ -0,0 +1,284 @@
+/******************************************************************************/
+/* render_graph_test.go                                                       */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package rendering
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	vk "kaiju/rendering/vulkan"
+)
+
+func testRenderGraph(t *testing.T, src string) (RenderGraph, error) {
+	t.Helper()
+	data, err := NewRenderGraphData(src)
+	if err != nil {
+		t.Fatalf("failed to parse the render graph: %v", err)
+	}
+	return data.Compile()
+}
+
+func TestRenderGraphSortsByImages(t *testing.T) {
+	g, err := testRenderGraph(t, `{"Name":"test","Passes":[
+		{"Name":"combine","DependsOn":["transparent","outline"]},
+		{"Name":"transparent","Reads":["opaque.color","opaque.depth"]},
+		{"Name":"outline","Samples":["opaque.depth"]},
+		{"Name":"opaque","Writes":["opaque.color","opaque.depth"]}
+	]}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{"opaque", "transparent", "outline", "combine"}
+	if got := g.Order(); !slices.Equal(got, expected) {
+		t.Errorf("expected the order %v, got %v", expected, got)
+	}
+	p, ok := g.Pass("outline")
+	if !ok {
+		t.Fatal("expected to find the outline pass")
+	}
+	if p.Order != 2 {
+		t.Errorf("expected the outline pass to be third, got %d", p.Order)
+	}
+	if len(p.Barriers) != 1 || p.Barriers[0] != (RenderGraphBarrier{"opaque.depth", "opaque"}) {
+		t.Errorf("expected a barrier for the sampled opaque depth, got %v", p.Barriers)
+	}
+	if p, _ := g.Pass("transparent"); len(p.Barriers) != 0 {
+		t.Errorf("expected loaded images to not need barriers, got %v", p.Barriers)
+	}
+}
+
+func TestRenderGraphKeepsDeclarationOrder(t *testing.T) {
+	g, err := testRenderGraph(t, `{"Passes":[
+		{"Name":"c"},{"Name":"a"},{"Name":"b","DependsOn":["c"]}
+	]}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := g.Order(); !slices.Equal(got, []string{"c", "a", "b"}) {
+		t.Errorf("expected independent passes to keep their order, got %v", got)
+	}
+}
+
+func TestRenderGraphErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		err  string
+	}{
+		{"cycle", `{"Passes":[{"Name":"a","DependsOn":["b"]},{"Name":"b","DependsOn":["a"]}]}`, "cycle"},
+		{"self", `{"Passes":[{"Name":"a","DependsOn":["a"]}]}`, "itself"},
+		{"unknown", `{"Passes":[{"Name":"a","DependsOn":["b"]}]}`, "unknown pass"},
+		{"unwritten", `{"Passes":[{"Name":"a","Reads":["b.color"]}]}`, "no pass writes"},
+		{"writers", `{"Passes":[{"Name":"a","Writes":["c"]},{"Name":"b","Writes":["c"]}]}`, "written by both"},
+		{"duplicate", `{"Passes":[{"Name":"a"},{"Name":"a"}]}`, "more than once"},
+		{"transient", `{"Passes":[{"Name":"a","Writes":["c"],"Transient":["c"]}]}`, "transient"},
+	}
+	for _, test := range tests {
+		_, err := testRenderGraph(t, test.src)
+		if err == nil || !strings.Contains(err.Error(), test.err) {
+			t.Errorf("%s: expected an error containing %q, got %v", test.name, test.err, err)
+		}
+	}
+}
+
+func TestRenderGraphApplyTo(t *testing.T) {
+	g, err := testRenderGraph(t, `{"Passes":[
+		{"Name":"opaque"},
+		{"Name":"transparent","DependsOn":["opaque"],"Transient":["accum","reveal"]}
+	]}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	image := func(name string, usage vk.ImageUsageFlagBits) RenderPassAttachmentDescriptionCompiled {
+		return RenderPassAttachmentDescriptionCompiled{
+			StoreOp: vk.AttachmentStoreOpStore,
+			Image: RenderPassAttachmentImageCompiled{
+				Name:       name,
+				MipLevels:  1,
+				LayerCount: 1,
+				Usage:      vk.ImageUsageFlags(vk.ImageUsageColorAttachmentBit | usage),
+			},
+		}
+	}
+	c := RenderPassDataCompiled{
+		Name: "transparent",
+		Sort: 50,
+		AttachmentDescriptions: []RenderPassAttachmentDescriptionCompiled{
+			image("accum", vk.ImageUsageInputAttachmentBit),
+			image("reveal", vk.ImageUsageSampledBit),
+			image("color", 0),
+		},
+	}
+	g.applyTo(&c)
+	if c.Sort != 1 {
+		t.Errorf("expected the sort to be replaced by the graph order, got %d", c.Sort)
+	}
+	a := c.AttachmentDescriptions
+	if a[0].StoreOp != vk.AttachmentStoreOpDontCare || a[1].StoreOp != vk.AttachmentStoreOpDontCare {
+		t.Error("expected transient images to not be stored")
+	}
+	if a[2].StoreOp != vk.AttachmentStoreOpStore {
+		t.Error("expected other images to still be stored")
+	}
+	transient := vk.ImageUsageFlags(vk.ImageUsageTransientAttachmentBit)
+	if a[0].Image.Usage&transient == 0 {
+		t.Error("expected the attachment only image to be a transient attachment")
+	}
+	if a[1].Image.Usage&transient != 0 {
+		t.Error("expected the sampled image to not be a transient attachment")
+	}
+	other := RenderPassDataCompiled{Name: "shadow", Sort: -100}
+	g.applyTo(&other)
+	if other.Sort >= g.Passes[0].Sort {
+		t.Errorf("expected passes outside of the graph to keep their place, got %d", other.Sort)
+	}
+}
+
+func TestRenderGraphResolveSorts(t *testing.T) {
+	g, err := testRenderGraph(t, `{"Passes":[
+		{"Name":"outline"},
+		{"Name":"opaque"},
+		{"Name":"transparent","DependsOn":["opaque"]},
+		{"Name":"ui","DependsOn":["transparent"]},
+		{"Name":"combine","DependsOn":["outline","ui"]},
+		{"Name":"custom","DependsOn":["combine"]}
+	]}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	declared := map[string]int{
+		"outline":     -2,
+		"opaque":      -1,
+		"transparent": 0,
+		"ui":          1000,
+		"combine":     0,
+	}
+	g.resolveSorts(func(p *RenderGraphPass) (int, bool) {
+		s, ok := declared[p.Name]
+		return s, ok
+	})
+	sorts := map[string]int{}
+	for _, p := range g.Passes {
+		c := RenderPassDataCompiled{Name: p.Name, Sort: declared[p.Name]}
+		g.applyTo(&c)
+		sorts[p.Name] = c.Sort
+	}
+	for _, o := range []struct {
+		name string
+		sort int
+	}{{"shadow", -100}, {"render_target", -50}, {"postprocess", 0}, {"late", 2000}} {
+		c := RenderPassDataCompiled{Name: o.name, Sort: o.sort}
+		g.applyTo(&c)
+		sorts[o.name] = c.Sort
+	}
+	order := []string{"shadow", "render_target", "outline", "opaque",
+		"transparent", "postprocess", "ui", "combine", "custom", "late"}
+	for i := 1; i < len(order); i++ {
+		if sorts[order[i-1]] >= sorts[order[i]] {
+			t.Errorf("expected %s (%d) to be drawn before %s (%d)", order[i-1],
+				sorts[order[i-1]], order[i], sorts[order[i]])
+		}
+	}
+}
+
+func TestRenderGraphValidateRenderPass(t *testing.T) {
+	existing := func(img string) RenderPassAttachmentDescription {
+		a := RenderPassAttachmentDescription{}
+		a.Image.ExistingImage = img
+		return a
+	}
+	owned := func(img string) RenderPassAttachmentDescription {
+		a := RenderPassAttachmentDescription{}
+		a.Image.Name = img
+		return a
+	}
+	rp := RenderPassData{
+		Name:                   "b",
+		AttachmentDescriptions: []RenderPassAttachmentDescription{owned("b.color"), existing("a.depth")},
+	}
+	tests := []struct {
+		name string
+		pass RenderGraphPassData
+		err  string
+	}{
+		{"valid", RenderGraphPassData{Name: "b", Reads: []string{"a.depth"}, Writes: []string{"b.color"}}, ""},
+		{"name", RenderGraphPassData{Name: "c", Reads: []string{"a.depth"}}, "points to"},
+		{"undeclared", RenderGraphPassData{Name: "b"}, "doesn't declare"},
+		{"unloaded", RenderGraphPassData{Name: "b", Reads: []string{"a.depth", "a.color"}}, "doesn't load"},
+		{"unowned", RenderGraphPassData{Name: "b", Reads: []string{"a.depth"}, Transient: []string{"b.reveal"}}, "doesn't have"},
+	}
+	for _, test := range tests {
+		p := RenderGraphPass{RenderGraphPassData: test.pass}
+		err := p.validateRenderPass(&rp)
+		if test.err == "" && err != nil {
+			t.Errorf("%s: expected no error, got %v", test.name, err)
+		} else if test.err != "" && (err == nil || !strings.Contains(err.Error(), test.err)) {
+			t.Errorf("%s: expected an error containing %q, got %v", test.name, test.err, err)
+		}
+	}
+}
+
+func TestRenderGraphDefaultMatchesRenderPasses(t *testing.T) {
+	root := filepath.Join("..", "..")
+	src, err := os.ReadFile(filepath.Join(root, "content", RenderGraphDefault))
+	if err != nil {
+		t.Fatalf("failed to read the default render graph: %v", err)
+	}
+	g, err := testRenderGraph(t, string(src))
+	if err != nil {
+		t.Fatalf("failed to compile the default render graph: %v", err)
+	}
+	for i := range g.Passes {
+		p := &g.Passes[i]
+		data, err := os.ReadFile(filepath.Join(root, p.RenderPass))
+		if err != nil {
+			t.Fatalf("failed to read the render pass of %s: %v", p.Name, err)
+		}
+		var rp RenderPassData
+		if err := json.Unmarshal(data, &rp); err != nil {
+			t.Fatalf("failed to parse the render pass of %s: %v", p.Name, err)
+		}
+		if err := p.validateRenderPass(&rp); err != nil {
+			t.Error(err)
+		}
+	}
+}
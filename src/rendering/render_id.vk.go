@@ -91,10 +91,11 @@ type TextureId struct {
 	Width      int
 	Height     int
 	LayerCount int
+	sw         *swTexture
 }
 
 func (t TextureId) IsValid() bool {
-	return t.Image != vk.NullImage
+	return t.Image != vk.NullImage || t.sw != nil
 }
 
 type MeshId struct {
@@ -104,11 +105,13 @@ type MeshId struct {
 	vertexBufferMemory vk.DeviceMemory
 	indexBuffer        vk.Buffer
 	indexBufferMemory  vk.DeviceMemory
+	sw                 *swMesh
+	dynamic            *vkDynamicMesh
 }
 
 func (m MeshId) IsValid() bool {
-	return m.vertexBuffer != vk.Buffer(vk.NullHandle) &&
-		m.indexBuffer != vk.Buffer(vk.NullHandle)
+	return m.sw != nil || m.dynamic != nil || (m.vertexBuffer != vk.Buffer(vk.NullHandle) &&
+		m.indexBuffer != vk.Buffer(vk.NullHandle))
 }
 
 func (d *ShaderDriverData) setup(sd *ShaderDataCompiled) {
@@ -9,8 +9,18 @@ import (
 )
 
 type RenderPassData struct {
-	Name                   string
-	Sort                   int
+	Name string
+	Sort int
+	// Width and Height are the size of the render pass images, when they are
+	// 0 the size of the swap chain is used
+	Width  uint32
+	Height uint32
+	// Scale multiplies the size of the swap chain for passes that follow
+	// it, like 0.5 for half resolution images. 0 is the same as 1.
+	Scale float32
+	// Offscreen render passes are drawn but are not combined into the final
+	// image, their images are meant to be sampled by other materials
+	Offscreen              bool
 	AttachmentDescriptions []RenderPassAttachmentDescription
 	SubpassDescriptions    []RenderPassSubpassDescription
 	SubpassDependencies    []RenderPassSubpassDependency
@@ -89,6 +99,10 @@ type RenderPassSubpassImageData struct {
 type RenderPassDataCompiled struct {
 	Name                   string
 	Sort                   int
+	Width                  uint32
+	Height                 uint32
+	Scale                  float32
+	Offscreen              bool
 	AttachmentDescriptions []RenderPassAttachmentDescriptionCompiled
 	SubpassDescriptions    []RenderPassSubpassDescriptionCompiled
 	SubpassDependencies    []RenderPassSubpassDependencyCompiled
@@ -169,6 +183,10 @@ func (d *RenderPassData) Compile(vr *Vulkan) RenderPassDataCompiled {
 	c := RenderPassDataCompiled{
 		Name:                   d.Name,
 		Sort:                   d.Sort,
+		Width:                  d.Width,
+		Height:                 d.Height,
+		Scale:                  d.Scale,
+		Offscreen:              d.Offscreen,
 		AttachmentDescriptions: make([]RenderPassAttachmentDescriptionCompiled, len(d.AttachmentDescriptions)),
 		SubpassDescriptions:    make([]RenderPassSubpassDescriptionCompiled, len(d.SubpassDescriptions)),
 		SubpassDependencies:    make([]RenderPassSubpassDependencyCompiled, len(d.SubpassDependencies)),
@@ -273,6 +291,12 @@ func (d *RenderPassData) Compile(vr *Vulkan) RenderPassDataCompiled {
 	if len(c.Subpass) != len(d.SubpassDescriptions)-1 {
 		slog.Error("one or more of your d.SubpassDescriptions[1:] haven't been setup")
 	}
+	if vr != nil && vr.renderGraph != nil {
+		vr.renderGraph.applyTo(&c)
+	}
+	if vr != nil && vr.hdrTargets {
+		applyHDRFormat(&c)
+	}
 	return c
 }
 
@@ -0,0 +1,214 @@
+/******************************************************************************/
+/* render_target.go                                                           */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package rendering
+
+import (
+	"errors"
+	"kaiju/engine/cameras"
+	"kaiju/matrix"
+	"log/slog"
+	"slices"
+	"sync"
+
+	vk "kaiju/rendering/vulkan"
+)
+
+// renderTargetPass is the render pass that every render target is built from,
+// its size is replaced by the size of the target
+const renderTargetPass = "renderer/passes/render_target.renderpass"
+
+// RenderTarget is a texture that a secondary camera renders the scene into.
+// The texture can be given to a material instance like any other texture,
+// which is how mirrors, minimaps, and security cameras are made. Drawings are
+// put into the target by adding it to #Drawing.RenderTargets.
+type RenderTarget struct {
+	// Camera is the camera that the drawings in the target are viewed from
+	Camera cameras.Camera
+	// ClearColor is the color the texture is cleared to before drawing
+	ClearColor matrix.Color
+	name       string
+	caches     RenderCaches
+	passData   RenderPassData
+	pass       *RenderPass
+	texture    *Texture
+	materials  map[*Material]*Material
+	mutex      sync.Mutex
+	// Vulkan, the global uniform data as seen through the target's camera
+	globalBuffers  [maxFramesInFlight]vk.Buffer
+	globalMemories [maxFramesInFlight]vk.DeviceMemory
+	// Software, the depth buffer the target is rasterized with
+	swDepth []float32
+}
+
+// NewRenderTarget creates a render target of the given size in pixels, the
+// name must be unique as it is used for the render pass and texture keys. The
+// camera of the target starts out as a perspective camera at the origin.
+func NewRenderTarget(renderer Renderer, caches RenderCaches, name string, width, height int) (*RenderTarget, error) {
+	if width <= 0 || height <= 0 {
+		return nil, errors.New("the render target size must be greater than 0")
+	}
+	src, err := caches.AssetDatabase().ReadText(renderTargetPass)
+	if err != nil {
+		return nil, err
+	}
+	rp, err := NewRenderPassData(src)
+	if err != nil {
+		return nil, err
+	}
+	rp.Name += "." + name
+	rp.Width = uint32(width)
+	rp.Height = uint32(height)
+	w, h := matrix.Float(width), matrix.Float(height)
+	t := &RenderTarget{
+		Camera:     cameras.NewStandardCamera(w, h, w, h, matrix.Vec3Backward()),
+		ClearColor: matrix.ColorBlack(),
+		name:       name,
+		caches:     caches,
+		passData:   rp,
+		materials:  make(map[*Material]*Material),
+	}
+	if err := renderer.CreateRenderTarget(t); err != nil {
+		return nil, err
+	}
+	t.pass.target = t
+	return t, nil
+}
+
+// Name returns the unique name the target was created with
+func (t *RenderTarget) Name() string { return t.name }
+
+// Texture returns the texture that the target renders into
+func (t *RenderTarget) Texture() *Texture { return t.texture }
+
+// Width returns the width of the target's texture in pixels
+func (t *RenderTarget) Width() int { return int(t.passData.Width) }
+
+// Height returns the height of the target's texture in pixels
+func (t *RenderTarget) Height() int { return int(t.passData.Height) }
+
+// Destroy releases the texture and render pass of the target, drawings that
+// still draw into the target must be destroyed before it
+func (t *RenderTarget) Destroy(renderer Renderer) {
+	renderer.DestroyRenderTarget(t)
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	clear(t.materials)
+}
+
+func (t *RenderTarget) textureKey() string { return "renderTarget:" + t.name }
+
+// material returns the version of the material that draws into the target.
+// The shader pipeline is built against the target's render pass, so each
+// root material gets its own shader for the target. Materials that draw into
+// more than one color attachment (order independent transparency) or that
+// sample the target's own texture can't be drawn into it and return nil.
+func (t *RenderTarget) material(m *Material) *Material {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	if found, ok := t.materials[m]; ok {
+		return found
+	}
+	if !renderTargetCompatible(m) || slices.Contains(m.Textures, t.texture) {
+		slog.Warn("the material can not be drawn into the render target",
+			"material", m.Name, "renderTarget", t.name)
+		t.materials[m] = nil
+		return nil
+	}
+	root := m.SelectRoot()
+	base, ok := t.materials[root]
+	if !ok {
+		base = &Material{
+			Name:         root.Name,
+			shaderInfo:   root.shaderInfo,
+			renderPass:   t.pass,
+			pipelineInfo: root.pipelineInfo,
+			Textures:     root.Textures,
+			Instances:    make(map[string]*Material),
+		}
+		base.Shader, _ = t.caches.ShaderCache().shader(
+			root.Name+"@"+t.pass.construction.Name, root.Shader.data)
+		base.Shader.pipelineInfo = &base.pipelineInfo
+		base.Shader.renderPass = t.pass
+		t.materials[root] = base
+	}
+	if m == root {
+		return base
+	}
+	instance := base.CreateInstance(m.Textures)
+	t.materials[m] = instance
+	return instance
+}
+
+// renderTargetInstance draws an instance into a render target. It shares the
+// data of the instance it wraps and follows its lifetime, so removing the
+// render target doesn't destroy or deactivate the drawing it was made from.
+type renderTargetInstance struct {
+	DrawInstance
+}
+
+func (r *renderTargetInstance) Destroy()    {}
+func (r *renderTargetInstance) Activate()   {}
+func (r *renderTargetInstance) Deactivate() {}
+
+func renderTargetCompatible(m *Material) bool {
+	if m == nil || m.Shader == nil || m.renderPass == nil {
+		return false
+	}
+	subpasses := m.renderPass.construction.SubpassDescriptions
+	return len(subpasses) > 0 && len(subpasses[0].ColorAttachmentReferences) == 1
+}
+
+// renderTargetGlobals is the global shader data of the frame as seen through
+// the target's camera. The light tiles were made for the main camera, so every
+// visible light is put into every tile of the target.
+func renderTargetGlobals(frame GlobalShaderData, camera cameras.Camera, width, height matrix.Float) GlobalShaderData {
+	g := frame
+	camOrtho := matrix.Float(0)
+	if camera.IsOrthographic() {
+		camOrtho = 1
+	}
+	g.View = camera.View()
+	g.Projection = camera.Projection()
+	g.CameraPosition = camera.Position().AsVec4WithW(camOrtho)
+	g.ScreenSize = matrix.Vec2{width, height}
+	mask := uint32(1)<<g.LightCount - 1
+	for i := range LightTileCount {
+		g.LightTiles[i/4][i%4] = mask
+	}
+	return g
+}
@@ -0,0 +1,230 @@
+/******************************************************************************/
+/* render_target_test.go                                                      */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package rendering
+
+import (
+	"encoding/binary"
+	"kaiju/engine/assets"
+	"kaiju/engine/cameras"
+	"kaiju/matrix"
+	"math"
+	"testing"
+
+	vk "kaiju/rendering/vulkan"
+)
+
+type testRenderCaches struct {
+	shaders   ShaderCache
+	materials MaterialCache
+	db        *assets.Database
+}
+
+func (c *testRenderCaches) ShaderCache() *ShaderCache       { return &c.shaders }
+func (c *testRenderCaches) TextureCache() *TextureCache     { return nil }
+func (c *testRenderCaches) MeshCache() *MeshCache           { return nil }
+func (c *testRenderCaches) FontCache() *FontCache           { return nil }
+func (c *testRenderCaches) MaterialCache() *MaterialCache   { return &c.materials }
+func (c *testRenderCaches) AssetDatabase() *assets.Database { return c.db }
+
+func testSoftwareRenderTarget(sr *Software, width, height int) *RenderTarget {
+	w, h := matrix.Float(2), matrix.Float(2)
+	target := &RenderTarget{
+		Camera:     cameras.NewStandardCameraOrthographic(w, h, w, h, matrix.Vec3{10, 0, 0}),
+		ClearColor: matrix.ColorBlue(),
+		name:       "test",
+		caches:     &testRenderCaches{shaders: NewShaderCache(sr, nil)},
+		passData:   RenderPassData{Name: "render_target.test", Sort: -50},
+		materials:  make(map[*Material]*Material),
+	}
+	target.passData.Width = uint32(width)
+	target.passData.Height = uint32(height)
+	sr.CreateRenderTarget(target)
+	target.pass.target = target
+	return target
+}
+
+func testSoftwarePassMaterial(sr *Software) *Material {
+	m := testSoftwareMaterial(sr)
+	m.renderPass = &RenderPass{construction: RenderPassDataCompiled{
+		SubpassDescriptions: []RenderPassSubpassDescriptionCompiled{{
+			ColorAttachmentReferences: []RenderPassAttachmentReferenceCompiled{{}},
+		}},
+	}}
+	return m
+}
+
+func TestSoftwareRenderTarget(t *testing.T) {
+	sr := NewSoftwareRenderer(16, 16)
+	target := testSoftwareRenderTarget(sr, 8, 8)
+	mesh := testSoftwareQuad(sr)
+	material := testSoftwarePassMaterial(sr)
+	// The quad is outside of the main camera, only the target can see it
+	drawings := NewDrawings()
+	drawings.AddDrawing(Drawing{
+		Material:      material,
+		Mesh:          mesh,
+		ShaderData:    testSoftwareInstance(matrix.Vec3{10, 0, -0.5}, matrix.Vec3One(), matrix.ColorRed()),
+		RenderTargets: []*RenderTarget{target},
+	})
+	screen := testSoftwarePassMaterial(sr)
+	screen.Textures = []*Texture{target.Texture()}
+	drawings.AddDrawing(Drawing{
+		Material:   screen,
+		Mesh:       mesh,
+		ShaderData: testSoftwareInstance(matrix.Vec3{0, 0, -0.5}, matrix.Vec3{2, 2, 1}, matrix.ColorWhite()),
+	})
+	drawings.PreparePending()
+	var projection matrix.Mat4
+	projection.Orthographic(-1, 1, -1, 1, 0, 2)
+	sr.globals.View = matrix.Mat4Identity()
+	sr.globals.Projection = projection
+	sr.clear()
+	drawings.Render(sr)
+	sr.SwapFrame(int32(sr.Width()), int32(sr.Height()))
+	img, err := sr.TextureReadPixels(target.Texture())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if img.Rect.Dx() != 8 || img.Rect.Dy() != 8 {
+		t.Fatalf("expected an 8x8 image, got %v", img.Rect)
+	}
+	if c := sr.TextureReadPixel(target.Texture(), 4, 4); !testColorsMatch(c, matrix.ColorRed()) {
+		t.Errorf("expected the target camera to see the quad, got %v", c)
+	}
+	if c := sr.TextureReadPixel(target.Texture(), 0, 0); !testColorsMatch(c, matrix.ColorBlue()) {
+		t.Errorf("expected the target to be cleared to its clear color, got %v", c)
+	}
+	if c := sr.FramePixel(8, 8); !testColorsMatch(c, matrix.ColorRed()) {
+		t.Errorf("expected the screen to show the target texture, got %v", c)
+	}
+	if c := sr.FramePixel(1, 1); !testColorsMatch(c, matrix.ColorBlue()) {
+		t.Errorf("expected the screen edge to show the target clear color, got %v", c)
+	}
+	drawings.RemoveRenderTarget(target, sr)
+	for i := range drawings.renderPassGroups {
+		if drawings.renderPassGroups[i].renderPass == target.pass {
+			t.Fatal("expected the render target drawings to be removed")
+		}
+	}
+	if drawings.renderPassGroups[0].draws[0].instanceGroups[0].Instances[0].IsDestroyed() {
+		t.Error("expected removing the target to keep the main drawing alive")
+	}
+}
+
+func TestRenderTargetSkipsIncompatibleMaterials(t *testing.T) {
+	sr := NewSoftwareRenderer(4, 4)
+	target := testSoftwareRenderTarget(sr, 4, 4)
+	oit := testSoftwarePassMaterial(sr)
+	oit.renderPass.construction.SubpassDescriptions[0].ColorAttachmentReferences =
+		make([]RenderPassAttachmentReferenceCompiled, 2)
+	if target.material(oit) != nil {
+		t.Error("expected a material with two color attachments to be skipped")
+	}
+	feedback := testSoftwarePassMaterial(sr)
+	feedback.Textures = []*Texture{target.Texture()}
+	if target.material(feedback) != nil {
+		t.Error("expected a material sampling the target to be skipped")
+	}
+	ok := testSoftwarePassMaterial(sr)
+	m := target.material(ok)
+	if m == nil || m.renderPass != target.pass || m.Shader == ok.Shader {
+		t.Error("expected the material to get its own shader for the target pass")
+	}
+	if target.material(ok) != m {
+		t.Error("expected the target material to be reused")
+	}
+}
+
+func TestRenderTargetGlobals(t *testing.T) {
+	frame := GlobalShaderData{Time: 3, LightCount: 3}
+	camera := cameras.NewStandardCamera(64, 32, 64, 32, matrix.Vec3{1, 2, 3})
+	g := renderTargetGlobals(frame, camera, 64, 32)
+	if g.View != camera.View() || g.Projection != camera.Projection() {
+		t.Error("expected the view and projection of the target camera")
+	}
+	if g.CameraPosition != (matrix.Vec4{1, 2, 3, 0}) {
+		t.Errorf("expected the target camera position, got %v", g.CameraPosition)
+	}
+	if g.ScreenSize != (matrix.Vec2{64, 32}) || g.Time != 3 {
+		t.Errorf("expected the target size and frame time, got %v %v", g.ScreenSize, g.Time)
+	}
+	for i := range LightTileCount {
+		if g.LightTiles[i/4][i%4] != 0b111 {
+			t.Fatalf("expected every light in tile %d, got %b", i, g.LightTiles[i/4][i%4])
+		}
+	}
+	frame.LightCount = MaxLights
+	g = renderTargetGlobals(frame, camera, 64, 32)
+	if g.LightTiles[0][0] != math.MaxUint32 {
+		t.Errorf("expected all of the light bits to be set, got %b", g.LightTiles[0][0])
+	}
+}
+
+func TestReadbackImage(t *testing.T) {
+	bgra := []byte{1, 2, 3, 4}
+	img, err := readbackImage(vk.FormatB8g8r8a8Unorm, bgra, 1, 1)
+	if err != nil || img.Pix[0] != 3 || img.Pix[1] != 2 || img.Pix[2] != 1 || img.Pix[3] != 4 {
+		t.Errorf("expected BGRA to be swapped to RGBA, got %v %v", img, err)
+	}
+	half := make([]byte, 8)
+	for i, f := range []float32{0.5, 2, -1, 1} {
+		binary.LittleEndian.PutUint16(half[i*2:], float32ToHalf(f))
+	}
+	if img, err = readbackImage(vk.FormatR16g16b16a16Sfloat, half, 1, 1); err != nil {
+		t.Fatal(err)
+	}
+	if img.Pix[0] != 128 || img.Pix[1] != 255 || img.Pix[2] != 0 || img.Pix[3] != 255 {
+		t.Errorf("expected half floats to be clamped to bytes, got %v", img.Pix)
+	}
+	full := make([]byte, 16)
+	for i, f := range []float32{0.25, float32(math.NaN()), 1, 0} {
+		binary.LittleEndian.PutUint32(full[i*4:], math.Float32bits(f))
+	}
+	if img, err = readbackImage(vk.FormatR32g32b32a32Sfloat, full, 1, 1); err != nil {
+		t.Fatal(err)
+	}
+	if img.Pix[0] != 64 || img.Pix[1] != 0 || img.Pix[2] != 255 || img.Pix[3] != 0 {
+		t.Errorf("expected floats to be clamped to bytes, got %v", img.Pix)
+	}
+	if _, err = readbackImage(vk.FormatD32Sfloat, full, 1, 1); err == nil {
+		t.Error("expected depth formats to not be read back")
+	}
+	if _, err = readbackImage(vk.FormatR8g8b8a8Unorm, bgra, 2, 1); err == nil {
+		t.Error("expected too little data to fail")
+	}
+}
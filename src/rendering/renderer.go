@@ -38,6 +38,7 @@
 package rendering
 
 import (
+	"image"
 	"kaiju/engine/assets"
 	"kaiju/engine/cameras"
 	"kaiju/matrix"
@@ -45,19 +46,25 @@ import (
 
 type Renderer interface {
 	Initialize(caches RenderCaches, width, height int32) error
-	ReadyFrame(camera cameras.Camera, uiCamera cameras.Camera, runtime float32) bool
+	ReadyFrame(camera cameras.Camera, uiCamera cameras.Camera, lights *LightList, runtime float32) bool
 	CreateShader(shader *Shader, assetDatabase *assets.Database) error
 	CreateMesh(mesh *Mesh, verts []Vertex, indices []uint32)
+	UpdateMesh(mesh *Mesh, verts []Vertex, indices []uint32)
 	CreateTexture(texture *Texture, textureData *TextureData)
 	TextureReadPixel(texture *Texture, x, y int) matrix.Color
 	TextureWritePixels(texture *Texture, x, y, width, height int, pixels []byte)
+	TextureReadPixels(texture *Texture) (*image.RGBA, error)
+	CreateRenderTarget(target *RenderTarget) error
+	CaptureFrame(done func(img *image.RGBA, err error))
 	Draw(renderPass *RenderPass, drawings []ShaderDraw) bool
 	BlitTargets(passes []*RenderPass)
+	SetPostProcessing(data *PostProcessData)
 	SwapFrame(width, height int32) bool
 	Resize(width, height int)
 	AddPreRun(preRun func())
 	DestroyGroup(group *DrawInstanceGroup)
 	DestroyTexture(texture *Texture)
+	DestroyRenderTarget(target *RenderTarget)
 	DestroyShader(shader *Shader)
 	DestroyMesh(mesh *Mesh)
 	Destroy()
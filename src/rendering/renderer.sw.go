@@ -0,0 +1,240 @@
+/******************************************************************************/
+/* renderer.sw.go                                                             */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package rendering
+
+import (
+	"image"
+	"kaiju/engine/assets"
+	"kaiju/engine/cameras"
+	"kaiju/matrix"
+	"kaiju/platform/profiler/tracing"
+	"log/slog"
+	"sync"
+)
+
+// Software is a renderer that rasterizes on the CPU into an in-memory frame
+// buffer. It needs no GPU or window which makes it useful for headless tests
+// and for producing reference images. It understands the same material,
+// shader pipeline, and render pass assets as the Vulkan renderer, but it only
+// evaluates unlit shading: the texture is multiplied by the vertex and
+// instance color. Blending is approximated with straight alpha blending.
+type Software struct {
+	caches          RenderCaches
+	defaultTexture  *Texture
+	width           int
+	height          int
+	color           []byte
+	depth           []float32
+	frame           []byte
+	globals         GlobalShaderData
+	shaders         map[*Shader]swShader
+	renderPassCache map[string]*RenderPass
+	pendingDraws    []swPassDraw
+	preRuns         []func()
+	captures        []func(*image.RGBA, error)
+	mutex           sync.RWMutex
+	// ClearColor is the color the frame buffer is cleared to at the start of
+	// every frame
+	ClearColor matrix.Color
+}
+
+// NewSoftwareRenderer creates a software renderer with a frame buffer of the
+// given size in pixels
+func NewSoftwareRenderer(width, height int32) *Software {
+	sr := &Software{
+		shaders:         make(map[*Shader]swShader),
+		renderPassCache: make(map[string]*RenderPass),
+		ClearColor:      matrix.ColorBlack(),
+	}
+	sr.resizeBuffers(int(width), int(height))
+	return sr
+}
+
+func (sr *Software) resizeBuffers(width, height int) {
+	width = max(width, 1)
+	height = max(height, 1)
+	if width == sr.width && height == sr.height {
+		return
+	}
+	sr.mutex.Lock()
+	defer sr.mutex.Unlock()
+	sr.width = width
+	sr.height = height
+	sr.color = make([]byte, width*height*bytesInPixel)
+	sr.depth = make([]float32, width*height)
+	sr.frame = make([]byte, width*height*bytesInPixel)
+}
+
+// Width returns the width of the frame buffer in pixels
+func (sr *Software) Width() int { return sr.width }
+
+// Height returns the height of the frame buffer in pixels
+func (sr *Software) Height() int { return sr.height }
+
+// Frame returns a copy of the last frame that was completed by SwapFrame
+func (sr *Software) Frame() *image.RGBA {
+	sr.mutex.RLock()
+	defer sr.mutex.RUnlock()
+	img := image.NewRGBA(image.Rect(0, 0, sr.width, sr.height))
+	copy(img.Pix, sr.frame)
+	return img
+}
+
+// FramePixel reads a single pixel from the last frame completed by SwapFrame,
+// (0, 0) is the top left of the frame
+func (sr *Software) FramePixel(x, y int) matrix.Color {
+	sr.mutex.RLock()
+	defer sr.mutex.RUnlock()
+	if x < 0 || y < 0 || x >= sr.width || y >= sr.height {
+		return matrix.ColorClear()
+	}
+	i := (y*sr.width + x) * bytesInPixel
+	return matrix.ColorRGBAInt(int(sr.frame[i]), int(sr.frame[i+1]),
+		int(sr.frame[i+2]), int(sr.frame[i+3]))
+}
+
+func (sr *Software) Initialize(caches RenderCaches, width, height int32) error {
+	defer tracing.NewRegion("Software::Initialize").End()
+	var err error
+	sr.defaultTexture, err = caches.TextureCache().Texture(
+		assets.TextureSquare, TextureFilterLinear)
+	if err != nil {
+		slog.Error(err.Error())
+		return err
+	}
+	sr.caches = caches
+	sr.resizeBuffers(int(width), int(height))
+	caches.TextureCache().CreatePending()
+	return nil
+}
+
+func (sr *Software) ReadyFrame(camera cameras.Camera, uiCamera cameras.Camera, lights *LightList, runtime float32) bool {
+	defer tracing.NewRegion("Software::ReadyFrame").End()
+	sr.globals = newGlobalShaderData(camera, uiCamera, lights,
+		matrix.Float(sr.width), matrix.Float(sr.height), runtime)
+	sr.clear()
+	for _, r := range sr.preRuns {
+		r()
+	}
+	sr.preRuns = sr.preRuns[:0]
+	return true
+}
+
+func (sr *Software) clear() {
+	c := sr.ClearColor
+	px := [bytesInPixel]byte{swUnitToByte(c.R()), swUnitToByte(c.G()),
+		swUnitToByte(c.B()), swUnitToByte(c.A())}
+	for i := 0; i < len(sr.color); i += bytesInPixel {
+		copy(sr.color[i:i+bytesInPixel], px[:])
+	}
+	sr.clearDepth()
+}
+
+func (sr *Software) clearDepth() {
+	for i := range sr.depth {
+		sr.depth[i] = 1
+	}
+}
+
+func (sr *Software) SwapFrame(width, height int32) bool {
+	defer tracing.NewRegion("Software::SwapFrame").End()
+	sr.mutex.Lock()
+	copy(sr.frame, sr.color)
+	sr.mutex.Unlock()
+	if len(sr.captures) > 0 {
+		img := sr.Frame()
+		for _, done := range sr.captures {
+			done(img, nil)
+		}
+		sr.captures = sr.captures[:0]
+	}
+	return true
+}
+
+// CaptureFrame calls done with the frame once the next frame is completed by
+// SwapFrame, this is the same image that #Software.Frame returns
+func (sr *Software) CaptureFrame(done func(*image.RGBA, error)) {
+	sr.captures = append(sr.captures, done)
+}
+
+func (sr *Software) Resize(width, height int) {
+	defer tracing.NewRegion("Software::Resize").End()
+	sr.resizeBuffers(width, height)
+}
+
+func (sr *Software) AddPreRun(preRun func()) {
+	sr.preRuns = append(sr.preRuns, preRun)
+}
+
+func (sr *Software) DestroyGroup(group *DrawInstanceGroup) {}
+
+func (sr *Software) WaitForRender() {}
+
+// SetPostProcessing is not supported by the software renderer, the final
+// image is left as it was drawn
+func (sr *Software) SetPostProcessing(*PostProcessData) {}
+
+func (sr *Software) Destroy() {
+	defer tracing.NewRegion("Software::Destroy").End()
+	clear(sr.shaders)
+	clear(sr.renderPassCache)
+	sr.pendingDraws = sr.pendingDraws[:0]
+}
+
+// renderPass returns the render pass for the given description. The software
+// renderer draws every pass into the same frame buffer, so the pass is only
+// used to order the drawings and to know when the depth buffer is cleared.
+func (sr *Software) renderPass(data *RenderPassData) *RenderPass {
+	if pass, ok := sr.renderPassCache[data.Name]; ok {
+		return pass
+	}
+	pass := &RenderPass{construction: data.Compile(nil)}
+	sr.renderPassCache[data.Name] = pass
+	return pass
+}
+
+// reloadRenderPass compiles the data into the loaded render pass with the same
+// name, nil is returned if it isn't loaded
+func (sr *Software) reloadRenderPass(data *RenderPassData) (*RenderPass, error) {
+	pass, ok := sr.renderPassCache[data.Name]
+	if !ok {
+		return nil, nil
+	}
+	pass.construction = data.Compile(nil)
+	return pass, nil
+}
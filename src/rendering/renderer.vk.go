@@ -39,6 +39,7 @@ package rendering
 
 import (
 	"errors"
+	"image"
 	"kaiju/engine/assets"
 	"kaiju/engine/cameras"
 	"kaiju/klib"
@@ -48,6 +49,7 @@ import (
 	"log/slog"
 	"math"
 	"sort"
+	"sync"
 	"unsafe"
 
 	vk "kaiju/rendering/vulkan"
@@ -85,6 +87,7 @@ type Vulkan struct {
 	descriptorPools            []vk.DescriptorPool
 	globalUniformBuffers       [maxFramesInFlight]vk.Buffer
 	globalUniformBuffersMemory [maxFramesInFlight]vk.DeviceMemory
+	frameGlobals               GlobalShaderData
 	bufferTrash                bufferDestroyer
 	depth                      TextureId
 	color                      TextureId
@@ -99,20 +102,29 @@ type Vulkan struct {
 	currentFrame               int
 	msaaSamples                vk.SampleCountFlagBits
 	combinedDrawings           Drawings
+	postProcess                vkPostProcess
 	preRuns                    []func()
 	dbg                        debugVulkan
 	renderPassCache            map[string]*RenderPass
+	renderGraph                *RenderGraph
+	hdrTargets                 bool
 	hasSwapChain               bool
 	writtenCommands            []CommandRecorder
 	transientCommands          []CommandRecorder
 	delayWrittenCommands       bool
 	singleTimeCommandPool      pooling.PoolGroup[CommandRecorder]
+	captureRequests            []func(*image.RGBA, error)
+	frameCapture               vkFrameCapture
 }
 
-func init() {
-	klib.Must(vk.SetDefaultGetInstanceProcAddr())
-	klib.Must(vk.Init())
-}
+// initVulkan loads the Vulkan library the first time a Vulkan renderer is
+// created, so programs using the software renderer can run without it
+var initVulkan = sync.OnceValue(func() error {
+	if err := vk.SetDefaultGetInstanceProcAddr(); err != nil {
+		return err
+	}
+	return vk.Init()
+})
 
 func (vr *Vulkan) WaitForRender() {
 	defer tracing.NewRegion("Vulkan::WaitForRender").End()
@@ -182,25 +194,11 @@ func (vr *Vulkan) createDescriptorSet(layout vk.DescriptorSetLayout, poolIdx int
 	return sets, vr.descriptorPools[poolIdx], nil
 }
 
-func (vr *Vulkan) updateGlobalUniformBuffer(camera cameras.Camera, uiCamera cameras.Camera, runtime float32) {
+func (vr *Vulkan) updateGlobalUniformBuffer(camera cameras.Camera, uiCamera cameras.Camera, lights *LightList, runtime float32) {
 	defer tracing.NewRegion("Vulkan::updateGlobalUniformBuffer").End()
-	camOrtho := matrix.Float(0)
-	if camera.IsOrthographic() {
-		camOrtho = 1
-	}
-	ubo := GlobalShaderData{
-		View:             camera.View(),
-		UIView:           uiCamera.View(),
-		Projection:       camera.Projection(),
-		UIProjection:     uiCamera.Projection(),
-		CameraPosition:   camera.Position().AsVec4WithW(camOrtho),
-		UICameraPosition: uiCamera.Position(),
-		Time:             runtime,
-		ScreenSize: matrix.Vec2{
-			matrix.Float(vr.swapChainExtent.Width),
-			matrix.Float(vr.swapChainExtent.Height),
-		},
-	}
+	ubo := newGlobalShaderData(camera, uiCamera, lights,
+		matrix.Float(vr.swapChainExtent.Width),
+		matrix.Float(vr.swapChainExtent.Height), runtime)
 	var data unsafe.Pointer
 	r := vk.MapMemory(vr.device, vr.globalUniformBuffersMemory[vr.currentFrame],
 		0, vk.DeviceSize(unsafe.Sizeof(ubo)), 0, &data)
@@ -210,6 +208,7 @@ func (vr *Vulkan) updateGlobalUniformBuffer(camera cameras.Camera, uiCamera came
 	}
 	vk.Memcopy(data, klib.StructToByteArray(ubo))
 	vk.UnmapMemory(vr.device, vr.globalUniformBuffersMemory[vr.currentFrame])
+	vr.frameGlobals = ubo
 }
 
 func (vr *Vulkan) createColorResources() bool {
@@ -222,6 +221,9 @@ func (vr *Vulkan) createColorResources() bool {
 }
 
 func NewVKRenderer(window RenderingContainer, applicationName string, assets *assets.Database) (*Vulkan, error) {
+	if err := initVulkan(); err != nil {
+		return nil, err
+	}
 	vr := &Vulkan{
 		window:           window,
 		instance:         vk.NullInstance,
@@ -230,6 +232,7 @@ func NewVKRenderer(window RenderingContainer, applicationName string, assets *as
 		msaaSamples:      vk.SampleCountFlagBits(vk.SampleCount1Bit),
 		dbg:              debugVulkanNew(),
 		combinedDrawings: NewDrawings(),
+		postProcess:      vkPostProcess{drawings: NewDrawings()},
 		renderPassCache:  make(map[string]*RenderPass),
 	}
 
@@ -260,6 +263,9 @@ func NewVKRenderer(window RenderingContainer, applicationName string, assets *as
 	if !vr.createImageViews() {
 		return nil, errors.New("failed to create image views")
 	}
+	if err := vr.loadRenderGraph(assets); err != nil {
+		return nil, err
+	}
 	if !vr.createSwapChainRenderPass(assets) {
 		return nil, errors.New("failed to create render pass")
 	}
@@ -294,7 +300,7 @@ func (vr *Vulkan) Initialize(caches RenderCaches, width, height int32) error {
 	}
 	vr.caches = caches
 	caches.TextureCache().CreatePending()
-	return nil
+	return vr.constructRenderGraph()
 }
 
 func (vr *Vulkan) remakeSwapChain() {
@@ -382,7 +388,7 @@ func (vr *Vulkan) createSwapChainRenderPass(assets *assets.Database) bool {
 	return true
 }
 
-func (vr *Vulkan) ReadyFrame(camera cameras.Camera, uiCamera cameras.Camera, runtime float32) bool {
+func (vr *Vulkan) ReadyFrame(camera cameras.Camera, uiCamera cameras.Camera, lights *LightList, runtime float32) bool {
 	defer tracing.NewRegion("Vulkan::ReadyFrame").End()
 	if !vr.hasSwapChain {
 		vr.remakeSwapChain()
@@ -409,7 +415,7 @@ func (vr *Vulkan) ReadyFrame(camera cameras.Camera, uiCamera cameras.Camera, run
 	inlTrace.End()
 	vk.ResetFences(vr.device, 1, &fences[0])
 	vr.bufferTrash.Cycle()
-	vr.updateGlobalUniformBuffer(camera, uiCamera, runtime)
+	vr.updateGlobalUniformBuffer(camera, uiCamera, lights, runtime)
 	for _, r := range vr.preRuns {
 		r()
 	}
@@ -473,8 +479,10 @@ func (vr *Vulkan) SwapFrame(width, height int32) bool {
 	eCode := vk.QueueSubmit(vr.graphicsQueue, 1, &submitInfo, vr.renderFences[vr.currentFrame])
 	if eCode != vk.Success {
 		slog.Error("Failed to submit draw command buffer", slog.Int("code", int(eCode)))
+		vr.finishFrameCapture(false)
 		return false
 	}
+	vr.finishFrameCapture(true)
 
 	dependency := vk.SubpassDependency{}
 	dependency.SrcSubpass = vk.SubpassExternal
@@ -510,7 +518,9 @@ func (vr *Vulkan) SwapFrame(width, height int32) bool {
 func (vr *Vulkan) Destroy() {
 	defer tracing.NewRegion("Vulkan::Destroy").End()
 	vr.WaitForRender()
+	vr.finishFrameCapture(false)
 	vr.combinedDrawings.Destroy(vr)
+	vr.postProcess.destroy(vr)
 	vr.bufferTrash.Purge()
 	vr.destroyTransientCommands()
 	if vr.device != vk.NullDevice {
@@ -42,6 +42,7 @@ import (
 	"kaiju/platform/profiler/tracing"
 	vk "kaiju/rendering/vulkan"
 	"path/filepath"
+	"slices"
 	"strings"
 )
 
@@ -133,6 +134,16 @@ func (sd *ShaderDataCompiled) ToDescriptorSetLayoutStructure() DescriptorSetLayo
 			if layout.Binding < 0 {
 				continue
 			}
+			// A binding that is shared between stages, like the global uniform
+			// buffer being read in both the vertex and fragment shader, is a
+			// single binding that is visible to each of those stages
+			idx := slices.IndexFunc(structure.Types, func(t DescriptorSetLayoutStructureType) bool {
+				return t.Binding == uint32(layout.Binding)
+			})
+			if idx >= 0 {
+				structure.Types[idx].Flags |= g.DescriptorFlag()
+				continue
+			}
 			structure.Types = append(structure.Types, DescriptorSetLayoutStructureType{
 				Type:    layout.DescriptorType(),
 				Flags:   g.DescriptorFlag(),
@@ -40,6 +40,8 @@ package rendering
 import (
 	"kaiju/engine/assets"
 	"kaiju/platform/profiler/tracing"
+	"reflect"
+	"slices"
 	"sync"
 )
 
@@ -63,16 +65,23 @@ func NewShaderCache(renderer Renderer, assetDatabase *assets.Database) ShaderCac
 
 func (s *ShaderCache) Shader(shaderData ShaderDataCompiled) (shader *Shader, isNew bool) {
 	defer tracing.NewRegion("ShaderCache::Shader").End()
+	return s.shader(shaderData.Name, shaderData)
+}
+
+// shader finds or creates the shader under the given key, this allows the
+// same shader data to be created more than once, like for the different
+// render passes of a render target
+func (s *ShaderCache) shader(key string, shaderData ShaderDataCompiled) (shader *Shader, isNew bool) {
 	s.mutex.Lock()
 	defer s.mutex.Unlock()
-	if shader, ok := s.shaders[shaderData.Name]; ok {
+	if shader, ok := s.shaders[key]; ok {
 		return shader, false
 	} else {
 		shader := NewShader(shaderData)
 		if shader != nil {
 			s.pendingShaders = append(s.pendingShaders, shader)
 		}
-		s.shaders[shader.data.Name] = shader
+		s.shaders[key] = shader
 		return shader, true
 	}
 }
@@ -87,6 +96,60 @@ func (s *ShaderCache) CreatePending() {
 	s.pendingShaders = s.pendingShaders[:0]
 }
 
+// reload swaps the shader data into every loaded shader with the same name
+// and recreates them. The layout can not change as the drawings of the shader
+// have already created their descriptors and instance data from it.
+func (s *ShaderCache) reload(shaderData ShaderDataCompiled) error {
+	defer tracing.NewRegion("ShaderCache::reload").End()
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	for _, shader := range s.shaders {
+		if shader == nil || shader.data.Name != shaderData.Name {
+			continue
+		}
+		if !reflect.DeepEqual(shader.data.LayoutGroups, shaderData.LayoutGroups) {
+			return errHotReloadLayout
+		}
+		shader.data = shaderData
+		s.rebuild(shader)
+	}
+	return nil
+}
+
+// rebuildRenderPass recreates all of the shaders that draw to the render pass
+// so that their pipelines match the pass after it has been reconstructed
+func (s *ShaderCache) rebuildRenderPass(pass *RenderPass) {
+	defer tracing.NewRegion("ShaderCache::rebuildRenderPass").End()
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	for _, shader := range s.shaders {
+		if shader != nil && shader.renderPass == pass {
+			s.rebuild(shader)
+		}
+	}
+}
+
+// rebuildShader recreates the shader with its current data and pipeline
+func (s *ShaderCache) rebuildShader(shader *Shader) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.rebuild(shader)
+}
+
+// rebuild destroys and creates the shader in place, everything that holds the
+// shader keeps using it. Shaders that are still pending are skipped as they
+// will be created with the latest data anyway.
+func (s *ShaderCache) rebuild(shader *Shader) {
+	if slices.Contains(s.pendingShaders, shader) {
+		return
+	}
+	s.renderer.DestroyShader(shader)
+	shader.RenderId = ShaderId{}
+	shader.DriverData = NewShaderDriverData()
+	shader.subShaders = make(map[string]*Shader)
+	shader.DelayedCreate(s.renderer, s.assetDatabase)
+}
+
 func (s *ShaderCache) Destroy() {
 	defer tracing.NewRegion("ShaderCache::Destroy").End()
 	for _, shader := range s.pendingShaders {
@@ -175,7 +175,7 @@ type ShaderPipelineColorBlendAttachmentsCompiled struct {
 }
 
 func (d *ShaderPipelineData) Compile(renderer Renderer) ShaderPipelineDataCompiled {
-	vr := renderer.(*Vulkan)
+	vr, _ := renderer.(*Vulkan)
 	c := ShaderPipelineDataCompiled{
 		Name: d.Name,
 		InputAssembly: ShaderPipelineInputAssemblyCompiled{
@@ -0,0 +1,298 @@
+/******************************************************************************/
+/* shadow.go                                                                  */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package rendering
+
+import (
+	"kaiju/engine/cameras"
+	"kaiju/matrix"
+	"sync/atomic"
+	"unsafe"
+)
+
+const (
+	// MaxShadowCascades is the number of cascades a directional light can
+	// split the view into, each cascade has its own slot in the shadow atlas
+	MaxShadowCascades = 4
+	// MaxShadowSpotLights is the number of spot lights that can cast shadows
+	// in a single frame
+	MaxShadowSpotLights = 4
+	// MaxShadowViews is the number of slots in the shadow atlas, the first
+	// MaxShadowCascades slots are for the directional light cascades and the
+	// rest are for spot lights
+	MaxShadowViews     = MaxShadowCascades + MaxShadowSpotLights
+	ShadowAtlasColumns = 4
+	ShadowAtlasRows    = MaxShadowViews / ShadowAtlasColumns
+)
+
+// ShadowSettings controls the shadows of the lights in a LightList. The depth
+// bias, atlas size and filtering radius are part of the shadow render pass,
+// pipeline and shader assets so that they can be tuned in the shader designer.
+type ShadowSettings struct {
+	// Enabled is set by Drawings.EnableShadows once the shadow pass is
+	// ready, clearing it will stop the lights from casting shadows
+	Enabled bool
+	// Distance is how far from the camera the directional light cascades
+	// reach, anything further away will not receive a shadow
+	Distance matrix.Float
+	// Cascades is the number of cascades used for the directional light, it
+	// is clamped to MaxShadowCascades
+	Cascades int
+	// SplitLambda blends between uniform (0) and logarithmic (1) distances
+	// for splitting the cascades
+	SplitLambda matrix.Float
+	// NormalBias pushes the receiving surface along its normal by this many
+	// shadow map texels before sampling, this removes shadow acne on
+	// surfaces that are nearly parallel to the light
+	NormalBias matrix.Float
+}
+
+func DefaultShadowSettings() ShadowSettings {
+	return ShadowSettings{
+		Distance:    50,
+		Cascades:    MaxShadowCascades,
+		SplitLambda: 0.75,
+		NormalBias:  1.5,
+	}
+}
+
+// shadowViews is the set of atlas slots that were written this frame, shadow
+// casters skip the slots that are not in use
+type shadowViews struct {
+	active atomic.Uint32
+}
+
+func (s *shadowViews) isActive(slot int32) bool {
+	return s.active.Load()&(1<<slot) != 0
+}
+
+// writeShadowData assigns the atlas slots to the visible lights that cast
+// shadows and writes their matrices into the global shader data. Only the
+// first directional light that casts shadows gets the cascades.
+func (l *LightList) writeShadowData(data *GlobalShaderData, camera cameras.Camera) {
+	for i := range data.Lights {
+		data.Lights[i].Cone[2] = -1
+	}
+	mask := uint32(0)
+	defer func() { l.shadowViews.active.Store(mask) }()
+	if !l.Shadows.Enabled {
+		return
+	}
+	cascades := min(max(l.Shadows.Cascades, 1), MaxShadowCascades)
+	data.ShadowParams = matrix.Vec4{matrix.Float(cascades), l.Shadows.NormalBias, 0, 0}
+	hasDirectional := false
+	spot := 0
+	for i, light := range l.visible {
+		if !light.CastShadows {
+			continue
+		}
+		switch light.Type {
+		case LightTypeDirectional:
+			if hasDirectional {
+				continue
+			}
+			hasDirectional = true
+			splits := shadowCascadeSplits(camera, l.Shadows, cascades)
+			near := matrix.Float(camera.NearPlane())
+			for c := range cascades {
+				data.ShadowMatrices[c] = shadowCascadeMatrix(light.Direction,
+					camera, near, splits[c], l.shadowSlotSize)
+				data.ShadowCascadeSplits[c] = splits[c]
+				near = splits[c]
+				mask |= 1 << c
+			}
+			data.Lights[i].Cone[2] = 0
+		case LightTypeSpot:
+			if spot >= MaxShadowSpotLights {
+				continue
+			}
+			slot := MaxShadowCascades + spot
+			data.ShadowMatrices[slot] = shadowSpotMatrix(light)
+			data.Lights[i].Cone[2] = matrix.Float(slot)
+			mask |= 1 << slot
+			spot++
+		}
+	}
+}
+
+// shadowCascadeSplits returns the view distance that each cascade ends at,
+// using a blend of uniform and logarithmic splits (practical split scheme)
+func shadowCascadeSplits(camera cameras.Camera, settings ShadowSettings, cascades int) [MaxShadowCascades]matrix.Float {
+	var splits [MaxShadowCascades]matrix.Float
+	near := max(matrix.Float(camera.NearPlane()), 0.001)
+	far := matrix.Float(camera.FarPlane())
+	if settings.Distance > 0 {
+		far = min(far, near+settings.Distance)
+	}
+	lambda := matrix.Clamp(settings.SplitLambda, 0, 1)
+	for i := range cascades {
+		p := matrix.Float(i+1) / matrix.Float(cascades)
+		log := near * matrix.Pow(far/near, p)
+		uniform := near + (far-near)*p
+		splits[i] = lambda*log + (1-lambda)*uniform
+	}
+	return splits
+}
+
+// shadowFrustumCorners returns the world space corners of the camera frustum
+// between the near and far view distances
+func shadowFrustumCorners(camera cameras.Camera, near, far matrix.Float) [8]matrix.Vec3 {
+	inv := matrix.Mat4Multiply(camera.View(), camera.Projection())
+	inv.Inverse()
+	nearZ := matrix.Float(-1)
+	if camera.IsOrthographic() {
+		nearZ = 0
+	}
+	camNear := matrix.Float(camera.NearPlane())
+	camFar := matrix.Float(camera.FarPlane())
+	depth := max(camFar-camNear, matrix.FloatSmallestNonzero)
+	tNear := (near - camNear) / depth
+	tFar := (far - camNear) / depth
+	var corners [8]matrix.Vec3
+	for i := range 4 {
+		x := matrix.Float((i&1)*2 - 1)
+		y := matrix.Float((i>>1&1)*2 - 1)
+		n := matrix.Mat4MultiplyVec4(inv, matrix.Vec4{x, y, nearZ, 1})
+		f := matrix.Mat4MultiplyVec4(inv, matrix.Vec4{x, y, 1, 1})
+		np := matrix.Vec3{n.X() / n.W(), n.Y() / n.W(), n.Z() / n.W()}
+		fp := matrix.Vec3{f.X() / f.W(), f.Y() / f.W(), f.Z() / f.W()}
+		ray := fp.Subtract(np)
+		corners[i] = np.Add(ray.Scale(tNear))
+		corners[i+4] = np.Add(ray.Scale(tFar))
+	}
+	return corners
+}
+
+// shadowCascadeMatrix fits an orthographic projection around the bounding
+// sphere of the cascade's frustum slice. Using a sphere keeps the size of
+// the projection the same while the camera rotates, and snapping it to the
+// shadow map texels stops the edges of shadows from shimmering as it moves.
+func shadowCascadeMatrix(direction matrix.Vec3, camera cameras.Camera, near, far, slotSize matrix.Float) matrix.Mat4 {
+	corners := shadowFrustumCorners(camera, near, far)
+	center := matrix.Vec3Zero()
+	for i := range corners {
+		center = center.Add(corners[i])
+	}
+	center = center.Scale(1.0 / matrix.Float(len(corners)))
+	radius := matrix.Float(0)
+	for i := range corners {
+		radius = max(radius, corners[i].Distance(center))
+	}
+	radius = matrix.Ceil(radius*16) / 16
+	dir := shadowLightDirection(direction)
+	// Casters that are outside of the sphere but between it and the light
+	// still need to be in the depth range to cast onto the cascade
+	pullBack := radius + max(radius, 50)
+	view := matrix.Mat4LookAt(center.Subtract(dir.Scale(pullBack)), center, shadowLightUp(dir))
+	projection := matrix.Mat4Identity()
+	projection.Orthographic(-radius, radius, -radius, radius, 0, pullBack+radius)
+	origin := matrix.Mat4MultiplyVec4(matrix.Mat4Multiply(view, projection),
+		matrix.Vec4{0, 0, 0, 1})
+	half := slotSize * 0.5
+	projection[matrix.Mat4x0y3] += (matrix.Round(origin.X()*half) - origin.X()*half) / half
+	projection[matrix.Mat4x1y3] += (matrix.Round(origin.Y()*half) - origin.Y()*half) / half
+	return matrix.Mat4Multiply(view, projection)
+}
+
+// shadowSpotMatrix is the perspective view of the spot light's cone, the
+// depth is remapped from [-1, 1] to the [0, 1] range of the depth buffer
+func shadowSpotMatrix(light *Light) matrix.Mat4 {
+	dir := shadowLightDirection(light.Direction)
+	view := matrix.Mat4LookAt(light.Position, light.Position.Add(dir), shadowLightUp(dir))
+	fov := matrix.Deg2Rad(matrix.Clamp(max(light.OuterAngle, light.InnerAngle)*2, 1, 170))
+	projection := matrix.Mat4Identity()
+	projection.Perspective(fov, 1, max(light.Range*0.01, 0.05), max(light.Range, 0.1))
+	depthFix := matrix.Mat4Identity()
+	depthFix[matrix.Mat4x2y2] = 0.5
+	depthFix[matrix.Mat4x2y3] = 0.5
+	return matrix.Mat4Multiply(view, matrix.Mat4Multiply(projection, depthFix))
+}
+
+func shadowLightDirection(direction matrix.Vec3) matrix.Vec3 {
+	if direction.IsZero() {
+		return matrix.Vec3Down()
+	}
+	return direction.Normal()
+}
+
+func shadowLightUp(direction matrix.Vec3) matrix.Vec3 {
+	if matrix.Abs(direction.Y()) > 0.99 {
+		return matrix.Vec3Backward()
+	}
+	return matrix.Vec3Up()
+}
+
+// shadowCaster draws a shadow casting instance into one slot of the shadow
+// atlas. It shares the model matrix of the instance it wraps and follows its
+// lifetime, so destroying or deactivating the drawing also removes it from
+// the shadow pass.
+type shadowCaster struct {
+	caster DrawInstance
+	views  *shadowViews
+	data   shadowCasterData
+}
+
+type shadowCasterData struct {
+	model matrix.Mat4
+	slot  int32
+}
+
+func (s *shadowCaster) Destroy()                    {}
+func (s *shadowCaster) IsDestroyed() bool           { return s.caster.IsDestroyed() }
+func (s *shadowCaster) Activate()                   {}
+func (s *shadowCaster) Deactivate()                 {}
+func (s *shadowCaster) DataPointer() unsafe.Pointer { return unsafe.Pointer(&s.data) }
+
+func (s *shadowCaster) IsActive() bool {
+	return s.caster.IsActive() && s.views.isActive(s.data.slot)
+}
+
+func (s *shadowCaster) Size() int {
+	return int(unsafe.Sizeof(shadowCasterData{}))
+}
+
+func (s *shadowCaster) SetModel(model matrix.Mat4) {}
+
+func (s *shadowCaster) UpdateModel() {
+	s.caster.UpdateModel()
+	s.data.model = *(*matrix.Mat4)(s.caster.DataPointer())
+}
+
+func (s *shadowCaster) UpdateNamedData(index, capacity int, name string) bool { return false }
+func (s *shadowCaster) NamedDataPointer(name string) unsafe.Pointer           { return nil }
+func (s *shadowCaster) NamedDataInstanceSize(name string) int                 { return 0 }
+func (s *shadowCaster) setTransform(transform *matrix.Transform)              {}
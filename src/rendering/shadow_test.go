@@ -0,0 +1,161 @@
+/******************************************************************************/
+/* shadow_test.go                                                             */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package rendering
+
+import (
+	"kaiju/matrix"
+	"testing"
+)
+
+func shadowProject(m matrix.Mat4, p matrix.Vec3) matrix.Vec3 {
+	c := matrix.Mat4MultiplyVec4(m, p.AsVec4WithW(1))
+	return matrix.Vec3{c.X() / c.W(), c.Y() / c.W(), c.Z() / c.W()}
+}
+
+func shadowInView(p matrix.Vec3) bool {
+	const e = 0.0001
+	return matrix.Abs(p.X()) <= 1+e && matrix.Abs(p.Y()) <= 1+e &&
+		p.Z() >= -e && p.Z() <= 1+e
+}
+
+func TestShadowCascadesCoverTheView(t *testing.T) {
+	camera := testLightCamera()
+	settings := DefaultShadowSettings()
+	splits := shadowCascadeSplits(camera, settings, MaxShadowCascades)
+	near := matrix.Float(camera.NearPlane())
+	for c := range MaxShadowCascades {
+		if splits[c] <= near {
+			t.Fatalf("expected cascade %d to end after %f, got %f", c, near, splits[c])
+		}
+		m := shadowCascadeMatrix(matrix.Vec3{0.3, -1, 0.2}, camera, near, splits[c], 1024)
+		for _, corner := range shadowFrustumCorners(camera, near, splits[c]) {
+			if p := shadowProject(m, corner); !shadowInView(p) {
+				t.Errorf("expected cascade %d to contain %v, projected to %v", c, corner, p)
+			}
+		}
+		near = splits[c]
+	}
+	want := matrix.Float(camera.NearPlane()) + settings.Distance
+	if matrix.Abs(splits[MaxShadowCascades-1]-want) > 0.001 {
+		t.Errorf("expected the last cascade to end at %f, got %f", want, splits[MaxShadowCascades-1])
+	}
+}
+
+func TestShadowSpotMatrix(t *testing.T) {
+	light := NewSpotLight(matrix.Vec3{0, 5, 0}, matrix.Vec3Down(),
+		matrix.ColorWhite(), 1, 10, 20, 30)
+	m := shadowSpotMatrix(light)
+	center := shadowProject(m, matrix.Vec3{0, 0, 0})
+	if matrix.Abs(center.X()) > 0.001 || matrix.Abs(center.Y()) > 0.001 || !shadowInView(center) {
+		t.Errorf("expected the point below the light to be in the center, got %v", center)
+	}
+	if p := shadowProject(m, matrix.Vec3{0, -6, 0}); shadowInView(p) {
+		t.Errorf("expected the point past the range to be outside, got %v", p)
+	}
+	if p := shadowProject(m, matrix.Vec3{5, 0, 0}); shadowInView(p) {
+		t.Errorf("expected the point outside of the cone to be outside, got %v", p)
+	}
+}
+
+func TestShadowSlots(t *testing.T) {
+	lights := NewLightList()
+	lights.Shadows.Enabled = true
+	sun := NewDirectionalLight(matrix.Vec3Down(), matrix.ColorWhite(), 1)
+	sun.CastShadows = true
+	lights.Add(sun)
+	spot := NewSpotLight(matrix.Vec3{0, 2, 0}, matrix.Vec3Down(),
+		matrix.ColorWhite(), 1, 5, 20, 30)
+	spot.CastShadows = true
+	lights.Add(spot)
+	lights.Add(NewPointLight(matrix.Vec3Zero(), matrix.ColorWhite(), 1, 5))
+	data := GlobalShaderData{}
+	lights.writeShaderData(&data, testLightCamera())
+	expected := []matrix.Float{0, MaxShadowCascades, -1}
+	for i, slot := range expected {
+		if data.Lights[i].Cone[2] != slot {
+			t.Errorf("expected light %d to use shadow slot %f, got %f", i, slot, data.Lights[i].Cone[2])
+		}
+	}
+	for slot := range int32(MaxShadowViews) {
+		active := slot < MaxShadowCascades+1
+		if lights.shadowViews.isActive(slot) != active {
+			t.Errorf("expected shadow slot %d active to be %t", slot, active)
+		}
+	}
+	lights.Shadows.Enabled = false
+	lights.writeShaderData(&data, testLightCamera())
+	if data.Lights[0].Cone[2] != -1 || lights.shadowViews.isActive(0) {
+		t.Error("expected no shadow slots while shadows are disabled")
+	}
+}
+
+func TestShadowCasterFollowsDrawing(t *testing.T) {
+	lights := NewLightList()
+	drawings := NewDrawings()
+	drawings.shadowMaterial = &Material{}
+	drawings.shadowViews = &lights.shadowViews
+	sd := &ShaderDataBasic{NewShaderDataBase(), matrix.ColorWhite()}
+	model := matrix.Mat4Identity()
+	model.SetTranslation(matrix.Vec3{1, 2, 3})
+	sd.SetModel(model)
+	drawings.AddDrawing(Drawing{Material: &Material{}, ShaderData: sd, CastShadows: true})
+	drawings.AddDrawing(Drawing{Material: &Material{}, ShaderData: sd})
+	if len(drawings.backDraws) != 2+MaxShadowViews {
+		t.Fatalf("expected %d drawings, got %d", 2+MaxShadowViews, len(drawings.backDraws))
+	}
+	caster := drawings.backDraws[1].ShaderData.(*shadowCaster)
+	caster.UpdateModel()
+	if caster.data.model != model {
+		t.Error("expected the caster to use the model of the drawing")
+	}
+	if caster.IsActive() {
+		t.Error("expected the caster to be inactive while its slot is unused")
+	}
+	lights.shadowViews.active.Store(1)
+	if !caster.IsActive() {
+		t.Error("expected the caster to be active once its slot is used")
+	}
+	sd.Deactivate()
+	if caster.IsActive() {
+		t.Error("expected the caster to follow the drawing being deactivated")
+	}
+	sd.Destroy()
+	if !caster.IsDestroyed() {
+		t.Error("expected the caster to follow the drawing being destroyed")
+	}
+}
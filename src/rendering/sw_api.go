@@ -0,0 +1,263 @@
+/******************************************************************************/
+/* sw_api.go                                                                  */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package rendering
+
+import (
+	"errors"
+	"image"
+	"kaiju/engine/assets"
+	"kaiju/matrix"
+	"kaiju/platform/profiler/tracing"
+	"log/slog"
+	"path"
+	"strings"
+)
+
+type swMesh struct {
+	verts   []Vertex
+	indices []uint32
+}
+
+type swTexture struct {
+	pixels []byte
+	width  int
+	height int
+}
+
+type swShader struct {
+	// useUICamera is true when the vertex shader transforms by the UI camera
+	// matrices rather than the world camera matrices
+	useUICamera bool
+}
+
+func (sr *Software) CreateMesh(mesh *Mesh, verts []Vertex, indices []uint32) {
+	defer tracing.NewRegion("Software::CreateMesh").End()
+	m := &swMesh{
+		verts:   make([]Vertex, len(verts)),
+		indices: make([]uint32, len(indices)),
+	}
+	copy(m.verts, verts)
+	copy(m.indices, indices)
+	mesh.MeshId = MeshId{
+		vertexCount: uint32(len(verts)),
+		indexCount:  uint32(len(indices)),
+		sw:          m,
+	}
+}
+
+// UpdateMesh replaces the vertices and indices of the mesh, the software
+// renderer draws synchronously so the copy can be replaced right away
+func (sr *Software) UpdateMesh(mesh *Mesh, verts []Vertex, indices []uint32) {
+	defer tracing.NewRegion("Software::UpdateMesh").End()
+	m := mesh.MeshId.sw
+	if m == nil {
+		m = &swMesh{}
+	}
+	m.verts = append(m.verts[:0], verts...)
+	m.indices = append(m.indices[:0], indices...)
+	mesh.MeshId = MeshId{
+		vertexCount: uint32(len(verts)),
+		indexCount:  uint32(len(indices)),
+		sw:          m,
+	}
+}
+
+func (sr *Software) DestroyMesh(mesh *Mesh) {
+	defer tracing.NewRegion("Software::DestroyMesh").End()
+	mesh.MeshId = MeshId{}
+}
+
+func (sr *Software) CreateTexture(texture *Texture, data *TextureData) {
+	defer tracing.NewRegion("Software::CreateTexture").End()
+	if data == nil {
+		return
+	}
+	t := &swTexture{width: data.Width, height: data.Height}
+	if t.width == 0 || t.height == 0 {
+		t.width, t.height = texture.Width, texture.Height
+	}
+	t.width, t.height = max(t.width, 1), max(t.height, 1)
+	t.pixels = make([]byte, t.width*t.height*bytesInPixel)
+	count := t.width * t.height
+	switch data.InternalFormat {
+	case TextureInputTypeRgba8:
+		copy(t.pixels, data.Mem)
+	case TextureInputTypeRgb8:
+		for i := 0; i < count && i*3+2 < len(data.Mem); i++ {
+			copy(t.pixels[i*bytesInPixel:], data.Mem[i*3:i*3+3])
+			t.pixels[i*bytesInPixel+3] = 255
+		}
+	case TextureInputTypeLuminance:
+		for i := 0; i < count && i < len(data.Mem); i++ {
+			l := data.Mem[i]
+			copy(t.pixels[i*bytesInPixel:], []byte{l, l, l, 255})
+		}
+	default:
+		slog.Warn("the software renderer can not decode the texture format, using white",
+			"texture", texture.Key, "format", data.InternalFormat)
+		for i := range t.pixels {
+			t.pixels[i] = 255
+		}
+	}
+	texture.RenderId = TextureId{
+		MipLevels:  1,
+		Width:      t.width,
+		Height:     t.height,
+		LayerCount: 1,
+		sw:         t,
+	}
+}
+
+func (sr *Software) TextureReadPixel(texture *Texture, x, y int) matrix.Color {
+	defer tracing.NewRegion("Software::TextureReadPixel").End()
+	t := texture.RenderId.sw
+	if t == nil || x < 0 || y < 0 || x >= t.width || y >= t.height {
+		return matrix.ColorClear()
+	}
+	i := (y*t.width + x) * bytesInPixel
+	return matrix.ColorRGBAInt(int(t.pixels[i]), int(t.pixels[i+1]),
+		int(t.pixels[i+2]), int(t.pixels[i+3]))
+}
+
+func (sr *Software) TextureWritePixels(texture *Texture, x, y, width, height int, pixels []byte) {
+	defer tracing.NewRegion("Software::TextureWritePixels").End()
+	t := texture.RenderId.sw
+	if t == nil {
+		return
+	}
+	for row := 0; row < height; row++ {
+		ty := y + row
+		if ty < 0 || ty >= t.height {
+			continue
+		}
+		for col := 0; col < width; col++ {
+			tx := x + col
+			from := (row*width + col) * bytesInPixel
+			if tx < 0 || tx >= t.width || from+bytesInPixel > len(pixels) {
+				continue
+			}
+			to := (ty*t.width + tx) * bytesInPixel
+			copy(t.pixels[to:to+bytesInPixel], pixels[from:from+bytesInPixel])
+		}
+	}
+}
+
+func (sr *Software) TextureReadPixels(texture *Texture) (*image.RGBA, error) {
+	defer tracing.NewRegion("Software::TextureReadPixels").End()
+	t := texture.RenderId.sw
+	if t == nil {
+		return nil, errors.New("the texture has not been created")
+	}
+	img := image.NewRGBA(image.Rect(0, 0, t.width, t.height))
+	copy(img.Pix, t.pixels)
+	return img, nil
+}
+
+func (sr *Software) DestroyTexture(texture *Texture) {
+	defer tracing.NewRegion("Software::DestroyTexture").End()
+	texture.RenderId = TextureId{}
+}
+
+// CreateRenderTarget makes the texture of the target in memory, the software
+// renderer rasterizes the drawings of the target straight into it
+func (sr *Software) CreateRenderTarget(target *RenderTarget) error {
+	defer tracing.NewRegion("Software::CreateRenderTarget").End()
+	w, h := target.Width(), target.Height()
+	t := &swTexture{pixels: make([]byte, w*h*bytesInPixel), width: w, height: h}
+	target.pass = &RenderPass{construction: target.passData.Compile(nil)}
+	target.texture = &Texture{
+		Key:    target.textureKey(),
+		Width:  w,
+		Height: h,
+		Filter: TextureFilterLinear,
+		RenderId: TextureId{
+			MipLevels:  1,
+			Width:      w,
+			Height:     h,
+			LayerCount: 1,
+			sw:         t,
+		},
+	}
+	target.swDepth = make([]float32, w*h)
+	return nil
+}
+
+func (sr *Software) DestroyRenderTarget(target *RenderTarget) {
+	defer tracing.NewRegion("Software::DestroyRenderTarget").End()
+	target.pass = nil
+	target.texture = nil
+	target.swDepth = nil
+}
+
+func (sr *Software) CreateShader(shader *Shader, assetDatabase *assets.Database) error {
+	defer tracing.NewRegion("Software::CreateShader").End()
+	sr.shaders[shader] = swShader{
+		useUICamera: swShaderUsesUICamera(assetDatabase, &shader.data),
+	}
+	return nil
+}
+
+func (sr *Software) DestroyShader(shader *Shader) {
+	defer tracing.NewRegion("Software::DestroyShader").End()
+	delete(sr.shaders, shader)
+}
+
+// swShaderUsesUICamera looks at the GLSL source of the vertex shader to find
+// out if it is projected through the UI camera. The compiled shader only
+// knows the path to the SPIR-V module, so the source path is derived from it
+// the same way ShaderData.CompileVariantName produced it.
+func swShaderUsesUICamera(assetDatabase *assets.Database, data *ShaderDataCompiled) bool {
+	if assetDatabase == nil || data.Vertex == "" {
+		return false
+	}
+	spv := strings.TrimSuffix(path.Clean(strings.ReplaceAll(data.Vertex, "\\", "/")), ".spv")
+	dir, name := path.Split(strings.Replace(spv, "/spv/", "/src/", 1))
+	candidates := []string{dir + name}
+	if variant := strings.TrimPrefix(name, data.Name+"_"); variant != name {
+		candidates = append(candidates, dir+variant)
+	}
+	for _, src := range candidates {
+		if !assetDatabase.Exists(src) {
+			continue
+		}
+		if txt, err := assetDatabase.ReadText(src); err == nil {
+			return strings.Contains(txt, "uiProjection")
+		}
+	}
+	return false
+}
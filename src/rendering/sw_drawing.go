@@ -0,0 +1,560 @@
+/******************************************************************************/
+/* sw_drawing.go                                                              */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package rendering
+
+import (
+	"kaiju/matrix"
+	"kaiju/platform/profiler/tracing"
+	"unsafe"
+
+	vk "kaiju/rendering/vulkan"
+)
+
+// swClipEpsilon keeps the clipped vertices in front of the camera so the
+// perspective divide never divides by zero
+const swClipEpsilon = 1e-5
+
+type swPassDraw struct {
+	renderPass *RenderPass
+	drawings   []ShaderDraw
+}
+
+type swVertex struct {
+	position matrix.Vec4
+	uv       matrix.Vec2
+	color    matrix.Color
+}
+
+type swScreenVertex struct {
+	x, y, z matrix.Float
+	invW    matrix.Float
+	uv      matrix.Vec2
+	color   matrix.Color
+}
+
+type swDrawState struct {
+	texture    *swTexture
+	nearest    bool
+	blend      bool
+	depthTest  bool
+	depthWrite bool
+	depthOp    vk.CompareOp
+	cull       vk.CullModeFlags
+	frontCCW   bool
+}
+
+// swInstanceLayout is the byte offset of the per-instance shader inputs that
+// the software renderer understands, -1 when the shader doesn't have them
+type swInstanceLayout struct {
+	model int
+	color int
+	uvs   int
+}
+
+func (sr *Software) Draw(renderPass *RenderPass, drawings []ShaderDraw) bool {
+	defer tracing.NewRegion("Software::Draw").End()
+	if renderPass == nil || (len(drawings) == 0 && renderPass.target == nil) {
+		return false
+	}
+	drawingAnything := false
+	for i := range drawings {
+		groups := drawings[i].instanceGroups
+		for j := range groups {
+			group := &groups[j]
+			if !group.IsReady() {
+				continue
+			}
+			group.UpdateData(sr)
+			drawingAnything = drawingAnything || group.AnyVisible()
+		}
+	}
+	if renderPass.target != nil {
+		sr.drawRenderTarget(renderPass.target, drawings)
+	} else if drawingAnything {
+		sr.pendingDraws = append(sr.pendingDraws, swPassDraw{renderPass, drawings})
+	}
+	return drawingAnything
+}
+
+// drawRenderTarget rasterizes the drawings straight into the texture of the
+// render target, this happens in Draw so that the texture is ready before
+// the passes that sample it are rasterized in BlitTargets
+func (sr *Software) drawRenderTarget(target *RenderTarget, drawings []ShaderDraw) {
+	defer tracing.NewRegion("Software::drawRenderTarget").End()
+	if target.texture == nil {
+		return
+	}
+	t := target.texture.RenderId.sw
+	color, depth, width, height := sr.color, sr.depth, sr.width, sr.height
+	globals, clearColor := sr.globals, sr.ClearColor
+	defer func() {
+		sr.color, sr.depth, sr.width, sr.height = color, depth, width, height
+		sr.globals, sr.ClearColor = globals, clearColor
+	}()
+	sr.color, sr.depth, sr.width, sr.height = t.pixels, target.swDepth, t.width, t.height
+	sr.globals = renderTargetGlobals(globals, target.Camera,
+		matrix.Float(t.width), matrix.Float(t.height))
+	sr.ClearColor = target.ClearColor
+	sr.clear()
+	for i := range drawings {
+		sr.drawShader(&drawings[i])
+	}
+}
+
+// BlitTargets rasterizes the drawings that were submitted through Draw, the
+// passes are already sorted so this is where the draw order is known
+func (sr *Software) BlitTargets(passes []*RenderPass) {
+	defer tracing.NewRegion("Software::BlitTargets").End()
+	for _, pass := range passes {
+		if swPassClearsDepth(pass) {
+			sr.clearDepth()
+		}
+		for i := range sr.pendingDraws {
+			if sr.pendingDraws[i].renderPass != pass {
+				continue
+			}
+			drawings := sr.pendingDraws[i].drawings
+			for j := range drawings {
+				sr.drawShader(&drawings[j])
+			}
+		}
+	}
+	sr.pendingDraws = sr.pendingDraws[:0]
+}
+
+func swPassClearsDepth(pass *RenderPass) bool {
+	for i := range pass.construction.AttachmentDescriptions {
+		a := &pass.construction.AttachmentDescriptions[i]
+		if a.IsDepthFormat() {
+			return a.LoadOp == vk.AttachmentLoadOpClear
+		}
+	}
+	return false
+}
+
+func swInstanceLayoutOf(info *ShaderDataCompiled) swInstanceLayout {
+	layout := swInstanceLayout{-1, -1, -1}
+	g := info.SelectLayout("Vertex")
+	if g == nil {
+		return layout
+	}
+	offset := 0
+	for i := range g.Layouts {
+		l := &g.Layouts[i]
+		if l.Source != "in" || l.Location < baseVertexAttributeCount {
+			continue
+		}
+		switch {
+		case l.Type == "mat4" && l.Name == "model":
+			layout.model = offset
+		case l.Type == "vec4" && (l.Name == "color" || l.Name == "fgColor"):
+			if layout.color < 0 {
+				layout.color = offset
+			}
+		case l.Type == "vec4" && l.Name == "uvs":
+			layout.uvs = offset
+		}
+		offset += fieldSize(l.Type, l.FullName())
+	}
+	return layout
+}
+
+func (sr *Software) drawShader(draw *ShaderDraw) {
+	material := draw.material
+	if material == nil || material.Shader == nil {
+		return
+	}
+	view, projection := sr.globals.View, sr.globals.Projection
+	if sr.shaders[material.Shader].useUICamera {
+		view, projection = sr.globals.UIView, sr.globals.UIProjection
+	}
+	viewProjection := matrix.Mat4Multiply(view, projection)
+	layout := swInstanceLayoutOf(&material.shaderInfo)
+	pipe := &material.pipelineInfo
+	state := swDrawState{
+		depthTest:  pipe.DepthStencil.DepthTestEnable == vk.True,
+		depthWrite: pipe.DepthStencil.DepthWriteEnable == vk.True,
+		depthOp:    pipe.DepthStencil.DepthCompareOp,
+		cull:       pipe.Rasterization.CullMode,
+		frontCCW:   pipe.Rasterization.FrontFace == vk.FrontFaceCounterClockwise,
+	}
+	if len(pipe.ColorBlendAttachments) > 0 {
+		state.blend = pipe.ColorBlendAttachments[0].BlendEnable == vk.True
+	}
+	for i := range draw.instanceGroups {
+		group := &draw.instanceGroups[i]
+		if !group.IsReady() || !group.AnyVisible() || group.Mesh.MeshId.sw == nil {
+			continue
+		}
+		state.texture, state.nearest = sr.groupTexture(group)
+		stride := group.instanceSize + group.rawData.padding
+		for j := range group.VisibleCount() {
+			start := j * stride
+			if start+group.instanceSize > len(group.rawData.bytes) {
+				break
+			}
+			data := group.rawData.bytes[start : start+group.instanceSize]
+			model := matrix.Mat4Identity()
+			if layout.model >= 0 && layout.model+mat4Size <= len(data) {
+				model = *(*matrix.Mat4)(unsafe.Pointer(&data[layout.model]))
+			}
+			tint := matrix.ColorWhite()
+			if layout.color >= 0 && layout.color+vec4Size <= len(data) {
+				tint = *(*matrix.Color)(unsafe.Pointer(&data[layout.color]))
+			}
+			var uvs *matrix.Vec4
+			if layout.uvs >= 0 && layout.uvs+vec4Size <= len(data) {
+				uvs = (*matrix.Vec4)(unsafe.Pointer(&data[layout.uvs]))
+			}
+			mvp := matrix.Mat4Multiply(model, viewProjection)
+			sr.drawMesh(group.Mesh.MeshId.sw, pipe.InputAssembly.Topology,
+				mvp, tint, uvs, &state)
+		}
+	}
+}
+
+func (sr *Software) groupTexture(group *DrawInstanceGroup) (*swTexture, bool) {
+	if group.MaterialInstance != nil {
+		for _, t := range group.MaterialInstance.Textures {
+			if t != nil && t.RenderId.sw != nil {
+				return t.RenderId.sw, t.Filter == TextureFilterNearest
+			}
+		}
+	}
+	if sr.defaultTexture != nil && sr.defaultTexture.RenderId.sw != nil {
+		return sr.defaultTexture.RenderId.sw, false
+	}
+	return nil, false
+}
+
+func (sr *Software) drawMesh(mesh *swMesh, topology vk.PrimitiveTopology, mvp matrix.Mat4, tint matrix.Color, uvs *matrix.Vec4, state *swDrawState) {
+	verts := make([]swVertex, len(mesh.verts))
+	for i := range mesh.verts {
+		v := &mesh.verts[i]
+		verts[i].position = matrix.Mat4MultiplyVec4(mvp, v.Position.AsVec4())
+		uv := v.UV0
+		if uvs != nil {
+			uv = matrix.Vec2{uv.X() * uvs.Z(), uv.Y() * uvs.W()}
+			uv[matrix.Vy] += (1.0 - uvs.W()) - uvs.Y()
+			uv[matrix.Vx] += uvs.X()
+		}
+		verts[i].uv = uv
+		verts[i].color = matrix.Color{
+			v.Color.R() * tint.R(), v.Color.G() * tint.G(),
+			v.Color.B() * tint.B(), v.Color.A() * tint.A(),
+		}
+	}
+	idx := mesh.indices
+	switch topology {
+	case vk.PrimitiveTopologyTriangleList:
+		for i := 0; i+2 < len(idx); i += 3 {
+			sr.drawTriangle([3]swVertex{verts[idx[i]], verts[idx[i+1]], verts[idx[i+2]]}, state)
+		}
+	case vk.PrimitiveTopologyTriangleStrip:
+		for i := 0; i+2 < len(idx); i++ {
+			if i%2 == 0 {
+				sr.drawTriangle([3]swVertex{verts[idx[i]], verts[idx[i+1]], verts[idx[i+2]]}, state)
+			} else {
+				sr.drawTriangle([3]swVertex{verts[idx[i+1]], verts[idx[i]], verts[idx[i+2]]}, state)
+			}
+		}
+	case vk.PrimitiveTopologyLineList:
+		for i := 0; i+1 < len(idx); i += 2 {
+			sr.drawLine(verts[idx[i]], verts[idx[i+1]], state)
+		}
+	case vk.PrimitiveTopologyLineStrip:
+		for i := 0; i+1 < len(idx); i++ {
+			sr.drawLine(verts[idx[i]], verts[idx[i+1]], state)
+		}
+	}
+}
+
+// swClipPlanes returns the signed distance of a clip space position to the
+// near (Vulkan uses a 0 to w depth range), far, and w planes
+var swClipPlanes = [...]func(p matrix.Vec4) matrix.Float{
+	func(p matrix.Vec4) matrix.Float { return p.Z() },
+	func(p matrix.Vec4) matrix.Float { return p.W() - p.Z() },
+	func(p matrix.Vec4) matrix.Float { return p.W() - swClipEpsilon },
+}
+
+func swLerpVertex(a, b swVertex, t matrix.Float) swVertex {
+	var v swVertex
+	for i := range v.position {
+		v.position[i] = a.position[i] + (b.position[i]-a.position[i])*t
+	}
+	for i := range v.uv {
+		v.uv[i] = a.uv[i] + (b.uv[i]-a.uv[i])*t
+	}
+	for i := range v.color {
+		v.color[i] = a.color[i] + (b.color[i]-a.color[i])*t
+	}
+	return v
+}
+
+func swClipPolygon(poly []swVertex) []swVertex {
+	for _, plane := range swClipPlanes {
+		if len(poly) == 0 {
+			break
+		}
+		out := make([]swVertex, 0, len(poly)+1)
+		for i := range poly {
+			a, b := poly[i], poly[(i+1)%len(poly)]
+			da, db := plane(a.position), plane(b.position)
+			if da >= 0 {
+				out = append(out, a)
+			}
+			if (da >= 0) != (db >= 0) {
+				out = append(out, swLerpVertex(a, b, da/(da-db)))
+			}
+		}
+		poly = out
+	}
+	return poly
+}
+
+func (sr *Software) toScreen(v swVertex) swScreenVertex {
+	invW := 1 / v.position.W()
+	s := swScreenVertex{
+		x:    (v.position.X()*invW*0.5 + 0.5) * matrix.Float(sr.width),
+		y:    (v.position.Y()*invW*0.5 + 0.5) * matrix.Float(sr.height),
+		z:    v.position.Z() * invW,
+		invW: invW,
+	}
+	s.uv = matrix.Vec2{v.uv.X() * invW, v.uv.Y() * invW}
+	for i := range v.color {
+		s.color[i] = v.color[i] * invW
+	}
+	return s
+}
+
+func swEdge(a, b *swScreenVertex, x, y matrix.Float) matrix.Float {
+	return (b.x-a.x)*(y-a.y) - (b.y-a.y)*(x-a.x)
+}
+
+// swEdgeOwnsTies decides which of the two triangles sharing an edge owns the
+// pixels that land exactly on it, so they are not drawn twice
+func swEdgeOwnsTies(a, b *swScreenVertex) bool {
+	dx, dy := b.x-a.x, b.y-a.y
+	return dy > 0 || (dy == 0 && dx < 0)
+}
+
+func (sr *Software) drawTriangle(tri [3]swVertex, state *swDrawState) {
+	poly := swClipPolygon(tri[:])
+	if len(poly) < 3 {
+		return
+	}
+	screen := make([]swScreenVertex, len(poly))
+	for i := range poly {
+		screen[i] = sr.toScreen(poly[i])
+	}
+	for i := 1; i+1 < len(screen); i++ {
+		sr.rasterizeTriangle(screen[0], screen[i], screen[i+1], state)
+	}
+}
+
+func (sr *Software) rasterizeTriangle(v0, v1, v2 swScreenVertex, state *swDrawState) {
+	area := swEdge(&v0, &v1, v2.x, v2.y)
+	if area == 0 {
+		return
+	}
+	// Vulkan measures the winding in frame buffer space where y points down,
+	// which flips the sign of the area compared to the usual convention
+	front := state.frontCCW == (area < 0)
+	if (front && state.cull&vk.CullModeFlags(vk.CullModeFrontBit) != 0) ||
+		(!front && state.cull&vk.CullModeFlags(vk.CullModeBackBit) != 0) {
+		return
+	}
+	if area < 0 {
+		v1, v2 = v2, v1
+		area = -area
+	}
+	minX := max(int(matrix.Floor(min(v0.x, v1.x, v2.x))), 0)
+	maxX := min(int(matrix.Ceil(max(v0.x, v1.x, v2.x))), sr.width-1)
+	minY := max(int(matrix.Floor(min(v0.y, v1.y, v2.y))), 0)
+	maxY := min(int(matrix.Ceil(max(v0.y, v1.y, v2.y))), sr.height-1)
+	owns := [3]bool{swEdgeOwnsTies(&v1, &v2), swEdgeOwnsTies(&v2, &v0), swEdgeOwnsTies(&v0, &v1)}
+	invArea := 1 / area
+	for y := minY; y <= maxY; y++ {
+		py := matrix.Float(y) + 0.5
+		for x := minX; x <= maxX; x++ {
+			px := matrix.Float(x) + 0.5
+			w := [3]matrix.Float{swEdge(&v1, &v2, px, py), swEdge(&v2, &v0, px, py), swEdge(&v0, &v1, px, py)}
+			inside := true
+			for i := range w {
+				if w[i] < 0 || (w[i] == 0 && !owns[i]) {
+					inside = false
+					break
+				}
+			}
+			if !inside {
+				continue
+			}
+			b0, b1, b2 := w[0]*invArea, w[1]*invArea, w[2]*invArea
+			z := b0*v0.z + b1*v1.z + b2*v2.z
+			invW := b0*v0.invW + b1*v1.invW + b2*v2.invW
+			uv := matrix.Vec2{
+				(b0*v0.uv.X() + b1*v1.uv.X() + b2*v2.uv.X()) / invW,
+				(b0*v0.uv.Y() + b1*v1.uv.Y() + b2*v2.uv.Y()) / invW,
+			}
+			var color matrix.Color
+			for i := range color {
+				color[i] = (b0*v0.color[i] + b1*v1.color[i] + b2*v2.color[i]) / invW
+			}
+			sr.shadeFragment(x, y, z, uv, color, state)
+		}
+	}
+}
+
+func (sr *Software) drawLine(a, b swVertex, state *swDrawState) {
+	for _, plane := range swClipPlanes {
+		da, db := plane(a.position), plane(b.position)
+		if da < 0 && db < 0 {
+			return
+		} else if da < 0 {
+			a = swLerpVertex(a, b, da/(da-db))
+		} else if db < 0 {
+			b = swLerpVertex(a, b, da/(da-db))
+		}
+	}
+	sa, sb := sr.toScreen(a), sr.toScreen(b)
+	steps := int(matrix.Ceil(max(matrix.Abs(sb.x-sa.x), matrix.Abs(sb.y-sa.y))))
+	for i := 0; i <= steps; i++ {
+		t := matrix.Float(0)
+		if steps > 0 {
+			t = matrix.Float(i) / matrix.Float(steps)
+		}
+		x := int(matrix.Floor(sa.x + (sb.x-sa.x)*t))
+		y := int(matrix.Floor(sa.y + (sb.y-sa.y)*t))
+		if x < 0 || y < 0 || x >= sr.width || y >= sr.height {
+			continue
+		}
+		invW := sa.invW + (sb.invW-sa.invW)*t
+		uv := matrix.Vec2{
+			(sa.uv.X() + (sb.uv.X()-sa.uv.X())*t) / invW,
+			(sa.uv.Y() + (sb.uv.Y()-sa.uv.Y())*t) / invW,
+		}
+		var color matrix.Color
+		for c := range color {
+			color[c] = (sa.color[c] + (sb.color[c]-sa.color[c])*t) / invW
+		}
+		sr.shadeFragment(x, y, sa.z+(sb.z-sa.z)*t, uv, color, state)
+	}
+}
+
+func swDepthPasses(op vk.CompareOp, z, stored matrix.Float) bool {
+	switch op {
+	case vk.CompareOpNever:
+		return false
+	case vk.CompareOpLess:
+		return z < stored
+	case vk.CompareOpEqual:
+		return z == stored
+	case vk.CompareOpLessOrEqual:
+		return z <= stored
+	case vk.CompareOpGreater:
+		return z > stored
+	case vk.CompareOpNotEqual:
+		return z != stored
+	case vk.CompareOpGreaterOrEqual:
+		return z >= stored
+	default:
+		return true
+	}
+}
+
+func (sr *Software) shadeFragment(x, y int, z matrix.Float, uv matrix.Vec2, color matrix.Color, state *swDrawState) {
+	i := y*sr.width + x
+	if state.depthTest && !swDepthPasses(state.depthOp, z, sr.depth[i]) {
+		return
+	}
+	if state.texture != nil {
+		texel := state.texture.sample(uv, state.nearest)
+		for c := range color {
+			color[c] *= texel[c]
+		}
+	}
+	px := sr.color[i*bytesInPixel : i*bytesInPixel+bytesInPixel]
+	if state.blend {
+		a := matrix.Clamp(color.A(), 0, 1)
+		for c := 0; c < 3; c++ {
+			color[c] = color[c]*a + matrix.Float(px[c])/255*(1-a)
+		}
+		color[matrix.A] = a + matrix.Float(px[3])/255*(1-a)
+	}
+	for c := range px {
+		px[c] = swUnitToByte(color[c])
+	}
+	if state.depthTest && state.depthWrite {
+		sr.depth[i] = z
+	}
+}
+
+func (t *swTexture) texel(x, y int) matrix.Color {
+	x = ((x % t.width) + t.width) % t.width
+	y = ((y % t.height) + t.height) % t.height
+	i := (y*t.width + x) * bytesInPixel
+	p := t.pixels[i : i+bytesInPixel]
+	return matrix.Color{matrix.Float(p[0]) / 255, matrix.Float(p[1]) / 255,
+		matrix.Float(p[2]) / 255, matrix.Float(p[3]) / 255}
+}
+
+func (t *swTexture) sample(uv matrix.Vec2, nearest bool) matrix.Color {
+	u := uv.X() * matrix.Float(t.width)
+	v := uv.Y() * matrix.Float(t.height)
+	if nearest {
+		return t.texel(int(matrix.Floor(u)), int(matrix.Floor(v)))
+	}
+	u -= 0.5
+	v -= 0.5
+	x0, y0 := matrix.Floor(u), matrix.Floor(v)
+	fx, fy := u-x0, v-y0
+	ix, iy := int(x0), int(y0)
+	c00, c10 := t.texel(ix, iy), t.texel(ix+1, iy)
+	c01, c11 := t.texel(ix, iy+1), t.texel(ix+1, iy+1)
+	var res matrix.Color
+	for c := range res {
+		top := c00[c] + (c10[c]-c00[c])*fx
+		bottom := c01[c] + (c11[c]-c01[c])*fx
+		res[c] = top + (bottom-top)*fy
+	}
+	return res
+}
+
+func swUnitToByte(v matrix.Float) byte {
+	return byte(matrix.Clamp(v, 0, 1)*255 + 0.5)
+}
@@ -0,0 +1,203 @@
+/******************************************************************************/
+/* sw_drawing_test.go                                                         */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package rendering
+
+import (
+	"kaiju/matrix"
+	"testing"
+
+	vk "kaiju/rendering/vulkan"
+)
+
+func testSoftwareMaterial(sr *Software) *Material {
+	m := &Material{
+		Shader: NewShader(ShaderDataCompiled{Name: "test"}),
+		shaderInfo: ShaderDataCompiled{
+			LayoutGroups: []ShaderLayoutGroup{{
+				Type: "Vertex",
+				Layouts: []ShaderLayout{
+					{Location: 8, Type: "mat4", Name: "model", Source: "in"},
+					{Location: 12, Type: "vec4", Name: "color", Source: "in"},
+				},
+			}},
+		},
+	}
+	m.pipelineInfo.InputAssembly.Topology = vk.PrimitiveTopologyTriangleList
+	m.pipelineInfo.Rasterization.CullMode = vk.CullModeFlags(vk.CullModeBackBit)
+	m.pipelineInfo.Rasterization.FrontFace = vk.FrontFaceCounterClockwise
+	m.pipelineInfo.DepthStencil.DepthTestEnable = vk.True
+	m.pipelineInfo.DepthStencil.DepthWriteEnable = vk.True
+	m.pipelineInfo.DepthStencil.DepthCompareOp = vk.CompareOpLess
+	sr.CreateShader(m.Shader, nil)
+	return m
+}
+
+func testSoftwareQuad(sr *Software) *Mesh {
+	verts := make([]Vertex, len(meshQuadCenter))
+	for i := range meshQuadCenter {
+		verts[i].Position = meshQuadCenter[i]
+		verts[i].UV0 = meshQuadUvs[i]
+		verts[i].Color = matrix.ColorWhite()
+	}
+	mesh := NewMesh("quad", verts, meshQuadIndexes[:])
+	mesh.DelayedCreate(sr)
+	return mesh
+}
+
+func testSoftwareInstance(position matrix.Vec3, scale matrix.Vec3, color matrix.Color) *ShaderDataBasic {
+	sd := &ShaderDataBasic{ShaderDataBase: NewShaderDataBase(), Color: color}
+	model := matrix.Mat4Identity()
+	model.Scale(scale)
+	model.Translate(position)
+	sd.SetModel(model)
+	return sd
+}
+
+func testSoftwareRender(sr *Software, material *Material, mesh *Mesh, instances ...DrawInstance) {
+	var projection matrix.Mat4
+	projection.Orthographic(-1, 1, -1, 1, 0, 2)
+	sr.globals.View = matrix.Mat4Identity()
+	sr.globals.Projection = projection
+	sr.clear()
+	group := NewDrawInstanceGroup(mesh, instances[0].Size())
+	group.MaterialInstance = material
+	for i := range instances {
+		group.AddInstance(instances[i])
+	}
+	pass := &RenderPass{}
+	draws := []ShaderDraw{{material: material, instanceGroups: []DrawInstanceGroup{group}}}
+	if sr.Draw(pass, draws) {
+		sr.BlitTargets([]*RenderPass{pass})
+	}
+	sr.SwapFrame(int32(sr.Width()), int32(sr.Height()))
+}
+
+func testColorsMatch(a, b matrix.Color) bool {
+	for i := range a {
+		if matrix.Abs(a[i]-b[i]) > 1.0/255.0 {
+			return false
+		}
+	}
+	return true
+}
+
+func TestSoftwareDrawQuad(t *testing.T) {
+	sr := NewSoftwareRenderer(32, 32)
+	material := testSoftwareMaterial(sr)
+	mesh := testSoftwareQuad(sr)
+	testSoftwareRender(sr, material, mesh, testSoftwareInstance(
+		matrix.Vec3{0.5, 0.5, -0.5}, matrix.Vec3One(), matrix.ColorRed()))
+	// The quad covers the top right quarter of the frame, y points down
+	if c := sr.FramePixel(24, 8); !testColorsMatch(c, matrix.ColorRed()) {
+		t.Errorf("expected the quad to be drawn at the top right, got %v", c)
+	}
+	for _, p := range [][2]int{{8, 8}, {8, 24}, {24, 24}} {
+		if c := sr.FramePixel(p[0], p[1]); !testColorsMatch(c, matrix.ColorBlack()) {
+			t.Errorf("expected pixel %v to be the clear color, got %v", p, c)
+		}
+	}
+	covered := 0
+	for y := range sr.Height() {
+		for x := range sr.Width() {
+			if testColorsMatch(sr.FramePixel(x, y), matrix.ColorRed()) {
+				covered++
+			}
+		}
+	}
+	if covered != 16*16 {
+		t.Errorf("expected the quad to cover %d pixels, covered %d", 16*16, covered)
+	}
+}
+
+func TestSoftwareDepthAndCulling(t *testing.T) {
+	sr := NewSoftwareRenderer(16, 16)
+	material := testSoftwareMaterial(sr)
+	mesh := testSoftwareQuad(sr)
+	full := matrix.Vec3{2, 2, 1}
+	testSoftwareRender(sr, material, mesh,
+		testSoftwareInstance(matrix.Vec3{0, 0, -0.25}, full, matrix.ColorGreen()),
+		testSoftwareInstance(matrix.Vec3{0, 0, -1}, full, matrix.ColorRed()))
+	if c := sr.FramePixel(8, 8); !testColorsMatch(c, matrix.ColorGreen()) {
+		t.Errorf("expected the nearer quad to win the depth test, got %v", c)
+	}
+	mirrored := matrix.Vec3{-2, 2, 1}
+	testSoftwareRender(sr, material, mesh,
+		testSoftwareInstance(matrix.Vec3{0, 0, -0.5}, mirrored, matrix.ColorRed()))
+	if c := sr.FramePixel(8, 8); !testColorsMatch(c, matrix.ColorBlack()) {
+		t.Errorf("expected the back facing quad to be culled, got %v", c)
+	}
+}
+
+func TestSoftwareUpdateMesh(t *testing.T) {
+	sr := NewSoftwareRenderer(32, 32)
+	material := testSoftwareMaterial(sr)
+	mesh := testSoftwareQuad(sr)
+	verts := make([]Vertex, len(meshQuadCenter))
+	for i := range meshQuadCenter {
+		verts[i].Position = meshQuadCenter[i].Add(matrix.Vec3{-0.5, -0.5, 0})
+		verts[i].Color = matrix.ColorWhite()
+	}
+	sr.UpdateMesh(mesh, verts, meshQuadIndexes[:])
+	testSoftwareRender(sr, material, mesh, testSoftwareInstance(
+		matrix.Vec3{0, 0, -0.5}, matrix.Vec3One(), matrix.ColorRed()))
+	if c := sr.FramePixel(8, 24); !testColorsMatch(c, matrix.ColorRed()) {
+		t.Errorf("expected the updated quad to be drawn at the bottom left, got %v", c)
+	}
+	if c := sr.FramePixel(24, 8); !testColorsMatch(c, matrix.ColorBlack()) {
+		t.Errorf("expected the old quad position to be the clear color, got %v", c)
+	}
+	sr.UpdateMesh(mesh, nil, nil)
+	testSoftwareRender(sr, material, mesh, testSoftwareInstance(
+		matrix.Vec3{0, 0, -0.5}, matrix.Vec3One(), matrix.ColorRed()))
+	if c := sr.FramePixel(8, 24); !testColorsMatch(c, matrix.ColorBlack()) {
+		t.Errorf("expected nothing to be drawn for an empty mesh, got %v", c)
+	}
+}
+
+func TestSoftwareTextureSample(t *testing.T) {
+	tex := &swTexture{width: 2, height: 1, pixels: []byte{
+		255, 0, 0, 255, 0, 0, 255, 255,
+	}}
+	if c := tex.sample(matrix.Vec2{0.25, 0.5}, true); !testColorsMatch(c, matrix.ColorRed()) {
+		t.Errorf("expected the nearest sample to be red, got %v", c)
+	}
+	c := tex.sample(matrix.Vec2{0.5, 0.5}, false)
+	if !testColorsMatch(c, matrix.Color{0.5, 0, 0.5, 1}) {
+		t.Errorf("expected the linear sample to blend both texels, got %v", c)
+	}
+}
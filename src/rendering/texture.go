@@ -42,9 +42,14 @@ import (
 	"errors"
 	"image"
 	"image/draw"
+	"image/jpeg"
 	"image/png"
 	"kaiju/engine/assets"
 	"kaiju/matrix"
+	"kaiju/rendering/texture_processing"
+	"log/slog"
+	"math/bits"
+	"path/filepath"
 	"strings"
 )
 
@@ -89,6 +94,12 @@ const (
 	TextureInputTypeRgba8
 	TextureInputTypeRgb8
 	TextureInputTypeLuminance
+	TextureInputTypeCompressedRgbBc1
+	TextureInputTypeCompressedRgbaBc3
+	TextureInputTypeCompressedRgBc5
+	TextureInputTypeCompressedRgbaBc7
+	TextureInputTypeRgba16f
+	TextureInputTypeRgba32f
 )
 
 const (
@@ -97,6 +108,8 @@ const (
 	TextureColorFormatRgbaSrgb
 	TextureColorFormatRgbSrgb
 	TextureColorFormatLuminance
+	TextureColorFormatRgba16Sfloat
+	TextureColorFormatRgba32Sfloat
 )
 
 const (
@@ -107,19 +120,45 @@ const (
 
 const (
 	TextureMemTypeUnsignedByte TextureMemType = iota
+	TextureMemTypeHalfFloat
+	TextureMemTypeFloat
 )
 
 const (
 	TextureFileFormatAstc TextureFileFormat = iota
 	TextureFileFormatPng
 	TextureFileFormatRaw
+	TextureFileFormatKtx2
+	TextureFileFormatJpeg
+	TextureFileFormatTga
+	TextureFileFormatHdr
+	TextureFileFormatDds
+	TextureFileFormatKtx
 )
 
 const (
 	bytesInPixel = 4
 	CubeMapSides = 6
+	// textureMaxDimension is the largest width or height that is read from
+	// a texture file, it matches the 2D image limit of most GPUs and keeps a
+	// corrupt header from allocating more memory than the file could hold
+	textureMaxDimension = 16384
 )
 
+var errTextureTooLarge = errors.New("the texture is larger than the supported size")
+
+// validTextureSize returns true if the width and height are within the sizes
+// that can be read from a texture file
+func validTextureSize(width, height int) bool {
+	return width > 0 && height > 0 &&
+		width <= textureMaxDimension && height <= textureMaxDimension
+}
+
+// textureMaxMipLevels is the number of levels in a full mip chain
+func textureMaxMipLevels(width, height int) int {
+	return bits.Len(uint(max(width, height, 1)))
+}
+
 type TextureData struct {
 	Mem            []byte
 	InternalFormat TextureInputType
@@ -128,6 +167,10 @@ type TextureData struct {
 	Width          int
 	Height         int
 	InputType      TextureFileFormat
+	// MipLevels is the number of mip levels that are in Mem, one after the
+	// other starting with the largest. When it is 0, Mem only holds the full
+	// size image and the renderer generates the mip levels.
+	MipLevels int
 }
 
 type Texture struct {
@@ -141,6 +184,8 @@ type Texture struct {
 	Height            int
 	CacheInvalid      bool
 	pendingData       *TextureData
+	// renderPass is set when the texture is an image of a render pass
+	renderPass *RenderPass
 }
 
 func TextureKeys(textures []*Texture) []string {
@@ -209,31 +254,57 @@ func ReadRawTextureData(mem []byte, inputType TextureFileFormat) TextureData {
 		res.Format = TextureColorFormatRgbaUnorm
 		res.Type = TextureMemTypeUnsignedByte
 	case TextureFileFormatPng:
-		r := bytes.NewReader(mem)
-		if img, err := png.Decode(r); err == nil {
-			var mem []byte
-			switch img.(type) {
-			case *image.RGBA:
-				mem = img.(*image.RGBA).Pix
-			default:
-				b := img.Bounds()
-				dst := image.NewRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
-				draw.Draw(dst, dst.Bounds(), img, b.Min, draw.Src)
-				mem = dst.Pix
-			}
-			res.Width = img.Bounds().Dx()
-			res.Height = img.Bounds().Dy()
-			res.InternalFormat = TextureInputTypeRgba8
-			res.Format = TextureColorFormatRgbaUnorm
-			res.Type = TextureMemTypeUnsignedByte
-			res.Mem = mem
-			//res.Mem = make([]byte, len(mem))
-			//byteWidth := res.Width * bytesInPixel
-			//for y := 0; y < res.Height; y++ {
-			//	from := y * byteWidth
-			//	to := (res.Height - y - 1) * byteWidth
-			//	copy(res.Mem[to:to+byteWidth], mem[from:from+byteWidth])
-			//}
+		if img, err := png.Decode(bytes.NewReader(mem)); err == nil {
+			res.setImage(img)
+		}
+	case TextureFileFormatJpeg:
+		if cfg, err := jpeg.DecodeConfig(bytes.NewReader(mem)); err != nil {
+			slog.Error("failed to decode the JPEG texture", "error", err)
+		} else if !validTextureSize(cfg.Width, cfg.Height) {
+			slog.Error("failed to decode the JPEG texture", "error", errTextureTooLarge)
+		} else if img, err := jpeg.Decode(bytes.NewReader(mem)); err == nil {
+			res.setImage(img)
+		}
+	case TextureFileFormatTga:
+		if w, h, pix, err := decodeTGA(mem); err == nil {
+			res.setRgba8(w, h, pix)
+		} else {
+			slog.Error("failed to decode the TGA texture", "error", err)
+		}
+	case TextureFileFormatHdr:
+		if w, h, pix, err := decodeHDR(mem); err == nil {
+			res.Width = w
+			res.Height = h
+			res.InternalFormat = TextureInputTypeRgba16f
+			res.Format = TextureColorFormatRgba16Sfloat
+			res.Type = TextureMemTypeHalfFloat
+			res.Mem = pix
+		} else {
+			slog.Error("failed to decode the HDR texture", "error", err)
+		}
+	case TextureFileFormatDds:
+		if err := res.readDDS(mem); err != nil {
+			slog.Error("failed to read the DDS texture", "error", err)
+		}
+	case TextureFileFormatKtx:
+		if err := res.readKTX(mem); err != nil {
+			slog.Error("failed to read the KTX texture", "error", err)
+		}
+	case TextureFileFormatKtx2:
+		k, err := texture_processing.DecodeKTX2(mem)
+		if err != nil {
+			break
+		}
+		res.Width = k.Width
+		res.Height = k.Height
+		res.InternalFormat = ktx2InputType(k.Format)
+		// Shaders convert the color to linear themselves, the same as they do
+		// for PNG textures, so the data is always read as unorm
+		res.Format = TextureColorFormatRgbaUnorm
+		res.Type = TextureMemTypeUnsignedByte
+		res.MipLevels = len(k.Levels)
+		for i := range k.Levels {
+			res.Mem = append(res.Mem, k.Levels[i]...)
 		}
 	case TextureFileFormatRaw:
 		res.Mem = mem[:]
@@ -246,15 +317,106 @@ func ReadRawTextureData(mem []byte, inputType TextureFileFormat) TextureData {
 	return res
 }
 
+// setImage sets the data to the pixels of an image that was decoded by one of
+// the standard library image packages
+func (d *TextureData) setImage(img image.Image) {
+	var mem []byte
+	switch img.(type) {
+	case *image.RGBA:
+		mem = img.(*image.RGBA).Pix
+	default:
+		b := img.Bounds()
+		dst := image.NewRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+		draw.Draw(dst, dst.Bounds(), img, b.Min, draw.Src)
+		mem = dst.Pix
+	}
+	d.setRgba8(img.Bounds().Dx(), img.Bounds().Dy(), mem)
+	//d.Mem = make([]byte, len(mem))
+	//byteWidth := d.Width * bytesInPixel
+	//for y := 0; y < d.Height; y++ {
+	//	from := y * byteWidth
+	//	to := (d.Height - y - 1) * byteWidth
+	//	copy(d.Mem[to:to+byteWidth], mem[from:from+byteWidth])
+	//}
+}
+
+func (d *TextureData) setRgba8(width, height int, pix []byte) {
+	d.Width = width
+	d.Height = height
+	d.InternalFormat = TextureInputTypeRgba8
+	d.Format = TextureColorFormatRgbaUnorm
+	d.Type = TextureMemTypeUnsignedByte
+	d.Mem = pix
+}
+
+func ktx2InputType(format texture_processing.Format) TextureInputType {
+	switch format {
+	case texture_processing.FormatBC1:
+		return TextureInputTypeCompressedRgbBc1
+	case texture_processing.FormatBC3:
+		return TextureInputTypeCompressedRgbaBc3
+	case texture_processing.FormatBC5:
+		return TextureInputTypeCompressedRgBc5
+	case texture_processing.FormatBC7:
+		return TextureInputTypeCompressedRgbaBc7
+	case texture_processing.FormatASTC4x4:
+		return TextureInputTypeCompressedRgbaAstc4x4
+	default:
+		return TextureInputTypeRgba8
+	}
+}
+
+// mipLevelSize is the number of bytes of the given mip level of the texture
+// data, it is only valid for the input types that can hold mip levels
+func (d *TextureData) mipLevelSize(level int) int {
+	w, h := max(d.Width>>level, 1), max(d.Height>>level, 1)
+	format := texture_processing.FormatRGBA8
+	switch d.InternalFormat {
+	case TextureInputTypeRgba16f:
+		return w * h * bytesInPixel * 2
+	case TextureInputTypeRgba32f:
+		return w * h * bytesInPixel * 4
+	case TextureInputTypeCompressedRgbBc1:
+		format = texture_processing.FormatBC1
+	case TextureInputTypeCompressedRgbaBc3:
+		format = texture_processing.FormatBC3
+	case TextureInputTypeCompressedRgBc5:
+		format = texture_processing.FormatBC5
+	case TextureInputTypeCompressedRgbaBc7:
+		format = texture_processing.FormatBC7
+	case TextureInputTypeCompressedRgbaAstc4x4:
+		format = texture_processing.FormatASTC4x4
+	}
+	return format.LevelSize(w, h)
+}
+
+// TextureFileFormatFromPath finds the file format of a texture from the
+// extension of its path, unknown extensions are read as raw RGBA data
+func TextureFileFormatFromPath(path string) TextureFileFormat {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".astc":
+		return TextureFileFormatAstc
+	case ".png":
+		return TextureFileFormatPng
+	case ".jpg", ".jpeg":
+		return TextureFileFormatJpeg
+	case ".tga":
+		return TextureFileFormatTga
+	case ".hdr":
+		return TextureFileFormatHdr
+	case ".dds":
+		return TextureFileFormatDds
+	case ".ktx":
+		return TextureFileFormatKtx
+	case ".ktx2":
+		return TextureFileFormatKtx2
+	}
+	return TextureFileFormatRaw
+}
+
 func (t *Texture) createData(imgBuff []byte, overrideWidth, overrideHeight int, key string) TextureData {
-	inputType := TextureFileFormatRaw
 	// TODO:  Use the content system to pull the type from the key
-	if strings.HasSuffix(key, ".astc") {
-		inputType = TextureFileFormatAstc
-	} else if strings.HasSuffix(key, ".png") {
-		inputType = TextureFileFormatPng
-	}
-	data := ReadRawTextureData(imgBuff, inputType)
+	data := ReadRawTextureData(imgBuff, TextureFileFormatFromPath(key))
 	if data.Width == 0 {
 		data.Width = overrideWidth
 	}
@@ -48,6 +48,7 @@ type TextureCache struct {
 	assetDatabase   *assets.Database
 	textures        [TextureFilterMax]map[string]*Texture
 	pendingTextures []*Texture
+	importedData    func(textureKey string) ([]byte, bool)
 	mutex           sync.Mutex
 }
 
@@ -64,11 +65,25 @@ func NewTextureCache(renderer Renderer, assetDatabase *assets.Database) TextureC
 	return tc
 }
 
+// SetImportedDataLookup sets the function that is used to find the data
+// that was generated for a texture when it was imported. The data is a KTX2
+// file holding the already compressed mip levels. When the lookup doesn't
+// find any data, the texture is read from the asset database.
+func (t *TextureCache) SetImportedDataLookup(lookup func(textureKey string) ([]byte, bool)) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	t.importedData = lookup
+}
+
 func (t *TextureCache) Texture(textureKey string, filter TextureFilter) (*Texture, error) {
 	t.mutex.Lock()
 	defer t.mutex.Unlock()
 	if texture, ok := t.textures[filter][textureKey]; ok {
 		return texture, nil
+	} else if texture, ok := t.importedTexture(textureKey, filter); ok {
+		t.pendingTextures = append(t.pendingTextures, texture)
+		t.textures[filter][textureKey] = texture
+		return texture, nil
 	} else {
 		if texture, err := NewTexture(t.renderer, t.assetDatabase, textureKey, filter); err == nil {
 			t.pendingTextures = append(t.pendingTextures, texture)
@@ -80,6 +95,26 @@ func (t *TextureCache) Texture(textureKey string, filter TextureFilter) (*Textur
 	}
 }
 
+func (t *TextureCache) importedTexture(textureKey string, filter TextureFilter) (*Texture, bool) {
+	if t.importedData == nil {
+		return nil, false
+	}
+	mem, ok := t.importedData(textureKey)
+	if !ok {
+		return nil, false
+	}
+	data := ReadRawTextureData(mem, TextureFileFormatKtx2)
+	if data.MipLevels == 0 {
+		return nil, false
+	}
+	texture := &Texture{Key: textureKey, Filter: filter}
+	texture.pendingData = &data
+	texture.Width = data.Width
+	texture.Height = data.Height
+	texture.MipLevels = data.MipLevels
+	return texture, true
+}
+
 func (t *TextureCache) CreatePending() {
 	defer tracing.NewRegion("TextureCache::CreatePending").End()
 	t.mutex.Lock()
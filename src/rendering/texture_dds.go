@@ -0,0 +1,231 @@
+/******************************************************************************/
+/* texture_dds.go                                                             */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package rendering
+
+import (
+	"encoding/binary"
+	"errors"
+	"math/bits"
+)
+
+const (
+	ddsHeaderSize        = 128
+	ddsDX10HeaderSize    = 20
+	ddsFlagMipMapCount   = 0x20000
+	ddsPixelAlphaPixels  = 0x1
+	ddsPixelFourCC       = 0x4
+	ddsPixelRGB          = 0x40
+	ddsPixelLuminance    = 0x20000
+	ddsCaps2CubeMap      = 0x200
+	ddsCaps2Volume       = 0x200000
+	ddsResourceTexture2D = 3
+	// D3DFMT values that are written in place of a four character code
+	ddsD3DFormatRgba16f = 113
+	ddsD3DFormatRgba32f = 116
+	// DXGI_FORMAT values of the DX10 header
+	dxgiFormatRgba32f   = 2
+	dxgiFormatRgba16f   = 10
+	dxgiFormatRgba8     = 28
+	dxgiFormatRgba8Srgb = 29
+	dxgiFormatBC1       = 71
+	dxgiFormatBC1Srgb   = 72
+	dxgiFormatBC3       = 77
+	dxgiFormatBC3Srgb   = 78
+	dxgiFormatBC5       = 83
+	dxgiFormatBgra8     = 87
+	dxgiFormatBgra8Srgb = 91
+	dxgiFormatBC7       = 98
+	dxgiFormatBC7Srgb   = 99
+)
+
+var errInvalidDDS = errors.New("the data is not a valid DDS file")
+
+func ddsFourCC(code string) uint32 {
+	return binary.LittleEndian.Uint32([]byte(code))
+}
+
+// readDDS reads a 2D DirectDraw Surface along with all of its mip levels,
+// cube maps, volumes, and texture arrays are not supported. As with the
+// other file formats, sRGB data is read as unorm since the shaders convert
+// the color to linear themselves.
+func (d *TextureData) readDDS(mem []byte) error {
+	le := binary.LittleEndian
+	if len(mem) < ddsHeaderSize || string(mem[:4]) != "DDS " || le.Uint32(mem[4:]) != 124 {
+		return errInvalidDDS
+	}
+	flags := le.Uint32(mem[8:])
+	d.Height = int(le.Uint32(mem[12:]))
+	d.Width = int(le.Uint32(mem[16:]))
+	if !validTextureSize(d.Width, d.Height) {
+		return errInvalidDDS
+	}
+	levels := 1
+	if flags&ddsFlagMipMapCount != 0 {
+		levels = max(1, int(le.Uint32(mem[28:])))
+	}
+	if levels > textureMaxMipLevels(d.Width, d.Height) {
+		return errInvalidDDS
+	}
+	pixelFlags := le.Uint32(mem[80:])
+	fourCC := le.Uint32(mem[84:])
+	bitCount := int(le.Uint32(mem[88:]))
+	masks := [4]uint32{le.Uint32(mem[92:]), le.Uint32(mem[96:]),
+		le.Uint32(mem[100:]), le.Uint32(mem[104:])}
+	if le.Uint32(mem[112:])&(ddsCaps2CubeMap|ddsCaps2Volume) != 0 {
+		return errors.New("DDS cube maps and volume textures are not supported")
+	}
+	data := mem[ddsHeaderSize:]
+	d.Format = TextureColorFormatRgbaUnorm
+	d.Type = TextureMemTypeUnsignedByte
+	convert := false
+	if pixelFlags&ddsPixelFourCC != 0 {
+		format := uint32(0)
+		switch fourCC {
+		case ddsFourCC("DXT1"):
+			format = dxgiFormatBC1
+		case ddsFourCC("DXT5"):
+			format = dxgiFormatBC3
+		case ddsFourCC("ATI2"), ddsFourCC("BC5U"):
+			format = dxgiFormatBC5
+		case ddsD3DFormatRgba16f:
+			format = dxgiFormatRgba16f
+		case ddsD3DFormatRgba32f:
+			format = dxgiFormatRgba32f
+		case ddsFourCC("DX10"):
+			if len(data) < ddsDX10HeaderSize {
+				return errInvalidDDS
+			}
+			format = le.Uint32(data[0:])
+			if le.Uint32(data[4:]) != ddsResourceTexture2D || le.Uint32(data[12:]) > 1 {
+				return errors.New("only 2D DDS textures are supported")
+			}
+			data = data[ddsDX10HeaderSize:]
+		}
+		switch format {
+		case dxgiFormatBC1, dxgiFormatBC1Srgb:
+			d.InternalFormat = TextureInputTypeCompressedRgbBc1
+		case dxgiFormatBC3, dxgiFormatBC3Srgb:
+			d.InternalFormat = TextureInputTypeCompressedRgbaBc3
+		case dxgiFormatBC5:
+			d.InternalFormat = TextureInputTypeCompressedRgBc5
+		case dxgiFormatBC7, dxgiFormatBC7Srgb:
+			d.InternalFormat = TextureInputTypeCompressedRgbaBc7
+		case dxgiFormatRgba8, dxgiFormatRgba8Srgb:
+			d.InternalFormat = TextureInputTypeRgba8
+		case dxgiFormatBgra8, dxgiFormatBgra8Srgb:
+			d.InternalFormat = TextureInputTypeRgba8
+			bitCount = 32
+			masks = [4]uint32{0xFF0000, 0xFF00, 0xFF, 0xFF000000}
+			convert = true
+		case dxgiFormatRgba16f:
+			d.InternalFormat = TextureInputTypeRgba16f
+			d.Format = TextureColorFormatRgba16Sfloat
+			d.Type = TextureMemTypeHalfFloat
+		case dxgiFormatRgba32f:
+			d.InternalFormat = TextureInputTypeRgba32f
+			d.Format = TextureColorFormatRgba32Sfloat
+			d.Type = TextureMemTypeFloat
+		default:
+			return errors.New("the DDS pixel format is not supported")
+		}
+	} else if pixelFlags&(ddsPixelRGB|ddsPixelLuminance) != 0 {
+		if bitCount != 8 && bitCount != 16 && bitCount != 24 && bitCount != 32 {
+			return errors.New("the DDS pixel format is not supported")
+		}
+		if pixelFlags&ddsPixelAlphaPixels == 0 {
+			masks[3] = 0
+		}
+		if pixelFlags&ddsPixelLuminance != 0 {
+			masks[1], masks[2] = masks[0], masks[0]
+		}
+		d.InternalFormat = TextureInputTypeRgba8
+		convert = masks != [4]uint32{0xFF, 0xFF00, 0xFF0000, 0xFF000000}
+	} else {
+		return errors.New("the DDS pixel format is not supported")
+	}
+	size := 0
+	for i := range levels {
+		size += d.mipLevelSize(i)
+	}
+	if convert {
+		count := size / bytesInPixel
+		stride := bitCount / 8
+		if len(data) < count*stride {
+			return errInvalidDDS
+		}
+		d.Mem = ddsConvertPixels(data, count, stride, masks)
+	} else {
+		if len(data) < size {
+			return errInvalidDDS
+		}
+		d.Mem = data[:size]
+	}
+	// Uncompressed textures without mip levels have them generated by the
+	// renderer, the same as any other image. 32 bit float formats are left
+	// out as not all devices can filter them when blitting.
+	d.MipLevels = levels
+	if levels == 1 && (d.InternalFormat == TextureInputTypeRgba8 ||
+		d.InternalFormat == TextureInputTypeRgba16f) {
+		d.MipLevels = 0
+	}
+	return nil
+}
+
+// ddsConvertPixels reads pixels of any channel layout described by bit masks
+// into RGBA, channels without a mask are 255
+func ddsConvertPixels(data []byte, count, stride int, masks [4]uint32) []byte {
+	out := make([]byte, count*bytesInPixel)
+	for i := range count {
+		p := data[i*stride:]
+		v := uint32(0)
+		for b := range stride {
+			v |= uint32(p[b]) << (b * 8)
+		}
+		for c, mask := range masks {
+			if mask == 0 {
+				out[i*bytesInPixel+c] = 255
+				continue
+			}
+			shift := bits.TrailingZeros32(mask)
+			maxValue := uint64(1)<<bits.OnesCount32(mask) - 1
+			value := uint64((v & mask) >> shift)
+			out[i*bytesInPixel+c] = byte(value * 255 / maxValue)
+		}
+	}
+	return out
+}
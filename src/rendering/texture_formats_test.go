@@ -0,0 +1,410 @@
+/******************************************************************************/
+/* texture_formats_test.go                                                    */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package rendering
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/jpeg"
+	"math"
+	"testing"
+)
+
+func TestTextureFileFormatFromPath(t *testing.T) {
+	tests := map[string]TextureFileFormat{
+		"textures/a.png":  TextureFileFormatPng,
+		"textures/a.JPG":  TextureFileFormatJpeg,
+		"textures/a.jpeg": TextureFileFormatJpeg,
+		"textures/a.tga":  TextureFileFormatTga,
+		"textures/a.hdr":  TextureFileFormatHdr,
+		"textures/a.dds":  TextureFileFormatDds,
+		"textures/a.ktx":  TextureFileFormatKtx,
+		"textures/a.ktx2": TextureFileFormatKtx2,
+		"textures/a.astc": TextureFileFormatAstc,
+		"textures/a.bin":  TextureFileFormatRaw,
+	}
+	for path, expected := range tests {
+		if f := TextureFileFormatFromPath(path); f != expected {
+			t.Errorf("expected %s to be format %d, got %d", path, expected, f)
+		}
+	}
+}
+
+func TestReadJpeg(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 8, 4))
+	for i := range img.Pix {
+		img.Pix[i] = 200
+	}
+	buf := bytes.Buffer{}
+	if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: 100}); err != nil {
+		t.Fatal(err)
+	}
+	data := ReadRawTextureData(buf.Bytes(), TextureFileFormatJpeg)
+	if data.Width != 8 || data.Height != 4 || len(data.Mem) != 8*4*4 {
+		t.Fatalf("expected an 8x4 RGBA image, got %dx%d", data.Width, data.Height)
+	}
+	if d := int(data.Mem[0]) - 200; d < -2 || d > 2 || data.Mem[3] != 255 {
+		t.Errorf("unexpected pixel %v", data.Mem[:4])
+	}
+}
+
+func tgaHeader(imageType, depth, descriptor byte, width, height int) []byte {
+	h := make([]byte, tgaHeaderSize)
+	h[2] = imageType
+	binary.LittleEndian.PutUint16(h[12:], uint16(width))
+	binary.LittleEndian.PutUint16(h[14:], uint16(height))
+	h[16] = depth
+	h[17] = descriptor
+	return h
+}
+
+func TestDecodeTGA(t *testing.T) {
+	// 2x2 bottom to top BGRA, so the first pixel in the file is bottom left
+	mem := tgaHeader(tgaTypeTrueColor, 32, 8, 2, 2)
+	mem = append(mem,
+		0, 0, 255, 255, 0, 255, 0, 255, // red, green
+		255, 0, 0, 128, 255, 255, 255, 0) // blue, clear white
+	w, h, pix, err := decodeTGA(mem)
+	if err != nil || w != 2 || h != 2 {
+		t.Fatalf("failed to decode the TGA: %v", err)
+	}
+	expected := []byte{
+		0, 0, 255, 128, 255, 255, 255, 0,
+		255, 0, 0, 255, 0, 255, 0, 255,
+	}
+	if !bytes.Equal(pix, expected) {
+		t.Errorf("expected %v, got %v", expected, pix)
+	}
+}
+
+func TestDecodeTGARLE(t *testing.T) {
+	mem := tgaHeader(tgaTypeTrueColor|tgaTypeRLE, 24, tgaDescriptorTop, 3, 1)
+	// A run of 2 red pixels then 1 raw green pixel
+	mem = append(mem, tgaRLEPacket|1, 0, 0, 255, 0, 0, 255, 0)
+	_, _, pix, err := decodeTGA(mem)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []byte{255, 0, 0, 255, 255, 0, 0, 255, 0, 255, 0, 255}
+	if !bytes.Equal(pix, expected) {
+		t.Errorf("expected %v, got %v", expected, pix)
+	}
+	if _, _, _, err := decodeTGA(mem[:len(mem)-2]); err == nil {
+		t.Error("expected truncated data to fail")
+	}
+}
+
+func TestFloat32ToHalf(t *testing.T) {
+	for _, f := range []float32{0, 1, -2.5, 0.1, 1000, 65504, 0.00001} {
+		h := halfToFloat32(float32ToHalf(f))
+		if math.Abs(float64(h-f)) > math.Abs(float64(f))*0.001+1e-7 {
+			t.Errorf("expected %f to round trip, got %f", f, h)
+		}
+	}
+	if h := float32ToHalf(1e10); h != 0x7BFF {
+		t.Errorf("expected large values to clamp to the largest half, got %x", h)
+	}
+	if h := float32ToHalf(1); h != halfFloatOne {
+		t.Errorf("expected 1 to be %x, got %x", halfFloatOne, h)
+	}
+}
+
+func TestDecodeHDR(t *testing.T) {
+	const width = 8
+	mem := []byte("#?RADIANCE\nFORMAT=32-bit_rle_rgbe\n\n-Y 2 +X 8\n")
+	// Run length encoded line of 2.0 (mantissa 128, exponent 130)
+	mem = append(mem, 2, 2, 0, width)
+	for _, v := range []byte{128, 128, 128, 130} {
+		mem = append(mem, hdrRLERunFlag+width, v)
+	}
+	// Flat line of 0.5 (mantissa 128, exponent 128)
+	for range width {
+		mem = append(mem, 128, 128, 128, 128)
+	}
+	w, h, pix, err := decodeHDR(mem)
+	if err != nil || w != width || h != 2 {
+		t.Fatalf("failed to decode the HDR: %v", err)
+	}
+	first := halfToFloat32(binary.LittleEndian.Uint16(pix[0:]))
+	last := halfToFloat32(binary.LittleEndian.Uint16(pix[len(pix)-8:]))
+	alpha := binary.LittleEndian.Uint16(pix[6:])
+	if first != 2 || last != 0.5 || alpha != halfFloatOne {
+		t.Errorf("expected 2 and 0.5 with an alpha of 1, got %f, %f, %x", first, last, alpha)
+	}
+	if _, _, _, err := decodeHDR([]byte("not an hdr")); err == nil {
+		t.Error("expected invalid data to fail")
+	}
+}
+
+func ddsHeader(width, height, mips int, pixelFlags uint32, fourCC string, bitCount uint32, masks [4]uint32) []byte {
+	h := make([]byte, ddsHeaderSize)
+	le := binary.LittleEndian
+	copy(h, "DDS ")
+	le.PutUint32(h[4:], 124)
+	flags := uint32(0)
+	if mips > 0 {
+		flags |= ddsFlagMipMapCount
+	}
+	le.PutUint32(h[8:], flags)
+	le.PutUint32(h[12:], uint32(height))
+	le.PutUint32(h[16:], uint32(width))
+	le.PutUint32(h[28:], uint32(mips))
+	le.PutUint32(h[76:], 32)
+	le.PutUint32(h[80:], pixelFlags)
+	copy(h[84:88], fourCC)
+	le.PutUint32(h[88:], bitCount)
+	for i, m := range masks {
+		le.PutUint32(h[92+i*4:], m)
+	}
+	return h
+}
+
+func TestReadDDSCompressedMips(t *testing.T) {
+	// 8x8 DXT1 with 4 levels is 4 + 1 + 1 + 1 blocks of 8 bytes
+	mem := ddsHeader(8, 8, 4, ddsPixelFourCC, "DXT1", 0, [4]uint32{})
+	mem = append(mem, make([]byte, 7*8)...)
+	data := ReadRawTextureData(mem, TextureFileFormatDds)
+	if data.InternalFormat != TextureInputTypeCompressedRgbBc1 || data.MipLevels != 4 {
+		t.Fatalf("expected 4 BC1 levels, got format %d with %d levels",
+			data.InternalFormat, data.MipLevels)
+	}
+	if len(data.Mem) != 7*8 {
+		t.Errorf("expected 56 bytes, got %d", len(data.Mem))
+	}
+	if d := ReadRawTextureData(mem[:len(mem)-1], TextureFileFormatDds); len(d.Mem) != 0 {
+		t.Error("expected truncated data to not be read")
+	}
+}
+
+func TestReadDDSBGRA(t *testing.T) {
+	mem := ddsHeader(1, 1, 0, ddsPixelRGB|ddsPixelAlphaPixels, "", 32,
+		[4]uint32{0xFF0000, 0xFF00, 0xFF, 0xFF000000})
+	mem = append(mem, 10, 20, 30, 40)
+	data := ReadRawTextureData(mem, TextureFileFormatDds)
+	if !bytes.Equal(data.Mem, []byte{30, 20, 10, 40}) || data.MipLevels != 0 {
+		t.Errorf("expected the BGRA pixel to be swizzled, got %v", data.Mem)
+	}
+}
+
+func TestReadDDSDX10Float(t *testing.T) {
+	mem := ddsHeader(2, 1, 0, ddsPixelFourCC, "DX10", 0, [4]uint32{})
+	dx10 := make([]byte, ddsDX10HeaderSize)
+	binary.LittleEndian.PutUint32(dx10[0:], dxgiFormatRgba16f)
+	binary.LittleEndian.PutUint32(dx10[4:], ddsResourceTexture2D)
+	binary.LittleEndian.PutUint32(dx10[12:], 1)
+	mem = append(mem, dx10...)
+	mem = append(mem, make([]byte, 2*8)...)
+	data := ReadRawTextureData(mem, TextureFileFormatDds)
+	if data.InternalFormat != TextureInputTypeRgba16f || data.Format != TextureColorFormatRgba16Sfloat {
+		t.Errorf("expected a half float texture, got %d", data.InternalFormat)
+	}
+	if len(data.Mem) != 16 {
+		t.Errorf("expected 16 bytes, got %d", len(data.Mem))
+	}
+}
+
+func ktxFile(internalFormat, glType uint32, width, height int, levels [][]byte) []byte {
+	mem := append([]byte{}, ktxIdentifier...)
+	le := binary.LittleEndian
+	header := []uint32{ktxEndianness, glType, 1, 0, internalFormat, 0,
+		uint32(width), uint32(height), 0, 0, 1, uint32(len(levels)), 0}
+	for _, v := range header {
+		mem = le.AppendUint32(mem, v)
+	}
+	for _, l := range levels {
+		mem = le.AppendUint32(mem, uint32(len(l)))
+		mem = append(mem, l...)
+		for len(mem)%ktxMipPadding != 0 {
+			mem = append(mem, 0)
+		}
+	}
+	return mem
+}
+
+func TestReadKTX(t *testing.T) {
+	levels := [][]byte{make([]byte, 16), make([]byte, 16)}
+	levels[0][0] = 1
+	levels[1][0] = 2
+	mem := ktxFile(glCompressedBPTC, 0, 4, 4, levels)
+	data := ReadRawTextureData(mem, TextureFileFormatKtx)
+	if data.InternalFormat != TextureInputTypeCompressedRgbaBc7 || data.MipLevels != 2 {
+		t.Fatalf("expected 2 BC7 levels, got format %d with %d levels",
+			data.InternalFormat, data.MipLevels)
+	}
+	if len(data.Mem) != 32 || data.Mem[0] != 1 || data.Mem[16] != 2 {
+		t.Error("expected the levels to be read in order")
+	}
+}
+
+func TestReadKTXRGB(t *testing.T) {
+	// Rows of 3 RGB pixels are padded from 9 to 12 bytes
+	level := []byte{1, 2, 3, 4, 5, 6, 7, 8, 9, 0, 0, 0}
+	mem := ktxFile(glRGB8, glUnsignedByte, 3, 1, [][]byte{level})
+	data := ReadRawTextureData(mem, TextureFileFormatKtx)
+	expected := []byte{1, 2, 3, 255, 4, 5, 6, 255, 7, 8, 9, 255}
+	if !bytes.Equal(data.Mem, expected) || data.MipLevels != 0 {
+		t.Errorf("expected %v, got %v", expected, data.Mem)
+	}
+}
+
+// textureSamples are valid files of each of the decoded formats along with
+// the size of their header, which is mutated to find unchecked sizes
+func textureSamples(t testing.TB) map[TextureFileFormat]struct {
+	mem    []byte
+	header int
+} {
+	img := image.NewRGBA(image.Rect(0, 0, 8, 4))
+	buf := bytes.Buffer{}
+	if err := jpeg.Encode(&buf, img, nil); err != nil {
+		t.Fatal(err)
+	}
+	tga := tgaHeader(tgaTypeTrueColor|tgaTypeRLE, 24, tgaDescriptorTop, 3, 1)
+	tga = append(tga, tgaRLEPacket|1, 0, 0, 255, 0, 0, 255, 0)
+	hdr := []byte("#?RADIANCE\nFORMAT=32-bit_rle_rgbe\n\n-Y 1 +X 8\n")
+	hdr = append(hdr, 2, 2, 0, 8)
+	for _, v := range []byte{128, 128, 128, 130} {
+		hdr = append(hdr, hdrRLERunFlag+8, v)
+	}
+	headerLen := bytes.Index(hdr, []byte("-Y"))
+	dds := ddsHeader(8, 8, 4, ddsPixelFourCC, "DXT1", 0, [4]uint32{})
+	dds = append(dds, make([]byte, 7*8)...)
+	ktx := ktxFile(glRGB8, glUnsignedByte, 3, 2, [][]byte{make([]byte, 24), make([]byte, 4)})
+	return map[TextureFileFormat]struct {
+		mem    []byte
+		header int
+	}{
+		TextureFileFormatJpeg: {buf.Bytes(), min(buf.Len(), 256)},
+		TextureFileFormatTga:  {tga, tgaHeaderSize},
+		TextureFileFormatHdr:  {hdr, headerLen + len("-Y 1 +X 8\\n")},
+		TextureFileFormatDds:  {dds, ddsHeaderSize},
+		TextureFileFormatKtx:  {ktx, ktxHeaderSize + 4},
+	}
+}
+
+// checkTextureData makes sure the decoded data holds every mip level that
+// the texture will be created with
+func checkTextureData(t testing.TB, data TextureData) {
+	t.Helper()
+	if len(data.Mem) == 0 {
+		return
+	}
+	size := 0
+	for i := range max(1, data.MipLevels) {
+		size += data.mipLevelSize(i)
+	}
+	if len(data.Mem) < size {
+		t.Fatalf("a %dx%d texture with %d levels needs %d bytes but only %d were read",
+			data.Width, data.Height, data.MipLevels, size, len(data.Mem))
+	}
+}
+
+func TestTextureDecodersTruncated(t *testing.T) {
+	for format, sample := range textureSamples(t) {
+		if data := ReadRawTextureData(sample.mem, format); len(data.Mem) == 0 {
+			t.Fatalf("expected the format %d sample to be valid", format)
+		}
+		for i := range len(sample.mem) {
+			data := ReadRawTextureData(sample.mem[:i], format)
+			if len(data.Mem) != 0 {
+				t.Errorf("expected format %d truncated to %d bytes to fail", format, i)
+			}
+		}
+	}
+}
+
+func TestTextureDecodersMutatedHeader(t *testing.T) {
+	for format, sample := range textureSamples(t) {
+		mem := bytes.Clone(sample.mem)
+		for i := range sample.header {
+			for _, v := range []byte{0x00, 0x01, 0x7F, 0x80, 0xFF} {
+				mem[i] = v
+				checkTextureData(t, ReadRawTextureData(mem, format))
+			}
+			mem[i] = sample.mem[i]
+		}
+	}
+}
+
+func TestTextureDecodersOversized(t *testing.T) {
+	// A run length encoded image claiming 65535x65535 pixels from a few bytes
+	tga := tgaHeader(tgaTypeTrueColor|tgaTypeRLE, 32, 0, 0xFFFF, 0xFFFF)
+	tga = append(tga, bytes.Repeat([]byte{tgaRLEPacket | 0x7F, 1, 2, 3, 4}, 16)...)
+	if _, _, _, err := decodeTGA(tga); err == nil {
+		t.Error("expected the oversized TGA to fail")
+	}
+	tga = tgaHeader(tgaTypeTrueColor|tgaTypeRLE, 32, 0, 4096, 4096)
+	tga = append(tga, bytes.Repeat([]byte{tgaRLEPacket | 0x7F, 1, 2, 3, 4}, 16)...)
+	if _, _, _, err := decodeTGA(tga); err == nil {
+		t.Error("expected the TGA with too little data to fail")
+	}
+	dds := ddsHeader(8, 8, 0, ddsPixelFourCC, "DXT1", 0, [4]uint32{})
+	binary.LittleEndian.PutUint32(dds[8:], ddsFlagMipMapCount)
+	binary.LittleEndian.PutUint32(dds[28:], 0xFFFFFFFF)
+	if err := (&TextureData{}).readDDS(append(dds, make([]byte, 64)...)); err == nil {
+		t.Error("expected the DDS with too many mip levels to fail")
+	}
+	ktx := ktxFile(glCompressedBPTC, 0, 8, 8, [][]byte{make([]byte, 16)})
+	if err := (&TextureData{}).readKTX(ktx); err == nil {
+		t.Error("expected the KTX level smaller than the image to fail")
+	}
+	ktx = ktxFile(glRGB8, glUnsignedByte, 3, 2, [][]byte{make([]byte, 12)})
+	if err := (&TextureData{}).readKTX(ktx); err == nil {
+		t.Error("expected the KTX RGB level smaller than the image to fail")
+	}
+	hdr := []byte("#?RADIANCE\n\n-Y 16384 +X 16384\n\x01\x02\x03\x04")
+	if _, _, _, err := decodeHDR(hdr); err == nil {
+		t.Error("expected the HDR with too little data to fail")
+	}
+	hdr = []byte("#?RADIANCE\n\n-Y 1 +X 1000000\n\x01\x02\x03\x04")
+	if _, _, _, err := decodeHDR(hdr); err == nil {
+		t.Error("expected the oversized HDR to fail")
+	}
+}
+
+func FuzzReadRawTextureData(f *testing.F) {
+	for format, sample := range textureSamples(f) {
+		f.Add(sample.mem, format)
+	}
+	f.Fuzz(func(t *testing.T, mem []byte, format int) {
+		if format < TextureFileFormatJpeg || format > TextureFileFormatKtx {
+			return
+		}
+		checkTextureData(t, ReadRawTextureData(mem, format))
+	})
+}
@@ -0,0 +1,266 @@
+/******************************************************************************/
+/* texture_hdr.go                                                             */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package rendering
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"math"
+	"strconv"
+	"strings"
+)
+
+const (
+	hdrFormatRGBE   = "32-bit_rle_rgbe"
+	hdrMinRLEWidth  = 8
+	hdrMaxRLEWidth  = 0x7FFF
+	hdrRLERunFlag   = 128
+	hdrExponentBias = 128 + 8
+	halfFloatOne    = 0x3C00
+)
+
+var errInvalidHDR = errors.New("the data is not a valid Radiance HDR image")
+
+// decodeHDR reads a Radiance RGBE image into half float RGBA pixels with the
+// first row at the top of the image, alpha is always 1
+func decodeHDR(mem []byte) (width, height int, pix []byte, err error) {
+	if !bytes.HasPrefix(mem, []byte("#?")) {
+		return 0, 0, nil, errInvalidHDR
+	}
+	// The header is lines of variables up to an empty line, followed by the
+	// resolution line
+	for {
+		end := bytes.IndexByte(mem, '\n')
+		if end < 0 {
+			return 0, 0, nil, errInvalidHDR
+		}
+		line := strings.TrimSpace(string(mem[:end]))
+		mem = mem[end+1:]
+		if line == "" {
+			break
+		}
+		if format, ok := strings.CutPrefix(line, "FORMAT="); ok && format != hdrFormatRGBE {
+			return 0, 0, nil, errors.New("the HDR pixel format is not supported: " + format)
+		}
+	}
+	end := bytes.IndexByte(mem, '\n')
+	if end < 0 {
+		return 0, 0, nil, errInvalidHDR
+	}
+	res := strings.Fields(string(mem[:end]))
+	mem = mem[end+1:]
+	if len(res) != 4 || !strings.HasSuffix(res[0], "Y") || !strings.HasSuffix(res[2], "X") {
+		return 0, 0, nil, errors.New("the HDR image orientation is not supported")
+	}
+	flipY, flipX := res[0][0] == '+', res[2][0] == '-'
+	if height, err = strconv.Atoi(res[1]); err != nil || height <= 0 {
+		return 0, 0, nil, errInvalidHDR
+	}
+	if width, err = strconv.Atoi(res[3]); err != nil || width <= 0 {
+		return 0, 0, nil, errInvalidHDR
+	}
+	// Every scanline takes at least one pixel (4 bytes) of the file
+	if !validTextureSize(width, height) || len(mem)/4 < height {
+		return 0, 0, nil, errInvalidHDR
+	}
+	rgbe := make([]byte, width*height*4)
+	for y := range height {
+		line := rgbe[y*width*4 : (y+1)*width*4]
+		if mem, err = readHDRScanline(mem, line, width); err != nil {
+			return 0, 0, nil, err
+		}
+	}
+	pix = make([]byte, width*height*bytesInPixel*2)
+	for y := range height {
+		dy := y
+		if flipY {
+			dy = height - 1 - y
+		}
+		for x := range width {
+			dx := x
+			if flipX {
+				dx = width - 1 - x
+			}
+			p := rgbe[(y*width+x)*4:]
+			out := pix[(dy*width+dx)*bytesInPixel*2:]
+			scale := float32(0)
+			if p[3] != 0 {
+				scale = float32(math.Ldexp(1, int(p[3])-hdrExponentBias))
+			}
+			for c := range 3 {
+				binary.LittleEndian.PutUint16(out[c*2:], float32ToHalf(float32(p[c])*scale))
+			}
+			binary.LittleEndian.PutUint16(out[6:], halfFloatOne)
+		}
+	}
+	return width, height, pix, nil
+}
+
+// readHDRScanline reads one row of RGBE pixels, either run length encoded
+// per channel, or flat with the old style repeat pixels
+func readHDRScanline(mem, line []byte, width int) ([]byte, error) {
+	if len(mem) < 4 {
+		return mem, errInvalidHDR
+	}
+	if width < hdrMinRLEWidth || width > hdrMaxRLEWidth ||
+		mem[0] != 2 || mem[1] != 2 || mem[2]&0x80 != 0 {
+		return readHDRFlatScanline(mem, line, width)
+	}
+	if int(mem[2])<<8|int(mem[3]) != width {
+		return mem, errInvalidHDR
+	}
+	mem = mem[4:]
+	for c := range 4 {
+		for x := 0; x < width; {
+			if len(mem) == 0 {
+				return mem, errInvalidHDR
+			}
+			count := int(mem[0])
+			mem = mem[1:]
+			if count > hdrRLERunFlag {
+				count -= hdrRLERunFlag
+				if count > width-x || len(mem) == 0 {
+					return mem, errInvalidHDR
+				}
+				for range count {
+					line[x*4+c] = mem[0]
+					x++
+				}
+				mem = mem[1:]
+			} else {
+				if count == 0 || count > width-x || len(mem) < count {
+					return mem, errInvalidHDR
+				}
+				for i := range count {
+					line[x*4+c] = mem[i]
+					x++
+				}
+				mem = mem[count:]
+			}
+		}
+	}
+	return mem, nil
+}
+
+func readHDRFlatScanline(mem, line []byte, width int) ([]byte, error) {
+	shift := 0
+	for x := 0; x < width; {
+		if len(mem) < 4 {
+			return mem, errInvalidHDR
+		}
+		p := mem[:4]
+		mem = mem[4:]
+		// A pixel of 1, 1, 1 repeats the previous pixel, consecutive
+		// repeats are shifted to make larger counts
+		if p[0] == 1 && p[1] == 1 && p[2] == 1 && x > 0 {
+			count := int(p[3]) << shift
+			if count > width-x {
+				return mem, errInvalidHDR
+			}
+			for range count {
+				copy(line[x*4:x*4+4], line[(x-1)*4:x*4])
+				x++
+			}
+			shift += 8
+			continue
+		}
+		copy(line[x*4:x*4+4], p)
+		x++
+		shift = 0
+	}
+	return mem, nil
+}
+
+// float32ToHalf converts to an IEEE 754 half float, rounding to the nearest
+// value, values too large for a half float are clamped to its largest value
+func float32ToHalf(f float32) uint16 {
+	bits := math.Float32bits(f)
+	sign := uint16(bits>>16) & 0x8000
+	exp := int((bits>>23)&0xFF) - 127 + 15
+	mantissa := bits & 0x7FFFFF
+	switch {
+	case (bits>>23)&0xFF == 0xFF:
+		if mantissa != 0 {
+			return sign | 0x7E00
+		}
+		return sign | 0x7C00
+	case exp >= 0x1F:
+		return sign | 0x7BFF
+	case exp <= 0:
+		if exp < -10 {
+			return sign
+		}
+		mantissa |= 0x800000
+		shift := uint32(14 - exp)
+		half := uint16(mantissa >> shift)
+		if mantissa>>(shift-1)&1 != 0 {
+			half++
+		}
+		return sign | half
+	}
+	half := sign | uint16(exp)<<10 | uint16(mantissa>>13)
+	if mantissa&0x1000 != 0 {
+		// Rounding can carry into the exponent which is still correct, but
+		// it must not carry into infinity
+		if half&0x7FFF < 0x7BFF {
+			half++
+		}
+	}
+	return half
+}
+
+// halfToFloat32 converts an IEEE 754 half float to a float32
+func halfToFloat32(h uint16) float32 {
+	sign := float32(1)
+	if h&0x8000 != 0 {
+		sign = -1
+	}
+	exp := int(h>>10) & 0x1F
+	mantissa := float64(h & 0x3FF)
+	switch exp {
+	case 0:
+		return sign * float32(math.Ldexp(mantissa, -24))
+	case 0x1F:
+		if mantissa != 0 {
+			return float32(math.NaN())
+		}
+		return sign * float32(math.Inf(1))
+	}
+	return sign * float32(math.Ldexp(1024+mantissa, exp-25))
+}
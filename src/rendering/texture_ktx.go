@@ -0,0 +1,207 @@
+/******************************************************************************/
+/* texture_ktx.go                                                             */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package rendering
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+)
+
+const (
+	ktxHeaderSize      = 64
+	ktxEndianness      = 0x04030201
+	glUnsignedByte     = 0x1401
+	glRGB              = 0x1907
+	glRGBA             = 0x1908
+	glRGB8             = 0x8051
+	glRGBA8            = 0x8058
+	glRGBA32F          = 0x8814
+	glRGBA16F          = 0x881A
+	glSRGB8            = 0x8C41
+	glSRGB8Alpha8      = 0x8C43
+	glCompressedDXT1   = 0x83F0
+	glCompressedDXT1A  = 0x83F1
+	glCompressedDXT5   = 0x83F3
+	glCompressedSDXT1  = 0x8C4C
+	glCompressedSDXT1A = 0x8C4D
+	glCompressedSDXT5  = 0x8C4F
+	glCompressedRGTC2  = 0x8DBD
+	glCompressedBPTC   = 0x8E8C
+	glCompressedSBPTC  = 0x8E8D
+	glCompressedASTC4  = 0x93B0
+	glCompressedSASTC4 = 0x93D0
+	ktxRowAlignment    = 4
+	ktxMipPadding      = 4
+)
+
+var (
+	ktxIdentifier = []byte{0xAB, 'K', 'T', 'X', ' ', '1', '1', 0xBB, '\r', '\n', 0x1A, '\n'}
+
+	errInvalidKTX = errors.New("the data is not a valid KTX file")
+)
+
+// readKTX reads a 2D KTX (version 1) file along with all of its mip levels,
+// cube maps, arrays, and 3D textures are not supported
+func (d *TextureData) readKTX(mem []byte) error {
+	if len(mem) < ktxHeaderSize || !bytes.Equal(mem[:12], ktxIdentifier) {
+		return errInvalidKTX
+	}
+	var order binary.ByteOrder = binary.LittleEndian
+	if order.Uint32(mem[12:]) != ktxEndianness {
+		order = binary.BigEndian
+	}
+	header := make([]uint32, 13)
+	for i := range header {
+		header[i] = order.Uint32(mem[12+i*4:])
+	}
+	glType, typeSize, internalFormat := header[1], int(header[2]), header[4]
+	d.Width, d.Height = int(header[6]), int(header[7])
+	depth, elements, faces := header[8], header[9], header[10]
+	levels := max(1, int(header[11]))
+	if depth > 1 || elements > 0 || faces != 1 {
+		return errors.New("only 2D KTX textures are supported")
+	}
+	if !validTextureSize(d.Width, d.Height) || levels > textureMaxMipLevels(d.Width, d.Height) {
+		return errInvalidKTX
+	}
+	d.Format = TextureColorFormatRgbaUnorm
+	d.Type = TextureMemTypeUnsignedByte
+	rgb := false
+	switch internalFormat {
+	case glRGBA8, glSRGB8Alpha8:
+		d.InternalFormat = TextureInputTypeRgba8
+	case glRGB8, glSRGB8:
+		d.InternalFormat = TextureInputTypeRgba8
+		rgb = true
+	case glRGBA, glRGB:
+		// Unsized formats use the type to describe the size of the channels
+		if glType != glUnsignedByte {
+			return errors.New("the KTX pixel format is not supported")
+		}
+		d.InternalFormat = TextureInputTypeRgba8
+		rgb = internalFormat == glRGB
+	case glCompressedDXT1, glCompressedDXT1A, glCompressedSDXT1, glCompressedSDXT1A:
+		d.InternalFormat = TextureInputTypeCompressedRgbBc1
+	case glCompressedDXT5, glCompressedSDXT5:
+		d.InternalFormat = TextureInputTypeCompressedRgbaBc3
+	case glCompressedRGTC2:
+		d.InternalFormat = TextureInputTypeCompressedRgBc5
+	case glCompressedBPTC, glCompressedSBPTC:
+		d.InternalFormat = TextureInputTypeCompressedRgbaBc7
+	case glCompressedASTC4, glCompressedSASTC4:
+		d.InternalFormat = TextureInputTypeCompressedRgbaAstc4x4
+	case glRGBA16F:
+		d.InternalFormat = TextureInputTypeRgba16f
+		d.Format = TextureColorFormatRgba16Sfloat
+		d.Type = TextureMemTypeHalfFloat
+	case glRGBA32F:
+		d.InternalFormat = TextureInputTypeRgba32f
+		d.Format = TextureColorFormatRgba32Sfloat
+		d.Type = TextureMemTypeFloat
+	default:
+		return errors.New("the KTX pixel format is not supported")
+	}
+	offset := ktxHeaderSize + int(header[12])
+	// The levels are only kept once all of them have been read
+	var levelMem []byte
+	for i := range levels {
+		if offset+4 > len(mem) {
+			return errInvalidKTX
+		}
+		size := int(order.Uint32(mem[offset:]))
+		offset += 4
+		if offset+size > len(mem) {
+			return errInvalidKTX
+		}
+		// The level has to hold every pixel the texture will read from it
+		w, h := max(1, d.Width>>i), max(1, d.Height>>i)
+		expected := d.mipLevelSize(i)
+		if rgb {
+			expected = ktxRGBRowSize(w) * h
+		}
+		if size < expected {
+			return errInvalidKTX
+		}
+		level := mem[offset : offset+expected]
+		if rgb {
+			level = ktxExpandRGB(level, w, h)
+		} else if order != binary.LittleEndian && typeSize > 1 {
+			level = ktxSwapBytes(level, typeSize)
+		}
+		levelMem = append(levelMem, level...)
+		offset += (size + ktxMipPadding - 1) / ktxMipPadding * ktxMipPadding
+	}
+	d.Mem = levelMem
+	d.MipLevels = levels
+	if levels == 1 && (d.InternalFormat == TextureInputTypeRgba8 ||
+		d.InternalFormat == TextureInputTypeRgba16f) {
+		d.MipLevels = 0
+	}
+	return nil
+}
+
+func ktxRGBRowSize(width int) int {
+	return (width*3 + ktxRowAlignment - 1) / ktxRowAlignment * ktxRowAlignment
+}
+
+// ktxExpandRGB converts the RGB rows, which are padded to 4 bytes, to RGBA,
+// the level must hold all of the rows
+func ktxExpandRGB(level []byte, width, height int) []byte {
+	rowSize := ktxRGBRowSize(width)
+	out := make([]byte, width*height*bytesInPixel)
+	for y := range height {
+		for x := range width {
+			src := y*rowSize + x*3
+			dst := (y*width + x) * bytesInPixel
+			copy(out[dst:], level[src:src+3])
+			out[dst+3] = 255
+		}
+	}
+	return out
+}
+
+func ktxSwapBytes(level []byte, typeSize int) []byte {
+	out := make([]byte, len(level))
+	for i := 0; i+typeSize <= len(level); i += typeSize {
+		for b := range typeSize {
+			out[i+b] = level[i+typeSize-1-b]
+		}
+	}
+	return out
+}
@@ -0,0 +1,115 @@
+/******************************************************************************/
+/* astc.go                                                                    */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package texture_processing
+
+import "math"
+
+// The ASTC blocks are 4x4 with a single partition and a 4x4 weight grid.
+// Opaque blocks use the RGB direct endpoint mode with 3 bit weights, blocks
+// with alpha use the RGBA direct mode with 2 bit weights. In both layouts
+// the endpoints are stored as plain 8 bit values, which keeps the integer
+// sequence encoding down to writing bits.
+const (
+	// astcModeOpaque is a 4x4 grid of weights quantized to 8 levels
+	astcModeOpaque = 0x053
+	// astcModeAlpha is a 4x4 grid of weights quantized to 4 levels
+	astcModeAlpha = 0x042
+	// astcEndpointRGB and astcEndpointRGBA are the LDR RGB direct and
+	// LDR RGBA direct color endpoint modes
+	astcEndpointRGB  = 8
+	astcEndpointRGBA = 12
+)
+
+var (
+	astcLevels8 = []float32{0, 9.0 / 64, 18.0 / 64, 27.0 / 64, 37.0 / 64, 46.0 / 64, 55.0 / 64, 1}
+	astcLevels4 = []float32{0, 21.0 / 64, 43.0 / 64, 1}
+)
+
+func quantizeASTC(e0, e1 [4]float32) ([4]float32, [4]float32) {
+	for i := range 4 {
+		e0[i] = float32(math.Round(float64(e0[i])))
+		e1[i] = float32(math.Round(float64(e1[i])))
+	}
+	return e0, e1
+}
+
+func encodeASTC4x4(b *block, quality Quality, out []byte) {
+	opaque := true
+	for i := range b {
+		if b[i][3] < 255 {
+			opaque = false
+			break
+		}
+	}
+	f := endpointFit{channels: 4, levels: astcLevels4, quantize: quantizeASTC, quality: quality}
+	mode, cem, weightBits := astcModeAlpha, astcEndpointRGBA, 2
+	if opaque {
+		f.channels, f.levels = 3, astcLevels8
+		mode, cem, weightBits = astcModeOpaque, astcEndpointRGB, 3
+	}
+	f.fit(b)
+	e0, e1 := f.q0, f.q1
+	indices := f.indices
+	// When the second endpoint is darker than the first the decoder swaps
+	// them and applies blue contraction, so keep it the brighter one
+	if e1[0]+e1[1]+e1[2] < e0[0]+e0[1]+e0[2] {
+		e0, e1 = e1, e0
+		for i := range indices {
+			indices[i] = len(f.levels) - 1 - indices[i]
+		}
+	}
+	w := bitWriter{out: out}
+	w.write(uint64(mode), 11)
+	// A partition count of 1 is stored as 0
+	w.write(0, 2)
+	w.write(uint64(cem), 4)
+	for c := range f.channels {
+		w.write(uint64(e0[c]), 8)
+		w.write(uint64(e1[c]), 8)
+	}
+	// The weights are stored from the highest bit of the block down
+	pos := 127
+	for _, idx := range indices {
+		for bit := range weightBits {
+			if idx&(1<<bit) != 0 {
+				out[pos/8] |= 1 << (pos % 8)
+			}
+			pos--
+		}
+	}
+}
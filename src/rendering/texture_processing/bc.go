@@ -0,0 +1,276 @@
+/******************************************************************************/
+/* bc.go                                                                      */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package texture_processing
+
+import (
+	"encoding/binary"
+	"math"
+)
+
+var (
+	// bc1Levels are the palette positions in order, bc1Index maps them to
+	// the index stored in the block (c0, c1, 2/3 c0 + 1/3 c1, 1/3 c0 + 2/3 c1)
+	bc1Levels = []float32{0, 1.0 / 3.0, 2.0 / 3.0, 1}
+	bc1Index  = [4]uint32{0, 2, 3, 1}
+	// bc7Weights4 are the interpolation weights of the 4 bit BC7 indices
+	bc7Weights4 = [16]float32{0, 4, 9, 13, 17, 21, 26, 30, 34, 38, 43, 47, 51, 55, 60, 64}
+	bc7Levels   = func() []float32 {
+		l := make([]float32, len(bc7Weights4))
+		for i, w := range bc7Weights4 {
+			l[i] = w / 64
+		}
+		return l
+	}()
+)
+
+func to565(c [4]float32) uint16 {
+	r := uint16(math.Round(float64(c[0]) * 31 / 255))
+	g := uint16(math.Round(float64(c[1]) * 63 / 255))
+	b := uint16(math.Round(float64(c[2]) * 31 / 255))
+	return r<<11 | g<<5 | b
+}
+
+func from565(v uint16) [4]float32 {
+	r, g, b := (v>>11)&31, (v>>5)&63, v&31
+	return [4]float32{
+		float32(r<<3 | r>>2),
+		float32(g<<2 | g>>4),
+		float32(b<<3 | b>>2),
+		255,
+	}
+}
+
+func quantize565(e0, e1 [4]float32) ([4]float32, [4]float32) {
+	return from565(to565(e0)), from565(to565(e1))
+}
+
+// encodeBC1Color writes the 8 byte color block that is shared by BC1 and BC3,
+// the block always uses the 4 color mode
+func encodeBC1Color(b *block, quality Quality, out []byte) {
+	f := endpointFit{channels: 3, levels: bc1Levels, quantize: quantize565, quality: quality}
+	f.fit(b)
+	c0, c1 := to565(f.e0), to565(f.e1)
+	var indices [16]uint32
+	for i, l := range f.indices {
+		indices[i] = bc1Index[l]
+	}
+	if c0 < c1 {
+		c0, c1 = c1, c0
+		for i := range indices {
+			// Swapping the endpoints flips 0<->1 and 2<->3
+			indices[i] ^= 1
+		}
+	} else if c0 == c1 {
+		indices = [16]uint32{}
+	}
+	bits := uint32(0)
+	for i, idx := range indices {
+		bits |= idx << (i * 2)
+	}
+	binary.LittleEndian.PutUint16(out[0:], c0)
+	binary.LittleEndian.PutUint16(out[2:], c1)
+	binary.LittleEndian.PutUint32(out[4:], bits)
+}
+
+// encodeBC4 writes an 8 byte single channel block, it tries the 8 value
+// mode and, unless the quality is fast, the 6 value mode which has exact 0
+// and 255 values for channels like cut out alpha
+func encodeBC4(values *[16]float32, quality Quality, out []byte) {
+	lo, hi := float32(255), float32(0)
+	for _, v := range values {
+		lo, hi = min(lo, v), max(hi, v)
+	}
+	a0, a1 := byte(math.Round(float64(hi))), byte(math.Round(float64(lo)))
+	bits, err := bc4Indices(values, a0, a1)
+	if quality != QualityFast {
+		inLo, inHi := float32(255), float32(0)
+		for _, v := range values {
+			if v > 0 && v < 255 {
+				inLo, inHi = min(inLo, v), max(inHi, v)
+			}
+		}
+		if inLo <= inHi {
+			b0, b1 := byte(math.Round(float64(inLo))), byte(math.Round(float64(inHi)))
+			if bits6, err6 := bc4Indices(values, b0, b1); err6 < err {
+				a0, a1, bits = b0, b1, bits6
+			}
+		}
+	}
+	out[0], out[1] = a0, a1
+	for i := range 6 {
+		out[2+i] = byte(bits >> (i * 8))
+	}
+}
+
+// bc4Palette is the 8 values of the block, a0 > a1 interpolates 6 values
+// between them while a0 <= a1 interpolates 4 and adds 0 and 255
+func bc4Palette(a0, a1 byte) [8]float32 {
+	p := [8]float32{float32(a0), float32(a1)}
+	if a0 > a1 {
+		for i := 1; i < 7; i++ {
+			p[i+1] = float32((7-i)*int(a0)+i*int(a1)) / 7
+		}
+	} else {
+		for i := 1; i < 5; i++ {
+			p[i+1] = float32((5-i)*int(a0)+i*int(a1)) / 5
+		}
+		p[6], p[7] = 0, 255
+	}
+	return p
+}
+
+func bc4Indices(values *[16]float32, a0, a1 byte) (uint64, float32) {
+	palette := bc4Palette(a0, a1)
+	bits := uint64(0)
+	total := float32(0)
+	for i, v := range values {
+		best, bestErr := 0, float32(math.Inf(1))
+		for j, p := range palette {
+			if d := (p - v) * (p - v); d < bestErr {
+				best, bestErr = j, d
+			}
+		}
+		bits |= uint64(best) << (i * 3)
+		total += bestErr
+	}
+	return bits, total
+}
+
+func blockChannel(b *block, channel int) [16]float32 {
+	var values [16]float32
+	for i := range b {
+		values[i] = b[i][channel]
+	}
+	return values
+}
+
+func encodeBC1(b *block, quality Quality, out []byte) {
+	encodeBC1Color(b, quality, out)
+}
+
+func encodeBC3(b *block, quality Quality, out []byte) {
+	alpha := blockChannel(b, 3)
+	encodeBC4(&alpha, quality, out[0:8])
+	encodeBC1Color(b, quality, out[8:16])
+}
+
+func encodeBC5(b *block, quality Quality, out []byte) {
+	r, g := blockChannel(b, 0), blockChannel(b, 1)
+	encodeBC4(&r, quality, out[0:8])
+	encodeBC4(&g, quality, out[8:16])
+}
+
+// bc7Endpoint is a 7 bit per channel endpoint and its shared p-bit which
+// becomes the lowest bit of each 8 bit channel
+type bc7Endpoint struct {
+	rgba [4]uint8
+	p    uint8
+}
+
+func (e bc7Endpoint) expand() [4]float32 {
+	var c [4]float32
+	for i, v := range e.rgba {
+		c[i] = float32(v<<1 | e.p)
+	}
+	return c
+}
+
+func toBC7Endpoint(c [4]float32) bc7Endpoint {
+	best, bestErr := bc7Endpoint{}, float32(math.Inf(1))
+	for p := range uint8(2) {
+		e := bc7Endpoint{p: p}
+		err := float32(0)
+		for i := range c {
+			v := math.Round((float64(c[i]) - float64(p)) / 2)
+			e.rgba[i] = uint8(min(max(v, 0), 127))
+			d := float32(e.rgba[i]<<1|p) - c[i]
+			err += d * d
+		}
+		if err < bestErr {
+			best, bestErr = e, err
+		}
+	}
+	return best
+}
+
+func quantizeBC7(e0, e1 [4]float32) ([4]float32, [4]float32) {
+	return toBC7Endpoint(e0).expand(), toBC7Endpoint(e1).expand()
+}
+
+// encodeBC7 writes a mode 6 block, a single subset with 7 bit RGBA
+// endpoints, a p-bit for each endpoint and 4 bit indices
+func encodeBC7(b *block, quality Quality, out []byte) {
+	f := endpointFit{channels: 4, levels: bc7Levels, quantize: quantizeBC7, quality: quality}
+	f.fit(b)
+	e0, e1 := toBC7Endpoint(f.e0), toBC7Endpoint(f.e1)
+	indices := f.indices
+	// The highest bit of the first index is not stored, it is always 0
+	if indices[0] >= 8 {
+		e0, e1 = e1, e0
+		for i := range indices {
+			indices[i] = 15 - indices[i]
+		}
+	}
+	w := bitWriter{out: out}
+	w.write(1<<6, 7)
+	for c := range 4 {
+		w.write(uint64(e0.rgba[c]), 7)
+		w.write(uint64(e1.rgba[c]), 7)
+	}
+	w.write(uint64(e0.p), 1)
+	w.write(uint64(e1.p), 1)
+	w.write(uint64(indices[0]), 3)
+	for _, idx := range indices[1:] {
+		w.write(uint64(idx), 4)
+	}
+}
+
+// bitWriter writes values into a block from the lowest bit up
+type bitWriter struct {
+	out []byte
+	pos int
+}
+
+func (w *bitWriter) write(value uint64, bits int) {
+	for i := range bits {
+		if value&(1<<i) != 0 {
+			w.out[w.pos/8] |= 1 << (w.pos % 8)
+		}
+		w.pos++
+	}
+}
@@ -0,0 +1,234 @@
+/******************************************************************************/
+/* block_fit.go                                                               */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package texture_processing
+
+import "math"
+
+// Quality trades encoding time for how closely the compressed blocks match
+// the image
+type Quality int
+
+const (
+	// QualityFast places the endpoints along the principal axis of the
+	// block's colors
+	QualityFast Quality = iota
+	// QualityNormal refines the endpoints once with a least squares fit
+	QualityNormal
+	// QualityHigh refines the endpoints until they stop improving
+	QualityHigh
+)
+
+func (q Quality) refinements() int {
+	switch q {
+	case QualityNormal:
+		return 1
+	case QualityHigh:
+		return 8
+	}
+	return 0
+}
+
+// block is the 4x4 pixels of one compressed block, each channel from 0 to
+// 255. Pixels outside of the image repeat the closest edge pixel.
+type block [16][4]float32
+
+func readBlock(img Image, bx, by int) block {
+	var b block
+	for y := range 4 {
+		sy := min(by*4+y, img.Height-1)
+		for x := range 4 {
+			sx := min(bx*4+x, img.Width-1)
+			p := img.Pix[(sy*img.Width+sx)*4:]
+			for c := range 4 {
+				b[y*4+x][c] = float32(p[c])
+			}
+		}
+	}
+	return b
+}
+
+// endpointQuantizer rounds the endpoints to what the block format can store
+// and returns the values that the decoder will read back
+type endpointQuantizer func(e0, e1 [4]float32) ([4]float32, [4]float32)
+
+// endpointFit finds two endpoints and an index into levels for each pixel
+// so that the colors interpolated between the endpoints are close to the
+// block. levels are the interpolation positions between the endpoints, in
+// increasing order from 0 to 1.
+type endpointFit struct {
+	channels int
+	levels   []float32
+	quantize endpointQuantizer
+	quality  Quality
+	e0, e1   [4]float32
+	q0, q1   [4]float32
+	indices  [16]int
+	sqrError float32
+}
+
+func (f *endpointFit) fit(b *block) {
+	e0, e1 := principalEndpoints(b, f.channels)
+	f.sqrError = float32(math.Inf(1))
+	f.try(b, e0, e1)
+	for range f.quality.refinements() {
+		e0, e1, ok := f.leastSquares(b)
+		if !ok {
+			break
+		}
+		if !f.try(b, e0, e1) {
+			break
+		}
+	}
+}
+
+// try keeps the endpoints if they are better than the best found so far
+func (f *endpointFit) try(b *block, e0, e1 [4]float32) bool {
+	q0, q1 := f.quantize(e0, e1)
+	var indices [16]int
+	total := float32(0)
+	for i := range b {
+		best, bestErr := 0, float32(math.Inf(1))
+		for l, t := range f.levels {
+			err := float32(0)
+			for c := range f.channels {
+				d := q0[c] + (q1[c]-q0[c])*t - b[i][c]
+				err += d * d
+			}
+			if err < bestErr {
+				best, bestErr = l, err
+			}
+		}
+		indices[i] = best
+		total += bestErr
+	}
+	if total >= f.sqrError {
+		return false
+	}
+	f.e0, f.e1, f.q0, f.q1 = e0, e1, q0, q1
+	f.indices = indices
+	f.sqrError = total
+	return true
+}
+
+// leastSquares solves for the endpoints that best fit the pixels with the
+// current indices
+func (f *endpointFit) leastSquares(b *block) ([4]float32, [4]float32, bool) {
+	var a, bb, c float32
+	var d0, d1 [4]float32
+	for i := range b {
+		w := f.levels[f.indices[i]]
+		iw := 1 - w
+		a += iw * iw
+		bb += iw * w
+		c += w * w
+		for ch := range f.channels {
+			d0[ch] += iw * b[i][ch]
+			d1[ch] += w * b[i][ch]
+		}
+	}
+	det := a*c - bb*bb
+	if math.Abs(float64(det)) < 1e-6 {
+		return f.e0, f.e1, false
+	}
+	var e0, e1 [4]float32
+	for ch := range f.channels {
+		e0[ch] = min(max((c*d0[ch]-bb*d1[ch])/det, 0), 255)
+		e1[ch] = min(max((a*d1[ch]-bb*d0[ch])/det, 0), 255)
+	}
+	for ch := f.channels; ch < 4; ch++ {
+		e0[ch], e1[ch] = 255, 255
+	}
+	return e0, e1, true
+}
+
+// principalEndpoints places the endpoints at the ends of the block's colors
+// along the direction that they vary the most
+func principalEndpoints(b *block, channels int) ([4]float32, [4]float32) {
+	var mean [4]float32
+	for i := range b {
+		for c := range channels {
+			mean[c] += b[i][c]
+		}
+	}
+	for c := range channels {
+		mean[c] /= float32(len(b))
+	}
+	var cov [4][4]float32
+	for i := range b {
+		for r := range channels {
+			dr := b[i][r] - mean[r]
+			for c := range channels {
+				cov[r][c] += dr * (b[i][c] - mean[c])
+			}
+		}
+	}
+	axis := [4]float32{1, 1, 1, 1}
+	for range 8 {
+		var next [4]float32
+		for r := range channels {
+			for c := range channels {
+				next[r] += cov[r][c] * axis[c]
+			}
+		}
+		length := float32(0)
+		for c := range channels {
+			length += next[c] * next[c]
+		}
+		if length < 1e-12 {
+			break
+		}
+		length = float32(math.Sqrt(float64(length)))
+		for c := range channels {
+			axis[c] = next[c] / length
+		}
+	}
+	lo, hi := float32(math.Inf(1)), float32(math.Inf(-1))
+	for i := range b {
+		t := float32(0)
+		for c := range channels {
+			t += (b[i][c] - mean[c]) * axis[c]
+		}
+		lo, hi = min(lo, t), max(hi, t)
+	}
+	e0, e1 := [4]float32{255, 255, 255, 255}, [4]float32{255, 255, 255, 255}
+	for c := range channels {
+		e0[c] = min(max(mean[c]+axis[c]*lo, 0), 255)
+		e1[c] = min(max(mean[c]+axis[c]*hi, 0), 255)
+	}
+	return e0, e1
+}
@@ -0,0 +1,193 @@
+/******************************************************************************/
+/* format.go                                                                  */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package texture_processing
+
+import "errors"
+
+// Format is how the pixels of the processed texture are stored
+type Format int
+
+const (
+	// FormatRGBA8 stores the pixels uncompressed
+	FormatRGBA8 Format = iota
+	// FormatBC1 is 4 bits per pixel RGB without alpha
+	FormatBC1
+	// FormatBC3 is 8 bits per pixel RGBA, the alpha is compressed on its own
+	FormatBC3
+	// FormatBC5 is 8 bits per pixel of only the red and green channels, it
+	// is meant for normal maps
+	FormatBC5
+	// FormatBC7 is 8 bits per pixel RGBA with the best quality of the BC
+	// formats
+	FormatBC7
+	// FormatASTC4x4 is 8 bits per pixel RGBA for mobile devices
+	FormatASTC4x4
+)
+
+// Vulkan format numbers (VkFormat) that are written in the KTX2 header
+const (
+	vkFormatR8G8B8A8Unorm   = 37
+	vkFormatR8G8B8A8Srgb    = 43
+	vkFormatBC1RGBUnorm     = 131
+	vkFormatBC1RGBSrgb      = 132
+	vkFormatBC3Unorm        = 137
+	vkFormatBC3Srgb         = 138
+	vkFormatBC5Unorm        = 141
+	vkFormatBC7Unorm        = 145
+	vkFormatBC7Srgb         = 146
+	vkFormatASTC4x4Unorm    = 157
+	vkFormatASTC4x4Srgb     = 158
+	vkFormatUndefined       = 0
+	blockFormatDimension    = 4
+	uncompressedPixelStride = 4
+)
+
+var ErrUnsupportedFormat = errors.New("the texture format is not supported")
+
+// BlockSize is the width and height in pixels of a block of the format and
+// the number of bytes the block takes
+func (f Format) BlockSize() (width, height, bytes int) {
+	switch f {
+	case FormatBC1:
+		return blockFormatDimension, blockFormatDimension, 8
+	case FormatBC3, FormatBC5, FormatBC7, FormatASTC4x4:
+		return blockFormatDimension, blockFormatDimension, 16
+	}
+	return 1, 1, uncompressedPixelStride
+}
+
+// LevelSize is the number of bytes of an image of the given size
+func (f Format) LevelSize(width, height int) int {
+	bw, bh, bytes := f.BlockSize()
+	return ((width + bw - 1) / bw) * ((height + bh - 1) / bh) * bytes
+}
+
+func (f Format) vkFormat(srgb bool) uint32 {
+	switch f {
+	case FormatRGBA8:
+		if srgb {
+			return vkFormatR8G8B8A8Srgb
+		}
+		return vkFormatR8G8B8A8Unorm
+	case FormatBC1:
+		if srgb {
+			return vkFormatBC1RGBSrgb
+		}
+		return vkFormatBC1RGBUnorm
+	case FormatBC3:
+		if srgb {
+			return vkFormatBC3Srgb
+		}
+		return vkFormatBC3Unorm
+	case FormatBC5:
+		return vkFormatBC5Unorm
+	case FormatBC7:
+		if srgb {
+			return vkFormatBC7Srgb
+		}
+		return vkFormatBC7Unorm
+	case FormatASTC4x4:
+		if srgb {
+			return vkFormatASTC4x4Srgb
+		}
+		return vkFormatASTC4x4Unorm
+	}
+	return vkFormatUndefined
+}
+
+func formatFromVk(vkFormat uint32) (Format, bool, error) {
+	switch vkFormat {
+	case vkFormatR8G8B8A8Unorm:
+		return FormatRGBA8, false, nil
+	case vkFormatR8G8B8A8Srgb:
+		return FormatRGBA8, true, nil
+	case vkFormatBC1RGBUnorm:
+		return FormatBC1, false, nil
+	case vkFormatBC1RGBSrgb:
+		return FormatBC1, true, nil
+	case vkFormatBC3Unorm:
+		return FormatBC3, false, nil
+	case vkFormatBC3Srgb:
+		return FormatBC3, true, nil
+	case vkFormatBC5Unorm:
+		return FormatBC5, false, nil
+	case vkFormatBC7Unorm:
+		return FormatBC7, false, nil
+	case vkFormatBC7Srgb:
+		return FormatBC7, true, nil
+	case vkFormatASTC4x4Unorm:
+		return FormatASTC4x4, false, nil
+	case vkFormatASTC4x4Srgb:
+		return FormatASTC4x4, true, nil
+	}
+	return FormatRGBA8, false, ErrUnsupportedFormat
+}
+
+// Compress encodes the image into the format, the blocks on the right and
+// bottom edges repeat the edge pixels when the size isn't a multiple of 4
+func Compress(img Image, format Format, quality Quality) []byte {
+	if format == FormatRGBA8 {
+		return img.Pix
+	}
+	var encode func(b *block, quality Quality, out []byte)
+	switch format {
+	case FormatBC1:
+		encode = encodeBC1
+	case FormatBC3:
+		encode = encodeBC3
+	case FormatBC5:
+		encode = encodeBC5
+	case FormatBC7:
+		encode = encodeBC7
+	case FormatASTC4x4:
+		encode = encodeASTC4x4
+	default:
+		return nil
+	}
+	bw, bh, size := format.BlockSize()
+	cols, rows := (img.Width+bw-1)/bw, (img.Height+bh-1)/bh
+	out := make([]byte, cols*rows*size)
+	for by := range rows {
+		for bx := range cols {
+			b := readBlock(img, bx, by)
+			i := (by*cols + bx) * size
+			encode(&b, quality, out[i:i+size])
+		}
+	}
+	return out
+}
@@ -0,0 +1,110 @@
+/******************************************************************************/
+/* image.go                                                                   */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package texture_processing
+
+import "math"
+
+// Image is an 8 bit RGBA image with the rows stored top to bottom
+type Image struct {
+	Width  int
+	Height int
+	Pix    []byte
+}
+
+// floatImage is an RGBA image with each channel from 0 to 1, the color
+// channels of sRGB images are converted to linear so that filtering them
+// doesn't darken the result
+type floatImage struct {
+	width  int
+	height int
+	pix    []float32
+}
+
+var srgbToLinearTable = func() [256]float32 {
+	var t [256]float32
+	for i := range t {
+		t[i] = float32(srgbToLinear(float64(i) / 255))
+	}
+	return t
+}()
+
+func srgbToLinear(c float64) float64 {
+	if c <= 0.04045 {
+		return c / 12.92
+	}
+	return math.Pow((c+0.055)/1.055, 2.4)
+}
+
+func linearToSRGB(c float64) float64 {
+	if c <= 0.0031308 {
+		return c * 12.92
+	}
+	return 1.055*math.Pow(c, 1/2.4) - 0.055
+}
+
+func toFloatImage(img Image, srgb bool) floatImage {
+	f := floatImage{
+		width:  img.Width,
+		height: img.Height,
+		pix:    make([]float32, len(img.Pix)),
+	}
+	for i, v := range img.Pix {
+		if srgb && i%4 != 3 {
+			f.pix[i] = srgbToLinearTable[v]
+		} else {
+			f.pix[i] = float32(v) / 255
+		}
+	}
+	return f
+}
+
+func (f *floatImage) toImage(srgb bool) Image {
+	img := Image{
+		Width:  f.width,
+		Height: f.height,
+		Pix:    make([]byte, len(f.pix)),
+	}
+	for i, v := range f.pix {
+		c := math.Min(math.Max(float64(v), 0), 1)
+		if srgb && i%4 != 3 {
+			c = linearToSRGB(c)
+		}
+		img.Pix[i] = byte(math.Round(c * 255))
+	}
+	return img
+}
@@ -0,0 +1,244 @@
+/******************************************************************************/
+/* ktx2.go                                                                    */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package texture_processing
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+)
+
+// KTX2 is a 2D texture and its mip levels as stored in a KTX2 container,
+// only the formats of this package are supported and the levels are not
+// supercompressed
+type KTX2 struct {
+	Format Format
+	// SRGB is set when the color channels are sRGB encoded
+	SRGB   bool
+	Width  int
+	Height int
+	// Levels are the mip levels from the largest to the smallest
+	Levels [][]byte
+}
+
+var (
+	ktx2Identifier = [12]byte{0xAB, 'K', 'T', 'X', ' ', '2', '0', 0xBB, '\r', '\n', 0x1A, '\n'}
+
+	ErrInvalidKTX2 = errors.New("the data is not a valid KTX2 file")
+)
+
+const (
+	ktx2HeaderSize     = 80
+	ktx2LevelIndexSize = 24
+)
+
+// Data Format Descriptor values from the Khronos Data Format specification
+const (
+	dfdModelRGBSDA     = 1
+	dfdModelBC1A       = 128
+	dfdModelBC3        = 130
+	dfdModelBC5        = 132
+	dfdModelBC7        = 134
+	dfdModelASTC       = 162
+	dfdPrimariesBT709  = 1
+	dfdTransferLinear  = 1
+	dfdTransferSRGB    = 2
+	dfdVersion         = 2
+	dfdChannelRed      = 0
+	dfdChannelGreen    = 1
+	dfdChannelBlue     = 2
+	dfdChannelAlpha    = 15
+	dfdQualifierLinear = 0x10
+	dfdBasicHeaderSize = 24
+	dfdSampleSize      = 16
+)
+
+type dfdSample struct {
+	offset  int
+	bits    int
+	channel byte
+	upper   uint32
+}
+
+// dataFormatDescriptor builds the basic descriptor block that KTX2 requires
+// to describe the layout of the texel blocks
+func (k *KTX2) dataFormatDescriptor() []byte {
+	model := byte(dfdModelRGBSDA)
+	var samples []dfdSample
+	switch k.Format {
+	case FormatRGBA8:
+		samples = []dfdSample{
+			{0, 8, dfdChannelRed, 255},
+			{8, 8, dfdChannelGreen, 255},
+			{16, 8, dfdChannelBlue, 255},
+			{24, 8, dfdChannelAlpha, 255},
+		}
+	case FormatBC1:
+		model = dfdModelBC1A
+		samples = []dfdSample{{0, 64, dfdChannelRed, 0xFFFFFFFF}}
+	case FormatBC3:
+		model = dfdModelBC3
+		samples = []dfdSample{
+			{0, 64, dfdChannelAlpha, 0xFFFFFFFF},
+			{64, 64, dfdChannelRed, 0xFFFFFFFF},
+		}
+	case FormatBC5:
+		model = dfdModelBC5
+		samples = []dfdSample{
+			{0, 64, dfdChannelRed, 0xFFFFFFFF},
+			{64, 64, dfdChannelGreen, 0xFFFFFFFF},
+		}
+	case FormatBC7:
+		model = dfdModelBC7
+		samples = []dfdSample{{0, 128, dfdChannelRed, 0xFFFFFFFF}}
+	case FormatASTC4x4:
+		model = dfdModelASTC
+		samples = []dfdSample{{0, 128, dfdChannelRed, 0xFFFFFFFF}}
+	}
+	transfer := byte(dfdTransferLinear)
+	if k.SRGB {
+		transfer = dfdTransferSRGB
+	}
+	bw, bh, size := k.Format.BlockSize()
+	blockSize := dfdBasicHeaderSize + dfdSampleSize*len(samples)
+	buf := bytes.Buffer{}
+	le := binary.LittleEndian
+	binary.Write(&buf, le, uint32(4+blockSize))
+	binary.Write(&buf, le, uint32(0))
+	binary.Write(&buf, le, uint32(dfdVersion|blockSize<<16))
+	buf.Write([]byte{model, dfdPrimariesBT709, transfer, 0})
+	buf.Write([]byte{byte(bw - 1), byte(bh - 1), 0, 0})
+	buf.Write([]byte{byte(size), 0, 0, 0, 0, 0, 0, 0})
+	for _, s := range samples {
+		channel := s.channel
+		// Alpha is never sRGB encoded
+		if k.SRGB && channel == dfdChannelAlpha {
+			channel |= dfdQualifierLinear
+		}
+		binary.Write(&buf, le, uint32(s.offset|(s.bits-1)<<16|int(channel)<<24))
+		binary.Write(&buf, le, uint32(0))
+		binary.Write(&buf, le, uint32(0))
+		binary.Write(&buf, le, s.upper)
+	}
+	return buf.Bytes()
+}
+
+// Encode writes the texture as a KTX2 file
+func (k *KTX2) Encode() []byte {
+	le := binary.LittleEndian
+	dfd := k.dataFormatDescriptor()
+	levelIndex := ktx2HeaderSize
+	dfdOffset := levelIndex + len(k.Levels)*ktx2LevelIndexSize
+	_, _, blockBytes := k.Format.BlockSize()
+	// Levels are aligned to the least common multiple of the block size and
+	// 4, all of the block sizes here are a multiple of 4
+	align := max(blockBytes, 4)
+	// The smallest level is stored first
+	offsets := make([]int, len(k.Levels))
+	end := dfdOffset + len(dfd)
+	for i := len(k.Levels) - 1; i >= 0; i-- {
+		end = (end + align - 1) / align * align
+		offsets[i] = end
+		end += len(k.Levels[i])
+	}
+	out := make([]byte, end)
+	copy(out, ktx2Identifier[:])
+	header := []uint32{
+		k.Format.vkFormat(k.SRGB),
+		1, // typeSize
+		uint32(k.Width),
+		uint32(k.Height),
+		0, // pixelDepth
+		0, // layerCount
+		1, // faceCount
+		uint32(len(k.Levels)),
+		0, // supercompressionScheme
+		uint32(dfdOffset),
+		uint32(len(dfd)),
+		0, // kvdByteOffset
+		0, // kvdByteLength
+	}
+	for i, v := range header {
+		le.PutUint32(out[12+i*4:], v)
+	}
+	// sgdByteOffset and sgdByteLength are left as 0
+	for i, level := range k.Levels {
+		entry := out[levelIndex+i*ktx2LevelIndexSize:]
+		le.PutUint64(entry[0:], uint64(offsets[i]))
+		le.PutUint64(entry[8:], uint64(len(level)))
+		le.PutUint64(entry[16:], uint64(len(level)))
+		copy(out[offsets[i]:], level)
+	}
+	copy(out[dfdOffset:], dfd)
+	return out
+}
+
+// DecodeKTX2 reads a KTX2 file written by #KTX2.Encode, or any other 2D
+// KTX2 file in one of the formats of this package without supercompression
+func DecodeKTX2(data []byte) (KTX2, error) {
+	k := KTX2{}
+	le := binary.LittleEndian
+	if len(data) < ktx2HeaderSize || !bytes.Equal(data[:12], ktx2Identifier[:]) {
+		return k, ErrInvalidKTX2
+	}
+	format, srgb, err := formatFromVk(le.Uint32(data[12:]))
+	if err != nil {
+		return k, err
+	}
+	k.Format, k.SRGB = format, srgb
+	k.Width = int(le.Uint32(data[20:]))
+	k.Height = int(le.Uint32(data[24:]))
+	depth, layers, faces := le.Uint32(data[28:]), le.Uint32(data[32:]), le.Uint32(data[36:])
+	levels := int(max(le.Uint32(data[40:]), 1))
+	if depth > 1 || layers > 1 || faces != 1 || le.Uint32(data[44:]) != 0 {
+		return k, ErrUnsupportedFormat
+	}
+	if len(data) < ktx2HeaderSize+levels*ktx2LevelIndexSize {
+		return k, ErrInvalidKTX2
+	}
+	k.Levels = make([][]byte, levels)
+	for i := range levels {
+		entry := data[ktx2HeaderSize+i*ktx2LevelIndexSize:]
+		offset, length := le.Uint64(entry[0:]), le.Uint64(entry[8:])
+		if offset+length > uint64(len(data)) {
+			return k, ErrInvalidKTX2
+		}
+		k.Levels[i] = data[offset : offset+length]
+	}
+	return k, nil
+}
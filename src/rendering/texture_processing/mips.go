@@ -0,0 +1,238 @@
+/******************************************************************************/
+/* mips.go                                                                    */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package texture_processing
+
+import "math"
+
+// MipFilter is the filter used to shrink each mip level into the next
+type MipFilter int
+
+const (
+	// MipFilterBox averages the pixels that each new pixel covers, it is the
+	// fastest and softest of the filters
+	MipFilterBox MipFilter = iota
+	// MipFilterKaiser is a Kaiser windowed sinc, it keeps more detail than
+	// the box filter without the ringing of the Lanczos filter
+	MipFilterKaiser
+	// MipFilterLanczos is a 3 lobe Lanczos windowed sinc, it is the sharpest
+	// of the filters but can ring around hard edges
+	MipFilterLanczos
+)
+
+const (
+	kaiserAlpha  = 4.0
+	kaiserRadius = 3.0
+	lanczosLobes = 3.0
+)
+
+type filterKernel struct {
+	radius float64
+	weight func(x float64) float64
+}
+
+func sinc(x float64) float64 {
+	if x == 0 {
+		return 1
+	}
+	x *= math.Pi
+	return math.Sin(x) / x
+}
+
+// besselI0 is the zeroth order modified Bessel function of the first kind
+func besselI0(x float64) float64 {
+	sum, term := 1.0, 1.0
+	for k := 1; k < 32; k++ {
+		term *= (x * x) / (4 * float64(k*k))
+		sum += term
+		if term < sum*1e-12 {
+			break
+		}
+	}
+	return sum
+}
+
+func (f MipFilter) kernel() filterKernel {
+	switch f {
+	case MipFilterKaiser:
+		norm := besselI0(kaiserAlpha)
+		return filterKernel{kaiserRadius, func(x float64) float64 {
+			t := x / kaiserRadius
+			if t*t >= 1 {
+				return 0
+			}
+			return sinc(x) * besselI0(kaiserAlpha*math.Sqrt(1-t*t)) / norm
+		}}
+	case MipFilterLanczos:
+		return filterKernel{lanczosLobes, func(x float64) float64 {
+			if math.Abs(x) >= lanczosLobes {
+				return 0
+			}
+			return sinc(x) * sinc(x/lanczosLobes)
+		}}
+	default:
+		return filterKernel{0.5, func(x float64) float64 {
+			if math.Abs(x) <= 0.5 {
+				return 1
+			}
+			return 0
+		}}
+	}
+}
+
+type filterTap struct {
+	index  int
+	weight float32
+}
+
+// filterTaps returns the source pixels and weights for every destination
+// pixel when resampling a row of srcLen pixels to dstLen pixels, the edges
+// are clamped
+func (k filterKernel) filterTaps(srcLen, dstLen int) [][]filterTap {
+	scale := float64(srcLen) / float64(dstLen)
+	support := k.radius * max(scale, 1)
+	taps := make([][]filterTap, dstLen)
+	for i := range taps {
+		center := (float64(i)+0.5)*scale - 0.5
+		from := int(math.Floor(center - support))
+		to := int(math.Ceil(center + support))
+		total := 0.0
+		row := make([]filterTap, 0, to-from+1)
+		for s := from; s <= to; s++ {
+			w := k.weight((float64(s) - center) / max(scale, 1))
+			if w == 0 {
+				continue
+			}
+			total += w
+			row = append(row, filterTap{min(max(s, 0), srcLen-1), float32(w)})
+		}
+		if total == 0 {
+			row = append(row[:0], filterTap{min(max(int(math.Round(center)), 0), srcLen-1), 1})
+			total = 1
+		}
+		for j := range row {
+			row[j].weight /= float32(total)
+		}
+		taps[i] = row
+	}
+	return taps
+}
+
+// resize filters the image to the new size, the rows are filtered and then
+// the columns
+func (f *floatImage) resize(width, height int, filter MipFilter) floatImage {
+	k := filter.kernel()
+	xTaps := k.filterTaps(f.width, width)
+	yTaps := k.filterTaps(f.height, height)
+	rows := make([]float32, width*f.height*4)
+	for y := 0; y < f.height; y++ {
+		src := f.pix[y*f.width*4:]
+		dst := rows[y*width*4:]
+		for x, taps := range xTaps {
+			var c [4]float32
+			for _, t := range taps {
+				p := src[t.index*4:]
+				c[0] += p[0] * t.weight
+				c[1] += p[1] * t.weight
+				c[2] += p[2] * t.weight
+				c[3] += p[3] * t.weight
+			}
+			copy(dst[x*4:], c[:])
+		}
+	}
+	out := floatImage{width, height, make([]float32, width*height*4)}
+	for y, taps := range yTaps {
+		dst := out.pix[y*width*4:]
+		for x := 0; x < width*4; x++ {
+			var c float32
+			for _, t := range taps {
+				c += rows[t.index*width*4+x] * t.weight
+			}
+			dst[x] = c
+		}
+	}
+	// The sinc filters have negative lobes that can overshoot
+	for i := range out.pix {
+		out.pix[i] = min(max(out.pix[i], 0), 1)
+	}
+	return out
+}
+
+// MipLevelCount is the number of levels in the full mip chain of an image
+// of the given size, down to 1x1
+func MipLevelCount(width, height int) int {
+	count := 1
+	for width > 1 || height > 1 {
+		width, height = max(width/2, 1), max(height/2, 1)
+		count++
+	}
+	return count
+}
+
+// GenerateMips returns the image and its mip levels, each half the size of
+// the one before it. Each level is filtered from the one before it in
+// floating point so the rounding errors don't build up. A levels count of 0
+// generates the full chain. The color channels of sRGB images are filtered
+// in linear space.
+func GenerateMips(img Image, levels int, filter MipFilter, srgb bool) []Image {
+	full := MipLevelCount(img.Width, img.Height)
+	if levels <= 0 || levels > full {
+		levels = full
+	}
+	mips := make([]Image, 0, levels)
+	mips = append(mips, img)
+	current := toFloatImage(img, srgb)
+	for len(mips) < levels {
+		current = current.resize(max(current.width/2, 1), max(current.height/2, 1), filter)
+		mips = append(mips, current.toImage(srgb))
+	}
+	return mips
+}
+
+// Resize scales the image down so that neither side is larger than
+// maxSize, keeping the aspect ratio
+func Resize(img Image, maxSize int, filter MipFilter, srgb bool) Image {
+	if maxSize <= 0 || (img.Width <= maxSize && img.Height <= maxSize) {
+		return img
+	}
+	scale := float64(maxSize) / float64(max(img.Width, img.Height))
+	w := max(int(math.Round(float64(img.Width)*scale)), 1)
+	h := max(int(math.Round(float64(img.Height)*scale)), 1)
+	f := toFloatImage(img, srgb)
+	f = f.resize(w, h, filter)
+	return f.toImage(srgb)
+}
@@ -0,0 +1,71 @@
+/******************************************************************************/
+/* process.go                                                                 */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package texture_processing
+
+// Options are how an image is processed when it is imported
+type Options struct {
+	Format  Format
+	Quality Quality
+	// MipLevels is the number of mip levels to generate, 0 generates the
+	// full chain down to 1x1
+	MipLevels int
+	MipFilter MipFilter
+	// SRGB images have their color channels filtered in linear space, it
+	// should be false for data like normal and roughness maps
+	SRGB bool
+	// MaxSize is the largest the width or height can be, larger images are
+	// scaled down with the mip filter. 0 keeps the size of the image.
+	MaxSize int
+}
+
+// Process scales, generates the mip levels of and compresses the image
+func Process(img Image, opts Options) KTX2 {
+	img = Resize(img, opts.MaxSize, opts.MipFilter, opts.SRGB)
+	mips := GenerateMips(img, opts.MipLevels, opts.MipFilter, opts.SRGB)
+	k := KTX2{
+		Format: opts.Format,
+		SRGB:   opts.SRGB && opts.Format != FormatBC5,
+		Width:  img.Width,
+		Height: img.Height,
+		Levels: make([][]byte, len(mips)),
+	}
+	for i := range mips {
+		k.Levels[i] = Compress(mips[i], opts.Format, opts.Quality)
+	}
+	return k
+}
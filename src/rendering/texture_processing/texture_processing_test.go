@@ -0,0 +1,359 @@
+/******************************************************************************/
+/* texture_processing_test.go                                                 */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package texture_processing
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"testing"
+)
+
+// testGradientImage is a diagonal gradient where the colors of each block
+// are on a line, which all of the block formats can represent closely
+func testGradientImage(width, height int, alpha bool) Image {
+	img := Image{Width: width, Height: height, Pix: make([]byte, width*height*4)}
+	for y := range height {
+		for x := range width {
+			v := (x + y) * 255 / max(1, width+height-2)
+			p := img.Pix[(y*width+x)*4:]
+			p[0] = byte(v)
+			p[1] = byte(v/2 + 64)
+			p[2] = byte(255 - v)
+			p[3] = 255
+			if alpha {
+				p[3] = byte(255 - x*255/max(1, width-1))
+			}
+		}
+	}
+	return img
+}
+
+func decodeBC1Color(src []byte, out *[16][4]float32) {
+	c0 := binary.LittleEndian.Uint16(src[0:])
+	c1 := binary.LittleEndian.Uint16(src[2:])
+	bits := binary.LittleEndian.Uint32(src[4:])
+	e0, e1 := from565(c0), from565(c1)
+	var palette [4][4]float32
+	palette[0], palette[1] = e0, e1
+	for c := range 3 {
+		if c0 > c1 {
+			palette[2][c] = (2*e0[c] + e1[c]) / 3
+			palette[3][c] = (e0[c] + 2*e1[c]) / 3
+		} else {
+			palette[2][c] = (e0[c] + e1[c]) / 2
+		}
+	}
+	for i := range out {
+		p := palette[(bits>>(i*2))&3]
+		out[i][0], out[i][1], out[i][2] = p[0], p[1], p[2]
+	}
+}
+
+func decodeBC4(src []byte, out *[16][4]float32, channel int) {
+	palette := bc4Palette(src[0], src[1])
+	bits := uint64(0)
+	for i := range 6 {
+		bits |= uint64(src[2+i]) << (i * 8)
+	}
+	for i := range out {
+		out[i][channel] = palette[(bits>>(i*3))&7]
+	}
+}
+
+func readBits(src []byte, pos, bits int) uint32 {
+	v := uint32(0)
+	for i := range bits {
+		p := pos + i
+		v |= uint32(src[p/8]>>(p%8)&1) << i
+	}
+	return v
+}
+
+func decodeBC7Mode6(t *testing.T, src []byte, out *[16][4]float32) {
+	if readBits(src, 0, 7) != 1<<6 {
+		t.Fatal("expected a mode 6 block")
+	}
+	var e [2]bc7Endpoint
+	pos := 7
+	for c := range 4 {
+		e[0].rgba[c] = uint8(readBits(src, pos, 7))
+		e[1].rgba[c] = uint8(readBits(src, pos+7, 7))
+		pos += 14
+	}
+	e[0].p, e[1].p = uint8(readBits(src, pos, 1)), uint8(readBits(src, pos+1, 1))
+	pos += 2
+	c0, c1 := e[0].expand(), e[1].expand()
+	for i := range out {
+		bits := 4
+		if i == 0 {
+			bits = 3
+		}
+		w := bc7Weights4[readBits(src, pos, bits)]
+		pos += bits
+		for c := range 4 {
+			out[i][c] = float32(int(c0[c]*(64-w)+c1[c]*w+32) >> 6)
+		}
+	}
+}
+
+func decodeASTC4x4(t *testing.T, src []byte, out *[16][4]float32) {
+	mode := readBits(src, 0, 11)
+	cem := readBits(src, 13, 4)
+	channels, weightBits, levels := 4, 2, astcLevels4
+	if mode == astcModeOpaque {
+		if cem != astcEndpointRGB {
+			t.Fatalf("expected the RGB direct endpoint mode, got %d", cem)
+		}
+		channels, weightBits, levels = 3, 3, astcLevels8
+	} else if mode != astcModeAlpha || cem != astcEndpointRGBA {
+		t.Fatalf("unexpected block mode %x and endpoint mode %d", mode, cem)
+	}
+	var e0, e1 [4]float32
+	e0[3], e1[3] = 255, 255
+	for c := range channels {
+		e0[c] = float32(readBits(src, 17+c*16, 8))
+		e1[c] = float32(readBits(src, 25+c*16, 8))
+	}
+	if e1[0]+e1[1]+e1[2] < e0[0]+e0[1]+e0[2] {
+		t.Fatal("expected the endpoints to not be blue contracted")
+	}
+	pos := 127
+	for i := range out {
+		idx := 0
+		for bit := range weightBits {
+			idx |= int(src[pos/8]>>(pos%8)&1) << bit
+			pos--
+		}
+		w := float64(levels[idx] * 64)
+		for c := range 4 {
+			// 8 bit endpoints are expanded to 16 bits before interpolating
+			a, b := float64(int(e0[c])*257), float64(int(e1[c])*257)
+			v := math.Floor((a*(64-w) + b*w + 32) / 64)
+			out[i][c] = float32(int(v) >> 8)
+		}
+	}
+}
+
+func blockRMSE(t *testing.T, img Image, format Format, quality Quality) float64 {
+	data := Compress(img, format, quality)
+	_, _, size := format.BlockSize()
+	if len(data) != format.LevelSize(img.Width, img.Height) {
+		t.Fatalf("expected %d bytes, got %d", format.LevelSize(img.Width, img.Height), len(data))
+	}
+	cols := (img.Width + 3) / 4
+	total, count := 0.0, 0
+	for i := 0; i < len(data); i += size {
+		var decoded [16][4]float32
+		src := data[i : i+size]
+		channels := 4
+		switch format {
+		case FormatBC1:
+			decodeBC1Color(src, &decoded)
+			channels = 3
+		case FormatBC3:
+			decodeBC4(src[0:8], &decoded, 3)
+			decodeBC1Color(src[8:16], &decoded)
+		case FormatBC5:
+			decodeBC4(src[0:8], &decoded, 0)
+			decodeBC4(src[8:16], &decoded, 1)
+			channels = 2
+		case FormatBC7:
+			decodeBC7Mode6(t, src, &decoded)
+		case FormatASTC4x4:
+			decodeASTC4x4(t, src, &decoded)
+		}
+		b := readBlock(img, (i/size)%cols, (i/size)/cols)
+		for p := range b {
+			for c := range channels {
+				d := float64(b[p][c] - decoded[p][c])
+				total += d * d
+				count++
+			}
+		}
+	}
+	return math.Sqrt(total / float64(count))
+}
+
+func TestCompressRoundTrip(t *testing.T) {
+	tests := []struct {
+		format  Format
+		alpha   bool
+		maxRMSE float64
+	}{
+		{FormatBC1, false, 3},
+		{FormatBC3, true, 3},
+		{FormatBC5, false, 1.5},
+		{FormatBC7, false, 1},
+		{FormatBC7, true, 3.5},
+		{FormatASTC4x4, false, 1.5},
+		{FormatASTC4x4, true, 4},
+	}
+	for _, test := range tests {
+		img := testGradientImage(30, 22, test.alpha)
+		for _, q := range []Quality{QualityFast, QualityNormal, QualityHigh} {
+			if e := blockRMSE(t, img, test.format, q); e > test.maxRMSE {
+				t.Errorf("format %d at quality %d had an error of %f, expected at most %f",
+					test.format, q, e, test.maxRMSE)
+			}
+		}
+	}
+}
+
+func TestCompressSolidColor(t *testing.T) {
+	img := Image{Width: 4, Height: 4, Pix: bytes.Repeat([]byte{200, 100, 50, 255}, 16)}
+	for _, format := range []Format{FormatBC7, FormatASTC4x4} {
+		if e := blockRMSE(t, img, format, QualityNormal); e > 1 {
+			t.Errorf("expected format %d to keep a solid color, error was %f", format, e)
+		}
+	}
+}
+
+func TestHigherQualityIsNotWorse(t *testing.T) {
+	img := testGradientImage(32, 32, true)
+	for _, format := range []Format{FormatBC1, FormatBC7} {
+		fast := blockRMSE(t, img, format, QualityFast)
+		high := blockRMSE(t, img, format, QualityHigh)
+		if high > fast+0.01 {
+			t.Errorf("expected high quality to be at least as good as fast for format %d, %f > %f",
+				format, high, fast)
+		}
+	}
+}
+
+func TestMipLevelCount(t *testing.T) {
+	if c := MipLevelCount(256, 64); c != 9 {
+		t.Errorf("expected 9 levels for 256x64, got %d", c)
+	}
+	if c := MipLevelCount(1, 1); c != 1 {
+		t.Errorf("expected 1 level for 1x1, got %d", c)
+	}
+}
+
+func TestGenerateMipsSizes(t *testing.T) {
+	img := testGradientImage(37, 10, false)
+	for _, filter := range []MipFilter{MipFilterBox, MipFilterKaiser, MipFilterLanczos} {
+		mips := GenerateMips(img, 0, filter, true)
+		if len(mips) != MipLevelCount(37, 10) {
+			t.Fatalf("expected the full chain, got %d levels", len(mips))
+		}
+		w, h := 37, 10
+		for i, m := range mips {
+			if m.Width != w || m.Height != h || len(m.Pix) != w*h*4 {
+				t.Errorf("level %d was %dx%d, expected %dx%d", i, m.Width, m.Height, w, h)
+			}
+			w, h = max(1, w/2), max(1, h/2)
+		}
+	}
+	if mips := GenerateMips(img, 2, MipFilterBox, true); len(mips) != 2 {
+		t.Errorf("expected 2 levels, got %d", len(mips))
+	}
+}
+
+func TestGenerateMipsSRGBAverage(t *testing.T) {
+	// A black and white checker averages to half the light, which is about
+	// 188 in sRGB rather than the 128 of averaging the encoded values
+	img := Image{Width: 2, Height: 2, Pix: []byte{
+		0, 0, 0, 255, 255, 255, 255, 255,
+		255, 255, 255, 255, 0, 0, 0, 255,
+	}}
+	srgb := GenerateMips(img, 2, MipFilterBox, true)[1]
+	if v := srgb.Pix[0]; v < 185 || v > 190 {
+		t.Errorf("expected the sRGB average to be about 188, got %d", v)
+	}
+	if a := srgb.Pix[3]; a != 255 {
+		t.Errorf("expected alpha to not be gamma corrected, got %d", a)
+	}
+	linear := GenerateMips(img, 2, MipFilterBox, false)[1]
+	if v := linear.Pix[0]; v < 127 || v > 128 {
+		t.Errorf("expected the linear average to be about 128, got %d", v)
+	}
+}
+
+func TestResize(t *testing.T) {
+	img := testGradientImage(64, 16, false)
+	r := Resize(img, 32, MipFilterLanczos, true)
+	if r.Width != 32 || r.Height != 8 {
+		t.Errorf("expected 32x8, got %dx%d", r.Width, r.Height)
+	}
+	if r := Resize(img, 128, MipFilterBox, true); r.Width != 64 || r.Height != 16 {
+		t.Error("expected an image smaller than the max size to be unchanged")
+	}
+}
+
+func TestKTX2RoundTrip(t *testing.T) {
+	img := testGradientImage(20, 12, true)
+	for _, format := range []Format{FormatRGBA8, FormatBC1, FormatBC3, FormatBC5, FormatBC7, FormatASTC4x4} {
+		k := Process(img, Options{
+			Format:    format,
+			Quality:   QualityFast,
+			MipFilter: MipFilterKaiser,
+			SRGB:      true,
+		})
+		data := k.Encode()
+		if !bytes.Equal(data[:12], ktx2Identifier[:]) {
+			t.Fatal("expected the KTX2 identifier")
+		}
+		d, err := DecodeKTX2(data)
+		if err != nil {
+			t.Fatalf("failed to decode format %d: %v", format, err)
+		}
+		if d.Format != format || d.SRGB != (format != FormatBC5) || d.Width != 20 || d.Height != 12 {
+			t.Errorf("header mismatch for format %d: %+v", format, d)
+		}
+		if len(d.Levels) != MipLevelCount(20, 12) {
+			t.Fatalf("expected %d levels, got %d", MipLevelCount(20, 12), len(d.Levels))
+		}
+		w, h := 20, 12
+		for i := range d.Levels {
+			if !bytes.Equal(d.Levels[i], k.Levels[i]) {
+				t.Errorf("level %d of format %d did not round trip", i, format)
+			}
+			if len(d.Levels[i]) != format.LevelSize(w, h) {
+				t.Errorf("level %d of format %d was %d bytes", i, format, len(d.Levels[i]))
+			}
+			w, h = max(1, w/2), max(1, h/2)
+		}
+	}
+}
+
+func TestDecodeKTX2Invalid(t *testing.T) {
+	if _, err := DecodeKTX2([]byte("not a ktx2 file")); err != ErrInvalidKTX2 {
+		t.Errorf("expected ErrInvalidKTX2, got %v", err)
+	}
+}
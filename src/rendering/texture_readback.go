@@ -0,0 +1,111 @@
+/******************************************************************************/
+/* texture_readback.go                                                        */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package rendering
+
+import (
+	"encoding/binary"
+	"errors"
+	"image"
+	"math"
+
+	vk "kaiju/rendering/vulkan"
+)
+
+var errReadbackFormat = errors.New("the texture format can not be read back")
+
+// readbackPixelSize is the number of bytes for a pixel of the format when it
+// is copied out of an image, 0 for formats that can't be read back
+func readbackPixelSize(format vk.Format) int {
+	switch format {
+	case vk.FormatR8g8b8a8Unorm, vk.FormatR8g8b8a8Srgb,
+		vk.FormatB8g8r8a8Unorm, vk.FormatB8g8r8a8Srgb:
+		return 4
+	case vk.FormatR16g16b16a16Sfloat:
+		return 8
+	case vk.FormatR32g32b32a32Sfloat:
+		return 16
+	default:
+		return 0
+	}
+}
+
+// readbackImage converts the pixels that were copied out of an image into an
+// 8 bit RGBA image, float formats are clamped to the 0 to 1 range
+func readbackImage(format vk.Format, pixels []byte, width, height int) (*image.RGBA, error) {
+	size := readbackPixelSize(format)
+	if size == 0 {
+		return nil, errReadbackFormat
+	}
+	count := width * height
+	if len(pixels) < count*size {
+		return nil, errors.New("not enough pixel data for the size of the image")
+	}
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	switch format {
+	case vk.FormatR8g8b8a8Unorm, vk.FormatR8g8b8a8Srgb:
+		copy(img.Pix, pixels[:count*size])
+	case vk.FormatB8g8r8a8Unorm, vk.FormatB8g8r8a8Srgb:
+		for i := 0; i < count*size; i += size {
+			img.Pix[i+0] = pixels[i+2]
+			img.Pix[i+1] = pixels[i+1]
+			img.Pix[i+2] = pixels[i+0]
+			img.Pix[i+3] = pixels[i+3]
+		}
+	case vk.FormatR16g16b16a16Sfloat:
+		for i := range count * 4 {
+			h := binary.LittleEndian.Uint16(pixels[i*2:])
+			img.Pix[i] = readbackUnitToByte(halfToFloat32(h))
+		}
+	case vk.FormatR32g32b32a32Sfloat:
+		for i := range count * 4 {
+			f := math.Float32frombits(binary.LittleEndian.Uint32(pixels[i*4:]))
+			img.Pix[i] = readbackUnitToByte(f)
+		}
+	}
+	return img, nil
+}
+
+func readbackUnitToByte(f float32) byte {
+	if math.IsNaN(float64(f)) || f <= 0 {
+		return 0
+	}
+	if f >= 1 {
+		return 255
+	}
+	return byte(f*255 + 0.5)
+}
@@ -0,0 +1,216 @@
+/******************************************************************************/
+/* texture_tga.go                                                             */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package rendering
+
+import (
+	"encoding/binary"
+	"errors"
+)
+
+const (
+	tgaHeaderSize         = 18
+	tgaTypeColorMapped    = 1
+	tgaTypeTrueColor      = 2
+	tgaTypeGrayscale      = 3
+	tgaTypeRLE            = 8
+	tgaDescriptorAlpha    = 0x0F
+	tgaDescriptorRight    = 0x10
+	tgaDescriptorTop      = 0x20
+	tgaRLEPacket          = 0x80
+	tgaRLEPacketCountBits = 0x7F
+)
+
+var errInvalidTGA = errors.New("the data is not a valid TGA image")
+
+// decodeTGA reads an uncompressed or run length encoded true color,
+// grayscale or color mapped TGA image into RGBA pixels with the first row
+// at the top of the image
+func decodeTGA(mem []byte) (width, height int, pix []byte, err error) {
+	if len(mem) < tgaHeaderSize {
+		return 0, 0, nil, errInvalidTGA
+	}
+	le := binary.LittleEndian
+	idLength := int(mem[0])
+	hasColorMap := mem[1] == 1
+	imageType := mem[2]
+	mapFirst, mapLength := int(le.Uint16(mem[3:])), int(le.Uint16(mem[5:]))
+	mapDepth := int(mem[7])
+	width, height = int(le.Uint16(mem[12:])), int(le.Uint16(mem[14:]))
+	depth := int(mem[16])
+	descriptor := mem[17]
+	rle := imageType&tgaTypeRLE != 0
+	baseType := imageType &^ tgaTypeRLE
+	if !validTextureSize(width, height) {
+		return 0, 0, nil, errInvalidTGA
+	}
+	offset := tgaHeaderSize + idLength
+	if offset > len(mem) {
+		return 0, 0, nil, errInvalidTGA
+	}
+	var colorMap [][4]byte
+	if hasColorMap {
+		entrySize := (mapDepth + 7) / 8
+		end := offset + mapLength*entrySize
+		if end > len(mem) {
+			return 0, 0, nil, errInvalidTGA
+		}
+		colorMap = make([][4]byte, mapFirst+mapLength)
+		for i := range mapLength {
+			colorMap[mapFirst+i] = tgaColor(mem[offset+i*entrySize:], mapDepth, mapDepth == 32)
+		}
+		offset = end
+	}
+	switch baseType {
+	case tgaTypeColorMapped:
+		if colorMap == nil || (depth != 8 && depth != 16) {
+			return 0, 0, nil, errInvalidTGA
+		}
+	case tgaTypeTrueColor:
+		if depth != 15 && depth != 16 && depth != 24 && depth != 32 {
+			return 0, 0, nil, errInvalidTGA
+		}
+	case tgaTypeGrayscale:
+		if depth != 8 && depth != 16 {
+			return 0, 0, nil, errInvalidTGA
+		}
+	default:
+		return 0, 0, nil, errors.New("the TGA image type is not supported")
+	}
+	// Some tools write 32 bit images without setting the alpha bits, the
+	// alpha channel is then left unused
+	hasAlpha := depth >= 16 && descriptor&tgaDescriptorAlpha != 0
+	pixelSize := (depth + 7) / 8
+	count := width * height
+	src := mem[offset:]
+	// A run packet is the most pixels that can come from the fewest bytes,
+	// anything claiming more pixels than that is truncated
+	if rle && len(src)/(1+pixelSize)*(tgaRLEPacketCountBits+1) < count {
+		return 0, 0, nil, errInvalidTGA
+	}
+	var raw []byte
+	if rle {
+		raw = make([]byte, 0, count*pixelSize)
+		for len(raw) < count*pixelSize {
+			if len(src) == 0 {
+				return 0, 0, nil, errInvalidTGA
+			}
+			header := src[0]
+			n := int(header&tgaRLEPacketCountBits) + 1
+			src = src[1:]
+			if header&tgaRLEPacket != 0 {
+				if len(src) < pixelSize {
+					return 0, 0, nil, errInvalidTGA
+				}
+				for range n {
+					raw = append(raw, src[:pixelSize]...)
+				}
+				src = src[pixelSize:]
+			} else {
+				if len(src) < n*pixelSize {
+					return 0, 0, nil, errInvalidTGA
+				}
+				raw = append(raw, src[:n*pixelSize]...)
+				src = src[n*pixelSize:]
+			}
+		}
+		raw = raw[:count*pixelSize]
+	} else {
+		if len(src) < count*pixelSize {
+			return 0, 0, nil, errInvalidTGA
+		}
+		raw = src[:count*pixelSize]
+	}
+	pix = make([]byte, count*bytesInPixel)
+	for y := range height {
+		dy := y
+		if descriptor&tgaDescriptorTop == 0 {
+			dy = height - 1 - y
+		}
+		for x := range width {
+			dx := x
+			if descriptor&tgaDescriptorRight != 0 {
+				dx = width - 1 - x
+			}
+			p := raw[(y*width+x)*pixelSize:]
+			var c [4]byte
+			switch baseType {
+			case tgaTypeColorMapped:
+				idx := int(p[0])
+				if pixelSize == 2 {
+					idx = int(le.Uint16(p))
+				}
+				if idx >= len(colorMap) {
+					return 0, 0, nil, errInvalidTGA
+				}
+				c = colorMap[idx]
+			case tgaTypeGrayscale:
+				c = [4]byte{p[0], p[0], p[0], 255}
+				if pixelSize == 2 {
+					c[3] = p[1]
+				}
+			default:
+				c = tgaColor(p, depth, hasAlpha)
+			}
+			copy(pix[(dy*width+dx)*bytesInPixel:], c[:])
+		}
+	}
+	return width, height, pix, nil
+}
+
+// tgaColor converts a BGR(A) or 16 bit ARRRRRGGGGGBBBBB color to RGBA
+func tgaColor(p []byte, depth int, hasAlpha bool) [4]byte {
+	switch depth {
+	case 15, 16:
+		v := binary.LittleEndian.Uint16(p)
+		expand := func(c uint16) byte { return byte(c<<3 | c>>2) }
+		c := [4]byte{expand((v >> 10) & 31), expand((v >> 5) & 31), expand(v & 31), 255}
+		if depth == 16 && hasAlpha && v&0x8000 == 0 {
+			c[3] = 0
+		}
+		return c
+	case 24:
+		return [4]byte{p[2], p[1], p[0], 255}
+	case 32:
+		c := [4]byte{p[2], p[1], p[0], 255}
+		if hasAlpha {
+			c[3] = p[3]
+		}
+		return c
+	}
+	return [4]byte{}
+}
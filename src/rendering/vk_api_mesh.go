@@ -69,5 +69,11 @@ func (vr *Vulkan) DestroyMesh(mesh *Mesh) {
 	vr.dbg.remove(vk.TypeToUintPtr(id.vertexBuffer))
 	vk.FreeMemory(vr.device, id.vertexBufferMemory, nil)
 	vr.dbg.remove(vk.TypeToUintPtr(id.vertexBufferMemory))
+	if d := id.dynamic; d != nil {
+		for i := range d.frames {
+			vr.destroyHostBuffer(&d.frames[i].vertex)
+			vr.destroyHostBuffer(&d.frames[i].index)
+		}
+	}
 	mesh.MeshId = MeshId{}
 }
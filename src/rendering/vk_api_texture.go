@@ -119,7 +119,10 @@ func (vr *Vulkan) CreateTexture(texture *Texture, data *TextureData) {
 	case TextureInputTypeCompressedRgbaAstc4x4:
 		//format = VK_FORMAT_ASTC_4x4_SFLOAT_BLOCK
 		format = vk.FormatAstc4x4SrgbBlock
-		//format = VK_FORMAT_ASTC_4x4_UNORM_BLOCK;
+		// Imported KTX textures are linearized in the shader like PNGs
+		if data.InputType == TextureFileFormatKtx2 || data.InputType == TextureFileFormatKtx {
+			format = vk.FormatAstc4x4UnormBlock
+		}
 	case TextureInputTypeCompressedRgbaAstc5x4:
 		//format = VK_FORMAT_ASTC_5x4_SFLOAT_BLOCK
 		format = vk.FormatAstc5x4SrgbBlock
@@ -172,6 +175,27 @@ func (vr *Vulkan) CreateTexture(texture *Texture, data *TextureData) {
 		//format = VK_FORMAT_ASTC_12x1SFLOAT_BLOCK;
 		format = vk.FormatAstc12x12SrgbBlock
 		//format = VK_FORMAT_ASTC_12x12_UNORM_BLOCK;
+	case TextureInputTypeCompressedRgbBc1:
+		format = vk.FormatBc1RgbUnormBlock
+		if data.Format == TextureColorFormatRgbaSrgb {
+			format = vk.FormatBc1RgbSrgbBlock
+		}
+	case TextureInputTypeCompressedRgbaBc3:
+		format = vk.FormatBc3UnormBlock
+		if data.Format == TextureColorFormatRgbaSrgb {
+			format = vk.FormatBc3SrgbBlock
+		}
+	case TextureInputTypeCompressedRgBc5:
+		format = vk.FormatBc5UnormBlock
+	case TextureInputTypeCompressedRgbaBc7:
+		format = vk.FormatBc7UnormBlock
+		if data.Format == TextureColorFormatRgbaSrgb {
+			format = vk.FormatBc7SrgbBlock
+		}
+	case TextureInputTypeRgba16f:
+		format = vk.FormatR16g16b16a16Sfloat
+	case TextureInputTypeRgba32f:
+		format = vk.FormatR32g32b32a32Sfloat
 	case TextureInputTypeLuminance:
 		panic("Luminance textures are not supported")
 	}
@@ -205,7 +229,9 @@ func (vr *Vulkan) CreateTexture(texture *Texture, data *TextureData) {
 	use := vk.ImageUsageTransferSrcBit | vk.ImageUsageTransferDstBit | vk.ImageUsageSampledBit
 	props := vk.MemoryPropertyDeviceLocalBit
 	mip := texture.MipLevels
-	if mip <= 0 {
+	if data.MipLevels > 0 {
+		mip = data.MipLevels
+	} else if mip <= 0 {
 		w, h := float32(data.Width), float32(data.Height)
 		mip = int(matrix.Floor(matrix.Log2(matrix.Max(w, h)))) + 1
 	}
@@ -234,14 +260,29 @@ func (vr *Vulkan) CreateTexture(texture *Texture, data *TextureData) {
 	vr.transitionImageLayout(&texture.RenderId,
 		vk.ImageLayoutTransferDstOptimal, vk.ImageAspectFlags(vk.ImageAspectColorBit),
 		texture.RenderId.Access, nil)
-	vr.copyBufferToImage(stagingBuffer, texture.RenderId.Image,
-		uint32(data.Width), uint32(data.Height))
+	if data.MipLevels > 0 {
+		vr.copyMipLevelsToImage(stagingBuffer, texture.RenderId.Image, data)
+	} else {
+		vr.copyBufferToImage(stagingBuffer, texture.RenderId.Image,
+			uint32(data.Width), uint32(data.Height))
+	}
 	vk.DestroyBuffer(vr.device, stagingBuffer, nil)
 	vr.dbg.remove(vk.TypeToUintPtr(stagingBuffer))
 	vk.FreeMemory(vr.device, stagingBufferMemory, nil)
 	vr.dbg.remove(vk.TypeToUintPtr(stagingBufferMemory))
-	vr.generateMipmaps(texture.RenderId.Image, format,
-		uint32(data.Width), uint32(data.Height), uint32(mip), filter)
+	if data.MipLevels > 0 {
+		vr.transitionImageLayout(&texture.RenderId,
+			vk.ImageLayoutShaderReadOnlyOptimal, vk.ImageAspectFlags(vk.ImageAspectColorBit),
+			vk.AccessFlags(vk.AccessShaderReadBit), nil)
+	} else if !vr.generateMipmaps(texture.RenderId.Image, format,
+		uint32(data.Width), uint32(data.Height), uint32(mip), filter) {
+		// The format can't be blitted, so only the full size image is usable
+		vr.transitionImageLayout(&texture.RenderId,
+			vk.ImageLayoutShaderReadOnlyOptimal, vk.ImageAspectFlags(vk.ImageAspectColorBit),
+			vk.AccessFlags(vk.AccessShaderReadBit), nil)
+		mip = 1
+		texture.RenderId.MipLevels = 1
+	}
 	vr.createImageView(&texture.RenderId,
 		vk.ImageAspectFlags(vk.ImageAspectColorBit))
 	vr.createTextureSampler(&texture.RenderId.Sampler, uint32(mip), filter)
@@ -268,8 +309,3 @@ func (vr *Vulkan) DestroyTexture(texture *Texture) {
 	vr.textureIdFree(&texture.RenderId)
 	texture.RenderId = TextureId{}
 }
-
-func (vr *Vulkan) TextureReadPixel(texture *Texture, x, y int) matrix.Color {
-	defer tracing.NewRegion("Vulkan::TextureReadPixel").End()
-	panic("not implemented")
-}
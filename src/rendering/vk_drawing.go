@@ -63,7 +63,7 @@ func (vr *Vulkan) mapAndCopy(fromBuffer []byte, sb ShaderBuffer, mapLen vk.Devic
 	return true
 }
 
-func (vr *Vulkan) writeDrawingDescriptors(material *Material, groups []DrawInstanceGroup) bool {
+func (vr *Vulkan) writeDrawingDescriptors(material *Material, groups []DrawInstanceGroup, globals vk.Buffer) bool {
 	shaderDataSize := material.Shader.DriverData.Stride
 	instanceSize := vr.padUniformBufferSize(vk.DeviceSize(shaderDataSize))
 	updatedAnything := false
@@ -94,46 +94,48 @@ func (vr *Vulkan) writeDrawingDescriptors(material *Material, groups []DrawInsta
 			continue
 		}
 		set := group.InstanceDriverData.descriptorSets[vr.currentFrame]
-		globalInfo := bufferInfo(vr.globalUniformBuffers[vr.currentFrame],
+		globalInfo := bufferInfo(globals,
 			vk.DeviceSize(unsafe.Sizeof(*(*GlobalShaderData)(nil))))
 		namedInfos := map[string]vk.DescriptorBufferInfo{}
 		for k := range group.namedBuffers {
 			namedInfos[k] = bufferInfo(group.namedBuffers[k].buffers[vr.currentFrame],
 				group.namedBuffers[k].size)
 		}
+		const maxDescriptorWrites = 4
+		descriptorWrites := [maxDescriptorWrites]vk.WriteDescriptorSet{
+			prepareSetWriteBuffer(set, []vk.DescriptorBufferInfo{globalInfo},
+				0, vk.DescriptorTypeUniformBuffer),
+		}
+		count := 1
 		texCount := len(group.MaterialInstance.Textures)
 		if texCount > 0 {
 			for j := 0; j < texCount; j++ {
 				t := group.MaterialInstance.Textures[j]
-				group.imageInfos[j] = imageInfo(t.RenderId.View, t.RenderId.Sampler)
-			}
-			const maxDescriptorWrites = 4
-			descriptorWrites := [maxDescriptorWrites]vk.WriteDescriptorSet{
-				prepareSetWriteBuffer(set, []vk.DescriptorBufferInfo{globalInfo},
-					0, vk.DescriptorTypeUniformBuffer),
-				prepareSetWriteImage(set, group.imageInfos, 1, false),
-			}
-			count := 2
-			for k := range group.namedBuffers {
-				if count >= maxDescriptorWrites {
-					slog.Error("need to increase max descriptor writes array size")
-					break
+				if t.renderPass != nil && !t.renderPass.drawn {
+					// Nothing has been drawn into the image yet, like the
+					// shadow map before shadows are enabled, the default
+					// texture is white so it reads as nothing in shadow
+					t = vr.defaultTexture
 				}
-				descriptorWrites[count] = prepareSetWriteBuffer(set,
-					[]vk.DescriptorBufferInfo{namedInfos[k]},
-					uint32(group.namedBuffers[k].bindingId),
-					vk.DescriptorTypeUniformBuffer)
-				count++
+				group.imageInfos[j] = imageInfo(t.RenderId.View, t.RenderId.Sampler)
 			}
-			vk.UpdateDescriptorSets(vr.device, uint32(count), &descriptorWrites[0], 0, nil)
-		} else {
-			descriptorWrites := []vk.WriteDescriptorSet{
-				prepareSetWriteBuffer(set, []vk.DescriptorBufferInfo{globalInfo},
-					0, vk.DescriptorTypeUniformBuffer),
+			descriptorWrites[count] = prepareSetWriteImage(set, group.imageInfos, 1, false)
+			count++
+		}
+		// Named buffers, like the material parameters, are written even
+		// when the material has no textures
+		for k := range group.namedBuffers {
+			if count >= maxDescriptorWrites {
+				slog.Error("need to increase max descriptor writes array size")
+				break
 			}
-			count := uint32(len(descriptorWrites))
-			vk.UpdateDescriptorSets(vr.device, count, &descriptorWrites[0], 0, nil)
+			descriptorWrites[count] = prepareSetWriteBuffer(set,
+				[]vk.DescriptorBufferInfo{namedInfos[k]},
+				uint32(group.namedBuffers[k].bindingId),
+				vk.DescriptorTypeUniformBuffer)
+			count++
 		}
+		vk.UpdateDescriptorSets(vr.device, uint32(count), &descriptorWrites[0], 0, nil)
 		updatedAnything = true
 	}
 	return updatedAnything
@@ -146,15 +148,19 @@ func (vr *Vulkan) renderEach(cmd vk.CommandBuffer, pipeline vk.Pipeline, layout
 		if !group.IsReady() || group.VisibleCount() == 0 {
 			continue
 		}
+		meshId := group.Mesh.MeshId
+		vertexBuffer, indexBuffer, ok := vr.meshBuffers(&meshId)
+		if !ok {
+			continue
+		}
 		descriptorSets := [...]vk.DescriptorSet{
 			group.InstanceDriverData.descriptorSets[vr.currentFrame],
 		}
 		dynOffsets := [...]uint32{0}
 		vk.CmdBindDescriptorSets(cmd, vk.PipelineBindPointGraphics,
 			layout, 0, 1, &descriptorSets[0], 0, &dynOffsets[0])
-		meshId := group.Mesh.MeshId
 		vbOffsets := [...]vk.DeviceSize{0}
-		vb := [...]vk.Buffer{meshId.vertexBuffer}
+		vb := [...]vk.Buffer{vertexBuffer}
 		vk.CmdBindVertexBuffers(cmd, 0, 1, &vb[0], &vbOffsets[0])
 		instanceBuffers := [...]vk.Buffer{group.instanceBuffer.buffers[vr.currentFrame]}
 		ibOffsets := [...]vk.DeviceSize{0}
@@ -165,7 +171,7 @@ func (vr *Vulkan) renderEach(cmd vk.CommandBuffer, pipeline vk.Pipeline, layout
 			vk.CmdBindVertexBuffers(cmd, uint32(group.namedBuffers[k].bindingId),
 				1, &namedBuffers[0], &ibOffsets[0])
 		}
-		vk.CmdBindIndexBuffer(cmd, meshId.indexBuffer, 0, vk.IndexTypeUint32)
+		vk.CmdBindIndexBuffer(cmd, indexBuffer, 0, vk.IndexTypeUint32)
 		vk.CmdDrawIndexed(cmd, meshId.indexCount,
 			uint32(group.VisibleCount()), 0, 0, 0)
 	}
@@ -173,20 +179,30 @@ func (vr *Vulkan) renderEach(cmd vk.CommandBuffer, pipeline vk.Pipeline, layout
 
 func (vr *Vulkan) Draw(renderPass *RenderPass, drawings []ShaderDraw) bool {
 	defer tracing.NewRegion("Vulkan::Draw").End()
-	if !vr.hasSwapChain || len(drawings) == 0 {
+	offscreen := renderPass.construction.Offscreen
+	if !vr.hasSwapChain || (len(drawings) == 0 && !offscreen) {
 		return false
 	}
+	globals := vr.globalUniformBuffers[vr.currentFrame]
+	if renderPass.target != nil {
+		globals = vr.prepareRenderTarget(renderPass.target)
+	}
 	drawingAnything := false
 	doDrawings := make([]bool, len(drawings))
 	for i := range drawings {
 		d := &drawings[i]
-		doDrawings[i] = vr.writeDrawingDescriptors(d.material, d.instanceGroups)
+		doDrawings[i] = vr.writeDrawingDescriptors(d.material, d.instanceGroups, globals)
 		drawingAnything = drawingAnything || doDrawings[i]
 	}
-	if !drawingAnything {
+	// Offscreen passes are sampled by other materials, so they are still
+	// cleared when there is nothing to draw into them
+	if !drawingAnything && !offscreen {
 		return false
 	}
-	renderPass.beginNextSubpass(vr.currentFrame, vr.swapChainExtent, renderPass.construction.ImageClears)
+	sampled := vr.renderGraphSampledImages(renderPass)
+	vr.beginRenderGraphBarriers(sampled)
+	extent := renderPass.extent(vr)
+	renderPass.beginNextSubpass(vr.currentFrame, extent, renderPass.construction.ImageClears)
 	for i := range drawings {
 		d := &drawings[i]
 		if doDrawings[i] {
@@ -198,7 +214,7 @@ func (vr *Vulkan) Draw(renderPass *RenderPass, drawings []ShaderDraw) bool {
 	renderPass.ExecuteSecondaryCommands()
 	for i := range renderPass.subpasses {
 		s := &renderPass.subpasses[i]
-		renderPass.beginNextSubpass(vr.currentFrame, vr.swapChainExtent, renderPass.construction.ImageClears)
+		renderPass.beginNextSubpass(vr.currentFrame, extent, renderPass.construction.ImageClears)
 		cmd := &s.cmd[vr.currentFrame]
 		vk.CmdBindPipeline(cmd.buffer, vk.PipelineBindPointGraphics, s.shader.RenderId.graphicsPipeline)
 		imageInfos := make([]vk.DescriptorImageInfo, len(s.sampledImages))
@@ -229,6 +245,8 @@ func (vr *Vulkan) Draw(renderPass *RenderPass, drawings []ShaderDraw) bool {
 	}
 	renderPass.endSubpasses()
 	vr.forceQueueCommand(renderPass.cmd[vr.currentFrame])
+	vr.endRenderGraphBarriers(sampled)
+	renderPass.drawn = true
 	return true
 }
 
@@ -285,7 +303,7 @@ func (vr *Vulkan) prepCombinedTargets(passes []*RenderPass) {
 	vr.combinedDrawings.PreparePending()
 }
 
-func (vr *Vulkan) combineTargets() *TextureId {
+func (vr *Vulkan) combineTargets() *Texture {
 	defer tracing.NewRegion("Vulkan::combineTargets").End()
 	cmd := vr.beginSingleTimeCommands()
 	// There is only one render pass in combined, so we can just grab the first one
@@ -299,7 +317,7 @@ func (vr *Vulkan) combineTargets() *TextureId {
 	vr.endSingleTimeCommands(cmd)
 	combinePass := vr.combinedDrawings.renderPassGroups[0].renderPass
 	vr.Draw(combinePass, draws)
-	return &combinePass.textures[0].RenderId
+	return &combinePass.textures[0]
 }
 
 func (vr *Vulkan) cleanupCombined(cmd *CommandRecorder) {
@@ -323,7 +341,7 @@ func (vr *Vulkan) BlitTargets(passes []*RenderPass) {
 	vr.prepCombinedTargets(passes)
 	vr.delayWrittenCommands = true
 	defer func() { vr.delayWrittenCommands = false }()
-	img := vr.combineTargets()
+	img := &vr.postProcess.render(vr, vr.combineTargets()).RenderId
 	cmd := vr.beginSingleTimeCommands()
 	defer vr.endSingleTimeCommands(cmd)
 	frame := vr.currentFrame
@@ -350,6 +368,7 @@ func (vr *Vulkan) BlitTargets(passes []*RenderPass) {
 	vk.CmdBlitImage(cmd.buffer, img.Image, img.Layout,
 		vr.swapImages[idxSF].Image, vk.ImageLayoutTransferDstOptimal,
 		1, &region, vk.FilterNearest)
+	vr.recordFrameCapture(img, cmd)
 	vr.transitionImageLayout(img, vk.ImageLayoutColorAttachmentOptimal,
 		vk.ImageAspectFlags(vk.ImageAspectColorBit),
 		vk.AccessFlags(vk.AccessColorAttachmentReadBit|vk.AccessColorAttachmentWriteBit), cmd)
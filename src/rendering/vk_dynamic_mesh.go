@@ -0,0 +1,151 @@
+/******************************************************************************/
+/* vk_dynamic_mesh.go                                                         */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package rendering
+
+import (
+	"kaiju/klib"
+	"kaiju/platform/profiler/tracing"
+	"log/slog"
+	"unsafe"
+
+	vk "kaiju/rendering/vulkan"
+)
+
+// vkDynamicMesh holds the buffers of a mesh that is changed through
+// UpdateMesh. Each frame in flight has its own host visible buffers, they
+// only grow and are written when the frame draws the mesh, so the new data
+// never touches the buffers that an earlier frame is still reading.
+type vkDynamicMesh struct {
+	verts   []Vertex
+	indices []uint32
+	frames  [maxFramesInFlight]vkDynamicMeshFrame
+}
+
+type vkDynamicMeshFrame struct {
+	vertex vkHostBuffer
+	index  vkHostBuffer
+	stale  bool
+}
+
+type vkHostBuffer struct {
+	buffer   vk.Buffer
+	memory   vk.DeviceMemory
+	capacity vk.DeviceSize
+}
+
+// UpdateMesh replaces the vertices and indices of a mesh that changes often,
+// such as debug lines. Unlike creating the mesh again, this doesn't wait on
+// the device, the data is copied and written into the buffers of the frame
+// that next draws the mesh.
+func (vr *Vulkan) UpdateMesh(mesh *Mesh, verts []Vertex, indices []uint32) {
+	defer tracing.NewRegion("Vulkan::UpdateMesh").End()
+	if mesh.MeshId.dynamic == nil {
+		if mesh.MeshId.vertexBuffer != vk.Buffer(vk.NullHandle) {
+			vr.DestroyMesh(mesh)
+		}
+		mesh.MeshId.dynamic = &vkDynamicMesh{}
+	}
+	id := &mesh.MeshId
+	d := id.dynamic
+	d.verts = append(d.verts[:0], verts...)
+	d.indices = append(d.indices[:0], indices...)
+	for i := range d.frames {
+		d.frames[i].stale = true
+	}
+	id.vertexCount = uint32(len(verts))
+	id.indexCount = uint32(len(indices))
+}
+
+// meshBuffers returns the vertex and index buffers to draw the mesh with for
+// the current frame, false is returned if there is nothing to draw
+func (vr *Vulkan) meshBuffers(id *MeshId) (vk.Buffer, vk.Buffer, bool) {
+	d := id.dynamic
+	if d == nil {
+		return id.vertexBuffer, id.indexBuffer, true
+	}
+	if len(d.verts) == 0 || len(d.indices) == 0 {
+		return vk.Buffer(vk.NullHandle), vk.Buffer(vk.NullHandle), false
+	}
+	f := &d.frames[vr.currentFrame]
+	if f.stale {
+		if !vr.writeHostBuffer(&f.vertex, klib.StructSliceToByteArray(d.verts),
+			vk.BufferUsageFlags(vk.BufferUsageVertexBufferBit)) ||
+			!vr.writeHostBuffer(&f.index, klib.StructSliceToByteArray(d.indices),
+				vk.BufferUsageFlags(vk.BufferUsageIndexBufferBit)) {
+			return vk.Buffer(vk.NullHandle), vk.Buffer(vk.NullHandle), false
+		}
+		f.stale = false
+	}
+	return f.vertex.buffer, f.index.buffer, true
+}
+
+// writeHostBuffer copies the data into the buffer, growing it first if it is
+// too small. The fence of the current frame has been waited on before any
+// drawing, so the buffer being replaced is no longer in use.
+func (vr *Vulkan) writeHostBuffer(b *vkHostBuffer, data []byte, usage vk.BufferUsageFlags) bool {
+	size := vk.DeviceSize(len(data))
+	if size > b.capacity {
+		capacity := max(size, b.capacity*2)
+		vr.destroyHostBuffer(b)
+		if !vr.CreateBuffer(capacity, usage,
+			vk.MemoryPropertyFlags(vk.MemoryPropertyHostVisibleBit|vk.MemoryPropertyHostCoherentBit),
+			&b.buffer, &b.memory) {
+			slog.Error("failed to create the buffer for the dynamic mesh")
+			vr.destroyHostBuffer(b)
+			return false
+		}
+		b.capacity = capacity
+	}
+	var mapped unsafe.Pointer
+	vk.MapMemory(vr.device, b.memory, 0, size, 0, &mapped)
+	vk.Memcopy(mapped, data)
+	vk.UnmapMemory(vr.device, b.memory)
+	return true
+}
+
+func (vr *Vulkan) destroyHostBuffer(b *vkHostBuffer) {
+	if b.buffer != vk.Buffer(vk.NullHandle) {
+		vk.DestroyBuffer(vr.device, b.buffer, nil)
+		vr.dbg.remove(vk.TypeToUintPtr(b.buffer))
+	}
+	if b.memory != vk.DeviceMemory(vk.NullHandle) {
+		vk.FreeMemory(vr.device, b.memory, nil)
+		vr.dbg.remove(vk.TypeToUintPtr(b.memory))
+	}
+	*b = vkHostBuffer{}
+}
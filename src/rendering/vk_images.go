@@ -315,6 +315,31 @@ func (vr *Vulkan) copyBufferToImage(buffer vk.Buffer, image vk.Image, width, hei
 	vr.endSingleTimeCommands(cmd)
 }
 
+// copyMipLevelsToImage copies each of the mip levels that were generated on
+// import, they are in the buffer one after the other from the largest
+func (vr *Vulkan) copyMipLevelsToImage(buffer vk.Buffer, image vk.Image, data *TextureData) {
+	regions := make([]vk.BufferImageCopy, data.MipLevels)
+	offset := 0
+	for i := range regions {
+		r := &regions[i]
+		r.BufferOffset = vk.DeviceSize(offset)
+		r.ImageSubresource.AspectMask = vk.ImageAspectFlags(vk.ImageAspectColorBit)
+		r.ImageSubresource.MipLevel = uint32(i)
+		r.ImageSubresource.BaseArrayLayer = 0
+		r.ImageSubresource.LayerCount = 1
+		r.ImageExtent = vk.Extent3D{
+			Width:  uint32(max(data.Width>>i, 1)),
+			Height: uint32(max(data.Height>>i, 1)),
+			Depth:  1,
+		}
+		offset += data.mipLevelSize(i)
+	}
+	cmd := vr.beginSingleTimeCommands()
+	vk.CmdCopyBufferToImage(cmd.buffer, buffer, image, vk.ImageLayoutTransferDstOptimal,
+		uint32(len(regions)), &regions[0])
+	vr.endSingleTimeCommands(cmd)
+}
+
 func (vr *Vulkan) writeBufferToImageRegion(image vk.Image, buffer []byte, x, y, width, height int) {
 	var stagingBuffer vk.Buffer
 	var stagingBufferMemory vk.DeviceMemory
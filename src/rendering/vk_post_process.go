@@ -0,0 +1,296 @@
+/******************************************************************************/
+/* vk_post_process.go                                                         */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package rendering
+
+import (
+	"errors"
+	"kaiju/platform/profiler/tracing"
+	"log/slog"
+	"path"
+	"slices"
+
+	vk "kaiju/rendering/vulkan"
+)
+
+type vkPostProcessPass struct {
+	renderPass *RenderPass
+	shaderData *ShaderDataPostProcess
+	effect     int
+	pass       int
+	// transitions are the images sampled by this pass that are not sampled
+	// by an earlier pass, they are made readable right before it draws
+	transitions []*Texture
+}
+
+type vkPostProcess struct {
+	data     *PostProcessData
+	layout   postProcessLayout
+	source   *Texture
+	built    bool
+	drawings Drawings
+	passes   []vkPostProcessPass
+	// sampled are all of the images sampled by the passes, they are
+	// returned to being color attachments once the frame is done
+	sampled []*Texture
+	output  *Texture
+}
+
+// SetPostProcessing sets the post-processing stack that is drawn over the
+// combined image, the settings are read every frame so they can be changed
+// at any time. Nil turns off post-processing.
+func (vr *Vulkan) SetPostProcessing(data *PostProcessData) {
+	vr.postProcess.data = data
+	vr.postProcess.built = false
+	if err := vr.setHDRTargets(data != nil); err != nil {
+		slog.Error("failed to change the format of the scene images", "error", err)
+	}
+}
+
+// hdrRenderPasses are the passes that draw the scene colors that are read by
+// the post-processing stack, in the order they have to be reconstructed as
+// the later passes load the images of the earlier ones
+var hdrRenderPasses = [...]string{"opaque", "transparent", "combine"}
+
+// applyHDRFormat makes the 8 bit color images of the scene passes float while
+// post-processing is on, so bloom and exposure work on colors brighter than 1
+// rather than on colors that have been clamped
+func applyHDRFormat(c *RenderPassDataCompiled) {
+	if !slices.Contains(hdrRenderPasses[:], c.Name) {
+		return
+	}
+	for i := range c.AttachmentDescriptions {
+		a := &c.AttachmentDescriptions[i]
+		isColor := a.Image.Usage&vk.ImageUsageFlags(vk.ImageUsageColorAttachmentBit) != 0
+		if a.Format == vk.FormatR8g8b8a8Unorm && (isColor || a.Image.ExistingImage != "") {
+			a.Format = vk.FormatR16g16b16a16Sfloat
+		}
+	}
+}
+
+// setHDRTargets reconstructs the scene passes that are already loaded with
+// the format for the post-processing state, passes that are loaded later are
+// compiled with it. The shaders drawn in those passes are rebuilt as their
+// pipelines depend on the format of the images.
+func (vr *Vulkan) setHDRTargets(hdr bool) error {
+	if vr.hdrTargets == hdr {
+		return nil
+	}
+	vr.hdrTargets = hdr
+	if vr.caches == nil {
+		return nil
+	}
+	db := vr.caches.AssetDatabase()
+	var errs []error
+	for _, name := range hdrRenderPasses {
+		if _, ok := vr.renderPassCache[name]; !ok {
+			continue
+		}
+		key := path.Join("renderer/passes", name+".renderpass")
+		if vr.renderGraph != nil {
+			if gp, ok := vr.renderGraph.Pass(name); ok && gp.RenderPass != "" {
+				key = gp.RenderPass
+			}
+		}
+		var data RenderPassData
+		if err := materialUnmarshallData(db, key, &data); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		pass, err := vr.reloadRenderPass(&data)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		vr.caches.ShaderCache().rebuildRenderPass(pass)
+	}
+	return errors.Join(errs...)
+}
+
+func (p *vkPostProcess) destroy(vr *Vulkan) {
+	for i := range p.drawings.renderPassGroups {
+		rpg := &p.drawings.renderPassGroups[i]
+		for j := range rpg.draws {
+			for k := range rpg.draws[j].instanceGroups {
+				rpg.draws[j].instanceGroups[k].Clear(vr)
+			}
+		}
+	}
+	p.drawings.Destroy(vr)
+	p.passes = p.passes[:0]
+	p.sampled = p.sampled[:0]
+	p.output = nil
+}
+
+func (p *vkPostProcess) build(vr *Vulkan, source *Texture) {
+	defer tracing.NewRegion("vkPostProcess::build").End()
+	p.destroy(vr)
+	p.layout = p.data.layout()
+	p.source = source
+	p.built = true
+	mesh := NewMeshQuad(vr.caches.MeshCache())
+	input := source
+	for _, i := range p.layout.effects[:p.layout.count] {
+		passes, drawings, output, ok := p.buildEffect(vr, i, input, mesh)
+		if !ok {
+			continue
+		}
+		p.passes = append(p.passes, passes...)
+		p.drawings.AddDrawings(drawings)
+		input = output
+	}
+	p.output = input
+	vr.caches.ShaderCache().CreatePending()
+	p.drawings.PreparePending()
+}
+
+func (p *vkPostProcess) buildEffect(vr *Vulkan, index int, input *Texture, mesh *Mesh) ([]vkPostProcessPass, []Drawing, *Texture, bool) {
+	e := &postProcessEffects[index]
+	passes := make([]vkPostProcessPass, 0, len(e.passes))
+	drawings := make([]Drawing, 0, len(e.passes))
+	outputs := make([]*Texture, 0, len(e.passes))
+	sampled := len(p.sampled)
+	fail := func() ([]vkPostProcessPass, []Drawing, *Texture, bool) {
+		p.sampled = p.sampled[:sampled]
+		return nil, nil, nil, false
+	}
+	for j := range e.passes {
+		pp := &e.passes[j]
+		mat, err := vr.caches.MaterialCache().Material(pp.material)
+		if err != nil {
+			slog.Error("failed to load the post-processing material",
+				"material", pp.material, "error", err)
+			return fail()
+		}
+		if mat.renderPass == nil {
+			slog.Error("the post-processing material is missing its render pass",
+				"material", pp.material)
+			return fail()
+		}
+		count := len(pp.inputs)
+		if e.texture != nil {
+			count++
+		}
+		textures := slices.Clone(mat.Textures)
+		if len(textures) < count {
+			textures = append(textures, make([]*Texture, count-len(textures))...)
+		}
+		pass := vkPostProcessPass{
+			renderPass: mat.renderPass,
+			shaderData: &ShaderDataPostProcess{ShaderDataBase: NewShaderDataBase()},
+			effect:     index,
+			pass:       j,
+		}
+		for k, from := range pp.inputs {
+			t := input
+			if from >= 0 {
+				t = outputs[from]
+			}
+			textures[k] = t
+			if !slices.Contains(p.sampled, t) {
+				p.sampled = append(p.sampled, t)
+				pass.transitions = append(pass.transitions, t)
+			}
+		}
+		if e.texture != nil {
+			key := e.texture(p.data)
+			tex, err := vr.caches.TextureCache().Texture(key, TextureFilterLinear)
+			if err != nil {
+				slog.Error("failed to load the post-processing texture",
+					"texture", key, "error", err)
+				return fail()
+			}
+			textures[len(pp.inputs)] = tex
+		}
+		passes = append(passes, pass)
+		drawings = append(drawings, Drawing{
+			Renderer:   vr,
+			Material:   mat.CreateInstance(textures),
+			Mesh:       mesh,
+			ShaderData: pass.shaderData,
+		})
+		outputs = append(outputs, mat.renderPass.SelectOutputAttachment(vr))
+	}
+	return passes, drawings, outputs[len(outputs)-1], true
+}
+
+// render draws the enabled effects over the source image and returns the
+// image of the last effect, or the source when no effect is enabled
+func (p *vkPostProcess) render(vr *Vulkan, source *Texture) *Texture {
+	defer tracing.NewRegion("vkPostProcess::render").End()
+	if p.data == nil {
+		if p.built {
+			p.destroy(vr)
+			p.built = false
+		}
+		return source
+	}
+	if !p.built || p.source != source || p.layout != p.data.layout() {
+		p.build(vr, source)
+	}
+	if len(p.passes) == 0 {
+		return source
+	}
+	for i := range p.passes {
+		pass := &p.passes[i]
+		e := &postProcessEffects[pass.effect]
+		pass.shaderData.Params0, pass.shaderData.Params1 = e.params(p.data, pass.pass)
+		if len(pass.transitions) > 0 {
+			cmd := vr.beginSingleTimeCommands()
+			for _, t := range pass.transitions {
+				vr.transitionImageLayout(&t.RenderId, vk.ImageLayoutShaderReadOnlyOptimal,
+					vk.ImageAspectFlags(vk.ImageAspectColorBit),
+					vk.AccessFlags(vk.AccessShaderReadBit), cmd)
+			}
+			vr.endSingleTimeCommands(cmd)
+		}
+		if g, ok := p.drawings.findRenderPassGroup(pass.renderPass); ok {
+			vr.Draw(pass.renderPass, g.draws)
+		}
+	}
+	return p.output
+}
+
+// cleanup returns the sampled images to being color attachments so that
+// they can be drawn to in the next frame
+func (p *vkPostProcess) cleanup(vr *Vulkan, cmd *CommandRecorder) {
+	for _, t := range p.sampled {
+		vr.transitionImageLayout(&t.RenderId, vk.ImageLayoutColorAttachmentOptimal,
+			vk.ImageAspectFlags(vk.ImageAspectColorBit),
+			vk.AccessFlags(vk.AccessColorAttachmentReadBit|vk.AccessColorAttachmentWriteBit), cmd)
+	}
+}
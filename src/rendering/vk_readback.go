@@ -0,0 +1,212 @@
+/******************************************************************************/
+/* vk_readback.go                                                             */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package rendering
+
+import (
+	"errors"
+	"image"
+	"kaiju/matrix"
+	"kaiju/platform/profiler/tracing"
+	"math"
+	"unsafe"
+
+	vk "kaiju/rendering/vulkan"
+)
+
+// vkFrameCapture is a copy of the final image of a frame, it is read once the
+// frame has finished rendering on the GPU
+type vkFrameCapture struct {
+	buffer vk.Buffer
+	memory vk.DeviceMemory
+	size   vk.DeviceSize
+	width  int
+	height int
+	format vk.Format
+	done   []func(*image.RGBA, error)
+}
+
+// TextureReadPixels copies the whole texture back from the GPU. This waits
+// for the GPU to finish the copy, so it should not be used every frame.
+func (vr *Vulkan) TextureReadPixels(texture *Texture) (*image.RGBA, error) {
+	defer tracing.NewRegion("Vulkan::TextureReadPixels").End()
+	id := &texture.RenderId
+	if id.Image == vk.NullImage {
+		return nil, errors.New("the texture has not been created")
+	}
+	if vr.delayWrittenCommands {
+		return nil, errors.New("textures can not be read while the frame is being recorded")
+	}
+	buffer, memory, size, err := vr.createReadbackBuffer(id)
+	if err != nil {
+		return nil, err
+	}
+	defer vr.freeReadbackBuffer(buffer, memory)
+	cmd := vr.beginSingleTimeCommands()
+	layout, access := id.Layout, id.Access
+	if layout == vk.ImageLayoutUndefined {
+		layout = vk.ImageLayoutShaderReadOnlyOptimal
+		access = vk.AccessFlags(vk.AccessShaderReadBit)
+	}
+	vr.transitionImageLayout(id, vk.ImageLayoutTransferSrcOptimal,
+		vk.ImageAspectFlags(vk.ImageAspectColorBit), vk.AccessFlags(vk.AccessTransferReadBit), cmd)
+	vr.copyImageToBuffer(id, buffer, cmd)
+	vr.transitionImageLayout(id, layout,
+		vk.ImageAspectFlags(vk.ImageAspectColorBit), access, cmd)
+	vr.endSingleTimeCommands(cmd)
+	pixels, err := vr.readBuffer(memory, size)
+	if err != nil {
+		return nil, err
+	}
+	return readbackImage(id.Format, pixels, id.Width, id.Height)
+}
+
+// TextureReadPixel reads a single pixel of the texture, (0, 0) is the top
+// left. The whole texture is copied back from the GPU to do this, use
+// #Vulkan.TextureReadPixels when reading more than one pixel.
+func (vr *Vulkan) TextureReadPixel(texture *Texture, x, y int) matrix.Color {
+	defer tracing.NewRegion("Vulkan::TextureReadPixel").End()
+	img, err := vr.TextureReadPixels(texture)
+	if err != nil || !(image.Point{x, y}).In(img.Rect) {
+		return matrix.ColorClear()
+	}
+	c := img.RGBAAt(x, y)
+	return matrix.ColorRGBAInt(int(c.R), int(c.G), int(c.B), int(c.A))
+}
+
+// CaptureFrame reads back the final image of the next frame that is drawn,
+// before it is presented. The done function is called from #Vulkan.SwapFrame
+// once the frame has finished rendering.
+func (vr *Vulkan) CaptureFrame(done func(*image.RGBA, error)) {
+	vr.captureRequests = append(vr.captureRequests, done)
+}
+
+// recordFrameCapture adds the copy of the final image of the frame into the
+// frame's commands, the image must already be in the transfer source layout
+func (vr *Vulkan) recordFrameCapture(img *TextureId, cmd *CommandRecorder) {
+	if len(vr.captureRequests) == 0 || len(vr.frameCapture.done) > 0 {
+		return
+	}
+	c := vkFrameCapture{width: img.Width, height: img.Height, format: img.Format}
+	var err error
+	c.buffer, c.memory, c.size, err = vr.createReadbackBuffer(img)
+	if err != nil {
+		for _, done := range vr.captureRequests {
+			done(nil, err)
+		}
+		vr.captureRequests = vr.captureRequests[:0]
+		return
+	}
+	vr.copyImageToBuffer(img, c.buffer, cmd)
+	c.done = vr.captureRequests
+	vr.captureRequests = nil
+	vr.frameCapture = c
+}
+
+// finishFrameCapture waits for the frame that was just submitted and reads
+// its captured image, when the frame was not submitted the capture fails
+func (vr *Vulkan) finishFrameCapture(submitted bool) {
+	c := vr.frameCapture
+	if len(c.done) == 0 {
+		return
+	}
+	vr.frameCapture = vkFrameCapture{}
+	defer vr.freeReadbackBuffer(c.buffer, c.memory)
+	var img *image.RGBA
+	err := errors.New("the frame was not rendered")
+	if submitted {
+		fences := [...]vk.Fence{vr.renderFences[vr.currentFrame]}
+		vk.WaitForFences(vr.device, 1, &fences[0], vk.True, math.MaxUint64)
+		var pixels []byte
+		if pixels, err = vr.readBuffer(c.memory, c.size); err == nil {
+			img, err = readbackImage(c.format, pixels, c.width, c.height)
+		}
+	}
+	for _, done := range c.done {
+		done(img, err)
+	}
+}
+
+func (vr *Vulkan) createReadbackBuffer(id *TextureId) (vk.Buffer, vk.DeviceMemory, vk.DeviceSize, error) {
+	var buffer vk.Buffer
+	var memory vk.DeviceMemory
+	pixelSize := readbackPixelSize(id.Format)
+	if pixelSize == 0 {
+		return buffer, memory, 0, errReadbackFormat
+	}
+	size := vk.DeviceSize(id.Width * id.Height * pixelSize)
+	if size == 0 {
+		return buffer, memory, 0, errors.New("the image has no size")
+	}
+	if !vr.CreateBuffer(size, vk.BufferUsageFlags(vk.BufferUsageTransferDstBit),
+		vk.MemoryPropertyFlags(vk.MemoryPropertyHostVisibleBit|vk.MemoryPropertyHostCoherentBit),
+		&buffer, &memory) {
+		return buffer, memory, 0, errors.New("failed to create the buffer to read the image into")
+	}
+	return buffer, memory, size, nil
+}
+
+func (vr *Vulkan) copyImageToBuffer(id *TextureId, buffer vk.Buffer, cmd *CommandRecorder) {
+	region := vk.BufferImageCopy{}
+	region.ImageSubresource.AspectMask = vk.ImageAspectFlags(vk.ImageAspectColorBit)
+	region.ImageSubresource.LayerCount = 1
+	region.ImageExtent = vk.Extent3D{Width: uint32(id.Width), Height: uint32(id.Height), Depth: 1}
+	vk.CmdCopyImageToBuffer(cmd.buffer, id.Image, vk.ImageLayoutTransferSrcOptimal,
+		buffer, 1, &region)
+}
+
+func (vr *Vulkan) readBuffer(memory vk.DeviceMemory, size vk.DeviceSize) ([]byte, error) {
+	var data unsafe.Pointer
+	if vk.MapMemory(vr.device, memory, 0, size, 0, &data) != vk.Success || data == nil {
+		return nil, errors.New("failed to map the memory of the read back buffer")
+	}
+	pixels := make([]byte, size)
+	copy(pixels, unsafe.Slice((*byte)(data), size))
+	vk.UnmapMemory(vr.device, memory)
+	return pixels, nil
+}
+
+func (vr *Vulkan) freeReadbackBuffer(buffer vk.Buffer, memory vk.DeviceMemory) {
+	if buffer != vk.NullBuffer {
+		vk.DestroyBuffer(vr.device, buffer, nil)
+		vr.dbg.remove(vk.TypeToUintPtr(buffer))
+	}
+	if memory != vk.NullDeviceMemory {
+		vk.FreeMemory(vr.device, memory, nil)
+		vr.dbg.remove(vk.TypeToUintPtr(memory))
+	}
+}
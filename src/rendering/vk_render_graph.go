@@ -0,0 +1,216 @@
+/******************************************************************************/
+/* vk_render_graph.go                                                         */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package rendering
+
+import (
+	"fmt"
+	"kaiju/engine/assets"
+	"slices"
+
+	vk "kaiju/rendering/vulkan"
+)
+
+// vkRenderGraphTransition is the layout an image sampled by a pass of the
+// render graph was in before it was transitioned, so it can be restored
+type vkRenderGraphTransition struct {
+	image  *TextureId
+	layout vk.ImageLayout
+	access vk.AccessFlags
+}
+
+// loadRenderGraph reads and sorts the render graph, when there is no render
+// graph file the passes are drawn in the order of their Sort
+func (vr *Vulkan) loadRenderGraph(db *assets.Database) error {
+	if !db.Exists(RenderGraphDefault) {
+		return nil
+	}
+	src, err := db.ReadText(RenderGraphDefault)
+	if err != nil {
+		return err
+	}
+	data, err := NewRenderGraphData(src)
+	if err != nil {
+		return fmt.Errorf("failed to parse the render graph: %w", err)
+	}
+	g, err := data.Compile()
+	if err != nil {
+		return err
+	}
+	g.resolveSorts(func(p *RenderGraphPass) (int, bool) {
+		rp := RenderPassData{}
+		if p.RenderPass == "" || materialUnmarshallData(db, p.RenderPass, &rp) != nil {
+			return 0, false
+		}
+		return rp.Sort, true
+	})
+	vr.renderGraph = &g
+	return nil
+}
+
+// constructRenderGraph creates the render passes of the graph in their sorted
+// order. This makes sure that the images a pass loads from another pass exist
+// before it, and that passes added by a project exist before any material
+// uses them. The attachments are created from the .renderpass files, a file
+// that doesn't match what the graph declares for its pass is an error.
+func (vr *Vulkan) constructRenderGraph() error {
+	if vr.renderGraph == nil {
+		return nil
+	}
+	db := vr.caches.AssetDatabase()
+	for i := range vr.renderGraph.Passes {
+		gp := &vr.renderGraph.Passes[i]
+		if _, ok := vr.renderPassCache[gp.Name]; ok || gp.RenderPass == "" {
+			continue
+		}
+		if vr.swapChainRenderPass != nil && vr.swapChainRenderPass.construction.Name == gp.Name {
+			continue
+		}
+		rp := RenderPassData{}
+		if err := materialUnmarshallData(db, gp.RenderPass, &rp); err != nil {
+			return fmt.Errorf("failed to load the render pass %s of the render graph: %w", gp.Name, err)
+		}
+		if err := gp.validateRenderPass(&rp); err != nil {
+			return err
+		}
+		rpc := rp.Compile(vr)
+		pass, ok := rpc.ConstructRenderPass(vr)
+		if !ok {
+			return fmt.Errorf("failed to construct the render pass %s of the render graph", gp.Name)
+		}
+		vr.renderPassCache[gp.Name] = pass
+	}
+	return nil
+}
+
+// renderGraphSampledImages finds the images the render pass samples, through
+// the passes of the render graph that wrote them
+func (vr *Vulkan) renderGraphSampledImages(renderPass *RenderPass) []vkRenderGraphTransition {
+	if vr.renderGraph == nil {
+		return nil
+	}
+	gp, ok := vr.renderGraph.Pass(renderPass.construction.Name)
+	if !ok || len(gp.Barriers) == 0 {
+		return nil
+	}
+	images := make([]vkRenderGraphTransition, 0, len(gp.Barriers))
+	for i := range gp.Barriers {
+		b := &gp.Barriers[i]
+		writer, ok := vr.renderPassCache[b.Writer]
+		if !ok {
+			continue
+		}
+		if t, ok := writer.findTextureByName(b.Image); ok {
+			images = append(images, vkRenderGraphTransition{image: &t.RenderId})
+		}
+	}
+	return images
+}
+
+// beginRenderGraphBarriers moves the images sampled by a pass into a read only
+// layout. The commands are queued with the frame so that they run after the
+// passes that write the images and before the pass that samples them.
+func (vr *Vulkan) beginRenderGraphBarriers(images []vkRenderGraphTransition) {
+	if len(images) == 0 {
+		return
+	}
+	delay := vr.delayWrittenCommands
+	vr.delayWrittenCommands = true
+	defer func() { vr.delayWrittenCommands = delay }()
+	cmd := vr.beginSingleTimeCommands()
+	for i := range images {
+		t := images[i].image
+		isDepth := slices.Contains(depthFormatCandidates(), t.Format)
+		attachmentAccess := renderGraphAttachmentAccess(isDepth)
+		if t.Access == 0 {
+			// Render passes don't track the access of their images, the
+			// last thing done to the image was the pass writing to it
+			t.Access = attachmentAccess
+		}
+		images[i].layout = t.Layout
+		images[i].access = t.Access
+		layout := vk.ImageLayoutShaderReadOnlyOptimal
+		if isDepth {
+			layout = vk.ImageLayoutDepthStencilReadOnlyOptimal
+		}
+		if t.Layout != layout {
+			vr.transitionImageLayout(t, layout, renderGraphAspect(t.Format, isDepth),
+				vk.AccessFlags(vk.AccessShaderReadBit), cmd)
+		}
+	}
+	vr.endSingleTimeCommands(cmd)
+}
+
+// endRenderGraphBarriers moves the images sampled by a pass back to the layout
+// they were in before #beginRenderGraphBarriers, so the passes that write to
+// them find them in the layout they expect on the next frame
+func (vr *Vulkan) endRenderGraphBarriers(images []vkRenderGraphTransition) {
+	if len(images) == 0 {
+		return
+	}
+	delay := vr.delayWrittenCommands
+	vr.delayWrittenCommands = true
+	defer func() { vr.delayWrittenCommands = delay }()
+	cmd := vr.beginSingleTimeCommands()
+	for i := range images {
+		t := images[i].image
+		if t.Layout != images[i].layout {
+			isDepth := slices.Contains(depthFormatCandidates(), t.Format)
+			vr.transitionImageLayout(t, images[i].layout,
+				renderGraphAspect(t.Format, isDepth), images[i].access, cmd)
+		}
+	}
+	vr.endSingleTimeCommands(cmd)
+}
+
+func renderGraphAttachmentAccess(isDepth bool) vk.AccessFlags {
+	if isDepth {
+		return vk.AccessFlags(vk.AccessDepthStencilAttachmentReadBit | vk.AccessDepthStencilAttachmentWriteBit)
+	}
+	return vk.AccessFlags(vk.AccessColorAttachmentReadBit | vk.AccessColorAttachmentWriteBit)
+}
+
+func renderGraphAspect(format vk.Format, isDepth bool) vk.ImageAspectFlags {
+	if !isDepth {
+		return vk.ImageAspectFlags(vk.ImageAspectColorBit)
+	}
+	aspect := vk.ImageAspectFlags(vk.ImageAspectDepthBit)
+	if format == vk.FormatD32SfloatS8Uint || format == vk.FormatD24UnormS8Uint {
+		aspect |= vk.ImageAspectFlags(vk.ImageAspectStencilBit)
+	}
+	return aspect
+}
@@ -61,6 +61,12 @@ type RenderPass struct {
 	currentIdx   int
 	subpassIdx   int
 	frame        int
+	// target is set when the pass draws into a render target, the pass then
+	// uses the target's camera rather than the main camera
+	target *RenderTarget
+	// drawn is set once the pass has drawn a frame, until then the materials
+	// that sample its images are given the default texture instead
+	drawn bool
 }
 
 type RenderPassSubpass struct {
@@ -82,6 +88,21 @@ func (r *RenderPass) findTextureByName(name string) (*Texture, bool) {
 	return nil, false
 }
 
+// extent is the size of the render pass images, passes without a fixed size
+// follow the size of the swap chain multiplied by their scale
+func (r *RenderPass) extent(vr *Vulkan) vk.Extent2D {
+	if r.construction.Width > 0 && r.construction.Height > 0 {
+		return vk.Extent2D{Width: r.construction.Width, Height: r.construction.Height}
+	}
+	if s := r.construction.Scale; s > 0 && s != 1 {
+		return vk.Extent2D{
+			Width:  max(uint32(float32(vr.swapChainExtent.Width)*s), 1),
+			Height: max(uint32(float32(vr.swapChainExtent.Height)*s), 1),
+		}
+	}
+	return vr.swapChainExtent
+}
+
 func (r *RenderPass) setupSubpass(c *RenderPassSubpassDataCompiled, vr *Vulkan, assets *assets.Database, index int) error {
 	r.subpasses = r.subpasses[:0]
 	sp := RenderPassSubpass{}
@@ -246,11 +267,45 @@ func NewRenderPass(vr *Vulkan, setup *RenderPassDataCompiled) (*RenderPass, erro
 		if k == "" {
 			k = fmt.Sprintf("renderPass-%s-%d", setup.Name, i)
 		}
-		p.textures = append(p.textures, Texture{Key: k})
+		p.textures = append(p.textures, Texture{Key: k, renderPass: p})
 	}
 	return p, p.Recontstruct(vr)
 }
 
+// reloadRenderPass reconstructs the loaded render pass with the same name as
+// the data in place, so the materials and textures that point to it are kept.
+// Materials and render pass images point into the textures of the pass, so the
+// number of attachments can not change.
+func (vr *Vulkan) reloadRenderPass(data *RenderPassData) (*RenderPass, error) {
+	pass, ok := vr.renderPassCache[data.Name]
+	if !ok {
+		return nil, nil
+	}
+	setup := data.Compile(vr)
+	keys := make([]string, 0, len(setup.AttachmentDescriptions))
+	for i := range setup.AttachmentDescriptions {
+		a := &setup.AttachmentDescriptions[i]
+		if a.Image.IsInvalid() {
+			continue
+		}
+		k := a.Image.Name
+		if k == "" {
+			k = fmt.Sprintf("renderPass-%s-%d", setup.Name, i)
+		}
+		keys = append(keys, k)
+	}
+	if len(setup.AttachmentDescriptions) != len(pass.construction.AttachmentDescriptions) ||
+		len(keys) != len(pass.textures) {
+		return nil, errors.New("the attachments of the render pass changed, a restart is required to use the new attachments")
+	}
+	vk.DeviceWaitIdle(vr.device)
+	pass.construction = setup
+	for i := range keys {
+		pass.textures[i].Key = keys[i]
+	}
+	return pass, pass.Recontstruct(vr)
+}
+
 func (p *RenderPass) Recontstruct(vr *Vulkan) error {
 	p.Destroy(vr)
 	r := &p.construction
@@ -266,8 +321,9 @@ func (p *RenderPass) Recontstruct(vr *Vulkan) error {
 		}
 	}
 	{
-		w := uint32(vr.swapChainExtent.Width)
-		h := uint32(vr.swapChainExtent.Height)
+		extent := p.extent(vr)
+		w := uint32(extent.Width)
+		h := uint32(extent.Height)
 		for i := range len(r.AttachmentDescriptions) {
 			a := &r.AttachmentDescriptions[i]
 			img := &a.Image
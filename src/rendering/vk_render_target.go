@@ -0,0 +1,112 @@
+/******************************************************************************/
+/* vk_render_target.go                                                        */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package rendering
+
+import (
+	"errors"
+	"kaiju/klib"
+	"kaiju/matrix"
+	"kaiju/platform/profiler/tracing"
+	"log/slog"
+	"unsafe"
+
+	vk "kaiju/rendering/vulkan"
+)
+
+func (vr *Vulkan) CreateRenderTarget(target *RenderTarget) error {
+	defer tracing.NewRegion("Vulkan::CreateRenderTarget").End()
+	compiled := target.passData.Compile(vr)
+	pass, err := NewRenderPass(vr, &compiled)
+	if err != nil {
+		return err
+	}
+	if len(pass.textures) == 0 || readbackPixelSize(pass.textures[0].RenderId.Format) == 0 {
+		pass.Destroy(vr)
+		return errors.New("the render target pass must start with an RGBA color image")
+	}
+	bufferSize := vk.DeviceSize(unsafe.Sizeof(*(*GlobalShaderData)(nil)))
+	for i := range maxFramesInFlight {
+		if !vr.CreateBuffer(bufferSize, vk.BufferUsageFlags(vk.BufferUsageUniformBufferBit),
+			vk.MemoryPropertyFlags(vk.MemoryPropertyHostVisibleBit|vk.MemoryPropertyHostCoherentBit),
+			&target.globalBuffers[i], &target.globalMemories[i]) {
+			target.pass = pass
+			vr.DestroyRenderTarget(target)
+			return errors.New("failed to create the global uniform buffer for the render target")
+		}
+	}
+	pass.textures[0].Key = target.textureKey()
+	target.pass = pass
+	target.texture = &pass.textures[0]
+	return nil
+}
+
+func (vr *Vulkan) DestroyRenderTarget(target *RenderTarget) {
+	defer tracing.NewRegion("Vulkan::DestroyRenderTarget").End()
+	vk.DeviceWaitIdle(vr.device)
+	for i := range target.globalBuffers {
+		vr.freeReadbackBuffer(target.globalBuffers[i], target.globalMemories[i])
+		target.globalBuffers[i] = vk.NullBuffer
+		target.globalMemories[i] = vk.NullDeviceMemory
+	}
+	if target.pass != nil {
+		target.pass.Destroy(vr)
+		target.pass = nil
+	}
+	target.texture = nil
+}
+
+// prepareRenderTarget writes the global shader data of the frame, as seen
+// through the camera of the target, into the target's uniform buffer and
+// returns the buffer for the drawings of the target to use
+func (vr *Vulkan) prepareRenderTarget(target *RenderTarget) vk.Buffer {
+	c := target.ClearColor
+	target.pass.construction.ImageClears[0].SetColor([]float32{
+		float32(c.R()), float32(c.G()), float32(c.B()), float32(c.A())})
+	ubo := renderTargetGlobals(vr.frameGlobals, target.Camera,
+		matrix.Float(target.Width()), matrix.Float(target.Height()))
+	memory := target.globalMemories[vr.currentFrame]
+	var data unsafe.Pointer
+	r := vk.MapMemory(vr.device, memory, 0, vk.DeviceSize(unsafe.Sizeof(ubo)), 0, &data)
+	if r != vk.Success {
+		slog.Error("Failed to map render target uniform buffer memory", slog.Int("code", int(r)))
+	} else {
+		vk.Memcopy(data, klib.StructToByteArray(ubo))
+		vk.UnmapMemory(vr.device, memory)
+	}
+	return target.globalBuffers[vr.currentFrame]
+}
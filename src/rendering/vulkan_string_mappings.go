@@ -604,6 +604,9 @@ func imageLayoutToVK(val string) vk.ImageLayout {
 
 func sampleCountToVK(val string, vr *Vulkan) vk.SampleCountFlagBits {
 	if val == swapChainSampleCountKey {
+		if vr == nil {
+			return vk.SampleCount1Bit
+		}
 		return vr.msaaSamples
 	} else if res, ok := StringVkSampleCountFlagBits[val]; ok {
 		return res
@@ -615,8 +618,14 @@ func sampleCountToVK(val string, vr *Vulkan) vk.SampleCountFlagBits {
 
 func formatToVK(val string, vr *Vulkan) vk.Format {
 	if val == detectDepthFormatKey {
+		if vr == nil {
+			return vk.FormatD32Sfloat
+		}
 		return vr.findDepthFormat()
 	} else if val == swapChainFormatKey {
+		if vr == nil {
+			return vk.FormatR8g8b8a8Unorm
+		}
 		return vr.swapImages[0].Format
 	} else if res, ok := StringVkFormat[val]; ok {
 		return res
@@ -0,0 +1,154 @@
+/******************************************************************************/
+/* golden.go                                                                  */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package tests
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"image/png"
+	"kaiju/engine"
+	"kaiju/engine/host_container"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	goldenFolder = "golden"
+	// goldenWaitFrames gives the test time to load and settle before the
+	// frame is captured
+	goldenWaitFrames = 10
+	// goldenTolerance is how far any color channel can be off before the
+	// pixel is counted as different, drivers don't agree on the last bit
+	goldenTolerance = 2
+)
+
+func goldenPath(name string) string {
+	name = strings.ReplaceAll(strings.ToLower(name), " ", "_")
+	return filepath.Join(goldenFolder, name+".png")
+}
+
+// runGolden captures a frame of the test once it has settled and compares it
+// to the golden image of the test. When update is true, the frame is written
+// as the golden image instead. When the images are different, or there is no
+// golden image, the frame is written next to the golden image with an
+// ".actual.png" extension.
+func runGolden(c *host_container.Container, name string, update bool, done func(error)) {
+	path := goldenPath(name)
+	c.RunFunction(func() {
+		c.Host.RunAfterFrames(goldenWaitFrames, func() {
+			captureGolden(c.Host, path, update, done)
+		})
+	})
+}
+
+func captureGolden(host *engine.Host, path string, update bool, done func(error)) {
+	if update {
+		host.Screenshot(path, done)
+		return
+	}
+	expected, err := readPNG(path)
+	missing := errors.Is(err, os.ErrNotExist)
+	if err != nil && !missing {
+		done(err)
+		return
+	}
+	actualPath := strings.TrimSuffix(path, ".png") + ".actual.png"
+	host.Screenshot(actualPath, func(err error) {
+		if err == nil && missing {
+			err = fmt.Errorf("there is no golden image at %s, the frame was written to %s", path, actualPath)
+		}
+		if err != nil {
+			done(err)
+			return
+		}
+		actual, err := readPNG(actualPath)
+		if err != nil {
+			done(err)
+			return
+		}
+		if err = compareImages(expected, actual, goldenTolerance); err == nil {
+			os.Remove(actualPath)
+		}
+		done(err)
+	})
+}
+
+func readPNG(path string) (image.Image, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return png.Decode(f)
+}
+
+// compareImages returns an error describing how many pixels are different if
+// any of the color channels of a pixel differ by more than the tolerance
+func compareImages(expected, actual image.Image, tolerance uint8) error {
+	eb, ab := expected.Bounds(), actual.Bounds()
+	if eb.Dx() != ab.Dx() || eb.Dy() != ab.Dy() {
+		return fmt.Errorf("expected the image to be %dx%d but was %dx%d",
+			eb.Dx(), eb.Dy(), ab.Dx(), ab.Dy())
+	}
+	diff := 0
+	limit := uint32(tolerance) * 0x101
+	for y := 0; y < eb.Dy(); y++ {
+		for x := 0; x < eb.Dx(); x++ {
+			er, eg, ebl, ea := expected.At(eb.Min.X+x, eb.Min.Y+y).RGBA()
+			ar, ag, abl, aa := actual.At(ab.Min.X+x, ab.Min.Y+y).RGBA()
+			if channelDiff(er, ar) > limit || channelDiff(eg, ag) > limit ||
+				channelDiff(ebl, abl) > limit || channelDiff(ea, aa) > limit {
+				diff++
+			}
+		}
+	}
+	if diff > 0 {
+		return fmt.Errorf("%d of %d pixels are different from the golden image",
+			diff, eb.Dx()*eb.Dy())
+	}
+	return nil
+}
+
+func channelDiff(a, b uint32) uint32 {
+	if a > b {
+		return a - b
+	}
+	return b - a
+}
@@ -0,0 +1,117 @@
+/******************************************************************************/
+/* golden_test.go                                                             */
+/******************************************************************************/
+/*                           This file is part of:                            */
+/*                                KAIJU ENGINE                                */
+/*                          https://kaijuengine.org                           */
+/******************************************************************************/
+/* MIT License                                                                */
+/*                                                                            */
+/* Copyright (c) 2023-present Kaiju Engine authors (AUTHORS.md).              */
+/* Copyright (c) 2015-present Brent Farris.                                   */
+/*                                                                            */
+/* May all those that this source may reach be blessed by the LORD and find   */
+/* peace and joy in life.                                                     */
+/* Everyone who drinks of this water will be thirsty again; but whoever       */
+/* drinks of the water that I will give him shall never thirst; John 4:13-14  */
+/*                                                                            */
+/* Permission is hereby granted, free of charge, to any person obtaining a    */
+/* copy of this software and associated documentation files (the "Software"), */
+/* to deal in the Software without restriction, including without limitation  */
+/* the rights to use, copy, modify, merge, publish, distribute, sublicense,   */
+/* and/or sell copies of the Software, and to permit persons to whom the      */
+/* Software is furnished to do so, subject to the following conditions:       */
+/*                                                                            */
+/* The above copyright, blessing, biblical verse, notice and                  */
+/* this permission notice shall be included in all copies or                  */
+/* substantial portions of the Software.                                      */
+/*                                                                            */
+/* THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS    */
+/* OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF                 */
+/* MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.     */
+/* IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY       */
+/* CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT  */
+/* OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE      */
+/* OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.                              */
+/******************************************************************************/
+
+package tests
+
+import (
+	"flag"
+	"image"
+	"image/color"
+	"kaiju/engine"
+	"path/filepath"
+	"testing"
+)
+
+var updateGolden = flag.Bool("update", false, "write the golden images rather than comparing against them")
+
+// softwareGoldenTests are the rendering tests that the software renderer can
+// draw, the software renderer draws the same frame on every machine so its
+// golden images are kept apart from those captured on the GPU
+var softwareGoldenTests = []string{"drawing", "panel"}
+
+func TestCompareImages(t *testing.T) {
+	a := image.NewRGBA(image.Rect(0, 0, 4, 4))
+	b := image.NewRGBA(image.Rect(0, 0, 4, 4))
+	a.SetRGBA(1, 1, color.RGBA{100, 100, 100, 255})
+	b.SetRGBA(1, 1, color.RGBA{102, 99, 100, 255})
+	if err := compareImages(a, b, 2); err != nil {
+		t.Errorf("expected differences within the tolerance to match, got %v", err)
+	}
+	b.SetRGBA(2, 2, color.RGBA{0, 0, 10, 0})
+	if err := compareImages(a, b, 2); err == nil {
+		t.Error("expected a different pixel to fail")
+	}
+	if err := compareImages(a, image.NewRGBA(image.Rect(0, 0, 4, 3)), 2); err == nil {
+		t.Error("expected different sizes to fail")
+	}
+}
+
+func TestGoldenPath(t *testing.T) {
+	if p := goldenPath("Two Drawings"); p != "golden/two_drawings.png" {
+		t.Errorf("unexpected golden path %s", p)
+	}
+}
+
+func TestSoftwareGolden(t *testing.T) {
+	folder, err := filepath.Abs(filepath.Join(goldenFolder, "software"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Assets are read relative to the root of the repository
+	t.Chdir("../../..")
+	for _, name := range softwareGoldenTests {
+		t.Run(name, func(t *testing.T) {
+			host := engine.NewHost("Test "+name, nil)
+			err := host.InitializeHeadless(engine.HeadlessOptions{
+				Width:  320,
+				Height: 180,
+				Render: true,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer host.Teardown()
+			setupTest(host, findTest(name))
+			path := filepath.Join(folder, filepath.Base(goldenPath(name)))
+			captured := false
+			host.RunAfterFrames(goldenWaitFrames, func() {
+				captureGolden(host, path, *updateGolden, func(captureErr error) {
+					err, captured = captureErr, true
+				})
+			})
+			for frame := 0; !captured && frame <= goldenWaitFrames*2; frame++ {
+				host.Update(1.0 / 60.0)
+				host.Render()
+			}
+			if !captured {
+				t.Fatal("the frame was never captured")
+			} else if err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
@@ -411,6 +411,43 @@ func testMonkeyGLB(uiMan *ui.Manager) {
 	drawBasicMesh(host, res)
 }
 
+func testRenderTarget(uiMan *ui.Manager) {
+	host := uiMan.Host
+	target, err := host.NewRenderTarget("test", 256, 256)
+	if err != nil {
+		slog.Error("failed to create the render target", "error", err)
+		return
+	}
+	target.ClearColor = matrix.ColorDarkBG()
+	target.Camera.SetPositionAndLookAt(matrix.Vec3{10, 0, 2}, matrix.Vec3{10, 0, 0})
+	matKey := assets.MaterialDefinitionBasic
+	material, err := host.MaterialCache().Material(matKey)
+	if err != nil {
+		slog.Error("failed to load the material", "material", matKey, "error", err)
+		return
+	}
+	mesh := rendering.NewMeshQuad(host.MeshCache())
+	droidTex, _ := host.TextureCache().Texture("textures/android.png", rendering.TextureFilterNearest)
+	droid := TestBasicShaderData{rendering.NewShaderDataBase(), matrix.ColorWhite()}
+	m := matrix.Mat4Identity()
+	m.Translate(matrix.Vec3{10, 0, 0})
+	droid.SetModel(m)
+	host.Drawings.AddDrawing(rendering.Drawing{
+		Renderer:      host.Window.Renderer,
+		Material:      material.CreateInstance([]*rendering.Texture{droidTex}),
+		Mesh:          mesh,
+		ShaderData:    &droid,
+		RenderTargets: []*rendering.RenderTarget{target},
+	})
+	screen := TestBasicShaderData{rendering.NewShaderDataBase(), matrix.ColorWhite()}
+	host.Drawings.AddDrawing(rendering.Drawing{
+		Renderer:   host.Window.Renderer,
+		Material:   material.CreateInstance([]*rendering.Texture{target.Texture()}),
+		Mesh:       mesh,
+		ShaderData: &screen,
+	})
+}
+
 func testAnimationGLTF(uiMan *ui.Manager) {
 	const animationGLTF = "editor/meshes/fox/Fox.gltf"
 	host := uiMan.Host
@@ -507,51 +544,93 @@ func testAnimationGLTF(uiMan *ui.Manager) {
 	}
 }
 
+func findTest(t string) func(*ui.Manager) {
+	var testFunc func(*ui.Manager) = nil
+	switch strings.ToLower(t) {
+	case "drawing":
+		testFunc = testDrawing
+	case "two drawings":
+		testFunc = testTwoDrawings
+	case "font":
+		testFunc = testFont
+	case "oit":
+		testFunc = testOIT
+	case "panel":
+		testFunc = testPanel
+	case "label":
+		testFunc = testLabel
+	case "button":
+		testFunc = testButton
+	case "html":
+		testFunc = testHTML
+	case "layout simple":
+		testFunc = testLayoutSimple
+	case "layout":
+		testFunc = testLayout
+	case "html binding":
+		testFunc = testHTMLBinding
+	case "obj":
+		testFunc = testMonkeyOBJ
+	case "gltf":
+		testFunc = testMonkeyGLTF
+	case "glb":
+		testFunc = testMonkeyGLB
+	case "animation":
+		testFunc = testAnimationGLTF
+	case "render target":
+		testFunc = testRenderTarget
+	}
+	return testFunc
+}
+
+func runTest(name string, testFunc func(*ui.Manager)) *host_container.Container {
+	c := host_container.New("Test "+name, nil)
+	go c.Run(engine.DefaultWindowWidth,
+		engine.DefaultWindowHeight, -1, -1)
+	<-c.PrepLock
+	setupTest(c.Host, testFunc)
+	return c
+}
+
+func setupTest(host *engine.Host, testFunc func(*ui.Manager)) {
+	host.Camera.SetPosition(matrix.Vec3Backward().Scale(2))
+	uiMan := &ui.Manager{}
+	uiMan.Init(host)
+	testFunc(uiMan)
+}
+
 func SetupConsole(host *engine.Host) {
 	console.For(host).AddCommand("render.test", "Open a rendering test given it's name", func(_ *engine.Host, t string) string {
-		var testFunc func(*ui.Manager) = nil
-		switch strings.ToLower(t) {
-		case "drawing":
-			testFunc = testDrawing
-		case "two drawings":
-			testFunc = testTwoDrawings
-		case "font":
-			testFunc = testFont
-		case "oit":
-			testFunc = testOIT
-		case "panel":
-			testFunc = testPanel
-		case "label":
-			testFunc = testLabel
-		case "button":
-			testFunc = testButton
-		case "html":
-			testFunc = testHTML
-		case "layout simple":
-			testFunc = testLayoutSimple
-		case "layout":
-			testFunc = testLayout
-		case "html binding":
-			testFunc = testHTMLBinding
-		case "obj":
-			testFunc = testMonkeyOBJ
-		case "gltf":
-			testFunc = testMonkeyGLTF
-		case "glb":
-			testFunc = testMonkeyGLB
-		case "animation":
-			testFunc = testAnimationGLTF
-		}
-		if testFunc != nil {
-			c := host_container.New("Test "+t, nil)
-			go c.Run(engine.DefaultWindowWidth,
-				engine.DefaultWindowHeight, -1, -1)
-			<-c.PrepLock
-			c.Host.Camera.SetPosition(matrix.Vec3Backward().Scale(2))
-			uiMan := &ui.Manager{}
-			uiMan.Init(c.Host)
-			testFunc(uiMan)
+		if testFunc := findTest(t); testFunc != nil {
+			runTest(t, testFunc)
 		}
 		return "Running test"
 	})
+	console.For(host).AddCommand("render.golden", "Run a rendering test given it's name and compare it to the golden image", func(_ *engine.Host, t string) string {
+		return startGolden(t, false)
+	})
+	console.For(host).AddCommand("render.golden.update", "Run a rendering test given it's name and write the frame as its golden image", func(_ *engine.Host, t string) string {
+		return startGolden(t, true)
+	})
+}
+
+func startGolden(t string, update bool) string {
+	testFunc := findTest(t)
+	if testFunc == nil {
+		return "Unknown test"
+	}
+	c := runTest(t, testFunc)
+	runGolden(c, t, update, func(err error) {
+		if err != nil {
+			slog.Error("golden image test failed", "test", t, "error", err)
+		} else if update {
+			slog.Info("golden image updated", "test", t)
+		} else {
+			slog.Info("golden image test passed", "test", t)
+		}
+	})
+	if update {
+		return "Updating golden image"
+	}
+	return "Running golden image test"
 }